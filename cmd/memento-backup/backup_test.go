@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/scrypster/memento/internal/backup"
+	"github.com/scrypster/memento/internal/config"
 	_ "modernc.org/sqlite"
 )
 
@@ -321,7 +322,7 @@ func TestBackupService_RestoreFromBackup(t *testing.T) {
 	}
 
 	// Restore from backup
-	if err := service.RestoreBackup(ctx, backupPath); err != nil {
+	if _, err := service.RestoreBackup(ctx, backupPath, backup.RestoreOptions{}); err != nil {
 		t.Fatalf("RestoreBackup failed: %v", err)
 	}
 
@@ -420,7 +421,7 @@ func TestBackupService_RestoreBackup_NonexistentBackup(t *testing.T) {
 
 	ctx := context.Background()
 	nonexistentBackup := filepath.Join(backupDir, "nonexistent.db")
-	err = service.RestoreBackup(ctx, nonexistentBackup)
+	_, err = service.RestoreBackup(ctx, nonexistentBackup, backup.RestoreOptions{})
 	if err == nil {
 		t.Error("Expected error when restoring from nonexistent backup")
 	}
@@ -468,7 +469,7 @@ func TestBackupService_RestoreBackup_WhileRunning(t *testing.T) {
 	}
 
 	// Try to restore while service is running
-	err = service.RestoreBackup(ctx, backupPath)
+	_, err = service.RestoreBackup(ctx, backupPath, backup.RestoreOptions{})
 	if err == nil {
 		t.Error("Expected error when restoring while service is running")
 	}
@@ -588,3 +589,115 @@ func TestBackupService_BackupDirectory_Created(t *testing.T) {
 		t.Fatalf("Backup directory was not created: %v", err)
 	}
 }
+
+func TestRetentionPolicy_FromConfig(t *testing.T) {
+	t.Setenv("MEMENTO_BACKUP_RETENTION_HOURLY", "6")
+	t.Setenv("MEMENTO_BACKUP_RETENTION_DAILY", "3")
+	t.Setenv("MEMENTO_BACKUP_RETENTION_WEEKLY", "2")
+	t.Setenv("MEMENTO_BACKUP_RETENTION_MONTHLY", "1")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	want := backup.RetentionPolicy{Hourly: 6, Daily: 3, Weekly: 2, Monthly: 1}
+	if got := retentionPolicy(cfg); got != want {
+		t.Errorf("retentionPolicy() = %+v, want %+v", got, want)
+	}
+}
+
+// TestBackupService_RestoreBackup_DryRun verifies a dry run reports what the
+// backup holds without touching the live database, and that a real restore
+// keeps a safety copy of the database it replaces.
+func TestBackupService_RestoreBackup_DryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	backupDir := filepath.Join(tmpDir, "backups")
+
+	createTestDB(t, dbPath)
+	oldest := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	newest := time.Date(2026, 6, 7, 8, 9, 10, 0, time.UTC)
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE memories (id TEXT PRIMARY KEY, created_at TIMESTAMP, deleted_at TIMESTAMP)`); err != nil {
+		t.Fatalf("Failed to create memories table: %v", err)
+	}
+	for _, m := range []struct {
+		id        string
+		createdAt time.Time
+		deleted   bool
+	}{
+		{"mem:test:a", oldest, false},
+		{"mem:test:b", newest, false},
+		{"mem:test:gone", newest.Add(time.Hour), true},
+	} {
+		var deletedAt interface{}
+		if m.deleted {
+			deletedAt = m.createdAt
+		}
+		if _, err := db.Exec(`INSERT INTO memories (id, created_at, deleted_at) VALUES (?, ?, ?)`, m.id, m.createdAt, deletedAt); err != nil {
+			t.Fatalf("Failed to insert memory: %v", err)
+		}
+	}
+	_ = db.Close()
+
+	service, err := backup.NewBackupService(backup.BackupConfig{
+		DBPath:        dbPath,
+		BackupDir:     backupDir,
+		Interval:      1 * time.Hour,
+		VerifyBackups: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create backup service: %v", err)
+	}
+
+	ctx := context.Background()
+	result, err := service.BackupNow(ctx)
+	if err != nil {
+		t.Fatalf("BackupNow failed: %v", err)
+	}
+
+	// Change the live database after the backup was taken.
+	db, err = sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if _, err := db.Exec("DELETE FROM test_data"); err != nil {
+		t.Fatalf("Failed to delete data: %v", err)
+	}
+	_ = db.Close()
+
+	report, err := service.RestoreBackup(ctx, result.Path, backup.RestoreOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry-run RestoreBackup failed: %v", err)
+	}
+	if !report.DryRun || report.SafetyCopy != "" {
+		t.Errorf("dry-run report = %+v, want DryRun and no safety copy", report)
+	}
+	if report.MemoryCount != 2 {
+		t.Errorf("MemoryCount = %d, want 2", report.MemoryCount)
+	}
+	if !report.OldestMemory.Equal(oldest) || !report.NewestMemory.Equal(newest) {
+		t.Errorf("date range = %v to %v, want %v to %v", report.OldestMemory, report.NewestMemory, oldest, newest)
+	}
+	if count := countTestRecords(t, dbPath); count != 0 {
+		t.Errorf("dry run modified the live database: %d records, want 0", count)
+	}
+
+	report, err = service.RestoreBackup(ctx, result.Path, backup.RestoreOptions{})
+	if err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if count := countTestRecords(t, dbPath); count != 3 {
+		t.Errorf("Expected 3 restored records, got %d", count)
+	}
+	if report.SafetyCopy == "" {
+		t.Fatal("restore did not report a safety copy")
+	}
+	if count := countTestRecords(t, report.SafetyCopy); count != 0 {
+		t.Errorf("safety copy has %d records, want the pre-restore 0", count)
+	}
+}
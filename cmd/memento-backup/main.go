@@ -16,15 +16,16 @@ import (
 )
 
 var (
-	_      = flag.String("config", "", "Path to config file (optional, uses env vars by default)")
-	dbPath = flag.String("db", "", "Path to database file (overrides config)")
-	backupDir  = flag.String("backup-dir", "", "Backup directory path (overrides config)")
-	interval   = flag.Duration("interval", 0, "Backup interval (overrides config)")
-	verify     = flag.Bool("verify", true, "Verify backups after creation")
-	oneshot    = flag.Bool("oneshot", false, "Perform a single backup and exit")
-	restore    = flag.String("restore", "", "Restore database from backup file and exit")
-	healthCmd  = flag.Bool("health", false, "Check backup service health and exit")
-	listCmd    = flag.Bool("list", false, "List all available backups and exit")
+	_         = flag.String("config", "", "Path to config file (optional, uses env vars by default)")
+	dbPath    = flag.String("db", "", "Path to database file (overrides config)")
+	backupDir = flag.String("backup-dir", "", "Backup directory path (overrides config)")
+	interval  = flag.Duration("interval", 0, "Backup interval (overrides config)")
+	verify    = flag.Bool("verify", true, "Verify backups after creation")
+	oneshot   = flag.Bool("oneshot", false, "Perform a single backup and exit")
+	restore   = flag.String("restore", "", "Restore database from backup file and exit")
+	dryRun    = flag.Bool("dry-run", false, "With -restore, verify and describe the backup without replacing the database")
+	healthCmd = flag.Bool("health", false, "Check backup service health and exit")
+	listCmd   = flag.Bool("list", false, "List all available backups and exit")
 )
 
 func main() {
@@ -59,15 +60,10 @@ func main() {
 
 	// Create backup service
 	service, err := backup.NewBackupService(backup.BackupConfig{
-		DBPath:    dbPathFinal,
-		BackupDir: backupDirFinal,
-		Interval:  intervalFinal,
-		Retention: backup.RetentionPolicy{
-			Hourly:  24,
-			Daily:   7,
-			Weekly:  4,
-			Monthly: 12,
-		},
+		DBPath:        dbPathFinal,
+		BackupDir:     backupDirFinal,
+		Interval:      intervalFinal,
+		Retention:     retentionPolicy(cfg),
 		VerifyBackups: *verify,
 	})
 	if err != nil {
@@ -78,7 +74,7 @@ func main() {
 
 	// Handle command modes
 	if *restore != "" {
-		handleRestore(ctx, service, *restore)
+		handleRestore(ctx, service, *restore, *dryRun)
 		return
 	}
 
@@ -101,13 +97,43 @@ func main() {
 	runService(ctx, service)
 }
 
-func handleRestore(ctx context.Context, service *backup.BackupService, backupPath string) {
-	log.Printf("Restoring database from backup: %s", backupPath)
+// retentionPolicy builds the backup retention tiers from config
+// (MEMENTO_BACKUP_RETENTION_HOURLY, _DAILY, _WEEKLY and _MONTHLY).
+func retentionPolicy(cfg *config.Config) backup.RetentionPolicy {
+	return backup.RetentionPolicy{
+		Hourly:  cfg.Backup.BackupRetentionHourly,
+		Daily:   cfg.Backup.BackupRetentionDaily,
+		Weekly:  cfg.Backup.BackupRetentionWeekly,
+		Monthly: cfg.Backup.BackupRetentionMonthly,
+	}
+}
+
+func handleRestore(ctx context.Context, service *backup.BackupService, backupPath string, dryRun bool) {
+	if dryRun {
+		log.Printf("Dry run: checking restore from backup: %s", backupPath)
+	} else {
+		log.Printf("Restoring database from backup: %s", backupPath)
+	}
 
-	if err := service.RestoreBackup(ctx, backupPath); err != nil {
+	report, err := service.RestoreBackup(ctx, backupPath, backup.RestoreOptions{DryRun: dryRun})
+	if err != nil {
 		log.Fatalf("Restore failed: %v", err)
 	}
 
+	fmt.Printf("Memories: %d\n", report.MemoryCount)
+	if report.MemoryCount > 0 {
+		fmt.Printf("Date Range: %s to %s\n",
+			report.OldestMemory.Format(time.RFC3339),
+			report.NewestMemory.Format(time.RFC3339))
+	}
+
+	if dryRun {
+		log.Println("Dry run complete: backup verified and target writable; database not modified")
+		return
+	}
+	if report.SafetyCopy != "" {
+		fmt.Printf("Previous Database: %s\n", report.SafetyCopy)
+	}
 	log.Println("Database restored successfully")
 }
 
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// Output formats accepted by -format.
+const (
+	formatJSONL    = "jsonl"
+	formatMarkdown = "markdown"
+)
+
+// exportOptions selects which memories are exported and how.
+type exportOptions struct {
+	Format         string    // formatJSONL or formatMarkdown
+	IncludeDeleted bool      // also export soft-deleted and expired memories
+	Since          time.Time // only memories created after this time; zero = no lower bound
+	Until          time.Time // only memories created before this time; zero = no upper bound
+}
+
+// exportPageSize is the number of memories fetched per List call, so only
+// one page is held in memory at a time.
+const exportPageSize = storage.MaxLimit
+
+// exportMemories streams every memory matching opts from store to w and
+// returns how many were written. Memories are listed in ID order so pages
+// never overlap, even when many memories share a creation time.
+func exportMemories(ctx context.Context, store storage.MemoryStore, w io.Writer, opts exportOptions) (int, error) {
+	var write func(*bufio.Writer, *types.Memory) error
+	switch opts.Format {
+	case formatJSONL:
+		write = writeJSONL
+	case formatMarkdown:
+		write = writeMarkdown
+	default:
+		return 0, fmt.Errorf("unknown format %q (want %s or %s)", opts.Format, formatJSONL, formatMarkdown)
+	}
+
+	bw := bufio.NewWriter(w)
+	count := 0
+	for page := 1; ; page++ {
+		result, err := store.List(ctx, storage.ListOptions{
+			Page:           page,
+			Limit:          exportPageSize,
+			SortBy:         "id",
+			SortOrder:      "asc",
+			CreatedAfter:   opts.Since,
+			CreatedBefore:  opts.Until,
+			IncludeDeleted: opts.IncludeDeleted,
+			IncludeExpired: opts.IncludeDeleted,
+		})
+		if err != nil {
+			return count, fmt.Errorf("failed to list memories (page %d): %w", page, err)
+		}
+		for i := range result.Items {
+			if err := write(bw, &result.Items[i]); err != nil {
+				return count, fmt.Errorf("failed to write memory %s: %w", result.Items[i].ID, err)
+			}
+			count++
+		}
+		if !result.HasMore || len(result.Items) == 0 {
+			break
+		}
+	}
+	return count, bw.Flush()
+}
+
+// writeJSONL writes m as a single line of JSON.
+func writeJSONL(w *bufio.Writer, m *types.Memory) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return w.WriteByte('\n')
+}
+
+// writeMarkdown writes m as a level-two section headed by its ID, with a
+// short field list followed by the content.
+func writeMarkdown(w *bufio.Writer, m *types.Memory) error {
+	fmt.Fprintf(w, "## %s\n\n", m.ID)
+	fmt.Fprintf(w, "- Created: %s\n", m.CreatedAt.UTC().Format(time.RFC3339))
+	for _, field := range []struct{ name, value string }{
+		{"Source", m.Source},
+		{"Domain", m.Domain},
+		{"Type", m.MemoryType},
+		{"Category", m.Category},
+		{"State", m.State},
+		{"Status", string(m.Status)},
+		{"Tags", strings.Join(m.Tags, ", ")},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(w, "- %s: %s\n", field.name, field.value)
+		}
+	}
+	if m.DeletedAt != nil {
+		fmt.Fprintf(w, "- Deleted: %s\n", m.DeletedAt.UTC().Format(time.RFC3339))
+	}
+	if m.Summary != "" {
+		fmt.Fprintf(w, "\n> %s\n", m.Summary)
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n\n---\n\n", strings.TrimRight(m.Content, "\n"))
+	return err
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// newExportStore returns an in-memory store holding n memories created one
+// minute apart starting at base, with IDs mem:general:000, mem:general:001, ...
+func newExportStore(t *testing.T, n int, base time.Time) *sqlite.MemoryStore {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	for i := 0; i < n; i++ {
+		created := base.Add(time.Duration(i) * time.Minute)
+		m := &types.Memory{
+			ID:        fmt.Sprintf("mem:general:%03d", i),
+			Content:   fmt.Sprintf("memory number %d", i),
+			Source:    "test",
+			Domain:    "general",
+			Tags:      []string{"export"},
+			Status:    types.StatusEnriched,
+			CreatedAt: created,
+			UpdatedAt: created,
+			Timestamp: created,
+		}
+		if err := store.Store(context.Background(), m); err != nil {
+			t.Fatalf("failed to store %s: %v", m.ID, err)
+		}
+	}
+	return store
+}
+
+func TestExportMemories_JSONLStreamsEveryPage(t *testing.T) {
+	store := newExportStore(t, 205, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var buf bytes.Buffer
+	n, err := exportMemories(context.Background(), store, &buf, exportOptions{Format: formatJSONL})
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if n != 205 {
+		t.Fatalf("exported %d memories, want 205", n)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	seen := make(map[string]bool)
+	for scanner.Scan() {
+		var m types.Memory
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatalf("line %d is not a memory: %v", len(seen)+1, err)
+		}
+		if seen[m.ID] {
+			t.Fatalf("memory %s exported twice", m.ID)
+		}
+		seen[m.ID] = true
+		if m.Content == "" {
+			t.Fatalf("memory %s exported without content", m.ID)
+		}
+	}
+	if len(seen) != 205 {
+		t.Fatalf("got %d JSON lines, want 205", len(seen))
+	}
+}
+
+func TestExportMemories_DeletedAndDateRange(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newExportStore(t, 5, base)
+	ctx := context.Background()
+	if err := store.Delete(ctx, "mem:general:001"); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+
+	ids := func(opts exportOptions) []string {
+		var buf bytes.Buffer
+		opts.Format = formatJSONL
+		if _, err := exportMemories(ctx, store, &buf, opts); err != nil {
+			t.Fatalf("export failed: %v", err)
+		}
+		var out []string
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			var m types.Memory
+			if err := json.Unmarshal([]byte(line), &m); err != nil {
+				t.Fatalf("bad line %q: %v", line, err)
+			}
+			out = append(out, m.ID)
+		}
+		return out
+	}
+
+	if got := ids(exportOptions{}); strings.Join(got, ",") != "mem:general:000,mem:general:002,mem:general:003,mem:general:004" {
+		t.Errorf("default export = %v, want deleted memory excluded", got)
+	}
+	if got := ids(exportOptions{IncludeDeleted: true}); len(got) != 5 {
+		t.Errorf("include-deleted export = %v, want all 5", got)
+	}
+	got := ids(exportOptions{Since: base.Add(90 * time.Second), Until: base.Add(4 * time.Minute)})
+	if strings.Join(got, ",") != "mem:general:002,mem:general:003" {
+		t.Errorf("date-range export = %v, want 002 and 003", got)
+	}
+}
+
+func TestExportMemories_Markdown(t *testing.T) {
+	store := newExportStore(t, 2, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var buf bytes.Buffer
+	if _, err := exportMemories(context.Background(), store, &buf, exportOptions{Format: formatMarkdown}); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"## mem:general:000\n",
+		"- Created: 2025-01-01T00:00:00Z\n",
+		"- Tags: export\n",
+		"\nmemory number 1\n\n---\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExportMemories_UnknownFormat(t *testing.T) {
+	store := newExportStore(t, 0, time.Now())
+	if _, err := exportMemories(context.Background(), store, &bytes.Buffer{}, exportOptions{Format: "csv"}); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	day := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+	if got, err := parseDate("2025-03-04", false); err != nil || !got.Equal(day) {
+		t.Errorf("parseDate(since) = %v, %v; want %v", got, err, day)
+	}
+	if got, err := parseDate("2025-03-04", true); err != nil || !got.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("parseDate(until) = %v, %v; want the next day", got, err)
+	}
+	if got, err := parseDate("2025-03-04T10:00:00Z", true); err != nil || !got.Equal(day.Add(10*time.Hour)) {
+		t.Errorf("parseDate(RFC3339) = %v, %v", got, err)
+	}
+	if _, err := parseDate("March 4", false); err == nil {
+		t.Error("expected an error for an unparseable date")
+	}
+}
@@ -0,0 +1,90 @@
+// Command memento-export dumps memories to stdout or a file without running
+// the MCP server. It opens the store directly, honoring MEMENTO_DATA_PATH and
+// connections.json, and writes JSON Lines (one memory per line, the format
+// memento-import reads) or Markdown.
+//
+// Logging goes to stderr so stdout carries only the export.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/connections"
+)
+
+var (
+	connection     = flag.String("connection", "", "Connection to export (default: the default connection from connections.json)")
+	format         = flag.String("format", formatJSONL, "Output format: jsonl or markdown")
+	output         = flag.String("output", "", "File to write (default: stdout)")
+	includeDeleted = flag.Bool("include-deleted", false, "Also export soft-deleted and expired memories")
+	since          = flag.String("since", "", "Only memories created after this date (YYYY-MM-DD or RFC3339)")
+	until          = flag.String("until", "", "Only memories created before this date (YYYY-MM-DD, inclusive, or RFC3339)")
+)
+
+func main() {
+	log.SetOutput(os.Stderr)
+	log.SetPrefix("memento-export: ")
+	log.SetFlags(0)
+	flag.Parse()
+
+	opts := exportOptions{Format: *format, IncludeDeleted: *includeDeleted}
+	var err error
+	if opts.Since, err = parseDate(*since, false); err != nil {
+		log.Fatalf("invalid -since: %v", err)
+	}
+	if opts.Until, err = parseDate(*until, true); err != nil {
+		log.Fatalf("invalid -until: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	store, _, closeStore, err := connections.OpenStore(cfg, *connection, *connection != "", false)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	defer closeStore()
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("failed to create %s: %v", *output, err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	n, err := exportMemories(context.Background(), store, out, opts)
+	if err != nil {
+		log.Fatalf("export failed after %d memories: %v", n, err)
+	}
+	log.Printf("exported %d memories", n)
+}
+
+// parseDate parses a YYYY-MM-DD or RFC3339 date; empty input yields the zero
+// time. With endOfDay, a bare date moves to the start of the next day so an
+// exclusive upper bound still includes the whole named day.
+func parseDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not YYYY-MM-DD or RFC3339", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
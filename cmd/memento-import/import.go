@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/connections"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// Conflict modes accepted by -on-conflict: what happens when a memory ID
+// already exists in the target connection.
+const (
+	conflictSkip      = "skip"      // keep the existing memory
+	conflictOverwrite = "overwrite" // replace it with the imported one
+	conflictRename    = "rename"    // import under a new ID (<id>-2, <id>-3, ...)
+)
+
+// importOptions controls how a dump is written into the target store.
+type importOptions struct {
+	Domain        string // target connection name, the domain of re-keyed IDs ("" means "general")
+	OnConflict    string // conflictSkip, conflictOverwrite or conflictRename
+	KeepStatus    bool   // keep imported enrichment statuses instead of resetting them to pending
+	IDScheme      string // config.MemoryIDScheme* used to re-key memories from another connection
+	IDPrefix      string // prefix of re-keyed IDs, the target connection's id_prefix ("" means "mem")
+	Normalization string // config.Dedup* level used to re-key memories from another connection
+
+	// Routes, when set, records Domain as the connection of every imported
+	// ID so opaque IDs resolve (see config.MemoryIDSchemeOpaque).
+	Routes *connections.RouteTable
+}
+
+// importSummary counts what happened to each record of a dump.
+type importSummary struct {
+	Imported    int // stored under an ID that did not exist yet
+	Overwritten int // replaced an existing memory (conflictOverwrite)
+	Renamed     int // stored under a new ID because the original existed (conflictRename)
+	Skipped     int // left the existing memory untouched (conflictSkip)
+	Rekeyed     int // given a new ID because they came from another connection
+}
+
+func (s importSummary) String() string {
+	return fmt.Sprintf("imported %d, overwritten %d, renamed %d, skipped %d (%d re-keyed for the target connection)",
+		s.Imported, s.Overwritten, s.Renamed, s.Skipped, s.Rekeyed)
+}
+
+// importMemories reads memories written by memento-export (JSON Lines, one
+// types.Memory per line) from r and stores them, resolving ID conflicts with
+// opts.OnConflict. Memories from another connection get the ID store_memory
+// would give them in the target connection. Unless opts.KeepStatus is set,
+// each memory is reset to pending so the engine re-enriches it.
+func importMemories(ctx context.Context, store storage.MemoryStore, r io.Reader, opts importOptions) (importSummary, error) {
+	var summary importSummary
+	switch opts.OnConflict {
+	case conflictSkip, conflictOverwrite, conflictRename:
+	default:
+		return summary, fmt.Errorf("unknown conflict mode %q (want %s, %s or %s)", opts.OnConflict, conflictSkip, conflictOverwrite, conflictRename)
+	}
+	domain := orGeneral(opts.Domain)
+
+	dec := json.NewDecoder(r)
+	for record := 1; ; record++ {
+		var m types.Memory
+		if err := dec.Decode(&m); err == io.EOF {
+			return summary, nil
+		} else if err != nil {
+			return summary, fmt.Errorf("record %d: invalid memory: %w", record, err)
+		}
+		if m.ID == "" || m.Content == "" {
+			return summary, fmt.Errorf("record %d: memory needs an id and content", record)
+		}
+
+		if orGeneral(m.Domain) != domain {
+			m.ID = mcp.MemoryID(opts.IDScheme, opts.Normalization, opts.IDPrefix, domain, m.Content)
+			m.Domain = opts.Domain
+			summary.Rekeyed++
+		}
+		if !opts.KeepStatus {
+			resetEnrichment(&m)
+		}
+
+		exists, err := memoryExists(ctx, store, m.ID)
+		if err != nil {
+			return summary, fmt.Errorf("record %d (%s): %w", record, m.ID, err)
+		}
+		counter := &summary.Imported
+		if exists {
+			switch opts.OnConflict {
+			case conflictSkip:
+				summary.Skipped++
+				continue
+			case conflictOverwrite:
+				counter = &summary.Overwritten
+			case conflictRename:
+				if m.ID, err = freeID(ctx, store, m.ID); err != nil {
+					return summary, fmt.Errorf("record %d: %w", record, err)
+				}
+				counter = &summary.Renamed
+			}
+		}
+
+		// Record the route first, as store_memory does, so a stored memory is
+		// never left unroutable.
+		if opts.Routes != nil {
+			if err := opts.Routes.Set(ctx, m.ID, opts.Domain); err != nil {
+				return summary, fmt.Errorf("record %d: %w", record, err)
+			}
+		}
+		if err := store.Store(ctx, &m); err != nil {
+			return summary, fmt.Errorf("record %d (%s): failed to store: %w", record, m.ID, err)
+		}
+		*counter++
+	}
+}
+
+// orGeneral returns domain, or "general" for the empty domain that
+// store_memory records when no connection is named.
+func orGeneral(domain string) string {
+	if domain == "" {
+		return "general"
+	}
+	return domain
+}
+
+// resetEnrichment marks m as never enriched, so the engine's pending
+// recovery queues it for enrichment and embedding in its new store.
+func resetEnrichment(m *types.Memory) {
+	m.Status = types.StatusPending
+	m.EntityStatus = types.EnrichmentPending
+	m.RelationshipStatus = types.EnrichmentPending
+	m.EmbeddingStatus = types.EnrichmentPending
+	m.ClassificationStatus = types.EnrichmentPending
+	m.SummarizationStatus = types.EnrichmentPending
+	m.EnrichmentAttempts = 0
+	m.EnrichmentError = ""
+	m.EnrichedAt = nil
+}
+
+// memoryExists reports whether store already holds a memory with id.
+func memoryExists(ctx context.Context, store storage.MemoryStore, id string) (bool, error) {
+	_, err := store.Get(ctx, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// freeID returns the first of id-2, id-3, ... that store does not hold.
+func freeID(ctx context.Context, store storage.MemoryStore, id string) (string, error) {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", id, n)
+		exists, err := memoryExists(ctx, store, candidate)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", candidate, err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+}
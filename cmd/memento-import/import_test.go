@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/connections"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// newImportStore returns an in-memory store already holding one enriched
+// memory, mem:work:existing, with content "original".
+func newImportStore(t *testing.T) *sqlite.MemoryStore {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	now := time.Now()
+	if err := store.Store(context.Background(), &types.Memory{
+		ID: "mem:work:existing", Content: "original", Source: "test", Domain: "work",
+		Status: types.StatusEnriched, CreatedAt: now, UpdatedAt: now, Timestamp: now,
+	}); err != nil {
+		t.Fatalf("failed to seed store: %v", err)
+	}
+	return store
+}
+
+// dump renders memories as memento-export JSON Lines.
+func dump(t *testing.T, memories ...types.Memory) *strings.Reader {
+	t.Helper()
+	var b strings.Builder
+	for _, m := range memories {
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatalf("failed to marshal %s: %v", m.ID, err)
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return strings.NewReader(b.String())
+}
+
+func importOpts(onConflict string) importOptions {
+	return importOptions{
+		Domain:     "work",
+		OnConflict: onConflict,
+		IDScheme:   config.MemoryIDSchemeDeterministic,
+	}
+}
+
+func conflicting() types.Memory {
+	now := time.Now()
+	return types.Memory{
+		ID: "mem:work:existing", Content: "imported", Source: "dump", Domain: "work",
+		Status: types.StatusEnriched, CreatedAt: now, UpdatedAt: now, Timestamp: now,
+	}
+}
+
+func mustGet(t *testing.T, store *sqlite.MemoryStore, id string) *types.Memory {
+	t.Helper()
+	m, err := store.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", id, err)
+	}
+	return m
+}
+
+func TestImportMemories_Skip(t *testing.T) {
+	store := newImportStore(t)
+
+	summary, err := importMemories(context.Background(), store, dump(t, conflicting()), importOpts(conflictSkip))
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if summary != (importSummary{Skipped: 1}) {
+		t.Errorf("summary = %+v, want one skipped", summary)
+	}
+	if got := mustGet(t, store, "mem:work:existing").Content; got != "original" {
+		t.Errorf("content = %q, want the existing memory kept", got)
+	}
+}
+
+func TestImportMemories_Overwrite(t *testing.T) {
+	store := newImportStore(t)
+
+	summary, err := importMemories(context.Background(), store, dump(t, conflicting()), importOpts(conflictOverwrite))
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if summary != (importSummary{Overwritten: 1}) {
+		t.Errorf("summary = %+v, want one overwritten", summary)
+	}
+	m := mustGet(t, store, "mem:work:existing")
+	if m.Content != "imported" {
+		t.Errorf("content = %q, want the imported memory", m.Content)
+	}
+	if m.Status != types.StatusPending {
+		t.Errorf("status = %q, want pending so enrichment is re-queued", m.Status)
+	}
+}
+
+func TestImportMemories_Rename(t *testing.T) {
+	store := newImportStore(t)
+	first, second := conflicting(), conflicting()
+	second.Content = "imported again"
+
+	summary, err := importMemories(context.Background(), store, dump(t, first, second), importOpts(conflictRename))
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if summary != (importSummary{Renamed: 2}) {
+		t.Errorf("summary = %+v, want two renamed", summary)
+	}
+	if got := mustGet(t, store, "mem:work:existing").Content; got != "original" {
+		t.Errorf("original content = %q, want it untouched", got)
+	}
+	if got := mustGet(t, store, "mem:work:existing-2").Content; got != "imported" {
+		t.Errorf("first renamed content = %q", got)
+	}
+	if got := mustGet(t, store, "mem:work:existing-3").Content; got != "imported again" {
+		t.Errorf("second renamed content = %q", got)
+	}
+}
+
+func TestImportMemories_RekeysOtherConnectionAndKeepsStatus(t *testing.T) {
+	store := newImportStore(t)
+	now := time.Now()
+	personal := types.Memory{
+		ID: "mem:personal:0123456789abcdef", Content: "from another connection", Source: "dump", Domain: "personal",
+		Status: types.StatusEnriched, CreatedAt: now, UpdatedAt: now, Timestamp: now,
+	}
+	opts := importOpts(conflictSkip)
+	opts.KeepStatus = true
+	routes, err := connections.OpenRouteTable(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open route table: %v", err)
+	}
+	t.Cleanup(func() { _ = routes.Close() })
+	opts.Routes = routes
+
+	summary, err := importMemories(context.Background(), store, dump(t, personal), opts)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if summary != (importSummary{Imported: 1, Rekeyed: 1}) {
+		t.Errorf("summary = %+v, want one imported and re-keyed", summary)
+	}
+
+	wantID := mcp.MemoryID(config.MemoryIDSchemeDeterministic, "", "", "work", personal.Content)
+	m := mustGet(t, store, wantID)
+	if m.Domain != "work" {
+		t.Errorf("domain = %q, want work", m.Domain)
+	}
+	if m.Status != types.StatusEnriched {
+		t.Errorf("status = %q, want enriched kept with KeepStatus", m.Status)
+	}
+	if conn, err := routes.Lookup(context.Background(), wantID); err != nil || conn != "work" {
+		t.Errorf("route = %q, %v; want work", conn, err)
+	}
+}
+
+func TestImportMemories_RejectsBadInput(t *testing.T) {
+	store := newImportStore(t)
+
+	if _, err := importMemories(context.Background(), store, strings.NewReader("{}"), importOpts("merge")); err == nil {
+		t.Error("expected an error for an unknown conflict mode")
+	}
+	if _, err := importMemories(context.Background(), store, strings.NewReader("not json"), importOpts(conflictSkip)); err == nil {
+		t.Error("expected an error for malformed input")
+	}
+	if _, err := importMemories(context.Background(), store, strings.NewReader(`{"id":"mem:work:x"}`), importOpts(conflictSkip)); err == nil {
+		t.Error("expected an error for a memory without content")
+	}
+}
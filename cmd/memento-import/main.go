@@ -0,0 +1,83 @@
+// Command memento-import loads a JSON Lines dump written by memento-export
+// into a connection. It opens the store directly, honoring MEMENTO_DATA_PATH
+// and connections.json, so the MCP server does not need to be running.
+//
+// Imported memories are reset to pending unless -keep-status is given; the
+// memory engine enriches them on its next start or pending rescan.
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/connections"
+)
+
+var (
+	connection = flag.String("connection", "", "Connection to import into (default: the default connection from connections.json)")
+	input      = flag.String("input", "", "JSON Lines file to read (default: stdin)")
+	onConflict = flag.String("on-conflict", conflictSkip, "When a memory ID already exists: skip, overwrite or rename")
+	keepStatus = flag.Bool("keep-status", false, "Keep imported enrichment statuses instead of re-queueing enrichment")
+)
+
+func main() {
+	log.SetOutput(os.Stderr)
+	log.SetPrefix("memento-import: ")
+	log.SetFlags(0)
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	var in io.Reader = os.Stdin
+	if *input != "" {
+		f, err := os.Open(*input)
+		if err != nil {
+			log.Fatalf("failed to open %s: %v", *input, err)
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+
+	// Like memento-mcp, memories stored without a connection carry
+	// MEMENTO_DEFAULT_CONNECTION as their domain ("general" when unset).
+	name := *connection
+	if name == "" {
+		name = os.Getenv("MEMENTO_DEFAULT_CONNECTION")
+	}
+	store, idPrefix, closeStore, err := connections.OpenStore(cfg, name, *connection != "", true)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	defer closeStore()
+
+	opts := importOptions{
+		Domain:        name,
+		OnConflict:    *onConflict,
+		KeepStatus:    *keepStatus,
+		IDScheme:      cfg.Storage.MemoryIDScheme,
+		IDPrefix:      idPrefix,
+		Normalization: cfg.Storage.DedupNormalization,
+	}
+	if cfg.Storage.MemoryIDScheme == config.MemoryIDSchemeOpaque {
+		routes, err := connections.OpenRouteTable(filepath.Join(cfg.Storage.DataPath, "memory_routes.db"))
+		if err != nil {
+			log.Fatalf("failed to open memory route table: %v", err)
+		}
+		defer func() { _ = routes.Close() }()
+		opts.Routes = routes
+	}
+
+	summary, err := importMemories(context.Background(), store, in, opts)
+	if err != nil {
+		log.Fatalf("import stopped: %v (so far: %s)", err, summary)
+	}
+	log.Print(summary)
+}
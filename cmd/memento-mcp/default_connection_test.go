@@ -0,0 +1,73 @@
+// default_connection_test.go exercises the default connection resolution.
+//
+// Tests verify that resolveDefaultConnection() respects the priority order:
+//  1. A .memento file in the directory or one of its parents
+//  2. MEMENTO_DEFAULT_CONNECTION
+//  3. None
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeProjectConfig writes a .memento file with content into dir.
+func writeProjectConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, projectConfigFile)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestResolveDefaultConnection_ProjectFileBeatsEnv(t *testing.T) {
+	root := t.TempDir()
+	path := writeProjectConfig(t, root, `{"connection": "work"}`)
+	t.Setenv("MEMENTO_DEFAULT_CONNECTION", "global")
+
+	name, source := resolveDefaultConnection(root)
+	assert.Equal(t, "work", name)
+	assert.Equal(t, path, source)
+}
+
+func TestResolveDefaultConnection_FoundInParent(t *testing.T) {
+	root := t.TempDir()
+	path := writeProjectConfig(t, root, `{"connection": "work"}`)
+	sub := filepath.Join(root, "src", "pkg")
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+	t.Setenv("MEMENTO_DEFAULT_CONNECTION", "")
+
+	name, source := resolveDefaultConnection(sub)
+	assert.Equal(t, "work", name)
+	assert.Equal(t, path, source)
+}
+
+func TestResolveDefaultConnection_EnvWithoutProjectFile(t *testing.T) {
+	t.Setenv("MEMENTO_DEFAULT_CONNECTION", "global")
+
+	name, source := resolveDefaultConnection(t.TempDir())
+	assert.Equal(t, "global", name)
+	assert.Equal(t, "MEMENTO_DEFAULT_CONNECTION", source)
+}
+
+func TestResolveDefaultConnection_None(t *testing.T) {
+	t.Setenv("MEMENTO_DEFAULT_CONNECTION", "")
+
+	name, source := resolveDefaultConnection(t.TempDir())
+	assert.Empty(t, name)
+	assert.Empty(t, source)
+}
+
+func TestResolveDefaultConnection_InvalidProjectFileFallsBackToEnv(t *testing.T) {
+	t.Setenv("MEMENTO_DEFAULT_CONNECTION", "global")
+
+	for _, content := range []string{`work`, `{}`} {
+		root := t.TempDir()
+		writeProjectConfig(t, root, content)
+		name, _ := resolveDefaultConnection(root)
+		assert.Equal(t, "global", name, content)
+	}
+}
@@ -8,7 +8,8 @@
 //  3. Create a MemoryEngine wrapping the store.
 //  4. Start the decay-score updater as a background goroutine.
 //  5. Create the MCP server, injecting the engine's store.
-//  6. Serve JSON-RPC 2.0 requests from stdin, writing responses to stdout.
+//  6. Serve JSON-RPC 2.0 requests from stdin, writing responses to stdout, or
+//     over HTTP when MEMENTO_MCP_TRANSPORT=http.
 //
 // CRITICAL: ALL logging MUST go to stderr.  Any bytes written to stdout that
 // are not valid JSON-RPC 2.0 response frames will corrupt the protocol.
@@ -16,20 +17,29 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/scrypster/memento/internal/api/mcp"
 	"github.com/scrypster/memento/internal/config"
 	"github.com/scrypster/memento/internal/connections"
 	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/internal/logging"
 	"github.com/scrypster/memento/internal/notify"
+	"github.com/scrypster/memento/internal/storage/postgres"
 	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
 )
 
 // resolveConnectionsConfig finds the connections.json path, trying multiple locations.
@@ -40,7 +50,7 @@ func resolveConnectionsConfig() string {
 		if _, err := os.Stat(path); err == nil {
 			return path
 		}
-		log.Printf("MEMENTO_CONNECTIONS_CONFIG=%s does not exist, continuing search", path)
+		slog.Warn("MEMENTO_CONNECTIONS_CONFIG does not exist, continuing search", "path", path)
 	}
 
 	// 2. Next to the executable (typical installed layout: memento-mcp + config/ in same dir)
@@ -59,6 +69,53 @@ func resolveConnectionsConfig() string {
 	return ""
 }
 
+// projectConfigFile is the name of the project-level config file that names
+// the default connection for tool calls made from the project.
+const projectConfigFile = ".memento"
+
+// projectConfig is the content of a .memento file, e.g.
+// {"connection": "work"}.
+type projectConfig struct {
+	Connection string `json:"connection"`
+}
+
+// resolveDefaultConnection picks the connection used when a tool call names
+// none, returning it and where it came from:
+//  1. The connection named by the nearest .memento file in dir or one of
+//     its parents, so each project directory can pick its own
+//  2. MEMENTO_DEFAULT_CONNECTION
+//  3. None (""), leaving the connections config's default in effect
+//
+// A .memento file that cannot be read or names no connection is logged and
+// skipped.
+func resolveDefaultConnection(dir string) (name, source string) {
+	if dir != "" {
+		for {
+			path := filepath.Join(dir, projectConfigFile)
+			if data, err := os.ReadFile(path); err == nil {
+				var pc projectConfig
+				if err := json.Unmarshal(data, &pc); err != nil {
+					slog.Warn("ignoring unreadable project config", "path", path, "error", err)
+				} else if pc.Connection == "" {
+					slog.Warn("ignoring project config without a connection", "path", path)
+				} else {
+					return pc.Connection, path
+				}
+				break
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	if name := os.Getenv("MEMENTO_DEFAULT_CONNECTION"); name != "" {
+		return name, "MEMENTO_DEFAULT_CONNECTION"
+	}
+	return "", ""
+}
+
 func main() {
 	// Redirect the default logger to stderr so that any incidental log calls
 	// (e.g. from imported packages) never pollute the stdout JSON-RPC stream.
@@ -71,6 +128,11 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
+	// MEMENTO_LOG_LEVEL picks the leveled logger's threshold; it writes to
+	// stderr like everything else.
+	if err := logging.Setup(os.Stderr, cfg.Server.LogLevel); err != nil {
+		log.Fatalf("%v", err)
+	}
 
 	// Ensure the data directory exists.
 	if err := os.MkdirAll(cfg.Storage.DataPath, 0o700); err != nil {
@@ -79,7 +141,7 @@ func main() {
 
 	// Open the SQLite database.
 	dbPath := fmt.Sprintf("%s/memento.db", cfg.Storage.DataPath)
-	store, err := sqlite.NewMemoryStore(dbPath)
+	store, err := sqlite.NewMemoryStore(dbPath, sqlite.OptionsFromConfig(cfg.Storage)...)
 	if err != nil {
 		log.Fatalf("failed to open database at %q: %v", dbPath, err)
 	}
@@ -93,35 +155,81 @@ func main() {
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigCh
-		log.Println("received shutdown signal")
+		slog.Info("received shutdown signal")
 		cancel()
 	}()
 
+	// Load connections config so the MCP server can route connection_id to
+	// the right store and so that memory IDs get the correct domain segment.
+	//
+	// Priority order for finding connections.json:
+	//   1. MEMENTO_CONNECTIONS_CONFIG env var (absolute path, set by integration configs)
+	//   2. config/connections.json relative to the executable's directory
+	//   3. config/connections.json relative to CWD (legacy fallback)
+	var connManager *connections.Manager
+	connectionsConfigPath := resolveConnectionsConfig()
+	if connectionsConfigPath != "" {
+		if cm, err := connections.NewManager(connectionsConfigPath); err == nil {
+			cm.SetSQLiteOptions(sqlite.OptionsFromConfig(cfg.Storage)...)
+			cm.SetPostgresOptions(postgres.OptionsFromConfig(cfg.Storage, cfg.LLM.EmbeddingDimension)...)
+			cm.SetStateMachine(cfg.Storage.StateMachine)
+			cm.SetDedupNormalization(cfg.Storage.DedupNormalization)
+			connManager = cm
+			slog.Debug("loaded connections config", "path", connectionsConfigPath)
+		} else {
+			slog.Warn("failed to load connections config", "path", connectionsConfigPath, "error", err)
+		}
+	}
+	if connManager == nil {
+		slog.Debug("using single-store mode", "data_path", cfg.Storage.DataPath)
+		connManager = connections.NewManagerWithStore(store, "default")
+	}
+
 	// Wrap the raw store in the MemoryEngine so that memories stored via MCP
 	// flow through the enrichment and decay pipeline.
 	//
-	// Worker count is provider-aware: Ollama serializes inference requests
+	// Worker count is backend- and provider-aware: SQLite takes one writer at
+	// a time, so it gets one worker, and Ollama serializes inference requests
 	// (single model loaded at a time), so concurrent workers just queue up
-	// and risk timeouts.  Cloud APIs (OpenAI, Anthropic) handle concurrency
-	// natively, so more workers improve throughput.
+	// and risk timeouts. Stores with row-level locking and cloud APIs
+	// (OpenAI, Anthropic) handle concurrency natively.
 	//
-	// MEMENTO_NUM_WORKERS env var overrides auto-detection.
+	// MEMENTO_ENRICHMENT_WORKERS (or the older MEMENTO_NUM_WORKERS)
+	// overrides auto-detection.
 	engineCfg := engine.DefaultConfig()
-	if override := os.Getenv("MEMENTO_NUM_WORKERS"); override != "" {
-		if n, err := strconv.Atoi(override); err == nil && n >= 1 {
-			engineCfg.NumWorkers = n
-			log.Printf("enrichment workers: %d (from MEMENTO_NUM_WORKERS)", n)
-		}
+	engineCfg.ApplyWorkerConfig(cfg.Storage, store)
+	if cfg.Storage.EnrichmentWorkers > 0 {
+		slog.Debug("enrichment workers from MEMENTO_ENRICHMENT_WORKERS", "workers", engineCfg.NumWorkers)
 	} else if cfg.LLM.LLMProvider == "ollama" {
 		engineCfg.NumWorkers = 1
-		log.Printf("enrichment workers: 1 (ollama provider — sequential to avoid contention)")
+		slog.Debug("enrichment workers: ollama provider, sequential to avoid contention", "workers", 1)
 	} else {
-		log.Printf("enrichment workers: %d (cloud provider: %s)", engineCfg.NumWorkers, cfg.LLM.LLMProvider)
+		slog.Debug("enrichment workers for backend", "workers", engineCfg.NumWorkers, "provider", cfg.LLM.LLMProvider)
+	}
+	engineCfg.DetectContradictions = cfg.Features.EnableContradictionEvents
+	engineCfg.EmbeddingDimension = cfg.LLM.EmbeddingDimension
+	engineCfg.EntityPrompt = cfg.LLM.EntityPrompt
+	engineCfg.RelationshipPrompt = cfg.LLM.RelationshipPrompt
+	if engineCfg.AutoArchive, err = engine.AutoArchivePolicyFromConfig(cfg.Storage); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := engineCfg.ApplyQueueConfig(cfg.Storage); err != nil {
+		log.Fatalf("%v", err)
 	}
+
+	// MEMENTO_LLM_TIMEOUT bounds each embedding / summarization call made on
+	// behalf of an MCP request so a stalled LLM backend cannot hang the client.
+	if err := engineCfg.ApplyLLMConfig(cfg.LLM); err != nil {
+		log.Fatalf("%v", err)
+	}
+	slog.Debug("LLM call timeout", "timeout", engineCfg.LLMTimeout)
 	memEngine, err := engine.NewMemoryEngine(store, engineCfg, cfg)
 	if err != nil {
 		log.Fatalf("failed to create memory engine: %v", err)
 	}
+	// Connections with enrichment_enabled=false are skipped by the engine's
+	// pending recovery, as store_memory never queues jobs for them.
+	memEngine.SetEnrichmentFilter(connManager.EnrichmentEnabled)
 	if err := memEngine.Start(ctx); err != nil {
 		log.Fatalf("failed to start memory engine: %v", err)
 	}
@@ -131,7 +239,7 @@ func main() {
 	eventWriter := notify.NewEventWriter(cfg.Storage.DataPath)
 	notifyEvent := func(eventType, memoryID string) {
 		if err := eventWriter.Notify(eventType, memoryID); err != nil {
-			log.Printf("notify: failed to write %s event for %s: %v", eventType, memoryID, err)
+			slog.Warn("notify: failed to write event", "event", eventType, "memory_id", memoryID, "error", err)
 		}
 	}
 	memEngine.SetOnMemoryCreated(func(memoryID string) {
@@ -140,46 +248,18 @@ func main() {
 	memEngine.SetOnEnrichmentStarted(func(memoryID string) {
 		notifyEvent("enrichment_started", memoryID)
 	})
-	memEngine.SetOnEnrichmentComplete(func(memoryID string) {
-		notifyEvent("enrichment_complete", memoryID)
+	memEngine.SetOnContradictionDetected(func(event engine.ContradictionEvent) {
+		if err := eventWriter.NotifyWithData("contradiction_detected", event.MemoryID, event); err != nil {
+			slog.Warn("notify: failed to write event", "event", "contradiction_detected", "memory_id", event.MemoryID, "error", err)
+		}
 	})
 
 	defer func() {
 		if err := memEngine.Shutdown(ctx); err != nil {
-			log.Printf("engine shutdown error: %v", err)
+			slog.Error("engine shutdown error", "error", err)
 		}
 	}()
 
-	// Load connections config so the MCP server can route connection_id to
-	// the right store and so that memory IDs get the correct domain segment.
-	//
-	// Priority order for finding connections.json:
-	//   1. MEMENTO_CONNECTIONS_CONFIG env var (absolute path, set by integration configs)
-	//   2. config/connections.json relative to the executable's directory
-	//   3. config/connections.json relative to CWD (legacy fallback)
-	var connManager *connections.Manager
-	connectionsConfigPath := resolveConnectionsConfig()
-	if connectionsConfigPath != "" {
-		if cm, err := connections.NewManager(connectionsConfigPath); err == nil {
-			connManager = cm
-			log.Printf("loaded connections config from %s", connectionsConfigPath)
-		} else {
-			log.Printf("warning: failed to load connections config from %s: %v", connectionsConfigPath, err)
-		}
-	}
-	if connManager == nil {
-		log.Printf("using single-store mode with MEMENTO_DATA_PATH=%s", cfg.Storage.DataPath)
-		connManager = connections.NewManagerWithStore(store, "default")
-	}
-
-	// Read optional default connection from env.
-	// MEMENTO_DEFAULT_CONNECTION pins the connection used when no connection_id
-	// is passed to any MCP tool call.  Useful for global or per-project defaults.
-	defaultConn := os.Getenv("MEMENTO_DEFAULT_CONNECTION")
-	if defaultConn != "" {
-		log.Printf("default connection: %s", defaultConn)
-	}
-
 	// Create the MCP server, injecting the store so that memories
 	// stored via MCP are accessible to the enrichment pipeline.
 	// Also inject the engine so store_memory and retry_enrichment can queue
@@ -188,22 +268,193 @@ func main() {
 		mcp.WithConfig(cfg),
 		mcp.WithConnectionManager(connManager),
 		mcp.WithEngine(memEngine),
+		// The connection used when no connection_id is passed: the one a
+		// .memento file in the working directory (or a parent) names, else
+		// MEMENTO_DEFAULT_CONNECTION.
+		mcp.WithDefaultConnectionResolver(func() string {
+			cwd, err := os.Getwd()
+			if err != nil {
+				slog.Warn("cannot read the working directory for a project config", "error", err)
+			}
+			name, source := resolveDefaultConnection(cwd)
+			if name != "" {
+				slog.Info("default connection selected", "connection", name, "source", source)
+			} else {
+				slog.Info("no default connection selected; using the connections config default")
+			}
+			return name
+		}),
 	}
-	if defaultConn != "" {
-		srvOpts = append(srvOpts, mcp.WithDefaultConnection(defaultConn))
+
+	// MEMENTO_READONLY rejects all mutating tools so a memory store can be
+	// shared with a reviewer without risk of modification.
+	if readOnly, _ := strconv.ParseBool(os.Getenv("MEMENTO_READONLY")); readOnly {
+		slog.Info("read-only mode: mutating tools are disabled")
+		srvOpts = append(srvOpts, mcp.WithReadOnly(true))
+	}
+	// MEMENTO_MCP_REQUEST_TIMEOUT puts an overall deadline on every request,
+	// which the stdio transport enforces even if a handler is stuck.
+	if override := os.Getenv("MEMENTO_MCP_REQUEST_TIMEOUT"); override != "" {
+		if d, err := time.ParseDuration(override); err == nil && d > 0 {
+			slog.Debug("request timeout from MEMENTO_MCP_REQUEST_TIMEOUT", "timeout", d)
+			srvOpts = append(srvOpts, mcp.WithRequestTimeout(d))
+		}
 	}
+	// MEMENTO_IDEMPOTENCY_TTL controls how long store_memory remembers an
+	// idempotency_key for client retries.
+	if override := os.Getenv("MEMENTO_IDEMPOTENCY_TTL"); override != "" {
+		if d, err := time.ParseDuration(override); err == nil && d > 0 {
+			slog.Debug("idempotency TTL from MEMENTO_IDEMPOTENCY_TTL", "ttl", d)
+			srvOpts = append(srvOpts, mcp.WithIdempotencyTTL(d))
+		}
+	}
+	// MEMENTO_SESSION_IDLE_TIMEOUT sets the idle gap after which the next stored
+	// memory starts a new session; 0 keeps one session per process.
+	if override := os.Getenv("MEMENTO_SESSION_IDLE_TIMEOUT"); override != "" {
+		if d, err := time.ParseDuration(override); err == nil && d >= 0 {
+			slog.Debug("session idle timeout from MEMENTO_SESSION_IDLE_TIMEOUT", "timeout", d)
+			srvOpts = append(srvOpts, mcp.WithSessionIdleTimeout(d))
+		}
+	}
+	// MEMENTO_SESSION_TTL caps how long a session lasts, so a busy
+	// long-running server still rolls over to a new session.
+	if override := os.Getenv("MEMENTO_SESSION_TTL"); override != "" {
+		if d, err := time.ParseDuration(override); err == nil && d >= 0 {
+			slog.Debug("session TTL from MEMENTO_SESSION_TTL", "ttl", d)
+			srvOpts = append(srvOpts, mcp.WithSessionTTL(d))
+		}
+	}
+	// MEMENTO_MEMORY_ID_SCHEME=opaque generates mem:<uuid> IDs that do not
+	// reveal the connection name; a route table maps them back to their
+	// connection. The first run backfills routes for existing memories.
+	switch cfg.Storage.MemoryIDScheme {
+	case config.MemoryIDSchemeDeterministic:
+	case config.MemoryIDSchemeOpaque:
+		routes, err := connections.OpenRouteTable(filepath.Join(cfg.Storage.DataPath, "memory_routes.db"))
+		if err != nil {
+			log.Fatalf("failed to open memory route table: %v", err)
+		}
+		defer func() { _ = routes.Close() }()
+		n, err := routes.Backfill(ctx, connManager)
+		if err != nil {
+			log.Fatalf("failed to backfill memory routes: %v", err)
+		}
+		if n > 0 {
+			slog.Info("memory routes: backfilled existing memories", "count", n)
+		}
+		// Routes name connections, so follow any renamed in connections.json.
+		n, err = routes.Reconcile(ctx, connManager)
+		if err != nil {
+			log.Fatalf("failed to reconcile memory routes: %v", err)
+		}
+		if n > 0 {
+			slog.Info("memory routes: followed renamed connections", "count", n)
+		}
+		slog.Debug("memory IDs: opaque (<prefix>:<uuid>)")
+		srvOpts = append(srvOpts, mcp.WithOpaqueIDs(routes))
+	default:
+		log.Fatalf("invalid MEMENTO_MEMORY_ID_SCHEME %q: must be %q or %q",
+			cfg.Storage.MemoryIDScheme, config.MemoryIDSchemeDeterministic, config.MemoryIDSchemeOpaque)
+	}
+	// MEMENTO_DETECT_ID_COLLISIONS keeps different content that hashes to a
+	// taken ID from being reported as a duplicate of it.
+	srvOpts = append(srvOpts, mcp.WithIDCollisionCheck(cfg.Storage.DetectIDCollisions))
+	// MEMENTO_MAX_CONTENT_LENGTH bounds store_memory content so a single huge
+	// memory cannot overflow the embedding model's context.
+	srvOpts = append(srvOpts, mcp.WithMaxContentLength(cfg.Storage.MaxContentLength))
+	// MEMENTO_NORMALIZE_UNICODE=false stores content without NFC normalization.
+	srvOpts = append(srvOpts, mcp.WithUnicodeNormalization(cfg.Storage.NormalizeUnicode))
+	// Semantic contradiction detection spends LLM calls, so it is opt-in.
+	srvOpts = append(srvOpts, mcp.WithSemanticContradictions(cfg.Features.EnableSemanticContradictions))
+	// get_embedding returns raw vectors for debugging search, so it is opt-in.
+	srvOpts = append(srvOpts, mcp.WithEmbeddingDebug(cfg.Features.EnableEmbeddingDebug))
+	// The audit log adds a write per mutating operation, so it is opt-in.
+	srvOpts = append(srvOpts, mcp.WithAuditLog(cfg.Features.EnableAuditLog))
+	// MEMENTO_DEDUP_NORMALIZATION lets near-identical content (case,
+	// whitespace, trailing punctuation) dedupe to the same memory ID.
+	switch cfg.Storage.DedupNormalization {
+	case config.DedupExact, config.DedupWhitespace, config.DedupNormalized:
+		srvOpts = append(srvOpts, mcp.WithDedupNormalization(cfg.Storage.DedupNormalization))
+	default:
+		log.Fatalf("invalid MEMENTO_DEDUP_NORMALIZATION %q: must be %q, %q or %q",
+			cfg.Storage.DedupNormalization, config.DedupExact, config.DedupWhitespace, config.DedupNormalized)
+	}
+	// MEMENTO_DEFAULT_LIMIT and MEMENTO_MAX_LIMIT set the page size used by
+	// the list and search tools and the most a single call may ask for.
+	srvOpts = append(srvOpts, mcp.WithResultLimits(cfg.Storage.DefaultLimit, cfg.Storage.MaxLimit))
+	srvOpts = append(srvOpts, mcp.WithTraversalBreadth(cfg.Storage.TraversalMaxBreadth))
+	srvOpts = append(srvOpts, mcp.WithFuzzySearch(cfg.Storage.FuzzyThreshold, cfg.Storage.FuzzyMaxResults))
+	if cfg.LLM.QueryExpansion {
+		srvOpts = append(srvOpts, mcp.WithQueryExpansion(cfg.LLM.QueryExpansionMaxTerms))
+	}
+	// MEMENTO_MEMORY_TEMPLATES_FILE adds templates for create_typed_memory.
+	srvOpts = append(srvOpts, mcp.WithMemoryTemplates(cfg.Storage.MemoryTemplates))
 	srv := mcp.NewServer(store, srvOpts...)
 
-	// Wrap the server in a StdioTransport that reads line-delimited JSON-RPC
-	// from stdin and writes responses to stdout.  All logging inside the
-	// transport is directed to stderr.
-	transport := mcp.NewStdioTransport(srv, os.Stdin, os.Stdout)
+	// Enrichment outcomes go to memento-web as events and, for MCP clients
+	// that opted in during initialize, out as JSON-RPC notifications so an
+	// agent knows when a memory is ready for semantic search.
+	memEngine.SetOnEnrichmentComplete(func(memoryID string) {
+		notifyEvent("enrichment_complete", memoryID)
+		srv.NotifyEnrichment(memoryID, types.StatusEnriched)
+	})
+	memEngine.SetOnEnrichmentFailed(func(memoryID string) {
+		notifyEvent("enrichment_failed", memoryID)
+		srv.NotifyEnrichment(memoryID, types.StatusFailed)
+	})
+
+	switch cfg.Server.MCPTransport {
+	case "stdio":
+		// Wrap the server in a StdioTransport that reads line-delimited
+		// JSON-RPC from stdin and writes responses to stdout.  All logging
+		// inside the transport is directed to stderr.
+		transport := mcp.NewStdioTransport(srv, os.Stdin, os.Stdout,
+			mcp.WithMaxRequestBytes(cfg.Server.MCPMaxRequestBytes))
 
-	log.Println("ready — serving JSON-RPC 2.0 on stdin/stdout")
+		slog.Info("ready — serving JSON-RPC 2.0 on stdin/stdout")
+
+		if err := transport.Serve(ctx); err != nil {
+			// A non-nil error here is normal (context cancellation) or indicates a
+			// fatal stdin/stdout problem.  Either way it is informational only.
+			slog.Info("transport stopped", "reason", err)
+		}
+	case "http":
+		serveHTTP(ctx, cfg, srv)
+	default:
+		log.Fatalf("invalid MEMENTO_MCP_TRANSPORT %q: must be \"stdio\" or \"http\"", cfg.Server.MCPTransport)
+	}
+}
+
+// serveHTTP serves srv to several clients over HTTP at /mcp on
+// MEMENTO_MCP_HTTP_ADDR until ctx is cancelled. When MEMENTO_API_TOKEN is
+// set, clients must send it as a bearer token.
+func serveHTTP(ctx context.Context, cfg *config.Config, srv *mcp.Server) {
+	var opts []mcp.HTTPTransportOption
+	if cfg.Security.APIToken != "" {
+		opts = append(opts, mcp.WithBearerToken(cfg.Security.APIToken))
+	} else {
+		slog.Warn("MEMENTO_API_TOKEN is not set; the MCP HTTP endpoint accepts unauthenticated requests", "addr", cfg.Server.MCPHTTPAddr)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewHTTPTransport(srv, opts...))
+	httpServer := &http.Server{
+		Addr:              cfg.Server.MCPHTTPAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		// Request contexts end with ctx, which closes open SSE streams.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
 
-	if err := transport.Serve(ctx); err != nil {
-		// A non-nil error here is normal (context cancellation) or indicates a
-		// fatal stdin/stdout problem.  Either way it is informational only.
-		log.Printf("transport stopped: %v", err)
+	slog.Info("ready — serving MCP over HTTP", "addr", cfg.Server.MCPHTTPAddr, "path", "/mcp")
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("MCP HTTP server failed: %v", err)
 	}
+	slog.Info("transport stopped", "reason", ctx.Err())
 }
@@ -151,20 +151,12 @@ func TestMCPMain_NumWorkersEnvironmentVariable(t *testing.T) {
 	t.Setenv("MEMENTO_LLM_BASE_URL", "https://api.openai.com/v1")
 	t.Setenv("MEMENTO_NUM_WORKERS", "8")
 
-	_, err = config.LoadConfig()
+	cfg, err := config.LoadConfig()
 	require.NoError(t, err)
 
-	// Test the logic from main()
+	// The override wins over the single-worker SQLite default.
 	engineCfg := engine.DefaultConfig()
-
-	// Simulate the override logic from main()
-	if override := os.Getenv("MEMENTO_NUM_WORKERS"); override != "" {
-		var n int
-		_, _ = fmt.Sscanf(override, "%d", &n)
-		if n >= 1 {
-			engineCfg.NumWorkers = n
-		}
-	}
+	engineCfg.ApplyWorkerConfig(cfg.Storage, store)
 
 	assert.Equal(t, 8, engineCfg.NumWorkers, "num workers should be overridden to 8")
 }
@@ -4,13 +4,16 @@ import (
 	"context"
 	"flag"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/connections"
 	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/internal/logging"
 	"github.com/scrypster/memento/internal/notify"
 	"github.com/scrypster/memento/internal/server"
 	"github.com/scrypster/memento/internal/storage"
@@ -22,23 +25,27 @@ func main() {
 	configPath := flag.String("config", "", "Path to connections config file (default: config/connections.json)")
 	flag.Parse()
 
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	// MEMENTO_LOG_LEVEL picks the leveled logger's threshold.
+	if err := logging.Setup(os.Stderr, cfg.Server.LogLevel); err != nil {
+		log.Fatalf("%v", err)
+	}
+
 	// If no config path specified, use default if it exists
 	if *configPath == "" {
 		defaultPath := "config/connections.json"
 		if _, err := os.Stat(defaultPath); err == nil {
 			*configPath = defaultPath
-			log.Printf("Using connections config: %s", defaultPath)
+			slog.Debug("using connections config", "path", defaultPath)
 		}
 	}
 
-	// Load configuration
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
-	}
-
 	// Initialize storage
-	store, err := sqlite.NewMemoryStore(cfg.Storage.DataPath + "/memento.db")
+	store, err := sqlite.NewMemoryStore(cfg.Storage.DataPath+"/memento.db", sqlite.OptionsFromConfig(cfg.Storage)...)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
@@ -50,12 +57,39 @@ func main() {
 
 	// Initialize memory engine for enrichment
 	engineCfg := engine.DefaultConfig()
-	engineCfg.NumWorkers = 1 // Use 1 worker for SQLite to avoid database locking
+	engineCfg.ApplyWorkerConfig(cfg.Storage, store)
+	engineCfg.DetectContradictions = cfg.Features.EnableContradictionEvents
+	engineCfg.EmbeddingDimension = cfg.LLM.EmbeddingDimension
+	engineCfg.EntityPrompt = cfg.LLM.EntityPrompt
+	engineCfg.RelationshipPrompt = cfg.LLM.RelationshipPrompt
+	if engineCfg.AutoArchive, err = engine.AutoArchivePolicyFromConfig(cfg.Storage); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := engineCfg.ApplyQueueConfig(cfg.Storage); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := engineCfg.ApplyLLMConfig(cfg.LLM); err != nil {
+		log.Fatalf("%v", err)
+	}
+	// MEMENTO_DIGEST_INTERVAL schedules digests; only the web server runs them.
+	if err := engineCfg.ApplyDigestConfig(cfg.Storage); err != nil {
+		log.Fatalf("%v", err)
+	}
 	memoryEngine, err := engine.NewMemoryEngine(store, engineCfg, cfg)
 	if err != nil {
 		log.Fatalf("Failed to initialize memory engine: %v", err)
 	}
 
+	// Skip pending memories of connections with enrichment_enabled=false,
+	// as memento-mcp does.
+	if *configPath != "" {
+		if cm, err := connections.NewManager(*configPath); err == nil {
+			memoryEngine.SetEnrichmentFilter(cm.EnrichmentEnabled)
+		} else {
+			slog.Warn("failed to load connections config", "path", *configPath, "error", err)
+		}
+	}
+
 	// Start enrichment workers
 	if err := memoryEngine.Start(ctx); err != nil {
 		log.Fatalf("Failed to start memory engine: %v", err)
@@ -63,7 +97,7 @@ func main() {
 
 	// Start server (pass memory engine for queue size reporting and optional config path)
 	addr, wsHub := server.Start(ctx, cfg, store, memoryEngine, *configPath)
-	log.Printf("Memento Web UI running at http://%s", addr)
+	slog.Info("Memento Web UI running", "url", "http://"+addr)
 
 	// Broadcast a lifecycle event over WebSocket
 	broadcastEvent := func(eventType, memoryID string) {
@@ -83,11 +117,31 @@ func main() {
 	memoryEngine.SetOnEnrichmentComplete(func(memoryID string) {
 		broadcastEvent("enrichment_complete", memoryID)
 	})
+	memoryEngine.SetOnEnrichmentFailed(func(memoryID string) {
+		broadcastEvent("enrichment_failed", memoryID)
+	})
+	memoryEngine.SetOnContradictionDetected(func(event engine.ContradictionEvent) {
+		wsHub.Broadcast(map[string]interface{}{
+			"type":     "contradiction_detected",
+			"memoryId": event.MemoryID,
+			"data":     event,
+		})
+	})
 
-	// Cross-process events (from memento-mcp via filesystem events)
-	eventWatcher := notify.NewEventWatcher(cfg.Storage.DataPath, broadcastEvent)
+	// Cross-process events (from memento-mcp via filesystem events).
+	// Payload-carrying events (e.g. contradiction_detected) forward their data.
+	eventWatcher := notify.NewEventWatcherWithData(cfg.Storage.DataPath, func(evt notify.Event) {
+		msg := map[string]interface{}{
+			"type":     evt.Type,
+			"memoryId": evt.MemoryID,
+		}
+		if len(evt.Data) > 0 {
+			msg["data"] = evt.Data
+		}
+		wsHub.Broadcast(msg)
+	})
 	if err := eventWatcher.Start(); err != nil {
-		log.Printf("WARNING: cross-process notifications disabled: %v", err)
+		slog.Warn("cross-process notifications disabled", "error", err)
 	}
 	defer eventWatcher.Stop()
 
@@ -96,11 +150,11 @@ func main() {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	<-sigChan
 
-	log.Println("Shutting down gracefully...")
+	slog.Info("shutting down gracefully")
 
 	// Shutdown enrichment workers first
 	if err := memoryEngine.Shutdown(ctx); err != nil {
-		log.Printf("Error shutting down memory engine: %v", err)
+		slog.Error("error shutting down memory engine", "error", err)
 	}
 
 	cancel()
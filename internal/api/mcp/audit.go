@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/scrypster/memento/internal/attribution"
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// WithAuditLog makes the server append an entry to the audit log of the
+// default store after every mutating tool call that succeeds (see
+// mutatingTools), naming the caller, the tool, the memories involved and
+// the connection written to. Stores without storage.AuditLog record
+// nothing. get_audit_log reads the log whether or not this is enabled.
+func WithAuditLog(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.auditLog = enabled
+	}
+}
+
+// auditArgs holds the arguments of a mutating tool that the audit log
+// records: its targets and, for store_memory, the declared author.
+type auditArgs struct {
+	writeTarget
+	CreatedBy  string `json:"created_by"`
+	AuthorType string `json:"author_type"`
+}
+
+// recordAudit appends an audit entry for a successful call of tool with
+// params that returned result. It is a no-op unless WithAuditLog is on and
+// tool is mutating. The operation has already happened, so a failure to
+// record it is logged rather than returned.
+func (s *Server) recordAudit(ctx context.Context, tool string, params, result interface{}) {
+	if !s.auditLog || !mutatingTools[tool] {
+		return
+	}
+	auditLog, ok := s.memoryStore.(storage.AuditLog)
+	if !ok {
+		return
+	}
+
+	var args auditArgs
+	if raw, err := json.Marshal(params); err == nil {
+		_ = json.Unmarshal(raw, &args)
+	}
+	entry := &storage.AuditEntry{
+		Tool:       tool,
+		Actor:      args.CreatedBy,
+		AuthorType: args.AuthorType,
+		MemoryIDs:  auditMemoryIDs(args.writeTarget, result),
+	}
+	if entry.Actor == "" {
+		actor, authorType := attribution.DetectAuthor()
+		entry.Actor, entry.AuthorType = actor, string(authorType)
+	} else if entry.AuthorType == "" {
+		entry.AuthorType = string(attribution.AuthorAgent)
+	}
+	if tool != "begin_session" && tool != "end_session" {
+		entry.Connection = args.connectionNames(ctx, s)[0]
+	}
+
+	// Record the entry even if the request deadline passed as the call
+	// finished.
+	if err := auditLog.AppendAuditEntry(context.WithoutCancel(ctx), entry); err != nil {
+		slog.Warn("failed to record audit entry", "tool", tool, "error", err)
+	}
+}
+
+// auditMemoryIDs returns the distinct memory IDs a mutating call involved:
+// those named in its arguments followed by those its result created or
+// changed.
+func auditMemoryIDs(target writeTarget, result interface{}) []string {
+	ids := target.memoryIDs()
+	switch r := result.(type) {
+	case *StoreMemoryResult:
+		ids = append(ids, r.ID)
+	case *EvolveMemoryResult:
+		ids = append(ids, r.NewID)
+	case *ConsolidateMemoriesResult:
+		ids = append(ids, r.NewID)
+		ids = append(ids, r.ConsolidatedIDs...)
+	case *CreateProjectResult:
+		ids = append(ids, r.ProjectID)
+		ids = append(ids, r.PhaseIDs...)
+	case *AddProjectItemResult:
+		ids = append(ids, r.ID)
+	case *MoveProjectItemResult:
+		ids = append(ids, r.OldParentID)
+	case *BatchUpdateStateResult:
+		// Only the memories actually changed; query matches are not in the
+		// arguments.
+		ids = ids[:0]
+		for _, u := range r.Results {
+			if u.Success {
+				ids = append(ids, u.ID)
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	distinct := []string{}
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+	return distinct
+}
+
+// GetAuditLog lists audit log entries of the default store, newest first,
+// filtered by memory, agent, tool and time range.
+func (s *Server) GetAuditLog(ctx context.Context, args GetAuditLogArgs) (*GetAuditLogResult, error) {
+	auditLog, ok := s.memoryStore.(storage.AuditLog)
+	if !ok {
+		return nil, fmt.Errorf("the audit log is not supported by this store")
+	}
+
+	filter := storage.AuditFilter{
+		MemoryID: args.MemoryID,
+		Actor:    args.Agent,
+		Tool:     args.Tool,
+		Limit:    s.effectiveLimit(args.Limit),
+	}
+	var err error
+	if filter.Since, err = parseAuditTime("since", args.Since); err != nil {
+		return nil, err
+	}
+	if filter.Until, err = parseAuditTime("until", args.Until); err != nil {
+		return nil, err
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Until.After(filter.Since) {
+		return nil, invalidParamsf("until (%s) must be after since (%s)", args.Until, args.Since)
+	}
+
+	entries, err := auditLog.ListAuditEntries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	return &GetAuditLogResult{
+		Entries: entries,
+		Total:   len(entries),
+		Limit:   filter.Limit,
+		Enabled: s.auditLog,
+	}, nil
+}
+
+// parseAuditTime parses an optional RFC-3339 get_audit_log bound.
+func parseAuditTime(field, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, invalidParamsf("%s: invalid RFC-3339 timestamp %q: %v", field, value, err)
+	}
+	return t, nil
+}
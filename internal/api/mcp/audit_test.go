@@ -0,0 +1,96 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog_StoreThenForget(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t, mcp.WithAuditLog(true), mcp.WithDefaultConnection("work"))
+	start := time.Now().Add(-time.Second)
+
+	var stored mcp.StoreMemoryResult
+	callRPC(t, srv, "store_memory", map[string]interface{}{
+		"content":     "Release checklist lives in the wiki",
+		"created_by":  "release-bot",
+		"author_type": "agent",
+	}, &stored)
+	var forgotten map[string]interface{}
+	callRPC(t, srv, "forget_memory", map[string]interface{}{"id": stored.ID}, &forgotten)
+
+	// Reads are not recorded.
+	var log mcp.GetAuditLogResult
+	callRPC(t, srv, "get_audit_log", map[string]interface{}{}, &log)
+	assert.True(t, log.Enabled)
+	require.Len(t, log.Entries, 2)
+	assert.Equal(t, 2, log.Total)
+
+	forget, store := log.Entries[0], log.Entries[1]
+	assert.Greater(t, forget.ID, store.ID)
+	assert.False(t, forget.Timestamp.Before(store.Timestamp))
+	assert.True(t, store.Timestamp.After(start))
+
+	assert.Equal(t, "store_memory", store.Tool)
+	assert.Equal(t, "release-bot", store.Actor)
+	assert.Equal(t, "agent", store.AuthorType)
+	assert.Equal(t, []string{stored.ID}, store.MemoryIDs)
+	assert.Equal(t, "work", store.Connection)
+
+	assert.Equal(t, "forget_memory", forget.Tool)
+	assert.NotEmpty(t, forget.Actor)
+	assert.Equal(t, []string{stored.ID}, forget.MemoryIDs)
+	assert.Equal(t, "work", forget.Connection)
+
+	// Filters.
+	ctx := context.Background()
+	byAgent, err := srv.GetAuditLog(ctx, mcp.GetAuditLogArgs{Agent: "release-bot"})
+	require.NoError(t, err)
+	require.Len(t, byAgent.Entries, 1)
+	assert.Equal(t, "store_memory", byAgent.Entries[0].Tool)
+
+	byMemory, err := srv.GetAuditLog(ctx, mcp.GetAuditLogArgs{MemoryID: stored.ID})
+	require.NoError(t, err)
+	assert.Len(t, byMemory.Entries, 2)
+
+	future, err := srv.GetAuditLog(ctx, mcp.GetAuditLogArgs{Since: time.Now().Add(time.Hour).Format(time.RFC3339)})
+	require.NoError(t, err)
+	assert.Empty(t, future.Entries)
+
+	_, err = srv.GetAuditLog(ctx, mcp.GetAuditLogArgs{Since: "yesterday"})
+	require.Error(t, err)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv,
+		`{"jsonrpc":"2.0","method":"get_audit_log","params":{"since":"2026-01-02T00:00:00Z","until":"2026-01-01T00:00:00Z"},"id":1}`))
+}
+
+func TestAuditLog_DisabledRecordsNothing(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t)
+
+	var stored mcp.StoreMemoryResult
+	callRPC(t, srv, "store_memory", map[string]interface{}{"content": "Unaudited note"}, &stored)
+
+	var log mcp.GetAuditLogResult
+	callRPC(t, srv, "get_audit_log", map[string]interface{}{}, &log)
+	assert.False(t, log.Enabled)
+	assert.Empty(t, log.Entries)
+}
+
+func TestAuditLog_SupersedeRecordsBothMemories(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t, mcp.WithAuditLog(true))
+
+	var older, newer mcp.StoreMemoryResult
+	callRPC(t, srv, "store_memory", map[string]interface{}{"content": "Deploys run on Fridays"}, &older)
+	callRPC(t, srv, "store_memory", map[string]interface{}{"content": "Deploys run on Tuesdays"}, &newer)
+	var superseded mcp.SupersedeMemoryResult
+	callRPC(t, srv, "supersede_memory", map[string]interface{}{"old_id": older.ID, "new_id": newer.ID}, &superseded)
+
+	var log mcp.GetAuditLogResult
+	callRPC(t, srv, "get_audit_log", map[string]interface{}{}, &log)
+	require.NotEmpty(t, log.Entries)
+	assert.Equal(t, "supersede_memory", log.Entries[0].Tool)
+	assert.Equal(t, []string{older.ID, newer.ID}, log.Entries[0].MemoryIDs)
+}
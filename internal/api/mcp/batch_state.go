@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// batch_update_state bounds.
+const (
+	// MaxBatchStateIDs is the most memories one batch_update_state call
+	// transitions.
+	MaxBatchStateIDs = 100
+	// DefaultBatchStateQueryLimit is how many search results a query-based
+	// batch_update_state transitions when no limit is given.
+	DefaultBatchStateQueryLimit = 10
+)
+
+// BatchUpdateState transitions many memories of one connection to the same
+// lifecycle state. Each transition is validated as in update_memory_state;
+// one that fails is reported in its result without stopping the others.
+// Stores that implement storage.StateBatchUpdater apply the batch in one
+// transaction.
+func (s *Server) BatchUpdateState(ctx context.Context, args BatchUpdateStateArgs) (*BatchUpdateStateResult, error) {
+	if args.State == "" {
+		return nil, invalidParamsf("state is required")
+	}
+	if (len(args.IDs) == 0) == (args.Query == "") {
+		return nil, invalidParamsf("exactly one of ids or query is required")
+	}
+	if len(args.IDs) > MaxBatchStateIDs {
+		return nil, invalidParamsf("at most %d ids may be updated at once, got %d", MaxBatchStateIDs, len(args.IDs))
+	}
+
+	// The batch runs on one connection: the one named, else the one owning
+	// the first ID.
+	conn := args.ConnectionID
+	if conn == "" && len(args.IDs) > 0 {
+		conn = s.connectionForID(ctx, args.IDs[0])
+	}
+	store, searchProvider := s.resolveSearchStore(conn)
+	if conn == "" {
+		conn = s.defaultConnection
+	}
+	if !stateMachineOf(store).IsValidState(args.State) {
+		return nil, invalidParamsf("invalid state: %s", args.State)
+	}
+
+	ids := args.IDs
+	if args.Query != "" {
+		if searchProvider == nil {
+			return nil, fmt.Errorf("search is not available in this configuration; please provide explicit ids instead")
+		}
+		limit := args.Limit
+		if limit <= 0 {
+			limit = DefaultBatchStateQueryLimit
+		}
+		limit = min(limit, MaxBatchStateIDs)
+		found, err := searchProvider.FullTextSearch(ctx, storage.SearchOptions{Query: args.Query, Limit: limit})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for memories: %w", err)
+		}
+		for _, m := range found.Items {
+			ids = append(ids, m.ID)
+		}
+	}
+
+	results := make([]BatchStateResult, len(ids))
+	var batch []string // IDs in conn, in order
+	var positions []int
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		results[i] = BatchStateResult{ID: id}
+		idConn := s.connectionForID(ctx, id)
+		if idConn == "" {
+			idConn = s.defaultConnection
+		}
+		switch {
+		case id == "":
+			results[i].Error = "memory ID is required"
+		case seen[id]:
+			results[i].Error = "duplicate ID"
+		case idConn != conn:
+			results[i].Error = fmt.Sprintf("memory is in connection %q, not %q", idConn, conn)
+		default:
+			batch = append(batch, id)
+			positions = append(positions, i)
+		}
+		seen[id] = true
+	}
+
+	updates, err := s.updateStates(ctx, store, batch, args.State)
+	if err != nil {
+		return nil, err
+	}
+	result := &BatchUpdateStateResult{State: args.State, Results: results}
+	for j, u := range updates {
+		r := &results[positions[j]]
+		r.PreviousState = u.PreviousState
+		switch {
+		case u.Err == nil:
+			r.Success = true
+		case errors.Is(u.Err, storage.ErrNotFound):
+			r.Error = "memory not found"
+		default:
+			r.Error = u.Err.Error()
+		}
+	}
+	for _, r := range results {
+		if r.Success {
+			result.Updated++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// updateStates applies the transitions in one transaction when store
+// supports it and one at a time otherwise.
+func (s *Server) updateStates(ctx context.Context, store storage.MemoryStore, ids []string, state string) ([]storage.StateUpdate, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if batcher, ok := store.(storage.StateBatchUpdater); ok {
+		updates, err := batcher.UpdateStates(ctx, ids, state)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update states: %w", err)
+		}
+		return updates, nil
+	}
+
+	updates := make([]storage.StateUpdate, 0, len(ids))
+	for _, id := range ids {
+		update := storage.StateUpdate{ID: id}
+		if mem, err := store.Get(ctx, id); err != nil {
+			update.Err = err
+		} else {
+			update.PreviousState = mem.State
+			update.Err = store.UpdateState(ctx, id, state)
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
@@ -0,0 +1,122 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBatchStateStore returns a server over a store holding one memory per
+// entry of states, keyed by its ID.
+func newBatchStateStore(t *testing.T, states map[string]string) (*mcp.Server, *sqlite.MemoryStore) {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	for id, state := range states {
+		require.NoError(t, store.Store(context.Background(), &types.Memory{
+			ID: id, Content: "Quarterly report task " + id, Source: "test",
+			State: state, Status: types.StatusEnriched,
+		}))
+	}
+	return mcp.NewServer(store), store
+}
+
+func TestBatchUpdateState_ReportsEachID(t *testing.T) {
+	srv, store := newBatchStateStore(t, map[string]string{
+		"mem:test:draft":   types.StateActive,
+		"mem:test:review":  types.StateActive,
+		"mem:test:planned": types.StatePlanning,
+	})
+	ctx := context.Background()
+
+	var result mcp.BatchUpdateStateResult
+	callRPC(t, srv, "batch_update_state", map[string]interface{}{
+		"ids":   []string{"mem:test:draft", "mem:test:planned", "mem:test:missing", "mem:test:review", "mem:test:draft"},
+		"state": types.StateCompleted,
+	}, &result)
+
+	assert.Equal(t, types.StateCompleted, result.State)
+	assert.Equal(t, 2, result.Updated)
+	assert.Equal(t, 3, result.Failed)
+	require.Len(t, result.Results, 5)
+
+	draft, planned, missing, review, duplicate := result.Results[0], result.Results[1], result.Results[2], result.Results[3], result.Results[4]
+	assert.True(t, draft.Success)
+	assert.Equal(t, types.StateActive, draft.PreviousState)
+	assert.True(t, review.Success)
+
+	assert.False(t, planned.Success)
+	assert.Equal(t, types.StatePlanning, planned.PreviousState)
+	assert.Contains(t, planned.Error, "invalid state transition")
+	assert.False(t, missing.Success)
+	assert.Equal(t, "memory not found", missing.Error)
+	assert.False(t, duplicate.Success)
+	assert.Equal(t, "duplicate ID", duplicate.Error)
+
+	for id, want := range map[string]string{
+		"mem:test:draft":   types.StateCompleted,
+		"mem:test:review":  types.StateCompleted,
+		"mem:test:planned": types.StatePlanning,
+	} {
+		mem, err := store.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, want, mem.State, id)
+	}
+}
+
+func TestBatchUpdateState_ByQuery(t *testing.T) {
+	srv, store := newBatchStateStore(t, map[string]string{
+		"mem:test:one": types.StateActive,
+		"mem:test:two": types.StateActive,
+	})
+	ctx := context.Background()
+
+	result, err := srv.BatchUpdateState(ctx, mcp.BatchUpdateStateArgs{Query: "quarterly", State: types.StatePaused})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Updated)
+	for _, id := range []string{"mem:test:one", "mem:test:two"} {
+		mem, err := store.Get(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, types.StatePaused, mem.State)
+	}
+}
+
+func TestBatchUpdateState_InvalidArgs(t *testing.T) {
+	srv, _ := newBatchStateStore(t, nil)
+
+	for _, params := range []string{
+		`{"ids":["mem:test:a"]}`,
+		`{"ids":["mem:test:a"],"state":"finished"}`,
+		`{"state":"completed"}`,
+		`{"ids":["mem:test:a"],"query":"report","state":"completed"}`,
+	} {
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv,
+			`{"jsonrpc":"2.0","method":"batch_update_state","params":`+params+`,"id":1}`), params)
+	}
+}
+
+func TestBatchUpdateState_ScopedToOneConnection(t *testing.T) {
+	srv := newReadOnlyConnectionServer(t)
+	ctx := context.Background()
+
+	task, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Close the sprint", ConnectionID: "work"})
+	require.NoError(t, err)
+
+	// Called directly, bypassing the dispatcher's read-only check, so the
+	// archive ID reaches the connection scoping.
+	result, err := srv.BatchUpdateState(ctx, mcp.BatchUpdateStateArgs{
+		IDs:   []string{task.ID, "mem:archive:one"},
+		State: types.StatePlanning,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Results, 2)
+	assert.True(t, result.Results[0].Success, result.Results[0].Error)
+	assert.False(t, result.Results[1].Success)
+	assert.Contains(t, result.Results[1].Error, `connection "archive"`)
+}
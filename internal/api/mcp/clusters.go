@@ -0,0 +1,404 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// Bounds of cluster_memories.
+const (
+	defaultClusterMemories = 200
+	maxClusterMemories     = 1000
+	maxClusters            = 20
+	// clusterRepresentatives is how many memories closest to its centre
+	// are returned for each cluster and shown to the LLM to label it.
+	clusterRepresentatives = 3
+	// kmeansIterations bounds the refinement rounds of k-means.
+	kmeansIterations = 50
+)
+
+// Grouping methods reported by ClusterMemoriesResult.Method.
+const (
+	ClusterMethodEmbeddings = "embeddings"
+	ClusterMethodTags       = "tags"
+)
+
+// ClusterMemories groups the newest memories of a connection into topics,
+// for an overview of a large workspace. Memories are clustered by their
+// stored embeddings with k-means; when fewer than two have one (e.g.
+// without an embedding model) they are grouped by their most common tag
+// instead. Each cluster is labelled by the LLM engine if there is one, and
+// from its tags otherwise.
+func (s *Server) ClusterMemories(ctx context.Context, args ClusterMemoriesArgs) (*ClusterMemoriesResult, error) {
+	if args.Clusters < 0 || args.Clusters > maxClusters {
+		return nil, invalidParamsf("clusters must be between 1 and %d (0 picks a count)", maxClusters)
+	}
+	if args.MaxMemories < 0 {
+		return nil, invalidParamsf("max_memories must not be negative")
+	}
+	maxMemories := args.MaxMemories
+	if maxMemories == 0 {
+		maxMemories = defaultClusterMemories
+	}
+	maxMemories = min(maxMemories, maxClusterMemories)
+	createdAfter, createdBefore, err := parseTimeRange(args.CreatedAfter, args.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	store, _ := s.resolveSearchStore(args.ConnectionID)
+	opts := storage.ListOptions{
+		Limit:         storage.MaxLimit,
+		SortBy:        "created_at",
+		SortOrder:     "desc",
+		MemoryType:    args.MemoryType,
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+	}
+	var memories []types.Memory
+	total := 0
+	for page := 1; len(memories) < maxMemories; page++ {
+		opts.Page = page
+		opts.Normalize()
+		result, err := store.List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list memories: %w", err)
+		}
+		total = result.Total
+		memories = append(memories, result.Items...)
+		if !result.HasMore || len(result.Items) == 0 {
+			break
+		}
+	}
+	if len(memories) > maxMemories {
+		memories = memories[:maxMemories]
+	}
+
+	result := &ClusterMemoriesResult{
+		Memories:    len(memories),
+		Total:       total,
+		Clusters:    []MemoryCluster{},
+		Unclustered: []string{},
+	}
+	vectors, unclustered := s.clusterEmbeddings(ctx, store, memories)
+	if len(vectors) >= 2 {
+		result.Method = ClusterMethodEmbeddings
+		result.Unclustered = unclustered
+		result.Clusters = clusterByEmbedding(memories, vectors, args.Clusters)
+	} else {
+		result.Method = ClusterMethodTags
+		result.Clusters = clusterByTag(memories, args.Clusters)
+	}
+	for i := range result.Clusters {
+		s.labelCluster(ctx, &result.Clusters[i])
+	}
+	return result, nil
+}
+
+// clusterEmbeddings returns the stored embeddings of memories by memory ID,
+// and the IDs of the memories without one. Embeddings whose dimension
+// differs from the most common one, left over from an earlier embedding
+// model, count as missing.
+func (s *Server) clusterEmbeddings(ctx context.Context, store storage.MemoryStore, memories []types.Memory) (map[string][]float64, []string) {
+	vectors := make(map[string][]float64, len(memories))
+	dimensions := make(map[int]int)
+	for _, m := range memories {
+		vec, _, err := store.GetEmbedding(ctx, m.ID)
+		if err != nil || normalized(vec) == nil {
+			continue
+		}
+		vectors[m.ID] = vec
+		dimensions[len(vec)]++
+	}
+	dimension := 0
+	for d, n := range dimensions {
+		if n > dimensions[dimension] || (n == dimensions[dimension] && d > dimension) {
+			dimension = d
+		}
+	}
+	missing := []string{}
+	for _, m := range memories {
+		if vec, ok := vectors[m.ID]; !ok || len(vec) != dimension {
+			delete(vectors, m.ID)
+			missing = append(missing, m.ID)
+		}
+	}
+	return vectors, missing
+}
+
+// clusterCount returns k when set, and otherwise about sqrt(n/2), the usual
+// rule of thumb, within 2 and maxClusters. It never exceeds n.
+func clusterCount(k, n int) int {
+	if k == 0 {
+		k = max(2, min(maxClusters, int(math.Round(math.Sqrt(float64(n)/2)))))
+	}
+	return min(k, n)
+}
+
+// clusterByEmbedding clusters the memories with an embedding in vectors by
+// spherical k-means (cosine similarity), largest cluster first.
+func clusterByEmbedding(memories []types.Memory, vectors map[string][]float64, k int) []MemoryCluster {
+	var points []types.Memory
+	var unit [][]float64
+	for _, m := range memories {
+		if vec, ok := vectors[m.ID]; ok {
+			points = append(points, m)
+			unit = append(unit, normalized(vec))
+		}
+	}
+	k = clusterCount(k, len(points))
+	assignment, centroids := kmeans(unit, k)
+
+	clusters := make([]MemoryCluster, k)
+	members := make([][]ClusterMember, k)
+	for i, c := range assignment {
+		clusters[c].MemoryIDs = append(clusters[c].MemoryIDs, points[i].ID)
+		members[c] = append(members[c], ClusterMember{
+			ID:         points[i].ID,
+			Label:      graphLabel(points[i].Content),
+			Similarity: dot(unit[i], centroids[c]),
+			Tags:       points[i].Tags,
+		})
+	}
+	for c := range clusters {
+		sort.SliceStable(members[c], func(i, j int) bool { return members[c][i].Similarity > members[c][j].Similarity })
+		finishCluster(&clusters[c], members[c])
+	}
+	return sortClusters(clusters)
+}
+
+// kmeans partitions unit vectors into k clusters, seeding the centroids
+// with k-means++ from a fixed seed so the same memories always cluster
+// the same way. It returns each point's cluster and the cluster centroids.
+func kmeans(points [][]float64, k int) ([]int, [][]float64) {
+	rng := rand.New(rand.NewPCG(1, uint64(len(points))))
+	centroids := [][]float64{points[rng.IntN(len(points))]}
+	distances := make([]float64, len(points))
+	for len(centroids) < k {
+		var sum float64
+		for i, p := range points {
+			distances[i] = math.MaxFloat64
+			for _, c := range centroids {
+				distances[i] = min(distances[i], 1-dot(p, c))
+			}
+			distances[i] = max(distances[i], 0)
+			sum += distances[i]
+		}
+		next := 0
+		if sum > 0 {
+			target := rng.Float64() * sum
+			for next = 0; next < len(points)-1; next++ {
+				if target -= distances[next]; target <= 0 {
+					break
+				}
+			}
+		} else {
+			next = len(centroids) // all points coincide with a centroid
+		}
+		centroids = append(centroids, points[next])
+	}
+
+	assignment := make([]int, len(points))
+	for iteration := 0; iteration < kmeansIterations; iteration++ {
+		changed := iteration == 0
+		for i, p := range points {
+			best := 0
+			for c := 1; c < k; c++ {
+				if dot(p, centroids[c]) > dot(p, centroids[best]) {
+					best = c
+				}
+			}
+			if assignment[i] != best {
+				assignment[i], changed = best, true
+			}
+		}
+		if !changed {
+			break
+		}
+		sums := make([][]float64, k)
+		for c := range sums {
+			sums[c] = make([]float64, len(points[0]))
+		}
+		for i, p := range points {
+			for d, v := range p {
+				sums[assignment[i]][d] += v
+			}
+		}
+		for c := range centroids {
+			// An emptied cluster keeps its old centroid.
+			if n := normalized(sums[c]); n != nil {
+				centroids[c] = n
+			}
+		}
+	}
+	return assignment, centroids
+}
+
+// clusterByTag groups memories by the tag they have that is most common
+// among all of them, keeping the k-1 largest groups (k chosen as for
+// embeddings) and merging the rest, untagged memories included, into one.
+func clusterByTag(memories []types.Memory, k int) []MemoryCluster {
+	if len(memories) == 0 {
+		return []MemoryCluster{}
+	}
+	k = clusterCount(k, len(memories))
+	frequency := make(map[string]int)
+	for _, m := range memories {
+		for _, t := range m.Tags {
+			frequency[t]++
+		}
+	}
+	groups := make(map[string][]types.Memory)
+	var order []string
+	for _, m := range memories {
+		tag := ""
+		for _, t := range m.Tags {
+			if tag == "" || frequency[t] > frequency[tag] || (frequency[t] == frequency[tag] && t < tag) {
+				tag = t
+			}
+		}
+		if _, ok := groups[tag]; !ok {
+			order = append(order, tag)
+		}
+		groups[tag] = append(groups[tag], m)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i] == "" || order[j] == "" {
+			return order[j] == ""
+		}
+		return len(groups[order[i]]) > len(groups[order[j]])
+	})
+
+	var clusters []MemoryCluster
+	var rest []types.Memory
+	for i, tag := range order {
+		if tag == "" || (i >= k-1 && len(order) > k) {
+			rest = append(rest, groups[tag]...)
+			continue
+		}
+		clusters = append(clusters, tagCluster(groups[tag]))
+	}
+	if len(rest) > 0 {
+		cluster := tagCluster(rest)
+		if len(cluster.Tags) > 0 {
+			cluster.Label = "other"
+		} else {
+			cluster.Label = "untagged"
+		}
+		cluster.LabelSource = "tags"
+		clusters = append(clusters, cluster)
+	}
+	return sortClusters(clusters)
+}
+
+// tagCluster builds a cluster of memories, newest first, whose
+// representatives are the newest ones.
+func tagCluster(memories []types.Memory) MemoryCluster {
+	var cluster MemoryCluster
+	members := make([]ClusterMember, len(memories))
+	for i, m := range memories {
+		cluster.MemoryIDs = append(cluster.MemoryIDs, m.ID)
+		members[i] = ClusterMember{ID: m.ID, Label: graphLabel(m.Content), Tags: m.Tags}
+	}
+	finishCluster(&cluster, members)
+	return cluster
+}
+
+// finishCluster sets the size, top tags and representatives of cluster
+// from its members, best representative first, and a label from its tags
+// or, failing that, its best representative (replaced by the LLM's label
+// in labelCluster if there is an engine).
+func finishCluster(cluster *MemoryCluster, members []ClusterMember) {
+	cluster.Size = len(members)
+	frequency := make(map[string]int)
+	for _, m := range members {
+		for _, t := range m.Tags {
+			frequency[t]++
+		}
+	}
+	for t := range frequency {
+		cluster.Tags = append(cluster.Tags, t)
+	}
+	sort.Slice(cluster.Tags, func(i, j int) bool {
+		a, b := cluster.Tags[i], cluster.Tags[j]
+		return frequency[a] > frequency[b] || (frequency[a] == frequency[b] && a < b)
+	})
+	cluster.Tags = cluster.Tags[:min(len(cluster.Tags), 5)]
+	if cluster.Tags == nil {
+		cluster.Tags = []string{}
+	}
+
+	cluster.Representatives = members[:min(len(members), clusterRepresentatives)]
+	switch {
+	case len(cluster.Tags) > 0:
+		cluster.Label = strings.Join(cluster.Tags[:min(len(cluster.Tags), 3)], ", ")
+		cluster.LabelSource = "tags"
+	case len(members) > 0:
+		cluster.Label = members[0].Label
+		cluster.LabelSource = "content"
+	}
+}
+
+// sortClusters orders clusters largest first.
+func sortClusters(clusters []MemoryCluster) []MemoryCluster {
+	clusters = slices.DeleteFunc(clusters, func(c MemoryCluster) bool { return c.Size == 0 })
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Size > clusters[j].Size })
+	return clusters
+}
+
+// labelCluster asks the LLM engine for a short topic label for cluster
+// from its representatives. Without an engine, or if the call fails, the
+// label from its tags is kept.
+func (s *Server) labelCluster(ctx context.Context, cluster *MemoryCluster) {
+	if s.engine == nil || len(cluster.Representatives) == 0 {
+		return
+	}
+	var sources strings.Builder
+	for i, m := range cluster.Representatives {
+		fmt.Fprintf(&sources, "[%d] %s\n", i+1, m.Label)
+	}
+	prompt := fmt.Sprintf(`These memories belong to one topic:
+
+%s
+Name the topic in 2 to 5 words. Respond with only the name, no quotes or punctuation.`, sources.String())
+	label, err := s.engine.Summarize(ctx, prompt)
+	if err != nil {
+		return
+	}
+	label, _, _ = strings.Cut(strings.TrimSpace(label), "\n")
+	label = strings.Trim(strings.TrimSpace(label), `"'.`)
+	if label == "" {
+		return
+	}
+	cluster.Label = graphLabel(label)
+	cluster.LabelSource = "llm"
+}
+
+// normalized returns v scaled to unit length, or nil if v is zero.
+func normalized(v []float64) []float64 {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return nil
+	}
+	out := make([]float64, len(v))
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+func dot(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,158 @@
+package mcp_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clusterFixture is a memory of the cluster tests; a nil vector leaves it
+// without an embedding.
+type clusterFixture struct {
+	id     string
+	tags   []string
+	vector []float64
+}
+
+func newClusterStore(t *testing.T, fixtures []clusterFixture) *sqlite.MemoryStore {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+	for i, f := range fixtures {
+		created := base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, store.Store(ctx, &types.Memory{
+			ID: f.id, Content: f.id + " notes", Source: "test", Tags: f.tags,
+			CreatedAt: created, UpdatedAt: created, Timestamp: created,
+		}))
+		if f.vector != nil {
+			require.NoError(t, store.SetEmbedding(ctx, f.id, f.vector, "test-model"))
+		}
+	}
+	return store
+}
+
+// clusterMembers returns the sorted memory IDs of each cluster.
+func clusterMembers(clusters []mcp.MemoryCluster) [][]string {
+	var members [][]string
+	for _, c := range clusters {
+		members = append(members, c.MemoryIDs)
+	}
+	return members
+}
+
+func TestClusterMemories_Embeddings(t *testing.T) {
+	store := newClusterStore(t, []clusterFixture{
+		{"mem:general:kestrel", []string{"birds"}, []float64{1, 0.1, 0}},
+		{"mem:general:budget", []string{"finance"}, []float64{0, 1, 0.1}},
+		{"mem:general:falcon", []string{"birds"}, []float64{0.9, 0, 0.1}},
+		{"mem:general:invoice", []string{"finance"}, []float64{0.1, 0.9, 0}},
+		{"mem:general:osprey", []string{"birds", "coast"}, []float64{1, 0, 0}},
+		{"mem:general:pending", nil, nil},
+	})
+	srv := mcp.NewServer(store)
+
+	var result mcp.ClusterMemoriesResult
+	callRPC(t, srv, "cluster_memories", map[string]interface{}{"clusters": 2}, &result)
+	assert.Equal(t, mcp.ClusterMethodEmbeddings, result.Method)
+	assert.Equal(t, 6, result.Memories)
+	assert.Equal(t, []string{"mem:general:pending"}, result.Unclustered)
+	require.Len(t, result.Clusters, 2)
+
+	birds, finance := result.Clusters[0], result.Clusters[1]
+	assert.ElementsMatch(t, []string{"mem:general:kestrel", "mem:general:falcon", "mem:general:osprey"}, birds.MemoryIDs)
+	assert.ElementsMatch(t, []string{"mem:general:budget", "mem:general:invoice"}, finance.MemoryIDs)
+	assert.Equal(t, 3, birds.Size)
+	assert.Equal(t, []string{"birds", "coast"}, birds.Tags)
+	assert.Equal(t, "birds, coast", birds.Label)
+	assert.Equal(t, "tags", birds.LabelSource)
+	require.Len(t, birds.Representatives, 3)
+	for i := 1; i < len(birds.Representatives); i++ {
+		assert.GreaterOrEqual(t, birds.Representatives[i-1].Similarity, birds.Representatives[i].Similarity, "representatives closest to the centre first")
+	}
+
+	var again mcp.ClusterMemoriesResult
+	callRPC(t, srv, "cluster_memories", map[string]interface{}{"clusters": 2}, &again)
+	assert.Equal(t, clusterMembers(result.Clusters), clusterMembers(again.Clusters), "clustering is deterministic")
+}
+
+func TestClusterMemories_LLMLabels(t *testing.T) {
+	store := newClusterStore(t, []clusterFixture{
+		{"mem:general:kestrel", nil, []float64{1, 0}},
+		{"mem:general:budget", nil, []float64{0, 1}},
+	})
+	eng := &summarizingEngine{response: "\"Birds of prey.\"\nextra"}
+	srv := mcp.NewServer(store, mcp.WithEngine(eng))
+
+	var result mcp.ClusterMemoriesResult
+	callRPC(t, srv, "cluster_memories", map[string]interface{}{}, &result)
+	require.Len(t, result.Clusters, 2)
+	for _, c := range result.Clusters {
+		assert.Equal(t, "Birds of prey", c.Label)
+		assert.Equal(t, "llm", c.LabelSource)
+	}
+	assert.Contains(t, eng.prompt, "notes")
+}
+
+// TestClusterMemories_TagFallback verifies that memories without
+// embeddings are grouped by their most common tag.
+func TestClusterMemories_TagFallback(t *testing.T) {
+	store := newClusterStore(t, []clusterFixture{
+		{"mem:general:kestrel", []string{"birds"}, nil},
+		{"mem:general:falcon", []string{"birds", "coast"}, nil},
+		{"mem:general:budget", []string{"finance"}, nil},
+		{"mem:general:invoice", []string{"finance", "travel"}, nil},
+		{"mem:general:osprey", []string{"birds"}, nil},
+		{"mem:general:misc", nil, nil},
+	})
+	srv := mcp.NewServer(store)
+
+	var result mcp.ClusterMemoriesResult
+	callRPC(t, srv, "cluster_memories", map[string]interface{}{"clusters": 3}, &result)
+	assert.Equal(t, mcp.ClusterMethodTags, result.Method)
+	assert.Empty(t, result.Unclustered)
+	require.Len(t, result.Clusters, 3)
+	assert.Equal(t, "birds, coast", result.Clusters[0].Label)
+	assert.ElementsMatch(t, []string{"mem:general:kestrel", "mem:general:falcon", "mem:general:osprey"}, result.Clusters[0].MemoryIDs)
+	assert.Equal(t, "mem:general:osprey", result.Clusters[0].Representatives[0].ID, "newest first")
+	assert.ElementsMatch(t, []string{"mem:general:budget", "mem:general:invoice"}, result.Clusters[1].MemoryIDs)
+	assert.Equal(t, "untagged", result.Clusters[2].Label)
+	assert.Equal(t, []string{"mem:general:misc"}, result.Clusters[2].MemoryIDs)
+
+	callRPC(t, srv, "cluster_memories", map[string]interface{}{"clusters": 2}, &result)
+	require.Len(t, result.Clusters, 2)
+	assert.Equal(t, "other", result.Clusters[1].Label, "smaller groups are merged")
+	assert.Equal(t, 3, result.Clusters[1].Size)
+}
+
+func TestClusterMemories_Bounds(t *testing.T) {
+	var fixtures []clusterFixture
+	for i := 0; i < 5; i++ {
+		fixtures = append(fixtures, clusterFixture{id: fmt.Sprintf("mem:general:m%d", i), vector: []float64{1, float64(i)}})
+	}
+	srv := mcp.NewServer(newClusterStore(t, fixtures))
+
+	var result mcp.ClusterMemoriesResult
+	callRPC(t, srv, "cluster_memories", map[string]interface{}{"max_memories": 3}, &result)
+	assert.Equal(t, 3, result.Memories)
+	assert.Equal(t, 5, result.Total)
+
+	for name, params := range map[string]string{
+		"too many clusters": `{"clusters":21}`,
+		"negative clusters": `{"clusters":-1}`,
+		"negative max":      `{"max_memories":-1}`,
+		"bad time":          `{"created_after":"yesterday"}`,
+	} {
+		req := `{"jsonrpc":"2.0","method":"cluster_memories","params":` + params + `,"id":1}`
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), name)
+	}
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrConnectionReadOnly is returned when a mutating tool targets a connection
+// configured with read_only (see connections.Connection.ReadOnly). It maps to
+// ErrCodeReadOnly, like ErrReadOnly.
+var ErrConnectionReadOnly = errors.New("connection is read-only")
+
+// writeTarget holds the arguments of a mutating tool that name the
+// connection it writes to, either directly or through memory IDs.
+type writeTarget struct {
+	ConnectionID  string   `json:"connection_id"`
+	Domain        string   `json:"domain"` // legacy store_memory spelling of connection_id
+	ID            string   `json:"id"`
+	IDs           []string `json:"ids"`
+	ParentID      string   `json:"parent_id"`
+	ItemID        string   `json:"item_id"`
+	NewParentID   string   `json:"new_parent_id"`
+	WinnerID      string   `json:"winner_id"`
+	OldID         string   `json:"old_id"`
+	NewID         string   `json:"new_id"`
+	Contradiction struct {
+		MemoryIDs []string `json:"memory_ids"`
+	} `json:"contradiction"`
+	Snapshot struct {
+		Memory struct {
+			ID string `json:"id"`
+		} `json:"memory"`
+	} `json:"snapshot"`
+}
+
+// memoryIDs returns the memory IDs named in the arguments, some of which
+// may be empty.
+func (t writeTarget) memoryIDs() []string {
+	ids := append([]string{t.ID, t.ParentID, t.ItemID, t.NewParentID, t.WinnerID, t.OldID, t.NewID, t.Snapshot.Memory.ID}, t.IDs...)
+	return append(ids, t.Contradiction.MemoryIDs...)
+}
+
+// connectionNames returns the connections the tool would write to: the
+// explicit connection when one is given, otherwise the owners of the memory
+// IDs it names, otherwise the default connection.
+func (t writeTarget) connectionNames(ctx context.Context, s *Server) []string {
+	if t.ConnectionID != "" {
+		return []string{t.ConnectionID}
+	}
+	if t.Domain != "" {
+		return []string{t.Domain}
+	}
+	var names []string
+	for _, id := range t.memoryIDs() {
+		if id == "" {
+			continue
+		}
+		name := s.connectionForID(ctx, id)
+		if name == "" {
+			name = s.defaultConnection
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		names = append(names, s.defaultConnection)
+	}
+	return names
+}
+
+// checkConnectionWritable returns ErrConnectionReadOnly when name is a
+// mutating tool whose params resolve to a read-only connection. Session
+// tools write no memories and are always allowed.
+func (s *Server) checkConnectionWritable(ctx context.Context, name string, params interface{}) error {
+	if s.connectionManager == nil || !mutatingTools[name] || name == "begin_session" || name == "end_session" {
+		return nil
+	}
+	var target writeTarget
+	if raw, err := json.Marshal(params); err == nil {
+		// Malformed params are left for the handler to report.
+		_ = json.Unmarshal(raw, &target)
+	}
+	for _, conn := range target.connectionNames(ctx, s) {
+		if s.connectionManager.ReadOnly(conn) {
+			if conn == "" {
+				conn = s.connectionManager.GetDefaultConnection()
+			}
+			return fmt.Errorf("%s is not allowed on %q: %w", name, conn, ErrConnectionReadOnly)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/connections"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReadOnlyConnectionServer returns a server whose default connection
+// "work" is writable and whose "archive" connection is read-only and holds
+// two memories, mem:archive:one and mem:archive:two.
+func newReadOnlyConnectionServer(t *testing.T) *mcp.Server {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := connections.ConnectionsConfig{
+		DefaultConnection: "work",
+		Connections: []connections.Connection{
+			{Name: "work", Enabled: true, Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "work.db")}},
+			{Name: "archive", Enabled: true, ReadOnly: true, Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "archive.db")}},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(dir, "connections.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+	manager, err := connections.NewManager(configPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close() })
+
+	archive, err := manager.GetStore("archive")
+	require.NoError(t, err)
+	ctx := context.Background()
+	for _, id := range []string{"mem:archive:one", "mem:archive:two"} {
+		require.NoError(t, archive.Store(ctx, &types.Memory{
+			ID: id, Content: "Archived postmortem for the billing outage", Source: "test",
+			State: types.StateActive, Status: types.StatusEnriched,
+		}))
+	}
+
+	fallback, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = fallback.Close() })
+	return mcp.NewServer(fallback, mcp.WithConnectionManager(manager), mcp.WithDefaultConnection("work"))
+}
+
+func TestReadOnlyConnection_RejectsWrites(t *testing.T) {
+	srv := newReadOnlyConnectionServer(t)
+
+	writes := []struct {
+		tool   string
+		params map[string]interface{}
+	}{
+		{"store_memory", map[string]interface{}{"content": "new note", "connection_id": "archive"}},
+		{"store_memory", map[string]interface{}{"content": "new note", "domain": "archive"}},
+		{"update_memory", map[string]interface{}{"id": "mem:archive:one", "content": "edited"}},
+		{"update_memory_state", map[string]interface{}{"id": "mem:archive:one", "state": "archived"}},
+		{"evolve_memory", map[string]interface{}{"id": "mem:archive:one", "content": "evolved"}},
+		{"forget_memory", map[string]interface{}{"id": "mem:archive:one"}},
+		{"restore_memory", map[string]interface{}{"id": "mem:archive:one"}},
+		{"consolidate_memories", map[string]interface{}{"ids": []string{"mem:archive:one", "mem:archive:two"}}},
+		{"consolidate_memories", map[string]interface{}{"query": "billing", "connection_id": "archive"}},
+		{"add_project_item", map[string]interface{}{"parent_id": "mem:archive:one", "content": "task"}},
+		{"supersede_memory", map[string]interface{}{"old_id": "mem:archive:one", "new_id": "mem:archive:two"}},
+		{"recompute_decay", map[string]interface{}{"connection_id": "archive"}},
+	}
+	for _, w := range writes {
+		t.Run(w.tool, func(t *testing.T) {
+			body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": w.tool, "params": w.params, "id": 1})
+			require.NoError(t, err)
+			assert.Equal(t, mcp.ErrCodeReadOnly, rpcErrorCode(t, srv, string(body)))
+
+			body, err = json.Marshal(map[string]interface{}{
+				"jsonrpc": "2.0", "method": "tools/call", "id": 2,
+				"params": map[string]interface{}{"name": w.tool, "arguments": w.params},
+			})
+			require.NoError(t, err)
+			resp, err := srv.HandleRequest(context.Background(), body)
+			require.NoError(t, err)
+			var frame struct {
+				Result mcp.MCPToolCallResult `json:"result"`
+			}
+			require.NoError(t, json.Unmarshal(resp, &frame))
+			require.True(t, frame.Result.IsError, "%s", resp)
+			assert.Contains(t, frame.Result.Content[0].Text, `"archive"`)
+			assert.Contains(t, frame.Result.Content[0].Text, "connection is read-only")
+		})
+	}
+
+	// Nothing was written.
+	var recalled mcp.RecallMemoryResult
+	callRPC(t, srv, "recall_memory", map[string]interface{}{"id": "mem:archive:one"}, &recalled)
+	require.True(t, recalled.Found)
+	assert.Equal(t, "Archived postmortem for the billing outage", recalled.Memory.Content)
+	assert.Equal(t, types.StateActive, recalled.Memory.State)
+	callRPC(t, srv, "recall_memory", map[string]interface{}{"id": "mem:archive:two"}, &recalled)
+	require.True(t, recalled.Found)
+	assert.Empty(t, recalled.Memory.SupersedesID)
+}
+
+func TestReadOnlyConnection_AllowsReadsAndOtherConnections(t *testing.T) {
+	srv := newReadOnlyConnectionServer(t)
+
+	var recalled mcp.RecallMemoryResult
+	callRPC(t, srv, "recall_memory", map[string]interface{}{"connection_id": "archive"}, &recalled)
+	assert.Len(t, recalled.Memories, 2)
+
+	var related mcp.FindRelatedResult
+	callRPC(t, srv, "find_related", map[string]interface{}{"query": "billing outage", "connection_id": "archive"}, &related)
+	assert.NotEmpty(t, related.Memories)
+
+	var traversed map[string]interface{}
+	callRPC(t, srv, "traverse_memory_graph", map[string]interface{}{"memory_id": "mem:archive:one"}, &traversed)
+
+	// The writable default connection is unaffected.
+	var stored mcp.StoreMemoryResult
+	callRPC(t, srv, "store_memory", map[string]interface{}{"content": "Fresh note"}, &stored)
+	assert.NotEmpty(t, stored.ID)
+	var forgotten mcp.ForgetMemoryResult
+	callRPC(t, srv, "forget_memory", map[string]interface{}{"id": stored.ID}, &forgotten)
+}
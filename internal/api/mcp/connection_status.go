@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/connections"
+)
+
+// GetConnectionStatus reports, for every configured connection, whether its
+// store opened and answers a ping, along with its memory count. Without a
+// connection manager the injected store is reported as the only connection.
+func (s *Server) GetConnectionStatus(ctx context.Context) (*GetConnectionStatusResult, error) {
+	result := &GetConnectionStatusResult{DefaultConnection: s.defaultConnection}
+
+	if s.connectionManager == nil {
+		status := connections.ConnectionStatus{Name: "default", Enabled: true, Default: true, Opened: true}
+		var err error
+		status.MemoryCount, status.PingMillis, err = connections.PingStore(ctx, s.memoryStore)
+		if err != nil {
+			status.PingError = err.Error()
+		} else {
+			status.PingOK = true
+		}
+		result.Connections = []connections.ConnectionStatus{status}
+	} else {
+		if result.DefaultConnection == "" {
+			result.DefaultConnection = s.connectionManager.GetDefaultConnection()
+		}
+		result.Connections = s.connectionManager.Status(ctx)
+	}
+
+	unhealthy := 0
+	for _, c := range result.Connections {
+		if c.Enabled && !c.PingOK {
+			unhealthy++
+		}
+	}
+	if unhealthy == 0 {
+		result.Message = fmt.Sprintf("All %d enabled connections are reachable", countEnabled(result.Connections))
+	} else {
+		result.Message = fmt.Sprintf("%d of %d enabled connections are unreachable", unhealthy, countEnabled(result.Connections))
+	}
+	return result, nil
+}
+
+// countEnabled returns how many of statuses are enabled connections.
+func countEnabled(statuses []connections.ConnectionStatus) int {
+	n := 0
+	for _, c := range statuses {
+		if c.Enabled {
+			n++
+		}
+	}
+	return n
+}
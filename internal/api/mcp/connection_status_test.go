@@ -0,0 +1,84 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/connections"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConnectionStatus_GoodAndBroken(t *testing.T) {
+	dir := t.TempDir()
+	notADir := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(notADir, []byte("x"), 0644))
+
+	cfg := connections.ConnectionsConfig{
+		DefaultConnection: "work",
+		Connections: []connections.Connection{
+			{Name: "work", Enabled: true, Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "work.db")}},
+			{Name: "broken", Enabled: true, Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(notADir, "broken.db")}},
+		},
+	}
+	cfg.Settings.MaxConnections = 10
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(dir, "connections.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+	manager, err := connections.NewManager(configPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close() })
+
+	fallback, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = fallback.Close() })
+	srv := mcp.NewServer(fallback, mcp.WithConnectionManager(manager), mcp.WithDefaultConnection("work"))
+
+	_, err = srv.StoreMemory(context.Background(), mcp.StoreMemoryArgs{Content: "Reachable memory", ConnectionID: "work"})
+	require.NoError(t, err)
+
+	var result mcp.GetConnectionStatusResult
+	callRPC(t, srv, "get_connection_status", map[string]interface{}{}, &result)
+	require.Len(t, result.Connections, 2)
+	assert.Equal(t, "work", result.DefaultConnection)
+
+	work, broken := result.Connections[0], result.Connections[1]
+	assert.True(t, work.Opened)
+	assert.True(t, work.PingOK)
+	assert.Equal(t, 1, work.MemoryCount)
+	assert.False(t, broken.Opened)
+	assert.False(t, broken.PingOK)
+	assert.NotEmpty(t, broken.OpenError)
+	assert.Contains(t, result.Message, "1 of 2")
+}
+
+func TestWithDefaultConnectionResolver(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	for _, tc := range []struct {
+		name     string
+		resolved string
+		want     string
+	}{
+		{"resolver wins", "project", "project"},
+		{"empty keeps the pinned default", "", "global"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// The resolver is consulted after every option, whatever the order.
+			srv := mcp.NewServer(store,
+				mcp.WithDefaultConnectionResolver(func() string { return tc.resolved }),
+				mcp.WithDefaultConnection("global"))
+			result, err := srv.GetConnectionStatus(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, result.DefaultConnection)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package mcp_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// recordingEngine captures the content queued for enrichment.
+type recordingEngine struct {
+	queued map[string]string
+}
+
+func (e *recordingEngine) QueueEnrichmentForMemory(memoryID, content string) bool {
+	e.queued[memoryID] = content
+	return true
+}
+
+// WaitForEnrichment returns a channel that never fires: recorded jobs are
+// never processed.
+func (e *recordingEngine) WaitForEnrichment(string) (<-chan types.MemoryStatus, func()) {
+	return make(chan types.MemoryStatus), func() {}
+}
+
+func (e *recordingEngine) Embed(context.Context, string) ([]float64, error) { return nil, nil }
+
+func (e *recordingEngine) Summarize(context.Context, string) (string, error) { return "", nil }
+
+func (e *recordingEngine) Status(context.Context) (*engine.EngineStatus, error) {
+	return &engine.EngineStatus{StatusCounts: map[types.MemoryStatus]int{}}, nil
+}
+
+func TestStoreMemory_ContentOverLimitRejected(t *testing.T) {
+	srv := mcp.NewServer(newMockStore(), mcp.WithMaxContentLength(10))
+
+	_, err := srv.StoreMemory(context.Background(), mcp.StoreMemoryArgs{Content: strings.Repeat("a", 11)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "10 character limit")
+
+	req := `{"jsonrpc":"2.0","method":"store_memory","params":{"content":"` + strings.Repeat("a", 11) + `"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req))
+
+	// Content at the limit is accepted; multi-byte characters count once.
+	_, err = srv.StoreMemory(context.Background(), mcp.StoreMemoryArgs{Content: strings.Repeat("é", 10)})
+	require.NoError(t, err)
+}
+
+func TestStoreMemory_TruncateStoresFullContentAndEnrichesPrefix(t *testing.T) {
+	store := newMockStore()
+	eng := &recordingEngine{queued: map[string]string{}}
+	srv := mcp.NewServer(store, mcp.WithMaxContentLength(10), mcp.WithEngine(eng))
+	ctx := context.Background()
+
+	content := "0123456789 and a long tail that is never embedded"
+	result, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{
+		Content:  content,
+		Truncate: true,
+		Metadata: map[string]interface{}{"origin": "test"},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Truncated)
+	assert.Contains(t, result.Message, "only the first 10")
+
+	stored := store.memories[result.ID]
+	require.NotNil(t, stored)
+	assert.Equal(t, content, stored.Content)
+	assert.Equal(t, 10, stored.Metadata[types.MetadataEnrichedLength])
+	assert.Equal(t, "test", stored.Metadata["origin"])
+	assert.Equal(t, "0123456789", eng.queued[result.ID])
+	assert.Equal(t, "0123456789", stored.EnrichmentContent())
+}
+
+func TestStoreMemory_NoLimitByDefault(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	result, err := srv.StoreMemory(context.Background(), mcp.StoreMemoryArgs{Content: strings.Repeat("a", 100000)})
+	require.NoError(t, err)
+	assert.False(t, result.Truncated)
+}
@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// WithUnicodeNormalization turns NFC normalization of stored content on or
+// off (it is on by default). With it on, a character typed as a base letter
+// plus a combining accent is stored the same way as its precomposed form,
+// so both spellings match the same searches. Trimming and control-character
+// stripping (see normalizeContent) apply either way.
+func WithUnicodeNormalization(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.normalizeUnicode = enabled
+	}
+}
+
+// normalizeContent prepares memory content for storage: line endings become
+// "\n", control characters other than newlines and tabs are removed, the
+// text is NFC-normalized when enabled (see WithUnicodeNormalization), and
+// surrounding whitespace is trimmed. Content that is empty afterwards is
+// rejected with an invalid-params error naming field.
+func (s *Server) normalizeContent(field, content string) (string, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\r':
+			return '\n'
+		case r == '\n' || r == '\t':
+			return r
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, content)
+	if s.normalizeUnicode {
+		content = norm.NFC.String(content)
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", invalidParamsf("%s is empty or only whitespace", field)
+	}
+	return content, nil
+}
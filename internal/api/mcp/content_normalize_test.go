@@ -0,0 +1,80 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreMemory_StripsControlCharacters(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	res, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "  Deploy\x00 finished\x07\r\nnext\tstep\x7f\rdone \n"})
+	require.NoError(t, err)
+	stored, err := store.Get(ctx, res.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Deploy finished\nnext\tstep\ndone", stored.Content)
+
+	_, err = srv.UpdateMemory(ctx, mcp.UpdateMemoryArgs{ID: res.ID, Content: "\x1bupdated\x00 note\t"})
+	require.NoError(t, err)
+	stored, err = store.Get(ctx, res.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "updated note", stored.Content)
+
+	evolved, err := srv.EvolveMemory(ctx, mcp.EvolveMemoryArgs{ID: res.ID, NewContent: "evolved\x08 note\n\n"})
+	require.NoError(t, err)
+	stored, err = store.Get(ctx, evolved.NewID)
+	require.NoError(t, err)
+	assert.Equal(t, "evolved note", stored.Content)
+}
+
+func TestStoreMemory_UnicodeNormalization(t *testing.T) {
+	const decomposed = "Cafe\u0301 au lait" // e + combining acute accent
+	const composed = "Caf\u00e9 au lait"    // precomposed é
+	ctx := context.Background()
+
+	srv, store := newSQLiteTestServer(t)
+	res, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: decomposed})
+	require.NoError(t, err)
+	stored, err := store.Get(ctx, res.ID)
+	require.NoError(t, err)
+	assert.Equal(t, composed, stored.Content)
+
+	// Both spellings now store the same text, so they dedupe to one memory.
+	again, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: composed})
+	require.NoError(t, err)
+	assert.Equal(t, res.ID, again.ID)
+
+	srv, store = newSQLiteTestServer(t, mcp.WithUnicodeNormalization(false))
+	res, err = srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: decomposed})
+	require.NoError(t, err)
+	stored, err = store.Get(ctx, res.ID)
+	require.NoError(t, err)
+	assert.Equal(t, decomposed, stored.Content)
+}
+
+func TestStoreMemory_RejectsWhitespaceOnlyContent(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	blank := " \n\t\x00\r\n "
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv,
+		`{"jsonrpc":"2.0","method":"store_memory","params":{"content":" \n\t\u0000\r\n "},"id":1}`))
+
+	_, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: blank})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "content is empty or only whitespace")
+
+	res, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Kept note"})
+	require.NoError(t, err)
+	_, err = srv.UpdateMemory(ctx, mcp.UpdateMemoryArgs{ID: res.ID, Content: blank})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "content is empty or only whitespace")
+	_, err = srv.EvolveMemory(ctx, mcp.EvolveMemoryArgs{ID: res.ID, NewContent: blank})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "new_content is empty or only whitespace")
+}
@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// DefaultDecayReportThreshold is the decay score below which
+// get_decay_report lists memories when no threshold is given.
+const DefaultDecayReportThreshold = 0.3
+
+// DefaultDecayRecomputeCooldown is how long recompute_decay refuses to run
+// again for the same connection when WithDecayRecomputeCooldown is not given.
+const DefaultDecayRecomputeCooldown = time.Minute
+
+// decayCooldown rate-limits recompute_decay per connection. Recomputing
+// touches every active memory, so back-to-back calls are rejected until the
+// interval has passed since the last successful start.
+type decayCooldown struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time
+}
+
+func newDecayCooldown(interval time.Duration) *decayCooldown {
+	return &decayCooldown{interval: interval, last: make(map[string]time.Time)}
+}
+
+// acquire records a run for key and returns 0, or returns how long the
+// caller must still wait when key ran within the interval.
+func (c *decayCooldown) acquire(key string, now time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.last[key]; ok && c.interval > 0 {
+		if wait := c.interval - now.Sub(last); wait > 0 {
+			return wait
+		}
+	}
+	c.last[key] = now
+	return 0
+}
+
+// release forgets the run recorded for key so a failed recompute can be
+// retried straight away.
+func (c *decayCooldown) release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.last, key)
+}
+
+// RecomputeDecay applies time-based decay to the active memories of the
+// resolved connection immediately instead of waiting for the background
+// updater, e.g. after a bulk import or a long downtime.
+func (s *Server) RecomputeDecay(ctx context.Context, args RecomputeDecayArgs) (*RecomputeDecayResult, error) {
+	store, _ := s.resolveSearchStore(args.ConnectionID)
+	name := s.searchConnectionName(args.ConnectionID)
+
+	if wait := s.decayCooldown.acquire(name, time.Now()); wait > 0 {
+		return nil, &Error{
+			Code:    ErrCodeRateLimited,
+			Message: fmt.Sprintf("decay scores were recomputed recently; try again in %s", wait.Round(time.Second)),
+		}
+	}
+
+	updated, err := store.UpdateDecayScores(ctx)
+	if err != nil {
+		s.decayCooldown.release(name)
+		return nil, fmt.Errorf("failed to recompute decay scores: %w", err)
+	}
+
+	return &RecomputeDecayResult{
+		ConnectionID: name,
+		Updated:      updated,
+		Message:      fmt.Sprintf("Recomputed decay scores for %d memories", updated),
+	}, nil
+}
+
+// GetDecayReport lists the memories of a connection whose decay score has
+// fallen to a threshold or below, most faded first, so they can be reviewed and
+// reinforced, archived or deleted.
+func (s *Server) GetDecayReport(ctx context.Context, args GetDecayReportArgs) (*GetDecayReportResult, error) {
+	threshold := args.Threshold
+	if threshold == 0 {
+		threshold = DefaultDecayReportThreshold
+	}
+	if threshold < 0 || threshold > 1 {
+		return nil, invalidParamsf("threshold must be between 0 and 1, got %g", threshold)
+	}
+
+	store, _ := s.resolveSearchStore(args.ConnectionID)
+	opts := storage.ListOptions{
+		Page:          args.Page,
+		Limit:         s.effectiveLimit(args.Limit),
+		SortBy:        "decay_score",
+		SortOrder:     "asc",
+		MaxDecayScore: threshold,
+	}
+	opts.Normalize()
+
+	result, err := store.List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fading memories: %w", err)
+	}
+
+	now := time.Now()
+	entries := make([]DecayReportEntry, len(result.Items))
+	for i, m := range result.Items {
+		content := m.Content
+		if len(content) > 200 {
+			content = content[:200] + "…"
+		}
+		lastSeen := m.CreatedAt
+		entry := DecayReportEntry{
+			ID:          m.ID,
+			Content:     content,
+			State:       m.State,
+			Tags:        m.Tags,
+			DecayScore:  m.DecayScore,
+			AccessCount: m.AccessCount,
+		}
+		if m.LastAccessedAt != nil {
+			lastSeen = *m.LastAccessedAt
+			entry.LastAccessedAt = m.LastAccessedAt.UTC().Format(time.RFC3339)
+		}
+		entry.DaysSinceAccess = int(now.Sub(lastSeen).Hours() / 24)
+		entries[i] = entry
+	}
+
+	return &GetDecayReportResult{
+		Memories:  entries,
+		Threshold: threshold,
+		Total:     result.Total,
+		Page:      result.Page,
+		HasMore:   result.HasMore,
+		Limit:     opts.Limit,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecomputeDecay_UpdatesAndCoolsDown(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Store(ctx, &types.Memory{
+		ID: "mem:general:imported", Content: "Imported in bulk", Source: "test", State: types.StateActive,
+	}))
+
+	var result mcp.RecomputeDecayResult
+	callRPC(t, srv, "recompute_decay", map[string]interface{}{}, &result)
+	assert.Equal(t, 1, result.Updated)
+
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"recompute_decay","params":{},"id":2}`)
+	assert.Equal(t, mcp.ErrCodeRateLimited, code)
+}
+
+func TestRecomputeDecay_CooldownDisabled(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t, mcp.WithDecayRecomputeCooldown(0))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := srv.RecomputeDecay(ctx, mcp.RecomputeDecayArgs{})
+		require.NoError(t, err)
+	}
+}
+
+func TestGetDecayReport_ListsFadingMemoriesLowestFirst(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	lastWeek := now.Add(-7 * 24 * time.Hour)
+	for id, decay := range map[string]float64{
+		"mem:general:faded":   0.05,
+		"mem:general:fading":  0.2,
+		"mem:general:dimming": 0.25,
+		"mem:general:fresh":   0.6,
+		"mem:general:vivid":   0.95,
+	} {
+		m := &types.Memory{
+			ID: id, Content: "decay fixture " + id, Source: "test", State: types.StateActive,
+			DecayScore: decay, CreatedAt: now.Add(-30 * 24 * time.Hour),
+		}
+		if id == "mem:general:fading" {
+			m.LastAccessedAt = &lastWeek
+			m.AccessCount = 2
+		}
+		require.NoError(t, store.Store(ctx, m))
+	}
+
+	var report mcp.GetDecayReportResult
+	callRPC(t, srv, "get_decay_report", map[string]interface{}{}, &report)
+	assert.Equal(t, mcp.DefaultDecayReportThreshold, report.Threshold)
+	assert.Equal(t, 3, report.Total)
+	require.Len(t, report.Memories, 3)
+	ids := []string{report.Memories[0].ID, report.Memories[1].ID, report.Memories[2].ID}
+	assert.Equal(t, []string{"mem:general:faded", "mem:general:fading", "mem:general:dimming"}, ids)
+
+	assert.Equal(t, 30, report.Memories[0].DaysSinceAccess, "never accessed: days since creation")
+	assert.Empty(t, report.Memories[0].LastAccessedAt)
+	assert.Equal(t, 7, report.Memories[1].DaysSinceAccess)
+	assert.Equal(t, 2, report.Memories[1].AccessCount)
+	assert.NotEmpty(t, report.Memories[1].LastAccessedAt)
+
+	report = mcp.GetDecayReportResult{}
+	callRPC(t, srv, "get_decay_report", map[string]interface{}{"threshold": 0.7, "limit": 2, "page": 2}, &report)
+	assert.Equal(t, 4, report.Total)
+	require.Len(t, report.Memories, 2)
+	assert.Equal(t, "mem:general:dimming", report.Memories[0].ID)
+	assert.Equal(t, "mem:general:fresh", report.Memories[1].ID)
+	assert.False(t, report.HasMore)
+
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"get_decay_report","params":{"threshold":1.5},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, code)
+}
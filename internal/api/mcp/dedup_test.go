@@ -0,0 +1,46 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDedupNormalization verifies which near-identical submissions collapse
+// to one memory at each normalization level, and that the first submission's
+// content is the one kept.
+func TestDedupNormalization(t *testing.T) {
+	tests := []struct {
+		level     string
+		first     string
+		second    string
+		duplicate bool
+	}{
+		{config.DedupExact, "Hello World.", "hello   world", false},
+		{"", "Hello World.", "hello   world", false},
+		{config.DedupWhitespace, "Hello World.", "  Hello \n World. ", true},
+		{config.DedupWhitespace, "Hello World.", "hello   world", false},
+		{config.DedupNormalized, "Hello World.", "hello   world", true},
+		{config.DedupNormalized, "Hello World.", "Hello, World", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level+"/"+tt.second, func(t *testing.T) {
+			store := newMockStore()
+			srv := mcp.NewServer(store, mcp.WithDedupNormalization(tt.level))
+			ctx := context.Background()
+
+			first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: tt.first})
+			require.NoError(t, err)
+			second, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: tt.second})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.duplicate, second.Duplicate)
+			assert.Equal(t, tt.duplicate, first.ID == second.ID)
+			assert.Equal(t, tt.first, store.memories[first.ID].Content, "stored content must be unchanged")
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/attribution"
+	"github.com/scrypster/memento/internal/engine"
+)
+
+// GenerateDigest writes a digest of the memories a connection captured in
+// a time window, grouped by domain or topic, with the LLM engine if there
+// is one (see engine.GenerateDigest). The window is the day or week before
+// args.CreatedBefore (default: now) unless args.CreatedAfter sets its
+// start. The digest is stored as a memory of type types.MemoryTypeDigest,
+// so later searches find it, unless args.DryRun is set or nothing was
+// captured.
+func (s *Server) GenerateDigest(ctx context.Context, args GenerateDigestArgs) (*GenerateDigestResult, error) {
+	var window time.Duration
+	switch args.Period {
+	case "", DigestPeriodDay:
+		window = 24 * time.Hour
+	case DigestPeriodWeek:
+		window = 7 * 24 * time.Hour
+	default:
+		return nil, invalidParamsf("invalid period %q: must be day or week", args.Period)
+	}
+	from, to, err := parseTimeRange(args.CreatedAfter, args.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-window)
+	}
+	if !from.Before(to) {
+		return nil, invalidParamsf("created_after (%s) must be in the past", from.Format(time.RFC3339))
+	}
+
+	store, _ := s.resolveSearchStore(args.ConnectionID)
+	var summarize engine.SummarizeFunc
+	if s.engine != nil {
+		summarize = s.engine.Summarize
+	}
+	digest, err := engine.GenerateDigest(ctx, store, from, to, summarize)
+	if err != nil {
+		return nil, err
+	}
+	result := &GenerateDigestResult{
+		Content:    digest.Content,
+		From:       digest.From,
+		To:         digest.To,
+		Memories:   digest.Memories,
+		Truncated:  digest.Truncated,
+		Groups:     digest.Groups,
+		Summarized: digest.Summarized,
+	}
+	if args.DryRun || digest.Memories == 0 {
+		return result, nil
+	}
+
+	connection := s.searchConnectionName(args.ConnectionID)
+	memory := digest.Memory(s.generateMemoryID(connection, digest.Content))
+	memory.Domain = connection
+	memory.CreatedBy = attribution.DetectAgent()
+	memory.SessionID = s.session.forWrite()
+	if err := s.recordRoute(ctx, memory.ID, connection); err != nil {
+		return nil, err
+	}
+	if err := store.Store(ctx, memory); err != nil {
+		return nil, fmt.Errorf("failed to store digest: %w", err)
+	}
+	if s.engine != nil {
+		s.engine.QueueEnrichmentForMemory(memory.ID, memory.Content)
+	}
+	result.ID, result.Stored = memory.ID, true
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDigest_StoresDigestMemory(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	eng := &summarizingEngine{
+		recordingEngine: recordingEngine{queued: map[string]string{}},
+		response:        "## general\n- The kestrel rollout finished and the pager was quiet.",
+	}
+	srv := mcp.NewServer(store, mcp.WithEngine(eng))
+	ctx := context.Background()
+
+	for _, content := range []string{"kestrel rollout finished", "pager was quiet all night"} {
+		_, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: content})
+		require.NoError(t, err)
+	}
+
+	preview, err := srv.GenerateDigest(ctx, mcp.GenerateDigestArgs{DryRun: true})
+	require.NoError(t, err)
+	assert.False(t, preview.Stored)
+	assert.Empty(t, preview.ID)
+	assert.Equal(t, 2, preview.Memories)
+	assert.True(t, preview.Summarized)
+	assert.Contains(t, eng.prompt, "kestrel rollout finished")
+	assert.Contains(t, preview.Content, "pager was quiet")
+	assert.InDelta(t, 24*time.Hour, preview.To.Sub(preview.From), float64(time.Second))
+
+	digest, err := srv.GenerateDigest(ctx, mcp.GenerateDigestArgs{Period: mcp.DigestPeriodWeek})
+	require.NoError(t, err)
+	require.True(t, digest.Stored)
+	assert.Equal(t, 7*24*time.Hour, digest.To.Sub(digest.From))
+	stored, err := store.Get(ctx, digest.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.MemoryTypeDigest, stored.MemoryType)
+	assert.Equal(t, digest.Content, stored.Content)
+	assert.Contains(t, eng.queued, digest.ID)
+
+	related, err := srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "kestrel", MemoryType: types.MemoryTypeDigest})
+	require.NoError(t, err)
+	require.Len(t, related.Memories, 1)
+	assert.Equal(t, digest.ID, related.Memories[0].ID)
+
+	// A later digest does not cover the earlier one.
+	again, err := srv.GenerateDigest(ctx, mcp.GenerateDigestArgs{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, again.Memories)
+}
+
+func TestGenerateDigest_EmptyWindowIsNotStored(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	_, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "kestrel rollout finished"})
+	require.NoError(t, err)
+
+	result, err := srv.GenerateDigest(ctx, mcp.GenerateDigestArgs{CreatedBefore: time.Now().Add(-48 * time.Hour).Format(time.RFC3339)})
+	require.NoError(t, err)
+	assert.False(t, result.Stored)
+	assert.Zero(t, result.Memories)
+	assert.Contains(t, result.Content, "No memories were captured")
+
+	// Without an engine the memories are listed.
+	result, err = srv.GenerateDigest(ctx, mcp.GenerateDigestArgs{DryRun: true})
+	require.NoError(t, err)
+	assert.False(t, result.Summarized)
+	assert.Contains(t, result.Content, "- kestrel rollout finished")
+}
+
+func TestGenerateDigest_InvalidArgs(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	for _, params := range []string{
+		`{"period":"month"}`,
+		`{"created_after":"yesterday"}`,
+		`{"created_after":"` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`,
+	} {
+		req := `{"jsonrpc":"2.0","method":"generate_digest","params":` + params + `,"id":1}`
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), params)
+	}
+}
@@ -0,0 +1,181 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// Precision of the values get_embedding returns, in decimal places.
+const (
+	DefaultEmbeddingPrecision = 4
+	MaxEmbeddingPrecision     = 8
+)
+
+// WithEmbeddingDebug allows get_embedding, which returns raw embedding
+// vectors for diagnosing search ranking. It is off by default because the
+// vectors are large and only useful when tuning search.
+func WithEmbeddingDebug(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.embeddingDebug = enabled
+	}
+}
+
+// GetEmbedding returns the stored embedding of memory args.ID, or embeds
+// args.Text with the engine's model the way find_related embeds a query,
+// so two vectors can be compared when memories that look alike do not rank
+// together. Values are rounded to args.Precision decimal places; the norm
+// is computed before rounding.
+func (s *Server) GetEmbedding(ctx context.Context, args GetEmbeddingArgs) (*GetEmbeddingResult, error) {
+	if !s.embeddingDebug {
+		return nil, invalidParamsf("get_embedding is disabled (set MEMENTO_ENABLE_EMBEDDING_DEBUG=true)")
+	}
+	hasID, hasText := strings.TrimSpace(args.ID) != "", strings.TrimSpace(args.Text) != ""
+	if hasID == hasText {
+		return nil, invalidParamsf("exactly one of id or text is required")
+	}
+	precision := args.Precision
+	if precision == 0 {
+		precision = DefaultEmbeddingPrecision
+	}
+	if precision < 0 || precision > MaxEmbeddingPrecision {
+		return nil, invalidParamsf("precision must be between 0 and %d", MaxEmbeddingPrecision)
+	}
+
+	var (
+		result = &GetEmbeddingResult{Precision: precision}
+		vector []float64
+	)
+	if hasID {
+		store, err := s.readStoreForID(ctx, args.ID, args.ConnectionID)
+		if err != nil {
+			return nil, err
+		}
+		vector, result.Model, err = store.GetEmbedding(ctx, args.ID)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("memory %s has no embedding (it may not exist or not be enriched yet)", args.ID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get embedding: %w", err)
+		}
+		result.ID, result.Source = args.ID, "stored"
+	} else {
+		var err error
+		if vector, result.Model, err = s.embed(ctx, args.Text); err != nil {
+			return nil, err
+		}
+		result.Source = "computed"
+	}
+
+	scale := math.Pow(10, float64(precision))
+	var sum float64
+	result.Vector = make([]float64, len(vector))
+	for i, v := range vector {
+		sum += v * v
+		result.Vector[i] = math.Round(v*scale) / scale
+	}
+	result.Dimension = len(vector)
+	result.Norm = math.Round(math.Sqrt(sum)*scale) / scale
+	return result, nil
+}
+
+// MemorySimilarity returns the cosine similarity of the embeddings of
+// memory args.MemoryID and either memory args.OtherID or args.Text, the
+// measure the semantic half of find_related ranks by. A memory without a
+// stored embedding, e.g. one still pending enrichment, is embedded on the
+// fly from its content; nothing is stored.
+func (s *Server) MemorySimilarity(ctx context.Context, args MemorySimilarityArgs) (*MemorySimilarityResult, error) {
+	if strings.TrimSpace(args.MemoryID) == "" {
+		return nil, invalidParamsf("memory_id is required")
+	}
+	hasOther, hasText := strings.TrimSpace(args.OtherID) != "", strings.TrimSpace(args.Text) != ""
+	if hasOther == hasText {
+		return nil, invalidParamsf("exactly one of other_id or text is required")
+	}
+
+	result := &MemorySimilarityResult{MemoryID: args.MemoryID, OtherID: args.OtherID}
+	a, modelA, err := s.memoryEmbedding(ctx, args.MemoryID, args.ConnectionID, &result.MemoryEmbedding)
+	if err != nil {
+		return nil, err
+	}
+	var b []float64
+	var modelB string
+	if hasOther {
+		b, modelB, err = s.memoryEmbedding(ctx, args.OtherID, args.ConnectionID, &result.OtherEmbedding)
+	} else {
+		b, modelB, err = s.embed(ctx, args.Text)
+		result.OtherEmbedding = "computed"
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(a) != len(b) {
+		return nil, fmt.Errorf("%w: the embeddings have %d and %d dimensions (models %q and %q)",
+			storage.ErrDimensionMismatch, len(a), len(b), modelA, modelB)
+	}
+	if modelA != "" && modelB != "" && modelA != modelB {
+		result.Warning = fmt.Sprintf("the embeddings come from different models (%s and %s), so their similarity is not meaningful", modelA, modelB)
+	}
+	result.Similarity = storage.CosineSimilarity(a, b)
+	result.Dimension = len(a)
+	return result, nil
+}
+
+// memoryEmbedding returns the embedding of memory id and its model, reading
+// the stored one if there is one and otherwise embedding the memory's
+// content. source is set to "stored" or "computed" accordingly.
+func (s *Server) memoryEmbedding(ctx context.Context, id, connectionID string, source *string) ([]float64, string, error) {
+	store, err := s.readStoreForID(ctx, id, connectionID)
+	if err != nil {
+		return nil, "", err
+	}
+	vector, model, err := store.GetEmbedding(ctx, id)
+	if err == nil {
+		*source = "stored"
+		return vector, model, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, "", fmt.Errorf("failed to get embedding of %s: %w", id, err)
+	}
+
+	memory, err := store.Get(ctx, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, "", notFoundf("memory not found: %s", id)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to retrieve memory: %w", err)
+	}
+	if s.engine == nil {
+		return nil, "", fmt.Errorf("memory %s has no embedding and no embedder is configured", id)
+	}
+	// Embed what enrichment would, which stops at the content limit.
+	content := memory.Content
+	if s.contentTooLong(content) {
+		content = types.TruncateRunes(content, s.maxContentLength)
+	}
+	*source = "computed"
+	return s.embed(ctx, content)
+}
+
+// embed embeds text with the engine and returns the vector and the
+// embedding model, which is empty if the engine does not report it.
+func (s *Server) embed(ctx context.Context, text string) ([]float64, string, error) {
+	if s.engine == nil {
+		return nil, "", fmt.Errorf("embedding is not available: no LLM engine is configured")
+	}
+	vector, err := s.engine.Embed(ctx, text)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to embed text: %w", err)
+	}
+	var model string
+	if m, ok := s.engine.(interface{ EmbeddingModel() string }); ok {
+		model = m.EmbeddingModel()
+	}
+	return vector, model, nil
+}
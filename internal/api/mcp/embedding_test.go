@@ -0,0 +1,155 @@
+package mcp_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// modelEngine is an embeddingEngine that also reports its model name.
+type modelEngine struct {
+	embeddingEngine
+}
+
+func (e *modelEngine) EmbeddingModel() string { return "nomic-embed-text" }
+
+func newEmbeddingServer(t *testing.T, opts ...mcp.ServerOption) *mcp.Server {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	require.NoError(t, store.Store(ctx, &types.Memory{ID: "mem:general:kestrel", Content: "kestrel notes", Source: "test"}))
+	require.NoError(t, store.SetEmbedding(ctx, "mem:general:kestrel", []float64{0.123456789, -0.6, 0.8}, "test-model"))
+	require.NoError(t, store.Store(ctx, &types.Memory{ID: "mem:general:pending", Content: "not enriched yet", Source: "test"}))
+	return mcp.NewServer(store, opts...)
+}
+
+func TestGetEmbedding_Stored(t *testing.T) {
+	srv := newEmbeddingServer(t, mcp.WithEmbeddingDebug(true))
+
+	var result mcp.GetEmbeddingResult
+	callRPC(t, srv, "get_embedding", map[string]interface{}{"id": "mem:general:kestrel"}, &result)
+	assert.Equal(t, "mem:general:kestrel", result.ID)
+	assert.Equal(t, "stored", result.Source)
+	assert.Equal(t, "test-model", result.Model)
+	assert.Equal(t, 3, result.Dimension)
+	assert.Equal(t, mcp.DefaultEmbeddingPrecision, result.Precision)
+	assert.Equal(t, []float64{0.1235, -0.6, 0.8}, result.Vector)
+	assert.InDelta(t, 1.0076, result.Norm, 1e-9)
+
+	callRPC(t, srv, "get_embedding", map[string]interface{}{"id": "mem:general:kestrel", "precision": 1}, &result)
+	assert.Equal(t, []float64{0.1, -0.6, 0.8}, result.Vector)
+}
+
+func TestGetEmbedding_Computed(t *testing.T) {
+	engine := &modelEngine{embeddingEngine{vector: []float64{1, 0, 0}}}
+	srv := newEmbeddingServer(t, mcp.WithEmbeddingDebug(true), mcp.WithEngine(engine))
+
+	var result mcp.GetEmbeddingResult
+	callRPC(t, srv, "get_embedding", map[string]interface{}{"text": "kestrel"}, &result)
+	assert.Empty(t, result.ID)
+	assert.Equal(t, "computed", result.Source)
+	assert.Equal(t, "nomic-embed-text", result.Model)
+	assert.Equal(t, 3, result.Dimension)
+	assert.Equal(t, []float64{1, 0, 0}, result.Vector)
+	assert.Equal(t, 1.0, result.Norm)
+}
+
+func TestGetEmbedding_Errors(t *testing.T) {
+	disabled := newEmbeddingServer(t)
+	req := `{"jsonrpc":"2.0","method":"get_embedding","params":{"id":"mem:general:kestrel"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, disabled, req))
+
+	srv := newEmbeddingServer(t, mcp.WithEmbeddingDebug(true))
+	for name, params := range map[string]string{
+		"neither":   `{}`,
+		"both":      `{"id":"mem:general:kestrel","text":"kestrel"}`,
+		"precision": `{"id":"mem:general:kestrel","precision":9}`,
+		"negative":  `{"id":"mem:general:kestrel","precision":-1}`,
+	} {
+		req := `{"jsonrpc":"2.0","method":"get_embedding","params":` + params + `,"id":1}`
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), name)
+	}
+	req = `{"jsonrpc":"2.0","method":"get_embedding","params":{"id":"mem:general:pending"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeNotFound, rpcErrorCode(t, srv, req))
+}
+
+func newSimilarityStore(t *testing.T) *sqlite.MemoryStore {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	for id, vec := range map[string][]float64{
+		"mem:general:kestrel": {1, 0, 0},
+		"mem:general:falcon":  {0.6, 0.8, 0},
+	} {
+		require.NoError(t, store.Store(ctx, &types.Memory{ID: id, Content: id + " notes", Source: "test"}))
+		require.NoError(t, store.SetEmbedding(ctx, id, vec, "test-model"))
+	}
+	require.NoError(t, store.Store(ctx, &types.Memory{ID: "mem:general:osprey", Content: "osprey notes", Source: "test"}))
+	require.NoError(t, store.SetEmbedding(ctx, "mem:general:osprey", []float64{0, 0, 1}, "other-model"))
+	require.NoError(t, store.Store(ctx, &types.Memory{ID: "mem:general:pending", Content: "not enriched yet", Source: "test"}))
+	return store
+}
+
+func TestMemorySimilarity(t *testing.T) {
+	store := newSimilarityStore(t)
+	engine := &modelEngine{embeddingEngine{vector: []float64{1, 1, 0}}}
+	srv := mcp.NewServer(store, mcp.WithEngine(engine))
+
+	var result mcp.MemorySimilarityResult
+	callRPC(t, srv, "memory_similarity", map[string]interface{}{"memory_id": "mem:general:kestrel", "other_id": "mem:general:falcon"}, &result)
+	assert.InDelta(t, 0.6, result.Similarity, 1e-9)
+	assert.Equal(t, 3, result.Dimension)
+	assert.Equal(t, "stored", result.MemoryEmbedding)
+	assert.Equal(t, "stored", result.OtherEmbedding)
+	assert.Empty(t, result.Warning)
+
+	result = mcp.MemorySimilarityResult{}
+	callRPC(t, srv, "memory_similarity", map[string]interface{}{"memory_id": "mem:general:kestrel", "text": "birds of prey"}, &result)
+	assert.InDelta(t, 1/math.Sqrt2, result.Similarity, 1e-9)
+	assert.Equal(t, "computed", result.OtherEmbedding)
+
+	result = mcp.MemorySimilarityResult{}
+	callRPC(t, srv, "memory_similarity", map[string]interface{}{"memory_id": "mem:general:pending", "other_id": "mem:general:falcon"}, &result)
+	assert.InDelta(t, 1.4/math.Sqrt2, result.Similarity, 1e-9)
+	assert.Equal(t, "computed", result.MemoryEmbedding, "a memory without an embedding is embedded on the fly")
+
+	result = mcp.MemorySimilarityResult{}
+	callRPC(t, srv, "memory_similarity", map[string]interface{}{"memory_id": "mem:general:kestrel", "other_id": "mem:general:osprey"}, &result)
+	assert.Zero(t, result.Similarity)
+	assert.Contains(t, result.Warning, "different models")
+}
+
+func TestMemorySimilarity_Errors(t *testing.T) {
+	store := newSimilarityStore(t)
+	ctx := context.Background()
+
+	srv := mcp.NewServer(store)
+	for name, params := range map[string]string{
+		"no memory": `{"other_id":"mem:general:falcon"}`,
+		"neither":   `{"memory_id":"mem:general:kestrel"}`,
+		"both":      `{"memory_id":"mem:general:kestrel","other_id":"mem:general:falcon","text":"x"}`,
+	} {
+		req := `{"jsonrpc":"2.0","method":"memory_similarity","params":` + params + `,"id":1}`
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), name)
+	}
+	req := `{"jsonrpc":"2.0","method":"memory_similarity","params":{"memory_id":"mem:general:missing","other_id":"mem:general:falcon"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeNotFound, rpcErrorCode(t, srv, req))
+
+	_, err := srv.MemorySimilarity(ctx, mcp.MemorySimilarityArgs{MemoryID: "mem:general:pending", OtherID: "mem:general:falcon"})
+	assert.ErrorContains(t, err, "no embedder is configured")
+
+	mismatched := mcp.NewServer(store, mcp.WithEngine(&embeddingEngine{vector: []float64{1, 0}}))
+	_, err = mismatched.MemorySimilarity(ctx, mcp.MemorySimilarityArgs{MemoryID: "mem:general:kestrel", Text: "birds"})
+	assert.ErrorIs(t, err, storage.ErrDimensionMismatch)
+}
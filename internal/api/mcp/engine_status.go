@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// GetEngineStatus reports the enrichment engine's queue, workers, backend
+// reachability and memory counts by enrichment status. The numbers come from
+// MemoryEngine.Status, the same source the web UI's queue page uses. Without
+// an engine the result is marked unavailable rather than failing.
+func (s *Server) GetEngineStatus(ctx context.Context) (*GetEngineStatusResult, error) {
+	if s.engine == nil {
+		return &GetEngineStatusResult{
+			Message: "Enrichment engine is not running; memories are stored without enrichment",
+		}, nil
+	}
+
+	status, err := s.engine.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read engine status: %w", err)
+	}
+
+	result := &GetEngineStatusResult{Available: true, Engine: status}
+	result.Message = fmt.Sprintf("%d queued (%.0f%% of %d), %d in flight across %d workers; %d pending, %d failed",
+		status.QueueDepth, status.QueueUtilization*100, status.QueueCapacity, status.InFlight, status.Workers,
+		status.StatusCounts[types.StatusPending], status.StatusCounts[types.StatusFailed])
+	if status.Deferred > 0 {
+		result.Message += fmt.Sprintf("; %d deferred because the queue was full", status.Deferred)
+	}
+	if down := unreachableBackends(status); down != "" {
+		result.Message += "; unreachable: " + down
+	}
+	return result, nil
+}
+
+// unreachableBackends names the LLM backends whose health check failed.
+func unreachableBackends(status *engine.EngineStatus) string {
+	var down []string
+	if status.Embedder.State == engine.BackendUnreachable {
+		down = append(down, "embedder")
+	}
+	if status.Summarizer.State == engine.BackendUnreachable {
+		down = append(down, "summarizer")
+	}
+	return strings.Join(down, ", ")
+}
@@ -0,0 +1,55 @@
+package mcp_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func TestGetEngineStatus_ReportsEngineSnapshot(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	cfg := engine.DefaultConfig()
+	cfg.NumWorkers = 2
+	eng, err := engine.NewMemoryEngine(store, cfg, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, store.Store(ctx, &types.Memory{ID: "mem:general:failed", Content: "x", Source: "test", Status: types.StatusFailed}))
+
+	srv := mcp.NewServer(store, mcp.WithEngine(eng))
+	var result mcp.GetEngineStatusResult
+	callRPC(t, srv, "get_engine_status", map[string]interface{}{}, &result)
+
+	assert.True(t, result.Available)
+	require.NotNil(t, result.Engine)
+	assert.Equal(t, 2, result.Engine.Workers)
+	assert.Equal(t, cfg.QueueSize, result.Engine.QueueCapacity)
+	assert.Zero(t, result.Engine.QueueUtilization)
+	assert.Zero(t, result.Engine.Deferred)
+	assert.Equal(t, 1, result.Engine.StatusCounts[types.StatusFailed])
+	assert.Equal(t, engine.BackendNotConfigured, result.Engine.Embedder.State)
+	assert.Contains(t, result.Message, "1 failed")
+	assert.Contains(t, result.Message, "(0% of 1000)")
+	assert.NotContains(t, result.Message, "deferred")
+}
+
+func TestGetEngineStatus_WithoutEngine(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	result, err := srv.GetEngineStatus(context.Background())
+	require.NoError(t, err)
+	assert.False(t, result.Available)
+	assert.Nil(t, result.Engine)
+	assert.Contains(t, result.Message, "not running")
+}
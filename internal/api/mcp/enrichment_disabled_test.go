@@ -0,0 +1,62 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/connections"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreMemory_EnrichmentDisabledConnection(t *testing.T) {
+	dir := t.TempDir()
+	off := false
+	cfg := connections.ConnectionsConfig{
+		DefaultConnection: "work",
+		Connections: []connections.Connection{
+			{Name: "work", Enabled: true, Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "work.db")}},
+			{Name: "logs", Enabled: true, EnrichmentEnabled: &off, Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "logs.db")}},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(dir, "connections.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+	manager, err := connections.NewManager(configPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close() })
+
+	fallback, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = fallback.Close() })
+	eng := &recordingEngine{queued: map[string]string{}}
+	srv := mcp.NewServer(fallback, mcp.WithConnectionManager(manager), mcp.WithDefaultConnection("work"), mcp.WithEngine(eng))
+	ctx := context.Background()
+
+	skipped, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "GET /health 200", ConnectionID: "logs"})
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusEnriched, skipped.Status)
+	assert.Equal(t, mcp.EnrichmentDisabled, skipped.Enrichment)
+	assert.NotContains(t, eng.queued, skipped.ID)
+
+	logs, err := manager.GetStore("logs")
+	require.NoError(t, err)
+	stored, err := logs.Get(ctx, skipped.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusEnriched, stored.Status)
+	assert.Equal(t, types.EnrichmentSkipped, stored.EntityStatus)
+	assert.Equal(t, types.EnrichmentSkipped, stored.EmbeddingStatus)
+
+	enriched, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Quarterly planning notes", ConnectionID: "work"})
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusPending, enriched.Status)
+	assert.Equal(t, mcp.EnrichmentQueued, enriched.Enrichment)
+	assert.Contains(t, eng.queued, enriched.ID)
+}
@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// MergeEntities merges duplicate entities of a connection into a canonical
+// one, moving their memory links and relationships over, so the graph
+// traverse_memory_graph walks is not split across spellings of one name.
+func (s *Server) MergeEntities(ctx context.Context, args MergeEntitiesArgs) (*MergeEntitiesResult, error) {
+	if args.CanonicalID == "" {
+		return nil, invalidParamsf("canonical_id is required")
+	}
+	if len(args.DuplicateIDs) == 0 {
+		return nil, invalidParamsf("duplicate_ids is required")
+	}
+
+	store, _ := s.resolveSearchStore(args.ConnectionID)
+	if args.ConnectionID != "" {
+		// Fail rather than merge in the default store.
+		var err error
+		if store, err = s.readStoreForID(ctx, "", args.ConnectionID); err != nil {
+			return nil, err
+		}
+	}
+	merger, ok := store.(storage.EntityMerger)
+	if !ok {
+		return nil, fmt.Errorf("store does not support merging entities")
+	}
+
+	result, err := merger.MergeEntities(ctx, args.CanonicalID, args.DuplicateIDs)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return nil, notFoundf("%v", err)
+	case errors.Is(err, storage.ErrInvalidInput):
+		return nil, invalidParamsf("%v", err)
+	case err != nil:
+		return nil, fmt.Errorf("failed to merge entities: %w", err)
+	}
+	return &MergeEntitiesResult{
+		CanonicalID:            result.CanonicalID,
+		MergedIDs:              result.MergedIDs,
+		MemoriesRepointed:      result.MemoriesRepointed,
+		RelationshipsRepointed: result.RelationshipsRepointed,
+		RelationshipsDropped:   result.RelationshipsDropped,
+	}, nil
+}
+
+// listDuplicateEntities groups every entity of store matching args.Type
+// with the entities likely to duplicate it and returns a page of the
+// groups, largest mention count first.
+func (s *Server) listDuplicateEntities(ctx context.Context, store storage.MemoryStore, args ListEntitiesArgs) (*ListEntitiesResult, error) {
+	var entities []types.Entity
+	opts := storage.EntityListOptions{Type: args.Type, Limit: 100}
+	for opts.Page = 1; ; opts.Page++ {
+		page, err := store.ListEntities(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list entities: %w", err)
+		}
+		entities = append(entities, page.Items...)
+		if !page.HasMore {
+			break
+		}
+	}
+
+	// Union each pair of likely duplicates; group[i] is the index of the
+	// first entity of i's group, which is its most mentioned.
+	group := make([]int, len(entities))
+	for i := range group {
+		group[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if group[i] != i {
+			group[i] = find(group[i])
+		}
+		return group[i]
+	}
+	words := make([][]string, len(entities))
+	for i, e := range entities {
+		words[i] = entityNameWords(e.Name)
+	}
+	for i := range entities {
+		for j := i + 1; j < len(entities); j++ {
+			if likelyDuplicateEntities(entities[i], entities[j], words[i], words[j]) {
+				a, b := find(i), find(j)
+				group[max(a, b)] = min(a, b)
+			}
+		}
+	}
+
+	members := map[int][]EntitySummary{}
+	var roots []int
+	for i, e := range entities {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], entitySummary(e))
+	}
+	var groups []DuplicateEntityGroup
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			groups = append(groups, DuplicateEntityGroup{CanonicalID: entities[root].ID, Entities: members[root]})
+		}
+	}
+
+	pageOpts := storage.EntityListOptions{Page: args.Page, Limit: args.Limit}
+	pageOpts.Normalize()
+	start := min(pageOpts.Offset(), len(groups))
+	end := min(start+pageOpts.Limit, len(groups))
+	return &ListEntitiesResult{
+		Entities:        []EntitySummary{},
+		DuplicateGroups: groups[start:end],
+		Total:           len(groups),
+		Page:            pageOpts.Page,
+		HasMore:         end < len(groups),
+	}, nil
+}
+
+// likelyDuplicateEntities reports whether a and b, whose names have the
+// words aWords and bWords, probably name the same thing: their names have
+// the same words ignoring case and punctuation, or they are of the same
+// type and every word of one name appears in the other, as "Alice" and
+// "Alice Smith". The second rule also matches "Alice Jones", which is why
+// duplicates are listed for review rather than merged automatically.
+func likelyDuplicateEntities(a, b types.Entity, aWords, bWords []string) bool {
+	if len(aWords) == 0 || len(bWords) == 0 {
+		return false
+	}
+	if slices.Equal(aWords, bWords) {
+		return true
+	}
+	if a.Type != b.Type {
+		return false
+	}
+	if len(aWords) > len(bWords) {
+		aWords, bWords = bWords, aWords
+	}
+	for _, w := range aWords {
+		if !slices.Contains(bWords, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// entityNameWords returns the lower-cased words of an entity name, with
+// punctuation dropped.
+func entityNameWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// entitySummary converts an entity listed by ListEntities for a
+// list_entities result.
+func entitySummary(e types.Entity) EntitySummary {
+	return EntitySummary{
+		ID:          e.ID,
+		Name:        e.Name,
+		Type:        e.Type,
+		MemoryCount: e.MemoryCount,
+		FirstSeen:   e.FirstSeen.Format(time.RFC3339),
+		LastSeen:    e.LastSeen.Format(time.RFC3339),
+	}
+}
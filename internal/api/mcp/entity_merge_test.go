@@ -0,0 +1,105 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEntityStore returns a server over a store holding the given entities,
+// each {id, name, type}. Earlier entities are linked to more memories, so
+// they list first.
+func newEntityStore(t *testing.T, entities [][3]string) (*mcp.Server, *sqlite.MemoryStore) {
+	t.Helper()
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	db := store.GetDB()
+	now := time.Now()
+	for i, e := range entities {
+		_, err := db.ExecContext(ctx, `INSERT INTO entities (id, name, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			e[0], e[1], e[2], now, now)
+		require.NoError(t, err)
+		for n := 0; n < len(entities)-i; n++ {
+			mem, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: e[1] + " note " + string(rune('a'+n))})
+			require.NoError(t, err)
+			_, err = db.ExecContext(ctx, `INSERT INTO memory_entities (memory_id, entity_id, created_at) VALUES (?, ?, ?)`,
+				mem.ID, e[0], now)
+			require.NoError(t, err)
+		}
+	}
+	return srv, store
+}
+
+func TestListEntities_Duplicates(t *testing.T) {
+	srv, _ := newEntityStore(t, [][3]string{
+		{"ent:alice", "Alice", "person"},
+		{"ent:alice-smith", "Alice Smith", "person"},
+		{"ent:bob", "Bob", "person"},
+		{"ent:alice-lower", "alice", "organization"},
+		{"ent:go", "Go", "language"},
+		{"ent:smith-co", "Smith & Co.", "organization"},
+	})
+
+	var result mcp.ListEntitiesResult
+	callRPC(t, srv, "list_entities", map[string]interface{}{"duplicates": true}, &result)
+
+	assert.Empty(t, result.Entities)
+	assert.Equal(t, 1, result.Total)
+	require.Len(t, result.DuplicateGroups, 1)
+	group := result.DuplicateGroups[0]
+	assert.Equal(t, "ent:alice", group.CanonicalID)
+	var ids []string
+	for _, e := range group.Entities {
+		ids = append(ids, e.ID)
+	}
+	// "Smith & Co." shares a word with "Alice Smith" but neither name
+	// contains the other.
+	assert.Equal(t, []string{"ent:alice", "ent:alice-smith", "ent:alice-lower"}, ids)
+}
+
+func TestMergeEntities(t *testing.T) {
+	srv, _ := newEntityStore(t, [][3]string{
+		{"ent:alice", "Alice", "person"},
+		{"ent:alice-smith", "Alice Smith", "person"},
+		{"ent:alice-lower", "alice", "person"},
+	})
+	ctx := context.Background()
+
+	var result mcp.MergeEntitiesResult
+	callRPC(t, srv, "merge_entities", map[string]interface{}{
+		"canonical_id":  "ent:alice",
+		"duplicate_ids": []string{"ent:alice-smith", "ent:alice-lower"},
+	}, &result)
+	assert.Equal(t, []string{"ent:alice-smith", "ent:alice-lower"}, result.MergedIDs)
+	assert.Equal(t, 3, result.MemoriesRepointed)
+
+	entities, err := srv.ListEntities(ctx, mcp.ListEntitiesArgs{})
+	require.NoError(t, err)
+	require.Len(t, entities.Entities, 1)
+	assert.Equal(t, "ent:alice", entities.Entities[0].ID)
+	assert.Equal(t, 6, entities.Entities[0].MemoryCount)
+}
+
+func TestMergeEntities_InvalidArgs(t *testing.T) {
+	srv, _ := newEntityStore(t, [][3]string{
+		{"ent:alice", "Alice", "person"},
+		{"ent:alice-lower", "alice", "person"},
+	})
+
+	for params, want := range map[string]int{
+		`{"duplicate_ids":["ent:alice-lower"]}`:                                                   mcp.ErrCodeInvalidParams,
+		`{"canonical_id":"ent:alice"}`:                                                            mcp.ErrCodeInvalidParams,
+		`{"canonical_id":"ent:alice","duplicate_ids":["ent:alice"]}`:                              mcp.ErrCodeInvalidParams,
+		`{"canonical_id":"ent:alice","duplicate_ids":["ent:missing"]}`:                            mcp.ErrCodeNotFound,
+		`{"canonical_id":"ent:alice","duplicate_ids":["ent:alice-lower"],"connection_id":"nope"}`: mcp.ErrCodeInvalidParams,
+	} {
+		assert.Equal(t, want, rpcErrorCode(t, srv,
+			`{"jsonrpc":"2.0","method":"merge_entities","params":`+params+`,"id":1}`), params)
+	}
+}
@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// RecallAboutEntity finds the entities named args.Entity and returns the
+// memories linked to them within the since/until window, newest first and
+// grouped by domain. It combines entity lookup (list_entities) with the
+// created_at bounds of recall_memory.
+func (s *Server) RecallAboutEntity(ctx context.Context, args RecallAboutEntityArgs) (*RecallAboutEntityResult, error) {
+	name := strings.TrimSpace(args.Entity)
+	if name == "" {
+		return nil, invalidParamsf("entity is required")
+	}
+	if args.Type != "" && !types.IsValidEntityType(args.Type) {
+		return nil, invalidParamsf("type %q is not a valid entity type", args.Type)
+	}
+	since, err := parseAuditTime("since", args.Since)
+	if err != nil {
+		return nil, err
+	}
+	until, err := parseAuditTime("until", args.Until)
+	if err != nil {
+		return nil, err
+	}
+	if !since.IsZero() && !until.IsZero() && !until.After(since) {
+		return nil, invalidParamsf("until (%s) must be after since (%s)", args.Until, args.Since)
+	}
+
+	store, _ := s.resolveSearchStore(args.ConnectionID)
+
+	entityOpts := storage.EntityListOptions{Name: name, Type: args.Type, Limit: storage.MaxLimit}
+	entities, err := store.ListEntities(ctx, entityOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up entity: %w", err)
+	}
+	result := &RecallAboutEntityResult{
+		Entity:  name,
+		Matches: make([]EntitySummary, len(entities.Items)),
+		Groups:  []EntityDomainGroup{},
+	}
+	entityIDs := make([]string, len(entities.Items))
+	for i, e := range entities.Items {
+		entityIDs[i] = e.ID
+		result.Matches[i] = EntitySummary{
+			ID:          e.ID,
+			Name:        e.Name,
+			Type:        e.Type,
+			MemoryCount: e.MemoryCount,
+			FirstSeen:   e.FirstSeen.Format(time.RFC3339),
+			LastSeen:    e.LastSeen.Format(time.RFC3339),
+		}
+	}
+	if len(entityIDs) == 0 {
+		result.Summary = fmt.Sprintf("no entity named %q", name)
+		return result, nil
+	}
+
+	opts := storage.ListOptions{
+		Limit:         s.effectiveLimit(args.Limit),
+		SortBy:        "created_at",
+		SortOrder:     "desc",
+		EntityIDs:     entityIDs,
+		CreatedAfter:  since,
+		CreatedBefore: until,
+	}
+	opts.Normalize()
+	memories, err := store.List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories about %q: %w", name, err)
+	}
+
+	topics, summary := summarizeTopics(memories.Items, "about "+name)
+	byDomain := make(map[string]*EntityDomainGroup, len(topics))
+	for _, t := range topics {
+		result.Groups = append(result.Groups, EntityDomainGroup{Domain: t.Domain, Count: t.Count, Memories: []types.Memory{}})
+	}
+	for i := range result.Groups {
+		byDomain[result.Groups[i].Domain] = &result.Groups[i]
+	}
+	for _, m := range memories.Items {
+		domain := m.Domain
+		if domain == "" {
+			domain = "general"
+		}
+		group := byDomain[domain]
+		group.Memories = append(group.Memories, m)
+	}
+
+	result.Returned = len(memories.Items)
+	result.Total = memories.Total
+	result.Summary = summary
+	if memories.Total > len(memories.Items) {
+		result.Summary += fmt.Sprintf(" (%d more not shown)", memories.Total-len(memories.Items))
+	}
+	return result, nil
+}
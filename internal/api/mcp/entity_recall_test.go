@@ -0,0 +1,122 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEntityRecallServer stores memories about Acme in two domains, one of
+// them a year old, plus one unrelated memory.
+func newEntityRecallServer(t *testing.T) (*mcp.Server, time.Time) {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	memories := []struct {
+		id, domain string
+		age        time.Duration
+		acme       bool
+	}{
+		{"mem:test:pricing", "sales", 24 * time.Hour, true},
+		{"mem:test:contract", "legal", 48 * time.Hour, true},
+		{"mem:test:kickoff", "sales", 72 * time.Hour, true},
+		{"mem:test:old-pitch", "sales", 365 * 24 * time.Hour, true},
+		{"mem:test:lunch", "personal", 24 * time.Hour, false},
+	}
+	db := store.GetDB()
+	_, err = db.ExecContext(ctx, `INSERT INTO entities (id, name, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		"ent:acme", "Acme", types.EntityTypeOrganization, now, now)
+	require.NoError(t, err)
+	for _, m := range memories {
+		require.NoError(t, store.Store(ctx, &types.Memory{
+			ID: m.id, Content: "Note " + m.id, Source: "test", Domain: m.domain,
+			Status: types.StatusEnriched, CreatedAt: now.Add(-m.age),
+		}))
+		if m.acme {
+			_, err = db.ExecContext(ctx, `INSERT INTO memory_entities (memory_id, entity_id, frequency, confidence) VALUES (?, ?, 1, 0.9)`, m.id, "ent:acme")
+			require.NoError(t, err)
+		}
+	}
+	return mcp.NewServer(store), now
+}
+
+func TestRecallAboutEntity_GroupsByDomain(t *testing.T) {
+	srv, now := newEntityRecallServer(t)
+
+	var result mcp.RecallAboutEntityResult
+	callRPC(t, srv, "recall_about_entity", map[string]interface{}{
+		"entity": "acme",
+		"since":  now.Add(-30 * 24 * time.Hour).Format(time.RFC3339),
+	}, &result)
+
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "ent:acme", result.Matches[0].ID)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 3, result.Returned)
+	assert.Equal(t, "3 memories about acme: sales (2), legal (1)", result.Summary)
+
+	require.Len(t, result.Groups, 2)
+	sales, legal := result.Groups[0], result.Groups[1]
+	assert.Equal(t, "sales", sales.Domain)
+	assert.Equal(t, 2, sales.Count)
+	require.Len(t, sales.Memories, 2)
+	assert.Equal(t, "mem:test:pricing", sales.Memories[0].ID, "newest first")
+	assert.Equal(t, "mem:test:kickoff", sales.Memories[1].ID)
+	assert.Equal(t, "legal", legal.Domain)
+	require.Len(t, legal.Memories, 1)
+	assert.Equal(t, "mem:test:contract", legal.Memories[0].ID)
+}
+
+func TestRecallAboutEntity_WindowAndLimit(t *testing.T) {
+	srv, now := newEntityRecallServer(t)
+	ctx := context.Background()
+
+	old, err := srv.RecallAboutEntity(ctx, mcp.RecallAboutEntityArgs{
+		Entity: "Acme",
+		Until:  now.Add(-30 * 24 * time.Hour).Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	require.Len(t, old.Groups, 1)
+	assert.Equal(t, "mem:test:old-pitch", old.Groups[0].Memories[0].ID)
+
+	limited, err := srv.RecallAboutEntity(ctx, mcp.RecallAboutEntityArgs{Entity: "Acme", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, limited.Returned)
+	assert.Equal(t, 4, limited.Total)
+	assert.Contains(t, limited.Summary, "2 more not shown")
+
+	missing, err := srv.RecallAboutEntity(ctx, mcp.RecallAboutEntityArgs{Entity: "Globex"})
+	require.NoError(t, err)
+	assert.Empty(t, missing.Matches)
+	assert.Empty(t, missing.Groups)
+	assert.Equal(t, `no entity named "Globex"`, missing.Summary)
+
+	wrongType, err := srv.RecallAboutEntity(ctx, mcp.RecallAboutEntityArgs{Entity: "Acme", Type: types.EntityTypePerson})
+	require.NoError(t, err)
+	assert.Empty(t, wrongType.Matches)
+}
+
+func TestRecallAboutEntity_InvalidArgs(t *testing.T) {
+	srv, _ := newEntityRecallServer(t)
+
+	for _, params := range []string{
+		`{}`,
+		`{"entity":"  "}`,
+		`{"entity":"Acme","type":"planet"}`,
+		`{"entity":"Acme","since":"last quarter"}`,
+		`{"entity":"Acme","since":"2026-02-01T00:00:00Z","until":"2026-01-01T00:00:00Z"}`,
+	} {
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv,
+			`{"jsonrpc":"2.0","method":"recall_about_entity","params":`+params+`,"id":1}`), params)
+	}
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// ErrRequestTimeout is returned when a request runs longer than the timeout
+// set with WithRequestTimeout.
+var ErrRequestTimeout = errors.New("request timed out")
+
+// Error is a handler error that carries a JSON-RPC error code.
+// HandleRequest reports the code verbatim so MCP clients can tell
+// "not found" from "invalid input" from an internal failure without
+// string-matching the message (e.g. to decide between retrying and
+// surfacing the error to the user).
+type Error struct {
+	Code    int    // JSON-RPC error code (one of the ErrCode* constants)
+	Message string // Human-readable error message
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// invalidParamsf returns an *Error with ErrCodeInvalidParams for argument
+// validation failures.
+func invalidParamsf(format string, args ...interface{}) error {
+	return &Error{Code: ErrCodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// notFoundf returns an *Error with ErrCodeNotFound for lookups of memories
+// (or projects, parents, ...) that do not exist.
+func notFoundf(format string, args ...interface{}) error {
+	return &Error{Code: ErrCodeNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// errorCode maps a handler error to the JSON-RPC error code reported to the
+// client. Explicit *Error values win; well-known sentinel errors from the
+// storage layer are mapped next; everything else is a generic server error.
+func errorCode(err error) int {
+	var mcpErr *Error
+	switch {
+	case errors.As(err, &mcpErr):
+		return mcpErr.Code
+	case errors.Is(err, storage.ErrNotFound):
+		return ErrCodeNotFound
+	case errors.Is(err, storage.ErrInvalidInput):
+		return ErrCodeInvalidParams
+	case errors.Is(err, ErrReadOnly), errors.Is(err, ErrConnectionReadOnly):
+		return ErrCodeReadOnly
+	case errors.Is(err, ErrRequestTimeout), errors.Is(err, engine.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return ErrCodeTimeout
+	default:
+		return ErrCodeServerError
+	}
+}
@@ -0,0 +1,4 @@
+package mcp
+
+// WithMemoryIDHash exposes withMemoryIDHash to the external tests.
+var WithMemoryIDHash = withMemoryIDHash
@@ -0,0 +1,59 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMemory_Found(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t)
+
+	stored, err := srv.StoreMemory(context.Background(), mcp.StoreMemoryArgs{Content: "Standup moved to 9:30"})
+	require.NoError(t, err)
+
+	var result mcp.GetMemoryResult
+	callRPC(t, srv, "get_memory", map[string]interface{}{"id": stored.ID}, &result)
+	assert.True(t, result.Found)
+	require.NotNil(t, result.Memory)
+	assert.Equal(t, stored.ID, result.Memory.ID)
+	assert.Equal(t, "Standup moved to 9:30", result.Memory.Content)
+}
+
+func TestGetMemory_NotFound(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t)
+
+	var result mcp.GetMemoryResult
+	callRPC(t, srv, "get_memory", map[string]interface{}{"id": "mem:test:missing"}, &result)
+	assert.False(t, result.Found)
+	assert.Nil(t, result.Memory)
+}
+
+func TestGetMemory_RequiresID(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t)
+
+	for _, params := range []string{`{}`, `{"id":""}`, `{"id":"  "}`} {
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv,
+			`{"jsonrpc":"2.0","method":"get_memory","params":`+params+`,"id":1}`), params)
+	}
+}
+
+func TestGetMemory_ConnectionID(t *testing.T) {
+	srv := newReadOnlyConnectionServer(t)
+	ctx := context.Background()
+
+	result, err := srv.GetMemory(ctx, mcp.GetMemoryArgs{ID: "mem:archive:one", ConnectionID: "archive"})
+	require.NoError(t, err)
+	assert.True(t, result.Found)
+
+	// The memory lives in archive, not work.
+	result, err = srv.GetMemory(ctx, mcp.GetMemoryArgs{ID: "mem:archive:one", ConnectionID: "work"})
+	require.NoError(t, err)
+	assert.False(t, result.Found)
+
+	_, err = srv.GetMemory(ctx, mcp.GetMemoryArgs{ID: "mem:archive:one", ConnectionID: "nowhere"})
+	require.Error(t, err)
+}
@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// Bounds on the number of nodes get_graph_export returns.
+const (
+	defaultGraphExportNodes = 500
+	maxGraphExportNodes     = 5000
+)
+
+// graphLabelLength is the most runes of a memory's content used as its
+// node label.
+const graphLabelLength = 80
+
+// GetGraphExport exports the memory and entity graph of a connection for
+// visualization tools: memory and entity nodes, with edges for the entities
+// each memory mentions, the relationships between entities and the links
+// between memories. The newest memories and the entities they mention most
+// are kept within max_nodes. The json format is node-link JSON as read by
+// D3; graphml is read by Gephi and most other graph tools.
+func (s *Server) GetGraphExport(ctx context.Context, args GetGraphExportArgs) (*GetGraphExportResult, error) {
+	format := args.Format
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "graphml" {
+		return nil, invalidParamsf("format must be json or graphml, got %q", args.Format)
+	}
+	createdAfter, createdBefore, err := parseTimeRange(args.CreatedAfter, args.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	maxNodes := args.MaxNodes
+	if maxNodes <= 0 {
+		maxNodes = defaultGraphExportNodes
+	}
+	maxNodes = min(maxNodes, maxGraphExportNodes)
+
+	store, _ := s.resolveSearchStore(args.ConnectionID)
+	exporter, ok := store.(storage.GraphExporter)
+	if !ok {
+		return nil, fmt.Errorf("store does not support graph export")
+	}
+	graph, err := exporter.ExportGraph(ctx, storage.GraphExportOptions{
+		Domain:        args.Domain,
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		MaxNodes:      maxNodes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export graph: %w", err)
+	}
+
+	nodes := make([]GraphNode, 0, len(graph.Memories)+len(graph.Entities))
+	for _, m := range graph.Memories {
+		nodes = append(nodes, GraphNode{
+			ID:        m.ID,
+			Kind:      "memory",
+			Label:     graphLabel(m.Content),
+			Type:      m.MemoryType,
+			Domain:    m.Domain,
+			State:     m.State,
+			CreatedAt: m.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	for _, e := range graph.Entities {
+		nodes = append(nodes, GraphNode{
+			ID:          e.ID,
+			Kind:        "entity",
+			Label:       e.Name,
+			Type:        e.Type,
+			MemoryCount: e.MemoryCount,
+		})
+	}
+	edges := make([]GraphEdge, 0, len(graph.Mentions)+len(graph.Relationships)+len(graph.Links))
+	for _, m := range graph.Mentions {
+		edges = append(edges, GraphEdge{Source: m.MemoryID, Target: m.EntityID, Kind: "mentions", Weight: m.Confidence})
+	}
+	for _, r := range graph.Relationships {
+		edges = append(edges, GraphEdge{Source: r.FromID, Target: r.ToID, Kind: "relationship", Type: r.Type, Weight: r.Strength})
+	}
+	for _, l := range graph.Links {
+		edges = append(edges, GraphEdge{Source: l.SourceID, Target: l.TargetID, Kind: "link", Type: l.Type})
+	}
+
+	result := &GetGraphExportResult{
+		Format:    format,
+		NodeCount: len(nodes),
+		EdgeCount: len(edges),
+		Truncated: graph.Truncated,
+	}
+	if format == "graphml" {
+		if result.GraphML, err = encodeGraphML(nodes, edges); err != nil {
+			return nil, fmt.Errorf("failed to encode GraphML: %w", err)
+		}
+	} else {
+		result.Nodes, result.Links = nodes, edges
+	}
+	return result, nil
+}
+
+// graphLabel returns the first line of content, cut to graphLabelLength
+// runes.
+func graphLabel(content string) string {
+	label, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	if utf8.RuneCountInString(label) <= graphLabelLength {
+		return label
+	}
+	return string([]rune(label)[:graphLabelLength-1]) + "…"
+}
+
+// GraphML document structure. Node and edge attributes are GraphML data
+// elements keyed by the graphmlKeys declarations.
+type (
+	graphmlDoc struct {
+		XMLName xml.Name     `xml:"graphml"`
+		XMLNS   string       `xml:"xmlns,attr"`
+		Keys    []graphmlKey `xml:"key"`
+		Graph   graphmlGraph `xml:"graph"`
+	}
+	graphmlKey struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		AttrName string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	}
+	graphmlGraph struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphmlNode `xml:"node"`
+		Edges       []graphmlEdge `xml:"edge"`
+	}
+	graphmlNode struct {
+		ID   string        `xml:"id,attr"`
+		Data []graphmlData `xml:"data"`
+	}
+	graphmlEdge struct {
+		Source string        `xml:"source,attr"`
+		Target string        `xml:"target,attr"`
+		Data   []graphmlData `xml:"data"`
+	}
+	graphmlData struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+)
+
+// graphmlKeys declares the attributes of exported nodes and edges.
+var graphmlKeys = []graphmlKey{
+	{ID: "kind", For: "all", AttrName: "kind", AttrType: "string"},
+	{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+	{ID: "type", For: "all", AttrName: "type", AttrType: "string"},
+	{ID: "domain", For: "node", AttrName: "domain", AttrType: "string"},
+	{ID: "state", For: "node", AttrName: "state", AttrType: "string"},
+	{ID: "created_at", For: "node", AttrName: "created_at", AttrType: "string"},
+	{ID: "memory_count", For: "node", AttrName: "memory_count", AttrType: "int"},
+	{ID: "weight", For: "edge", AttrName: "weight", AttrType: "double"},
+}
+
+// encodeGraphML renders nodes and edges as a directed GraphML document,
+// leaving out attributes that are not set.
+func encodeGraphML(nodes []GraphNode, edges []GraphEdge) (string, error) {
+	doc := graphmlDoc{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphmlKeys,
+		Graph: graphmlGraph{ID: "memento", EdgeDefault: "directed"},
+	}
+	for _, n := range nodes {
+		node := graphmlNode{ID: n.ID}
+		node.Data = appendGraphMLData(node.Data, "kind", n.Kind)
+		node.Data = appendGraphMLData(node.Data, "label", n.Label)
+		node.Data = appendGraphMLData(node.Data, "type", n.Type)
+		node.Data = appendGraphMLData(node.Data, "domain", n.Domain)
+		node.Data = appendGraphMLData(node.Data, "state", n.State)
+		node.Data = appendGraphMLData(node.Data, "created_at", n.CreatedAt)
+		if n.MemoryCount > 0 {
+			node.Data = appendGraphMLData(node.Data, "memory_count", strconv.Itoa(n.MemoryCount))
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for _, e := range edges {
+		edge := graphmlEdge{Source: e.Source, Target: e.Target}
+		edge.Data = appendGraphMLData(edge.Data, "kind", e.Kind)
+		edge.Data = appendGraphMLData(edge.Data, "type", e.Type)
+		if e.Weight != 0 {
+			edge.Data = appendGraphMLData(edge.Data, "weight", strconv.FormatFloat(e.Weight, 'g', -1, 64))
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(out) + "\n", nil
+}
+
+// appendGraphMLData appends a data element for key unless value is empty.
+func appendGraphMLData(data []graphmlData, key, value string) []graphmlData {
+	if value == "" {
+		return data
+	}
+	return append(data, graphmlData{Key: key, Value: value})
+}
@@ -0,0 +1,122 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGraphExportServer returns a server over a store holding two memories,
+// linked to each other, that mention two related entities.
+func newGraphExportServer(t *testing.T) *mcp.Server {
+	t.Helper()
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Alice started the <memento> project\nwith notes"})
+	require.NoError(t, err)
+	second, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Alice shipped memento 1.0"})
+	require.NoError(t, err)
+	require.NoError(t, store.CreateMemoryLink(ctx, "link:1", second.ID, first.ID, "FOLLOWS"))
+
+	db := store.GetDB()
+	now := time.Now()
+	for _, stmt := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{`INSERT INTO entities (id, name, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`, []interface{}{"ent:alice", "Alice", "person", now, now}},
+		{`INSERT INTO entities (id, name, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`, []interface{}{"ent:memento", "Memento", "project", now, now}},
+		{`INSERT INTO memory_entities (memory_id, entity_id, created_at) VALUES (?, ?, ?)`, []interface{}{first.ID, "ent:alice", now}},
+		{`INSERT INTO memory_entities (memory_id, entity_id, created_at) VALUES (?, ?, ?)`, []interface{}{first.ID, "ent:memento", now}},
+		{`INSERT INTO memory_entities (memory_id, entity_id, created_at) VALUES (?, ?, ?)`, []interface{}{second.ID, "ent:alice", now}},
+		{`INSERT INTO relationships (id, source_id, target_id, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`, []interface{}{"rel:1", "ent:alice", "ent:memento", "works_on", now, now}},
+	} {
+		_, err := db.ExecContext(ctx, stmt.query, stmt.args...)
+		require.NoError(t, err)
+	}
+	return srv
+}
+
+func TestGetGraphExport_JSON(t *testing.T) {
+	srv := newGraphExportServer(t)
+
+	var result mcp.GetGraphExportResult
+	callRPC(t, srv, "get_graph_export", map[string]interface{}{}, &result)
+
+	assert.Equal(t, "json", result.Format)
+	assert.Equal(t, 4, result.NodeCount)
+	assert.Equal(t, 5, result.EdgeCount)
+	assert.False(t, result.Truncated)
+	require.Len(t, result.Nodes, 4)
+	require.Len(t, result.Links, 5)
+
+	kinds := map[string]int{}
+	for _, e := range result.Links {
+		kinds[e.Kind]++
+	}
+	assert.Equal(t, map[string]int{"mentions": 3, "relationship": 1, "link": 1}, kinds)
+	for _, n := range result.Nodes {
+		if n.ID == "ent:alice" {
+			assert.Equal(t, "entity", n.Kind)
+			assert.Equal(t, 2, n.MemoryCount)
+		}
+		if n.Kind == "memory" {
+			assert.NotContains(t, n.Label, "\n")
+		}
+	}
+}
+
+func TestGetGraphExport_GraphML(t *testing.T) {
+	srv := newGraphExportServer(t)
+
+	result, err := srv.GetGraphExport(context.Background(), mcp.GetGraphExportArgs{Format: "graphml"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Nodes)
+	assert.True(t, strings.HasPrefix(result.GraphML, xml.Header))
+
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(result.GraphML), &doc))
+	assert.Len(t, doc.Graph.Nodes, 4)
+	assert.Len(t, doc.Graph.Edges, 5)
+	assert.Contains(t, result.GraphML, "&lt;memento&gt;")
+}
+
+func TestGetGraphExport_Cap(t *testing.T) {
+	srv := newGraphExportServer(t)
+
+	result, err := srv.GetGraphExport(context.Background(), mcp.GetGraphExportArgs{MaxNodes: 3})
+	require.NoError(t, err)
+	assert.True(t, result.Truncated)
+	assert.Equal(t, 3, result.NodeCount)
+	// The memento entity is dropped with its mention and relationship.
+	assert.Equal(t, 3, result.EdgeCount)
+}
+
+func TestGetGraphExport_InvalidArgs(t *testing.T) {
+	srv := newGraphExportServer(t)
+
+	for _, params := range []string{
+		`{"format":"dot"}`,
+		`{"created_after":"yesterday"}`,
+		`{"created_after":"2026-02-01T00:00:00Z","created_before":"2026-01-01T00:00:00Z"}`,
+	} {
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv,
+			`{"jsonrpc":"2.0","method":"get_graph_export","params":`+params+`,"id":1}`), params)
+	}
+}
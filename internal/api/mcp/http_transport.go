@@ -0,0 +1,322 @@
+// Package mcp – http_transport.go provides the HTTPTransport that serves one
+// MCP Server to several clients at once over HTTP, following the MCP
+// "Streamable HTTP" transport:
+//   - POST delivers one JSON-RPC message. A request gets its response as the
+//     JSON body; a notification (no id) gets 202 Accepted.
+//   - GET opens a Server-Sent Events stream that carries the server's
+//     notifications to that client.
+//   - DELETE ends the session.
+//
+// A successful initialize response carries an Mcp-Session-Id header; every
+// later request from that client must send it back. Sessions only correlate
+// transport state (notification opt-in, the open stream): all clients share
+// the same Server, its stores and its memory session.
+package mcp
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionHeader is the HTTP header carrying the session ID assigned by
+// HTTPTransport during initialize.
+const SessionHeader = "Mcp-Session-Id"
+
+const (
+	// maxHTTPRequestBytes caps a POST body, matching the default stdio line
+	// limit.
+	maxHTTPRequestBytes = DefaultMaxRequestBytes
+
+	// httpStreamBuffer is how many notifications may wait for a slow SSE
+	// client before further ones are dropped.
+	httpStreamBuffer = 64
+
+	// httpKeepAliveInterval is how often an idle SSE stream gets a comment
+	// line, so proxies do not close it.
+	httpKeepAliveInterval = 30 * time.Second
+
+	// httpSessionIdleTimeout is how long a session without an open stream
+	// survives without requests before it is forgotten.
+	httpSessionIdleTimeout = time.Hour
+)
+
+// HTTPTransport serves a Server over HTTP with an SSE notification stream.
+// It implements http.Handler; mount it at a single path such as /mcp.
+type HTTPTransport struct {
+	server *Server
+	token  string
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// httpSession is the transport state of one HTTP client.
+type httpSession struct {
+	notify   bool        // client opted in to enrichment notifications during initialize
+	events   chan []byte // notification frames for the open SSE stream; nil when none is open
+	lastSeen time.Time
+}
+
+// HTTPTransportOption configures optional HTTPTransport behaviour.
+type HTTPTransportOption func(*HTTPTransport)
+
+// WithBearerToken makes the transport reject requests whose Authorization
+// header is not "Bearer <token>". An empty token leaves the transport open.
+func WithBearerToken(token string) HTTPTransportOption {
+	return func(t *HTTPTransport) {
+		t.token = token
+	}
+}
+
+// NewHTTPTransport constructs an HTTPTransport for srv and attaches it as the
+// carrier of the server's notifications, each of which goes to every client
+// that opted in during its own initialize.
+//
+// Usage:
+//
+//	mux.Handle("/mcp", mcp.NewHTTPTransport(srv))
+func NewHTTPTransport(srv *Server, opts ...HTTPTransportOption) *HTTPTransport {
+	t := &HTTPTransport{
+		server:   srv,
+		logger:   slog.Default().With("component", "http_transport"),
+		sessions: make(map[string]*httpSession),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	srv.notifications.setPerClientSender(t.broadcast)
+	return t
+}
+
+// ServeHTTP dispatches on the request method (see the package comment above).
+func (t *HTTPTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if t.token != "" {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		t.handleMessage(w, r)
+	case http.MethodGet:
+		t.handleStream(w, r)
+	case http.MethodDelete:
+		t.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleMessage runs one JSON-RPC message through Server.HandleRequest.
+// initialize opens a new session; anything else must name an open one.
+func (t *HTTPTransport) handleMessage(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPRequestBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	// Malformed JSON leaves the envelope empty; HandleRequest reports it.
+	var envelope struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			Capabilities map[string]interface{} `json:"capabilities"`
+		} `json:"params"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+
+	if envelope.Method != "initialize" {
+		if !t.checkSession(w, r) {
+			return
+		}
+	}
+
+	resp, err := t.server.HandleRequest(r.Context(), body)
+	if err != nil {
+		t.logger.Error("handler error", "error", err)
+		resp = internalErrorResponse(body, err)
+	}
+
+	if envelope.Method == "initialize" && !isErrorResponse(resp) {
+		w.Header().Set(SessionHeader, t.openSession(clientWantsEnrichmentNotifications(envelope.Params.Capabilities)))
+	}
+	if envelope.Method != "" && len(envelope.ID) == 0 {
+		// A notification: nothing to send back.
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(resp); err != nil {
+		t.logger.Warn("write error", "error", err)
+	}
+}
+
+// handleStream holds an SSE stream open and writes the session's
+// notifications to it until the client disconnects or the session ends.
+func (t *HTTPTransport) handleStream(w http.ResponseWriter, r *http.Request) {
+	if !t.checkSession(w, r) {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	id := r.Header.Get(SessionHeader)
+	events, ok := t.attachStream(id)
+	if !ok {
+		http.Error(w, "a stream is already open for this session", http.StatusConflict)
+		return
+	}
+	defer t.detachStream(id, events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(httpKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case frame, open := <-events:
+			if !open {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", frame); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// handleDelete ends a session, closing its stream if one is open.
+func (t *HTTPTransport) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if !t.checkSession(w, r) {
+		return
+	}
+	t.mu.Lock()
+	id := r.Header.Get(SessionHeader)
+	if sess := t.sessions[id]; sess != nil && sess.events != nil {
+		close(sess.events)
+	}
+	delete(t.sessions, id)
+	t.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkSession verifies the request names an open session and records the
+// activity. Otherwise it writes 400 (no session header) or 404 (unknown or
+// ended session, so the client should initialize again) and returns false.
+func (t *HTTPTransport) checkSession(w http.ResponseWriter, r *http.Request) bool {
+	id := r.Header.Get(SessionHeader)
+	if id == "" {
+		http.Error(w, "missing "+SessionHeader+" header; send initialize first", http.StatusBadRequest)
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sess, ok := t.sessions[id]
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return false
+	}
+	sess.lastSeen = time.Now()
+	return true
+}
+
+// openSession registers a new session and returns its ID. Sessions idle for
+// longer than httpSessionIdleTimeout without an open stream are forgotten.
+func (t *HTTPTransport) openSession(notify bool) string {
+	id := uuid.New().String()
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for other, sess := range t.sessions {
+		if sess.events == nil && now.Sub(sess.lastSeen) > httpSessionIdleTimeout {
+			delete(t.sessions, other)
+		}
+	}
+	t.sessions[id] = &httpSession{notify: notify, lastSeen: now}
+	return id
+}
+
+// attachStream gives session id a notification channel, unless it already
+// has a stream open or no longer exists.
+func (t *HTTPTransport) attachStream(id string) (chan []byte, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sess, ok := t.sessions[id]
+	if !ok || sess.events != nil {
+		return nil, false
+	}
+	sess.events = make(chan []byte, httpStreamBuffer)
+	return sess.events, true
+}
+
+// detachStream removes the notification channel of a stream that ended.
+func (t *HTTPTransport) detachStream(id string, events chan []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sess, ok := t.sessions[id]; ok && sess.events == events {
+		sess.events = nil
+		sess.lastSeen = time.Now()
+	}
+}
+
+// broadcast queues a notification frame on the stream of every session that
+// opted in to notifications. A client whose stream is not draining misses
+// the frame rather than blocking the sender.
+func (t *HTTPTransport) broadcast(frame []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, sess := range t.sessions {
+		if !sess.notify || sess.events == nil {
+			continue
+		}
+		select {
+		case sess.events <- frame:
+		default:
+			t.logger.Warn("notification dropped: client stream is full", "session_id", id)
+		}
+	}
+	return nil
+}
+
+// isErrorResponse reports whether a JSON-RPC response frame carries an error.
+func isErrorResponse(resp []byte) bool {
+	var frame struct {
+		Error json.RawMessage `json:"error"`
+	}
+	return json.Unmarshal(resp, &frame) == nil && len(frame.Error) > 0 && string(frame.Error) != "null"
+}
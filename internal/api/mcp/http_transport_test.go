@@ -0,0 +1,178 @@
+package mcp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHTTPTestServer serves a sqlite-backed Server through an HTTPTransport.
+func newHTTPTestServer(t *testing.T, opts ...mcp.HTTPTransportOption) (*mcp.Server, *httptest.Server) {
+	t.Helper()
+	srv, _ := newSQLiteTestServer(t)
+	ts := httptest.NewServer(mcp.NewHTTPTransport(srv, opts...))
+	t.Cleanup(ts.Close)
+	return srv, ts
+}
+
+// postRPC POSTs body to the transport with the given session header.
+func postRPC(t *testing.T, url, session, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if session != "" {
+		req.Header.Set(mcp.SessionHeader, session)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+// initializeHTTP runs initialize and returns the assigned session ID.
+func initializeHTTP(t *testing.T, url string, capabilities string) string {
+	t.Helper()
+	resp := postRPC(t, url, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":`+capabilities+`}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var frame map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&frame))
+	require.Contains(t, frame, "result")
+	session := resp.Header.Get(mcp.SessionHeader)
+	require.NotEmpty(t, session)
+	return session
+}
+
+func TestHTTPTransport_InitializeAndToolsCall(t *testing.T) {
+	_, ts := newHTTPTestServer(t)
+	session := initializeHTTP(t, ts.URL, `{}`)
+
+	// Client notifications are accepted without a body.
+	resp := postRPC(t, ts.URL, session, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp = postRPC(t, ts.URL, session, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"store_memory","arguments":{"content":"HTTP transport smoke test"}}}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var frame struct {
+		ID     float64 `json:"id"`
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&frame))
+	assert.Equal(t, float64(2), frame.ID)
+	require.False(t, frame.Result.IsError)
+	require.NotEmpty(t, frame.Result.Content)
+	assert.Contains(t, frame.Result.Content[0].Text, "mem:")
+
+	resp = postRPC(t, ts.URL, session, `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"store_memory"`)
+}
+
+func TestHTTPTransport_SessionRequired(t *testing.T) {
+	_, ts := newHTTPTestServer(t)
+
+	resp := postRPC(t, ts.URL, "", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = postRPC(t, ts.URL, "no-such-session", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// Ending the session makes its ID unknown.
+	session := initializeHTTP(t, ts.URL, `{}`)
+	req, err := http.NewRequest(http.MethodDelete, ts.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(mcp.SessionHeader, session)
+	del, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = del.Body.Close()
+	assert.Equal(t, http.StatusNoContent, del.StatusCode)
+
+	resp = postRPC(t, ts.URL, session, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestHTTPTransport_BearerToken(t *testing.T) {
+	_, ts := newHTTPTestServer(t, mcp.WithBearerToken("s3cret"))
+
+	resp := postRPC(t, ts.URL, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	ok, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = ok.Body.Close()
+	assert.Equal(t, http.StatusOK, ok.StatusCode)
+	assert.NotEmpty(t, ok.Header.Get(mcp.SessionHeader))
+}
+
+// TestHTTPTransport_NotificationStream verifies that notifications reach the
+// SSE stream of a client that opted in during initialize.
+func TestHTTPTransport_NotificationStream(t *testing.T) {
+	srv, ts := newHTTPTestServer(t)
+	session := initializeHTTP(t, ts.URL, `{"experimental":{"`+mcp.EnrichmentNotificationsCapability+`":{}}}`)
+	// A second client that did not opt in must not turn notifications off.
+	initializeHTTP(t, ts.URL, `{}`)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(mcp.SessionHeader, session)
+	req.Header.Set("Accept", "text/event-stream")
+	stream, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = stream.Body.Close() })
+	require.Equal(t, http.StatusOK, stream.StatusCode)
+	assert.Equal(t, "text/event-stream", stream.Header.Get("Content-Type"))
+
+	// Only one stream per session.
+	second, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = second.Body.Close()
+	assert.Equal(t, http.StatusConflict, second.StatusCode)
+
+	events := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stream.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				events <- data
+				return
+			}
+		}
+	}()
+
+	srv.NotifyEnrichment("mem:general:abc", types.StatusEnriched)
+
+	select {
+	case data := <-events:
+		var frame struct {
+			Method string                     `json:"method"`
+			Params mcp.EnrichmentNotification `json:"params"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(data), &frame))
+		assert.Equal(t, mcp.EnrichmentNotificationMethod, frame.Method)
+		assert.Equal(t, "mem:general:abc", frame.Params.MemoryID)
+		assert.Equal(t, types.StatusEnriched, frame.Params.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the notification on the SSE stream")
+	}
+}
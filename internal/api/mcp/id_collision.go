@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// MaxIDDisambiguators bounds how many suffixed IDs (<id>-2, <id>-3, ...)
+// store_memory tries when content collides with a different memory's ID.
+const MaxIDDisambiguators = 100
+
+// contentHashFunc hashes normalized content into a deterministic memory ID,
+// whose slug is the first 8 bytes of the sum as 16 hex characters.
+type contentHashFunc func(content []byte) []byte
+
+func sha256Hash(content []byte) []byte {
+	h := sha256.Sum256(content)
+	return h[:]
+}
+
+// withMemoryIDHash replaces the SHA-256 hash of deterministic memory IDs.
+// It is a test seam: a constant hash forces ID collisions.
+func withMemoryIDHash(hash contentHashFunc) ServerOption {
+	return func(s *Server) {
+		s.idHash = hash
+	}
+}
+
+// WithIDCollisionCheck makes store_memory tell a true duplicate from a
+// hash prefix collision: content whose ID is taken by a memory with
+// different content is stored under a suffixed ID (see
+// resolveIDCollision) instead of being reported as a duplicate of the
+// unrelated memory. It is on by default.
+func WithIDCollisionCheck(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.idCollisionCheck = enabled
+	}
+}
+
+// contentCollides reports whether stored and incoming content share an ID
+// without being duplicates at the server's dedup normalization level.
+func (s *Server) contentCollides(stored, incoming string) bool {
+	return storage.NormalizeForDedup(s.dedupNormalization, stored) != storage.NormalizeForDedup(s.dedupNormalization, incoming)
+}
+
+// resolveIDCollision returns the ID to store content under when id is taken
+// by a memory with different content: the first of <id>-2, <id>-3, ... that
+// is free or already holds the same content, which is returned as well so
+// the store is reported as a duplicate of it.
+func (s *Server) resolveIDCollision(ctx context.Context, store storage.MemoryStore, id, content string) (string, *types.Memory, error) {
+	for n := 2; n <= MaxIDDisambiguators; n++ {
+		candidate := fmt.Sprintf("%s-%d", id, n)
+		existing, err := store.Get(ctx, candidate)
+		if errors.Is(err, storage.ErrNotFound) {
+			slog.Warn("memory ID collision: different content hashes to an existing ID, storing under a disambiguated ID",
+				"id", id, "new_id", candidate)
+			return candidate, nil, nil
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to check memory ID %s: %w", candidate, err)
+		}
+		if !s.contentCollides(existing.Content, content) {
+			return candidate, existing, nil
+		}
+	}
+	return "", nil, fmt.Errorf("memory ID %s collides with %d memories of different content", id, MaxIDDisambiguators)
+}
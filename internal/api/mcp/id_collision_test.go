@@ -0,0 +1,82 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// constantHash maps all content to the same memory ID, forcing collisions.
+func constantHash([]byte) []byte { return make([]byte, 32) }
+
+// TestStoreMemory_IDCollision verifies that different contents hashing to
+// the same ID prefix are stored as distinct memories, while resubmitting
+// either content is still reported as a duplicate.
+func TestStoreMemory_IDCollision(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithMemoryIDHash(constantHash))
+	ctx := context.Background()
+
+	first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "kestrel nests on the ledge"})
+	require.NoError(t, err)
+	second, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "budget review moved to Friday"})
+	require.NoError(t, err)
+	third, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "release notes drafted"})
+	require.NoError(t, err)
+
+	assert.False(t, second.Duplicate)
+	assert.False(t, third.Duplicate)
+	assert.Equal(t, first.ID+"-2", second.ID)
+	assert.Equal(t, first.ID+"-3", third.ID)
+	assert.Equal(t, "kestrel nests on the ledge", store.memories[first.ID].Content)
+	assert.Equal(t, "budget review moved to Friday", store.memories[second.ID].Content)
+	assert.Equal(t, "release notes drafted", store.memories[third.ID].Content)
+
+	again, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "budget review moved to Friday"})
+	require.NoError(t, err)
+	assert.True(t, again.Duplicate)
+	assert.Equal(t, second.ID, again.ID)
+
+	again, err = srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "kestrel nests on the ledge"})
+	require.NoError(t, err)
+	assert.True(t, again.Duplicate)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Len(t, store.memories, 3)
+}
+
+// TestStoreMemory_IDCollisionCheckDisabled verifies the previous behaviour,
+// where colliding content is reported as a duplicate and not stored.
+func TestStoreMemory_IDCollisionCheckDisabled(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithMemoryIDHash(constantHash), mcp.WithIDCollisionCheck(false))
+	ctx := context.Background()
+
+	first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "kestrel nests on the ledge"})
+	require.NoError(t, err)
+	second, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "budget review moved to Friday"})
+	require.NoError(t, err)
+
+	assert.True(t, second.Duplicate)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Len(t, store.memories, 1)
+}
+
+// TestStoreMemory_IDCollisionRespectsNormalization verifies that content
+// equivalent at the dedup normalization level is a duplicate, not a
+// collision.
+func TestStoreMemory_IDCollisionRespectsNormalization(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithDedupNormalization(config.DedupNormalized))
+	ctx := context.Background()
+
+	first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Hello World."})
+	require.NoError(t, err)
+	second, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "hello   world"})
+	require.NoError(t, err)
+	assert.True(t, second.Duplicate)
+	assert.Equal(t, first.ID, second.ID)
+}
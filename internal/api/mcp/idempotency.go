@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long a store_memory result is remembered for
+// its idempotency_key when WithIdempotencyTTL is not given.
+const DefaultIdempotencyTTL = time.Hour
+
+// idempotencyKeyMetadata is the memory metadata key recording the
+// idempotency_key the memory was stored under.
+const idempotencyKeyMetadata = "idempotency_key"
+
+// idempotencyCache remembers store_memory results by idempotency key so a
+// client retrying after a timeout gets the original result back instead of
+// creating a second memory. Entries live in process memory for ttl; a call
+// that arrives while the first one is still storing waits for its result.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*idempotencyEntry
+}
+
+// idempotencyEntry is a cached result, or an in-flight store while result is
+// nil and done is open.
+type idempotencyEntry struct {
+	result  *StoreMemoryResult
+	expires time.Time
+	done    chan struct{}
+}
+
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	return &idempotencyCache{ttl: ttl, entries: make(map[string]*idempotencyEntry)}
+}
+
+// acquire returns the cached result for key, waiting for an in-flight store
+// with the same key to finish. A nil result means the caller now owns key
+// and must call release once its store completes.
+func (c *idempotencyCache) acquire(ctx context.Context, key string) (*StoreMemoryResult, error) {
+	for {
+		c.mu.Lock()
+		c.pruneLocked(time.Now())
+		e, ok := c.entries[key]
+		if !ok {
+			c.entries[key] = &idempotencyEntry{done: make(chan struct{})}
+			c.mu.Unlock()
+			return nil, nil
+		}
+		if e.result != nil {
+			c.mu.Unlock()
+			return e.result, nil
+		}
+		done := e.done
+		c.mu.Unlock()
+
+		select {
+		case <-done:
+			// Loop: the owner either cached a result or failed and freed the key.
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// release records result for key and wakes any waiting callers. A nil
+// result (the store failed) frees the key so the next retry stores afresh.
+func (c *idempotencyCache) release(key string, result *StoreMemoryResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	if result == nil {
+		delete(c.entries, key)
+	} else {
+		e.result = result
+		e.expires = time.Now().Add(c.ttl)
+	}
+	close(e.done)
+}
+
+// pruneLocked drops completed entries whose TTL has passed. c.mu must be held.
+func (c *idempotencyCache) pruneLocked(now time.Time) {
+	for key, e := range c.entries {
+		if e.result != nil && now.After(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
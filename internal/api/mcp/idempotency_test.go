@@ -0,0 +1,100 @@
+package mcp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+)
+
+func TestStoreMemory_IdempotencyKeyReturnsFirstResult(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "deploy the billing service", IdempotencyKey: "req-1"})
+	require.NoError(t, err)
+	assert.False(t, first.Replayed)
+
+	// A retry whose content differs only in whitespace would otherwise hash
+	// to a new ID and create a second memory.
+	retry, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "deploy the  billing service\n", IdempotencyKey: "req-1"})
+	require.NoError(t, err)
+	assert.True(t, retry.Replayed)
+	assert.Equal(t, first.ID, retry.ID)
+	assert.Equal(t, first.Message, retry.Message)
+	assert.Len(t, store.memories, 1)
+	assert.Equal(t, "req-1", store.memories[first.ID].Metadata["idempotency_key"])
+
+	// A different key stores normally.
+	other, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "deploy the  billing service\n", IdempotencyKey: "req-2"})
+	require.NoError(t, err)
+	assert.False(t, other.Replayed)
+	assert.NotEqual(t, first.ID, other.ID)
+}
+
+func TestStoreMemory_IdempotencyKeyExpires(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithIdempotencyTTL(20*time.Millisecond))
+	ctx := context.Background()
+
+	first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "first attempt", IdempotencyKey: "req-1"})
+	require.NoError(t, err)
+
+	time.Sleep(40 * time.Millisecond)
+
+	second, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "second attempt", IdempotencyKey: "req-1"})
+	require.NoError(t, err)
+	assert.False(t, second.Replayed)
+	assert.NotEqual(t, first.ID, second.ID)
+}
+
+func TestStoreMemory_IdempotencyKeyFailedStoreIsNotCached(t *testing.T) {
+	store := newMockStore()
+	store.storeErr = errors.New("disk full")
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	_, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "x", IdempotencyKey: "req-1"})
+	require.Error(t, err)
+
+	store.storeErr = nil
+
+	result, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "x", IdempotencyKey: "req-1"})
+	require.NoError(t, err)
+	assert.False(t, result.Replayed)
+}
+
+func TestStoreMemory_IdempotencyKeyConcurrentRetries(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	const n = 8
+	ids := make([]string, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{
+				Content:        "attempt " + string(rune('a'+i)),
+				IdempotencyKey: "req-1",
+			})
+			if assert.NoError(t, err) {
+				ids[i] = result.ID
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, id := range ids {
+		assert.Equal(t, ids[0], id)
+	}
+}
@@ -0,0 +1,132 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// limitRecordingStore returns a mockStore that records the Limit of every
+// List call it receives.
+func limitRecordingStore() (*mockStore, *[]int) {
+	store := newMockStore()
+	var limits []int
+	store.filterFn = func(_ *types.Memory, opts storage.ListOptions) bool {
+		limits = append(limits, opts.Limit)
+		return true
+	}
+	// filterFn only runs per memory, so make sure there is one.
+	store.memories["mem:general:seed"] = &types.Memory{ID: "mem:general:seed", Content: "seed"}
+	return store, &limits
+}
+
+func TestResultLimits_ClampedAndDefaulted(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func(srv *mcp.Server, limit int) (int, error)
+	}{
+		{"recall_memory list", func(srv *mcp.Server, limit int) (int, error) {
+			r, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{Limit: limit})
+			if err != nil {
+				return 0, err
+			}
+			return r.Limit, nil
+		}},
+		{"recall_memory query", func(srv *mcp.Server, limit int) (int, error) {
+			r, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{Query: "seed", Limit: limit})
+			if err != nil {
+				return 0, err
+			}
+			return r.Limit, nil
+		}},
+		{"find_related", func(srv *mcp.Server, limit int) (int, error) {
+			r, err := srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "seed", Limit: limit})
+			if err != nil {
+				return 0, err
+			}
+			return r.Limit, nil
+		}},
+		{"list_deleted_memories", func(srv *mcp.Server, limit int) (int, error) {
+			r, err := srv.ListDeletedMemories(ctx, mcp.ListDeletedMemoriesArgs{Limit: limit})
+			if err != nil {
+				return 0, err
+			}
+			return r.Limit, nil
+		}},
+		{"list_projects", func(srv *mcp.Server, limit int) (int, error) {
+			r, err := srv.ListProjects(ctx, mcp.ListProjectsArgs{Limit: limit})
+			if err != nil {
+				return 0, err
+			}
+			return r.Limit, nil
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, limits := limitRecordingStore()
+			srv := mcp.NewServer(store, mcp.WithResultLimits(15, 40))
+
+			got, err := tt.call(srv, 100000)
+			require.NoError(t, err)
+			assert.Equal(t, 40, got, "limit should be clamped to the cap")
+
+			got, err = tt.call(srv, 0)
+			require.NoError(t, err)
+			assert.Equal(t, 15, got, "omitted limit should use the default")
+
+			got, err = tt.call(srv, 25)
+			require.NoError(t, err)
+			assert.Equal(t, 25, got)
+
+			// The effective limit is what reaches the store.
+			assert.Equal(t, []int{40, 15, 25}, *limits)
+		})
+	}
+}
+
+func TestResultLimits_TraverseMemoryGraph(t *testing.T) {
+	srv := mcp.NewServer(newMockStore(), mcp.WithResultLimits(15, 40))
+
+	limitOf := func(params string) int {
+		resp, err := srv.HandleRequest(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"traverse_memory_graph","params":`+params+`}`))
+		require.NoError(t, err)
+		var decoded struct {
+			Result struct {
+				Limit int `json:"limit"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(resp, &decoded))
+		return decoded.Result.Limit
+	}
+
+	assert.Equal(t, 40, limitOf(`{"memory_id":"mem:general:a","limit":100000}`))
+	assert.Equal(t, 15, limitOf(`{"memory_id":"mem:general:a"}`))
+}
+
+func TestResultLimits_Defaults(t *testing.T) {
+	store, _ := limitRecordingStore()
+	srv := mcp.NewServer(store)
+
+	r, err := srv.RecallMemory(context.Background(), mcp.RecallMemoryArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, storage.DefaultLimit, r.Limit)
+
+	r, err = srv.RecallMemory(context.Background(), mcp.RecallMemoryArgs{Limit: 100000})
+	require.NoError(t, err)
+	assert.Equal(t, storage.MaxLimit, r.Limit)
+
+	// A configured cap above what the storage layer accepts is lowered to it.
+	srv = mcp.NewServer(store, mcp.WithResultLimits(0, 5000))
+	r, err = srv.RecallMemory(context.Background(), mcp.RecallMemoryArgs{Limit: 5000})
+	require.NoError(t, err)
+	assert.Equal(t, storage.MaxLimit, r.Limit)
+}
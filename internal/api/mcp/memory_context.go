@@ -0,0 +1,255 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// get_memory_context budget defaults and bounds.
+const (
+	DefaultContextMaxMemories = 8
+	MaxContextMaxMemories     = 20
+	DefaultContextMaxChars    = 4000
+	MinContextMaxChars        = 200
+	MaxContextMaxChars        = 50000
+
+	// minContextContentChars is the least content a memory other than the
+	// requested one must be able to keep for it to stay in the bundle.
+	minContextContentChars = 40
+
+	// contextSharedEntities caps the shared entities named in a related
+	// memory's heading.
+	contextSharedEntities = 3
+)
+
+// GetMemoryContext assembles a memory and the memories around it into a
+// bundle sized for an LLM prompt: the memory itself, the latest version of
+// its evolution chain, the memories directly linked to it, and the
+// memories that share entities with it (a one-hop traversal), in that
+// order of priority. The bundle holds at most MaxMemories memories, and its
+// rendered text at most MaxChars characters: lower-priority memories are
+// dropped when even a short excerpt would not fit, and the remaining
+// content is trimmed with the shortest contents kept whole.
+func (s *Server) GetMemoryContext(ctx context.Context, args GetMemoryContextArgs) (*GetMemoryContextResult, error) {
+	if args.ID == "" {
+		return nil, invalidParamsf("id is required")
+	}
+	maxMemories := args.MaxMemories
+	switch {
+	case maxMemories <= 0:
+		maxMemories = DefaultContextMaxMemories
+	case maxMemories > MaxContextMaxMemories:
+		maxMemories = MaxContextMaxMemories
+	}
+	maxChars := args.MaxChars
+	switch {
+	case maxChars <= 0:
+		maxChars = DefaultContextMaxChars
+	case maxChars < MinContextMaxChars:
+		maxChars = MinContextMaxChars
+	case maxChars > MaxContextMaxChars:
+		maxChars = MaxContextMaxChars
+	}
+
+	store := s.resolveStoreForID(ctx, args.ID)
+	memory, err := store.Get(ctx, args.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("memory not found: %s", args.ID)
+		}
+		return nil, fmt.Errorf("failed to get memory: %w", err)
+	}
+
+	result := &GetMemoryContextResult{ID: args.ID}
+	seen := map[string]bool{memory.ID: true}
+	candidates := []contextCandidate{{memory: memory, entry: MemoryContextEntry{Role: ContextRoleMemory}}}
+	add := func(m *types.Memory, entry MemoryContextEntry) {
+		if m == nil || seen[m.ID] || m.DeletedAt != nil {
+			return
+		}
+		seen[m.ID] = true
+		candidates = append(candidates, contextCandidate{memory: m, entry: entry})
+	}
+	fetch := func(id string) (*types.Memory, error) {
+		m, err := store.Get(ctx, id)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return m, err
+	}
+
+	chain, err := s.GetEvolutionChain(ctx, GetEvolutionChainArgs{ID: args.ID})
+	if err != nil {
+		return nil, err
+	}
+	if chain.CurrentID != "" && chain.CurrentID != args.ID {
+		result.CurrentID = chain.CurrentID
+		latest, err := fetch(chain.CurrentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get latest version %s: %w", chain.CurrentID, err)
+		}
+		add(latest, MemoryContextEntry{Role: ContextRoleLatestVersion})
+	}
+
+	neighbors, err := s.GetMemoryNeighbors(ctx, GetMemoryNeighborsArgs{ID: args.ID})
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range append(neighbors.Outgoing, neighbors.Incoming...) {
+		if n.Missing || seen[n.ID] {
+			continue
+		}
+		linked, err := fetch(n.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get neighbor %s: %w", n.ID, err)
+		}
+		add(linked, MemoryContextEntry{Role: ContextRoleLinked, LinkType: n.LinkType})
+	}
+
+	related, err := store.Traverse(ctx, args.ID, storage.TraversalOptions{
+		MaxHops:    1,
+		Limit:      maxMemories,
+		Weights:    storage.DefaultTraversalWeights,
+		MaxBreadth: s.traversalBreadth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("graph traversal failed: %w", err)
+	}
+	for _, r := range related {
+		add(r.Memory, MemoryContextEntry{Role: ContextRoleRelated, SharedEntities: r.SharedEntities})
+	}
+
+	if len(candidates) > maxMemories {
+		candidates = candidates[:maxMemories]
+	}
+	entries := fitContextBudget(candidates, maxChars)
+	result.Omitted = len(seen) - len(entries)
+	result.Memories = entries
+	result.Text = renderMemoryContext(entries)
+	return result, nil
+}
+
+// contextCandidate is a memory considered for a get_memory_context bundle.
+type contextCandidate struct {
+	memory *types.Memory
+	entry  MemoryContextEntry // role and link details; the rest is filled in by fitContextBudget
+}
+
+// fitContextBudget turns candidates, in priority order, into bundle entries
+// whose rendered text is at most maxChars characters. Trailing candidates
+// are dropped while the headings and a minimal excerpt of every content do
+// not fit; the first candidate is always kept. The space left for content
+// is then shared out so that every memory may use an equal share, and the
+// share a short memory does not need goes to the longer ones.
+func fitContextBudget(candidates []contextCandidate, maxChars int) []MemoryContextEntry {
+	entries := make([]MemoryContextEntry, len(candidates))
+	lengths := make([]int, len(candidates))
+	for i, c := range candidates {
+		e := c.entry
+		e.ID = c.memory.ID
+		e.State = c.memory.State
+		e.CreatedAt = c.memory.CreatedAt.Format(time.RFC3339)
+		e.Content = c.memory.Content
+		entries[i] = e
+		lengths[i] = utf8.RuneCountInString(e.Content)
+	}
+
+	// Headings, their newlines and the blank lines between entries.
+	overhead := func(n int) int {
+		total := 2 * (n - 1)
+		for _, e := range entries[:n] {
+			total += utf8.RuneCountInString(contextHeading(e)) + 1
+		}
+		return total
+	}
+	minContent := func(n int) int {
+		total := 0
+		for _, l := range lengths[1:n] {
+			total += min(l, minContextContentChars)
+		}
+		return total
+	}
+	n := len(entries)
+	for n > 1 && overhead(n)+minContent(n) > maxChars {
+		n--
+	}
+	entries, lengths = entries[:n], lengths[:n]
+
+	budget := max(maxChars-overhead(n), 0)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return lengths[order[a]] < lengths[order[b]] })
+	for k, i := range order {
+		share := min(lengths[i], budget/(n-k))
+		budget -= share
+		if share < lengths[i] {
+			entries[i].Content = trimContextContent(entries[i].Content, share)
+			entries[i].Truncated = true
+		}
+	}
+	return entries
+}
+
+// trimContextContent cuts content to n characters, the last of which is an
+// ellipsis marking the cut.
+func trimContextContent(content string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimRight(types.TruncateRunes(content, n-1), " \n") + "…"
+}
+
+// contextHeading is the line introducing an entry in the rendered bundle.
+func contextHeading(e MemoryContextEntry) string {
+	var b strings.Builder
+	switch e.Role {
+	case ContextRoleMemory:
+		b.WriteString("## Memory ")
+	case ContextRoleLatestVersion:
+		b.WriteString("## Latest version ")
+	case ContextRoleLinked:
+		b.WriteString("## Linked memory ")
+	default:
+		b.WriteString("## Related memory ")
+	}
+	b.WriteString(e.ID)
+
+	var details []string
+	if e.LinkType != "" {
+		details = append(details, e.LinkType)
+	}
+	if len(e.SharedEntities) > 0 {
+		shared := e.SharedEntities
+		if len(shared) > contextSharedEntities {
+			shared = shared[:contextSharedEntities]
+		}
+		details = append(details, "shares "+strings.Join(shared, ", "))
+	}
+	if e.State != "" {
+		details = append(details, e.State)
+	}
+	details = append(details, e.CreatedAt[:min(len(e.CreatedAt), len("2006-01-02"))])
+	b.WriteString(" (" + strings.Join(details, "; ") + ")")
+	return b.String()
+}
+
+// renderMemoryContext renders bundle entries as the text block returned by
+// get_memory_context: a heading line followed by the content of each entry,
+// with a blank line between entries.
+func renderMemoryContext(entries []MemoryContextEntry) string {
+	blocks := make([]string, len(entries))
+	for i, e := range entries {
+		blocks[i] = contextHeading(e) + "\n" + e.Content
+	}
+	return strings.Join(blocks, "\n\n")
+}
@@ -0,0 +1,117 @@
+package mcp_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMemoryContextGraph stores a target memory with a parent that CONTAINS
+// it, a memory sharing the "acme" entity with it, and an evolved successor.
+// Each content is repeated to the given number of runes.
+func newMemoryContextGraph(t *testing.T, contentLength int) (srv *mcp.Server, ids map[string]string) {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv = mcp.NewServer(store)
+	ctx := context.Background()
+
+	pad := func(s string) string {
+		for utf8.RuneCountInString(s) < contentLength {
+			s += " " + s
+		}
+		return s
+	}
+	ids = map[string]string{}
+	for _, name := range []string{"target", "parent", "related"} {
+		res, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: pad(name + " memory about the Acme rollout")})
+		require.NoError(t, err)
+		ids[name] = res.ID
+	}
+	require.NoError(t, store.CreateMemoryLink(ctx, "link:contains", ids["parent"], ids["target"], "CONTAINS"))
+
+	db := store.GetDB()
+	now := time.Now()
+	_, err = db.ExecContext(ctx, `INSERT INTO entities (id, name, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		"ent:acme", "acme", types.EntityTypeOrganization, now, now)
+	require.NoError(t, err)
+	for _, id := range []string{ids["target"], ids["related"]} {
+		_, err = db.ExecContext(ctx, `INSERT INTO memory_entities (memory_id, entity_id, frequency, confidence) VALUES (?, ?, 1, 0.9)`, id, "ent:acme")
+		require.NoError(t, err)
+	}
+
+	evolved, err := srv.EvolveMemory(ctx, mcp.EvolveMemoryArgs{ID: ids["target"], NewContent: pad("latest memory about the Acme rollout")})
+	require.NoError(t, err)
+	ids["latest"] = evolved.NewID
+	return srv, ids
+}
+
+func TestGetMemoryContext_Bundle(t *testing.T) {
+	srv, ids := newMemoryContextGraph(t, 0)
+
+	var result mcp.GetMemoryContextResult
+	callRPC(t, srv, "get_memory_context", map[string]string{"id": ids["target"]}, &result)
+
+	assert.Equal(t, ids["latest"], result.CurrentID)
+	assert.Zero(t, result.Omitted)
+	roles := map[string]string{}
+	for _, e := range result.Memories {
+		roles[e.ID] = e.Role
+		assert.False(t, e.Truncated, e.ID)
+	}
+	assert.Equal(t, map[string]string{
+		ids["target"]:  mcp.ContextRoleMemory,
+		ids["latest"]:  mcp.ContextRoleLatestVersion,
+		ids["parent"]:  mcp.ContextRoleLinked,
+		ids["related"]: mcp.ContextRoleRelated,
+	}, roles)
+	assert.Equal(t, ids["target"], result.Memories[0].ID)
+
+	assert.Contains(t, result.Text, "## Memory "+ids["target"])
+	assert.Contains(t, result.Text, "target memory about the Acme rollout")
+	assert.Contains(t, result.Text, "## Linked memory "+ids["parent"]+" (CONTAINS;")
+	assert.Contains(t, result.Text, "## Related memory "+ids["related"]+" (shares acme;")
+}
+
+func TestGetMemoryContext_RespectsCharBudget(t *testing.T) {
+	srv, ids := newMemoryContextGraph(t, 2000)
+	ctx := context.Background()
+
+	for _, maxChars := range []int{200, 600, 1500, 5000} {
+		result, err := srv.GetMemoryContext(ctx, mcp.GetMemoryContextArgs{ID: ids["target"], MaxChars: maxChars})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, utf8.RuneCountInString(result.Text), maxChars, "max_chars %d", maxChars)
+
+		require.NotEmpty(t, result.Memories)
+		primary := result.Memories[0]
+		assert.Equal(t, ids["target"], primary.ID)
+		assert.True(t, primary.Truncated)
+		assert.True(t, strings.HasSuffix(primary.Content, "…"))
+		assert.Equal(t, 4, len(result.Memories)+result.Omitted)
+	}
+
+	// A tight budget drops lower-priority memories rather than leaving
+	// them with a few characters each.
+	tight, err := srv.GetMemoryContext(ctx, mcp.GetMemoryContextArgs{ID: ids["target"], MaxChars: 200})
+	require.NoError(t, err)
+	assert.Positive(t, tight.Omitted)
+
+	// max_memories keeps the highest-priority memories; with room to spare
+	// nothing is trimmed.
+	limited, err := srv.GetMemoryContext(ctx, mcp.GetMemoryContextArgs{ID: ids["target"], MaxMemories: 2, MaxChars: 50000})
+	require.NoError(t, err)
+	require.Len(t, limited.Memories, 2)
+	assert.Equal(t, 2, limited.Omitted)
+	for _, e := range limited.Memories {
+		assert.False(t, e.Truncated, e.ID)
+	}
+}
@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// WithMemoryTemplates adds templates to those create_typed_memory offers,
+// after the built-in decision, meeting and person templates. A template
+// named like a built-in one replaces it.
+func WithMemoryTemplates(templates []types.MemoryTemplate) ServerOption {
+	return func(s *Server) {
+		s.memoryTemplates = types.MergeMemoryTemplates(s.memoryTemplates, templates)
+	}
+}
+
+// CreateTypedMemory renders args.Fields with the named template and stores
+// the result like store_memory, with the template's memory_type and the
+// template name and fields recorded in metadata, so the structured fields
+// can be read back from the memory.
+func (s *Server) CreateTypedMemory(ctx context.Context, args CreateTypedMemoryArgs) (*StoreMemoryResult, error) {
+	if args.Template == "" {
+		return nil, invalidParamsf("template is required")
+	}
+	i := slices.IndexFunc(s.memoryTemplates, func(t types.MemoryTemplate) bool { return t.Name == args.Template })
+	if i < 0 {
+		return nil, invalidParamsf("unknown template %q; available: %s", args.Template, strings.Join(s.memoryTemplateNames(), ", "))
+	}
+	template := s.memoryTemplates[i]
+
+	content, fields, err := template.Render(args.Fields)
+	if err != nil {
+		return nil, invalidParamsf("%v", err)
+	}
+	return s.StoreMemory(ctx, StoreMemoryArgs{
+		Content:      content,
+		Source:       args.Source,
+		ConnectionID: args.ConnectionID,
+		Tags:         args.Tags,
+		Metadata: map[string]interface{}{
+			types.MetadataTemplate:       template.Name,
+			types.MetadataTemplateFields: fields,
+		},
+		CreatedBy:      args.CreatedBy,
+		AuthorType:     args.AuthorType,
+		IdempotencyKey: args.IdempotencyKey,
+		memoryType:     template.Type(),
+	})
+}
+
+// ListMemoryTemplates returns the templates create_typed_memory accepts.
+func (s *Server) ListMemoryTemplates(_ context.Context, _ ListMemoryTemplatesArgs) (*ListMemoryTemplatesResult, error) {
+	return &ListMemoryTemplatesResult{
+		Templates: s.memoryTemplates,
+		Total:     len(s.memoryTemplates),
+	}, nil
+}
+
+// memoryTemplateNames returns the names of the available templates.
+func (s *Server) memoryTemplateNames() []string {
+	names := make([]string, len(s.memoryTemplates))
+	for i, t := range s.memoryTemplates {
+		names[i] = t.Name
+	}
+	return names
+}
@@ -0,0 +1,98 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTypedMemory_Decision(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	var stored mcp.StoreMemoryResult
+	callRPC(t, srv, "create_typed_memory", map[string]interface{}{
+		"template": "decision",
+		"fields": map[string]interface{}{
+			"context":      "Nightly builds take two hours",
+			"choice":       "Cache dependencies in CI",
+			"rationale":    "Most of the time is spent downloading modules",
+			"alternatives": []string{"Bigger runners", "Fewer nightly jobs"},
+		},
+		"tags": []string{"ci"},
+	}, &stored)
+	require.NotEmpty(t, stored.ID)
+
+	mem, err := store.Get(ctx, stored.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.MemoryTypeDecision, mem.MemoryType)
+	assert.Contains(t, mem.Content, "Choice: Cache dependencies in CI")
+	assert.Contains(t, mem.Content, "- Fewer nightly jobs")
+	assert.Equal(t, []string{"ci"}, mem.Tags)
+	assert.Equal(t, "decision", mem.Metadata[types.MetadataTemplate])
+	fields, ok := mem.Metadata[types.MetadataTemplateFields].(map[string]interface{})
+	require.True(t, ok, "fields recorded in metadata: %v", mem.Metadata)
+	assert.Equal(t, "Nightly builds take two hours", fields["context"])
+	assert.Equal(t, []interface{}{"Bigger runners", "Fewer nightly jobs"}, fields["alternatives"])
+
+	_, err = srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Nightly builds were slow again"})
+	require.NoError(t, err)
+
+	list, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{MemoryType: types.MemoryTypeDecision})
+	require.NoError(t, err)
+	require.Len(t, list.Memories, 1)
+	assert.Equal(t, stored.ID, list.Memories[0].ID)
+
+	search, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{Query: "nightly", MemoryType: types.MemoryTypeDecision})
+	require.NoError(t, err)
+	require.Len(t, search.Memories, 1)
+	assert.Equal(t, stored.ID, search.Memories[0].ID)
+}
+
+func TestCreateTypedMemory_InvalidArgs(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t)
+
+	for _, params := range []string{
+		`{"fields":{"name":"Ada"}}`,
+		`{"template":"recipe","fields":{"name":"Soup"}}`,
+		`{"template":"person","fields":{"role":"Engineer"}}`,
+		`{"template":"person","fields":{"name":"Ada","age":36}}`,
+		`{"template":"person","fields":{"name":["Ada",1]}}`,
+	} {
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv,
+			`{"jsonrpc":"2.0","method":"create_typed_memory","params":`+params+`,"id":1}`), params)
+	}
+}
+
+func TestCreateTypedMemory_CustomTemplates(t *testing.T) {
+	srv, store := newSQLiteTestServer(t, mcp.WithMemoryTemplates([]types.MemoryTemplate{
+		{Name: "incident", MemoryType: types.MemoryTypeEvent, Fields: []types.TemplateField{
+			{Name: "summary", Required: true},
+			{Name: "impact"},
+		}},
+	}))
+	ctx := context.Background()
+
+	var templates mcp.ListMemoryTemplatesResult
+	callRPC(t, srv, "list_memory_templates", map[string]interface{}{}, &templates)
+	var names []string
+	for _, tmpl := range templates.Templates {
+		names = append(names, tmpl.Name)
+	}
+	assert.Equal(t, []string{"decision", "meeting", "person", "incident"}, names)
+	assert.Equal(t, 4, templates.Total)
+
+	result, err := srv.CreateTypedMemory(ctx, mcp.CreateTypedMemoryArgs{
+		Template: "incident",
+		Fields:   map[string]interface{}{"summary": "Login outage", "impact": "All users for 20 minutes"},
+	})
+	require.NoError(t, err)
+	mem, err := store.Get(ctx, result.ID)
+	require.NoError(t, err)
+	assert.Equal(t, types.MemoryTypeEvent, mem.MemoryType)
+	assert.Equal(t, "Incident\nSummary: Login outage\nImpact: All users for 20 minutes", mem.Content)
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// neighborSummaryLength is how many characters of a neighbor's content
+// get_memory_neighbors returns when the neighbor has no summary.
+const neighborSummaryLength = 200
+
+// GetMemoryNeighbors lists the memories directly linked to a memory, in both
+// directions, with their link types. Unlike traverse_memory_graph it does not
+// follow entities: it reads the memory's own links (including SUPERSEDES
+// links derived from supersedes_id) and looks up each neighbor once.
+// Soft-deleted neighbors are left out until restored; Missing marks a link
+// whose other end no longer exists at all.
+func (s *Server) GetMemoryNeighbors(ctx context.Context, args GetMemoryNeighborsArgs) (*GetMemoryNeighborsResult, error) {
+	if args.ID == "" {
+		return nil, invalidParamsf("id is required")
+	}
+
+	store := s.resolveStoreForID(ctx, args.ID)
+	lister, ok := store.(interface {
+		ListMemoryLinks(ctx context.Context, memoryID string) ([]storage.MemoryLink, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("store does not support memory links")
+	}
+
+	if _, err := store.Get(ctx, args.ID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("memory not found: %s", args.ID)
+		}
+		return nil, fmt.Errorf("failed to get memory: %w", err)
+	}
+
+	links, err := lister.ListMemoryLinks(ctx, args.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memory links: %w", err)
+	}
+
+	result := &GetMemoryNeighborsResult{
+		ID:       args.ID,
+		Outgoing: []MemoryNeighbor{},
+		Incoming: []MemoryNeighbor{},
+	}
+	neighbors := make(map[string]*types.Memory)
+	for _, link := range links {
+		outgoing := link.SourceID == args.ID
+		otherID := link.SourceID
+		if outgoing {
+			otherID = link.TargetID
+		}
+
+		other, seen := neighbors[otherID]
+		if !seen {
+			other, err = store.Get(ctx, otherID)
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				return nil, fmt.Errorf("failed to get neighbor %s: %w", otherID, err)
+			}
+			neighbors[otherID] = other
+		}
+
+		neighbor := MemoryNeighbor{ID: otherID, LinkType: link.Type, Missing: other == nil}
+		if other != nil {
+			neighbor.State = other.State
+			neighbor.Summary = other.Summary
+			if neighbor.Summary == "" {
+				neighbor.Summary = types.TruncateRunes(other.Content, neighborSummaryLength)
+			}
+		}
+
+		if outgoing {
+			result.Outgoing = append(result.Outgoing, neighbor)
+		} else {
+			result.Incoming = append(result.Incoming, neighbor)
+		}
+		if link.Type == "SUPERSEDES" {
+			if outgoing {
+				result.Supersedes = append(result.Supersedes, otherID)
+			} else {
+				result.SupersededBy = append(result.SupersededBy, otherID)
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetMemoryNeighbors builds a small graph around a memory
+//
+//	parent ─CONTAINS─→ target ─DEPENDS_ON─→ dependency
+//	target ←SUPERSEDES─ successor  (via evolve_memory)
+//
+// and checks both directions, link types and supersession are reported.
+func TestGetMemoryNeighbors(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	ids := map[string]string{}
+	for name, content := range map[string]string{
+		"parent":     "Q3 platform roadmap",
+		"target":     "Migrate billing to Postgres",
+		"dependency": "Provision the Postgres cluster",
+	} {
+		res, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: content})
+		require.NoError(t, err)
+		ids[name] = res.ID
+	}
+	require.NoError(t, store.CreateMemoryLink(ctx, "link:contains", ids["parent"], ids["target"], "CONTAINS"))
+	require.NoError(t, store.CreateMemoryLink(ctx, "link:depends", ids["target"], ids["dependency"], "DEPENDS_ON"))
+
+	evolved, err := srv.EvolveMemory(ctx, mcp.EvolveMemoryArgs{ID: ids["target"], NewContent: "Migrate billing and invoicing to Postgres"})
+	require.NoError(t, err)
+
+	var result mcp.GetMemoryNeighborsResult
+	callRPC(t, srv, "get_memory_neighbors", map[string]string{"id": ids["target"]}, &result)
+
+	require.Len(t, result.Outgoing, 1)
+	assert.Equal(t, ids["dependency"], result.Outgoing[0].ID)
+	assert.Equal(t, "DEPENDS_ON", result.Outgoing[0].LinkType)
+	assert.Equal(t, "Provision the Postgres cluster", result.Outgoing[0].Summary)
+
+	incoming := map[string]string{}
+	for _, n := range result.Incoming {
+		incoming[n.LinkType] = n.ID
+	}
+	assert.Equal(t, map[string]string{"CONTAINS": ids["parent"], "SUPERSEDES": evolved.NewID}, incoming)
+	assert.Equal(t, []string{evolved.NewID}, result.SupersededBy)
+	assert.Empty(t, result.Supersedes)
+
+	// From the successor's side the supersession is an outgoing link.
+	successor, err := srv.GetMemoryNeighbors(ctx, mcp.GetMemoryNeighborsArgs{ID: evolved.NewID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{ids["target"]}, successor.Supersedes)
+	require.Len(t, successor.Outgoing, 1)
+	assert.Equal(t, "SUPERSEDES", successor.Outgoing[0].LinkType)
+	assert.Empty(t, successor.Incoming)
+}
+
+// TestGetMemoryNeighbors_SupersedesSeveral verifies that a memory replacing
+// two others, e.g. after a merge, lists both in Supersedes.
+func TestGetMemoryNeighbors_SupersedesSeveral(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Standup is at 9:30"})
+	require.NoError(t, err)
+	second, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Standup moved to the small room"})
+	require.NoError(t, err)
+	merged, err := srv.EvolveMemory(ctx, mcp.EvolveMemoryArgs{ID: first.ID, NewContent: "Standup is at 9:30 in the small room"})
+	require.NoError(t, err)
+	require.NoError(t, store.CreateMemoryLink(ctx, "link:supersedes", merged.NewID, second.ID, "SUPERSEDES"))
+
+	result, err := srv.GetMemoryNeighbors(ctx, mcp.GetMemoryNeighborsArgs{ID: merged.NewID})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{first.ID, second.ID}, result.Supersedes)
+	assert.Len(t, result.Outgoing, 2)
+}
+
+// TestGetMemoryNeighbors_HidesDeleted verifies that a soft-deleted neighbor
+// is left out rather than reported missing, and reappears when restored.
+func TestGetMemoryNeighbors_HidesDeleted(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	task, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Ship the mobile release"})
+	require.NoError(t, err)
+	blocker, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "App store review"})
+	require.NoError(t, err)
+	require.NoError(t, store.CreateMemoryLink(ctx, "link:blocked", task.ID, blocker.ID, "DEPENDS_ON"))
+
+	_, err = srv.ForgetMemory(ctx, mcp.ForgetMemoryArgs{ID: blocker.ID})
+	require.NoError(t, err)
+	result, err := srv.GetMemoryNeighbors(ctx, mcp.GetMemoryNeighborsArgs{ID: task.ID})
+	require.NoError(t, err)
+	assert.Empty(t, result.Outgoing)
+
+	_, err = srv.RestoreMemory(ctx, mcp.RestoreMemoryArgs{ID: blocker.ID})
+	require.NoError(t, err)
+	result, err = srv.GetMemoryNeighbors(ctx, mcp.GetMemoryNeighborsArgs{ID: task.ID})
+	require.NoError(t, err)
+	require.Len(t, result.Outgoing, 1)
+	assert.Equal(t, blocker.ID, result.Outgoing[0].ID)
+	assert.False(t, result.Outgoing[0].Missing)
+}
+
+func TestGetMemoryNeighbors_Errors(t *testing.T) {
+	srv, _ := newSQLiteTestServer(t)
+
+	req := `{"jsonrpc":"2.0","method":"get_memory_neighbors","params":{},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req))
+
+	req = `{"jsonrpc":"2.0","method":"get_memory_neighbors","params":{"id":"mem:general:missing"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeNotFound, rpcErrorCode(t, srv, req))
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// EnrichmentNotificationMethod is the JSON-RPC method of the notification
+// sent when a memory's enrichment finishes.
+const EnrichmentNotificationMethod = "notifications/memento/enrichment"
+
+// EnrichmentNotificationsCapability is the key a client sets under
+// capabilities.experimental in its initialize request to opt in to
+// enrichment notifications. The server advertises the same key.
+const EnrichmentNotificationsCapability = "enrichmentNotifications"
+
+// notifier delivers server-to-client notifications. A transport installs the
+// send function; the client enables delivery during initialize. Until both
+// have happened notifications are dropped.
+type notifier struct {
+	mu      sync.Mutex
+	send    func([]byte) error
+	enabled bool
+
+	// perClient is set by transports serving several clients, whose send
+	// function delivers only to clients that opted in; the enabled flag,
+	// which the latest initialize overwrites, is then ignored.
+	perClient bool
+}
+
+// setSender installs the function that writes a notification frame.
+func (n *notifier) setSender(send func([]byte) error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.send = send
+}
+
+// setPerClientSender installs a send function that filters recipients by
+// their own initialize capabilities.
+func (n *notifier) setPerClientSender(send func([]byte) error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.send = send
+	n.perClient = true
+}
+
+// setEnabled records whether the client accepts notifications.
+func (n *notifier) setEnabled(enabled bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.enabled = enabled
+}
+
+// notify sends a JSON-RPC notification if delivery is enabled.
+func (n *notifier) notify(method string, params interface{}) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if (!n.enabled && !n.perClient) || n.send == nil {
+		return
+	}
+	frame, err := json.Marshal(JSONRPCNotification{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		slog.Error("failed to marshal notification", "method", method, "error", err)
+		return
+	}
+	if err := n.send(frame); err != nil {
+		slog.Warn("failed to send notification", "method", method, "error", err)
+	}
+}
+
+// NotifyEnrichment tells the client that enrichment of memoryID has finished
+// with the given final status (types.StatusEnriched or types.StatusFailed).
+// It is a no-op unless the client opted in with the enrichmentNotifications
+// capability and the server is attached to a transport. Safe to call from
+// enrichment workers.
+func (s *Server) NotifyEnrichment(memoryID string, status types.MemoryStatus) {
+	s.notifications.notify(EnrichmentNotificationMethod, EnrichmentNotification{
+		MemoryID: memoryID,
+		Status:   status,
+	})
+}
+
+// clientWantsEnrichmentNotifications reports whether the initialize
+// capabilities include experimental.enrichmentNotifications.
+func clientWantsEnrichmentNotifications(capabilities map[string]interface{}) bool {
+	experimental, ok := capabilities["experimental"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	switch v := experimental[EnrichmentNotificationsCapability].(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	default:
+		return true
+	}
+}
@@ -0,0 +1,254 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/connections"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// newTwoConnectionManager returns a manager with SQLite connections "work"
+// (the default) and "personal", each in its own temporary database.
+func newTwoConnectionManager(t *testing.T) *connections.Manager {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := connections.ConnectionsConfig{DefaultConnection: "work"}
+	for _, name := range []string{"work", "personal"} {
+		cfg.Connections = append(cfg.Connections, connections.Connection{
+			Name:     name,
+			Enabled:  true,
+			Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, name+".db")},
+		})
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(dir, "connections.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0o600))
+
+	manager, err := connections.NewManager(configPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close() })
+	return manager
+}
+
+// newOpaqueServer returns a server using opaque IDs over a two-connection
+// manager, plus its route table.
+func newOpaqueServer(t *testing.T, manager *connections.Manager) (*mcp.Server, *connections.RouteTable) {
+	t.Helper()
+	routes, err := connections.OpenRouteTable(filepath.Join(t.TempDir(), "memory_routes.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = routes.Close() })
+
+	workStore, err := manager.GetStore("work")
+	require.NoError(t, err)
+	srv := mcp.NewServer(workStore,
+		mcp.WithConnectionManager(manager),
+		mcp.WithOpaqueIDs(routes),
+	)
+	return srv, routes
+}
+
+// TestOpaqueIDs_HideConnectionAndRoute verifies that opaque IDs do not
+// contain the connection name and still route to the right connection.
+func TestOpaqueIDs_HideConnectionAndRoute(t *testing.T) {
+	manager := newTwoConnectionManager(t)
+	srv, routes := newOpaqueServer(t, manager)
+	ctx := context.Background()
+
+	res, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "dentist on friday", ConnectionID: "personal"})
+	require.NoError(t, err)
+	assert.NotContains(t, res.ID, "personal")
+	assert.True(t, strings.HasPrefix(res.ID, "mem:"))
+	assert.Len(t, strings.Split(res.ID, ":"), 2, "opaque IDs have no connection segment")
+
+	conn, err := routes.Lookup(ctx, res.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "personal", conn)
+
+	recalled, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{ID: res.ID})
+	require.NoError(t, err)
+	require.True(t, recalled.Found)
+	assert.Equal(t, "dentist on friday", recalled.Memory.Content)
+
+	// Storing the same content again is still deduplicated.
+	again, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "dentist on friday", ConnectionID: "personal"})
+	require.NoError(t, err)
+	assert.Equal(t, res.ID, again.ID)
+	assert.True(t, again.Duplicate)
+}
+
+// TestOpaqueIDs_RoutesFollowRouteTable verifies that routing uses the route
+// table rather than anything encoded in the ID.
+func TestOpaqueIDs_RoutesFollowRouteTable(t *testing.T) {
+	manager := newTwoConnectionManager(t)
+	srv, routes := newOpaqueServer(t, manager)
+	ctx := context.Background()
+
+	res, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "quarterly goals", ConnectionID: "personal"})
+	require.NoError(t, err)
+
+	// Point the route at "work"; the memory is not found there.
+	require.NoError(t, routes.Set(ctx, res.ID, "work"))
+	recalled, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{ID: res.ID})
+	require.NoError(t, err)
+	assert.False(t, recalled.Found)
+}
+
+// TestOpaqueIDs_RoutingSurvivesConnectionRename verifies that after a
+// connection is renamed in connections.json, Reconcile moves its routes to
+// the new name so its opaque IDs keep resolving.
+func TestOpaqueIDs_RoutingSurvivesConnectionRename(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "connections.json")
+	// The renamed connection keeps its database, personal.db.
+	writeConfig := func(personal string) {
+		cfg := connections.ConnectionsConfig{DefaultConnection: "work", Connections: []connections.Connection{
+			{Name: "work", Enabled: true, Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "work.db")}},
+			{Name: personal, Enabled: true, Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "personal.db")}},
+		}}
+		data, err := json.Marshal(cfg)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(configPath, data, 0o600))
+	}
+	openServer := func(routes *connections.RouteTable) (*mcp.Server, *connections.Manager) {
+		manager, err := connections.NewManager(configPath)
+		require.NoError(t, err)
+		workStore, err := manager.GetStore("work")
+		require.NoError(t, err)
+		return mcp.NewServer(workStore, mcp.WithConnectionManager(manager), mcp.WithOpaqueIDs(routes)), manager
+	}
+	routes, err := connections.OpenRouteTable(filepath.Join(dir, "memory_routes.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = routes.Close() })
+	ctx := context.Background()
+
+	writeConfig("personal")
+	srv, manager := openServer(routes)
+	res, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "dentist on friday", ConnectionID: "personal"})
+	require.NoError(t, err)
+	require.NoError(t, manager.Close())
+
+	writeConfig("home")
+	srv, manager = openServer(routes)
+	t.Cleanup(func() { _ = manager.Close() })
+	n, err := routes.Reconcile(ctx, manager)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	connection, err := routes.Lookup(ctx, res.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "home", connection)
+	recalled, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{ID: res.ID})
+	require.NoError(t, err)
+	require.True(t, recalled.Found)
+	assert.Equal(t, "dentist on friday", recalled.Memory.Content)
+
+	// Nothing left to follow on the next start.
+	n, err = routes.Reconcile(ctx, manager)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+// TestOpaqueIDs_BackfilledLegacyIDsRoute verifies that memories created under
+// the deterministic scheme keep routing after the backfill.
+func TestOpaqueIDs_BackfilledLegacyIDsRoute(t *testing.T) {
+	manager := newTwoConnectionManager(t)
+	ctx := context.Background()
+
+	personal, err := manager.GetStore("personal")
+	require.NoError(t, err)
+	// A legacy evolve_memory ID has no connection segment to parse.
+	require.NoError(t, personal.Store(ctx, &types.Memory{ID: "mem:3f1b6c1e-legacy", Content: "old evolved note", Source: "test"}))
+
+	srv, routes := newOpaqueServer(t, manager)
+	n, err := routes.Backfill(ctx, manager)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	recalled, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{ID: "mem:3f1b6c1e-legacy"})
+	require.NoError(t, err)
+	require.True(t, recalled.Found)
+	assert.Equal(t, "old evolved note", recalled.Memory.Content)
+}
+
+// newPrefixedConnectionManager returns a manager with SQLite connections
+// "work" (the default) and "crm", whose memory IDs start with "acme".
+func newPrefixedConnectionManager(t *testing.T) *connections.Manager {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := connections.ConnectionsConfig{
+		DefaultConnection: "work",
+		Connections: []connections.Connection{
+			{Name: "work", Enabled: true, Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "work.db")}},
+			{Name: "crm", Enabled: true, IDPrefix: "acme", Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "crm.db")}},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(dir, "connections.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0o600))
+
+	manager, err := connections.NewManager(configPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close() })
+	return manager
+}
+
+// TestIDPrefix_BothSchemes verifies that a connection's id_prefix replaces
+// "mem" in deterministic and opaque IDs and that the IDs route back to it.
+func TestIDPrefix_BothSchemes(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		scheme   string
+		segments int
+	}{
+		{"deterministic", 3},
+		{"opaque", 2},
+	} {
+		t.Run(tc.scheme, func(t *testing.T) {
+			manager := newPrefixedConnectionManager(t)
+			workStore, err := manager.GetStore("work")
+			require.NoError(t, err)
+			srv := mcp.NewServer(workStore, mcp.WithConnectionManager(manager))
+			if tc.scheme == "opaque" {
+				srv, _ = newOpaqueServer(t, manager)
+			}
+
+			crm, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Renewal call with Initech", ConnectionID: "crm"})
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(crm.ID, "acme:"), crm.ID)
+			assert.Len(t, strings.Split(crm.ID, ":"), tc.segments)
+			work, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Sprint review on Thursday", ConnectionID: "work"})
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(work.ID, "mem:"), work.ID)
+
+			recalled, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{ID: crm.ID})
+			require.NoError(t, err)
+			require.True(t, recalled.Found, "prefixed ID routes to its connection")
+			assert.Equal(t, "Renewal call with Initech", recalled.Memory.Content)
+
+			crmStore, err := manager.GetStore("crm")
+			require.NoError(t, err)
+			_, err = crmStore.Get(ctx, crm.ID)
+			require.NoError(t, err, "stored in the crm connection")
+			_, err = workStore.Get(ctx, crm.ID)
+			assert.Error(t, err, "not stored in the default connection")
+
+			evolved, err := srv.EvolveMemory(ctx, mcp.EvolveMemoryArgs{ID: crm.ID, NewContent: "Renewal call with Initech moved to May"})
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(evolved.NewID, "acme:"), evolved.NewID)
+			got, err := srv.GetMemory(ctx, mcp.GetMemoryArgs{ID: evolved.NewID})
+			require.NoError(t, err)
+			assert.True(t, got.Found, "evolved ID routes to the same connection")
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// memoryLinkOrderer is implemented by stores whose memory links keep an
+// order among the links of a source (see storage.MemoryStore
+// GetMemoriesByRelationType).
+type memoryLinkOrderer interface {
+	SetMemoryLinkOrder(ctx context.Context, sourceID, linkType string, targetIDs []string) error
+}
+
+// projectChildIDs returns the IDs of parentID's CONTAINS children in their
+// current order.
+func projectChildIDs(ctx context.Context, store storage.MemoryStore, parentID string) ([]string, error) {
+	children, err := store.GetMemoriesByRelationType(ctx, parentID, "CONTAINS")
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(children))
+	for _, child := range children {
+		ids = append(ids, child.ID)
+	}
+	return ids, nil
+}
+
+// placeProjectItem moves itemID to the 1-based position among parentID's
+// children, shifting later children down. Positions past the end place the
+// item last.
+func placeProjectItem(ctx context.Context, store storage.MemoryStore, parentID, itemID string, position int) error {
+	orderer, ok := store.(memoryLinkOrderer)
+	if !ok {
+		return fmt.Errorf("store does not support ordering project items")
+	}
+	ids, err := projectChildIDs(ctx, store, parentID)
+	if err != nil {
+		return err
+	}
+
+	ordered := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != itemID {
+			ordered = append(ordered, id)
+		}
+	}
+	index := min(position-1, len(ordered))
+	ordered = append(ordered[:index], append([]string{itemID}, ordered[index:]...)...)
+	return orderer.SetMemoryLinkOrder(ctx, parentID, "CONTAINS", ordered)
+}
+
+// ReorderProjectItems sets the order of a project item's children. The
+// listed children come first, in the given order; children not listed
+// follow in their current order.
+func (s *Server) ReorderProjectItems(ctx context.Context, args ReorderProjectItemsArgs) (*ReorderProjectItemsResult, error) {
+	if args.ParentID == "" {
+		return nil, invalidParamsf("parent_id is required")
+	}
+	if len(args.ItemIDs) == 0 {
+		return nil, invalidParamsf("item_ids is required")
+	}
+
+	store := s.resolveStoreForID(ctx, args.ParentID)
+	orderer, ok := store.(memoryLinkOrderer)
+	if !ok {
+		return nil, fmt.Errorf("store does not support ordering project items")
+	}
+	if _, err := store.Get(ctx, args.ParentID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("parent memory not found: %s", args.ParentID)
+		}
+		return nil, fmt.Errorf("failed to retrieve parent: %w", err)
+	}
+
+	current, err := projectChildIDs(ctx, store, args.ParentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list children: %w", err)
+	}
+	isChild := make(map[string]bool, len(current))
+	for _, id := range current {
+		isChild[id] = true
+	}
+
+	listed := make(map[string]bool, len(args.ItemIDs))
+	ordered := make([]string, 0, len(current))
+	for _, id := range args.ItemIDs {
+		if !isChild[id] {
+			return nil, invalidParamsf("%s is not a child of %s", id, args.ParentID)
+		}
+		if listed[id] {
+			return nil, invalidParamsf("%s is listed more than once", id)
+		}
+		listed[id] = true
+		ordered = append(ordered, id)
+	}
+	for _, id := range current {
+		if !listed[id] {
+			ordered = append(ordered, id)
+		}
+	}
+
+	if err := orderer.SetMemoryLinkOrder(ctx, args.ParentID, "CONTAINS", ordered); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("children of %s changed during the reorder: %v", args.ParentID, err)
+		}
+		return nil, fmt.Errorf("failed to reorder project items: %w", err)
+	}
+	return &ReorderProjectItemsResult{ParentID: args.ParentID, ItemIDs: ordered}, nil
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// PurgeConnection permanently deletes every memory, entity, relationship
+// and link of a connection and, optionally, its connections.json entry and
+// SQLite database files. args.Confirm must repeat the connection name, so a
+// purge is never the result of a mistyped or defaulted argument.
+func (s *Server) PurgeConnection(ctx context.Context, args PurgeConnectionArgs) (*PurgeConnectionResult, error) {
+	name := args.ConnectionID
+	if name == "" {
+		return nil, invalidParamsf("connection_id is required")
+	}
+	if args.Confirm != name {
+		return nil, invalidParamsf("confirm must repeat the connection name %q to purge it", name)
+	}
+	if args.DeleteFiles && !args.RemoveConnection {
+		return nil, invalidParamsf("delete_files requires remove_connection")
+	}
+	if s.connectionManager == nil {
+		return nil, invalidParamsf("connection_id %q given but no connections are configured", name)
+	}
+	if args.RemoveConnection && (name == s.connectionManager.GetDefaultConnection() || name == s.defaultConnection) {
+		return nil, invalidParamsf("cannot remove the default connection %q; purge it without remove_connection", name)
+	}
+
+	store, err := s.connectionManager.GetStore(name)
+	if err != nil {
+		return nil, invalidParamsf("unknown connection %q: %v", name, err)
+	}
+	purger, ok := store.(storage.StorePurger)
+	if !ok {
+		return nil, fmt.Errorf("store does not support purging")
+	}
+	// Resolve the files while the connection is still configured.
+	var files []string
+	if args.DeleteFiles {
+		if files, err = s.connectionManager.DatabaseFiles(name); err != nil {
+			return nil, invalidParamsf("%v", err)
+		}
+	}
+
+	purged, err := purger.PurgeAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge connection %q: %w", name, err)
+	}
+	result := &PurgeConnectionResult{
+		ConnectionID:         name,
+		MemoriesDeleted:      purged.Memories,
+		EntitiesDeleted:      purged.Entities,
+		RelationshipsDeleted: purged.Relationships,
+		LinksDeleted:         purged.Links,
+		FilesDeleted:         []string{},
+	}
+	if s.routes != nil {
+		if result.RoutesDeleted, err = s.routes.DeleteConnection(ctx, name); err != nil {
+			return nil, fmt.Errorf("purged connection %q but failed to delete its memory routes: %w", name, err)
+		}
+	}
+	slog.Info("purged connection", "connection", name, "memories", purged.Memories, "entities", purged.Entities)
+
+	if !args.RemoveConnection {
+		return result, nil
+	}
+	// Removing the connection closes its store, so the files can go next.
+	if err := s.connectionManager.DeleteConnection(ctx, name); err != nil {
+		return nil, fmt.Errorf("purged connection %q but failed to remove it: %w", name, err)
+	}
+	result.ConnectionRemoved = true
+	for _, file := range files {
+		err := os.Remove(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("removed connection %q but failed to delete %s: %w", name, file, err)
+		}
+		result.FilesDeleted = append(result.FilesDeleted, file)
+	}
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/connections"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPurgeServer returns a server whose default connection is "work", with
+// a second connection "scratch", the manager and the connections.json path.
+func newPurgeServer(t *testing.T) (*mcp.Server, *connections.Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := connections.ConnectionsConfig{
+		DefaultConnection: "work",
+		Connections: []connections.Connection{
+			{Name: "work", Enabled: true, Database: connections.DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "work.db")}},
+			{Name: "scratch", Enabled: true, Database: connections.DatabaseConfig{Type: "sqlite", Path: "scratch.db"}},
+		},
+	}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	configPath := filepath.Join(dir, "connections.json")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+	manager, err := connections.NewManager(configPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = manager.Close() })
+
+	fallback, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = fallback.Close() })
+	return mcp.NewServer(fallback, mcp.WithConnectionManager(manager), mcp.WithDefaultConnection("work")), manager, configPath
+}
+
+func TestPurgeConnection_EmptiesStore(t *testing.T) {
+	srv, manager, _ := newPurgeServer(t)
+	ctx := context.Background()
+
+	for _, content := range []string{"Draft the quarterly plan", "Scratch idea about caching"} {
+		_, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: content, ConnectionID: "scratch"})
+		require.NoError(t, err)
+	}
+	deleted, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Discarded note", ConnectionID: "scratch"})
+	require.NoError(t, err)
+	scratch, err := manager.GetStore("scratch")
+	require.NoError(t, err)
+	require.NoError(t, scratch.Delete(ctx, deleted.ID))
+	kept, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Work memory that stays", ConnectionID: "work"})
+	require.NoError(t, err)
+
+	var result mcp.PurgeConnectionResult
+	callRPC(t, srv, "purge_connection", map[string]interface{}{
+		"connection_id": "scratch",
+		"confirm":       "scratch",
+	}, &result)
+	assert.Equal(t, "scratch", result.ConnectionID)
+	assert.Equal(t, 3, result.MemoriesDeleted)
+	assert.False(t, result.ConnectionRemoved)
+
+	page, err := scratch.List(ctx, storage.ListOptions{Page: 1, Limit: 10, IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+
+	work, err := manager.GetStore("work")
+	require.NoError(t, err)
+	_, err = work.Get(ctx, kept.ID)
+	assert.NoError(t, err, "other connections are untouched")
+}
+
+func TestPurgeConnection_ConfirmationGuard(t *testing.T) {
+	srv, manager, _ := newPurgeServer(t)
+	ctx := context.Background()
+	stored, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Scratch idea about caching", ConnectionID: "scratch"})
+	require.NoError(t, err)
+
+	for _, params := range []string{
+		`{"connection_id":"scratch"}`,
+		`{"connection_id":"scratch","confirm":"work"}`,
+		`{"connection_id":"scratch","confirm":"Scratch"}`,
+		`{"confirm":"scratch"}`,
+		`{"connection_id":"scratch","confirm":"scratch","delete_files":true}`,
+		`{"connection_id":"work","confirm":"work","remove_connection":true}`,
+		`{"connection_id":"missing","confirm":"missing"}`,
+	} {
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv,
+			`{"jsonrpc":"2.0","method":"purge_connection","params":`+params+`,"id":1}`), params)
+	}
+
+	scratch, err := manager.GetStore("scratch")
+	require.NoError(t, err)
+	_, err = scratch.Get(ctx, stored.ID)
+	assert.NoError(t, err, "a rejected purge deletes nothing")
+}
+
+func TestPurgeConnection_RemovesConnectionAndFiles(t *testing.T) {
+	srv, manager, configPath := newPurgeServer(t)
+	ctx := context.Background()
+	_, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Scratch idea about caching", ConnectionID: "scratch"})
+	require.NoError(t, err)
+	dbPath := filepath.Join(filepath.Dir(configPath), "scratch.db")
+	require.FileExists(t, dbPath)
+
+	result, err := srv.PurgeConnection(ctx, mcp.PurgeConnectionArgs{
+		ConnectionID:     "scratch",
+		Confirm:          "scratch",
+		RemoveConnection: true,
+		DeleteFiles:      true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.MemoriesDeleted)
+	assert.True(t, result.ConnectionRemoved)
+	assert.Contains(t, result.FilesDeleted, dbPath)
+	assert.NoFileExists(t, dbPath)
+
+	for _, conn := range manager.ListConnections() {
+		assert.NotEqual(t, "scratch", conn.Name)
+	}
+	reloaded, err := connections.NewManager(configPath)
+	require.NoError(t, err)
+	defer func() { _ = reloaded.Close() }()
+	assert.Len(t, reloaded.ListConnections(), 1, "connections.json no longer lists it")
+}
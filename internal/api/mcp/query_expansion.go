@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// DefaultQueryExpansionTerms is how many expansion terms find_related asks
+// the LLM for when WithQueryExpansion is given a non-positive count.
+const DefaultQueryExpansionTerms = 5
+
+// WithQueryExpansion lets find_related callers pass expand_query to have the
+// LLM suggest up to maxTerms synonyms or alternative spellings of the query
+// ("k8s" → "kubernetes"), each of which is also searched. Without this option
+// expand_query is ignored. Non-positive maxTerms uses
+// DefaultQueryExpansionTerms.
+func WithQueryExpansion(maxTerms int) ServerOption {
+	return func(s *Server) {
+		if maxTerms <= 0 {
+			maxTerms = DefaultQueryExpansionTerms
+		}
+		s.queryExpansionTerms = maxTerms
+	}
+}
+
+// expandQuery asks the LLM for alternative search terms for query. Any
+// failure is logged and yields no terms, so the search falls back to the raw
+// query.
+func (s *Server) expandQuery(ctx context.Context, query string) []string {
+	if s.engine == nil || s.queryExpansionTerms <= 0 {
+		return nil
+	}
+	prompt := fmt.Sprintf(`You are expanding a search query for a personal knowledge base.
+
+Query: %s
+
+List up to %d short alternative search terms that mean the same thing: synonyms, abbreviations and their expansions (e.g. "k8s" and "kubernetes", "PR" and "pull request"). Respond with one term per line and nothing else.`,
+		query, s.queryExpansionTerms)
+
+	completion, err := s.engine.Summarize(ctx, prompt)
+	if err != nil {
+		slog.Warn("query expansion failed; searching the raw query only", "error", err)
+		return nil
+	}
+	return parseExpansionTerms(completion, query, s.queryExpansionTerms)
+}
+
+// listMarker matches a leading bullet or number ("- ", "2. ", "3) ").
+var listMarker = regexp.MustCompile(`^\s*(?:(?:[-*•]|\d+[.)])\s+)?`)
+
+// parseExpansionTerms extracts at most limit distinct terms from an LLM
+// completion with one term per line (commas also separate terms). List
+// markers and quotes are stripped, and terms equal to the query are dropped.
+func parseExpansionTerms(completion, query string, limit int) []string {
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(query)): true}
+	var terms []string
+	for _, line := range strings.FieldsFunc(completion, func(r rune) bool { return r == '\n' || r == ',' }) {
+		term := listMarker.ReplaceAllString(line, "")
+		term = strings.Trim(term, "\"'` ")
+		key := strings.ToLower(term)
+		if term == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		terms = append(terms, term)
+		if len(terms) == limit {
+			break
+		}
+	}
+	return terms
+}
+
+// searchExpansionTerms runs a full-text search for each term and appends the
+// memories result does not already hold, up to opts.Limit in total. Each
+// added memory also counts towards result.Total. A term whose search fails
+// is skipped.
+func searchExpansionTerms(ctx context.Context, sp storage.SearchProvider, result *storage.PaginatedResult[types.Memory], terms []string, opts storage.SearchOptions) {
+	seen := make(map[string]bool, len(result.Items))
+	for _, m := range result.Items {
+		seen[m.ID] = true
+	}
+	for _, term := range terms {
+		termOpts := opts
+		termOpts.Query = term
+		extra, err := sp.FullTextSearch(ctx, termOpts)
+		if err != nil {
+			slog.Warn("expanded query search failed", "term", term, "error", err)
+			continue
+		}
+		for _, m := range extra.Items {
+			if seen[m.ID] {
+				continue
+			}
+			seen[m.ID] = true
+			result.Total++
+			if len(result.Items) < opts.Limit {
+				result.Items = append(result.Items, m)
+			}
+		}
+	}
+}
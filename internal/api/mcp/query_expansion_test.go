@@ -0,0 +1,85 @@
+package mcp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expansionEngine answers every LLM prompt with a fixed completion and has
+// no embeddings, so find_related uses full-text search.
+type expansionEngine struct {
+	recordingEngine
+	completion string
+	err        error
+	prompts    int
+}
+
+func (e *expansionEngine) Embed(context.Context, string) ([]float64, error) {
+	return nil, errors.New("no embeddings")
+}
+
+func (e *expansionEngine) Summarize(context.Context, string) (string, error) {
+	e.prompts++
+	return e.completion, e.err
+}
+
+func newExpansionServer(t *testing.T, eng *expansionEngine, opts ...mcp.ServerOption) *mcp.Server {
+	t.Helper()
+	eng.queued = map[string]string{}
+	srv, _ := newSQLiteTestServer(t, append([]mcp.ServerOption{mcp.WithEngine(eng)}, opts...)...)
+	for _, content := range []string{
+		"the k8s cluster was upgraded on friday",
+		"kubernetes node pools now autoscale",
+		"lunch menu for the offsite",
+	} {
+		_, err := srv.StoreMemory(context.Background(), mcp.StoreMemoryArgs{Content: content})
+		require.NoError(t, err)
+	}
+	return srv
+}
+
+func TestFindRelated_ExpandQuery(t *testing.T) {
+	eng := &expansionEngine{completion: "- kubernetes\n2. K8S\n- container orchestration\n- helm\n- kubectl"}
+	srv := newExpansionServer(t, eng, mcp.WithQueryExpansion(2))
+	ctx := context.Background()
+
+	plain, err := srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "k8s"})
+	require.NoError(t, err)
+	assert.Len(t, plain.Memories, 1)
+	assert.Empty(t, plain.ExpandedTerms)
+	assert.Zero(t, eng.prompts, "no LLM call without expand_query")
+
+	expanded, err := srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "k8s", ExpandQuery: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kubernetes", "container orchestration"}, expanded.ExpandedTerms,
+		"terms are capped and the query itself is dropped")
+	require.Len(t, expanded.Memories, 2)
+	assert.Contains(t, expanded.Memories[0].Content, "k8s", "the query's own hits come first")
+	assert.Contains(t, expanded.Memories[1].Content, "kubernetes")
+}
+
+func TestFindRelated_ExpandQueryFallsBackOnLLMFailure(t *testing.T) {
+	eng := &expansionEngine{err: errors.New("llm unavailable")}
+	srv := newExpansionServer(t, eng, mcp.WithQueryExpansion(3))
+
+	result, err := srv.FindRelated(context.Background(), mcp.FindRelatedArgs{Query: "k8s", ExpandQuery: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, eng.prompts)
+	assert.Empty(t, result.ExpandedTerms)
+	assert.Len(t, result.Memories, 1)
+}
+
+func TestFindRelated_ExpandQueryNeedsServerOption(t *testing.T) {
+	eng := &expansionEngine{completion: "kubernetes"}
+	srv := newExpansionServer(t, eng)
+
+	result, err := srv.FindRelated(context.Background(), mcp.FindRelatedArgs{Query: "k8s", ExpandQuery: true})
+	require.NoError(t, err)
+	assert.Zero(t, eng.prompts)
+	assert.Len(t, result.Memories, 1)
+}
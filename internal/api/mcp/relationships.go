@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// GetRelationships lists the typed relationships enrichment recorded for a
+// memory (those between the entities it mentions) or for one entity (those
+// from or to it), so users can audit what was inferred.
+func (s *Server) GetRelationships(ctx context.Context, args GetRelationshipsArgs) (*GetRelationshipsResult, error) {
+	if (args.MemoryID == "") == (args.EntityID == "") {
+		return nil, invalidParamsf("exactly one of memory_id or entity_id is required")
+	}
+
+	var store storage.MemoryStore
+	if args.MemoryID != "" {
+		var err error
+		if store, err = s.readStoreForID(ctx, args.MemoryID, args.ConnectionID); err != nil {
+			return nil, err
+		}
+	} else {
+		store, _ = s.resolveSearchStore(args.ConnectionID)
+	}
+	reader, ok := store.(storage.RelationshipReader)
+	if !ok {
+		return nil, fmt.Errorf("store does not support listing relationships")
+	}
+
+	limit := s.effectiveLimit(args.Limit)
+	rels, err := reader.ListRelationships(ctx, storage.RelationshipFilter{
+		MemoryID: args.MemoryID,
+		EntityID: args.EntityID,
+		Type:     args.Type,
+		Limit:    limit,
+	})
+	if errors.Is(err, storage.ErrNotFound) {
+		if args.MemoryID != "" {
+			return nil, notFoundf("memory not found: %s", args.MemoryID)
+		}
+		return nil, notFoundf("entity not found: %s", args.EntityID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relationships: %w", err)
+	}
+
+	edges := make([]RelationshipEdge, len(rels))
+	for i, r := range rels {
+		edges[i] = RelationshipEdge{
+			ID:         r.ID,
+			Type:       r.Type,
+			Source:     RelationshipEndpoint{ID: r.FromID, Name: r.SourceName, Type: r.SourceType},
+			Target:     RelationshipEndpoint{ID: r.ToID, Name: r.TargetName, Type: r.TargetType},
+			Confidence: r.Strength,
+			Context:    r.Context,
+			CreatedAt:  r.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:  r.UpdatedAt.Format(time.RFC3339),
+		}
+		if args.EntityID != "" {
+			edges[i].Direction = storage.TraversalIncoming
+			if r.FromID == args.EntityID {
+				edges[i].Direction = storage.TraversalOutgoing
+			}
+		}
+	}
+	return &GetRelationshipsResult{Relationships: edges, Total: len(edges), Limit: limit}, nil
+}
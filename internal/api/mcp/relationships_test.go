@@ -0,0 +1,65 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRelationships(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	mem, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Alice works on Memento"})
+	require.NoError(t, err)
+	db := store.GetDB()
+	now := time.Now()
+	for _, e := range [][3]string{{"ent:alice", "Alice", "person"}, {"ent:memento", "Memento", "project"}, {"ent:bob", "Bob", "person"}} {
+		_, err := db.ExecContext(ctx, `INSERT INTO entities (id, name, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			e[0], e[1], e[2], now, now)
+		require.NoError(t, err)
+	}
+	for _, id := range []string{"ent:alice", "ent:memento"} {
+		_, err := db.ExecContext(ctx, `INSERT INTO memory_entities (memory_id, entity_id, created_at) VALUES (?, ?, ?)`,
+			mem.ID, id, now)
+		require.NoError(t, err)
+	}
+	for _, r := range [][4]string{{"rel:1", "ent:alice", "ent:memento", "works_on"}, {"rel:2", "ent:bob", "ent:alice", "knows"}} {
+		_, err := db.ExecContext(ctx, `INSERT INTO relationships (id, source_id, target_id, type, weight, created_at, updated_at) VALUES (?, ?, ?, ?, 0.8, ?, ?)`,
+			r[0], r[1], r[2], r[3], now, now)
+		require.NoError(t, err)
+	}
+
+	var byMemory mcp.GetRelationshipsResult
+	callRPC(t, srv, "get_relationships", map[string]interface{}{"memory_id": mem.ID}, &byMemory)
+	require.Len(t, byMemory.Relationships, 1)
+	rel := byMemory.Relationships[0]
+	assert.Equal(t, "works_on", rel.Type)
+	assert.Equal(t, mcp.RelationshipEndpoint{ID: "ent:alice", Name: "Alice", Type: "person"}, rel.Source)
+	assert.Equal(t, "Memento", rel.Target.Name)
+	assert.InDelta(t, 0.8, rel.Confidence, 1e-9)
+	assert.Empty(t, rel.Direction)
+
+	byEntity, err := srv.GetRelationships(ctx, mcp.GetRelationshipsArgs{EntityID: "ent:alice"})
+	require.NoError(t, err)
+	require.Equal(t, 2, byEntity.Total)
+	directions := map[string]string{}
+	for _, r := range byEntity.Relationships {
+		directions[r.ID] = r.Direction
+	}
+	assert.Equal(t, map[string]string{"rel:1": "outgoing", "rel:2": "incoming"}, directions)
+
+	for params, want := range map[string]int{
+		`{}`: mcp.ErrCodeInvalidParams,
+		`{"memory_id":"` + mem.ID + `","entity_id":"ent:alice"}`: mcp.ErrCodeInvalidParams,
+		`{"memory_id":"mem:general:missing"}`:                    mcp.ErrCodeNotFound,
+		`{"entity_id":"ent:missing"}`:                            mcp.ErrCodeNotFound,
+	} {
+		assert.Equal(t, want, rpcErrorCode(t, srv,
+			`{"jsonrpc":"2.0","method":"get_relationships","params":`+params+`,"id":1}`), params)
+	}
+}
@@ -0,0 +1,175 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// Actions resolve_contradiction can apply to the losing memories.
+const (
+	resolveActionSupersede = "supersede"
+	resolveActionArchive   = "archive"
+)
+
+// ResolveContradiction settles a contradiction reported by detect_contradictions
+// by keeping the winner and moving every other memory in it to superseded (or
+// archived). The contradiction is re-checked first so a stale result cannot
+// change state, and every transition is validated before anything is written.
+func (s *Server) ResolveContradiction(ctx context.Context, args ResolveContradictionArgs) (*ResolveContradictionResult, error) {
+	ids := dedupIDs(args.Contradiction.MemoryIDs)
+	if len(ids) < 2 {
+		return nil, invalidParamsf("contradiction.memory_ids must list at least two memories")
+	}
+	if args.Contradiction.Type == "" {
+		return nil, invalidParamsf("contradiction.type is required")
+	}
+	if args.WinnerID == "" {
+		return nil, invalidParamsf("winner_id is required")
+	}
+	if !slices.Contains(ids, args.WinnerID) {
+		return nil, invalidParamsf("winner_id %s is not one of the contradiction's memory_ids", args.WinnerID)
+	}
+
+	action := strings.ToLower(args.Action)
+	if action == "" {
+		action = resolveActionSupersede
+	}
+	var target string
+	switch action {
+	case resolveActionSupersede:
+		target = types.StateSuperseded
+	case resolveActionArchive:
+		target = types.StateArchived
+	default:
+		return nil, invalidParamsf("invalid action %q: must be %q or %q", args.Action, resolveActionSupersede, resolveActionArchive)
+	}
+
+	memories := make(map[string]*types.Memory, len(ids))
+	for _, id := range ids {
+		m, err := s.resolveStoreForID(ctx, id).Get(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, notFoundf("memory not found: %s", id)
+			}
+			return nil, fmt.Errorf("failed to get memory %s: %w", id, err)
+		}
+		if m.DeletedAt != nil {
+			return nil, invalidParamsf("contradiction no longer holds: memory %s has been deleted", id)
+		}
+		memories[id] = m
+	}
+
+	if err := s.checkContradictionHolds(ctx, args.Contradiction.Type, args.WinnerID, ids); err != nil {
+		return nil, err
+	}
+
+	// Validate every transition up front so a rejected one leaves nothing
+	// half-resolved.
+	var losers []string
+	for _, id := range ids {
+		if id == args.WinnerID || memories[id].State == target {
+			continue
+		}
+		if !stateMachineOf(s.resolveStoreForID(ctx, id)).CanTransition(memories[id].State, target) {
+			return nil, invalidParamsf("cannot %s memory %s: invalid transition from '%s' to '%s'", action, id, memories[id].State, target)
+		}
+		losers = append(losers, id)
+	}
+	if len(losers) == 0 {
+		return nil, invalidParamsf("contradiction no longer holds: every other memory is already %s", target)
+	}
+
+	var linker interface {
+		CreateMemoryLink(ctx context.Context, id, sourceID, targetID, linkType string) error
+	}
+	if args.RecordRelationship {
+		var ok bool
+		linker, ok = s.resolveStoreForID(ctx, args.WinnerID).(interface {
+			CreateMemoryLink(ctx context.Context, id, sourceID, targetID, linkType string) error
+		})
+		if !ok {
+			return nil, fmt.Errorf("store does not support memory links")
+		}
+	}
+
+	result := &ResolveContradictionResult{
+		WinnerID: args.WinnerID,
+		Action:   action,
+		Memories: []ResolvedMemoryState{},
+	}
+	for _, id := range losers {
+		if err := s.resolveStoreForID(ctx, id).UpdateState(ctx, id, target); err != nil {
+			return nil, fmt.Errorf("failed to %s memory %s: %w", action, id, err)
+		}
+		if linker != nil {
+			if err := linker.CreateMemoryLink(ctx, uuid.New().String(), args.WinnerID, id, "SUPERSEDES"); err != nil {
+				return nil, fmt.Errorf("failed to record resolution link to %s: %w", id, err)
+			}
+			result.LinksCreated++
+		}
+	}
+
+	for _, id := range ids {
+		entry := ResolvedMemoryState{ID: id, Role: "loser", PreviousState: memories[id].State, State: memories[id].State}
+		if id == args.WinnerID {
+			entry.Role = "winner"
+		} else {
+			entry.State = target
+		}
+		result.Memories = append(result.Memories, entry)
+	}
+	result.Message = fmt.Sprintf("Kept %s; marked %d memories as %s", args.WinnerID, len(losers), target)
+	return result, nil
+}
+
+// checkContradictionHolds re-runs structural detection around the winner and
+// requires a contradiction of the same type covering every memory in ids.
+// Semantic contradictions are not re-checked, since that would cost another
+// LLM call; for them it is enough that the memories still exist.
+func (s *Server) checkContradictionHolds(ctx context.Context, contradictionType, winnerID string, ids []string) error {
+	if contradictionType == string(engine.ContradictionTypeSemantic) {
+		return nil
+	}
+
+	current, err := s.detector.DetectContradictions(ctx, winnerID)
+	if err != nil {
+		return fmt.Errorf("failed to re-check contradiction: %w", err)
+	}
+	for _, c := range current {
+		if string(c.Type) != contradictionType {
+			continue
+		}
+		covered := true
+		for _, id := range ids {
+			if !slices.Contains(c.MemoryIDs, id) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return nil
+		}
+	}
+	return invalidParamsf("contradiction no longer holds: no %s contradiction found between %s", contradictionType, strings.Join(ids, ", "))
+}
+
+// dedupIDs returns ids without blanks or repeats, preserving order.
+func dedupIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
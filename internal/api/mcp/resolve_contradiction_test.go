@@ -0,0 +1,97 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// marriedTo builds memory metadata asserting a married_to relationship, which
+// the contradiction detector reads as a single-valued relationship.
+func marriedTo(from, to string) map[string]interface{} {
+	return map[string]interface{}{
+		"relationships": []interface{}{
+			map[string]interface{}{"from_id": from, "to_id": to, "type": types.RelMarriedTo},
+		},
+	}
+}
+
+func TestResolveContradiction_SupersedesLoser(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	for _, m := range []*types.Memory{
+		{ID: "mem:general:old", Content: "Alice is married to Bob", Source: "test", State: types.StateActive, Metadata: marriedTo("ent:alice", "ent:bob")},
+		{ID: "mem:general:new", Content: "Alice is married to Carol", Source: "test", State: types.StateActive, Metadata: marriedTo("ent:alice", "ent:carol")},
+	} {
+		require.NoError(t, store.Store(ctx, m))
+	}
+
+	detected, err := srv.DetectContradictions(ctx, mcp.DetectContradictionsArgs{MemoryID: "mem:general:new"})
+	require.NoError(t, err)
+	require.NotEmpty(t, detected.Contradictions)
+	contradiction := detected.Contradictions[0]
+
+	var result mcp.ResolveContradictionResult
+	callRPC(t, srv, "resolve_contradiction", map[string]interface{}{
+		"contradiction":       contradiction,
+		"winner_id":           "mem:general:new",
+		"record_relationship": true,
+	}, &result)
+	assert.Equal(t, "supersede", result.Action)
+	assert.Equal(t, 1, result.LinksCreated)
+	states := map[string]string{}
+	for _, m := range result.Memories {
+		states[m.ID+"/"+m.Role] = m.State
+	}
+	assert.Equal(t, map[string]string{
+		"mem:general:new/winner": types.StateActive,
+		"mem:general:old/loser":  types.StateSuperseded,
+	}, states)
+
+	old, err := store.Get(ctx, "mem:general:old")
+	require.NoError(t, err)
+	assert.Equal(t, types.StateSuperseded, old.State)
+	links, err := store.ListMemoryLinks(ctx, "mem:general:new")
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, "mem:general:old", links[0].TargetID)
+
+	// Resolving the same contradiction again has nothing left to change.
+	_, err = srv.ResolveContradiction(ctx, mcp.ResolveContradictionArgs{Contradiction: contradiction, WinnerID: "mem:general:new"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no longer holds")
+}
+
+func TestResolveContradiction_Validation(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"mem:general:a", "mem:general:b"} {
+		require.NoError(t, store.Store(ctx, &types.Memory{ID: id, Content: "fixture " + id, Source: "test", State: types.StateActive}))
+	}
+
+	req := `{"jsonrpc":"2.0","method":"resolve_contradiction","params":{"contradiction":{"type":"semantic","memory_ids":["mem:general:a","mem:general:b"]},"winner_id":"mem:general:z"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), "winner must be part of the contradiction")
+
+	req = `{"jsonrpc":"2.0","method":"resolve_contradiction","params":{"contradiction":{"type":"conflicting_relationship","memory_ids":["mem:general:a","mem:general:b"]},"winner_id":"mem:general:a"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), "stale structural contradictions are rejected")
+
+	req = `{"jsonrpc":"2.0","method":"resolve_contradiction","params":{"contradiction":{"type":"semantic","memory_ids":["mem:general:a","mem:general:b"]},"winner_id":"mem:general:a","action":"archive"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), "active memories cannot be archived directly")
+
+	// Semantic contradictions are not re-checked by the LLM.
+	result, err := srv.ResolveContradiction(ctx, mcp.ResolveContradictionArgs{
+		Contradiction: mcp.ContradictionResult{Type: "semantic", MemoryIDs: []string{"mem:general:a", "mem:general:b"}},
+		WinnerID:      "mem:general:b",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.LinksCreated)
+	loser, err := store.Get(ctx, "mem:general:a")
+	require.NoError(t, err)
+	assert.Equal(t, types.StateSuperseded, loser.State)
+}
@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// lookupTool returns the definition of the named tool, whether or not it is
+// listed in read-only mode.
+func (s *Server) lookupTool(name string) (MCPTool, bool) {
+	for _, t := range s.allTools() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return MCPTool{}, false
+}
+
+// validateToolArguments checks tools/call arguments against the tool's
+// declared input schema: every argument must be a declared property, every
+// required property must be present, and values must match the declared
+// JSON type (and enum, when one is given). The returned error is an
+// invalid-params error naming the offending argument.
+func validateToolArguments(tool MCPTool, args map[string]interface{}) error {
+	properties, _ := tool.InputSchema["properties"].(map[string]interface{})
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop, ok := properties[name].(map[string]interface{})
+		if !ok {
+			if suggestion := closestProperty(name, properties); suggestion != "" {
+				return invalidParamsf("unknown argument %q for %s (did you mean %q?)", name, tool.Name, suggestion)
+			}
+			return invalidParamsf("unknown argument %q for %s", name, tool.Name)
+		}
+		value := args[name]
+		if value == nil {
+			continue
+		}
+		if want, _ := prop["type"].(string); want != "" && !matchesJSONType(value, want) {
+			return invalidParamsf("argument %q for %s must be of type %s", name, tool.Name, want)
+		}
+		if enum, ok := prop["enum"].([]string); ok {
+			if str, isString := value.(string); isString && str != "" && !containsFold(enum, str) {
+				return invalidParamsf("argument %q for %s must be one of %s, got %q", name, tool.Name, strings.Join(enum, ", "), str)
+			}
+		}
+	}
+
+	required, _ := tool.InputSchema["required"].([]string)
+	for _, name := range required {
+		if args[name] == nil {
+			return invalidParamsf("missing required argument %q for %s", name, tool.Name)
+		}
+	}
+	return nil
+}
+
+// matchesJSONType reports whether value, as decoded by encoding/json, is of
+// the JSON Schema type want. Unrecognised types always match.
+func matchesJSONType(value interface{}, want string) bool {
+	switch want {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+// containsFold reports whether values contains s, ignoring case; handlers
+// lowercase enum arguments such as sort_order themselves.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// closestProperty suggests the declared property a misspelled argument most
+// likely meant: one that extends it (connection → connection_id) or is at
+// most two edits away. It returns "" when nothing is close.
+func closestProperty(name string, properties map[string]interface{}) string {
+	best, bestDistance := "", 3
+	for prop := range properties {
+		d := editDistance(name, prop)
+		if strings.HasPrefix(prop, name+"_") || strings.HasPrefix(name, prop+"_") {
+			d = 1
+		}
+		if d < bestDistance || (d == bestDistance && prop < best) {
+			best, bestDistance = prop, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,154 @@
+package mcp_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callToolRaw invokes a tool through tools/call and returns the result
+// envelope, so tests can inspect IsError and the error text.
+func callToolRaw(t *testing.T, srv *mcp.Server, name string, args map[string]interface{}) mcp.MCPToolCallResult {
+	t.Helper()
+	var result mcp.MCPToolCallResult
+	callRPC(t, srv, "tools/call", map[string]interface{}{"name": name, "arguments": args}, &result)
+	return result
+}
+
+// TestToolSchemas_CoverArgs guards strict tools/call validation: every field
+// a handler decodes must be declared in its tool's input schema, or valid
+// calls would be rejected as unknown arguments.
+func TestToolSchemas_CoverArgs(t *testing.T) {
+	argTypes := map[string]interface{}{
+		"store_memory":            mcp.StoreMemoryArgs{},
+		"get_embedding":           mcp.GetEmbeddingArgs{},
+		"memory_similarity":       mcp.MemorySimilarityArgs{},
+		"cluster_memories":        mcp.ClusterMemoriesArgs{},
+		"generate_digest":         mcp.GenerateDigestArgs{},
+		"create_typed_memory":     mcp.CreateTypedMemoryArgs{},
+		"list_memory_templates":   mcp.ListMemoryTemplatesArgs{},
+		"recall_memory":           mcp.RecallMemoryArgs{},
+		"find_related":            mcp.FindRelatedArgs{},
+		"update_memory_state":     mcp.UpdateMemoryStateArgs{},
+		"forget_memory":           mcp.ForgetMemoryArgs{},
+		"evolve_memory":           mcp.EvolveMemoryArgs{},
+		"supersede_memory":        mcp.SupersedeMemoryArgs{},
+		"detect_contradictions":   mcp.DetectContradictionsArgs{},
+		"resolve_contradiction":   mcp.ResolveContradictionArgs{},
+		"recompute_decay":         mcp.RecomputeDecayArgs{},
+		"get_decay_report":        mcp.GetDecayReportArgs{},
+		"begin_session":           mcp.BeginSessionArgs{},
+		"update_memory":           mcp.UpdateMemoryArgs{},
+		"explain_reasoning":       mcp.ExplainReasoningArgs{},
+		"retry_enrichment":        mcp.RetryEnrichmentArgs{},
+		"get_session_context":     mcp.GetSessionContextArgs{},
+		"traverse_memory_graph":   mcp.TraverseMemoryGraphArgs{},
+		"get_memory_neighbors":    mcp.GetMemoryNeighborsArgs{},
+		"get_memory_context":      mcp.GetMemoryContextArgs{},
+		"get_audit_log":           mcp.GetAuditLogArgs{},
+		"restore_memory":          mcp.RestoreMemoryArgs{},
+		"list_deleted_memories":   mcp.ListDeletedMemoriesArgs{},
+		"get_evolution_chain":     mcp.GetEvolutionChainArgs{},
+		"summarize_memory":        mcp.SummarizeMemoryArgs{},
+		"get_memory_snapshot":     mcp.GetMemorySnapshotArgs{},
+		"restore_memory_snapshot": mcp.RestoreMemorySnapshotArgs{},
+		"create_project":          mcp.CreateProjectArgs{},
+		"add_project_item":        mcp.AddProjectItemArgs{},
+		"move_project_item":       mcp.MoveProjectItemArgs{},
+		"reorder_project_items":   mcp.ReorderProjectItemsArgs{},
+		"batch_update_state":      mcp.BatchUpdateStateArgs{},
+		"get_state_history":       mcp.GetStateHistoryArgs{},
+		"get_memory":              mcp.GetMemoryArgs{},
+		"get_project_tree":        mcp.GetProjectTreeArgs{},
+		"list_projects":           mcp.ListProjectsArgs{},
+		"list_entities":           mcp.ListEntitiesArgs{},
+		"merge_entities":          mcp.MergeEntitiesArgs{},
+		"purge_connection":        mcp.PurgeConnectionArgs{},
+		"get_graph_export":        mcp.GetGraphExportArgs{},
+		"get_relationships":       mcp.GetRelationshipsArgs{},
+		"recall_about_entity":     mcp.RecallAboutEntityArgs{},
+	}
+
+	srv := mcp.NewServer(newMockStore())
+	var list mcp.MCPToolsListResult
+	callRPC(t, srv, "tools/list", map[string]interface{}{}, &list)
+
+	for _, tool := range list.Tools {
+		args, ok := argTypes[tool.Name]
+		if !ok {
+			continue
+		}
+		props, _ := tool.InputSchema["properties"].(map[string]interface{})
+		rt := reflect.TypeOf(args)
+		for i := 0; i < rt.NumField(); i++ {
+			name := strings.Split(rt.Field(i).Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			assert.Contains(t, props, name, "%s: %s.%s is not declared in the input schema", tool.Name, rt.Name(), rt.Field(i).Name)
+		}
+	}
+}
+
+func TestToolsCall_RejectsUnknownArgument(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store)
+
+	result := callToolRaw(t, srv, "store_memory", map[string]interface{}{
+		"content":    "misspelled connection",
+		"connection": "work",
+	})
+	require.True(t, result.IsError)
+	text := result.Content[0].Text
+	assert.Contains(t, text, `unknown argument "connection"`)
+	assert.Contains(t, text, `did you mean "connection_id"`)
+	assert.Empty(t, store.memories, "nothing should be stored when validation fails")
+}
+
+func TestToolsCall_RejectsMissingRequiredArgument(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	result := callToolRaw(t, srv, "store_memory", map[string]interface{}{"source": "test"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, `missing required argument "content"`)
+}
+
+func TestToolsCall_RejectsWrongType(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	result := callToolRaw(t, srv, "recall_memory", map[string]interface{}{"limit": "ten"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, `argument "limit" for recall_memory must be of type integer`)
+
+	result = callToolRaw(t, srv, "recall_memory", map[string]interface{}{"sort_order": "sideways"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, `argument "sort_order"`)
+}
+
+func TestToolsCall_AcceptsDeclaredArguments(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	result := callToolRaw(t, srv, "recall_memory", map[string]interface{}{
+		"limit":      5,
+		"sort_by":    "created_at",
+		"sort_order": "ASC",
+	})
+	assert.False(t, result.IsError, result.Content[0].Text)
+}
+
+// TestNativeCall_KeepsLenientDecoding verifies that direct JSON-RPC method
+// calls are not subject to schema validation.
+func TestNativeCall_KeepsLenientDecoding(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	var result mcp.StoreMemoryResult
+	callRPC(t, srv, "store_memory", map[string]interface{}{
+		"content":    "native call with an extra field",
+		"connection": "ignored",
+	}, &result)
+	assert.NotEmpty(t, result.ID)
+}
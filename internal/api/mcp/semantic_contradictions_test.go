@@ -0,0 +1,107 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDetectContradictions_Semantic checks that semantic mode asks the LLM
+// about similar memories and merges its verdicts into the structural results.
+func TestDetectContradictions_Semantic(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	eng := &summarizingEngine{recordingEngine: recordingEngine{queued: map[string]string{}}}
+	srv := mcp.NewServer(store, mcp.WithEngine(eng), mcp.WithSemanticContradictions(true))
+	ctx := context.Background()
+
+	first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "The ledger service uses Postgres"})
+	require.NoError(t, err)
+	second, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "The ledger service uses MySQL, not Postgres"})
+	require.NoError(t, err)
+
+	eng.response = `{"contradictions":[{"id":"` + second.ID + `","confidence":0.85,"explanation":"Different databases for the ledger"}]}`
+
+	result, err := srv.DetectContradictions(ctx, mcp.DetectContradictionsArgs{MemoryID: first.ID, Semantic: true})
+	require.NoError(t, err)
+	assert.Contains(t, eng.prompt, second.ID)
+
+	var semantic []mcp.ContradictionResult
+	for _, c := range result.Contradictions {
+		if c.Type == "semantic" {
+			semantic = append(semantic, c)
+		}
+	}
+	require.Len(t, semantic, 1)
+	assert.ElementsMatch(t, []string{first.ID, second.ID}, semantic[0].MemoryIDs)
+	assert.InDelta(t, 0.85, semantic[0].Confidence, 1e-9)
+	assert.Equal(t, len(result.Contradictions), result.Total)
+}
+
+// TestDetectContradictions_SemanticValidation covers the gating: semantic mode
+// needs a memory_id and must be enabled on the server.
+func TestDetectContradictions_SemanticValidation(t *testing.T) {
+	eng := &summarizingEngine{recordingEngine: recordingEngine{queued: map[string]string{}}}
+
+	srv := mcp.NewServer(newMockStore(), mcp.WithEngine(eng))
+	req := `{"jsonrpc":"2.0","method":"detect_contradictions","params":{"memory_id":"mem:general:x","semantic":true},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req))
+
+	srv = mcp.NewServer(newMockStore(), mcp.WithEngine(eng), mcp.WithSemanticContradictions(true))
+	req = `{"jsonrpc":"2.0","method":"detect_contradictions","params":{"semantic":true},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req))
+	assert.Empty(t, eng.prompt, "no LLM call should be made when validation fails")
+}
+
+// TestDetectContradictions_MinConfidence checks that min_confidence drops
+// weak contradictions and that the rest come back strongest first.
+func TestDetectContradictions_MinConfidence(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	eng := &summarizingEngine{recordingEngine: recordingEngine{queued: map[string]string{}}}
+	srv := mcp.NewServer(store, mcp.WithEngine(eng), mcp.WithSemanticContradictions(true))
+	ctx := context.Background()
+
+	first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "The ledger service uses Postgres"})
+	require.NoError(t, err)
+	ids := map[string]string{}
+	for name, content := range map[string]string{
+		"weak":   "The ledger service might move off Postgres",
+		"strong": "The ledger service uses MySQL, not Postgres",
+		"medium": "The ledger service stores data in SQLite",
+	} {
+		stored, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: content})
+		require.NoError(t, err)
+		ids[name] = stored.ID
+	}
+	eng.response = `{"contradictions":[` +
+		`{"id":"` + ids["weak"] + `","confidence":0.3,"explanation":"Possible migration"},` +
+		`{"id":"` + ids["medium"] + `","confidence":0.6,"explanation":"Different storage"},` +
+		`{"id":"` + ids["strong"] + `","confidence":0.9,"explanation":"Different databases"}]}`
+
+	all, err := srv.DetectContradictions(ctx, mcp.DetectContradictionsArgs{MemoryID: first.ID, Semantic: true})
+	require.NoError(t, err)
+	require.Len(t, all.Contradictions, 3)
+	assert.Zero(t, all.BelowThreshold)
+	for i, want := range []float64{0.9, 0.6, 0.3} {
+		assert.InDelta(t, want, all.Contradictions[i].Confidence, 1e-9, i)
+	}
+
+	filtered, err := srv.DetectContradictions(ctx, mcp.DetectContradictionsArgs{MemoryID: first.ID, Semantic: true, MinConfidence: 0.5})
+	require.NoError(t, err)
+	require.Len(t, filtered.Contradictions, 2)
+	assert.Equal(t, 2, filtered.Total)
+	assert.Equal(t, 1, filtered.BelowThreshold)
+	assert.Contains(t, filtered.Contradictions[0].MemoryIDs, ids["strong"])
+	assert.Contains(t, filtered.Contradictions[1].MemoryIDs, ids["medium"])
+	assert.Contains(t, filtered.Message, "1 below min_confidence")
+
+	req := `{"jsonrpc":"2.0","method":"detect_contradictions","params":{"min_confidence":1.5},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req))
+}
@@ -2,20 +2,22 @@ package mcp
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"slices"
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/scrypster/memento/internal/attribution"
 	"github.com/scrypster/memento/internal/config"
 	"github.com/scrypster/memento/internal/connections"
 	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/internal/llm"
 	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/pkg/types"
 )
@@ -24,22 +26,79 @@ import (
 // Using an interface keeps the MCP package loosely coupled and testable.
 type memoryEngine interface {
 	QueueEnrichmentForMemory(memoryID, content string) bool
+	WaitForEnrichment(memoryID string) (<-chan types.MemoryStatus, func())
 	Embed(ctx context.Context, text string) ([]float64, error)
 	Summarize(ctx context.Context, prompt string) (string, error)
+	Status(ctx context.Context) (*engine.EngineStatus, error)
 }
 
 // Server implements the Model Context Protocol (MCP) for Memento.
 // It provides JSON-RPC 2.0 based tools for AI assistants to interact
 // with the memory system.
 type Server struct {
-	memoryStore        storage.MemoryStore
-	searchProvider     storage.SearchProvider
-	config             *config.Config
-	detector           *engine.ContradictionDetector
-	connectionManager  *connections.Manager
-	engine             memoryEngine
-	defaultConnection  string // connection used when no connection_id is provided
-	sessionID          string // unique ID generated once per MCP server lifetime
+	memoryStore               storage.MemoryStore
+	searchProvider            storage.SearchProvider
+	config                    *config.Config
+	detector                  *engine.ContradictionDetector
+	connectionManager         *connections.Manager
+	engine                    memoryEngine
+	defaultConnection         string                  // connection used when no connection_id is provided
+	defaultConnectionResolver func() string           // picks defaultConnection when the server is built (see WithDefaultConnectionResolver)
+	session                   *sessionTracker         // current session ID, rotated after an idle gap or TTL (see WithSessionIdleTimeout, WithSessionTTL)
+	readOnly                  bool                    // reject mutating tools (see WithReadOnly)
+	requestTimeout            time.Duration           // per-request deadline (see WithRequestTimeout)
+	routes                    *connections.RouteTable // memory ID → connection; set enables opaque IDs (see WithOpaqueIDs)
+	maxContentLength          int                     // store_memory content limit in characters; 0 = unlimited (see WithMaxContentLength)
+	normalizeUnicode          bool                    // NFC-normalize stored content (see WithUnicodeNormalization)
+	auditLog                  bool                    // record mutating tool calls in the audit log (see WithAuditLog)
+	dedupNormalization        string                  // how content is normalized before hashing into the memory ID (see WithDedupNormalization)
+	idHash                    contentHashFunc         // hash deterministic memory IDs are derived from (SHA-256 outside tests)
+	idCollisionCheck          bool                    // store colliding content under a suffixed ID (see WithIDCollisionCheck)
+	semanticContradictions    bool                    // allow detect_contradictions semantic mode (see WithSemanticContradictions)
+	embeddingDebug            bool                    // allow get_embedding (see WithEmbeddingDebug)
+	idempotency               *idempotencyCache       // store_memory results by idempotency_key (see WithIdempotencyTTL)
+	decayCooldown             *decayCooldown          // last recompute_decay per connection (see WithDecayRecomputeCooldown)
+	defaultLimit              int                     // limit used when a list/search tool omits one (see WithResultLimits)
+	maxLimit                  int                     // largest limit a list/search tool may request (see WithResultLimits)
+	traversalBreadth          int                     // memories expanded per entity per traversal hop; 0 = storage default (see WithTraversalBreadth)
+	queryExpansionTerms       int                     // synonyms find_related's expand_query asks for; 0 disables it (see WithQueryExpansion)
+	fuzzyThreshold            float64                 // trigram similarity find_related's fuzzy needs; 0 = storage default (see WithFuzzySearch)
+	fuzzyMaxResults           int                     // fuzzy matches find_related appends; 0 = storage default (see WithFuzzySearch)
+	notifications             notifier                // server-to-client notifications (see NotifyEnrichment)
+	memoryTemplates           []types.MemoryTemplate  // templates create_typed_memory accepts (see WithMemoryTemplates)
+}
+
+// ErrReadOnly is returned when a mutating tool is called on a server started
+// with WithReadOnly(true).
+var ErrReadOnly = errors.New("server is in read-only mode")
+
+// mutatingTools lists every tool that writes to the memory store. These are
+// rejected and hidden from tools/list when the server is read-only.
+var mutatingTools = map[string]bool{
+	"store_memory":            true,
+	"create_typed_memory":     true,
+	"update_memory":           true,
+	"update_memory_state":     true,
+	"batch_update_state":      true,
+	"forget_memory":           true,
+	"evolve_memory":           true,
+	"supersede_memory":        true,
+	"consolidate_memories":    true,
+	"restore_memory":          true,
+	"retry_enrichment":        true,
+	"create_project":          true,
+	"add_project_item":        true,
+	"move_project_item":       true,
+	"reorder_project_items":   true,
+	"summarize_memory":        true,
+	"restore_memory_snapshot": true,
+	"resolve_contradiction":   true,
+	"recompute_decay":         true,
+	"begin_session":           true,
+	"end_session":             true,
+	"merge_entities":          true,
+	"purge_connection":        true,
+	"generate_digest":         true,
 }
 
 // ServerOption is a functional option for configuring a Server.
@@ -96,6 +155,164 @@ func WithDefaultConnection(name string) ServerOption {
 	}
 }
 
+// WithDefaultConnectionResolver makes NewServer call resolve, once all
+// options are applied, to pick the default connection, for example from a
+// project file in the working directory. A resolver that returns "" keeps
+// the connection set by WithDefaultConnection, if any.
+func WithDefaultConnectionResolver(resolve func() string) ServerOption {
+	return func(s *Server) {
+		s.defaultConnectionResolver = resolve
+	}
+}
+
+// WithReadOnly puts the server in read-only mode. Mutating tools (store,
+// update, forget, evolve, consolidate, state changes, project creation, ...)
+// are rejected with ErrReadOnly and omitted from tools/list, while recall,
+// search, traversal and listing keep working. Useful for sharing a memory
+// store with a reviewer (via the MEMENTO_READONLY env var).
+func WithReadOnly(readOnly bool) ServerOption {
+	return func(s *Server) {
+		s.readOnly = readOnly
+	}
+}
+
+// WithRequestTimeout bounds every request with a context deadline of d, so
+// a stalled storage or LLM call cannot hang the client indefinitely. Requests
+// that exceed it fail with ErrRequestTimeout (code ErrCodeTimeout). The stdio
+// transport answers with that error on time even when a handler ignores its
+// context, but the handler keeps running in the background and may still
+// hold the store. A non-positive d disables the bound (the default).
+func WithRequestTimeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.requestTimeout = d
+	}
+}
+
+// WithOpaqueIDs switches generated memory IDs from mem:<connection>:<hash>
+// to mem:<uuid>, which does not reveal the connection name. Because the ID
+// no longer says where the memory lives, every new ID is recorded in routes
+// and ID lookups consult routes before falling back to parsing the ID.
+// Run routes.Backfill once so memories created before the switch keep
+// routing. Without this option the deterministic scheme is used.
+func WithOpaqueIDs(routes *connections.RouteTable) ServerOption {
+	return func(s *Server) {
+		s.routes = routes
+	}
+}
+
+// WithMaxContentLength caps store_memory content at n characters. Longer
+// content is rejected with invalid params unless the call sets truncate, in
+// which case the full content is stored but enrichment only reads the first
+// n characters and the memory's metadata records the cut-off. A non-positive
+// n disables the limit (the default).
+func WithMaxContentLength(n int) ServerOption {
+	return func(s *Server) {
+		s.maxContentLength = n
+	}
+}
+
+// WithDedupNormalization sets how store_memory normalizes content before
+// hashing it into the memory ID: config.DedupExact (the default) hashes the
+// content as-is, config.DedupWhitespace trims it and collapses whitespace
+// runs, and config.DedupNormalized additionally lowercases it and strips
+// trailing punctuation, so "Hello World." and "hello   world" dedupe to one
+// memory. Only the hash input is normalized; the stored content is not.
+// Stores record their own content_hash, so open them with the same level
+// (e.g. sqlite.WithDedupNormalization) to keep the two consistent.
+func WithDedupNormalization(level string) ServerOption {
+	return func(s *Server) {
+		s.dedupNormalization = level
+	}
+}
+
+// WithSemanticContradictions allows detect_contradictions to run with
+// semantic=true, which retrieves a memory's most similar memories via hybrid
+// search and asks the LLM engine whether any contradict it. It is off by
+// default because each semantic check costs an LLM call.
+func WithSemanticContradictions(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.semanticContradictions = enabled
+	}
+}
+
+// WithResultLimits sets the page size used by recall_memory, find_related,
+// list_deleted_memories, list_projects and traverse_memory_graph when the
+// caller omits limit, and the cap applied when it asks for more. The cap never
+// exceeds storage.MaxLimit, and non-positive values keep the defaults
+// (storage.DefaultLimit and storage.MaxLimit).
+func WithResultLimits(defaultLimit, maxLimit int) ServerOption {
+	return func(s *Server) {
+		if maxLimit > 0 {
+			s.maxLimit = min(maxLimit, storage.MaxLimit)
+		}
+		if defaultLimit > 0 {
+			s.defaultLimit = defaultLimit
+		}
+		s.defaultLimit = min(s.defaultLimit, s.maxLimit)
+	}
+}
+
+// WithFuzzySearch tunes the typo tolerance find_related applies when called
+// with fuzzy: a stored word needs a trigram similarity of at least
+// threshold (0 to 1) to a query word to match, and at most maxResults fuzzy
+// matches are appended after the exact ones. Non-positive values keep
+// storage.DefaultFuzzyThreshold and storage.DefaultFuzzyMaxResults.
+func WithFuzzySearch(threshold float64, maxResults int) ServerOption {
+	return func(s *Server) {
+		s.fuzzyThreshold = max(threshold, 0)
+		s.fuzzyMaxResults = max(maxResults, 0)
+	}
+}
+
+// WithTraversalBreadth caps how many memories each entity contributes to a
+// hop of traverse_memory_graph, so a hub entity linked to thousands of
+// memories only expands the n with the highest decay score. Non-positive
+// values keep storage.DefaultTraversalBreadth.
+func WithTraversalBreadth(n int) ServerOption {
+	return func(s *Server) {
+		s.traversalBreadth = max(n, 0)
+	}
+}
+
+// WithSessionIdleTimeout sets how long the server may go without a tool call
+// before the next stored memory starts a new session ID. Memories stored with
+// an explicit session_id are unaffected. 0 disables rotation, so one session
+// lasts the server's lifetime. Defaults to DefaultSessionIdleTimeout.
+func WithSessionIdleTimeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.session.idleTimeout = d
+	}
+}
+
+// WithSessionTTL sets the maximum age of a session: once it is older than d
+// the next stored memory starts a new session ID, however busy the server
+// is. Memories already stored keep their session. 0 (the default) disables
+// it.
+func WithSessionTTL(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.session.ttl = d
+	}
+}
+
+// WithIdempotencyTTL sets how long store_memory remembers a result for its
+// idempotency_key. A repeat call with the same key (and connection) inside
+// this window returns the original result instead of storing again.
+// Defaults to DefaultIdempotencyTTL.
+func WithIdempotencyTTL(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.idempotency.ttl = d
+	}
+}
+
+// WithDecayRecomputeCooldown sets how long recompute_decay refuses to run
+// again for the same connection. 0 disables the cooldown.
+// Defaults to DefaultDecayRecomputeCooldown.
+func WithDecayRecomputeCooldown(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.decayCooldown.interval = d
+	}
+}
+
 // NewServer creates a new MCP server instance.
 //
 // The variadic opts parameter accepts zero or more ServerOption values.
@@ -106,13 +323,27 @@ func WithDefaultConnection(name string) ServerOption {
 //	srv := mcp.NewServer(store, mcp.WithConfig(cfg))   // new call sites — with config
 func NewServer(store storage.MemoryStore, opts ...ServerOption) *Server {
 	s := &Server{
-		memoryStore: store,
-		detector:    engine.NewContradictionDetector(store),
-		sessionID:   uuid.New().String(),
+		memoryStore:   store,
+		detector:      engine.NewContradictionDetector(store),
+		session:       newSessionTracker(DefaultSessionIdleTimeout),
+		idempotency:   newIdempotencyCache(DefaultIdempotencyTTL),
+		decayCooldown: newDecayCooldown(DefaultDecayRecomputeCooldown),
+		defaultLimit:  storage.DefaultLimit,
+		maxLimit:      storage.MaxLimit,
+
+		normalizeUnicode: true,
+		memoryTemplates:  types.BuiltinMemoryTemplates(),
+		idHash:           sha256Hash,
+		idCollisionCheck: true,
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.defaultConnectionResolver != nil {
+		if name := s.defaultConnectionResolver(); name != "" {
+			s.defaultConnection = name
+		}
+	}
 	// If no explicit SearchProvider was injected, check whether the MemoryStore
 	// itself also implements SearchProvider (e.g. *sqlite.MemoryStore does).
 	// This keeps existing call sites working without any changes.
@@ -121,7 +352,7 @@ func NewServer(store storage.MemoryStore, opts ...ServerOption) *Server {
 			s.searchProvider = sp
 		}
 	}
-	log.Printf("memento-mcp: session ID: %s", s.sessionID)
+	slog.Debug("session started", "session_id", s.session.current())
 	return s
 }
 
@@ -131,8 +362,27 @@ func (s *Server) Config() *config.Config {
 	return s.config
 }
 
+// ReadOnly reports whether the server rejects mutating tools.
+func (s *Server) ReadOnly() bool {
+	return s.readOnly
+}
+
+// checkWritable returns ErrReadOnly when name is a mutating tool and the
+// server is read-only.
+func (s *Server) checkWritable(name string) error {
+	if s.readOnly && mutatingTools[name] {
+		return fmt.Errorf("%s is not allowed: %w", name, ErrReadOnly)
+	}
+	return nil
+}
+
 // HandleRequest processes a JSON-RPC 2.0 request and returns a response.
 // This is the main entry point for MCP protocol handling.
+//
+// Handler failures are reported with a typed error code (see errorCode):
+// ErrCodeNotFound, ErrCodeInvalidParams, ErrCodeReadOnly, ErrCodeTimeout,
+// ErrCodeRateLimited, or
+// ErrCodeServerError for anything unexpected.
 func (s *Server) HandleRequest(ctx context.Context, requestJSON []byte) ([]byte, error) {
 	var req JSONRPCRequest
 	if err := json.Unmarshal(requestJSON, &req); err != nil {
@@ -144,6 +394,33 @@ func (s *Server) HandleRequest(ctx context.Context, requestJSON []byte) ([]byte,
 		return s.errorResponse(req.ID, ErrCodeInvalidRequest, "Invalid JSON-RPC version", nil)
 	}
 
+	if err := s.checkWritable(req.Method); err != nil {
+		return s.errorResponse(req.ID, errorCode(err), err.Error(), nil)
+	}
+	if err := s.checkConnectionWritable(ctx, req.Method, req.Params); err != nil {
+		return s.errorResponse(req.ID, errorCode(err), err.Error(), nil)
+	}
+
+	if s.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.requestTimeout)
+		defer cancel()
+	}
+
+	// Name the calling agent so stores can attribute what they record,
+	// such as state transitions.
+	if storage.ActorFromContext(ctx) == "" {
+		ctx = storage.WithActor(ctx, attribution.DetectAgent())
+	}
+
+	// Every tool call counts as session activity. It is recorded after the
+	// handler runs so store_memory can still see the idle gap before it.
+	switch req.Method {
+	case "initialize", "initialized", "tools/list":
+	default:
+		defer s.session.touch()
+	}
+
 	// Route to appropriate handler
 	var result interface{}
 	var err error
@@ -163,8 +440,14 @@ func (s *Server) HandleRequest(ctx context.Context, requestJSON []byte) ([]byte,
 	// Native JSON-RPC methods (kept for backward-compat with direct callers)
 	case "store_memory":
 		result, err = s.handleStoreMemory(ctx, req.Params)
+	case "create_typed_memory":
+		result, err = s.handleCreateTypedMemory(ctx, req.Params)
+	case "list_memory_templates":
+		result, err = s.handleListMemoryTemplates(ctx, req.Params)
 	case "recall_memory":
 		result, err = s.handleRecallMemory(ctx, req.Params)
+	case "get_memory":
+		result, err = s.handleGetMemory(ctx, req.Params)
 	case "find_related":
 		result, err = s.handleFindRelated(ctx, req.Params)
 	case "retry_enrichment":
@@ -173,65 +456,172 @@ func (s *Server) HandleRequest(ctx context.Context, requestJSON []byte) ([]byte,
 		result, err = s.handleExplainReasoning(ctx, req.Params)
 	case "update_memory_state":
 		result, err = s.handleUpdateMemoryState(ctx, req.Params)
+	case "batch_update_state":
+		result, err = s.handleBatchUpdateState(ctx, req.Params)
+	case "get_state_history":
+		result, err = s.handleGetStateHistory(ctx, req.Params)
 	case "forget_memory":
 		result, err = s.handleForgetMemory(ctx, req.Params)
 	case "evolve_memory":
 		result, err = s.handleEvolveMemory(ctx, req.Params)
+	case "supersede_memory":
+		result, err = s.handleSupersedeMemory(ctx, req.Params)
 	case "consolidate_memories":
 		result, err = s.handleConsolidateMemories(ctx, req.Params)
 	case "detect_contradictions":
 		result, err = s.handleDetectContradictions(ctx, req.Params)
+	case "resolve_contradiction":
+		result, err = s.handleResolveContradiction(ctx, req.Params)
+	case "recompute_decay":
+		result, err = s.handleRecomputeDecay(ctx, req.Params)
+	case "get_decay_report":
+		result, err = s.handleGetDecayReport(ctx, req.Params)
+	case "get_connection_status":
+		result, err = s.handleGetConnectionStatus(ctx, req.Params)
+	case "get_engine_status":
+		result, err = s.handleGetEngineStatus(ctx, req.Params)
 	case "update_memory":
 		result, err = s.handleUpdateMemory(ctx, req.Params)
 	case "get_session_context":
 		result, err = s.handleGetSessionContext(ctx, req.Params)
+	case "get_current_session":
+		result, err = s.handleGetCurrentSession(ctx, req.Params)
+	case "begin_session":
+		result, err = s.handleBeginSession(ctx, req.Params)
+	case "end_session":
+		result, err = s.handleEndSession(ctx, req.Params)
 	case "traverse_memory_graph":
 		result, err = s.handleTraverseMemoryGraph(ctx, req.Params)
+	case "get_graph_export":
+		result, err = s.handleGetGraphExport(ctx, req.Params)
+	case "get_relationships":
+		result, err = s.handleGetRelationships(ctx, req.Params)
+	case "get_memory_neighbors":
+		result, err = s.handleGetMemoryNeighbors(ctx, req.Params)
+	case "get_memory_context":
+		result, err = s.handleGetMemoryContext(ctx, req.Params)
+	case "get_audit_log":
+		result, err = s.handleGetAuditLog(ctx, req.Params)
 	case "restore_memory":
 		result, err = s.handleRestoreMemory(ctx, req.Params)
 	case "list_deleted_memories":
 		result, err = s.handleListDeletedMemories(ctx, req.Params)
 	case "get_evolution_chain":
 		result, err = s.handleGetEvolutionChain(ctx, req.Params)
+	case "summarize_memory":
+		result, err = s.handleSummarizeMemory(ctx, req.Params)
+	case "get_memory_snapshot":
+		result, err = s.handleGetMemorySnapshot(ctx, req.Params)
+	case "restore_memory_snapshot":
+		result, err = s.handleRestoreMemorySnapshot(ctx, req.Params)
 	case "create_project":
 		result, err = s.handleCreateProject(ctx, req.Params)
 	case "add_project_item":
 		result, err = s.handleAddProjectItem(ctx, req.Params)
+	case "move_project_item":
+		result, err = s.handleMoveProjectItem(ctx, req.Params)
+	case "reorder_project_items":
+		result, err = s.handleReorderProjectItems(ctx, req.Params)
 	case "get_project_tree":
 		result, err = s.handleGetProjectTree(ctx, req.Params)
 	case "list_projects":
 		result, err = s.handleListProjects(ctx, req.Params)
+	case "list_entities":
+		result, err = s.handleListEntities(ctx, req.Params)
+	case "merge_entities":
+		result, err = s.handleMergeEntities(ctx, req.Params)
+	case "purge_connection":
+		result, err = s.handlePurgeConnection(ctx, req.Params)
+	case "recall_about_entity":
+		result, err = s.handleRecallAboutEntity(ctx, req.Params)
+	case "get_embedding":
+		result, err = s.handleGetEmbedding(ctx, req.Params)
+	case "memory_similarity":
+		result, err = s.handleMemorySimilarity(ctx, req.Params)
+	case "cluster_memories":
+		result, err = s.handleClusterMemories(ctx, req.Params)
+	case "generate_digest":
+		result, err = s.handleGenerateDigest(ctx, req.Params)
 	default:
 		return s.errorResponse(req.ID, ErrCodeMethodNotFound, fmt.Sprintf("Method not found: %s", req.Method), nil)
 	}
 
 	if err != nil {
-		return s.errorResponse(req.ID, ErrCodeServerError, err.Error(), nil)
+		err = s.checkTimeout(ctx, err)
+		return s.errorResponse(req.ID, errorCode(err), err.Error(), nil)
 	}
+	s.recordAudit(ctx, req.Method, req.Params, result)
 
 	return s.successResponse(req.ID, result)
 }
 
+// checkTimeout replaces err with ErrRequestTimeout when the request deadline
+// set by WithRequestTimeout has passed, so clients see a clean timeout rather
+// than whichever low-level error the interrupted call happened to return.
+func (s *Server) checkTimeout(ctx context.Context, err error) error {
+	if err == nil || s.requestTimeout <= 0 || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("request exceeded %v: %w", s.requestTimeout, ErrRequestTimeout)
+}
+
 // StoreMemory stores a new memory and returns immediately with pending status.
 // This is the v2.0 behavior where enrichment happens asynchronously.
+//
+// When args.IdempotencyKey is set, a repeat call with the same key for the
+// same connection within the idempotency TTL returns the first call's result
+// (with Replayed set) and stores nothing, even if the content differs.
 func (s *Server) StoreMemory(ctx context.Context, args StoreMemoryArgs) (*StoreMemoryResult, error) {
 	// Validate input
+	if args.Content != "" {
+		content, err := s.normalizeContent("content", args.Content)
+		if err != nil {
+			return nil, err
+		}
+		args.Content = content
+	}
 	if err := s.validateStoreMemoryArgs(args); err != nil {
 		return nil, err
 	}
+	if args.IdempotencyKey == "" {
+		return s.storeMemory(ctx, args)
+	}
+
+	key := storeConnection(args, s.defaultConnection) + "\x00" + args.IdempotencyKey
+	previous, err := s.idempotency.acquire(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		replay := *previous
+		replay.Replayed = true
+		return &replay, nil
+	}
+	result, err := s.storeMemory(ctx, args)
+	s.idempotency.release(key, result)
+	return result, err
+}
+
+// storeMemory does the work of StoreMemory for validated args.
+func (s *Server) storeMemory(ctx context.Context, args StoreMemoryArgs) (*StoreMemoryResult, error) {
+	var expiresAt *time.Time
+	if args.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, args.ExpiresAt)
+		if err != nil {
+			return nil, invalidParamsf("expires_at: invalid RFC-3339 timestamp %q: %v", args.ExpiresAt, err)
+		}
+		if !t.After(time.Now()) {
+			return nil, invalidParamsf("expires_at (%s) must be in the future", t.Format(time.RFC3339))
+		}
+		expiresAt = &t
+	}
 
 	// Resolve the effective connection name. Priority:
 	//   1. args.ConnectionID (explicit per-call)
 	//   2. args.Domain (legacy field)
 	//   3. s.defaultConnection (server-level default, set via env var or per-project CLAUDE.md)
 	//   4. "" → "general" (original default)
-	effectiveConn := args.ConnectionID
-	if effectiveConn == "" {
-		effectiveConn = args.Domain
-	}
-	if effectiveConn == "" {
-		effectiveConn = s.defaultConnection
-	}
+	effectiveConn := storeConnection(args, s.defaultConnection)
 
 	// The domain segment of the memory ID matches the connection name so that
 	// resolveStoreForID can route ID-based lookups back to the right store.
@@ -239,15 +629,30 @@ func (s *Server) StoreMemory(ctx context.Context, args StoreMemoryArgs) (*StoreM
 
 	// Resolve which store to write to.
 	store := s.memoryStore
+	routeConn := ""
 	if effectiveConn != "" && s.connectionManager != nil {
 		if connStore, err := s.connectionManager.GetStore(effectiveConn); err == nil {
 			store = connStore
+			routeConn = effectiveConn
 		} else if args.ConnectionID != "" {
 			// Only hard-fail for an explicitly requested connection that doesn't exist.
-			return nil, fmt.Errorf("unknown connection %q: %w", args.ConnectionID, err)
+			return nil, invalidParamsf("unknown connection %q: %v", args.ConnectionID, err)
 		}
 	}
 
+	// Over-long content is stored in full but enriched only up to the limit
+	// (validateStoreMemoryArgs already rejected it unless truncate was set).
+	enrichContent := args.Content
+	metadata := args.Metadata
+	truncated := s.contentTooLong(args.Content)
+	if truncated {
+		enrichContent = types.TruncateRunes(args.Content, s.maxContentLength)
+		metadata = withMetadata(metadata, types.MetadataEnrichedLength, s.maxContentLength)
+	}
+	if args.IdempotencyKey != "" {
+		metadata = withMetadata(metadata, idempotencyKeyMetadata, args.IdempotencyKey)
+	}
+
 	// Generate memory ID
 	memID := s.generateMemoryID(domain, args.Content)
 
@@ -258,7 +663,8 @@ func (s *Server) StoreMemory(ctx context.Context, args StoreMemoryArgs) (*StoreM
 		Source:             args.Source,
 		Domain:             domain,
 		Tags:               args.Tags,
-		Metadata:           args.Metadata,
+		Metadata:           metadata,
+		MemoryType:         args.memoryType,
 		Status:             types.StatusPending,
 		EntityStatus:       types.EnrichmentPending,
 		RelationshipStatus: types.EnrichmentPending,
@@ -266,50 +672,109 @@ func (s *Server) StoreMemory(ctx context.Context, args StoreMemoryArgs) (*StoreM
 		Timestamp:          time.Now(),
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
+		ExpiresAt:          expiresAt,
+	}
+
+	// A connection with enrichment disabled stores memories as enriched with
+	// every step skipped, so neither store_memory nor the engine's pending
+	// recovery queues jobs for them.
+	enrich := s.connectionManager == nil || s.connectionManager.EnrichmentEnabled(effectiveConn)
+	if !enrich {
+		skipEnrichment(memory)
 	}
 
-	// Set created_by: use explicit arg if provided, otherwise auto-detect
+	// Set created_by: use explicit arg if provided, otherwise auto-detect.
+	// An explicit created_by comes from the calling client, so it is treated
+	// as agent-authored unless author_type says otherwise.
 	if args.CreatedBy != "" {
 		memory.CreatedBy = args.CreatedBy
+		memory.AuthorType = string(attribution.AuthorAgent)
 	} else {
-		memory.CreatedBy = attribution.DetectAgent()
+		name, authorType := attribution.DetectAuthor()
+		memory.CreatedBy = name
+		memory.AuthorType = string(authorType)
+	}
+	if args.AuthorType != "" {
+		memory.AuthorType = args.AuthorType
 	}
 
 	// Set session_id: use explicit arg override if provided, otherwise use server session ID
 	if args.SessionID != "" {
 		memory.SessionID = args.SessionID
 	} else {
-		memory.SessionID = s.sessionID
+		memory.SessionID = s.session.forWrite()
 	}
 
 
 	// Detect duplicate: same content produces the same deterministic ID via
 	// generateMemoryID. If the record already exists, Get() will succeed
 	// before Store() runs. We check before storing to distinguish new vs existing.
-	wasDuplicate := false
-	if _, err := store.Get(ctx, memID); err == nil {
-		wasDuplicate = true
+	existing, err := store.Get(ctx, memID)
+	wasDuplicate := err == nil
+	// Different content under the same ID is a hash prefix collision, not a
+	// duplicate: keep both memories by moving this one to a suffixed ID.
+	if wasDuplicate && s.idCollisionCheck && s.contentCollides(existing.Content, memory.Content) {
+		if memID, existing, err = s.resolveIDCollision(ctx, store, memID, memory.Content); err != nil {
+			return nil, err
+		}
+		memory.ID = memID
+		wasDuplicate = existing != nil
+	}
+	// With dedup normalization the existing memory may differ from this
+	// submission (e.g. only in case); it keeps the content it was stored with.
+	sameContent := wasDuplicate && existing.Content == memory.Content
+
+	if err := s.recordRoute(ctx, memID, routeConn); err != nil {
+		return nil, err
 	}
 
 	// Store memory (upsert — safe to call even for duplicates)
-	if err := store.Store(ctx, memory); err != nil {
-		return nil, fmt.Errorf("failed to store memory: %w", err)
+	if !wasDuplicate || sameContent {
+		if err := store.Store(ctx, memory); err != nil {
+			return nil, fmt.Errorf("failed to store memory: %w", err)
+		}
 	}
 
 	result := &StoreMemoryResult{
-		ID:     memory.ID,
-		Status: types.StatusPending,
+		ID:        memory.ID,
+		Status:    memory.Status,
+		Truncated: truncated,
 	}
 
 	if wasDuplicate {
 		result.Duplicate = true
 		result.ExistingID = memory.ID
 		result.Message = "Memory already exists with identical content."
+		if !sameContent {
+			result.Message = "Memory already exists with equivalent content; the original content was kept."
+		}
+	} else if !enrich {
+		result.Enrichment = EnrichmentDisabled
+		result.Message = "Memory stored successfully. Enrichment is disabled for this connection."
+		if args.WaitForEnrichment {
+			result.Memory = memory
+		}
 	} else {
 		result.Message = "Memory stored successfully. Enrichment will happen asynchronously."
 		// Queue enrichment immediately if engine is available (only for new memories).
+		var enriched <-chan types.MemoryStatus
 		if s.engine != nil {
-			_ = s.engine.QueueEnrichmentForMemory(memID, args.Content) // queue full is OK — recovery on next restart will pick it up
+			if args.WaitForEnrichment {
+				// Register before queueing so a fast worker cannot finish first.
+				done, cancel := s.engine.WaitForEnrichment(memID)
+				defer cancel()
+				enriched = done
+			}
+			result.Enrichment = EnrichmentQueued
+			if !s.engine.QueueEnrichmentForMemory(memID, enrichContent) {
+				// The memory stays pending; the engine's pending rescan queues it later.
+				result.Enrichment = EnrichmentDeferred
+				result.Message = "Memory stored successfully. The enrichment queue is full, so enrichment is deferred until the next pending rescan."
+				enriched = nil
+			}
+		}
+		if truncated {
+			result.Message += fmt.Sprintf(" Content exceeds %d characters; only the first %d are enriched and embedded.", s.maxContentLength, s.maxContentLength)
 		}
 
 		// Onboarding hint: if this is the very first memory, guide the user.
@@ -317,21 +782,90 @@ func (s *Server) StoreMemory(ctx context.Context, args StoreMemoryArgs) (*StoreM
 		if countErr == nil && countResult.Total == 1 {
 			result.Message += " First memory stored! Tip: store a few more memories then use find_related to discover connections."
 		}
+
+		if args.WaitForEnrichment {
+			s.awaitEnrichment(ctx, store, result, enriched, time.Duration(args.TimeoutSeconds)*time.Second)
+		}
 	}
 
 	return result, nil
 }
 
+// skipEnrichment marks m as enriched with every enrichment step skipped, for
+// connections that have enrichment disabled.
+func skipEnrichment(m *types.Memory) {
+	now := time.Now()
+	m.Status = types.StatusEnriched
+	m.EntityStatus = types.EnrichmentSkipped
+	m.RelationshipStatus = types.EnrichmentSkipped
+	m.EmbeddingStatus = types.EnrichmentSkipped
+	m.ClassificationStatus = types.EnrichmentSkipped
+	m.SummarizationStatus = types.EnrichmentSkipped
+	m.EnrichedAt = &now
+}
+
+// GetMemory fetches exactly one memory by ID, from connection_id when given
+// and otherwise from the connection inferred from the ID. Unlike
+// recall_memory it has no search or list mode, so a wrong argument cannot
+// silently turn a lookup into a search. A missing memory is reported with
+// Found false rather than an error.
+func (s *Server) GetMemory(ctx context.Context, args GetMemoryArgs) (*GetMemoryResult, error) {
+	if strings.TrimSpace(args.ID) == "" {
+		return nil, invalidParamsf("id is required")
+	}
+
+	store, err := s.readStoreForID(ctx, args.ID, args.ConnectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	memory, err := store.Get(ctx, args.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return &GetMemoryResult{Found: false}, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve memory: %w", err)
+	}
+	// Access tracking is best-effort, as in recall_memory.
+	_ = store.IncrementAccessCount(ctx, memory.ID)
+
+	return &GetMemoryResult{
+		Found:             true,
+		Memory:            memory,
+		EnrichmentSummary: memory.EnrichmentSummary(),
+	}, nil
+}
+
+// readStoreForID returns the store to read memory id from: the connection
+// named by connectionID if set, otherwise the one inferred from the ID.
+func (s *Server) readStoreForID(ctx context.Context, id, connectionID string) (storage.MemoryStore, error) {
+	if connectionID == "" {
+		return s.resolveStoreForID(ctx, id), nil
+	}
+	if s.connectionManager == nil {
+		return nil, invalidParamsf("connection_id %q given but no connections are configured", connectionID)
+	}
+	store, err := s.connectionManager.GetStore(connectionID)
+	if err != nil {
+		return nil, invalidParamsf("unknown connection %q: %v", connectionID, err)
+	}
+	return store, nil
+}
+
 // RecallMemory retrieves memories with three priority modes:
 //  1. ID set → direct lookup by ID
 //  2. Query set → full-text search (delegates to FTS, same engine as find_related)
 //  3. Neither → list/filter mode with optional filter fields
 func (s *Server) RecallMemory(ctx context.Context, args RecallMemoryArgs) (*RecallMemoryResult, error) {
+	if err := validateAuthorType(args.AuthorType); err != nil {
+		return nil, err
+	}
+
 	// ------------------------------------------------------------------
 	// ID-lookup mode: auto-route to the connection inferred from the ID.
 	// ------------------------------------------------------------------
 	if args.ID != "" {
-		store := s.resolveStoreForID(args.ID)
+		store := s.resolveStoreForID(ctx, args.ID)
 		memory, err := store.Get(ctx, args.ID)
 		if err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
@@ -347,8 +881,9 @@ func (s *Server) RecallMemory(ctx context.Context, args RecallMemoryArgs) (*Reca
 		}
 
 		return &RecallMemoryResult{
-			Memory: memory,
-			Found:  true,
+			Memory:            memory,
+			Found:             true,
+			EnrichmentSummary: memory.EnrichmentSummary(),
 		}, nil
 	}
 
@@ -357,24 +892,27 @@ func (s *Server) RecallMemory(ctx context.Context, args RecallMemoryArgs) (*Reca
 	// Passes connection_id through so the right store is searched.
 	// ------------------------------------------------------------------
 	if args.Query != "" {
-		limit := args.Limit
-		if limit <= 0 {
-			limit = 10
-		}
+		limit := s.effectiveLimit(args.Limit)
 		ftsArgs := FindRelatedArgs{
-			Query:        args.Query,
-			Limit:        limit,
-			ConnectionID: args.ConnectionID,
+			Query:          args.Query,
+			Limit:          limit,
+			ConnectionID:   args.ConnectionID,
+			IncludeExpired: args.IncludeExpired,
+			AuthorType:     args.AuthorType,
+			MemoryType:     args.MemoryType,
+			Metadata:       args.Metadata,
 		}
 		ftsResult, err := s.FindRelated(ctx, ftsArgs)
 		if err != nil {
 			return nil, err
 		}
 		return &RecallMemoryResult{
-			Found:    len(ftsResult.Memories) > 0,
-			Memories: ftsResult.Memories,
-			Total:    ftsResult.Total,
-			Page:     1,
+			Found:               len(ftsResult.Memories) > 0,
+			Memories:            ftsResult.Memories,
+			Total:               ftsResult.Total,
+			Page:                1,
+			Limit:               limit,
+			EnrichmentSummaries: enrichmentSummaries(ftsResult.Memories),
 		}, nil
 	}
 
@@ -386,40 +924,43 @@ func (s *Server) RecallMemory(ctx context.Context, args RecallMemoryArgs) (*Reca
 	listStore, _ := s.resolveSearchStore(args.ConnectionID)
 
 	// Parse and validate temporal bounds.
-	var createdAfter, createdBefore time.Time
-
-	if args.CreatedAfter != "" {
-		t, err := time.Parse(time.RFC3339, args.CreatedAfter)
-		if err != nil {
-			return nil, fmt.Errorf("created_after: invalid RFC-3339 timestamp %q: %w", args.CreatedAfter, err)
-		}
-		createdAfter = t
+	createdAfter, createdBefore, err := parseTimeRange(args.CreatedAfter, args.CreatedBefore)
+	if err != nil {
+		return nil, err
 	}
-
-	if args.CreatedBefore != "" {
-		t, err := time.Parse(time.RFC3339, args.CreatedBefore)
-		if err != nil {
-			return nil, fmt.Errorf("created_before: invalid RFC-3339 timestamp %q: %w", args.CreatedBefore, err)
-		}
-		createdBefore = t
+	accessedAfter, accessedBefore, err := parseBounds("accessed", args.AccessedAfter, args.AccessedBefore)
+	if err != nil {
+		return nil, err
 	}
-
-	// Validate that createdAfter < createdBefore when both are set.
-	if !createdAfter.IsZero() && !createdBefore.IsZero() {
-		if !createdAfter.Before(createdBefore) {
-			return nil, fmt.Errorf("created_after (%s) must be before created_before (%s)",
-				createdAfter.Format(time.RFC3339), createdBefore.Format(time.RFC3339))
-		}
+	sortBy, sortOrder, err := parseSort(args.SortBy, args.SortOrder)
+	if err != nil {
+		return nil, err
+	}
+	if args.MaxDecayScore > 0 && args.MinDecayScore > args.MaxDecayScore {
+		return nil, invalidParamsf("min_decay_score (%g) must not exceed max_decay_score (%g)", args.MinDecayScore, args.MaxDecayScore)
+	}
+	metadata, err := parseMetadataFilter(args.Metadata)
+	if err != nil {
+		return nil, err
 	}
 
 	opts := storage.ListOptions{
-		Page:          args.Page,
-		Limit:         args.Limit,
-		State:         args.State,
-		CreatedBy:     args.CreatedBy,
-		CreatedAfter:  createdAfter,
-		CreatedBefore: createdBefore,
-		MinDecayScore: args.MinDecayScore,
+		Page:           args.Page,
+		Limit:          s.effectiveLimit(args.Limit),
+		SortBy:         sortBy,
+		SortOrder:      sortOrder,
+		State:          args.State,
+		CreatedBy:      args.CreatedBy,
+		AuthorType:     args.AuthorType,
+		MemoryType:     args.MemoryType,
+		CreatedAfter:   createdAfter,
+		CreatedBefore:  createdBefore,
+		AccessedAfter:  accessedAfter,
+		AccessedBefore: accessedBefore,
+		MinDecayScore:  args.MinDecayScore,
+		MaxDecayScore:  args.MaxDecayScore,
+		IncludeExpired: args.IncludeExpired,
+		Metadata:       metadata,
 	}
 	opts.Normalize()
 
@@ -429,14 +970,29 @@ func (s *Server) RecallMemory(ctx context.Context, args RecallMemoryArgs) (*Reca
 	}
 
 	return &RecallMemoryResult{
-		Found:    false,
-		Memories: result.Items,
-		Total:    result.Total,
-		Page:     result.Page,
-		HasMore:  result.HasMore,
+		Found:               false,
+		Memories:            result.Items,
+		Total:               result.Total,
+		Page:                result.Page,
+		HasMore:             result.HasMore,
+		Limit:               opts.Limit,
+		EnrichmentSummaries: enrichmentSummaries(result.Items),
 	}, nil
 }
 
+// enrichmentSummaries maps each memory ID to its EnrichmentSummary, or
+// returns nil when memories is empty.
+func enrichmentSummaries(memories []types.Memory) map[string]string {
+	if len(memories) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(memories))
+	for i := range memories {
+		out[memories[i].ID] = memories[i].EnrichmentSummary()
+	}
+	return out
+}
+
 // FindRelated finds memories related to a query.
 // For v2.0, this uses simple text-based filtering with optional temporal bounds.
 // Future versions will use vector search and semantic matching.
@@ -447,37 +1003,16 @@ func (s *Server) FindRelated(ctx context.Context, args FindRelatedArgs) (*FindRe
 	}
 
 	// Parse and validate temporal bounds.
-	var createdAfter, createdBefore time.Time
-
-	if args.CreatedAfter != "" {
-		t, err := time.Parse(time.RFC3339, args.CreatedAfter)
-		if err != nil {
-			return nil, fmt.Errorf("created_after: invalid RFC-3339 timestamp %q: %w", args.CreatedAfter, err)
-		}
-		createdAfter = t
-	}
-
-	if args.CreatedBefore != "" {
-		t, err := time.Parse(time.RFC3339, args.CreatedBefore)
-		if err != nil {
-			return nil, fmt.Errorf("created_before: invalid RFC-3339 timestamp %q: %w", args.CreatedBefore, err)
-		}
-		createdBefore = t
+	createdAfter, createdBefore, err := parseTimeRange(args.CreatedAfter, args.CreatedBefore)
+	if err != nil {
+		return nil, err
 	}
-
-	// Validate that createdAfter < createdBefore when both are set.
-	if !createdAfter.IsZero() && !createdBefore.IsZero() {
-		if !createdAfter.Before(createdBefore) {
-			return nil, fmt.Errorf("created_after (%s) must be before created_before (%s)",
-				createdAfter.Format(time.RFC3339), createdBefore.Format(time.RFC3339))
-		}
+	metadata, err := parseMetadataFilter(args.Metadata)
+	if err != nil {
+		return nil, err
 	}
 
-	// Set default limit
-	limit := args.Limit
-	if limit <= 0 {
-		limit = 10
-	}
+	limit := s.effectiveLimit(args.Limit)
 
 	// Resolve the store and search provider for this call.
 	// When connection_id is set the search is scoped to that connection's data.
@@ -487,10 +1022,19 @@ func (s *Server) FindRelated(ctx context.Context, args FindRelatedArgs) (*FindRe
 	// Prefer hybrid (FTS + vector) search when engine embedding is available.
 	if callSearchProvider != nil {
 		searchOpts := storage.SearchOptions{
-			Query:         args.Query,
-			Limit:         limit,
-			Offset:        0,
-			FuzzyFallback: true,
+			Query:          args.Query,
+			Limit:          limit,
+			Offset:         0,
+			FuzzyFallback:  true,
+			IncludeExpired: args.IncludeExpired,
+			MinSimilarity:  args.MinSimilarity,
+			Tags:           args.Tags,
+			TagsMatchAll:   args.TagsMatch == TagsMatchAll,
+			Metadata:       metadata,
+
+			Fuzzy:           args.Fuzzy,
+			FuzzyThreshold:  s.fuzzyThreshold,
+			FuzzyMaxResults: s.fuzzyMaxResults,
 		}
 
 		var ftsResult *storage.PaginatedResult[types.Memory]
@@ -500,6 +1044,8 @@ func (s *Server) FindRelated(ctx context.Context, args FindRelatedArgs) (*FindRe
 		if s.engine != nil {
 			if vec, embErr := s.engine.Embed(ctx, args.Query); embErr == nil {
 				ftsResult, err = callSearchProvider.HybridSearch(ctx, args.Query, vec, searchOpts)
+			} else if errors.Is(embErr, engine.ErrTimeout) || errors.Is(embErr, storage.ErrDimensionMismatch) {
+				slog.Warn("find_related: falling back to full-text search", "error", embErr)
 			}
 		}
 		// Fall back to FTS-only if hybrid unavailable or failed
@@ -510,7 +1056,17 @@ func (s *Server) FindRelated(ctx context.Context, args FindRelatedArgs) (*FindRe
 			return nil, fmt.Errorf("failed to search memories: %w", err)
 		}
 
+		// Search LLM-suggested synonyms too, appending their hits after the
+		// query's own.
+		var expandedTerms []string
+		if args.ExpandQuery {
+			expandedTerms = s.expandQuery(ctx, args.Query)
+			searchExpansionTerms(ctx, callSearchProvider, ftsResult, expandedTerms, searchOpts)
+		}
+
 		// Apply temporal bounds filter post-search (FTS5 searches content only).
+		// Tags and metadata are rechecked because fuzzy matches, and stores
+		// that do not support those constraints, return memories without them.
 		var filtered []types.Memory
 		for _, mem := range ftsResult.Items {
 			if !createdAfter.IsZero() && !mem.CreatedAt.After(createdAfter) {
@@ -522,6 +1078,18 @@ func (s *Server) FindRelated(ctx context.Context, args FindRelatedArgs) (*FindRe
 			if args.Domain != "" && mem.Domain != args.Domain {
 				continue
 			}
+			if args.AuthorType != "" && mem.AuthorType != args.AuthorType {
+				continue
+			}
+			if args.MemoryType != "" && mem.MemoryType != args.MemoryType {
+				continue
+			}
+			if !hasTags(mem.Tags, args.Tags, args.TagsMatch == TagsMatchAll) {
+				continue
+			}
+			if !storage.MetadataMatches(mem.Metadata, metadata) {
+				continue
+			}
 			filtered = append(filtered, mem)
 		}
 
@@ -533,17 +1101,24 @@ func (s *Server) FindRelated(ctx context.Context, args FindRelatedArgs) (*FindRe
 		}
 
 		return &FindRelatedResult{
-			Memories: filtered,
-			Total:    len(filtered),
+			Memories:      filtered,
+			Total:         len(filtered),
+			TotalMatches:  max(ftsResult.Total, len(ftsResult.Items)),
+			Limit:         limit,
+			ExpandedTerms: expandedTerms,
 		}, nil
 	}
 
 	// Fallback: list-then-filter using strings.Contains (no SearchProvider available).
 	listOpts := storage.ListOptions{
-		Limit:         limit,
-		Page:          1,
-		CreatedAfter:  createdAfter,
-		CreatedBefore: createdBefore,
+		Limit:          limit,
+		Page:           1,
+		CreatedAfter:   createdAfter,
+		CreatedBefore:  createdBefore,
+		IncludeExpired: args.IncludeExpired,
+		AuthorType:     args.AuthorType,
+		MemoryType:     args.MemoryType,
+		Metadata:       metadata,
 	}
 
 	if args.Domain != "" {
@@ -563,7 +1138,8 @@ func (s *Server) FindRelated(ctx context.Context, args FindRelatedArgs) (*FindRe
 
 	for _, mem := range result.Items {
 		content := strings.ToLower(mem.Content)
-		if strings.Contains(content, queryLower) {
+		if strings.Contains(content, queryLower) && hasTags(mem.Tags, args.Tags, args.TagsMatch == TagsMatchAll) &&
+			storage.MetadataMatches(mem.Metadata, metadata) {
 			filtered = append(filtered, mem)
 		}
 	}
@@ -576,9 +1152,13 @@ func (s *Server) FindRelated(ctx context.Context, args FindRelatedArgs) (*FindRe
 		}
 	}
 
+	// Without a search provider only one page is scanned, so the number of
+	// matches beyond it is unknown.
 	return &FindRelatedResult{
-		Memories: filtered,
-		Total:    len(filtered),
+		Memories:     filtered,
+		Total:        len(filtered),
+		TotalMatches: len(filtered),
+		Limit:        limit,
 	}, nil
 }
 
@@ -586,17 +1166,17 @@ func (s *Server) FindRelated(ctx context.Context, args FindRelatedArgs) (*FindRe
 func (s *Server) RetryEnrichment(ctx context.Context, args RetryEnrichmentArgs) (*RetryEnrichmentResult, error) {
 	// Validate input
 	if args.ID == "" {
-		return nil, errors.New("memory ID is required")
+		return nil, invalidParamsf("memory ID is required")
 	}
 
 	// Auto-route to the connection that owns this memory ID.
-	store := s.resolveStoreForID(args.ID)
+	store := s.resolveStoreForID(ctx, args.ID)
 
 	// Retrieve memory
 	memory, err := store.Get(ctx, args.ID)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return nil, fmt.Errorf("memory not found: %s", args.ID)
+			return nil, notFoundf("memory not found: %s", args.ID)
 		}
 		return nil, fmt.Errorf("failed to retrieve memory: %w", err)
 	}
@@ -630,7 +1210,7 @@ func (s *Server) RetryEnrichment(ctx context.Context, args RetryEnrichmentArgs)
 
 	// Queue enrichment immediately if engine is available.
 	if s.engine != nil {
-		s.engine.QueueEnrichmentForMemory(args.ID, memory.Content)
+		s.engine.QueueEnrichmentForMemory(args.ID, memory.EnrichmentContent())
 	}
 
 	return &RetryEnrichmentResult{
@@ -644,17 +1224,17 @@ func (s *Server) RetryEnrichment(ctx context.Context, args RetryEnrichmentArgs)
 // It fetches each memory by ID and identifies which query terms matched.
 func (s *Server) ExplainReasoning(ctx context.Context, args ExplainReasoningArgs) (*ExplainReasoningResult, error) {
 	if args.Query == "" {
-		return nil, errors.New("query is required")
+		return nil, invalidParamsf("query is required")
 	}
 	if len(args.MemoryIDs) == 0 {
-		return nil, errors.New("at least one memory ID is required")
+		return nil, invalidParamsf("at least one memory ID is required")
 	}
 
 	// Fetch each requested memory, auto-routing each ID to its connection.
 	var fetched []*types.Memory
 	var notFound []string
 	for _, id := range args.MemoryIDs {
-		mem, err := s.resolveStoreForID(id).Get(ctx, id)
+		mem, err := s.resolveStoreForID(ctx, id).Get(ctx, id)
 		if err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
 				notFound = append(notFound, id)
@@ -709,29 +1289,39 @@ func (s *Server) ExplainReasoning(ctx context.Context, args ExplainReasoningArgs
 	}, nil
 }
 
+// stateMachineOf returns the lifecycle state machine store validates state
+// changes against: its own when it is a storage.StateMachineProvider, else
+// the built-in one (nil).
+func stateMachineOf(store storage.MemoryStore) *types.StateMachine {
+	if p, ok := store.(storage.StateMachineProvider); ok {
+		return p.StateMachine()
+	}
+	return nil
+}
+
 // UpdateMemoryState updates the lifecycle state of a memory with state transition validation (Opus Issue #6).
 func (s *Server) UpdateMemoryState(ctx context.Context, args UpdateMemoryStateArgs) (*UpdateMemoryStateResult, error) {
 	// Validate input
 	if args.ID == "" {
-		return nil, errors.New("memory ID is required")
+		return nil, invalidParamsf("memory ID is required")
 	}
 	if args.State == "" {
-		return nil, errors.New("state is required")
-	}
-
-	// Validate that the state is a valid lifecycle state
-	if !types.IsValidLifecycleState(args.State) {
-		return nil, fmt.Errorf("invalid state: %s", args.State)
+		return nil, invalidParamsf("state is required")
 	}
 
 	// Auto-route to the connection that owns this memory ID.
-	store := s.resolveStoreForID(args.ID)
+	store := s.resolveStoreForID(ctx, args.ID)
+
+	// Validate that the state is a lifecycle state of the store's machine
+	if !stateMachineOf(store).IsValidState(args.State) {
+		return nil, invalidParamsf("invalid state: %s", args.State)
+	}
 
 	// Get the current memory to capture previous state
 	memory, err := store.Get(ctx, args.ID)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return nil, fmt.Errorf("memory not found: %s", args.ID)
+			return nil, notFoundf("memory not found: %s", args.ID)
 		}
 		return nil, fmt.Errorf("failed to retrieve memory: %w", err)
 	}
@@ -754,16 +1344,16 @@ func (s *Server) UpdateMemoryState(ctx context.Context, args UpdateMemoryStateAr
 // ForgetMemory soft-deletes or permanently purges a memory.
 func (s *Server) ForgetMemory(ctx context.Context, args ForgetMemoryArgs) (*ForgetMemoryResult, error) {
 	if args.ID == "" {
-		return nil, errors.New("id is required")
+		return nil, invalidParamsf("id is required")
 	}
 
-	store := s.resolveStoreForID(args.ID)
+	store := s.resolveStoreForID(ctx, args.ID)
 
 	if args.HardDelete {
 		// Permanent removal
 		if err := store.Purge(ctx, args.ID); err != nil {
 			if errors.Is(err, storage.ErrNotFound) {
-				return nil, fmt.Errorf("memory not found: %s", args.ID)
+				return nil, notFoundf("memory not found: %s", args.ID)
 			}
 			return nil, fmt.Errorf("failed to purge memory: %w", err)
 		}
@@ -773,7 +1363,7 @@ func (s *Server) ForgetMemory(ctx context.Context, args ForgetMemoryArgs) (*Forg
 	// Soft delete
 	if err := store.Delete(ctx, args.ID); err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return nil, fmt.Errorf("memory not found: %s", args.ID)
+			return nil, notFoundf("memory not found: %s", args.ID)
 		}
 		return nil, fmt.Errorf("failed to forget memory: %w", err)
 	}
@@ -783,23 +1373,29 @@ func (s *Server) ForgetMemory(ctx context.Context, args ForgetMemoryArgs) (*Forg
 // EvolveMemory creates a new version of a memory that supersedes the old one.
 func (s *Server) EvolveMemory(ctx context.Context, args EvolveMemoryArgs) (*EvolveMemoryResult, error) {
 	if args.ID == "" || args.NewContent == "" {
-		return nil, errors.New("id and new_content are required")
+		return nil, invalidParamsf("id and new_content are required")
 	}
+	newContent, err := s.normalizeContent("new_content", args.NewContent)
+	if err != nil {
+		return nil, err
+	}
+	args.NewContent = newContent
 
 	// Auto-route to the connection that owns this memory ID.
-	store := s.resolveStoreForID(args.ID)
+	store := s.resolveStoreForID(ctx, args.ID)
 
 	// Get the old memory to verify it exists and copy its metadata
 	old, err := store.Get(ctx, args.ID)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return nil, fmt.Errorf("memory not found: %s", args.ID)
+			return nil, notFoundf("memory not found: %s", args.ID)
 		}
 		return nil, fmt.Errorf("failed to retrieve memory to evolve: %w", err)
 	}
 
 	// Create the new memory that supersedes the old one
-	newID := "mem:" + uuid.New().String()
+	createdBy, authorType := attribution.DetectAuthor()
+	newID := s.idPrefix(old.Domain) + ":" + uuid.New().String()
 	newMem := &types.Memory{
 		ID:                  newID,
 		Content:             args.NewContent,
@@ -808,8 +1404,9 @@ func (s *Server) EvolveMemory(ctx context.Context, args EvolveMemoryArgs) (*Evol
 		Tags:                old.Tags,
 		Metadata:            old.Metadata,
 		SupersedesID:        old.ID,
-		CreatedBy:           attribution.DetectAgent(),
-		SessionID:           s.sessionID,
+		CreatedBy:           createdBy,
+		AuthorType:          string(authorType),
+		SessionID:           s.session.forWrite(),
 		CreatedAt:           time.Now(),
 		UpdatedAt:           time.Now(),
 		Timestamp:           time.Now(),
@@ -821,6 +1418,9 @@ func (s *Server) EvolveMemory(ctx context.Context, args EvolveMemoryArgs) (*Evol
 		EmbeddingStatus:     types.EnrichmentPending,
 	}
 
+	if err := s.recordRoute(ctx, newID, s.connectionForID(ctx, old.ID)); err != nil {
+		return nil, err
+	}
 	if err := store.Store(ctx, newMem); err != nil {
 		return nil, fmt.Errorf("failed to store evolved memory: %w", err)
 	}
@@ -845,7 +1445,7 @@ func (s *Server) EvolveMemory(ctx context.Context, args EvolveMemoryArgs) (*Evol
 // The originals are soft-deleted. The new memory supersedes them in a many-to-one pattern.
 func (s *Server) ConsolidateMemories(ctx context.Context, args ConsolidateMemoriesArgs) (*ConsolidateMemoriesResult, error) {
 	if len(args.IDs) == 0 && args.Query == "" {
-		return nil, fmt.Errorf("either ids or query is required")
+		return nil, invalidParamsf("either ids or query is required")
 	}
 
 	// Resolve the store and search provider
@@ -883,7 +1483,7 @@ func (s *Server) ConsolidateMemories(ctx context.Context, args ConsolidateMemori
 	}
 
 	if len(ids) < 2 {
-		return nil, fmt.Errorf("at least 2 memories are required for consolidation, got %d", len(ids))
+		return nil, invalidParamsf("at least 2 memories are required for consolidation, got %d", len(ids))
 	}
 
 	// Fetch all memories
@@ -899,7 +1499,7 @@ func (s *Server) ConsolidateMemories(ctx context.Context, args ConsolidateMemori
 	// Build consolidated content.
 	// If the LLM engine is available, use it to synthesise a single coherent
 	// paragraph. Otherwise fall back to numbered concatenation.
-	var consolidatedContent string
+	var consolidatedContent, fallback string
 	if s.engine != nil {
 		var sourceParts []string
 		for i, m := range memories {
@@ -919,6 +1519,9 @@ Write a single concise paragraph that synthesises all of the above into one cohe
 
 		if result, err := s.engine.Summarize(ctx, prompt); err == nil && result != "" {
 			consolidatedContent = result
+		} else if errors.Is(err, engine.ErrTimeout) {
+			slog.Warn("consolidate_memories: falling back to concatenation", "error", err)
+			fallback = " Summarization timed out, so the memories were concatenated."
 		}
 	}
 
@@ -962,12 +1565,16 @@ Write a single concise paragraph that synthesises all of the above into one cohe
 		SummarizationStatus:  types.EnrichmentPending,
 		EmbeddingStatus:      types.EnrichmentPending,
 		CreatedBy:            attribution.DetectAgent(),
-		SessionID:            s.sessionID,
+		AuthorType:           string(attribution.AuthorSystem),
+		SessionID:            s.session.forWrite(),
 		Timestamp:            time.Now(),
 		CreatedAt:            time.Now(),
 		UpdatedAt:            time.Now(),
 	}
 
+	if err := s.recordRoute(ctx, newID, s.searchConnectionName(args.ConnectionID)); err != nil {
+		return nil, err
+	}
 	if err := store.Store(ctx, consolidated); err != nil {
 		return nil, fmt.Errorf("failed to store consolidated memory: %w", err)
 	}
@@ -976,7 +1583,7 @@ Write a single concise paragraph that synthesises all of the above into one cohe
 	for _, id := range ids {
 		if err := store.Delete(ctx, id); err != nil {
 			// Non-fatal: log and continue
-			log.Printf("consolidate_memories: failed to soft-delete %s: %v", id, err)
+			slog.Warn("consolidate_memories: failed to soft-delete source memory", "memory_id", id, "error", err)
 		}
 	}
 
@@ -989,26 +1596,38 @@ Write a single concise paragraph that synthesises all of the above into one cohe
 		NewID:           newID,
 		ConsolidatedIDs: ids,
 		Content:         consolidatedContent,
-		Message:         fmt.Sprintf("Consolidated %d memories into %s. Originals soft-deleted.", len(ids), newID),
+		Message:         fmt.Sprintf("Consolidated %d memories into %s. Originals soft-deleted.%s", len(ids), newID, fallback),
 	}, nil
 }
 
 // UpdateMemory updates the content, tags, or metadata of an existing memory.
 func (s *Server) UpdateMemory(ctx context.Context, args UpdateMemoryArgs) (*UpdateMemoryResult, error) {
 	if args.ID == "" {
-		return nil, errors.New("id is required")
+		return nil, invalidParamsf("id is required")
 	}
 	if args.Content == "" && args.Tags == nil && args.Metadata == nil {
-		return nil, errors.New("at least one of content, tags, or metadata must be provided")
+		return nil, invalidParamsf("at least one of content, tags, or metadata must be provided")
+	}
+	switch args.TagsMode {
+	case "", TagsModeReplace, TagsModeAppend, TagsModeRemove:
+	default:
+		return nil, invalidParamsf("invalid tags_mode %q: must be replace, append, or remove", args.TagsMode)
+	}
+	if args.Content != "" {
+		content, err := s.normalizeContent("content", args.Content)
+		if err != nil {
+			return nil, err
+		}
+		args.Content = content
 	}
 
 	// Auto-route to the connection that owns this memory ID.
-	store := s.resolveStoreForID(args.ID)
+	store := s.resolveStoreForID(ctx, args.ID)
 
 	memory, err := store.Get(ctx, args.ID)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return nil, fmt.Errorf("memory not found: %s", args.ID)
+			return nil, notFoundf("memory not found: %s", args.ID)
 		}
 		return nil, fmt.Errorf("failed to retrieve memory: %w", err)
 	}
@@ -1017,10 +1636,14 @@ func (s *Server) UpdateMemory(ctx context.Context, args UpdateMemoryArgs) (*Upda
 		memory.Content = args.Content
 	}
 	if args.Tags != nil {
-		memory.Tags = args.Tags
+		memory.Tags = applyTags(memory.Tags, args.Tags, args.TagsMode)
 	}
 	if args.Metadata != nil {
-		memory.Metadata = args.Metadata
+		if args.MetadataMerge {
+			memory.Metadata = mergeMetadata(memory.Metadata, args.Metadata)
+		} else {
+			memory.Metadata = args.Metadata
+		}
 	}
 
 	if err := store.Update(ctx, memory); err != nil {
@@ -1034,32 +1657,114 @@ func (s *Server) UpdateMemory(ctx context.Context, args UpdateMemoryArgs) (*Upda
 	}, nil
 }
 
-// GetSessionContext returns recent memories from the current or specified session.
-// It is useful for answering "where did I leave off?" queries.
-func (s *Server) GetSessionContext(ctx context.Context, args GetSessionContextArgs) (*GetSessionContextResult, error) {
-	sessionID := args.SessionID
-	if sessionID == "" {
-		sessionID = s.sessionID
+// applyTags returns existing updated with tags according to mode.
+func applyTags(existing, tags []string, mode string) []string {
+	switch mode {
+	case TagsModeAppend:
+		result := append([]string{}, existing...)
+		for _, tag := range tags {
+			if !slices.Contains(result, tag) {
+				result = append(result, tag)
+			}
+		}
+		return result
+	case TagsModeRemove:
+		result := make([]string, 0, len(existing))
+		for _, tag := range existing {
+			if !slices.Contains(tags, tag) {
+				result = append(result, tag)
+			}
+		}
+		return result
+	default:
+		return tags
 	}
+}
 
-	limit := args.Limit
-	if limit <= 0 {
-		limit = 20
+// parseMetadataFilter validates a recall_memory or find_related metadata
+// filter, returning it with numbers normalized to float64.
+func parseMetadataFilter(filter map[string]interface{}) (map[string]interface{}, error) {
+	metadata, err := storage.NormalizeMetadataFilter(filter)
+	if err != nil {
+		return nil, invalidParamsf("invalid metadata filter: %s", strings.TrimPrefix(err.Error(), storage.ErrInvalidInput.Error()+": "))
 	}
+	return metadata, nil
+}
 
-	listStore, _ := s.resolveSearchStore(args.ConnectionID)
+// hasTags reports whether memTags holds any of tags, or all of them when
+// matchAll is set. An empty tags matches every memory.
+func hasTags(memTags, tags []string, matchAll bool) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		found := slices.Contains(memTags, tag)
+		if found && !matchAll {
+			return true
+		}
+		if !found && matchAll {
+			return false
+		}
+	}
+	return matchAll
+}
 
-	opts := storage.ListOptions{
-		Limit:     limit,
-		SessionID: sessionID,
-		SortBy:    "created_at",
-		SortOrder: "desc",
+// mergeMetadata merges updates into existing. A nil value deletes the key.
+func mergeMetadata(existing, updates map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(existing)+len(updates))
+	for k, v := range existing {
+		result[k] = v
+	}
+	for k, v := range updates {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = v
 	}
+	return result
+}
 
-	// Apply time window filter if specified.
+// GetSessionContext returns recent memories from the current or specified session.
+// It is useful for answering "where did I leave off?" queries.
+//
+// When session_id is omitted but a time range is given (time_window_hours,
+// created_after or created_before), the query spans every session in the
+// connection instead, and the result groups the memories by session.
+func (s *Server) GetSessionContext(ctx context.Context, args GetSessionContextArgs) (*GetSessionContextResult, error) {
+	createdAfter, createdBefore, err := parseTimeRange(args.CreatedAfter, args.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+	// Apply time window filter if specified. It tightens created_after when
+	// both are given.
 	if args.TimeWindowH > 0 {
 		after := time.Now().Add(-time.Duration(args.TimeWindowH) * time.Hour)
-		opts.CreatedAfter = after
+		if after.After(createdAfter) {
+			createdAfter = after
+		}
+	}
+	crossSession := args.SessionID == "" && (!createdAfter.IsZero() || !createdBefore.IsZero())
+
+	sessionID := args.SessionID
+	if sessionID == "" && !crossSession {
+		sessionID = s.session.current()
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	listStore, _ := s.resolveSearchStore(args.ConnectionID)
+
+	opts := storage.ListOptions{
+		Limit:         limit,
+		SessionID:     sessionID,
+		SortBy:        "created_at",
+		SortOrder:     "desc",
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
 	}
 
 	opts.Normalize()
@@ -1069,9 +1774,164 @@ func (s *Server) GetSessionContext(ctx context.Context, args GetSessionContextAr
 		return nil, fmt.Errorf("get session context: %w", err)
 	}
 
-	// Build topic summary by domain.
+	sessionResult := &GetSessionContextResult{
+		SessionID:   sessionID,
+		Label:       s.sessionLabel(ctx, sessionID),
+		MemoryCount: len(result.Items),
+		Memories:    result.Items,
+	}
+	if crossSession {
+		sessionResult.Sessions = groupBySession(result.Items)
+		for i := range sessionResult.Sessions {
+			sessionResult.Sessions[i].Label = s.sessionLabel(ctx, sessionResult.Sessions[i].SessionID)
+		}
+		sessionResult.Topics, sessionResult.Summary = summarizeTopics(result.Items,
+			fmt.Sprintf("across %d sessions", len(sessionResult.Sessions)))
+	} else {
+		sessionResult.Topics, sessionResult.Summary = summarizeTopics(result.Items, "in this session")
+	}
+
+	// Onboarding hint: if the store is completely empty, guide the user.
+	totalResult, totalErr := listStore.List(ctx, storage.ListOptions{Limit: 1})
+	if totalErr == nil && totalResult.Total == 0 {
+		sessionResult.OnboardingHint = "Your memory store is empty. Start by storing memories with store_memory. Tip: describe decisions, people, or projects you want to remember."
+	}
+
+	return sessionResult, nil
+}
+
+// GetCurrentSession returns the session ID that memories stored now are
+// tagged with, so clients can correlate their work with get_session_context.
+func (s *Server) GetCurrentSession(ctx context.Context) (*GetCurrentSessionResult, error) {
+	return s.session.snapshot(), nil
+}
+
+// BeginSession starts a new session, optionally labeled, that memories
+// stored from now on are tagged with. Unlike automatic sessions it is not
+// rotated by the idle timeout or TTL; it lasts until EndSession or the next
+// BeginSession, which ends it. Sessions are recorded in the default store
+// when it supports storage.SessionStore, so labels survive restarts.
+func (s *Server) BeginSession(ctx context.Context, args BeginSessionArgs) (*SessionInfo, error) {
+	label := strings.TrimSpace(args.Label)
+	current, ended := s.session.begin(label)
+	if ended != nil {
+		if err := s.saveSession(ctx, ended); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.saveSession(ctx, current); err != nil {
+		return nil, err
+	}
+	return sessionInfo(current), nil
+}
+
+// EndSession ends the session started by BeginSession, recording when it
+// ended. Memories stored afterwards fall back to automatic sessions.
+func (s *Server) EndSession(ctx context.Context) (*SessionInfo, error) {
+	ended, ok := s.session.end()
+	if !ok {
+		return nil, invalidParamsf("no session is open; start one with begin_session")
+	}
+	if err := s.saveSession(ctx, ended); err != nil {
+		return nil, err
+	}
+	return sessionInfo(ended), nil
+}
+
+// saveSession records session in the default store. Stores without
+// storage.SessionStore keep sessions in memory only.
+func (s *Server) saveSession(ctx context.Context, session *storage.Session) error {
+	sessions, ok := s.memoryStore.(storage.SessionStore)
+	if !ok {
+		return nil
+	}
+	if err := sessions.SaveSession(ctx, session); err != nil {
+		return fmt.Errorf("failed to record session: %w", err)
+	}
+	return nil
+}
+
+// sessionLabel returns the label given to begin_session for the session
+// with id, or "" for automatic sessions and unknown IDs.
+func (s *Server) sessionLabel(ctx context.Context, id string) string {
+	if id == "" {
+		return ""
+	}
+	if label, ok := s.session.labelOf(id); ok {
+		return label
+	}
+	sessions, ok := s.memoryStore.(storage.SessionStore)
+	if !ok {
+		return ""
+	}
+	session, err := sessions.GetSession(ctx, id)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			slog.Warn("failed to look up session label", "session_id", id, "error", err)
+		}
+		return ""
+	}
+	return session.Label
+}
+
+// sessionInfo converts a stored session to its tool result.
+func sessionInfo(session *storage.Session) *SessionInfo {
+	info := &SessionInfo{
+		SessionID: session.ID,
+		Label:     session.Label,
+		StartedAt: session.StartedAt.UTC().Format(time.RFC3339),
+	}
+	if session.EndedAt != nil {
+		info.EndedAt = session.EndedAt.UTC().Format(time.RFC3339)
+	}
+	return info
+}
+
+// groupBySession splits memories (newest first) into per-session summaries,
+// ordered by each session's most recent memory.
+func groupBySession(memories []types.Memory) []SessionSummary {
+	bySession := make(map[string][]types.Memory)
+	var order []string
+	for _, m := range memories {
+		if _, seen := bySession[m.SessionID]; !seen {
+			order = append(order, m.SessionID)
+		}
+		bySession[m.SessionID] = append(bySession[m.SessionID], m)
+	}
+
+	sessions := make([]SessionSummary, 0, len(order))
+	for _, id := range order {
+		items := bySession[id]
+		first, last := items[0].CreatedAt, items[0].CreatedAt
+		for _, m := range items[1:] {
+			if m.CreatedAt.Before(first) {
+				first = m.CreatedAt
+			}
+			if m.CreatedAt.After(last) {
+				last = m.CreatedAt
+			}
+		}
+		topics, summary := summarizeTopics(items, "in this session")
+		sessions = append(sessions, SessionSummary{
+			SessionID:     id,
+			MemoryCount:   len(items),
+			FirstActivity: first.Format(time.RFC3339),
+			LastActivity:  last.Format(time.RFC3339),
+			Topics:        topics,
+			Summary:       summary,
+		})
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastActivity > sessions[j].LastActivity
+	})
+	return sessions
+}
+
+// summarizeTopics counts memories per domain and builds a human-readable
+// summary such as "3 memories in this session: work (2), general (1)".
+func summarizeTopics(memories []types.Memory, scope string) ([]SessionTopicSummary, string) {
 	domainCount := make(map[string]int)
-	for _, m := range result.Items {
+	for _, m := range memories {
 		domain := m.Domain
 		if domain == "" {
 			domain = "general"
@@ -1091,8 +1951,7 @@ func (s *Server) GetSessionContext(ctx context.Context, args GetSessionContextAr
 		return topics[i].Domain < topics[j].Domain
 	})
 
-	// Build human-readable summary string.
-	summary := fmt.Sprintf("%d memories in this session", len(result.Items))
+	summary := fmt.Sprintf("%d memories %s", len(memories), scope)
 	if len(topics) > 0 {
 		topicStrs := make([]string, 0, len(topics))
 		for _, t := range topics {
@@ -1100,34 +1959,19 @@ func (s *Server) GetSessionContext(ctx context.Context, args GetSessionContextAr
 		}
 		summary += ": " + strings.Join(topicStrs, ", ")
 	}
-
-	sessionResult := &GetSessionContextResult{
-		SessionID:   sessionID,
-		MemoryCount: len(result.Items),
-		Memories:    result.Items,
-		Topics:      topics,
-		Summary:     summary,
-	}
-
-	// Onboarding hint: if the store is completely empty, guide the user.
-	totalResult, totalErr := listStore.List(ctx, storage.ListOptions{Limit: 1})
-	if totalErr == nil && totalResult.Total == 0 {
-		sessionResult.OnboardingHint = "Your memory store is empty. Start by storing memories with store_memory. Tip: describe decisions, people, or projects you want to remember."
-	}
-
-	return sessionResult, nil
+	return topics, summary
 }
 
 // RestoreMemory restores a soft-deleted memory.
 func (s *Server) RestoreMemory(ctx context.Context, args RestoreMemoryArgs) (*RestoreMemoryResult, error) {
 	if args.ID == "" {
-		return nil, errors.New("id is required")
+		return nil, invalidParamsf("id is required")
 	}
 
-	store := s.resolveStoreForID(args.ID)
+	store := s.resolveStoreForID(ctx, args.ID)
 	if err := store.Restore(ctx, args.ID); err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return nil, fmt.Errorf("memory not found or not soft-deleted: %s", args.ID)
+			return nil, notFoundf("memory not found or not soft-deleted: %s", args.ID)
 		}
 		return nil, fmt.Errorf("failed to restore memory: %w", err)
 	}
@@ -1137,11 +1981,17 @@ func (s *Server) RestoreMemory(ctx context.Context, args RestoreMemoryArgs) (*Re
 
 // ListDeletedMemories returns soft-deleted memories.
 func (s *Server) ListDeletedMemories(ctx context.Context, args ListDeletedMemoriesArgs) (*ListDeletedMemoriesResult, error) {
+	sortBy, sortOrder, err := parseSort(args.SortBy, args.SortOrder)
+	if err != nil {
+		return nil, err
+	}
 	listStore, _ := s.resolveSearchStore(args.ConnectionID)
 
 	opts := storage.ListOptions{
 		Page:           args.Page,
-		Limit:          args.Limit,
+		Limit:          s.effectiveLimit(args.Limit),
+		SortBy:         sortBy,
+		SortOrder:      sortOrder,
 		IncludeDeleted: true,
 		OnlyDeleted:    true,
 	}
@@ -1157,52 +2007,113 @@ func (s *Server) ListDeletedMemories(ctx context.Context, args ListDeletedMemori
 		Total:    result.Total,
 		Page:     result.Page,
 		HasMore:  result.HasMore,
+		Limit:    opts.Limit,
 	}, nil
 }
 
 // GetEvolutionChain retrieves the full version history for a memory.
+// Soft-deleted versions are left out unless IncludeDeleted is set, in which
+// case they are marked deleted; either way positions count every version,
+// so a gap shows where a deleted one was, and CurrentID is the newest
+// version that is not deleted.
 func (s *Server) GetEvolutionChain(ctx context.Context, args GetEvolutionChainArgs) (*GetEvolutionChainResult, error) {
 	if args.ID == "" {
-		return nil, errors.New("id is required")
+		return nil, invalidParamsf("id is required")
 	}
 
-	store := s.resolveStoreForID(args.ID)
+	store := s.resolveStoreForID(ctx, args.ID)
 	chain, err := store.GetEvolutionChain(ctx, args.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get evolution chain: %w", err)
 	}
 
-	entries := make([]EvolutionEntry, len(chain))
+	entries := make([]EvolutionEntry, 0, len(chain))
+	currentID := ""
+	deleted := 0
 	for i, m := range chain {
+		if m.DeletedAt != nil {
+			deleted++
+			if !args.IncludeDeleted {
+				continue
+			}
+		} else {
+			currentID = m.ID
+		}
 		snippet := m.Content
 		if len(snippet) > 200 {
 			snippet = snippet[:200] + "…"
 		}
-		entries[i] = EvolutionEntry{
+		entry := EvolutionEntry{
 			Position:  i + 1,
 			ID:        m.ID,
 			Content:   snippet,
 			State:     m.State,
 			CreatedAt: m.CreatedAt.Format(time.RFC3339),
 		}
+		if m.DeletedAt != nil {
+			entry.Deleted = true
+			entry.DeletedAt = m.DeletedAt.Format(time.RFC3339)
+		}
+		entries = append(entries, entry)
 	}
 
-	currentID := ""
-	if len(chain) > 0 {
-		currentID = chain[len(chain)-1].ID
+	return &GetEvolutionChainResult{
+		Chain:           entries,
+		TotalVersions:   len(entries),
+		CurrentID:       currentID,
+		DeletedVersions: deleted,
+	}, nil
+}
+
+// SummarizeMemory asks the LLM for a short summary of a memory's content and
+// stores it in the memory's Summary field, replacing any earlier summary.
+func (s *Server) SummarizeMemory(ctx context.Context, args SummarizeMemoryArgs) (*SummarizeMemoryResult, error) {
+	if args.ID == "" {
+		return nil, invalidParamsf("id is required")
+	}
+	if s.engine == nil {
+		return nil, fmt.Errorf("summarization is not available: no LLM engine is configured")
 	}
 
-	return &GetEvolutionChainResult{
-		Chain:         entries,
-		TotalVersions: len(entries),
-		CurrentID:     currentID,
+	store := s.resolveStoreForID(ctx, args.ID)
+	memory, err := store.Get(ctx, args.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("memory not found: %s", args.ID)
+		}
+		return nil, fmt.Errorf("failed to get memory: %w", err)
+	}
+
+	response, err := s.engine.Summarize(ctx, llm.SummarizationPrompt(memory.EnrichmentContent()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize memory: %w", err)
+	}
+	// The prompt asks for JSON; fall back to the raw text if the model
+	// answered in prose.
+	summary := strings.TrimSpace(response)
+	if parsed, err := llm.ParseSummarizationResponse(response); err == nil && parsed.Summary != "" {
+		summary = parsed.Summary
+	}
+	if summary == "" {
+		return nil, fmt.Errorf("failed to summarize memory: empty response from LLM")
+	}
+
+	memory.Summary = summary
+	memory.SummarizationStatus = types.EnrichmentCompleted
+	if err := store.Update(ctx, memory); err != nil {
+		return nil, fmt.Errorf("failed to store summary: %w", err)
+	}
+
+	return &SummarizeMemoryResult{
+		ID:      memory.ID,
+		Summary: summary,
 	}, nil
 }
 
 // CreateProject creates a new project memory and optionally pre-creates phases.
 func (s *Server) CreateProject(ctx context.Context, args CreateProjectArgs) (*CreateProjectResult, error) {
 	if args.Name == "" {
-		return nil, errors.New("name is required")
+		return nil, invalidParamsf("name is required")
 	}
 
 	// Resolve the store.
@@ -1212,9 +2123,11 @@ func (s *Server) CreateProject(ctx context.Context, args CreateProjectArgs) (*Cr
 	}
 
 	store := s.memoryStore
+	routeConn := ""
 	if effectiveConn != "" && s.connectionManager != nil {
 		if connStore, err := s.connectionManager.GetStore(effectiveConn); err == nil {
 			store = connStore
+			routeConn = effectiveConn
 		}
 	}
 
@@ -1242,6 +2155,9 @@ func (s *Server) CreateProject(ctx context.Context, args CreateProjectArgs) (*Cr
 		Timestamp:            time.Now(),
 	}
 
+	if err := s.recordRoute(ctx, projectID, routeConn); err != nil {
+		return nil, err
+	}
 	if err := store.Store(ctx, projectMem); err != nil {
 		return nil, fmt.Errorf("failed to store project: %w", err)
 	}
@@ -1268,6 +2184,9 @@ func (s *Server) CreateProject(ctx context.Context, args CreateProjectArgs) (*Cr
 			UpdatedAt:            time.Now(),
 			Timestamp:            time.Now(),
 		}
+		if err := s.recordRoute(ctx, phaseID, routeConn); err != nil {
+			continue // non-fatal; still return project ID
+		}
 		if err := store.Store(ctx, phaseMem); err != nil {
 			continue // non-fatal; still return project ID
 		}
@@ -1289,29 +2208,32 @@ func (s *Server) CreateProject(ctx context.Context, args CreateProjectArgs) (*Cr
 // AddProjectItem adds a child item to a parent project/phase/epic.
 func (s *Server) AddProjectItem(ctx context.Context, args AddProjectItemArgs) (*AddProjectItemResult, error) {
 	if args.ParentID == "" {
-		return nil, errors.New("parent_id is required")
+		return nil, invalidParamsf("parent_id is required")
 	}
 	if args.ItemType == "" {
-		return nil, errors.New("item_type is required")
+		return nil, invalidParamsf("item_type is required")
 	}
 	if args.Name == "" {
-		return nil, errors.New("name is required")
+		return nil, invalidParamsf("name is required")
 	}
 
 	validTypes := map[string]bool{
 		"epic": true, "phase": true, "task": true, "step": true, "milestone": true,
 	}
 	if !validTypes[args.ItemType] {
-		return nil, fmt.Errorf("invalid item_type %q: must be one of epic, phase, task, step, milestone", args.ItemType)
+		return nil, invalidParamsf("invalid item_type %q: must be one of epic, phase, task, step, milestone", args.ItemType)
+	}
+	if args.Position < 0 {
+		return nil, invalidParamsf("position must be positive, got %d", args.Position)
 	}
 
-	store := s.resolveStoreForID(args.ParentID)
+	store := s.resolveStoreForID(ctx, args.ParentID)
 
 	// Get the parent to inherit domain.
 	parent, err := store.Get(ctx, args.ParentID)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return nil, fmt.Errorf("parent memory not found: %s", args.ParentID)
+			return nil, notFoundf("parent memory not found: %s", args.ParentID)
 		}
 		return nil, fmt.Errorf("failed to retrieve parent: %w", err)
 	}
@@ -1339,6 +2261,9 @@ func (s *Server) AddProjectItem(ctx context.Context, args AddProjectItemArgs) (*
 		Timestamp:            time.Now(),
 	}
 
+	if err := s.recordRoute(ctx, itemID, s.connectionForID(ctx, args.ParentID)); err != nil {
+		return nil, err
+	}
 	if err := store.Store(ctx, itemMem); err != nil {
 		return nil, fmt.Errorf("failed to store project item: %w", err)
 	}
@@ -1350,6 +2275,13 @@ func (s *Server) AddProjectItem(ctx context.Context, args AddProjectItemArgs) (*
 	if ml, ok := store.(memoryLinker); ok {
 		_ = ml.CreateMemoryLink(ctx, uuid.New().String(), args.ParentID, itemID, "CONTAINS")
 	}
+	// New items are linked last; a position moves the item up among its
+	// siblings.
+	if args.Position > 0 {
+		if err := placeProjectItem(ctx, store, args.ParentID, itemID, args.Position); err != nil {
+			return nil, fmt.Errorf("failed to position project item: %w", err)
+		}
+	}
 
 	return &AddProjectItemResult{
 		ID:       itemID,
@@ -1358,10 +2290,137 @@ func (s *Server) AddProjectItem(ctx context.Context, args AddProjectItemArgs) (*
 	}, nil
 }
 
+// MoveProjectItem re-parents a project item by replacing the CONTAINS link
+// from its current parent with one from the new parent, in one transaction.
+// The item's memory is left unchanged. Moves across connections, or that
+// would make an item its own ancestor, are rejected.
+func (s *Server) MoveProjectItem(ctx context.Context, args MoveProjectItemArgs) (*MoveProjectItemResult, error) {
+	if args.ItemID == "" {
+		return nil, invalidParamsf("item_id is required")
+	}
+	if args.NewParentID == "" {
+		return nil, invalidParamsf("new_parent_id is required")
+	}
+	if args.ItemID == args.NewParentID {
+		return nil, invalidParamsf("an item cannot be moved under itself")
+	}
+	// Links never span stores, so both memories must live in the same
+	// connection.
+	conns := make([]string, 2)
+	for i, id := range []string{args.ItemID, args.NewParentID} {
+		if conns[i] = s.connectionForID(ctx, id); conns[i] == "" {
+			conns[i] = s.defaultConnection
+		}
+	}
+	if conns[0] != conns[1] {
+		return nil, invalidParamsf("cannot move %s (connection %q) under %s (connection %q): items cannot move across connections",
+			args.ItemID, conns[0], args.NewParentID, conns[1])
+	}
+
+	store := s.resolveStoreForID(ctx, args.ItemID)
+
+	type memoryLinkMover interface {
+		GetMemoryLinkSources(ctx context.Context, targetID, linkType string) ([]string, error)
+		MoveMemoryLink(ctx context.Context, id, oldSourceID, newSourceID, targetID, linkType string) error
+	}
+	mover, ok := store.(memoryLinkMover)
+	if !ok {
+		return nil, fmt.Errorf("store does not support moving project items")
+	}
+
+	for _, id := range []string{args.ItemID, args.NewParentID} {
+		if _, err := store.Get(ctx, id); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, notFoundf("memory not found: %s", id)
+			}
+			return nil, fmt.Errorf("failed to retrieve memory: %w", err)
+		}
+	}
+
+	parents, err := mover.GetMemoryLinkSources(ctx, args.ItemID, "CONTAINS")
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up current parent: %w", err)
+	}
+	switch len(parents) {
+	case 0:
+		return nil, invalidParamsf("item %s is not contained in any parent", args.ItemID)
+	case 1:
+	default:
+		return nil, invalidParamsf("item %s has %d parents; cannot determine which link to move", args.ItemID, len(parents))
+	}
+	oldParentID := parents[0]
+	if oldParentID == args.NewParentID {
+		return nil, invalidParamsf("item %s is already contained in %s", args.ItemID, args.NewParentID)
+	}
+	if _, err := store.Get(ctx, oldParentID); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("current parent not found: %s", oldParentID)
+		}
+		return nil, fmt.Errorf("failed to retrieve current parent: %w", err)
+	}
+
+	// The new parent must not be a descendant of the item, or the move would
+	// create a cycle in the project graph.
+	isDescendant, err := s.isContainedIn(ctx, store, args.NewParentID, args.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for cycles: %w", err)
+	}
+	if isDescendant {
+		return nil, invalidParamsf("cannot move %s under its own descendant %s", args.ItemID, args.NewParentID)
+	}
+
+	if err := mover.MoveMemoryLink(ctx, uuid.New().String(), oldParentID, args.NewParentID, args.ItemID, "CONTAINS"); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("item %s is no longer contained in %s", args.ItemID, oldParentID)
+		}
+		return nil, fmt.Errorf("failed to move project item: %w", err)
+	}
+
+	result := &MoveProjectItemResult{
+		ID:          args.ItemID,
+		OldParentID: oldParentID,
+		NewParentID: args.NewParentID,
+	}
+	// The move has been committed, so a failure to read back the new parent
+	// is logged rather than returned.
+	if parent, err := s.GetProjectTree(ctx, GetProjectTreeArgs{ProjectID: args.NewParentID, Depth: 2}); err == nil {
+		result.Parent = &parent.Tree
+	} else {
+		slog.Warn("move_project_item: failed to read the new parent", "memory_id", args.NewParentID, "error", err)
+	}
+	return result, nil
+}
+
+// isContainedIn reports whether id is reachable from ancestorID by following
+// CONTAINS links.
+func (s *Server) isContainedIn(ctx context.Context, store storage.MemoryStore, id, ancestorID string) (bool, error) {
+	visited := map[string]bool{ancestorID: true}
+	queue := []string{ancestorID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		children, err := store.GetMemoriesByRelationType(ctx, current, "CONTAINS")
+		if err != nil {
+			return false, err
+		}
+		for _, child := range children {
+			if child.ID == id {
+				return true, nil
+			}
+			if !visited[child.ID] {
+				visited[child.ID] = true
+				queue = append(queue, child.ID)
+			}
+		}
+	}
+	return false, nil
+}
+
 // GetProjectTree retrieves a nested project tree by walking CONTAINS relationships.
 func (s *Server) GetProjectTree(ctx context.Context, args GetProjectTreeArgs) (*GetProjectTreeResult, error) {
 	if args.ProjectID == "" {
-		return nil, errors.New("project_id is required")
+		return nil, invalidParamsf("project_id is required")
 	}
 
 	depth := args.Depth
@@ -1372,12 +2431,12 @@ func (s *Server) GetProjectTree(ctx context.Context, args GetProjectTreeArgs) (*
 		depth = 6
 	}
 
-	store := s.resolveStoreForID(args.ProjectID)
+	store := s.resolveStoreForID(ctx, args.ProjectID)
 
 	root, err := store.Get(ctx, args.ProjectID)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return nil, fmt.Errorf("project not found: %s", args.ProjectID)
+			return nil, notFoundf("project not found: %s", args.ProjectID)
 		}
 		return nil, fmt.Errorf("failed to retrieve project: %w", err)
 	}
@@ -1424,11 +2483,17 @@ func (s *Server) GetProjectTree(ctx context.Context, args GetProjectTreeArgs) (*
 
 // ListProjects lists all project memories.
 func (s *Server) ListProjects(ctx context.Context, args ListProjectsArgs) (*ListProjectsResult, error) {
+	sortBy, sortOrder, err := parseSort(args.SortBy, args.SortOrder)
+	if err != nil {
+		return nil, err
+	}
 	listStore, _ := s.resolveSearchStore(args.ConnectionID)
 
 	opts := storage.ListOptions{
 		Page:       args.Page,
-		Limit:      args.Limit,
+		Limit:      s.effectiveLimit(args.Limit),
+		SortBy:     sortBy,
+		SortOrder:  sortOrder,
 		State:      args.State,
 		MemoryType: "project",
 	}
@@ -1444,6 +2509,44 @@ func (s *Server) ListProjects(ctx context.Context, args ListProjectsArgs) (*List
 		Total:    result.Total,
 		Page:     result.Page,
 		HasMore:  result.HasMore,
+		Limit:    opts.Limit,
+	}, nil
+}
+
+// ListEntities lists the distinct entities extracted from a connection's
+// memories, most frequently mentioned first.
+func (s *Server) ListEntities(ctx context.Context, args ListEntitiesArgs) (*ListEntitiesResult, error) {
+	if args.Type != "" && !types.IsValidEntityType(args.Type) {
+		return nil, invalidParamsf("type %q is not a valid entity type", args.Type)
+	}
+
+	listStore, _ := s.resolveSearchStore(args.ConnectionID)
+	if args.Duplicates {
+		return s.listDuplicateEntities(ctx, listStore, args)
+	}
+
+	opts := storage.EntityListOptions{
+		Type:  args.Type,
+		Page:  args.Page,
+		Limit: args.Limit,
+	}
+	opts.Normalize()
+
+	result, err := listStore.ListEntities(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entities: %w", err)
+	}
+
+	entities := make([]EntitySummary, len(result.Items))
+	for i, e := range result.Items {
+		entities[i] = entitySummary(e)
+	}
+
+	return &ListEntitiesResult{
+		Entities: entities,
+		Total:    result.Total,
+		Page:     result.Page,
+		HasMore:  result.HasMore,
 	}, nil
 }
 
@@ -1456,6 +2559,42 @@ func (s *Server) handleStoreMemory(ctx context.Context, params interface{}) (int
 	return s.StoreMemory(ctx, args)
 }
 
+// handleCreateTypedMemory handles the create_typed_memory JSON-RPC method.
+func (s *Server) handleCreateTypedMemory(ctx context.Context, params interface{}) (interface{}, error) {
+	var args CreateTypedMemoryArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.CreateTypedMemory(ctx, args)
+}
+
+// handleListMemoryTemplates handles the list_memory_templates JSON-RPC method.
+func (s *Server) handleListMemoryTemplates(ctx context.Context, params interface{}) (interface{}, error) {
+	var args ListMemoryTemplatesArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.ListMemoryTemplates(ctx, args)
+}
+
+// handleGetMemory handles the get_memory JSON-RPC method.
+func (s *Server) handleGetMemory(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GetMemoryArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GetMemory(ctx, args)
+}
+
+// handleGetStateHistory handles the get_state_history JSON-RPC method.
+func (s *Server) handleGetStateHistory(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GetStateHistoryArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GetStateHistory(ctx, args)
+}
+
 // handleRecallMemory handles the recall_memory JSON-RPC method.
 func (s *Server) handleRecallMemory(ctx context.Context, params interface{}) (interface{}, error) {
 	var args RecallMemoryArgs
@@ -1501,6 +2640,15 @@ func (s *Server) handleUpdateMemoryState(ctx context.Context, params interface{}
 	return s.UpdateMemoryState(ctx, args)
 }
 
+// handleBatchUpdateState handles the batch_update_state JSON-RPC method.
+func (s *Server) handleBatchUpdateState(ctx context.Context, params interface{}) (interface{}, error) {
+	var args BatchUpdateStateArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.BatchUpdateState(ctx, args)
+}
+
 // handleForgetMemory handles the forget_memory JSON-RPC method.
 func (s *Server) handleForgetMemory(ctx context.Context, params interface{}) (interface{}, error) {
 	var args ForgetMemoryArgs
@@ -1519,6 +2667,15 @@ func (s *Server) handleEvolveMemory(ctx context.Context, params interface{}) (in
 	return s.EvolveMemory(ctx, args)
 }
 
+// handleSupersedeMemory handles the supersede_memory JSON-RPC method.
+func (s *Server) handleSupersedeMemory(ctx context.Context, params interface{}) (interface{}, error) {
+	var args SupersedeMemoryArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.SupersedeMemory(ctx, args)
+}
+
 // handleConsolidateMemories handles the consolidate_memories JSON-RPC method.
 func (s *Server) handleConsolidateMemories(ctx context.Context, params interface{}) (interface{}, error) {
 	var args ConsolidateMemoriesArgs
@@ -1555,59 +2712,98 @@ func (s *Server) handleGetSessionContext(ctx context.Context, params interface{}
 	return s.GetSessionContext(ctx, args)
 }
 
-// handleTraverseMemoryGraph handles the traverse_memory_graph JSON-RPC method.
-// It performs a multi-hop BFS through the entity relationship graph starting
-// from the specified memory and returns connected memories sorted by distance.
-func (s *Server) handleTraverseMemoryGraph(ctx context.Context, params interface{}) (interface{}, error) {
-	var raw map[string]interface{}
-	if err := s.unmarshalParams(params, &raw); err != nil {
+// handleGetCurrentSession handles the get_current_session JSON-RPC method.
+func (s *Server) handleGetCurrentSession(ctx context.Context, params interface{}) (interface{}, error) {
+	return s.GetCurrentSession(ctx)
+}
+
+// handleBeginSession handles the begin_session JSON-RPC method.
+func (s *Server) handleBeginSession(ctx context.Context, params interface{}) (interface{}, error) {
+	var args BeginSessionArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.BeginSession(ctx, args)
+}
+
+// handleEndSession handles the end_session JSON-RPC method.
+func (s *Server) handleEndSession(ctx context.Context, params interface{}) (interface{}, error) {
+	return s.EndSession(ctx)
+}
+
+// handleTraverseMemoryGraph handles the traverse_memory_graph JSON-RPC method.
+// It performs a multi-hop BFS through the entity relationship graph starting
+// from the specified memory and returns connected memories ranked by a
+// relevance score combining hop distance, shared entities and decay.
+// relationship_types, exclude_types and directed restrict which relationship
+// edges the traversal follows; the *_weight arguments tune the score.
+func (s *Server) handleTraverseMemoryGraph(ctx context.Context, params interface{}) (interface{}, error) {
+	var args TraverseMemoryGraphArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
 		return nil, err
 	}
 
-	memoryID, _ := raw["memory_id"].(string)
+	memoryID := args.MemoryID
 	if memoryID == "" {
-		return nil, fmt.Errorf("memory_id is required")
+		return nil, invalidParamsf("memory_id is required")
 	}
 
 	maxHops := 2
-	if v, ok := raw["max_hops"].(float64); ok && v > 0 {
-		maxHops = int(v)
+	if args.MaxHops > 0 {
+		maxHops = args.MaxHops
 		if maxHops > 4 {
 			maxHops = 4 // safety cap
 		}
 	}
 
-	limit := 10
-	if v, ok := raw["limit"].(float64); ok && v > 0 {
-		limit = int(v)
-		if limit > 50 {
-			limit = 50 // safety cap
-		}
+	limit := s.effectiveLimit(args.Limit)
+
+	weights := storage.TraversalWeights{
+		Proximity: args.ProximityWeight,
+		Overlap:   args.OverlapWeight,
+		Decay:     args.DecayWeight,
+	}
+	if weights == (storage.TraversalWeights{}) {
+		weights = storage.DefaultTraversalWeights
 	}
 
 	// Resolve which store to use. Traverse always operates on the store that
 	// owns the memory (inferred from the ID prefix), so we route the same way
 	// as other ID-based operations.
-	store := s.resolveStoreForID(memoryID)
-
-	results, err := store.Traverse(ctx, memoryID, maxHops, limit)
+	store := s.resolveStoreForID(ctx, memoryID)
+
+	results, err := store.Traverse(ctx, memoryID, storage.TraversalOptions{
+		MaxHops:           maxHops,
+		Limit:             limit,
+		RelationshipTypes: args.RelationshipTypes,
+		ExcludeTypes:      args.ExcludeTypes,
+		Directed:          args.Directed,
+		Weights:           weights,
+		MaxBreadth:        s.traversalBreadth,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("graph traversal failed: %w", err)
 	}
 
 	// Format response items.
 	type traversalItem struct {
-		Memory         map[string]interface{} `json:"memory"`
-		HopDistance    int                    `json:"hop_distance"`
-		SharedEntities []string               `json:"shared_entities,omitempty"`
+		Memory            map[string]interface{}  `json:"memory"`
+		HopDistance       int                     `json:"hop_distance"`
+		Score             float64                 `json:"score"`
+		SharedEntityCount int                     `json:"shared_entity_count"`
+		SharedEntities    []string                `json:"shared_entities,omitempty"`
+		Relationships     []storage.TraversalEdge `json:"relationships,omitempty"`
 	}
 
 	items := make([]traversalItem, 0, len(results))
 	for _, r := range results {
 		items = append(items, traversalItem{
-			Memory:         memoryToMap(r.Memory),
-			HopDistance:    r.HopDistance,
-			SharedEntities: r.SharedEntities,
+			Memory:            memoryToMap(r.Memory),
+			HopDistance:       r.HopDistance,
+			Score:             r.Score,
+			SharedEntityCount: r.SharedEntityCount,
+			SharedEntities:    r.SharedEntities,
+			Relationships:     r.Relationships,
 		})
 	}
 
@@ -1615,10 +2811,80 @@ func (s *Server) handleTraverseMemoryGraph(ctx context.Context, params interface
 		"start_memory_id": memoryID,
 		"total_found":     len(items),
 		"max_hops_used":   maxHops,
-		"results":         items,
+		"limit":           limit,
+		"weights": map[string]float64{
+			"proximity": weights.Proximity,
+			"overlap":   weights.Overlap,
+			"decay":     weights.Decay,
+		},
+		"results": items,
 	}, nil
 }
 
+// handleResolveContradiction handles the resolve_contradiction JSON-RPC method.
+func (s *Server) handleResolveContradiction(ctx context.Context, params interface{}) (interface{}, error) {
+	var args ResolveContradictionArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.ResolveContradiction(ctx, args)
+}
+
+// handleRecomputeDecay handles the recompute_decay JSON-RPC method.
+func (s *Server) handleRecomputeDecay(ctx context.Context, params interface{}) (interface{}, error) {
+	var args RecomputeDecayArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.RecomputeDecay(ctx, args)
+}
+
+// handleGetDecayReport handles the get_decay_report JSON-RPC method.
+func (s *Server) handleGetDecayReport(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GetDecayReportArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GetDecayReport(ctx, args)
+}
+
+// handleGetConnectionStatus handles the get_connection_status JSON-RPC method.
+func (s *Server) handleGetConnectionStatus(ctx context.Context, params interface{}) (interface{}, error) {
+	return s.GetConnectionStatus(ctx)
+}
+
+// handleGetEngineStatus handles the get_engine_status JSON-RPC method.
+func (s *Server) handleGetEngineStatus(ctx context.Context, params interface{}) (interface{}, error) {
+	return s.GetEngineStatus(ctx)
+}
+
+// handleGetMemoryNeighbors handles the get_memory_neighbors JSON-RPC method.
+func (s *Server) handleGetMemoryNeighbors(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GetMemoryNeighborsArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GetMemoryNeighbors(ctx, args)
+}
+
+// handleGetMemoryContext handles the get_memory_context JSON-RPC method.
+func (s *Server) handleGetMemoryContext(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GetMemoryContextArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GetMemoryContext(ctx, args)
+}
+
+// handleGetAuditLog handles the get_audit_log JSON-RPC method.
+func (s *Server) handleGetAuditLog(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GetAuditLogArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GetAuditLog(ctx, args)
+}
+
 // handleRestoreMemory handles the restore_memory JSON-RPC method.
 func (s *Server) handleRestoreMemory(ctx context.Context, params interface{}) (interface{}, error) {
 	var args RestoreMemoryArgs
@@ -1646,6 +2912,33 @@ func (s *Server) handleGetEvolutionChain(ctx context.Context, params interface{}
 	return s.GetEvolutionChain(ctx, args)
 }
 
+// handleGetMemorySnapshot handles the get_memory_snapshot JSON-RPC method.
+func (s *Server) handleGetMemorySnapshot(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GetMemorySnapshotArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GetMemorySnapshot(ctx, args)
+}
+
+// handleRestoreMemorySnapshot handles the restore_memory_snapshot JSON-RPC method.
+func (s *Server) handleRestoreMemorySnapshot(ctx context.Context, params interface{}) (interface{}, error) {
+	var args RestoreMemorySnapshotArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.RestoreMemorySnapshot(ctx, args)
+}
+
+// handleSummarizeMemory handles the summarize_memory JSON-RPC method.
+func (s *Server) handleSummarizeMemory(ctx context.Context, params interface{}) (interface{}, error) {
+	var args SummarizeMemoryArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.SummarizeMemory(ctx, args)
+}
+
 // handleCreateProject handles the create_project JSON-RPC method.
 func (s *Server) handleCreateProject(ctx context.Context, params interface{}) (interface{}, error) {
 	var args CreateProjectArgs
@@ -1664,6 +2957,24 @@ func (s *Server) handleAddProjectItem(ctx context.Context, params interface{}) (
 	return s.AddProjectItem(ctx, args)
 }
 
+// handleReorderProjectItems handles the reorder_project_items JSON-RPC method.
+func (s *Server) handleReorderProjectItems(ctx context.Context, params interface{}) (interface{}, error) {
+	var args ReorderProjectItemsArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.ReorderProjectItems(ctx, args)
+}
+
+// handleMoveProjectItem handles the move_project_item JSON-RPC method.
+func (s *Server) handleMoveProjectItem(ctx context.Context, params interface{}) (interface{}, error) {
+	var args MoveProjectItemArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.MoveProjectItem(ctx, args)
+}
+
 // handleGetProjectTree handles the get_project_tree JSON-RPC method.
 func (s *Server) handleGetProjectTree(ctx context.Context, params interface{}) (interface{}, error) {
 	var args GetProjectTreeArgs
@@ -1682,6 +2993,96 @@ func (s *Server) handleListProjects(ctx context.Context, params interface{}) (in
 	return s.ListProjects(ctx, args)
 }
 
+// handleGetRelationships handles the get_relationships JSON-RPC method.
+func (s *Server) handleGetRelationships(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GetRelationshipsArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GetRelationships(ctx, args)
+}
+
+// handleGetGraphExport handles the get_graph_export JSON-RPC method.
+func (s *Server) handleGetGraphExport(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GetGraphExportArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GetGraphExport(ctx, args)
+}
+
+// handleMergeEntities handles the merge_entities JSON-RPC method.
+func (s *Server) handleMergeEntities(ctx context.Context, params interface{}) (interface{}, error) {
+	var args MergeEntitiesArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.MergeEntities(ctx, args)
+}
+
+// handlePurgeConnection handles the purge_connection JSON-RPC method.
+func (s *Server) handlePurgeConnection(ctx context.Context, params interface{}) (interface{}, error) {
+	var args PurgeConnectionArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.PurgeConnection(ctx, args)
+}
+
+// handleGetEmbedding handles the get_embedding JSON-RPC method.
+func (s *Server) handleGetEmbedding(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GetEmbeddingArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GetEmbedding(ctx, args)
+}
+
+// handleMemorySimilarity handles the memory_similarity JSON-RPC method.
+func (s *Server) handleMemorySimilarity(ctx context.Context, params interface{}) (interface{}, error) {
+	var args MemorySimilarityArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.MemorySimilarity(ctx, args)
+}
+
+// handleClusterMemories handles the cluster_memories JSON-RPC method.
+func (s *Server) handleClusterMemories(ctx context.Context, params interface{}) (interface{}, error) {
+	var args ClusterMemoriesArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.ClusterMemories(ctx, args)
+}
+
+// handleGenerateDigest handles the generate_digest JSON-RPC method.
+func (s *Server) handleGenerateDigest(ctx context.Context, params interface{}) (interface{}, error) {
+	var args GenerateDigestArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.GenerateDigest(ctx, args)
+}
+
+// handleListEntities handles the list_entities JSON-RPC method.
+func (s *Server) handleListEntities(ctx context.Context, params interface{}) (interface{}, error) {
+	var args ListEntitiesArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.ListEntities(ctx, args)
+}
+
+// handleRecallAboutEntity handles the recall_about_entity JSON-RPC method.
+func (s *Server) handleRecallAboutEntity(ctx context.Context, params interface{}) (interface{}, error) {
+	var args RecallAboutEntityArgs
+	if err := s.unmarshalParams(params, &args); err != nil {
+		return nil, err
+	}
+	return s.RecallAboutEntity(ctx, args)
+}
+
 // memoryToMap converts a types.Memory to a plain map[string]interface{} for
 // JSON serialisation in MCP responses. Only the most useful fields are included.
 func memoryToMap(m *types.Memory) map[string]interface{} {
@@ -1707,6 +3108,12 @@ func memoryToMap(m *types.Memory) map[string]interface{} {
 	if m.State != "" {
 		out["state"] = m.State
 	}
+	out["entity_status"] = string(m.EntityStatus)
+	out["relationship_status"] = string(m.RelationshipStatus)
+	out["classification_status"] = string(m.ClassificationStatus)
+	out["summarization_status"] = string(m.SummarizationStatus)
+	out["embedding_status"] = string(m.EmbeddingStatus)
+	out["enrichment_summary"] = m.EnrichmentSummary()
 	return out
 }
 
@@ -1718,48 +3125,159 @@ func memoryToMap(m *types.Memory) map[string]interface{} {
 // 1. conflicting_relationship: Same entity with multiple values for single-valued relationships
 // 2. superseded_active: Superseded memories still have active relationships
 // 3. temporal_impossibility: Temporal ordering violations in relationships
+//
+// With semantic set, the memory is also compared with its most similar
+// memories by the LLM (see detectSemanticContradictions), and any semantic
+// contradictions are appended to the structural ones.
 func (s *Server) DetectContradictions(ctx context.Context, args DetectContradictionsArgs) (*DetectContradictionsResult, error) {
+	if args.MinConfidence < 0 || args.MinConfidence > 1 {
+		return nil, invalidParamsf("min_confidence must be between 0 and 1, got %g", args.MinConfidence)
+	}
+	if args.Semantic {
+		if args.MemoryID == "" {
+			return nil, invalidParamsf("memory_id is required for semantic contradiction detection")
+		}
+		if !s.semanticContradictions {
+			return nil, invalidParamsf("semantic contradiction detection is disabled (set MEMENTO_ENABLE_SEMANTIC_CONTRADICTIONS=true)")
+		}
+		if s.engine == nil {
+			return nil, fmt.Errorf("semantic contradiction detection is not available: no LLM engine is configured")
+		}
+	}
+
 	// Call the contradiction detector
 	contradictions, err := s.detector.DetectContradictions(ctx, args.MemoryID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect contradictions: %w", err)
 	}
 
-	// Convert engine contradictions to API result types
-	results := make([]ContradictionResult, len(contradictions))
-	for i, c := range contradictions {
-		results[i] = ContradictionResult{
+	if args.Semantic {
+		semantic, err := s.detectSemanticContradictions(ctx, args.MemoryID, args.TopK)
+		if err != nil {
+			return nil, err
+		}
+		contradictions = append(contradictions, semantic...)
+	}
+
+	// Convert engine contradictions to API result types, dropping those
+	// below the confidence threshold.
+	results := make([]ContradictionResult, 0, len(contradictions))
+	for _, c := range contradictions {
+		if c.Confidence < args.MinConfidence {
+			continue
+		}
+		results = append(results, ContradictionResult{
 			Type:        string(c.Type),
 			MemoryIDs:   c.MemoryIDs,
 			Description: c.Description,
 			Confidence:  c.Confidence,
-		}
+		})
 	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Confidence > results[j].Confidence
+	})
 
 	var message string
 	if args.MemoryID != "" {
-		message = fmt.Sprintf("Detected %d contradictions involving memory %s", len(contradictions), args.MemoryID)
+		message = fmt.Sprintf("Detected %d contradictions involving memory %s", len(results), args.MemoryID)
 	} else {
-		message = fmt.Sprintf("Detected %d contradictions in the memory graph", len(contradictions))
+		message = fmt.Sprintf("Detected %d contradictions in the memory graph", len(results))
+	}
+	below := len(contradictions) - len(results)
+	if below > 0 {
+		message += fmt.Sprintf(" (%d below min_confidence %g omitted)", below, args.MinConfidence)
 	}
 
 	return &DetectContradictionsResult{
 		Contradictions: results,
-		Total:          len(contradictions),
+		Total:          len(results),
+		BelowThreshold: below,
 		Message:        message,
 	}, nil
 }
 
+// Defaults and bounds for DetectContradictionsArgs.TopK.
+const (
+	defaultSemanticTopK = 5
+	maxSemanticTopK     = 20
+)
+
+// detectSemanticContradictions retrieves up to topK memories similar to
+// memoryID with hybrid search (full-text only if embedding fails) and asks the
+// LLM engine whether any of them contradict it.
+func (s *Server) detectSemanticContradictions(ctx context.Context, memoryID string, topK int) ([]engine.Contradiction, error) {
+	if topK <= 0 {
+		topK = defaultSemanticTopK
+	}
+	topK = min(topK, maxSemanticTopK)
+
+	store := s.resolveStoreForID(ctx, memoryID)
+	memory, err := store.Get(ctx, memoryID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("memory not found: %s", memoryID)
+		}
+		return nil, fmt.Errorf("failed to get memory: %w", err)
+	}
+
+	_, searchProvider := s.resolveSearchStore(s.connectionForID(ctx, memoryID))
+	if searchProvider == nil {
+		return nil, fmt.Errorf("semantic contradiction detection requires a search provider")
+	}
+
+	query := memory.EnrichmentContent()
+	// Ask for one extra result: the memory usually matches itself.
+	opts := storage.SearchOptions{Query: query, Limit: topK + 1, FuzzyFallback: true}
+	var similar *storage.PaginatedResult[types.Memory]
+	if vec, embErr := s.engine.Embed(ctx, query); embErr == nil && len(vec) > 0 {
+		similar, err = searchProvider.HybridSearch(ctx, query, vec, opts)
+	} else if embErr != nil {
+		slog.Warn("detect_contradictions: embedding failed, using full-text search for candidates", "error", embErr)
+	}
+	if similar == nil {
+		similar, err = searchProvider.FullTextSearch(ctx, opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar memories: %w", err)
+	}
+
+	candidates := make([]types.Memory, 0, topK)
+	for _, m := range similar.Items {
+		if m.ID != memoryID && len(candidates) < topK {
+			candidates = append(candidates, m)
+		}
+	}
+
+	contradictions, err := engine.DetectSemanticContradictions(ctx, memory, candidates, s.engine.Summarize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect semantic contradictions: %w", err)
+	}
+	return contradictions, nil
+}
+
 // ---------------------------------------------------------------------------
 // Standard MCP protocol handlers
 // ---------------------------------------------------------------------------
 
 // handleInitialize handles the MCP initialize handshake.
+// Clients that declare the experimental enrichmentNotifications capability
+// are sent a notification whenever a memory's enrichment completes or fails.
 func (s *Server) handleInitialize(ctx context.Context, params interface{}) (interface{}, error) {
+	var p MCPInitializeParams
+	if params != nil {
+		if err := s.unmarshalParams(params, &p); err != nil {
+			return nil, err
+		}
+	}
+	s.notifications.setEnabled(clientWantsEnrichmentNotifications(p.Capabilities))
+
 	return MCPInitializeResult{
 		ProtocolVersion: "2024-11-05",
 		Capabilities: MCPServerCapabilities{
 			Tools: &MCPToolsCapability{},
+			Experimental: map[string]interface{}{
+				EnrichmentNotificationsCapability: map[string]interface{}{},
+			},
 		},
 		ServerInfo: MCPServerInfo{
 			Name:    "memento",
@@ -1792,24 +3310,72 @@ func (s *Server) handleToolsCall(ctx context.Context, params interface{}) (inter
 		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
 	}
 
+	if err := s.checkWritable(p.Name); err != nil {
+		return &MCPToolCallResult{
+			Content: []MCPToolCallContent{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+	if err := s.checkConnectionWritable(ctx, p.Name, p.Arguments); err != nil {
+		return &MCPToolCallResult{
+			Content: []MCPToolCallContent{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+
+	// Check arguments against the tool's input schema so a misspelled or
+	// missing field is reported instead of silently ignored. Native method
+	// calls skip this and keep their lenient decoding.
+	if tool, ok := s.lookupTool(p.Name); ok {
+		if err := validateToolArguments(tool, p.Arguments); err != nil {
+			return &MCPToolCallResult{
+				Content: []MCPToolCallContent{{Type: "text", Text: err.Error()}},
+				IsError: true,
+			}, nil
+		}
+	}
+
 	var result interface{}
 	var handlerErr error
+	start := time.Now()
 
 	switch p.Name {
 	case "store_memory":
 		result, handlerErr = s.handleStoreMemory(ctx, rawParams)
+	case "create_typed_memory":
+		result, handlerErr = s.handleCreateTypedMemory(ctx, rawParams)
+	case "list_memory_templates":
+		result, handlerErr = s.handleListMemoryTemplates(ctx, rawParams)
 	case "recall_memory":
 		result, handlerErr = s.handleRecallMemory(ctx, rawParams)
+	case "get_memory":
+		result, handlerErr = s.handleGetMemory(ctx, rawParams)
 	case "find_related":
 		result, handlerErr = s.handleFindRelated(ctx, rawParams)
 	case "update_memory_state":
 		result, handlerErr = s.handleUpdateMemoryState(ctx, rawParams)
+	case "batch_update_state":
+		result, handlerErr = s.handleBatchUpdateState(ctx, rawParams)
+	case "get_state_history":
+		result, handlerErr = s.handleGetStateHistory(ctx, rawParams)
 	case "forget_memory":
 		result, handlerErr = s.handleForgetMemory(ctx, rawParams)
 	case "evolve_memory":
 		result, handlerErr = s.handleEvolveMemory(ctx, rawParams)
+	case "supersede_memory":
+		result, handlerErr = s.handleSupersedeMemory(ctx, rawParams)
 	case "detect_contradictions":
 		result, handlerErr = s.handleDetectContradictions(ctx, rawParams)
+	case "resolve_contradiction":
+		result, handlerErr = s.handleResolveContradiction(ctx, rawParams)
+	case "recompute_decay":
+		result, handlerErr = s.handleRecomputeDecay(ctx, rawParams)
+	case "get_decay_report":
+		result, handlerErr = s.handleGetDecayReport(ctx, rawParams)
+	case "get_connection_status":
+		result, handlerErr = s.handleGetConnectionStatus(ctx, rawParams)
+	case "get_engine_status":
+		result, handlerErr = s.handleGetEngineStatus(ctx, rawParams)
 	case "update_memory":
 		result, handlerErr = s.handleUpdateMemory(ctx, rawParams)
 	case "explain_reasoning":
@@ -1818,22 +3384,64 @@ func (s *Server) handleToolsCall(ctx context.Context, params interface{}) (inter
 		result, handlerErr = s.handleRetryEnrichment(ctx, rawParams)
 	case "get_session_context":
 		result, handlerErr = s.handleGetSessionContext(ctx, rawParams)
+	case "get_current_session":
+		result, handlerErr = s.handleGetCurrentSession(ctx, rawParams)
+	case "begin_session":
+		result, handlerErr = s.handleBeginSession(ctx, rawParams)
+	case "end_session":
+		result, handlerErr = s.handleEndSession(ctx, rawParams)
 	case "traverse_memory_graph":
 		result, handlerErr = s.handleTraverseMemoryGraph(ctx, rawParams)
+	case "get_graph_export":
+		result, handlerErr = s.handleGetGraphExport(ctx, rawParams)
+	case "get_relationships":
+		result, handlerErr = s.handleGetRelationships(ctx, rawParams)
+	case "get_memory_neighbors":
+		result, handlerErr = s.handleGetMemoryNeighbors(ctx, rawParams)
+	case "get_memory_context":
+		result, handlerErr = s.handleGetMemoryContext(ctx, rawParams)
+	case "get_audit_log":
+		result, handlerErr = s.handleGetAuditLog(ctx, rawParams)
 	case "restore_memory":
 		result, handlerErr = s.handleRestoreMemory(ctx, rawParams)
 	case "list_deleted_memories":
 		result, handlerErr = s.handleListDeletedMemories(ctx, rawParams)
 	case "get_evolution_chain":
 		result, handlerErr = s.handleGetEvolutionChain(ctx, rawParams)
+	case "summarize_memory":
+		result, handlerErr = s.handleSummarizeMemory(ctx, rawParams)
+	case "get_memory_snapshot":
+		result, handlerErr = s.handleGetMemorySnapshot(ctx, rawParams)
+	case "restore_memory_snapshot":
+		result, handlerErr = s.handleRestoreMemorySnapshot(ctx, rawParams)
 	case "create_project":
 		result, handlerErr = s.handleCreateProject(ctx, rawParams)
 	case "add_project_item":
 		result, handlerErr = s.handleAddProjectItem(ctx, rawParams)
+	case "move_project_item":
+		result, handlerErr = s.handleMoveProjectItem(ctx, rawParams)
+	case "reorder_project_items":
+		result, handlerErr = s.handleReorderProjectItems(ctx, rawParams)
 	case "get_project_tree":
 		result, handlerErr = s.handleGetProjectTree(ctx, rawParams)
 	case "list_projects":
 		result, handlerErr = s.handleListProjects(ctx, rawParams)
+	case "list_entities":
+		result, handlerErr = s.handleListEntities(ctx, rawParams)
+	case "merge_entities":
+		result, handlerErr = s.handleMergeEntities(ctx, rawParams)
+	case "purge_connection":
+		result, handlerErr = s.handlePurgeConnection(ctx, rawParams)
+	case "recall_about_entity":
+		result, handlerErr = s.handleRecallAboutEntity(ctx, rawParams)
+	case "get_embedding":
+		result, handlerErr = s.handleGetEmbedding(ctx, rawParams)
+	case "memory_similarity":
+		result, handlerErr = s.handleMemorySimilarity(ctx, rawParams)
+	case "cluster_memories":
+		result, handlerErr = s.handleClusterMemories(ctx, rawParams)
+	case "generate_digest":
+		result, handlerErr = s.handleGenerateDigest(ctx, rawParams)
 	default:
 		return &MCPToolCallResult{
 			Content: []MCPToolCallContent{{Type: "text", Text: fmt.Sprintf("unknown tool: %s", p.Name)}},
@@ -1842,11 +3450,15 @@ func (s *Server) handleToolsCall(ctx context.Context, params interface{}) (inter
 	}
 
 	if handlerErr != nil {
+		handlerErr = s.checkTimeout(ctx, handlerErr)
+		logToolCall(p.Name, p.Arguments, start, handlerErr)
 		return &MCPToolCallResult{
 			Content: []MCPToolCallContent{{Type: "text", Text: handlerErr.Error()}},
 			IsError: true,
 		}, nil
 	}
+	logToolCall(p.Name, p.Arguments, start, nil)
+	s.recordAudit(ctx, p.Name, rawParams, result)
 
 	text, err := json.Marshal(result)
 	if err != nil {
@@ -1858,23 +3470,109 @@ func (s *Server) handleToolsCall(ctx context.Context, params interface{}) (inter
 	}, nil
 }
 
-// buildToolsList returns the canonical list of MCP tool definitions.
+// logToolCall logs a finished tools/call with the tool as op and, when the
+// arguments name them, the connection and memory_id. Successful calls and
+// client errors such as invalid params are logged at debug; server errors
+// and timeouts at warn.
+func logToolCall(name string, args map[string]interface{}, start time.Time, err error) {
+	attrs := []any{"op", name, "duration", time.Since(start)}
+	if conn, _ := args["connection_id"].(string); conn != "" {
+		attrs = append(attrs, "connection", conn)
+	}
+	for _, key := range []string{"memory_id", "id"} {
+		if id, _ := args[key].(string); id != "" {
+			attrs = append(attrs, "memory_id", id)
+			break
+		}
+	}
+	switch {
+	case err == nil:
+		slog.Debug("tool call", attrs...)
+	case errorCode(err) == ErrCodeServerError || errorCode(err) == ErrCodeTimeout:
+		slog.Warn("tool call failed", append(attrs, "error", err)...)
+	default:
+		slog.Debug("tool call rejected", append(attrs, "error", err)...)
+	}
+}
+
+// buildToolsList returns the MCP tool definitions this server exposes.
+// In read-only mode mutating tools are left out entirely.
 func (s *Server) buildToolsList() []MCPTool {
+	tools := s.allTools()
+	if !s.readOnly {
+		return tools
+	}
+	visible := make([]MCPTool, 0, len(tools))
+	for _, t := range tools {
+		if !mutatingTools[t.Name] {
+			visible = append(visible, t)
+		}
+	}
+	return visible
+}
+
+// allTools returns the canonical list of MCP tool definitions.
+func (s *Server) allTools() []MCPTool {
 	return []MCPTool{
 		{
 			Name:        "store_memory",
-			Description: "Store a new memory. Returns immediately with a pending status; enrichment (entity extraction, embeddings) happens asynchronously. Duplicate content is deduplicated automatically.",
+			Description: "Store a new memory. Returns immediately with a pending status; enrichment (entity extraction, embeddings) happens asynchronously unless wait_for_enrichment is set. Duplicate content is deduplicated automatically.",
 			InputSchema: map[string]interface{}{
 				"type":     "object",
 				"required": []string{"content"},
 				"properties": map[string]interface{}{
-					"content":       map[string]interface{}{"type": "string", "description": "The memory content to store (required)"},
-					"source":        map[string]interface{}{"type": "string", "description": "Where this memory came from"},
-					"domain":        map[string]interface{}{"type": "string", "description": "Memory domain/category (deprecated: prefer connection_id)"},
-					"connection_id": map[string]interface{}{"type": "string", "description": "Connection to store into; sets the domain automatically"},
-					"tags":          map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Optional tags for categorization"},
-					"metadata":      map[string]interface{}{"type": "object", "description": "Arbitrary key-value metadata"},
-					"created_by":    map[string]interface{}{"type": "string", "description": "Name of the agent or developer storing this memory. Auto-detected if not provided."},
+					"content":             map[string]interface{}{"type": "string", "description": "The memory content to store (required). " + s.contentLimitDescription()},
+					"source":              map[string]interface{}{"type": "string", "description": "Where this memory came from"},
+					"domain":              map[string]interface{}{"type": "string", "description": "Memory domain/category (deprecated: prefer connection_id)"},
+					"connection_id":       map[string]interface{}{"type": "string", "description": "Connection to store into; sets the domain automatically"},
+					"tags":                map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Optional tags for categorization"},
+					"metadata":            map[string]interface{}{"type": "object", "description": "Arbitrary key-value metadata"},
+					"created_by":          map[string]interface{}{"type": "string", "description": "Name of the agent or developer storing this memory. Auto-detected if not provided."},
+					"expires_at":          map[string]interface{}{"type": "string", "description": "Optional RFC-3339 expiry time. Expired memories are hidden from recall/search and soft-deleted by a background sweeper."},
+					"author_type":         map[string]interface{}{"type": "string", "enum": []string{"human", "agent", "system"}, "description": "Authorship origin. Defaults to agent when created_by is given, otherwise derived from the detected author."},
+					"idempotency_key":     map[string]interface{}{"type": "string", "description": "Optional client-chosen key for safe retries: repeating a call with the same key (and connection) within the retry window returns the first result instead of storing again"},
+					"truncate":            map[string]interface{}{"type": "boolean", "description": "When content exceeds the length limit, store it in full but enrich and embed only the first part instead of rejecting it (default false)"},
+					"wait_for_enrichment": map[string]interface{}{"type": "boolean", "description": "Block until enrichment completes or fails and return the enriched memory instead of a pending status (default false). Useful for tests and scripts"},
+					"timeout_seconds":     map[string]interface{}{"type": "integer", "description": fmt.Sprintf("How long wait_for_enrichment may block, in seconds (default %d, max %d). On timeout the pending memory is returned and enrichment continues in the background", int(DefaultEnrichmentWaitTimeout/time.Second), MaxEnrichmentWaitSeconds)},
+					"session_id":          map[string]interface{}{"type": "string", "description": "Tag the memory with this session instead of the current one"},
+				},
+			},
+		},
+		{
+			Name:        "create_typed_memory",
+			Description: "Store a structured memory from a template, e.g. a decision with its context, choice, rationale and alternatives. The fields are rendered into the content, kept in metadata (template, fields) and the memory_type is set from the template, so recall_memory can filter by memory_type. Otherwise behaves like store_memory. Use list_memory_templates to see each template's fields.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"template", "fields"},
+				"properties": map[string]interface{}{
+					"template":        map[string]interface{}{"type": "string", "enum": s.memoryTemplateNames(), "description": "Template to use (required)"},
+					"fields":          map[string]interface{}{"type": "object", "description": "Field values by name, each a string or a list of strings, e.g. {\"context\": \"...\", \"choice\": \"...\", \"rationale\": \"...\", \"alternatives\": [\"...\"]} (required)"},
+					"connection_id":   map[string]interface{}{"type": "string", "description": "Connection to store into"},
+					"source":          map[string]interface{}{"type": "string", "description": "Where this memory came from"},
+					"tags":            map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Optional tags for categorization"},
+					"created_by":      map[string]interface{}{"type": "string", "description": "Name of the agent or developer storing this memory. Auto-detected if not provided."},
+					"author_type":     map[string]interface{}{"type": "string", "enum": []string{"human", "agent", "system"}, "description": "Authorship origin, as for store_memory"},
+					"idempotency_key": map[string]interface{}{"type": "string", "description": "Optional client-chosen key for safe retries, as for store_memory"},
+				},
+			},
+		},
+		{
+			Name:        "list_memory_templates",
+			Description: "List the templates create_typed_memory accepts, with each template's memory_type and fields (which are required).",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "get_memory",
+			Description: "Fetch exactly one memory by ID. Returns found=false when no such memory exists. Prefer this over recall_memory when you have an ID: it never falls back to search or listing.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"id"},
+				"properties": map[string]interface{}{
+					"id":            map[string]interface{}{"type": "string", "description": "Memory ID (required)"},
+					"connection_id": map[string]interface{}{"type": "string", "description": "Connection to read from (inferred from the ID if omitted)"},
 				},
 			},
 		},
@@ -1888,43 +3586,89 @@ func (s *Server) buildToolsList() []MCPTool {
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"id":             map[string]interface{}{"type": "string", "description": "Memory ID for direct lookup (connection is inferred from the ID)"},
-					"query":          map[string]interface{}{"type": "string", "description": "Natural-language search query (full-text search)"},
-					"connection_id":  map[string]interface{}{"type": "string", "description": "Scope search/list to this connection (workspace). Omit to use the default."},
-					"state":          map[string]interface{}{"type": "string", "description": "Filter by lifecycle state: active, archived, superseded"},
-					"created_by":     map[string]interface{}{"type": "string", "description": "Filter by creator"},
-					"created_after":  map[string]interface{}{"type": "string", "description": "RFC-3339 lower bound for created_at"},
-					"created_before": map[string]interface{}{"type": "string", "description": "RFC-3339 upper bound for created_at"},
-					"limit":          map[string]interface{}{"type": "integer", "description": "Max results to return (default 10, max 100)"},
-					"page":           map[string]interface{}{"type": "integer", "description": "Page number for list mode (default 1)"},
+					"id":              map[string]interface{}{"type": "string", "description": "Memory ID for direct lookup (connection is inferred from the ID)"},
+					"query":           map[string]interface{}{"type": "string", "description": "Natural-language search query (full-text search)"},
+					"connection_id":   map[string]interface{}{"type": "string", "description": "Scope search/list to this connection (workspace). Omit to use the default."},
+					"state":           map[string]interface{}{"type": "string", "description": "Filter by lifecycle state: active, archived, superseded"},
+					"created_by":      map[string]interface{}{"type": "string", "description": "Filter by creator"},
+					"author_type":     map[string]interface{}{"type": "string", "enum": []string{"human", "agent", "system"}, "description": "Filter by authorship origin: human, agent or system"},
+					"memory_type":     map[string]interface{}{"type": "string", "description": "Filter by memory type, e.g. decision, event or person for memories created with create_typed_memory"},
+					"created_after":   map[string]interface{}{"type": "string", "description": "RFC-3339 lower bound for created_at"},
+					"created_before":  map[string]interface{}{"type": "string", "description": "RFC-3339 upper bound for created_at"},
+					"accessed_after":  map[string]interface{}{"type": "string", "description": "List mode: RFC-3339 lower bound for last_accessed_at, e.g. a week ago for memories touched this week; never-accessed memories are excluded"},
+					"accessed_before": map[string]interface{}{"type": "string", "description": "List mode: RFC-3339 upper bound for last_accessed_at; never-accessed memories are excluded"},
+					"limit":           map[string]interface{}{"type": "integer", "description": s.limitDescription()},
+					"page":            map[string]interface{}{"type": "integer", "description": "Page number for list mode (default 1)"},
+					"include_expired": map[string]interface{}{"type": "boolean", "description": "Include memories past their expires_at that have not been swept yet (default false)"},
+					"sort_by":         map[string]interface{}{"type": "string", "enum": storage.SortFields, "description": "Sort list mode by this field, e.g. access_count for most-accessed or updated_at for most recently updated (default created_at)"},
+					"sort_order":      map[string]interface{}{"type": "string", "enum": []string{"asc", "desc"}, "description": "Sort direction for list mode (default desc)"},
+					"min_decay_score": map[string]interface{}{"type": "number", "description": "Only return memories whose decay_score is at least this value (0.0-1.0)"},
+					"max_decay_score": map[string]interface{}{"type": "number", "description": "Only return memories whose decay_score is at most this value (0.0-1.0), e.g. with sort_by decay_score for the coldest memories"},
+					"metadata":        map[string]interface{}{"type": "object", "description": "Only return memories whose metadata has each of these keys with an equal value, e.g. {\"project\": \"atlas\", \"priority\": 1}; values are strings, numbers or booleans and types must match (1 does not match \"1\")"},
 				},
 			},
 		},
 		{
 			Name:        "find_related",
-			Description: "Full-text (+ semantic) search across memories. Returns ranked results. Pass connection_id to search within a specific workspace.",
+			Description: "Full-text (+ semantic) search across memories. Returns ranked results; full-text matches include a short snippet with the matched terms marked **like this**. Pass connection_id to search within a specific workspace.",
 			InputSchema: map[string]interface{}{
 				"type":     "object",
 				"required": []string{"query"},
 				"properties": map[string]interface{}{
-					"query":          map[string]interface{}{"type": "string", "description": "Search query (required)"},
-					"connection_id":  map[string]interface{}{"type": "string", "description": "Scope search to this connection (workspace). Omit to search the default workspace."},
-					"limit":          map[string]interface{}{"type": "integer", "description": "Max results (default 10)"},
-					"domain":         map[string]interface{}{"type": "string", "description": "Restrict search to this domain (legacy; prefer connection_id)"},
-					"created_after":  map[string]interface{}{"type": "string", "description": "RFC-3339 lower bound for created_at"},
-					"created_before": map[string]interface{}{"type": "string", "description": "RFC-3339 upper bound for created_at"},
+					"query":           map[string]interface{}{"type": "string", "description": `Search query (required). Plain words match any of them. For precise searches use "exact phrase", term* (prefix), and uppercase AND, OR and NOT (a NOT b); other punctuation is ignored.`},
+					"connection_id":   map[string]interface{}{"type": "string", "description": "Scope search to this connection (workspace). Omit to search the default workspace."},
+					"limit":           map[string]interface{}{"type": "integer", "description": s.limitDescription()},
+					"domain":          map[string]interface{}{"type": "string", "description": "Restrict search to this domain (legacy; prefer connection_id)"},
+					"created_after":   map[string]interface{}{"type": "string", "description": "RFC-3339 lower bound for created_at"},
+					"created_before":  map[string]interface{}{"type": "string", "description": "RFC-3339 upper bound for created_at"},
+					"author_type":     map[string]interface{}{"type": "string", "enum": []string{"human", "agent", "system"}, "description": "Filter by authorship origin: human, agent or system"},
+					"memory_type":     map[string]interface{}{"type": "string", "description": "Filter by memory type, e.g. decision"},
+					"include_expired": map[string]interface{}{"type": "boolean", "description": "Include memories past their expires_at that have not been swept yet (default false)"},
+					"fuzzy":           map[string]interface{}{"type": "boolean", "description": "Tolerate typos: when there are fewer exact matches than limit, also return memories containing words spelled similarly to the query's, e.g. elasticserch finds elasticsearch (default false). Not applied to queries using phrase or operator syntax"},
+					"expand_query":    map[string]interface{}{"type": "boolean", "description": "Also search synonyms and abbreviations of the query suggested by the LLM, e.g. k8s and kubernetes (default false). Needs query expansion enabled on the server; the terms used are returned in expanded_terms"},
+					"min_similarity":  map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1, "description": "Drop semantic matches whose cosine similarity to the query is below this value (0-1) so unrelated queries return nothing instead of weak hits; keyword matches are kept (default 0, no cutoff)"},
+					"tags":            map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Only return memories carrying these tags, e.g. [\"project-x\"]; memories whose content matches but lack the tags are excluded"},
+					"tags_match":      map[string]interface{}{"type": "string", "enum": []string{"any", "all"}, "description": "Whether a memory needs any of tags (default) or all of them"},
+					"metadata":        map[string]interface{}{"type": "object", "description": "Only return memories whose metadata has each of these keys with an equal value, e.g. {\"project\": \"atlas\"}; values are strings, numbers or booleans and types must match"},
 				},
 			},
 		},
 		{
 			Name:        "update_memory_state",
-			Description: "Update the lifecycle state of a memory. Valid states: active, archived, superseded.",
+			Description: "Update the lifecycle state of a memory. The states and allowed transitions are the built-in ones (planning, active, paused, blocked, completed, cancelled, archived, superseded) unless the connection defines a custom state machine.",
 			InputSchema: map[string]interface{}{
 				"type":     "object",
 				"required": []string{"id", "state"},
 				"properties": map[string]interface{}{
 					"id":    map[string]interface{}{"type": "string", "description": "Memory ID (required)"},
-					"state": map[string]interface{}{"type": "string", "description": "New lifecycle state: active, archived, superseded"},
+					"state": map[string]interface{}{"type": "string", "description": "New lifecycle state (required)"},
+				},
+			},
+		},
+		{
+			Name:        "batch_update_state",
+			Description: "Move many memories of one connection to the same lifecycle state, e.g. mark ten tasks completed. Select them by ids or by a full-text query. Each transition is validated as in update_memory_state; invalid transitions and missing memories are reported per ID without stopping the rest. Applied in one transaction where the store supports it.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"state"},
+				"properties": map[string]interface{}{
+					"ids":           map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Memory IDs to transition (max 100); use this or query"},
+					"query":         map[string]interface{}{"type": "string", "description": "Full-text query selecting the memories; use this or ids"},
+					"limit":         map[string]interface{}{"type": "integer", "description": "Max memories matched by query (default 10, max 100)"},
+					"state":         map[string]interface{}{"type": "string", "description": "New lifecycle state: planning, active, paused, blocked, completed, cancelled, archived, superseded, or a state of the connection's custom state machine (required)"},
+					"connection_id": map[string]interface{}{"type": "string", "description": "Connection to update (inferred from the first ID if omitted)"},
+				},
+			},
+		},
+		{
+			Name:        "get_state_history",
+			Description: "Show a memory's lifecycle timeline: every state transition with when it happened, who made it and how long the memory stayed in each state, e.g. to see how long a task sat in blocked.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"id"},
+				"properties": map[string]interface{}{
+					"id":            map[string]interface{}{"type": "string", "description": "Memory ID (required)"},
+					"connection_id": map[string]interface{}{"type": "string", "description": "Connection to read from (inferred from the ID if omitted)"},
 				},
 			},
 		},
@@ -1948,64 +3692,143 @@ func (s *Server) buildToolsList() []MCPTool {
 				"type":     "object",
 				"required": []string{"id"},
 				"properties": map[string]interface{}{
-					"id":       map[string]interface{}{"type": "string", "description": "Memory ID to update (required)"},
-					"content":  map[string]interface{}{"type": "string", "description": "New content to replace the existing content"},
-					"tags":     map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "New tags list (replaces existing tags)"},
-					"metadata": map[string]interface{}{"type": "object", "description": "New metadata map (replaces existing metadata)"},
+					"id":      map[string]interface{}{"type": "string", "description": "Memory ID to update (required)"},
+					"content": map[string]interface{}{"type": "string", "description": "New content to replace the existing content"},
+					"tags":    map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Tags to apply; how they are applied is controlled by tags_mode"},
+					"tags_mode": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"replace", "append", "remove"},
+						"description": "How to apply tags: replace the list (default), append new tags, or remove the given tags",
+					},
+					"metadata":       map[string]interface{}{"type": "object", "description": "Metadata map (replaces existing metadata unless metadata_merge is true)"},
+					"metadata_merge": map[string]interface{}{"type": "boolean", "description": "Merge metadata keys into the existing map instead of replacing it; a null value deletes the key (default: false)"},
 				},
 			},
 		},
 		{
 			Name:        "detect_contradictions",
-			Description: "Scan the memory graph for contradictions (conflicting relationships, superseded-but-active memories, temporal impossibilities).",
+			Description: "Scan the memory graph for contradictions (conflicting relationships, superseded-but-active memories, temporal impossibilities). With semantic=true, also asks the LLM whether the memory's most similar memories contradict it.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"memory_id": map[string]interface{}{"type": "string", "description": "Optional: focus contradiction detection on this memory ID"},
+					"memory_id":      map[string]interface{}{"type": "string", "description": "Optional: focus contradiction detection on this memory ID"},
+					"semantic":       map[string]interface{}{"type": "boolean", "description": "Also run LLM-assisted semantic detection against similar memories (requires memory_id and MEMENTO_ENABLE_SEMANTIC_CONTRADICTIONS; costs an LLM call)"},
+					"top_k":          map[string]interface{}{"type": "integer", "description": "Number of similar memories to compare in semantic mode (default 5, max 20)"},
+					"min_confidence": map[string]interface{}{"type": "number", "description": "Drop contradictions with confidence below this value (0.0-1.0, default 0). Results are sorted by confidence, highest first"},
 				},
 			},
 		},
 		{
-			Name:        "explain_reasoning",
-			Description: "Explain why specific memories were retrieved for a query.",
+			Name:        "resolve_contradiction",
+			Description: "Resolve a contradiction from detect_contradictions by keeping one memory: every other memory in it is marked superseded (or archived). The contradiction is re-checked first, and the resulting state of each memory is returned.",
 			InputSchema: map[string]interface{}{
-				"type":     "object",
-				"required": []string{"query", "memory_ids"},
+				"type": "object",
 				"properties": map[string]interface{}{
-					"query":      map[string]interface{}{"type": "string", "description": "The original query"},
-					"memory_ids": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Memory IDs to explain"},
+					"contradiction": map[string]interface{}{
+						"type":        "object",
+						"description": "The contradiction object as returned by detect_contradictions (type and memory_ids are required)",
+						"properties": map[string]interface{}{
+							"type":       map[string]interface{}{"type": "string"},
+							"memory_ids": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+						},
+						"required": []string{"type", "memory_ids"},
+					},
+					"winner_id":           map[string]interface{}{"type": "string", "description": "ID of the memory to keep; must be one of the contradiction's memory_ids"},
+					"action":              map[string]interface{}{"type": "string", "enum": []string{"supersede", "archive"}, "description": "What to do with the other memories (default: supersede)"},
+					"record_relationship": map[string]interface{}{"type": "boolean", "description": "Also create a SUPERSEDES link from the winner to each other memory"},
 				},
+				"required": []string{"contradiction", "winner_id"},
 			},
 		},
 		{
-			Name:        "retry_enrichment",
-			Description: "Retry enrichment (entity extraction, embeddings) for a memory that previously failed.",
+			Name:        "recompute_decay",
+			Description: "Recompute decay scores for a connection's active memories now instead of waiting for the background updater (e.g. after a bulk import or long downtime). Returns the number of memories updated. Rate-limited per connection.",
 			InputSchema: map[string]interface{}{
-				"type":     "object",
-				"required": []string{"id"},
+				"type": "object",
 				"properties": map[string]interface{}{
-					"id": map[string]interface{}{"type": "string", "description": "Memory ID to retry enrichment for"},
+					"connection_id": map[string]interface{}{"type": "string", "description": "Optional: connection whose memories are recomputed (default: the default connection)"},
 				},
 			},
 		},
 		{
-			Name:        "get_session_context",
-			Description: "Retrieve memories from the current or a specified session to understand what work was done. Useful for 'where did I leave off?' queries. Returns recent memories grouped by topic with a summary.",
+			Name:        "get_decay_report",
+			Description: "List memories whose decay score has fallen to a threshold or below, most faded first, with their access count and days since last access. Use it to review memories that are about to fade and decide whether to reinforce, archive or delete them.",
 			InputSchema: map[string]interface{}{
-				"type":     "object",
-				"required": []string{},
+				"type": "object",
 				"properties": map[string]interface{}{
-					"session_id": map[string]interface{}{
-						"type":        "string",
-						"description": "Session ID to query. Defaults to the current MCP session.",
-					},
-					"limit": map[string]interface{}{
-						"type":        "integer",
-						"description": "Maximum number of memories to return (default: 20).",
+					"threshold":     map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1, "description": fmt.Sprintf("Only memories with a decay score at or below this value (default %g)", DefaultDecayReportThreshold)},
+					"connection_id": map[string]interface{}{"type": "string", "description": "Optional: connection to report on (default: the default connection)"},
+					"limit":         map[string]interface{}{"type": "integer", "description": s.limitDescription()},
+					"page":          map[string]interface{}{"type": "integer", "description": "Page number (default 1)"},
+				},
+			},
+		},
+		{
+			Name:        "get_connection_status",
+			Description: "List every configured connection with its enabled flag, backend type, whether its store opened (and why not), memory count, and a ping result. Use it to diagnose why memories went to an unexpected connection.",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "get_engine_status",
+			Description: "Report the enrichment engine's health: queue depth, capacity and utilization, jobs deferred because the queue was full, jobs in flight, worker count, whether the embedding and summarization backends are reachable, and memory counts by enrichment status. Use it to see why memories are stuck in pending.",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+
+		{
+			Name:        "explain_reasoning",
+			Description: "Explain why specific memories were retrieved for a query.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"query", "memory_ids"},
+				"properties": map[string]interface{}{
+					"query":      map[string]interface{}{"type": "string", "description": "The original query"},
+					"memory_ids": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Memory IDs to explain"},
+				},
+			},
+		},
+		{
+			Name:        "retry_enrichment",
+			Description: "Retry enrichment (entity extraction, embeddings) for a memory that previously failed.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"id"},
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{"type": "string", "description": "Memory ID to retry enrichment for"},
+				},
+			},
+		},
+		{
+			Name:        "get_session_context",
+			Description: "Retrieve memories from the current or a specified session to understand what work was done. Useful for 'where did I leave off?' queries. Returns recent memories grouped by topic with a summary. Omit session_id and give a time range (time_window_hours, created_after or created_before) to span every session instead, e.g. 'what did I do this week'; the result then also groups memories by session.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{},
+				"properties": map[string]interface{}{
+					"session_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Session ID to query. Defaults to the current MCP session.",
+					},
+					"limit": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of memories to return (default: 20).",
 					},
 					"time_window_hours": map[string]interface{}{
 						"type":        "integer",
-						"description": "Only return memories from the last N hours. 0 means all memories in the session.",
+						"description": "Only return memories from the last N hours. 0 means all memories in the session. Without session_id, searches all sessions.",
+					},
+					"created_after": map[string]interface{}{
+						"type":        "string",
+						"description": "ISO-8601 / RFC-3339 timestamp; only memories created after it. Without session_id, searches all sessions.",
+					},
+					"created_before": map[string]interface{}{
+						"type":        "string",
+						"description": "ISO-8601 / RFC-3339 timestamp; only memories created before it. Without session_id, searches all sessions.",
 					},
 					"connection_id": map[string]interface{}{
 						"type":        "string",
@@ -2014,6 +3837,32 @@ func (s *Server) buildToolsList() []MCPTool {
 				},
 			},
 		},
+		{
+			Name:        "get_current_session",
+			Description: "Return the current session ID that new memories are tagged with, its label when it was started with begin_session, when the session started and when the server last saw activity. The server starts a new session when a memory is stored after an idle gap or once the session is older than its TTL, unless the session was started with begin_session.",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "begin_session",
+			Description: "Start a named work session, e.g. 'refactor auth module'. Memories stored from now on are tagged with the returned session_id, and get_session_context shows the label. The session is not rotated by the idle timeout or TTL; it lasts until end_session or the next begin_session.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"label": map[string]interface{}{"type": "string", "description": "Human-readable name for the session"},
+				},
+			},
+		},
+		{
+			Name:        "end_session",
+			Description: "End the session started with begin_session and record when it ended. Memories stored afterwards go to automatic sessions again.",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
 		{
 			Name:        "evolve_memory",
 			Description: "Create a new version of a memory that supersedes the old one. The old memory is marked as 'superseded' and a new memory is stored with the updated content. Use this instead of update_memory when the change represents a meaningful evolution (not just a typo fix).",
@@ -2027,6 +3876,19 @@ func (s *Server) buildToolsList() []MCPTool {
 				},
 			},
 		},
+		{
+			Name:        "supersede_memory",
+			Description: "Mark an existing memory as superseding another existing memory, without creating a new one as evolve_memory does. Sets new_id's supersedes_id to old_id and moves old_id to 'superseded'. Refused if new_id already supersedes a memory, old_id has already been superseded, or the link would create a cycle in the evolution chain.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"old_id", "new_id"},
+				"properties": map[string]interface{}{
+					"old_id":        map[string]interface{}{"type": "string", "description": "ID of the memory being replaced (required)"},
+					"new_id":        map[string]interface{}{"type": "string", "description": "ID of the memory that replaces it (required)"},
+					"connection_id": map[string]interface{}{"type": "string", "description": "Connection both memories live in (inferred from the IDs if omitted)"},
+				},
+			},
+		},
 		{
 			Name:        "consolidate_memories",
 			Description: "Merge multiple memories into one consolidated memory. The originals are soft-deleted. Use when you have several related memories that should be combined into a single, coherent record. Provide either explicit memory IDs or a search query to find candidates.",
@@ -2043,7 +3905,7 @@ func (s *Server) buildToolsList() []MCPTool {
 		},
 		{
 			Name:        "traverse_memory_graph",
-			Description: "Follow entity relationship connections from a memory to find related memories. Discovers memories connected through shared entities (people, organizations, concepts). Use when you want to explore what is contextually related to a memory, not just textually similar.",
+			Description: "Follow entity relationship connections from a memory to find related memories. Discovers memories connected through shared entities (people, organizations, concepts). Use when you want to explore what is contextually related to a memory, not just textually similar. Results are ranked by a relevance score combining proximity (hops), overlap (shared entities) and decay. Each result lists the relationships followed to reach it, with their type and direction (outgoing: source→target, incoming: target→source).",
 			InputSchema: map[string]interface{}{
 				"type":     "object",
 				"required": []string{"memory_id"},
@@ -2059,9 +3921,75 @@ func (s *Server) buildToolsList() []MCPTool {
 					},
 					"limit": map[string]interface{}{
 						"type":        "integer",
-						"description": "Maximum number of results to return (default 10)",
-						"default":     10,
+						"description": s.limitDescription(),
+						"default":     s.defaultLimit,
 					},
+					"relationship_types": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Only follow relationships of these types, e.g. [\"CAUSES\", \"BLOCKS\"] (case-insensitive; default: all types)",
+					},
+					"exclude_types": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Never follow relationships of these types, e.g. [\"CONTAINS\"] (case-insensitive)",
+					},
+					"directed": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Follow relationships only from source to target (default: both directions)",
+						"default":     false,
+					},
+					"proximity_weight": map[string]interface{}{
+						"type":        "number",
+						"description": "Relative weight of hop distance in the relevance score (default 0.5; omit all weights for the defaults)",
+					},
+					"overlap_weight": map[string]interface{}{
+						"type":        "number",
+						"description": "Relative weight of the number of shared entities in the relevance score (default 0.3)",
+					},
+					"decay_weight": map[string]interface{}{
+						"type":        "number",
+						"description": "Relative weight of the memory's decay score in the relevance score (default 0.2)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "get_memory_neighbors",
+			Description: "List a memory's direct typed links in both directions: outgoing and incoming memory links (CONTAINS, SUPERSEDES and any custom types) with a short summary of the memory at the other end, plus which memory it supersedes and which supersede it. A cheap single-hop query; use traverse_memory_graph to follow shared entities instead.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"id"},
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{"type": "string", "description": "Memory ID whose neighbors to list (required)"},
+				},
+			},
+		},
+		{
+			Name:        "get_memory_context",
+			Description: "Bundle a memory with the context around it for an LLM prompt, in one call: the memory, the latest version of its evolution chain, its directly linked memories, and the top memories sharing entities with it. The bundle is capped at max_memories memories and max_chars characters of text, trimming content to fit. Returns each memory as a structured entry plus a pre-rendered text block ready to paste into a prompt.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"id"},
+				"properties": map[string]interface{}{
+					"id":           map[string]interface{}{"type": "string", "description": "Memory ID to build the context around (required)"},
+					"max_memories": map[string]interface{}{"type": "integer", "description": "Most memories in the bundle, the memory itself included (default 8, max 20)"},
+					"max_chars":    map[string]interface{}{"type": "integer", "description": "Length budget of the rendered text in characters (default 4000, min 200, max 50000)"},
+				},
+			},
+		},
+		{
+			Name:        "get_audit_log",
+			Description: "List the audit log of mutating operations (store, update, evolve, consolidate, forget, restore, state changes, project and session tools), newest first. Each entry records when it happened, who did it (created_by and author type), the tool, the memory IDs involved and the connection. Entries are only recorded while the audit log is enabled on the server.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"memory_id": map[string]interface{}{"type": "string", "description": "Only entries involving this memory"},
+					"agent":     map[string]interface{}{"type": "string", "description": "Only entries by this agent or user (created_by)"},
+					"tool":      map[string]interface{}{"type": "string", "description": "Only entries for this tool, e.g. forget_memory"},
+					"since":     map[string]interface{}{"type": "string", "description": "RFC-3339 time; only entries at or after it"},
+					"until":     map[string]interface{}{"type": "string", "description": "RFC-3339 time; only entries before it"},
+					"limit":     map[string]interface{}{"type": "integer", "description": "Maximum entries to return"},
 				},
 			},
 		},
@@ -2084,20 +4012,56 @@ func (s *Server) buildToolsList() []MCPTool {
 				"type": "object",
 				"properties": map[string]interface{}{
 					"connection_id": map[string]interface{}{"type": "string", "description": "Connection to query (defaults to primary)"},
-					"limit":         map[string]interface{}{"type": "integer", "description": "Max results (default 10)"},
+					"limit":         map[string]interface{}{"type": "integer", "description": s.limitDescription()},
 					"page":          map[string]interface{}{"type": "integer", "description": "Page number (default 1)"},
+					"sort_by":       map[string]interface{}{"type": "string", "enum": storage.SortFields, "description": "Sort by this field (default created_at)"},
+					"sort_order":    map[string]interface{}{"type": "string", "enum": []string{"asc", "desc"}, "description": "Sort direction (default desc)"},
 				},
 			},
 		},
 		{
 			Name:        "get_evolution_chain",
-			Description: "Get the full version history of a memory. Returns an ordered list from the original version to the latest, showing how the memory has evolved over time.",
+			Description: "Get the full version history of a memory. Returns an ordered list from the original version to the latest, showing how the memory has evolved over time. Soft-deleted versions are hidden unless include_deleted is set, in which case they are marked deleted.",
 			InputSchema: map[string]interface{}{
 				"type":     "object",
 				"required": []string{"id"},
 				"properties": map[string]interface{}{
-					"id":            map[string]interface{}{"type": "string", "description": "Memory ID to trace (required)"},
-					"connection_id": map[string]interface{}{"type": "string", "description": "Connection the memory lives in (inferred from ID if omitted)"},
+					"id":              map[string]interface{}{"type": "string", "description": "Memory ID to trace (required)"},
+					"connection_id":   map[string]interface{}{"type": "string", "description": "Connection the memory lives in (inferred from ID if omitted)"},
+					"include_deleted": map[string]interface{}{"type": "boolean", "description": "Also list soft-deleted versions, marked deleted (default false)"},
+				},
+			},
+		},
+		{
+			Name:        "summarize_memory",
+			Description: "Generate a short summary of a memory with the LLM and store it on the memory, replacing any earlier summary. Enrichment already summarizes long memories automatically; use this to create or refresh one on demand. Summaries are returned with recall and search results.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"id"},
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{"type": "string", "description": "Memory ID to summarize (required)"},
+				},
+			},
+		},
+		{
+			Name:        "get_memory_snapshot",
+			Description: "Capture a point-in-time copy of a memory for audit: the memory itself plus its entities, the relationships among them, and its incoming and outgoing memory links, as one self-contained JSON document. Unlike get_evolution_chain this is a literal state capture; pass it to restore_memory_snapshot to roll back.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"id"},
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{"type": "string", "description": "Memory ID to capture (required)"},
+				},
+			},
+		},
+		{
+			Name:        "restore_memory_snapshot",
+			Description: "Re-apply a snapshot from get_memory_snapshot: the memory is written back exactly as captured (undeleting or recreating it if needed), its entity associations are replaced, and missing links are recreated. Links to memories that no longer exist are skipped.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"snapshot"},
+				"properties": map[string]interface{}{
+					"snapshot": map[string]interface{}{"type": "object", "description": "Snapshot document returned by get_memory_snapshot (required)"},
 				},
 			},
 		},
@@ -2126,10 +4090,35 @@ func (s *Server) buildToolsList() []MCPTool {
 					"item_type":     map[string]interface{}{"type": "string", "description": "Type of item: epic, phase, task, step, or milestone (required)"},
 					"name":          map[string]interface{}{"type": "string", "description": "Item name (required)"},
 					"description":   map[string]interface{}{"type": "string", "description": "Item description"},
+					"position":      map[string]interface{}{"type": "integer", "description": "1-based position among the parent's children; later children shift down (default: last)"},
 					"connection_id": map[string]interface{}{"type": "string", "description": "Connection (inferred from parent_id if omitted)"},
 				},
 			},
 		},
+		{
+			Name:        "move_project_item",
+			Description: "Move a project item (epic, phase, task, step, or milestone) to a different parent, keeping its ID and content. Replaces the CONTAINS link from the current parent in one transaction and returns the new parent with its direct children. The new parent must be in the same connection; moves that would create a cycle are rejected.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"item_id", "new_parent_id"},
+				"properties": map[string]interface{}{
+					"item_id":       map[string]interface{}{"type": "string", "description": "ID of the item to move (required)"},
+					"new_parent_id": map[string]interface{}{"type": "string", "description": "ID of the new parent project, phase, or epic (required)"},
+				},
+			},
+		},
+		{
+			Name:        "reorder_project_items",
+			Description: "Set the order of a project item's children (e.g. the phases of a project or the steps of a task), which get_project_tree returns in that order. Listed children come first in the given order; children not listed keep their relative order after them.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"parent_id", "item_ids"},
+				"properties": map[string]interface{}{
+					"parent_id": map[string]interface{}{"type": "string", "description": "ID of the parent project, phase, epic, or task (required)"},
+					"item_ids":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Child IDs in their new order (required)"},
+				},
+			},
+		},
 		{
 			Name:        "get_project_tree",
 			Description: "Retrieve the full nested hierarchy of a project, including all phases, epics, tasks, steps, and milestones linked via CONTAINS relationships.",
@@ -2151,8 +4140,152 @@ func (s *Server) buildToolsList() []MCPTool {
 				"properties": map[string]interface{}{
 					"connection_id": map[string]interface{}{"type": "string", "description": "Connection to query (defaults to primary)"},
 					"state":         map[string]interface{}{"type": "string", "description": "Filter by lifecycle state (e.g. 'active', 'completed')"},
-					"limit":         map[string]interface{}{"type": "integer", "description": "Max results (default 10)"},
+					"limit":         map[string]interface{}{"type": "integer", "description": s.limitDescription()},
 					"page":          map[string]interface{}{"type": "integer", "description": "Page number (default 1)"},
+					"sort_by":       map[string]interface{}{"type": "string", "enum": storage.SortFields, "description": "Sort by this field, e.g. updated_at for most recently updated (default created_at)"},
+					"sort_order":    map[string]interface{}{"type": "string", "enum": []string{"asc", "desc"}, "description": "Sort direction (default desc)"},
+				},
+			},
+		},
+		{
+			Name:        "list_entities",
+			Description: "List the distinct entities (people, projects, tools, ...) extracted from memories, with how many memories mention each and when it was first and last seen. Ordered by memory count, most mentioned first. With duplicates, list groups of entities whose names suggest they are the same, such as \"Alice\", \"alice\" and \"Alice Smith\", to review before merge_entities.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"connection_id": map[string]interface{}{"type": "string", "description": "Connection to query (defaults to primary)"},
+					"type":          map[string]interface{}{"type": "string", "enum": types.ValidEntityTypes, "description": "Filter by entity type (e.g. 'person', 'tool')"},
+					"limit":         map[string]interface{}{"type": "integer", "description": "Max results (default 20, max 100)"},
+					"page":          map[string]interface{}{"type": "integer", "description": "Page number (default 1)"},
+					"duplicates":    map[string]interface{}{"type": "boolean", "description": "List groups of likely duplicate entities, each with a suggested canonical_id, instead of the entities (default false)"},
+				},
+			},
+		},
+		{
+			Name:        "get_relationships",
+			Description: "List the typed relationships enrichment inferred between entities, with their source and target entities and extraction confidence, highest first. Give memory_id for the relationships among the entities a memory mentions, or entity_id for those from or to one entity (each marked outgoing or incoming).",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"memory_id":     map[string]interface{}{"type": "string", "description": "Memory whose entities' relationships to list"},
+					"entity_id":     map[string]interface{}{"type": "string", "description": "Entity whose relationships to list"},
+					"type":          map[string]interface{}{"type": "string", "description": "Only relationships of this type, e.g. works_on"},
+					"connection_id": map[string]interface{}{"type": "string", "description": "Connection to query (inferred from memory_id, else primary)"},
+					"limit":         map[string]interface{}{"type": "integer", "description": s.limitDescription()},
+				},
+			},
+		},
+		{
+			Name:        "get_graph_export",
+			Description: "Export the memory and entity graph of a connection for visualization in Gephi, D3 or similar: memory and entity nodes, with typed edges for the entities each memory mentions, the relationships between entities and the links between memories. The newest memories and the entities they mention most are kept within max_nodes; truncated reports whether any were left out.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"connection_id":  map[string]interface{}{"type": "string", "description": "Connection to export (defaults to primary)"},
+					"format":         map[string]interface{}{"type": "string", "enum": []string{"json", "graphml"}, "description": "json for node-link JSON (nodes and links), graphml for a GraphML document (default json)"},
+					"domain":         map[string]interface{}{"type": "string", "description": "Only memories of this domain"},
+					"created_after":  map[string]interface{}{"type": "string", "description": "RFC-3339 lower bound for memory created_at"},
+					"created_before": map[string]interface{}{"type": "string", "description": "RFC-3339 upper bound for memory created_at"},
+					"max_nodes":      map[string]interface{}{"type": "integer", "description": "Most memory and entity nodes (default 500, max 5000)"},
+				},
+			},
+		},
+		{
+			Name:        "merge_entities",
+			Description: "Merge duplicate entities into a canonical one: memories and relationships of the duplicates are moved to the canonical entity and the duplicates are deleted, in one transaction. Use list_entities with duplicates to find candidates.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"canonical_id", "duplicate_ids"},
+				"properties": map[string]interface{}{
+					"connection_id": map[string]interface{}{"type": "string", "description": "Connection holding the entities (defaults to primary)"},
+					"canonical_id":  map[string]interface{}{"type": "string", "description": "ID of the entity to keep"},
+					"duplicate_ids": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "IDs of the entities to merge into it and delete"},
+				},
+			},
+		},
+		{
+			Name:        "purge_connection",
+			Description: "Permanently delete every memory, entity, relationship and link of a connection, soft-deleted memories included. This cannot be undone: confirm must repeat the connection name. Optionally also removes the connection from connections.json and deletes its SQLite database files.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"connection_id", "confirm"},
+				"properties": map[string]interface{}{
+					"connection_id":     map[string]interface{}{"type": "string", "description": "Connection to purge"},
+					"confirm":           map[string]interface{}{"type": "string", "description": "The connection name again, to confirm the purge"},
+					"remove_connection": map[string]interface{}{"type": "boolean", "description": "Also remove the connection from connections.json (not allowed for the default connection)"},
+					"delete_files":      map[string]interface{}{"type": "boolean", "description": "Also delete the connection's SQLite database files (requires remove_connection)"},
+				},
+			},
+		},
+		{
+			Name:        "recall_about_entity",
+			Description: "Recall everything about one person, company, project or other entity, e.g. \"the Acme deal last quarter\": finds the entity by name, gathers the memories linked to it created within since/until, and returns them grouped by domain with a short summary.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"entity"},
+				"properties": map[string]interface{}{
+					"entity":        map[string]interface{}{"type": "string", "description": "Entity name, matched ignoring case (required)"},
+					"type":          map[string]interface{}{"type": "string", "enum": types.ValidEntityTypes, "description": "Only match entities of this type (e.g. 'person')"},
+					"connection_id": map[string]interface{}{"type": "string", "description": "Connection to query (defaults to primary)"},
+					"since":         map[string]interface{}{"type": "string", "description": "RFC-3339 lower bound on when the memories were created"},
+					"until":         map[string]interface{}{"type": "string", "description": "RFC-3339 upper bound on when the memories were created"},
+					"limit":         map[string]interface{}{"type": "integer", "description": s.limitDescription()},
+				},
+			},
+		},
+		{
+			Name:        "get_embedding",
+			Description: "Debugging aid for search ranking: return the stored embedding vector of a memory (id) or embed ad-hoc text (text), with the model name, dimension count and norm. Compare two vectors to see why memories that look similar do not rank together. Requires MEMENTO_ENABLE_EMBEDDING_DEBUG.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id":            map[string]interface{}{"type": "string", "description": "Memory whose stored embedding to return (give id or text)"},
+					"text":          map[string]interface{}{"type": "string", "description": "Text to embed with the current model, as a search query would be (give id or text)"},
+					"connection_id": map[string]interface{}{"type": "string", "description": "Connection holding the memory (inferred from id if omitted)"},
+					"precision":     map[string]interface{}{"type": "integer", "description": fmt.Sprintf("Decimal places each value is rounded to (default %d, max %d)", DefaultEmbeddingPrecision, MaxEmbeddingPrecision)},
+				},
+			},
+		},
+		{
+			Name:        "memory_similarity",
+			Description: "Cosine similarity (-1 to 1) between the embeddings of two memories, or of a memory and ad-hoc text, as used by the semantic half of find_related. A memory without a stored embedding is embedded on the fly. Use it to understand search ranking or check that the embedder works.",
+			InputSchema: map[string]interface{}{
+				"type":     "object",
+				"required": []string{"memory_id"},
+				"properties": map[string]interface{}{
+					"memory_id":     map[string]interface{}{"type": "string", "description": "First memory"},
+					"other_id":      map[string]interface{}{"type": "string", "description": "Second memory (give other_id or text)"},
+					"text":          map[string]interface{}{"type": "string", "description": "Text to compare the memory with, embedded like a search query (give other_id or text)"},
+					"connection_id": map[string]interface{}{"type": "string", "description": "Connection holding the memories (inferred from the IDs if omitted)"},
+				},
+			},
+		},
+		{
+			Name:        "cluster_memories",
+			Description: "Overview of a large workspace: group the newest memories into topics by k-means over their embeddings (or by their most common tag when embeddings are unavailable). Each cluster has a short label (LLM-generated when available), its size, top tags, the memories closest to its centre and all its memory IDs.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"connection_id":  map[string]interface{}{"type": "string", "description": "Connection to cluster (defaults to primary)"},
+					"clusters":       map[string]interface{}{"type": "integer", "description": "Number of clusters (default: about the square root of half the memory count, max 20)"},
+					"max_memories":   map[string]interface{}{"type": "integer", "description": "Newest memories to cluster (default 200, max 1000)"},
+					"memory_type":    map[string]interface{}{"type": "string", "description": "Only cluster memories of this type"},
+					"created_after":  map[string]interface{}{"type": "string", "description": "RFC-3339 lower bound for memory created_at"},
+					"created_before": map[string]interface{}{"type": "string", "description": "RFC-3339 upper bound for memory created_at"},
+				},
+			},
+		},
+		{
+			Name:        "generate_digest",
+			Description: "Write a readable digest of what was captured in a connection over the last day or week: the memories created in the window, grouped by domain or topic and summarized by the LLM (a plain listing without one). The digest is stored as a memory of type digest so it can be searched later; use dry_run to only return it.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"connection_id":  map[string]interface{}{"type": "string", "description": "Connection to digest (defaults to primary)"},
+					"period":         map[string]interface{}{"type": "string", "enum": []string{"day", "week"}, "description": "Length of the window ending at created_before (default day)"},
+					"created_after":  map[string]interface{}{"type": "string", "description": "RFC-3339 start of the window, overriding period"},
+					"created_before": map[string]interface{}{"type": "string", "description": "RFC-3339 end of the window (default now)"},
+					"dry_run":        map[string]interface{}{"type": "boolean", "description": "Return the digest without storing it (default false)"},
 				},
 			},
 		},
@@ -2162,41 +4295,201 @@ func (s *Server) buildToolsList() []MCPTool {
 // validateStoreMemoryArgs validates store_memory arguments.
 func (s *Server) validateStoreMemoryArgs(args StoreMemoryArgs) error {
 	if args.Content == "" {
-		return errors.New("content is required")
+		return invalidParamsf("content is required")
 	}
-	return nil
+	if args.TimeoutSeconds < 0 || args.TimeoutSeconds > MaxEnrichmentWaitSeconds {
+		return invalidParamsf("timeout_seconds must be between 0 and %d", MaxEnrichmentWaitSeconds)
+	}
+	if s.contentTooLong(args.Content) && !args.Truncate {
+		return invalidParamsf("content is %d characters, over the %d character limit; shorten it or set truncate to store it with only the first %d characters enriched",
+			utf8.RuneCountInString(args.Content), s.maxContentLength, s.maxContentLength)
+	}
+	return validateAuthorType(args.AuthorType)
+}
+
+// storeConnection returns the connection a store_memory call writes to:
+// connection_id, then the legacy domain, then the server default. An empty
+// result means the default store.
+func storeConnection(args StoreMemoryArgs, defaultConnection string) string {
+	if args.ConnectionID != "" {
+		return args.ConnectionID
+	}
+	if args.Domain != "" {
+		return args.Domain
+	}
+	return defaultConnection
+}
+
+// withMetadata returns a copy of metadata with key set to value, leaving the
+// caller's map untouched.
+func withMetadata(metadata map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(metadata)+1)
+	for k, v := range metadata {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+// contentTooLong reports whether content exceeds the configured
+// store_memory length limit.
+func (s *Server) contentTooLong(content string) bool {
+	if s.maxContentLength <= 0 || len(content) <= s.maxContentLength {
+		return false
+	}
+	return utf8.RuneCountInString(content) > s.maxContentLength
+}
+
+// contentLimitDescription describes the content length policy for the
+// store_memory schema.
+func (s *Server) contentLimitDescription() string {
+	if s.maxContentLength <= 0 {
+		return "No length limit is enforced."
+	}
+	return fmt.Sprintf("At most %d characters; longer content is rejected unless truncate is set.", s.maxContentLength)
+}
+
+// effectiveLimit applies the server's result limits to a requested page size:
+// a non-positive request gets the default and a larger one is clamped to the
+// cap.
+func (s *Server) effectiveLimit(requested int) int {
+	if requested <= 0 {
+		return s.defaultLimit
+	}
+	return min(requested, s.maxLimit)
+}
+
+// limitDescription documents the limit parameter of the list and search
+// tools using the server's configured limits.
+func (s *Server) limitDescription() string {
+	return fmt.Sprintf("Max results (default %d, max %d)", s.defaultLimit, s.maxLimit)
+}
+
+// parseTimeRange parses optional RFC-3339 created_after / created_before
+// bounds. Empty strings yield zero times; when both are set created_after
+// must come first.
+func parseTimeRange(after, before string) (createdAfter, createdBefore time.Time, err error) {
+	return parseBounds("created", after, before)
+}
+
+// parseBounds parses the <field>_after and <field>_before arguments, e.g.
+// accessed_after and accessed_before, as parseTimeRange does.
+func parseBounds(field, after, before string) (afterTime, beforeTime time.Time, err error) {
+	if after != "" {
+		if afterTime, err = time.Parse(time.RFC3339, after); err != nil {
+			return time.Time{}, time.Time{}, invalidParamsf("%s_after: invalid RFC-3339 timestamp %q: %v", field, after, err)
+		}
+	}
+	if before != "" {
+		if beforeTime, err = time.Parse(time.RFC3339, before); err != nil {
+			return time.Time{}, time.Time{}, invalidParamsf("%s_before: invalid RFC-3339 timestamp %q: %v", field, before, err)
+		}
+	}
+	if !afterTime.IsZero() && !beforeTime.IsZero() && !afterTime.Before(beforeTime) {
+		return time.Time{}, time.Time{}, invalidParamsf("%[1]s_after (%[2]s) must be before %[1]s_before (%[3]s)",
+			field, afterTime.Format(time.RFC3339), beforeTime.Format(time.RFC3339))
+	}
+	return afterTime, beforeTime, nil
+}
+
+// parseSort validates the sort_by / sort_order arguments of the list tools.
+// sort_by must be one of storage.SortFields and sort_order asc or desc (in
+// any case); empty values keep the store default of created_at desc.
+func parseSort(sortBy, sortOrder string) (string, string, error) {
+	if sortBy != "" && !storage.IsSortField(sortBy) {
+		return "", "", invalidParamsf("sort_by %q is not supported; use one of %s", sortBy, strings.Join(storage.SortFields, ", "))
+	}
+	order := strings.ToLower(sortOrder)
+	if order != "" && order != "asc" && order != "desc" {
+		return "", "", invalidParamsf("sort_order %q is not supported; use asc or desc", sortOrder)
+	}
+	return sortBy, order, nil
 }
 
 // validateFindRelatedArgs validates find_related arguments.
 func (s *Server) validateFindRelatedArgs(args FindRelatedArgs) error {
 	if args.Query == "" {
-		return errors.New("query is required")
+		return invalidParamsf("query is required")
 	}
 	if args.Limit < 0 {
-		return errors.New("limit must be non-negative")
+		return invalidParamsf("limit must be non-negative")
 	}
-	return nil
+	if args.MinSimilarity < 0 || args.MinSimilarity > 1 {
+		return invalidParamsf("min_similarity must be between 0 and 1")
+	}
+	switch args.TagsMatch {
+	case "", TagsMatchAny, TagsMatchAll:
+	default:
+		return invalidParamsf("invalid tags_match %q: must be any or all", args.TagsMatch)
+	}
+	return validateAuthorType(args.AuthorType)
 }
 
-// resolveStoreForID returns the MemoryStore that owns the given memory ID.
-// Memory IDs follow the format "mem:<connection>:<hash>", so the connection
-// name is encoded directly in the ID. When the connection matches a known
-// entry in the connection manager its store is returned; otherwise the
-// default store is used as a fallback.
-func (s *Server) resolveStoreForID(id string) storage.MemoryStore {
-	if s.connectionManager == nil {
-		return s.memoryStore
+// validateAuthorType rejects author_type values other than the known
+// attribution types. An empty value is accepted and means "unset".
+func validateAuthorType(authorType string) error {
+	if authorType != "" && !attribution.IsValidAuthorType(authorType) {
+		return invalidParamsf("author_type must be one of human, agent, system; got %q", authorType)
 	}
-	parts := strings.SplitN(id, ":", 3)
-	if len(parts) != 3 || parts[0] != "mem" || parts[1] == "general" {
+	return nil
+}
+
+// resolveStoreForID returns the MemoryStore that owns the given memory ID,
+// as named by connectionForID. When the connection is unknown the default
+// store is used as a fallback.
+func (s *Server) resolveStoreForID(ctx context.Context, id string) storage.MemoryStore {
+	conn := s.connectionForID(ctx, id)
+	if conn == "" {
 		return s.memoryStore
 	}
-	if store, err := s.connectionManager.GetStore(parts[1]); err == nil {
+	if store, err := s.connectionManager.GetStore(conn); err == nil {
 		return store
 	}
 	return s.memoryStore
 }
 
+// connectionForID returns the name of the connection that owns the given
+// memory ID, or "" for the default store. With opaque IDs enabled the route
+// table is authoritative. IDs it has no route for (and every ID in the
+// deterministic scheme) follow the format "<prefix>:<connection>:<hash>",
+// where prefix is "mem" or the connection's id_prefix, so the connection
+// name is parsed from the ID. Failing that, an ID starting with a
+// connection's id_prefix, such as an opaque "<prefix>:<uuid>", belongs to
+// that connection.
+func (s *Server) connectionForID(ctx context.Context, id string) string {
+	if s.connectionManager == nil {
+		return ""
+	}
+	if s.routes != nil {
+		conn, err := s.routes.Lookup(ctx, id)
+		if err == nil {
+			return conn
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			slog.Warn("memory routes: falling back to the ID prefix", "error", err)
+		}
+	}
+	parts := strings.SplitN(id, ":", 3)
+	if len(parts) == 3 && (parts[0] == connections.DefaultIDPrefix || parts[0] == s.connectionManager.IDPrefix(parts[1])) {
+		if parts[1] == "general" {
+			return ""
+		}
+		return parts[1]
+	}
+	conn, _ := s.connectionManager.ConnectionForIDPrefix(parts[0])
+	return conn
+}
+
+// recordRoute stores the connection that owns a newly generated memory ID.
+// It is a no-op unless opaque IDs are enabled. Callers record the route
+// before storing the memory so a stored memory is never left unroutable.
+func (s *Server) recordRoute(ctx context.Context, id, conn string) error {
+	if s.routes == nil {
+		return nil
+	}
+	return s.routes.Set(ctx, id, conn)
+}
+
 // resolveSearchStore returns the MemoryStore and SearchProvider for a given
 // connection ID. Resolution priority:
 //  1. connectionID argument (explicit per-call override)
@@ -2213,6 +4506,7 @@ func (s *Server) resolveSearchStore(connectionID string) (storage.MemoryStore, s
 	}
 	store, err := s.connectionManager.GetStore(name)
 	if err != nil {
+		slog.Warn("falling back to the default store", "connection", name, "error", err)
 		return s.memoryStore, s.searchProvider
 	}
 	var sp storage.SearchProvider
@@ -2224,18 +4518,78 @@ func (s *Server) resolveSearchStore(connectionID string) (storage.MemoryStore, s
 	return store, sp
 }
 
+// memoryIDNamespace is the UUID namespace for opaque memory IDs.
+var memoryIDNamespace = uuid.MustParse("6f0c43a2-5a8e-4c1e-9d3b-2f7a1e8c9b40")
+
+// searchConnectionName returns the name of the connection resolveSearchStore
+// picks for connectionID, or "" when it falls back to the default store.
+func (s *Server) searchConnectionName(connectionID string) string {
+	name := connectionID
+	if name == "" {
+		name = s.defaultConnection
+	}
+	if name == "" || s.connectionManager == nil {
+		return ""
+	}
+	if _, err := s.connectionManager.GetStore(name); err != nil {
+		return ""
+	}
+	return name
+}
+
 // generateMemoryID generates a deterministic memory ID from the content.
 // Using a content hash means duplicate stores of the same text produce the
 // same ID. Since Store() has upsert semantics, the second call is a no-op
-// rather than creating a duplicate row.
+// rather than creating a duplicate row. The content is normalized first
+// according to WithDedupNormalization.
 // Format: mem:domain:<first 16 hex chars of SHA-256(content)>
+//
+// With opaque IDs enabled (WithOpaqueIDs) the format is mem:<uuid>, where
+// the UUID is a name-based (SHA-1) UUID of the domain and content, so
+// deduplication still works but the ID does not reveal the domain.
+//
+// A connection with an id_prefix uses it in place of "mem".
 func (s *Server) generateMemoryID(domain, content string) string {
+	scheme := config.MemoryIDSchemeDeterministic
+	if s.routes != nil {
+		scheme = config.MemoryIDSchemeOpaque
+	}
+	return memoryID(scheme, s.dedupNormalization, s.idHash, s.idPrefix(domain), domain, content)
+}
+
+// idPrefix returns the prefix of memory IDs generated for the named
+// connection (see connections.Connection.IDPrefix).
+func (s *Server) idPrefix(connection string) string {
+	if s.connectionManager == nil {
+		return connections.DefaultIDPrefix
+	}
+	return s.connectionManager.IDPrefix(connection)
+}
+
+// MemoryID returns the ID store_memory assigns to content stored under
+// domain (the connection name; empty means "general") with the given ID
+// scheme, ID prefix (empty means connections.DefaultIDPrefix) and dedup
+// normalization level. Tools that move memories between connections, such
+// as memento-import, use it to re-key them.
+func MemoryID(scheme, normalization, prefix, domain, content string) string {
+	return memoryID(scheme, normalization, sha256Hash, prefix, domain, content)
+}
+
+// memoryID is MemoryID with the hash of deterministic IDs as a parameter,
+// so tests can force collisions (see withMemoryIDHash).
+func memoryID(scheme, normalization string, hash contentHashFunc, prefix, domain, content string) string {
 	if domain == "" {
 		domain = "general"
 	}
-	h := sha256.Sum256([]byte(content))
-	slug := fmt.Sprintf("%x", h[:8]) // 16 hex chars
-	return fmt.Sprintf("mem:%s:%s", domain, slug)
+	if prefix == "" {
+		prefix = connections.DefaultIDPrefix
+	}
+	content = storage.NormalizeForDedup(normalization, content)
+	if scheme == config.MemoryIDSchemeOpaque {
+		return prefix + ":" + uuid.NewSHA1(memoryIDNamespace, []byte(domain+"\x00"+content)).String()
+	}
+	slug := fmt.Sprintf("%x", hash([]byte(content))[:8]) // 16 hex chars
+	return fmt.Sprintf("%s:%s:%s", prefix, domain, slug)
 }
 
 // unmarshalParams unmarshals JSON-RPC parameters into a typed struct.
@@ -2246,7 +4600,7 @@ func (s *Server) unmarshalParams(params interface{}, dest interface{}) error {
 	}
 
 	if err := json.Unmarshal(data, dest); err != nil {
-		return fmt.Errorf("failed to unmarshal params: %w", err)
+		return invalidParamsf("failed to unmarshal params: %v", err)
 	}
 
 	return nil
@@ -1,9 +1,11 @@
 package mcp_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/logging"
 	"github.com/scrypster/memento/internal/storage/sqlite"
 	"github.com/scrypster/memento/pkg/types"
 )
@@ -354,6 +357,105 @@ func TestHandleUpdateMemory_MissingID(t *testing.T) {
 	assert.Contains(t, string(resp), `"error"`)
 }
 
+// newUpdateTestMemory stores a memory with tags and metadata for the
+// incremental update tests and returns the server, store and memory ID.
+func newUpdateTestMemory(t *testing.T) (*mcp.Server, *sqlite.MemoryStore, string) {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	srv := mcp.NewServer(store)
+	result, err := srv.StoreMemory(context.Background(), mcp.StoreMemoryArgs{
+		Content:  "incremental update target",
+		Tags:     []string{"alpha", "beta"},
+		Metadata: map[string]interface{}{"owner": "ana", "priority": "low"},
+	})
+	require.NoError(t, err)
+	return srv, store, result.ID
+}
+
+// TestHandleUpdateMemory_MetadataMerge verifies that metadata_merge adds and
+// overwrites keys without dropping the others, and deletes keys set to null.
+func TestHandleUpdateMemory_MetadataMerge(t *testing.T) {
+	srv, store, memID := newUpdateTestMemory(t)
+	ctx := context.Background()
+
+	req := fmt.Sprintf(
+		`{"jsonrpc":"2.0","method":"update_memory","params":{"id":%q,"metadata_merge":true,"metadata":{"priority":"high","sprint":"42","owner":null}},"id":1}`,
+		memID,
+	)
+	resp, err := srv.HandleRequest(ctx, []byte(req))
+	require.NoError(t, err)
+	assert.NotContains(t, string(resp), `"error"`)
+
+	mem, err := store.Get(ctx, memID)
+	require.NoError(t, err)
+	assert.Equal(t, "high", mem.Metadata["priority"])
+	assert.Equal(t, "42", mem.Metadata["sprint"])
+	assert.NotContains(t, mem.Metadata, "owner")
+}
+
+// TestHandleUpdateMemory_MetadataReplaceByDefault verifies that metadata
+// without metadata_merge still replaces the whole map.
+func TestHandleUpdateMemory_MetadataReplaceByDefault(t *testing.T) {
+	srv, store, memID := newUpdateTestMemory(t)
+	ctx := context.Background()
+
+	_, err := srv.UpdateMemory(ctx, mcp.UpdateMemoryArgs{
+		ID:       memID,
+		Metadata: map[string]interface{}{"sprint": "42"},
+	})
+	require.NoError(t, err)
+
+	mem, err := store.Get(ctx, memID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"sprint": "42"}, mem.Metadata)
+}
+
+// TestHandleUpdateMemory_TagsAppendAndRemove verifies the append and remove
+// tag modes, including that append does not duplicate existing tags.
+func TestHandleUpdateMemory_TagsAppendAndRemove(t *testing.T) {
+	srv, store, memID := newUpdateTestMemory(t)
+	ctx := context.Background()
+
+	_, err := srv.UpdateMemory(ctx, mcp.UpdateMemoryArgs{
+		ID:       memID,
+		Tags:     []string{"beta", "gamma"},
+		TagsMode: mcp.TagsModeAppend,
+	})
+	require.NoError(t, err)
+
+	mem, err := store.Get(ctx, memID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "beta", "gamma"}, mem.Tags)
+
+	_, err = srv.UpdateMemory(ctx, mcp.UpdateMemoryArgs{
+		ID:       memID,
+		Tags:     []string{"alpha", "missing"},
+		TagsMode: mcp.TagsModeRemove,
+	})
+	require.NoError(t, err)
+
+	mem, err = store.Get(ctx, memID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"beta", "gamma"}, mem.Tags)
+}
+
+// TestHandleUpdateMemory_InvalidTagsMode verifies that an unknown tags_mode
+// is rejected as invalid params.
+func TestHandleUpdateMemory_InvalidTagsMode(t *testing.T) {
+	srv, _, memID := newUpdateTestMemory(t)
+
+	req := fmt.Sprintf(
+		`{"jsonrpc":"2.0","method":"update_memory","params":{"id":%q,"tags":["x"],"tags_mode":"prepend"},"id":1}`,
+		memID,
+	)
+	resp, err := srv.HandleRequest(context.Background(), []byte(req))
+	require.NoError(t, err)
+	assert.Contains(t, string(resp), `"code":-32602`)
+}
+
 // ---------------------------------------------------------------------------
 // Tests for handleRestoreMemory (restore_memory JSON-RPC method)
 // ---------------------------------------------------------------------------
@@ -861,3 +963,33 @@ func TestDispatchErrors_InvalidParams(t *testing.T) {
 	// May succeed or error depending on unmarshal tolerance
 	assert.NotEmpty(t, resp)
 }
+
+// TestToolsCall_Logging verifies that tools/call logs the tool as op with the
+// connection and memory_id fields, at debug unless the server failed.
+func TestToolsCall_Logging(t *testing.T) {
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	var buf bytes.Buffer
+	slog.SetDefault(logging.New(&buf, slog.LevelDebug))
+
+	store := newMockStore()
+	srv := mcp.NewServer(store)
+
+	result := callToolRaw(t, srv, "recall_memory", map[string]interface{}{"connection_id": "work", "limit": 5})
+	require.False(t, result.IsError, result.Content)
+	assert.Contains(t, buf.String(), `level=DEBUG msg="tool call" op=recall_memory`)
+	assert.Contains(t, buf.String(), "connection=work")
+
+	buf.Reset()
+	result = callToolRaw(t, srv, "forget_memory", map[string]interface{}{"id": "mem:general:missing"})
+	require.True(t, result.IsError)
+	assert.Contains(t, buf.String(), `level=DEBUG msg="tool call rejected" op=forget_memory`)
+	assert.Contains(t, buf.String(), "memory_id=mem:general:missing")
+
+	buf.Reset()
+	store.listErr = fmt.Errorf("disk on fire")
+	result = callToolRaw(t, srv, "recall_memory", map[string]interface{}{})
+	require.True(t, result.IsError)
+	assert.Contains(t, buf.String(), `level=WARN msg="tool call failed" op=recall_memory`)
+	assert.Contains(t, buf.String(), "disk on fire")
+}
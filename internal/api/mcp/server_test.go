@@ -2,6 +2,7 @@ package mcp_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/scrypster/memento/internal/api/mcp"
 	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/engine"
 	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/internal/storage/sqlite"
 	"github.com/scrypster/memento/pkg/types"
@@ -24,6 +26,12 @@ type mockStore struct {
 	// filterFn is an optional predicate applied during List calls.
 	// When nil all memories are returned.
 	filterFn func(mem *types.Memory, opts storage.ListOptions) bool
+	// listErr, when set, is returned by List to simulate a storage failure.
+	listErr error
+	// storeErr, when set, is returned by Store to simulate a write failure.
+	storeErr error
+	// blockList makes List wait for ctx to be done, simulating a stalled store.
+	blockList bool
 }
 
 func newMockStore() *mockStore {
@@ -31,6 +39,9 @@ func newMockStore() *mockStore {
 }
 
 func (m *mockStore) Store(_ context.Context, memory *types.Memory) error {
+	if m.storeErr != nil {
+		return m.storeErr
+	}
 	m.memories[memory.ID] = memory
 	return nil
 }
@@ -47,7 +58,14 @@ func (m *mockStore) Get(_ context.Context, id string) (*types.Memory, error) {
 	return mem, nil
 }
 
-func (m *mockStore) List(_ context.Context, opts storage.ListOptions) (*storage.PaginatedResult[types.Memory], error) {
+func (m *mockStore) List(ctx context.Context, opts storage.ListOptions) (*storage.PaginatedResult[types.Memory], error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	if m.blockList {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
 	var items []types.Memory
 	for _, mem := range m.memories {
 		if m.filterFn != nil && !m.filterFn(mem, opts) {
@@ -156,7 +174,7 @@ func (m *mockStore) GetRelatedMemories(_ context.Context, memoryID string) ([]st
 	return []string{}, nil
 }
 
-func (m *mockStore) Traverse(_ context.Context, startMemoryID string, maxHops int, limit int) ([]storage.TraversalResult, error) {
+func (m *mockStore) Traverse(_ context.Context, startMemoryID string, opts storage.TraversalOptions) ([]storage.TraversalResult, error) {
 	return nil, nil
 }
 
@@ -164,10 +182,26 @@ func (m *mockStore) GetMemoryEntities(_ context.Context, memoryID string) ([]*ty
 	return nil, nil
 }
 
+func (m *mockStore) ListEntities(_ context.Context, _ storage.EntityListOptions) (*storage.PaginatedResult[types.Entity], error) {
+	return &storage.PaginatedResult[types.Entity]{}, nil
+}
+
 func (m *mockStore) UpdateDecayScores(_ context.Context) (int, error) {
 	return 0, nil
 }
 
+func (m *mockStore) SetEmbedding(_ context.Context, _ string, _ []float64, _ string) error {
+	return nil
+}
+
+func (m *mockStore) GetEmbedding(_ context.Context, _ string) ([]float64, string, error) {
+	return nil, "", storage.ErrNotFound
+}
+
+func (m *mockStore) ExpireMemories(_ context.Context, _ time.Time) (int, error) {
+	return 0, nil
+}
+
 func (m *mockStore) Restore(_ context.Context, id string) error {
 	mem, ok := m.memories[id]
 	if !ok {
@@ -521,6 +555,40 @@ func TestUpdateMemoryState_InvalidState(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid state")
 }
 
+// TestUpdateMemoryState_CustomStateMachine verifies that states and
+// transitions come from the store's state machine when it has one.
+func TestUpdateMemoryState_CustomStateMachine(t *testing.T) {
+	machine := &types.StateMachine{
+		States:      []string{"todo", "review", "done"},
+		Initial:     []string{"todo"},
+		Transitions: map[string][]string{"todo": {"review"}, "review": {"todo", "done"}},
+	}
+	store, err := sqlite.NewMemoryStore(":memory:", sqlite.WithStateMachine(machine))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	stored, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Write the release notes"})
+	require.NoError(t, err)
+
+	for _, state := range []string{"todo", "review"} {
+		var result mcp.UpdateMemoryStateResult
+		callRPC(t, srv, "update_memory_state", map[string]interface{}{"id": stored.ID, "state": state}, &result)
+		assert.Equal(t, state, result.NewState)
+	}
+
+	// Built-in states are not part of the custom machine.
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv,
+		`{"jsonrpc":"2.0","method":"update_memory_state","params":{"id":"`+stored.ID+`","state":"active"},"id":1}`))
+
+	_, err = srv.UpdateMemoryState(ctx, mcp.UpdateMemoryStateArgs{ID: stored.ID, State: "done"})
+	require.NoError(t, err)
+	_, err = srv.UpdateMemoryState(ctx, mcp.UpdateMemoryStateArgs{ID: stored.ID, State: "todo"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid state transition")
+}
+
 // TestUpdateMemoryState_MissingID returns error when ID is empty.
 func TestUpdateMemoryState_MissingID(t *testing.T) {
 	store := newMockStore()
@@ -611,6 +679,38 @@ func TestRecallMemory_IDLookup_BackwardCompat(t *testing.T) {
 	assert.Equal(t, 0, result.Total)
 }
 
+// TestRecallMemory_EnrichmentSummary verifies that recall reports which
+// enrichment steps are still outstanding, by ID and in list mode.
+func TestRecallMemory_EnrichmentSummary(t *testing.T) {
+	store := newMockStore()
+	mem := &types.Memory{
+		ID:                   "mem:test:partial",
+		Content:              "partially enriched",
+		Status:               types.StatusProcessing,
+		EntityStatus:         types.EnrichmentCompleted,
+		RelationshipStatus:   types.EnrichmentCompleted,
+		ClassificationStatus: types.EnrichmentCompleted,
+		SummarizationStatus:  types.EnrichmentFailed,
+		EmbeddingStatus:      types.EnrichmentPending,
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
+	}
+	store.memories[mem.ID] = mem
+
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+	want := "3/5 complete, summarization failed, embedding pending"
+
+	result, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{ID: mem.ID})
+	require.NoError(t, err)
+	assert.Equal(t, want, result.EnrichmentSummary)
+	assert.Equal(t, types.EnrichmentPending, result.Memory.EmbeddingStatus)
+
+	list, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{mem.ID: want}, list.EnrichmentSummaries)
+}
+
 // TestRecallMemory_IDLookup_NotFound verifies that a missing ID returns
 // Found=false (existing behaviour preserved).
 func TestRecallMemory_IDLookup_NotFound(t *testing.T) {
@@ -812,6 +912,70 @@ func TestRecallMemory_FilterByMinDecayScore(t *testing.T) {
 	}
 }
 
+// TestRecallMemory_DecayScoreRange lists memories within a decay score range
+// and rejects a range whose minimum exceeds its maximum.
+func TestRecallMemory_DecayScoreRange(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	for id, decay := range map[string]float64{"mem:general:cold": 0.1, "mem:general:cool": 0.4, "mem:general:warm": 0.8} {
+		require.NoError(t, store.Store(ctx, &types.Memory{ID: id, Content: "decay " + id, Source: "test", DecayScore: decay}))
+	}
+	srv := mcp.NewServer(store)
+
+	result, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{MaxDecayScore: 0.4, SortBy: "decay_score", SortOrder: "asc"})
+	require.NoError(t, err)
+	require.Len(t, result.Memories, 2)
+	assert.Equal(t, "mem:general:cold", result.Memories[0].ID)
+	assert.Equal(t, "mem:general:cool", result.Memories[1].ID)
+
+	result, err = srv.RecallMemory(ctx, mcp.RecallMemoryArgs{MinDecayScore: 0.2, MaxDecayScore: 0.9})
+	require.NoError(t, err)
+	require.Len(t, result.Memories, 2)
+	assert.Equal(t, 2, result.Total)
+
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"recall_memory","params":{"min_decay_score":0.8,"max_decay_score":0.2},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, code)
+}
+
+// TestRecallMemory_AccessedWindow lists memories last accessed within a
+// window, skipping ones never accessed, and rejects a malformed bound.
+func TestRecallMemory_AccessedWindow(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	old, recent := now.Add(-72*time.Hour), now.Add(-2*time.Hour)
+	for id, accessed := range map[string]*time.Time{"mem:general:never": nil, "mem:general:old": &old, "mem:general:recent": &recent} {
+		require.NoError(t, store.Store(ctx, &types.Memory{ID: id, Content: "accessed " + id, Source: "test", LastAccessedAt: accessed}))
+	}
+	srv := mcp.NewServer(store)
+
+	result, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{AccessedAfter: now.Add(-24 * time.Hour).Format(time.RFC3339)})
+	require.NoError(t, err)
+	require.Len(t, result.Memories, 1)
+	assert.Equal(t, "mem:general:recent", result.Memories[0].ID)
+
+	result, err = srv.RecallMemory(ctx, mcp.RecallMemoryArgs{AccessedBefore: now.Add(-24 * time.Hour).Format(time.RFC3339)})
+	require.NoError(t, err)
+	require.Len(t, result.Memories, 1)
+	assert.Equal(t, "mem:general:old", result.Memories[0].ID)
+
+	result, err = srv.RecallMemory(ctx, mcp.RecallMemoryArgs{
+		AccessedAfter:  now.Add(-96 * time.Hour).Format(time.RFC3339),
+		AccessedBefore: now.Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"recall_memory","params":{"accessed_after":"yesterday"},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, code)
+	code = rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"recall_memory","params":{"accessed_after":"2026-02-01T00:00:00Z","accessed_before":"2026-01-01T00:00:00Z"},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, code)
+}
+
 // TestRecallMemory_InvalidTemporalBounds returns an error when CreatedAfter
 // is set to a time after CreatedBefore.
 func TestRecallMemory_InvalidTemporalBounds(t *testing.T) {
@@ -1256,6 +1420,78 @@ func TestGetSessionContext_TopicSummary(t *testing.T) {
 	assert.Contains(t, result.Summary, "product")
 }
 
+// TestGetSessionContext_AcrossSessions verifies that omitting session_id and
+// giving a time window aggregates every session in range, grouped by session.
+func TestGetSessionContext_AcrossSessions(t *testing.T) {
+	store := newMockStore()
+	store.filterFn = func(m *types.Memory, opts storage.ListOptions) bool {
+		if !opts.CreatedAfter.IsZero() && !m.CreatedAt.After(opts.CreatedAfter) {
+			return false
+		}
+		return opts.CreatedBefore.IsZero() || m.CreatedAt.Before(opts.CreatedBefore)
+	}
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	now := time.Now()
+	add := func(id, session, domain string, age time.Duration) {
+		store.memories[id] = &types.Memory{
+			ID:        id,
+			Content:   id,
+			Domain:    domain,
+			SessionID: session,
+			CreatedAt: now.Add(-age),
+			UpdatedAt: now.Add(-age),
+		}
+	}
+	add("mem:general:mon-1", "monday", "engineering", 72*time.Hour)
+	add("mem:general:mon-2", "monday", "product", 71*time.Hour)
+	add("mem:general:wed-1", "wednesday", "engineering", 24*time.Hour)
+	add("mem:general:old", "last-month", "engineering", 30*24*time.Hour)
+
+	result, err := srv.GetSessionContext(ctx, mcp.GetSessionContextArgs{TimeWindowH: 7 * 24})
+	require.NoError(t, err)
+
+	assert.Empty(t, result.SessionID)
+	assert.Equal(t, 3, result.MemoryCount)
+	require.Len(t, result.Sessions, 2)
+
+	// Most recently active session first.
+	assert.Equal(t, "wednesday", result.Sessions[0].SessionID)
+	assert.Equal(t, 1, result.Sessions[0].MemoryCount)
+	assert.Equal(t, "monday", result.Sessions[1].SessionID)
+	assert.Equal(t, 2, result.Sessions[1].MemoryCount)
+	assert.Equal(t, now.Add(-72*time.Hour).Format(time.RFC3339), result.Sessions[1].FirstActivity)
+	assert.Equal(t, now.Add(-71*time.Hour).Format(time.RFC3339), result.Sessions[1].LastActivity)
+	assert.Contains(t, result.Sessions[1].Summary, "2 memories in this session")
+
+	// Overall topic breakdown spans all sessions.
+	require.NotEmpty(t, result.Topics)
+	assert.Equal(t, "engineering", result.Topics[0].Domain)
+	assert.Equal(t, 2, result.Topics[0].Count)
+	assert.Contains(t, result.Summary, "3 memories across 2 sessions")
+
+	// An explicit date range works the same way.
+	result, err = srv.GetSessionContext(ctx, mcp.GetSessionContextArgs{
+		CreatedAfter:  now.Add(-40 * 24 * time.Hour).Format(time.RFC3339),
+		CreatedBefore: now.Add(-48 * time.Hour).Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Sessions, 2)
+	assert.Equal(t, "monday", result.Sessions[0].SessionID)
+	assert.Equal(t, "last-month", result.Sessions[1].SessionID)
+
+	// With session_id the time window stays scoped to that session.
+	result, err = srv.GetSessionContext(ctx, mcp.GetSessionContextArgs{SessionID: "monday", TimeWindowH: 7 * 24})
+	require.NoError(t, err)
+	assert.Equal(t, "monday", result.SessionID)
+	assert.Equal(t, 2, result.MemoryCount)
+	assert.Empty(t, result.Sessions)
+
+	_, err = srv.GetSessionContext(ctx, mcp.GetSessionContextArgs{CreatedAfter: "last week"})
+	require.Error(t, err)
+}
+
 // TestGetSessionContext_EmptySession verifies that an empty session returns
 // an empty memory list and a sensible summary.
 func TestGetSessionContext_EmptySession(t *testing.T) {
@@ -1325,6 +1561,339 @@ func TestStoreMemory_SessionIDOverride(t *testing.T) {
 	assert.Equal(t, customSession, stored.SessionID)
 }
 
+// TestStoreMemory_ExpiresAt verifies that expires_at is stored on the memory
+// and that recall hides the memory once it has expired unless
+// include_expired is set.
+func TestStoreMemory_ExpiresAt(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	res, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{
+		Content:   "temporary deploy freeze until the incident closes",
+		ExpiresAt: expiresAt.Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+
+	stored, err := store.Get(ctx, res.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.ExpiresAt)
+	assert.True(t, stored.ExpiresAt.Equal(expiresAt), "ExpiresAt = %v, want %v", stored.ExpiresAt, expiresAt)
+
+	// Backdate the expiry so the memory is expired but not yet swept.
+	past := time.Now().Add(-time.Minute)
+	stored.ExpiresAt = &past
+	require.NoError(t, store.Store(ctx, stored))
+
+	listed, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{})
+	require.NoError(t, err)
+	assert.Empty(t, listed.Memories)
+
+	searched, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{Query: "deploy freeze"})
+	require.NoError(t, err)
+	assert.Empty(t, searched.Memories)
+
+	listed, err = srv.RecallMemory(ctx, mcp.RecallMemoryArgs{IncludeExpired: true})
+	require.NoError(t, err)
+	require.Len(t, listed.Memories, 1)
+	assert.Equal(t, res.ID, listed.Memories[0].ID)
+
+	searched, err = srv.RecallMemory(ctx, mcp.RecallMemoryArgs{Query: "deploy freeze", IncludeExpired: true})
+	require.NoError(t, err)
+	assert.Len(t, searched.Memories, 1)
+}
+
+// TestStoreMemory_InvalidExpiresAt verifies that malformed and past expiry
+// times are rejected with invalid params.
+func TestStoreMemory_InvalidExpiresAt(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	for _, expiresAt := range []string{"tomorrow", time.Now().Add(-time.Hour).Format(time.RFC3339)} {
+		req := fmt.Sprintf(
+			`{"jsonrpc":"2.0","method":"store_memory","params":{"content":"x","expires_at":%q},"id":1}`,
+			expiresAt,
+		)
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), "expires_at=%q", expiresAt)
+	}
+}
+
+// TestAuthorType_StoreAndFilter verifies that store_memory records an
+// author_type and that recall_memory and find_related filter on it.
+func TestAuthorType_StoreAndFilter(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	human, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{
+		Content:    "hand-written note about the kestrel migration",
+		AuthorType: "human",
+	})
+	require.NoError(t, err)
+	agent, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{
+		Content:   "agent summary of the kestrel migration",
+		CreatedBy: "claude",
+	})
+	require.NoError(t, err)
+
+	stored, err := store.Get(ctx, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "agent", stored.AuthorType)
+
+	listed, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{AuthorType: "human"})
+	require.NoError(t, err)
+	require.Len(t, listed.Memories, 1)
+	assert.Equal(t, human.ID, listed.Memories[0].ID)
+
+	related, err := srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "kestrel", AuthorType: "agent"})
+	require.NoError(t, err)
+	require.Len(t, related.Memories, 1)
+	assert.Equal(t, agent.ID, related.Memories[0].ID)
+
+	searched, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{Query: "kestrel", AuthorType: "system"})
+	require.NoError(t, err)
+	assert.Empty(t, searched.Memories)
+}
+
+// TestFindRelated_IncludesSnippet verifies that find_related results carry a
+// marked excerpt around the matched term.
+func TestFindRelated_IncludesSnippet(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	filler := strings.Repeat("routine standup notes about sprint scope ", 40)
+	_, err = srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: filler + "the kestrel rollout slipped a week " + filler})
+	require.NoError(t, err)
+
+	result, err := srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "kestrel"})
+	require.NoError(t, err)
+	require.Len(t, result.Memories, 1)
+	assert.Contains(t, result.Memories[0].Snippet, "**kestrel**")
+	assert.Less(t, len(result.Memories[0].Snippet), len(result.Memories[0].Content))
+}
+
+// TestFindRelated_TotalMatches verifies that find_related reports how many
+// memories matched beyond the limit, separately from how many it returned.
+func TestFindRelated_TotalMatches(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: fmt.Sprintf("osprey migration step %d", i)})
+		require.NoError(t, err)
+	}
+	_, err = srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "unrelated lunch order"})
+	require.NoError(t, err)
+
+	result, err := srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "osprey", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	assert.Len(t, result.Memories, 2)
+	assert.Equal(t, 5, result.TotalMatches)
+
+	// Post-search filters shrink what is returned, not what matched.
+	result, err = srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "osprey", Limit: 2, Domain: "elsewhere"})
+	require.NoError(t, err)
+	assert.Zero(t, result.Total)
+	assert.Equal(t, 5, result.TotalMatches)
+}
+
+// TestFindRelated_Tags verifies that a tag-constrained search excludes
+// memories whose content matches but which lack the tags, that the
+// constraint is applied by the search rather than to the top results, and
+// that it combines with the other filters.
+func TestFindRelated_Tags(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: fmt.Sprintf("heron deploy checklist item %d", i)})
+		require.NoError(t, err)
+	}
+	projectX, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "heron deploy for project x", Tags: []string{"project-x"}, Domain: "ops"})
+	require.NoError(t, err)
+	both, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "heron deploy for project x rollback", Tags: []string{"project-x", "urgent"}})
+	require.NoError(t, err)
+
+	ids := func(result *mcp.FindRelatedResult) []string {
+		var out []string
+		for _, m := range result.Memories {
+			out = append(out, m.ID)
+		}
+		return out
+	}
+
+	result, err := srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "heron", Limit: 2, Tags: []string{"project-x"}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{projectX.ID, both.ID}, ids(result))
+	assert.Equal(t, 2, result.TotalMatches)
+
+	result, err = srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "heron", Tags: []string{"urgent", "missing"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{both.ID}, ids(result))
+
+	result, err = srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "heron", Tags: []string{"project-x", "urgent"}, TagsMatch: mcp.TagsMatchAll})
+	require.NoError(t, err)
+	assert.Equal(t, []string{both.ID}, ids(result))
+
+	result, err = srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "heron", Tags: []string{"project-x"}, Domain: "ops"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{projectX.ID}, ids(result))
+
+	_, err = srv.FindRelated(ctx, mcp.FindRelatedArgs{Query: "heron", Tags: []string{"project-x"}, TagsMatch: "some"})
+	require.Error(t, err)
+}
+
+// TestFindRelated_Metadata verifies that the metadata filter keeps only
+// memories with equal values for every key, in the search and the
+// list-then-filter fallback, and that recall_memory applies it in both
+// query and list mode.
+func TestFindRelated_Metadata(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	atlas, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "heron deploy for atlas", Metadata: map[string]interface{}{"project": "atlas", "priority": 1, "urgent": true}})
+	require.NoError(t, err)
+	zeus, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "heron deploy for zeus", Metadata: map[string]interface{}{"project": "zeus", "priority": "1"}})
+	require.NoError(t, err)
+	_, err = srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "heron deploy without metadata"})
+	require.NoError(t, err)
+
+	var found mcp.FindRelatedResult
+	callRPC(t, srv, "find_related", map[string]interface{}{"query": "heron", "metadata": map[string]interface{}{"project": "atlas"}}, &found)
+	require.Equal(t, 1, found.Total)
+	assert.Equal(t, atlas.ID, found.Memories[0].ID)
+	assert.Equal(t, 1, found.TotalMatches)
+
+	callRPC(t, srv, "find_related", map[string]interface{}{"query": "heron", "metadata": map[string]interface{}{"priority": "1"}}, &found)
+	require.Equal(t, 1, found.Total)
+	assert.Equal(t, zeus.ID, found.Memories[0].ID)
+
+	var recalled mcp.RecallMemoryResult
+	callRPC(t, srv, "recall_memory", map[string]interface{}{"query": "heron", "metadata": map[string]interface{}{"priority": 1, "urgent": true}}, &recalled)
+	require.Len(t, recalled.Memories, 1)
+	assert.Equal(t, atlas.ID, recalled.Memories[0].ID)
+
+	callRPC(t, srv, "recall_memory", map[string]interface{}{"metadata": map[string]interface{}{"project": "zeus"}}, &recalled)
+	require.Len(t, recalled.Memories, 1)
+	assert.Equal(t, zeus.ID, recalled.Memories[0].ID)
+	assert.Equal(t, 1, recalled.Total)
+
+	// Without a search provider the filter is applied after listing.
+	mock := newMockStore()
+	fallback := mcp.NewServer(mock)
+	kept, err := fallback.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "heron notes for atlas", Metadata: map[string]interface{}{"project": "atlas"}})
+	require.NoError(t, err)
+	_, err = fallback.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "heron notes for zeus", Metadata: map[string]interface{}{"project": "zeus"}})
+	require.NoError(t, err)
+	result, err := fallback.FindRelated(ctx, mcp.FindRelatedArgs{Query: "heron", Metadata: map[string]interface{}{"project": "atlas"}})
+	require.NoError(t, err)
+	require.Len(t, result.Memories, 1)
+	assert.Equal(t, kept.ID, result.Memories[0].ID)
+
+	for _, req := range []string{
+		`{"jsonrpc":"2.0","method":"find_related","params":{"query":"heron","metadata":{"project":{"name":"atlas"}}},"id":1}`,
+		`{"jsonrpc":"2.0","method":"find_related","params":{"query":"heron","metadata":{"":"atlas"}},"id":1}`,
+		`{"jsonrpc":"2.0","method":"recall_memory","params":{"metadata":{"project":null}},"id":1}`,
+		`{"jsonrpc":"2.0","method":"recall_memory","params":{"query":"heron","metadata":{"tags":["a"]}},"id":1}`,
+	} {
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), req)
+	}
+}
+
+// TestFindRelated_Fuzzy verifies that fuzzy returns memories with misspelled
+// words only when asked, and that WithFuzzySearch's threshold applies.
+func TestFindRelated_Fuzzy(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+	srv := mcp.NewServer(store)
+
+	_, err = srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Reindexed the elasticsearch cluster overnight"})
+	require.NoError(t, err)
+
+	var result mcp.FindRelatedResult
+	callRPC(t, srv, "find_related", map[string]interface{}{"query": "elasticserch"}, &result)
+	assert.Zero(t, result.Total)
+
+	callRPC(t, srv, "find_related", map[string]interface{}{"query": "elasticserch", "fuzzy": true}, &result)
+	require.Equal(t, 1, result.Total)
+	assert.Contains(t, result.Memories[0].Content, "elasticsearch")
+
+	strict := mcp.NewServer(store, mcp.WithFuzzySearch(0.95, 5))
+	strictResult, err := strict.FindRelated(ctx, mcp.FindRelatedArgs{Query: "elasticserch", Fuzzy: true})
+	require.NoError(t, err)
+	assert.Zero(t, strictResult.Total)
+}
+
+// embeddingEngine embeds every query as the same fixed vector.
+type embeddingEngine struct {
+	recordingEngine
+	vector []float64
+}
+
+func (e *embeddingEngine) Embed(context.Context, string) ([]float64, error) { return e.vector, nil }
+
+// TestFindRelated_MinSimilarity verifies that an unrelated query returns
+// nothing once weak semantic matches are cut off by min_similarity.
+func TestFindRelated_MinSimilarity(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	for id, vec := range map[string][]float64{
+		"mem:general:kestrel": {1, 0, 0},
+		"mem:general:budget":  {0.8, 0.6, 0},
+	} {
+		require.NoError(t, store.Store(ctx, &types.Memory{ID: id, Content: id + " notes", Source: "test"}))
+		require.NoError(t, store.SetEmbedding(ctx, id, vec, "test-model"))
+	}
+	srv := mcp.NewServer(store, mcp.WithEngine(&embeddingEngine{vector: []float64{0.1, 0, 1}}))
+
+	var loose mcp.FindRelatedResult
+	callRPC(t, srv, "find_related", map[string]interface{}{"query": "sourdough starter"}, &loose)
+	assert.Len(t, loose.Memories, 2, "without a cutoff weak semantic matches are returned")
+
+	var strict mcp.FindRelatedResult
+	callRPC(t, srv, "find_related", map[string]interface{}{"query": "sourdough starter", "min_similarity": 0.5}, &strict)
+	assert.Empty(t, strict.Memories)
+
+	req := `{"jsonrpc":"2.0","method":"find_related","params":{"query":"x","min_similarity":1.5},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req))
+}
+
+// TestAuthorType_Invalid verifies that unknown author_type values are
+// rejected with invalid params.
+func TestAuthorType_Invalid(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	for _, req := range []string{
+		`{"jsonrpc":"2.0","method":"store_memory","params":{"content":"x","author_type":"robot"},"id":1}`,
+		`{"jsonrpc":"2.0","method":"find_related","params":{"query":"x","author_type":"robot"},"id":1}`,
+		`{"jsonrpc":"2.0","method":"recall_memory","params":{"author_type":"robot"},"id":1}`,
+	} {
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), req)
+	}
+}
+
 // TestHandleRequest_GetSessionContext verifies get_session_context via the
 // JSON-RPC handler (tools/call path).
 func TestHandleRequest_GetSessionContext(t *testing.T) {
@@ -1510,6 +2079,52 @@ func TestConsolidateMemories_ByIDs(t *testing.T) {
 	assert.Error(t, err, "original memory should be soft-deleted")
 }
 
+// stallingEngine's Summarize blocks until timeout and then fails with
+// engine.ErrTimeout, as MemoryEngine does when the LLM exceeds LLMTimeout.
+type stallingEngine struct {
+	recordingEngine
+	timeout time.Duration
+}
+
+func (e *stallingEngine) Summarize(ctx context.Context, _ string) (string, error) {
+	select {
+	case <-time.After(e.timeout):
+		return "", fmt.Errorf("summarization exceeded %v: %w", e.timeout, engine.ErrTimeout)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// TestConsolidateMemories_SummarizeTimeout verifies that a summarization
+// call that times out falls back to concatenating the memories and that
+// consolidation returns promptly after the timeout.
+func TestConsolidateMemories_SummarizeTimeout(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	eng := &stallingEngine{recordingEngine: recordingEngine{queued: map[string]string{}}, timeout: 50 * time.Millisecond}
+	srv := mcp.NewServer(store, mcp.WithEngine(eng))
+	ctx := context.Background()
+
+	r1, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "First memory about Go programming"})
+	require.NoError(t, err)
+	r2, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Second memory about Go testing"})
+	require.NoError(t, err)
+
+	start := time.Now()
+	result, err := srv.ConsolidateMemories(ctx, mcp.ConsolidateMemoriesArgs{
+		IDs:   []string{r1.ID, r2.ID},
+		Title: "Go Development Notes",
+	})
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second, "consolidation must not wait on the stalled LLM")
+
+	assert.Equal(t, "# Go Development Notes\n\n[1] First memory about Go programming\n\n[2] Second memory about Go testing", result.Content)
+	assert.Contains(t, result.Message, "timed out")
+	assert.Contains(t, eng.queued, result.NewID, "the consolidated memory is still queued for enrichment")
+}
+
 // TestConsolidateMemories_RequiresMinTwo verifies minimum requirement of 2 memories.
 func TestConsolidateMemories_RequiresMinTwo(t *testing.T) {
 	store, err := sqlite.NewMemoryStore(":memory:")
@@ -1740,6 +2355,52 @@ func TestGetEvolutionChain(t *testing.T) {
 	assert.GreaterOrEqual(t, chain.TotalVersions, 2, "should have at least 2 versions in chain")
 }
 
+// TestGetEvolutionChain_DeletedVersion soft-deletes the middle of a
+// three-version chain: it is hidden by default and marked deleted with
+// include_deleted.
+func TestGetEvolutionChain_DeletedVersion(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	v1, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Deploy on Fridays"})
+	require.NoError(t, err)
+	v2, err := srv.EvolveMemory(ctx, mcp.EvolveMemoryArgs{ID: v1.ID, NewContent: "Deploy on Thursdays"})
+	require.NoError(t, err)
+	v3, err := srv.EvolveMemory(ctx, mcp.EvolveMemoryArgs{ID: v2.NewID, NewContent: "Deploy any weekday before 3pm"})
+	require.NoError(t, err)
+	_, err = srv.ForgetMemory(ctx, mcp.ForgetMemoryArgs{ID: v2.NewID})
+	require.NoError(t, err)
+
+	var hidden mcp.GetEvolutionChainResult
+	callRPC(t, srv, "get_evolution_chain", map[string]interface{}{"id": v3.NewID}, &hidden)
+	require.Len(t, hidden.Chain, 2)
+	assert.Equal(t, []string{v1.ID, v3.NewID}, []string{hidden.Chain[0].ID, hidden.Chain[1].ID})
+	assert.Equal(t, []int{1, 3}, []int{hidden.Chain[0].Position, hidden.Chain[1].Position}, "positions keep the gap")
+	assert.Equal(t, 1, hidden.DeletedVersions)
+	assert.Equal(t, v3.NewID, hidden.CurrentID)
+
+	var all mcp.GetEvolutionChainResult
+	callRPC(t, srv, "get_evolution_chain", map[string]interface{}{"id": v1.ID, "include_deleted": true}, &all)
+	require.Len(t, all.Chain, 3)
+	assert.Equal(t, v2.NewID, all.Chain[1].ID)
+	assert.True(t, all.Chain[1].Deleted)
+	assert.NotEmpty(t, all.Chain[1].DeletedAt)
+	assert.False(t, all.Chain[0].Deleted)
+	assert.False(t, all.Chain[2].Deleted)
+	assert.Equal(t, v3.NewID, all.CurrentID)
+
+	// Deleting the tip makes the newest live version current.
+	_, err = srv.ForgetMemory(ctx, mcp.ForgetMemoryArgs{ID: v3.NewID})
+	require.NoError(t, err)
+	chain, err := srv.GetEvolutionChain(ctx, mcp.GetEvolutionChainArgs{ID: v1.ID})
+	require.NoError(t, err)
+	assert.Equal(t, v1.ID, chain.CurrentID)
+	assert.Equal(t, 2, chain.DeletedVersions)
+}
+
 // ---------------------------------------------------------------------------
 // Tests for project management tools
 // ---------------------------------------------------------------------------
@@ -1798,6 +2459,193 @@ func TestAddProjectItem(t *testing.T) {
 	assert.Equal(t, "task", item.ItemType)
 }
 
+// newMoveTestProject creates a project with two phases and a task under the
+// first phase, returning the server, store, project ID, phase IDs and task ID.
+func newMoveTestProject(t *testing.T) (*mcp.Server, *sqlite.MemoryStore, string, []string, string) {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	proj, err := srv.CreateProject(ctx, mcp.CreateProjectArgs{
+		Name:       "Billing Revamp",
+		PhaseNames: []string{"Discovery", "Delivery"},
+	})
+	require.NoError(t, err)
+	require.Len(t, proj.PhaseIDs, 2)
+
+	task, err := srv.AddProjectItem(ctx, mcp.AddProjectItemArgs{
+		ParentID: proj.PhaseIDs[0],
+		ItemType: "task",
+		Name:     "Audit invoice templates",
+	})
+	require.NoError(t, err)
+
+	return srv, store, proj.ProjectID, proj.PhaseIDs, task.ID
+}
+
+// childIDs returns the IDs of the direct children of node.
+func childIDs(node mcp.ProjectTreeNode) []string {
+	ids := make([]string, 0, len(node.Children))
+	for _, c := range node.Children {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestMoveProjectItem(t *testing.T) {
+	srv, store, projectID, phaseIDs, taskID := newMoveTestProject(t)
+	ctx := context.Background()
+
+	result, err := srv.MoveProjectItem(ctx, mcp.MoveProjectItemArgs{
+		ItemID:      taskID,
+		NewParentID: phaseIDs[1],
+	})
+	require.NoError(t, err)
+	assert.Equal(t, taskID, result.ID)
+	assert.Equal(t, phaseIDs[0], result.OldParentID)
+	assert.Equal(t, phaseIDs[1], result.NewParentID)
+	require.NotNil(t, result.Parent)
+	assert.Equal(t, phaseIDs[1], result.Parent.ID)
+	assert.Equal(t, []string{taskID}, childIDs(*result.Parent))
+
+	tree, err := srv.GetProjectTree(ctx, mcp.GetProjectTreeArgs{ProjectID: projectID})
+	require.NoError(t, err)
+	for _, phase := range tree.Tree.Children {
+		switch phase.ID {
+		case phaseIDs[0]:
+			assert.Empty(t, phase.Children, "task should no longer be under the old phase")
+		case phaseIDs[1]:
+			assert.Equal(t, []string{taskID}, childIDs(phase), "task should be under the new phase")
+		}
+	}
+
+	// The moved item keeps its ID and content.
+	mem, err := store.Get(ctx, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, "Audit invoice templates", mem.Content)
+}
+
+func TestMoveProjectItem_RejectsCycle(t *testing.T) {
+	srv, _, projectID, phaseIDs, taskID := newMoveTestProject(t)
+	ctx := context.Background()
+
+	// Moving a phase under its own task would make the phase its own ancestor.
+	_, err := srv.MoveProjectItem(ctx, mcp.MoveProjectItemArgs{
+		ItemID:      phaseIDs[0],
+		NewParentID: taskID,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "descendant")
+
+	// Indirect descendants are caught as well.
+	step, err := srv.AddProjectItem(ctx, mcp.AddProjectItemArgs{
+		ParentID: taskID,
+		ItemType: "step",
+		Name:     "List templates in use",
+	})
+	require.NoError(t, err)
+	_, err = srv.MoveProjectItem(ctx, mcp.MoveProjectItemArgs{
+		ItemID:      phaseIDs[0],
+		NewParentID: step.ID,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "descendant")
+
+	// The tree is unchanged.
+	tree, err := srv.GetProjectTree(ctx, mcp.GetProjectTreeArgs{ProjectID: projectID})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, phaseIDs, childIDs(tree.Tree))
+}
+
+func TestMoveProjectItem_MissingParent(t *testing.T) {
+	srv, _, _, _, taskID := newMoveTestProject(t)
+
+	code := rpcErrorCode(t, srv, fmt.Sprintf(
+		`{"jsonrpc":"2.0","method":"move_project_item","params":{"item_id":%q,"new_parent_id":"mem:test:missing"},"id":1}`,
+		taskID,
+	))
+	assert.Equal(t, mcp.ErrCodeNotFound, code)
+}
+
+func TestMoveProjectItem_RejectsCrossConnection(t *testing.T) {
+	srv := newReadOnlyConnectionServer(t)
+	ctx := context.Background()
+
+	task, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Draft the billing runbook", ConnectionID: "work"})
+	require.NoError(t, err)
+
+	_, err = srv.MoveProjectItem(ctx, mcp.MoveProjectItemArgs{ItemID: task.ID, NewParentID: "mem:archive:one"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "across connections")
+}
+
+func TestProjectItemOrdering(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	proj, err := srv.CreateProject(ctx, mcp.CreateProjectArgs{
+		Name:       "Office Move",
+		PhaseNames: []string{"Plan", "Pack", "Unpack"},
+	})
+	require.NoError(t, err)
+	plan, pack, unpack := proj.PhaseIDs[0], proj.PhaseIDs[1], proj.PhaseIDs[2]
+
+	tree, err := srv.GetProjectTree(ctx, mcp.GetProjectTreeArgs{ProjectID: proj.ProjectID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{plan, pack, unpack}, childIDs(tree.Tree), "phases come back in creation order")
+
+	kickoff, err := srv.AddProjectItem(ctx, mcp.AddProjectItemArgs{
+		ParentID: proj.ProjectID,
+		ItemType: "phase",
+		Name:     "Kickoff",
+		Position: 1,
+	})
+	require.NoError(t, err)
+	tree, err = srv.GetProjectTree(ctx, mcp.GetProjectTreeArgs{ProjectID: proj.ProjectID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{kickoff.ID, plan, pack, unpack}, childIDs(tree.Tree))
+
+	// Listed children come first; the rest keep their relative order.
+	var result mcp.ReorderProjectItemsResult
+	callRPC(t, srv, "reorder_project_items", map[string]interface{}{
+		"parent_id": proj.ProjectID,
+		"item_ids":  []string{unpack, plan},
+	}, &result)
+	want := []string{unpack, plan, kickoff.ID, pack}
+	assert.Equal(t, want, result.ItemIDs)
+	tree, err = srv.GetProjectTree(ctx, mcp.GetProjectTreeArgs{ProjectID: proj.ProjectID})
+	require.NoError(t, err)
+	assert.Equal(t, want, childIDs(tree.Tree))
+
+	// A position past the end places the item last.
+	wrapUp, err := srv.AddProjectItem(ctx, mcp.AddProjectItemArgs{
+		ParentID: proj.ProjectID,
+		ItemType: "milestone",
+		Name:     "Keys returned",
+		Position: 99,
+	})
+	require.NoError(t, err)
+	tree, err = srv.GetProjectTree(ctx, mcp.GetProjectTreeArgs{ProjectID: proj.ProjectID})
+	require.NoError(t, err)
+	assert.Equal(t, append(want, wrapUp.ID), childIDs(tree.Tree))
+
+	for _, ids := range [][]string{{"mem:test:stranger"}, {plan, plan}} {
+		_, err = srv.ReorderProjectItems(ctx, mcp.ReorderProjectItemsArgs{ParentID: proj.ProjectID, ItemIDs: ids})
+		require.Error(t, err, "%v", ids)
+	}
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, fmt.Sprintf(
+		`{"jsonrpc":"2.0","method":"reorder_project_items","params":{"parent_id":%q,"item_ids":[%q]},"id":1}`,
+		plan, pack,
+	)))
+}
+
 func TestGetProjectTree(t *testing.T) {
 	store, err := sqlite.NewMemoryStore(":memory:")
 	require.NoError(t, err)
@@ -1884,3 +2732,240 @@ func TestTraverseMemoryGraph(t *testing.T) {
 	assert.Contains(t, string(resp), `"result"`)
 	assert.NotContains(t, string(resp), `"error"`)
 }
+
+// ---------------------------------------------------------------------------
+// Tests for read-only mode (WithReadOnly)
+// ---------------------------------------------------------------------------
+
+// TestReadOnly_StoreMemoryRejected verifies that store_memory is refused and
+// nothing is written when the server is read-only.
+func TestReadOnly_StoreMemoryRejected(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithReadOnly(true))
+	ctx := context.Background()
+
+	req := `{"jsonrpc":"2.0","method":"store_memory","params":{"content":"should not be stored"},"id":1}`
+	resp, err := srv.HandleRequest(ctx, []byte(req))
+	require.NoError(t, err)
+	assert.Contains(t, string(resp), `"error"`)
+	assert.Contains(t, string(resp), "read-only")
+	assert.Empty(t, store.memories, "read-only server must not write to the store")
+}
+
+// TestReadOnly_RecallMemorySucceeds verifies that reads keep working.
+func TestReadOnly_RecallMemorySucceeds(t *testing.T) {
+	store := newMockStore()
+	now := time.Now()
+	store.memories["mem:test:ro"] = &types.Memory{
+		ID:        "mem:test:ro",
+		Content:   "readable content",
+		Status:    types.StatusEnriched,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	srv := mcp.NewServer(store, mcp.WithReadOnly(true))
+	ctx := context.Background()
+
+	req := `{"jsonrpc":"2.0","method":"recall_memory","params":{"id":"mem:test:ro"},"id":1}`
+	resp, err := srv.HandleRequest(ctx, []byte(req))
+	require.NoError(t, err)
+	assert.Contains(t, string(resp), `"result"`)
+	assert.NotContains(t, string(resp), `"error"`)
+
+	var jsonResp map[string]interface{}
+	require.NoError(t, json.Unmarshal(resp, &jsonResp))
+	result := jsonResp["result"].(map[string]interface{})
+	assert.True(t, result["found"].(bool))
+}
+
+// TestReadOnly_ToolsCallRejected verifies that the MCP tools/call path also
+// rejects mutating tools, reporting the failure via isError.
+func TestReadOnly_ToolsCallRejected(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithReadOnly(true))
+	ctx := context.Background()
+
+	req := `{"jsonrpc":"2.0","method":"tools/call","params":{"name":"store_memory","arguments":{"content":"nope"}},"id":1}`
+	resp, err := srv.HandleRequest(ctx, []byte(req))
+	require.NoError(t, err)
+
+	var jsonResp struct {
+		Result mcp.MCPToolCallResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(resp, &jsonResp))
+	assert.True(t, jsonResp.Result.IsError)
+	require.Len(t, jsonResp.Result.Content, 1)
+	assert.Contains(t, jsonResp.Result.Content[0].Text, "read-only")
+	assert.Empty(t, store.memories)
+}
+
+// TestReadOnly_ToolsListOmitsMutatingTools verifies that tools/list only
+// advertises read tools in read-only mode.
+func TestReadOnly_ToolsListOmitsMutatingTools(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithReadOnly(true))
+	ctx := context.Background()
+
+	resp, err := srv.HandleRequest(ctx, []byte(`{"jsonrpc":"2.0","method":"tools/list","id":1}`))
+	require.NoError(t, err)
+
+	var jsonResp struct {
+		Result mcp.MCPToolsListResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(resp, &jsonResp))
+
+	names := make(map[string]bool)
+	for _, tool := range jsonResp.Result.Tools {
+		names[tool.Name] = true
+	}
+	for _, name := range []string{"recall_memory", "find_related", "traverse_memory_graph", "explain_reasoning", "list_projects"} {
+		assert.True(t, names[name], "read tool %s should be listed", name)
+	}
+	for _, name := range []string{"store_memory", "update_memory", "forget_memory", "evolve_memory", "consolidate_memories", "update_memory_state", "create_project", "add_project_item", "move_project_item"} {
+		assert.False(t, names[name], "mutating tool %s should be hidden", name)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Tests for typed JSON-RPC error codes
+// ---------------------------------------------------------------------------
+
+// rpcErrorCode sends req and returns the JSON-RPC error code in the response.
+func rpcErrorCode(t *testing.T, srv *mcp.Server, req string) int {
+	t.Helper()
+	resp, err := srv.HandleRequest(context.Background(), []byte(req))
+	require.NoError(t, err)
+
+	var jsonResp mcp.JSONRPCResponse
+	require.NoError(t, json.Unmarshal(resp, &jsonResp))
+	require.NotNil(t, jsonResp.Error, "expected an error response, got %s", resp)
+	return jsonResp.Error.Code
+}
+
+func TestErrorCodes_NotFound(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"forget_memory","params":{"id":"mem:test:missing"},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeNotFound, code)
+
+	code = rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"update_memory","params":{"id":"mem:test:missing","content":"x"},"id":2}`)
+	assert.Equal(t, mcp.ErrCodeNotFound, code)
+}
+
+func TestErrorCodes_InvalidParams(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"store_memory","params":{"content":""},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, code)
+
+	code = rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"update_memory_state","params":{"id":"mem:test:x","state":"bogus"},"id":2}`)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, code)
+
+	code = rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"recall_memory","params":{"created_after":"yesterday"},"id":3}`)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, code)
+}
+
+func TestErrorCodes_ReadOnly(t *testing.T) {
+	srv := mcp.NewServer(newMockStore(), mcp.WithReadOnly(true))
+
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"store_memory","params":{"content":"x"},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeReadOnly, code)
+}
+
+func TestErrorCodes_InternalFailureIsServerError(t *testing.T) {
+	store := newMockStore()
+	store.listErr = errors.New("disk on fire")
+	srv := mcp.NewServer(store)
+
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"list_projects","params":{},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeServerError, code)
+}
+
+// ---------------------------------------------------------------------------
+// Tests for WithRequestTimeout
+// ---------------------------------------------------------------------------
+
+func TestRequestTimeout_StalledHandlerReturnsTimeoutCode(t *testing.T) {
+	store := newMockStore()
+	store.blockList = true
+	srv := mcp.NewServer(store, mcp.WithRequestTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"list_projects","params":{},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeTimeout, code)
+	assert.Less(t, time.Since(start), 5*time.Second, "request should be cut off by the timeout")
+}
+
+func TestRequestTimeout_ToolsCallReturnsTimeoutMessage(t *testing.T) {
+	store := newMockStore()
+	store.blockList = true
+	srv := mcp.NewServer(store, mcp.WithRequestTimeout(20*time.Millisecond))
+
+	resp, err := srv.HandleRequest(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","method":"tools/call","params":{"name":"list_projects","arguments":{}},"id":1}`))
+	require.NoError(t, err)
+
+	var jsonResp struct {
+		Result mcp.MCPToolCallResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(resp, &jsonResp))
+	assert.True(t, jsonResp.Result.IsError)
+	require.Len(t, jsonResp.Result.Content, 1)
+	assert.Contains(t, jsonResp.Result.Content[0].Text, mcp.ErrRequestTimeout.Error())
+}
+
+func TestRequestTimeout_DisabledByDefault(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store)
+
+	resp, err := srv.HandleRequest(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","method":"list_projects","params":{},"id":1}`))
+	require.NoError(t, err)
+	assert.NotContains(t, string(resp), `"error"`)
+}
+
+// TestListEntities_FiltersByTypeWithCounts verifies list_entities against a
+// real store seeded with entities of several types.
+func TestListEntities_FiltersByTypeWithCounts(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv := mcp.NewServer(store)
+	ctx := context.Background()
+
+	first, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "alice reviewed the go service"})
+	require.NoError(t, err)
+	second, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "alice paired with bob"})
+	require.NoError(t, err)
+
+	db := store.GetDB()
+	now := time.Now()
+	for id, typ := range map[string]string{"ent:alice": "person", "ent:bob": "person", "ent:go": "language"} {
+		_, err := db.ExecContext(ctx, `INSERT INTO entities (id, name, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			id, strings.TrimPrefix(id, "ent:"), typ, now, now)
+		require.NoError(t, err)
+	}
+	for _, link := range [][2]string{
+		{first.ID, "ent:alice"}, {second.ID, "ent:alice"}, {second.ID, "ent:bob"}, {first.ID, "ent:go"},
+	} {
+		_, err := db.ExecContext(ctx, `INSERT INTO memory_entities (memory_id, entity_id, created_at) VALUES (?, ?, ?)`,
+			link[0], link[1], now)
+		require.NoError(t, err)
+	}
+
+	result, err := srv.ListEntities(ctx, mcp.ListEntitiesArgs{Type: "person"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	require.Len(t, result.Entities, 2)
+	assert.Equal(t, "alice", result.Entities[0].Name)
+	assert.Equal(t, 2, result.Entities[0].MemoryCount)
+	assert.NotEmpty(t, result.Entities[0].LastSeen)
+	assert.Equal(t, "bob", result.Entities[1].Name)
+
+	all, err := srv.ListEntities(ctx, mcp.ListEntitiesArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, all.Total)
+
+	req := `{"jsonrpc":"2.0","method":"list_entities","params":{"type":"spaceship"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req))
+}
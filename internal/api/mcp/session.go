@@ -0,0 +1,145 @@
+package mcp
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// DefaultSessionIdleTimeout is how long the server may go without a tool call
+// before the next stored memory starts a new session, when
+// WithSessionIdleTimeout is not given.
+const DefaultSessionIdleTimeout = 30 * time.Minute
+
+// sessionTracker holds the server's current session ID and rotates it after
+// an idle gap or once the session reaches its TTL, so a long-running server
+// does not lump days of work into one session. A session started with
+// begin_session is never rotated automatically; it lasts until end_session.
+type sessionTracker struct {
+	mu           sync.Mutex
+	id           string
+	label        string
+	explicit     bool // started by begin_session
+	startedAt    time.Time
+	lastActivity time.Time
+	idleTimeout  time.Duration // 0 disables idle rotation
+	ttl          time.Duration // maximum session age; 0 disables TTL rotation
+}
+
+func newSessionTracker(idleTimeout time.Duration) *sessionTracker {
+	now := time.Now()
+	return &sessionTracker{
+		id:           uuid.New().String(),
+		startedAt:    now,
+		lastActivity: now,
+		idleTimeout:  idleTimeout,
+	}
+}
+
+// current returns the current session ID without rotating it.
+func (t *sessionTracker) current() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.id
+}
+
+// forWrite returns the session a newly created memory belongs to. If the
+// server has been idle for longer than the idle timeout, or the session is
+// older than its TTL, a new session is started first, unless the current
+// session was started with begin_session.
+func (t *sessionTracker) forWrite() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	switch {
+	case t.explicit:
+	case t.idleTimeout > 0 && now.Sub(t.lastActivity) > t.idleTimeout:
+		t.rotate(now, "idle timeout", "idle", now.Sub(t.lastActivity).Round(time.Second))
+	case t.ttl > 0 && now.Sub(t.startedAt) > t.ttl:
+		t.rotate(now, "session TTL reached", "age", now.Sub(t.startedAt).Round(time.Second))
+	}
+	t.lastActivity = now
+	return t.id
+}
+
+// rotate starts a new session at now. The caller holds t.mu.
+func (t *sessionTracker) rotate(now time.Time, reason string, attrs ...any) {
+	previous := t.id
+	t.id = uuid.New().String()
+	t.label = ""
+	t.explicit = false
+	t.startedAt = now
+	slog.Debug(reason+", new session", append(attrs, "session_id", t.id, "previous", previous)...)
+}
+
+// begin starts a new session with the given label that is kept until end
+// is called. It returns the new session and, when a begin_session session
+// was still open, that session marked as ended.
+func (t *sessionTracker) begin(label string) (current, ended *storage.Session) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if t.explicit {
+		ended = t.session(&now)
+	}
+	t.rotate(now, "session begun", "label", label)
+	t.label = label
+	t.explicit = true
+	t.lastActivity = now
+	return t.session(nil), ended
+}
+
+// end closes the session started by begin_session and returns it, then
+// falls back to an automatic session. ok is false when no begin_session
+// session is open.
+func (t *sessionTracker) end() (ended *storage.Session, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.explicit {
+		return nil, false
+	}
+	now := time.Now()
+	ended = t.session(&now)
+	t.rotate(now, "session ended", "label", ended.Label)
+	return ended, true
+}
+
+// labelOf returns the label of the current session if id is current.
+func (t *sessionTracker) labelOf(id string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.label, id == t.id && t.explicit
+}
+
+// session describes the current session. The caller holds t.mu.
+func (t *sessionTracker) session(endedAt *time.Time) *storage.Session {
+	return &storage.Session{ID: t.id, Label: t.label, StartedAt: t.startedAt, EndedAt: endedAt}
+}
+
+// touch records tool-call activity.
+func (t *sessionTracker) touch() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastActivity = time.Now()
+}
+
+// snapshot returns the session state for get_current_session.
+func (t *sessionTracker) snapshot() *GetCurrentSessionResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := &GetCurrentSessionResult{
+		SessionID:          t.id,
+		Label:              t.label,
+		StartedAt:          t.startedAt.UTC().Format(time.RFC3339),
+		LastActivity:       t.lastActivity.UTC().Format(time.RFC3339),
+		IdleTimeoutSeconds: int(t.idleTimeout / time.Second),
+		TTLSeconds:         int(t.ttl / time.Second),
+	}
+	if t.ttl > 0 && !t.explicit {
+		result.ExpiresAt = t.startedAt.Add(t.ttl).UTC().Format(time.RFC3339)
+	}
+	return result
+}
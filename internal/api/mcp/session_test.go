@@ -0,0 +1,189 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_RotatesAfterIdleGap(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithSessionIdleTimeout(50*time.Millisecond))
+	ctx := context.Background()
+
+	first, err := srv.GetCurrentSession(ctx)
+	require.NoError(t, err)
+
+	r1, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "before the break"})
+	require.NoError(t, err)
+	r2, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "still working"})
+	require.NoError(t, err)
+	assert.Equal(t, first.SessionID, store.memories[r1.ID].SessionID)
+	assert.Equal(t, first.SessionID, store.memories[r2.ID].SessionID)
+
+	time.Sleep(80 * time.Millisecond)
+
+	r3, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "back after lunch"})
+	require.NoError(t, err)
+	rotated := store.memories[r3.ID].SessionID
+	assert.NotEqual(t, first.SessionID, rotated, "an idle gap should start a new session")
+
+	current, err := srv.GetCurrentSession(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, rotated, current.SessionID)
+}
+
+func TestSession_ToolCallsKeepSessionAlive(t *testing.T) {
+	srv := mcp.NewServer(newMockStore(), mcp.WithSessionIdleTimeout(100*time.Millisecond))
+	ctx := context.Background()
+
+	first, err := srv.GetCurrentSession(ctx)
+	require.NoError(t, err)
+
+	// Read-only tool calls through the protocol count as activity.
+	for i := 0; i < 4; i++ {
+		time.Sleep(40 * time.Millisecond)
+		_, err := srv.HandleRequest(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"recall_memory","params":{}}`))
+		require.NoError(t, err)
+	}
+
+	r, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "same session"})
+	require.NoError(t, err)
+	got, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{ID: r.ID})
+	require.NoError(t, err)
+	assert.Equal(t, first.SessionID, got.Memory.SessionID)
+}
+
+func TestSession_ExplicitSessionIDAndDisabledRotation(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithSessionIdleTimeout(0))
+	ctx := context.Background()
+
+	first, err := srv.GetCurrentSession(ctx)
+	require.NoError(t, err)
+
+	r, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "pinned", SessionID: "my-session"})
+	require.NoError(t, err)
+	assert.Equal(t, "my-session", store.memories[r.ID].SessionID)
+
+	time.Sleep(20 * time.Millisecond)
+	r, err = srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "no rotation"})
+	require.NoError(t, err)
+	assert.Equal(t, first.SessionID, store.memories[r.ID].SessionID)
+}
+
+func TestSession_GetCurrentSessionTool(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	req := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_current_session","arguments":{}}}`
+	resp, err := srv.HandleRequest(context.Background(), []byte(req))
+	require.NoError(t, err)
+
+	current, err := srv.GetCurrentSession(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, string(resp), current.SessionID)
+	assert.Equal(t, int(mcp.DefaultSessionIdleTimeout/time.Second), current.IdleTimeoutSeconds)
+}
+
+func TestSession_RotatesAfterTTL(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithSessionIdleTimeout(0), mcp.WithSessionTTL(60*time.Millisecond))
+	ctx := context.Background()
+
+	first, err := srv.GetCurrentSession(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, first.TTLSeconds, "sub-second TTLs round down")
+	assert.NotEmpty(t, first.ExpiresAt)
+
+	old, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "early in the session"})
+	require.NoError(t, err)
+
+	// Keep the server busy past the TTL: activity does not extend a session.
+	for i := 0; i < 4; i++ {
+		time.Sleep(25 * time.Millisecond)
+		_, err := srv.HandleRequest(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"recall_memory","params":{}}`))
+		require.NoError(t, err)
+	}
+
+	fresh, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "after the TTL"})
+	require.NoError(t, err)
+	assert.Equal(t, first.SessionID, store.memories[old.ID].SessionID, "old memories keep their session")
+	assert.NotEqual(t, first.SessionID, store.memories[fresh.ID].SessionID, "the TTL should start a new session")
+
+	current, err := srv.GetCurrentSession(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, store.memories[fresh.ID].SessionID, current.SessionID)
+}
+
+func TestSession_BeginLabeledSession(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	begun, err := srv.BeginSession(ctx, mcp.BeginSessionArgs{Label: "refactor auth module"})
+	require.NoError(t, err)
+	assert.Equal(t, "refactor auth module", begun.Label)
+	assert.Empty(t, begun.EndedAt)
+
+	current, err := srv.GetCurrentSession(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, begun.SessionID, current.SessionID)
+	assert.Equal(t, "refactor auth module", current.Label)
+
+	r, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "moved token validation into middleware"})
+	require.NoError(t, err)
+	got, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{ID: r.ID})
+	require.NoError(t, err)
+	assert.Equal(t, begun.SessionID, got.Memory.SessionID)
+
+	sessionCtx, err := srv.GetSessionContext(ctx, mcp.GetSessionContextArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, begun.SessionID, sessionCtx.SessionID)
+	assert.Equal(t, "refactor auth module", sessionCtx.Label)
+	assert.Equal(t, 1, sessionCtx.MemoryCount)
+
+	ended, err := srv.EndSession(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, begun.SessionID, ended.SessionID)
+	assert.NotEmpty(t, ended.EndedAt)
+
+	after, err := srv.GetCurrentSession(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, begun.SessionID, after.SessionID)
+	assert.Empty(t, after.Label)
+
+	// The label is read back from the store, e.g. after a restart.
+	restarted := mcp.NewServer(store)
+	sessionCtx, err = restarted.GetSessionContext(ctx, mcp.GetSessionContextArgs{SessionID: begun.SessionID})
+	require.NoError(t, err)
+	assert.Equal(t, "refactor auth module", sessionCtx.Label)
+
+	sessionCtx, err = restarted.GetSessionContext(ctx, mcp.GetSessionContextArgs{TimeWindowH: 1})
+	require.NoError(t, err)
+	require.Len(t, sessionCtx.Sessions, 1)
+	assert.Equal(t, "refactor auth module", sessionCtx.Sessions[0].Label)
+}
+
+func TestSession_BegunSessionIgnoresIdleTimeout(t *testing.T) {
+	store := newMockStore()
+	srv := mcp.NewServer(store, mcp.WithSessionIdleTimeout(20*time.Millisecond))
+	ctx := context.Background()
+
+	begun, err := srv.BeginSession(ctx, mcp.BeginSessionArgs{Label: "long task"})
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	r, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "after a long think"})
+	require.NoError(t, err)
+	assert.Equal(t, begun.SessionID, store.memories[r.ID].SessionID)
+}
+
+func TestSession_EndWithoutBegin(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"end_session","params":{},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeInvalidParams, code)
+}
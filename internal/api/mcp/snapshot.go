@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// MemorySnapshotVersion is the format version written into every snapshot.
+// restore_memory_snapshot rejects snapshots of any other version.
+const MemorySnapshotVersion = 1
+
+// GetMemorySnapshot captures a memory together with its entities, the
+// relationships among them, and its incoming and outgoing memory links as a
+// single self-contained document.
+func (s *Server) GetMemorySnapshot(ctx context.Context, args GetMemorySnapshotArgs) (*MemorySnapshot, error) {
+	if args.ID == "" {
+		return nil, invalidParamsf("id is required")
+	}
+
+	store := s.resolveStoreForID(ctx, args.ID)
+	snapshots, ok := store.(storage.SnapshotStore)
+	if !ok {
+		return nil, fmt.Errorf("store does not support memory snapshots")
+	}
+
+	memory, err := store.Get(ctx, args.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("memory not found: %s", args.ID)
+		}
+		return nil, fmt.Errorf("failed to get memory: %w", err)
+	}
+
+	graph, err := snapshots.GetMemoryGraph(ctx, args.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture memory graph: %w", err)
+	}
+
+	return &MemorySnapshot{
+		Version:     MemorySnapshotVersion,
+		CapturedAt:  time.Now().UTC().Format(time.RFC3339),
+		Memory:      *memory,
+		MemoryGraph: *graph,
+	}, nil
+}
+
+// RestoreMemorySnapshot re-applies a snapshot taken by GetMemorySnapshot.
+// The memory is written back exactly as captured (undeleting it if it was
+// soft-deleted or recreating it if it was purged), its entity associations
+// are replaced with the snapshot's, and links that have since disappeared are
+// recreated. Links to memories that no longer exist are skipped.
+func (s *Server) RestoreMemorySnapshot(ctx context.Context, args RestoreMemorySnapshotArgs) (*RestoreMemorySnapshotResult, error) {
+	snap := args.Snapshot
+	if snap.Version != MemorySnapshotVersion {
+		return nil, invalidParamsf("unsupported snapshot version %d (want %d)", snap.Version, MemorySnapshotVersion)
+	}
+	if snap.Memory.ID == "" || snap.Memory.Content == "" {
+		return nil, invalidParamsf("snapshot memory must have an id and content")
+	}
+
+	store := s.resolveStoreForID(ctx, snap.Memory.ID)
+	snapshots, ok := store.(storage.SnapshotStore)
+	if !ok {
+		return nil, fmt.Errorf("store does not support memory snapshots")
+	}
+
+	// Store is an upsert, so this both overwrites a live memory and brings
+	// back a deleted one.
+	memory := snap.Memory
+	if err := store.Store(ctx, &memory); err != nil {
+		return nil, fmt.Errorf("failed to restore memory: %w", err)
+	}
+
+	linksRecreated, err := snapshots.RestoreMemoryGraph(ctx, memory.ID, &snap.MemoryGraph)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore memory graph: %w", err)
+	}
+
+	return &RestoreMemorySnapshotResult{
+		ID:             memory.ID,
+		Restored:       true,
+		Entities:       len(snap.Entities),
+		Relationships:  len(snap.Relationships),
+		LinksRecreated: linksRecreated,
+	}, nil
+}
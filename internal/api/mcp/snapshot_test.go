@@ -0,0 +1,149 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callRPC sends a JSON-RPC request and unmarshals its result into out.
+func callRPC(t *testing.T, srv *mcp.Server, method string, params interface{}, out interface{}) {
+	t.Helper()
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params, "id": 1})
+	require.NoError(t, err)
+	resp, err := srv.HandleRequest(context.Background(), body)
+	require.NoError(t, err)
+
+	var jsonResp struct {
+		Result json.RawMessage   `json:"result"`
+		Error  *mcp.JSONRPCError `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(resp, &jsonResp))
+	require.Nil(t, jsonResp.Error, "%s failed: %s", method, resp)
+	require.NoError(t, json.Unmarshal(jsonResp.Result, out))
+}
+
+// newSQLiteTestServer returns a server over an in-memory SQLite store, and
+// the store.
+func newSQLiteTestServer(t *testing.T, opts ...mcp.ServerOption) (*mcp.Server, *sqlite.MemoryStore) {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return mcp.NewServer(store, opts...), store
+}
+
+// TestMemorySnapshot_RoundTrip captures a memory with entities and links,
+// purges the memory and its graph, and checks that restoring the JSON
+// snapshot brings all of it back.
+func TestMemorySnapshot_RoundTrip(t *testing.T) {
+	srv, store := newSQLiteTestServer(t)
+	ctx := context.Background()
+
+	var ids []string
+	for _, content := range []string{"Alice joined Acme as CTO", "Acme hiring plan", "Q3 leadership changes"} {
+		res, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: content})
+		require.NoError(t, err)
+		ids = append(ids, res.ID)
+	}
+	target := ids[0]
+
+	db := store.GetDB()
+	now := time.Now()
+	for _, stmt := range []struct {
+		query string
+		args  []interface{}
+	}{
+		{`INSERT INTO entities (id, name, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			[]interface{}{"ent:alice", "alice", types.EntityTypePerson, now, now}},
+		{`INSERT INTO entities (id, name, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			[]interface{}{"ent:acme", "acme", types.EntityTypeOrganization, now, now}},
+		{`INSERT INTO relationships (id, source_id, target_id, type, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			[]interface{}{"rel:works-at", "ent:alice", "ent:acme", "works_at", now, now}},
+		{`INSERT INTO memory_entities (memory_id, entity_id, frequency, confidence) VALUES (?, ?, 2, 0.9)`,
+			[]interface{}{target, "ent:alice"}},
+		{`INSERT INTO memory_entities (memory_id, entity_id, frequency, confidence) VALUES (?, ?, 1, 0.8)`,
+			[]interface{}{target, "ent:acme"}},
+	} {
+		_, err := db.ExecContext(ctx, stmt.query, stmt.args...)
+		require.NoError(t, err, stmt.query)
+	}
+	require.NoError(t, store.CreateMemoryLink(ctx, "link:out", target, ids[1], "REFERENCES"))
+	require.NoError(t, store.CreateMemoryLink(ctx, "link:in", ids[2], target, "CONTAINS"))
+
+	var snap mcp.MemorySnapshot
+	callRPC(t, srv, "get_memory_snapshot", map[string]string{"id": target}, &snap)
+	assert.Equal(t, mcp.MemorySnapshotVersion, snap.Version)
+	assert.Equal(t, "Alice joined Acme as CTO", snap.Memory.Content)
+	require.Len(t, snap.Entities, 2)
+	require.Len(t, snap.Relationships, 1)
+	require.Len(t, snap.Links, 2)
+
+	// Wipe the memory and everything around it.
+	_, err := srv.ForgetMemory(ctx, mcp.ForgetMemoryArgs{ID: target, HardDelete: true})
+	require.NoError(t, err)
+	for _, stmt := range []string{`DELETE FROM memory_links`, `DELETE FROM relationships`} {
+		_, err := db.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+
+	var result mcp.RestoreMemorySnapshotResult
+	callRPC(t, srv, "restore_memory_snapshot", map[string]interface{}{"snapshot": snap}, &result)
+	assert.True(t, result.Restored)
+	assert.Equal(t, 2, result.Entities)
+	assert.Equal(t, 1, result.Relationships)
+	assert.Equal(t, 2, result.LinksRecreated)
+
+	restored, err := store.Get(ctx, target)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice joined Acme as CTO", restored.Content)
+
+	var after mcp.MemorySnapshot
+	callRPC(t, srv, "get_memory_snapshot", map[string]string{"id": target}, &after)
+	require.Len(t, after.Entities, 2)
+	frequencies := map[string]int{}
+	for _, e := range after.Entities {
+		frequencies[e.ID] = e.Frequency
+	}
+	assert.Equal(t, map[string]int{"ent:alice": 2, "ent:acme": 1}, frequencies)
+	require.Len(t, after.Relationships, 1)
+	assert.Equal(t, "works_at", after.Relationships[0].Type)
+	require.Len(t, after.Links, 2)
+	linkEnds := map[string]string{}
+	for _, l := range after.Links {
+		linkEnds[l.Type] = fmt.Sprintf("%s->%s", l.SourceID, l.TargetID)
+	}
+	assert.Equal(t, target+"->"+ids[1], linkEnds["REFERENCES"])
+	assert.Equal(t, ids[2]+"->"+target, linkEnds["CONTAINS"])
+}
+
+// TestMemorySnapshot_Errors verifies parameter validation and that stores
+// without snapshot support are reported rather than silently ignored.
+func TestMemorySnapshot_Errors(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	req := `{"jsonrpc":"2.0","method":"get_memory_snapshot","params":{},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req))
+
+	req = `{"jsonrpc":"2.0","method":"restore_memory_snapshot","params":{"snapshot":{"version":99,"memory":{"id":"mem:general:x","content":"x"}}},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req))
+
+	_, err := srv.GetMemorySnapshot(context.Background(), mcp.GetMemorySnapshotArgs{ID: "mem:general:x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support memory snapshots")
+
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	srv = mcp.NewServer(store)
+	req = `{"jsonrpc":"2.0","method":"get_memory_snapshot","params":{"id":"mem:general:missing"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeNotFound, rpcErrorCode(t, srv, req))
+}
@@ -0,0 +1,69 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecallMemory_ListModeSortByAccessCount(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour)
+	for i, id := range []string{"mem:general:a", "mem:general:b", "mem:general:c"} {
+		require.NoError(t, store.Store(ctx, &types.Memory{
+			ID: id, Content: "memory " + id, Source: "test", Status: types.StatusEnriched,
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, store.IncrementAccessCount(ctx, "mem:general:a"))
+	}
+	require.NoError(t, store.IncrementAccessCount(ctx, "mem:general:b"))
+
+	srv := mcp.NewServer(store)
+	ids := func(memories []types.Memory) []string {
+		out := make([]string, len(memories))
+		for i, m := range memories {
+			out[i] = m.ID
+		}
+		return out
+	}
+
+	result, err := srv.RecallMemory(ctx, mcp.RecallMemoryArgs{SortBy: "access_count"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:general:a", "mem:general:b", "mem:general:c"}, ids(result.Memories))
+
+	result, err = srv.RecallMemory(ctx, mcp.RecallMemoryArgs{SortBy: "access_count", SortOrder: "ASC"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:general:c", "mem:general:b", "mem:general:a"}, ids(result.Memories))
+
+	// The default stays newest first.
+	result, err = srv.RecallMemory(ctx, mcp.RecallMemoryArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:general:c", "mem:general:b", "mem:general:a"}, ids(result.Memories))
+}
+
+func TestListTools_RejectUnknownSort(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	for _, req := range []string{
+		`{"jsonrpc":"2.0","method":"recall_memory","params":{"sort_by":"content; DROP TABLE memories"},"id":1}`,
+		`{"jsonrpc":"2.0","method":"list_projects","params":{"sort_by":"title"},"id":2}`,
+		`{"jsonrpc":"2.0","method":"list_deleted_memories","params":{"sort_by":"updated_at","sort_order":"sideways"},"id":3}`,
+	} {
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), req)
+	}
+
+	_, err := srv.ListProjects(context.Background(), mcp.ListProjectsArgs{SortBy: "updated_at", SortOrder: "asc"})
+	assert.NoError(t, err)
+}
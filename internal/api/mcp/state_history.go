@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// GetStateHistory returns the lifecycle timeline of one memory: each
+// recorded state transition, oldest first, with how long the memory stayed
+// in the state it entered.
+func (s *Server) GetStateHistory(ctx context.Context, args GetStateHistoryArgs) (*GetStateHistoryResult, error) {
+	if args.ID == "" {
+		return nil, invalidParamsf("id is required")
+	}
+	store, err := s.readStoreForID(ctx, args.ID, args.ConnectionID)
+	if err != nil {
+		return nil, err
+	}
+	history, ok := store.(storage.StateHistory)
+	if !ok {
+		return nil, fmt.Errorf("state history is not supported by this store")
+	}
+
+	memory, err := store.Get(ctx, args.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, notFoundf("memory not found: %s", args.ID)
+		}
+		return nil, fmt.Errorf("failed to retrieve memory: %w", err)
+	}
+	transitions, err := history.GetStateHistory(ctx, args.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state history: %w", err)
+	}
+
+	now := time.Now()
+	entries := make([]StateHistoryEntry, len(transitions))
+	for i, t := range transitions {
+		until := now
+		if i+1 < len(transitions) {
+			until = transitions[i+1].ChangedAt
+		}
+		entries[i] = StateHistoryEntry{
+			From:            t.From,
+			To:              t.To,
+			ChangedAt:       t.ChangedAt,
+			ChangedBy:       t.ChangedBy,
+			DurationSeconds: until.Sub(t.ChangedAt).Seconds(),
+		}
+	}
+	return &GetStateHistoryResult{
+		ID:             memory.ID,
+		CurrentState:   memory.State,
+		StateUpdatedAt: memory.StateUpdatedAt,
+		Transitions:    entries,
+		Total:          len(entries),
+	}, nil
+}
@@ -0,0 +1,84 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStateHistory_Timeline(t *testing.T) {
+	srv, _ := newBatchStateStore(t, map[string]string{"mem:test:task": ""})
+
+	for _, state := range []string{types.StatePlanning, types.StateActive, types.StateBlocked} {
+		var updated mcp.UpdateMemoryStateResult
+		callRPC(t, srv, "update_memory_state", map[string]interface{}{"id": "mem:test:task", "state": state}, &updated)
+	}
+
+	var result mcp.GetStateHistoryResult
+	callRPC(t, srv, "get_state_history", map[string]interface{}{"id": "mem:test:task"}, &result)
+
+	assert.Equal(t, "mem:test:task", result.ID)
+	assert.Equal(t, types.StateBlocked, result.CurrentState)
+	require.NotNil(t, result.StateUpdatedAt)
+	require.Len(t, result.Transitions, 3)
+	assert.Equal(t, 3, result.Total)
+
+	first, last := result.Transitions[0], result.Transitions[2]
+	assert.Empty(t, first.From)
+	assert.Equal(t, types.StatePlanning, first.To)
+	assert.Equal(t, types.StateActive, last.From)
+	assert.Equal(t, types.StateBlocked, last.To)
+	assert.NotEmpty(t, last.ChangedBy, "calls through the dispatcher name the agent")
+
+	// Each state lasts until the next transition; the current one until now.
+	for i, entry := range result.Transitions {
+		assert.GreaterOrEqual(t, entry.DurationSeconds, 0.0, i)
+		if i+1 < len(result.Transitions) {
+			next := result.Transitions[i+1].ChangedAt
+			assert.InDelta(t, next.Sub(entry.ChangedAt).Seconds(), entry.DurationSeconds, 0.001, i)
+		}
+	}
+	assert.InDelta(t, time.Since(last.ChangedAt).Seconds(), last.DurationSeconds, 5)
+}
+
+func TestGetStateHistory_NoTransitions(t *testing.T) {
+	srv, _ := newBatchStateStore(t, map[string]string{"mem:test:note": ""})
+
+	result, err := srv.GetStateHistory(context.Background(), mcp.GetStateHistoryArgs{ID: "mem:test:note"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Transitions)
+	assert.Zero(t, result.Total)
+}
+
+func TestGetStateHistory_InvalidArgs(t *testing.T) {
+	srv, _ := newBatchStateStore(t, nil)
+
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv,
+		`{"jsonrpc":"2.0","method":"get_state_history","params":{},"id":1}`))
+	assert.Equal(t, mcp.ErrCodeNotFound, rpcErrorCode(t, srv,
+		`{"jsonrpc":"2.0","method":"get_state_history","params":{"id":"mem:test:missing"},"id":1}`))
+}
+
+func TestGetStateHistory_ScopedToConnection(t *testing.T) {
+	srv := newReadOnlyConnectionServer(t)
+	ctx := context.Background()
+
+	task, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "Draft the roadmap", ConnectionID: "work"})
+	require.NoError(t, err)
+	_, err = srv.UpdateMemoryState(ctx, mcp.UpdateMemoryStateArgs{ID: task.ID, State: types.StatePlanning})
+	require.NoError(t, err)
+
+	result, err := srv.GetStateHistory(ctx, mcp.GetStateHistoryArgs{ID: task.ID})
+	require.NoError(t, err)
+	assert.Len(t, result.Transitions, 1)
+
+	_, err = srv.GetStateHistory(ctx, mcp.GetStateHistoryArgs{ID: task.ID, ConnectionID: "archive"})
+	require.Error(t, err)
+	_, err = srv.GetStateHistory(ctx, mcp.GetStateHistoryArgs{ID: task.ID, ConnectionID: "nope"})
+	require.Error(t, err)
+}
@@ -0,0 +1,78 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// summarizingEngine returns a canned LLM response from Summarize.
+type summarizingEngine struct {
+	recordingEngine
+	response string
+	prompt   string
+}
+
+func (e *summarizingEngine) Summarize(_ context.Context, prompt string) (string, error) {
+	e.prompt = prompt
+	return e.response, nil
+}
+
+func TestSummarizeMemory_StoresSummary(t *testing.T) {
+	store := newMockStore()
+	eng := &summarizingEngine{
+		recordingEngine: recordingEngine{queued: map[string]string{}},
+		response:        `{"summary":"Team picked Postgres for the ledger.","key_points":["ledger"]}`,
+	}
+	srv := mcp.NewServer(store, mcp.WithEngine(eng))
+	ctx := context.Background()
+
+	stored, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "After a long debate the team picked Postgres for the ledger service."})
+	require.NoError(t, err)
+
+	result, err := srv.SummarizeMemory(ctx, mcp.SummarizeMemoryArgs{ID: stored.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "Team picked Postgres for the ledger.", result.Summary)
+	assert.Contains(t, eng.prompt, "ledger service")
+
+	memory := store.memories[stored.ID]
+	assert.Equal(t, "Team picked Postgres for the ledger.", memory.Summary)
+	assert.Equal(t, types.EnrichmentCompleted, memory.SummarizationStatus)
+}
+
+func TestSummarizeMemory_FallsBackToRawText(t *testing.T) {
+	store := newMockStore()
+	eng := &summarizingEngine{
+		recordingEngine: recordingEngine{queued: map[string]string{}},
+		response:        "  Plain prose summary.  ",
+	}
+	srv := mcp.NewServer(store, mcp.WithEngine(eng))
+	ctx := context.Background()
+
+	stored, err := srv.StoreMemory(ctx, mcp.StoreMemoryArgs{Content: "some content"})
+	require.NoError(t, err)
+
+	result, err := srv.SummarizeMemory(ctx, mcp.SummarizeMemoryArgs{ID: stored.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "Plain prose summary.", result.Summary)
+}
+
+func TestSummarizeMemory_Errors(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+
+	req := `{"jsonrpc":"2.0","method":"summarize_memory","params":{},"id":1}`
+	assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req))
+
+	_, err := srv.SummarizeMemory(context.Background(), mcp.SummarizeMemoryArgs{ID: "mem:general:missing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no LLM engine")
+
+	eng := &summarizingEngine{recordingEngine: recordingEngine{queued: map[string]string{}}}
+	srv = mcp.NewServer(newMockStore(), mcp.WithEngine(eng))
+	req = `{"jsonrpc":"2.0","method":"summarize_memory","params":{"id":"mem:general:missing"},"id":1}`
+	assert.Equal(t, mcp.ErrCodeNotFound, rpcErrorCode(t, srv, req))
+}
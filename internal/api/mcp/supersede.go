@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// SupersedeMemory records that the existing memory args.NewID replaces the
+// existing memory args.OldID, as evolve_memory does when it writes a new
+// version, but without storing a third memory: NewID's supersedes_id is set
+// to OldID and OldID moves to the superseded state. Both memories must live
+// in the same connection, and the link is refused if NewID already
+// supersedes a memory, OldID has already been superseded, or the link would
+// close a cycle in the evolution chain. If OldID cannot be marked
+// superseded, the link is removed again.
+func (s *Server) SupersedeMemory(ctx context.Context, args SupersedeMemoryArgs) (*SupersedeMemoryResult, error) {
+	if args.OldID == "" || args.NewID == "" {
+		return nil, invalidParamsf("old_id and new_id are required")
+	}
+	if args.OldID == args.NewID {
+		return nil, invalidParamsf("a memory cannot supersede itself")
+	}
+
+	store, err := s.readStoreForID(ctx, args.OldID, args.ConnectionID)
+	if err != nil {
+		return nil, err
+	}
+	if args.ConnectionID == "" && s.connectionForID(ctx, args.OldID) != s.connectionForID(ctx, args.NewID) {
+		return nil, invalidParamsf("%s and %s live in different connections", args.OldID, args.NewID)
+	}
+	old, err := getLiveMemory(ctx, store, args.OldID)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := getLiveMemory(ctx, store, args.NewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if newer.SupersedesID == old.ID {
+		return nil, invalidParamsf("%s already supersedes %s", newer.ID, old.ID)
+	}
+	if newer.SupersedesID != "" {
+		return nil, invalidParamsf("%s already supersedes %s", newer.ID, newer.SupersedesID)
+	}
+	// The chain runs oldest to newest. NewID, which supersedes nothing, can
+	// only appear in it as the original, so linking it after OldID would
+	// close a cycle; a memory after OldID means OldID is already replaced.
+	chain, err := store.GetEvolutionChain(ctx, old.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get evolution chain of %s: %w", old.ID, err)
+	}
+	for i, m := range chain {
+		if m.ID == newer.ID {
+			return nil, invalidParamsf("%s supersedes %s already, directly or through earlier versions; the link would create a cycle", old.ID, newer.ID)
+		}
+		if m.ID == old.ID && i < len(chain)-1 {
+			return nil, invalidParamsf("%s has already been superseded by %s", old.ID, chain[i+1].ID)
+		}
+	}
+
+	if old.State != types.StateSuperseded && !stateMachineOf(store).CanTransition(old.State, types.StateSuperseded) {
+		return nil, invalidParamsf("cannot supersede memory %s: invalid transition from '%s' to '%s'", old.ID, old.State, types.StateSuperseded)
+	}
+
+	newer.SupersedesID = old.ID
+	if err := store.Update(ctx, newer); err != nil {
+		return nil, fmt.Errorf("failed to link %s to %s: %w", newer.ID, old.ID, err)
+	}
+	if old.State != types.StateSuperseded {
+		if err := store.UpdateState(ctx, old.ID, types.StateSuperseded); err != nil {
+			// Unlink again so the new memory does not claim to replace one
+			// that is still live.
+			newer.SupersedesID = ""
+			if revertErr := store.Update(ctx, newer); revertErr != nil {
+				return nil, fmt.Errorf("failed to mark old memory as superseded: %w (and failed to unlink %s: %v)", err, newer.ID, revertErr)
+			}
+			return nil, fmt.Errorf("failed to mark old memory as superseded: %w", err)
+		}
+	}
+
+	return &SupersedeMemoryResult{
+		OldID:         old.ID,
+		NewID:         newer.ID,
+		PreviousState: old.State,
+	}, nil
+}
+
+// getLiveMemory returns memory id, or a not-found error if it does not
+// exist or has been soft-deleted.
+func getLiveMemory(ctx context.Context, store storage.MemoryStore, id string) (*types.Memory, error) {
+	m, err := store.Get(ctx, id)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, notFoundf("memory not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory %s: %w", id, err)
+	}
+	if m.DeletedAt != nil {
+		return nil, notFoundf("memory %s has been deleted", id)
+	}
+	return m, nil
+}
@@ -0,0 +1,111 @@
+package mcp_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSupersedeServer returns a server over a store holding active memories
+// with the given IDs.
+func newSupersedeServer(t *testing.T, ids ...string) (*mcp.Server, *sqlite.MemoryStore) {
+	t.Helper()
+	store, err := sqlite.NewMemoryStore(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	for _, id := range ids {
+		require.NoError(t, store.Store(context.Background(), &types.Memory{ID: id, Content: "content of " + id, Source: "test", State: types.StateActive}))
+	}
+	return mcp.NewServer(store), store
+}
+
+func TestSupersedeMemory_LinksExistingMemories(t *testing.T) {
+	srv, store := newSupersedeServer(t, "mem:general:a", "mem:general:b")
+	ctx := context.Background()
+
+	var result mcp.SupersedeMemoryResult
+	callRPC(t, srv, "supersede_memory", map[string]interface{}{"old_id": "mem:general:a", "new_id": "mem:general:b"}, &result)
+	assert.Equal(t, mcp.SupersedeMemoryResult{OldID: "mem:general:a", NewID: "mem:general:b", PreviousState: types.StateActive}, result)
+
+	old, err := store.Get(ctx, "mem:general:a")
+	require.NoError(t, err)
+	assert.Equal(t, types.StateSuperseded, old.State)
+	newer, err := store.Get(ctx, "mem:general:b")
+	require.NoError(t, err)
+	assert.Equal(t, "mem:general:a", newer.SupersedesID)
+	assert.Equal(t, types.StateActive, newer.State)
+	assert.Equal(t, "content of mem:general:b", newer.Content, "no new memory is written")
+
+	chain, err := store.GetEvolutionChain(ctx, "mem:general:b")
+	require.NoError(t, err)
+	require.Len(t, chain, 2)
+	assert.Equal(t, "mem:general:a", chain[0].ID)
+	assert.Equal(t, "mem:general:b", chain[1].ID)
+}
+
+func TestSupersedeMemory_PreventsCyclesAndForks(t *testing.T) {
+	srv, store := newSupersedeServer(t, "mem:general:a", "mem:general:b", "mem:general:c", "mem:general:d")
+	ctx := context.Background()
+
+	// a <- b <- c
+	_, err := srv.SupersedeMemory(ctx, mcp.SupersedeMemoryArgs{OldID: "mem:general:a", NewID: "mem:general:b"})
+	require.NoError(t, err)
+	_, err = srv.SupersedeMemory(ctx, mcp.SupersedeMemoryArgs{OldID: "mem:general:b", NewID: "mem:general:c"})
+	require.NoError(t, err)
+
+	for name, params := range map[string]string{
+		"cycle":                  `{"old_id":"mem:general:c","new_id":"mem:general:a"}`,
+		"direct cycle":           `{"old_id":"mem:general:b","new_id":"mem:general:a"}`,
+		"new already supersedes": `{"old_id":"mem:general:d","new_id":"mem:general:c"}`,
+		"old already superseded": `{"old_id":"mem:general:a","new_id":"mem:general:d"}`,
+		"self":                   `{"old_id":"mem:general:d","new_id":"mem:general:d"}`,
+		"missing id":             `{"old_id":"mem:general:d"}`,
+	} {
+		code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"supersede_memory","params":`+params+`,"id":1}`)
+		assert.Equal(t, mcp.ErrCodeInvalidParams, code, name)
+	}
+	code := rpcErrorCode(t, srv, `{"jsonrpc":"2.0","method":"supersede_memory","params":{"old_id":"mem:general:d","new_id":"mem:general:nope"},"id":1}`)
+	assert.Equal(t, mcp.ErrCodeNotFound, code)
+
+	// Nothing changed: a still heads the chain and d is untouched.
+	a, err := store.Get(ctx, "mem:general:a")
+	require.NoError(t, err)
+	assert.Empty(t, a.SupersedesID)
+	d, err := store.Get(ctx, "mem:general:d")
+	require.NoError(t, err)
+	assert.Equal(t, types.StateActive, d.State)
+
+	// Extending the chain at its latest version is still allowed.
+	_, err = srv.SupersedeMemory(ctx, mcp.SupersedeMemoryArgs{OldID: "mem:general:c", NewID: "mem:general:d"})
+	require.NoError(t, err)
+}
+
+// failingStateStore fails every UpdateState call.
+type failingStateStore struct{ *sqlite.MemoryStore }
+
+func (s failingStateStore) UpdateState(context.Context, string, string) error {
+	return errors.New("disk full")
+}
+
+func TestSupersedeMemory_UnlinksWhenStateChangeFails(t *testing.T) {
+	_, store := newSupersedeServer(t, "mem:general:a", "mem:general:b")
+	srv := mcp.NewServer(failingStateStore{store})
+	ctx := context.Background()
+
+	_, err := srv.SupersedeMemory(ctx, mcp.SupersedeMemoryArgs{OldID: "mem:general:a", NewID: "mem:general:b"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "disk full")
+
+	newer, err := store.Get(ctx, "mem:general:b")
+	require.NoError(t, err)
+	assert.Empty(t, newer.SupersedesID)
+	old, err := store.Get(ctx, "mem:general:a")
+	require.NoError(t, err)
+	assert.Equal(t, types.StateActive, old.State)
+}
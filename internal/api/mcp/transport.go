@@ -13,43 +13,73 @@ package mcp
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"os"
+	"log/slog"
+	"sync"
 )
 
+// DefaultMaxRequestBytes is the largest request line StdioTransport accepts
+// unless WithMaxRequestBytes says otherwise.
+const DefaultMaxRequestBytes = 4 * 1024 * 1024
+
 // StdioTransport reads line-delimited JSON-RPC 2.0 requests from an io.Reader
 // and writes responses to an io.Writer.  It is the bridge between the raw
 // stdio streams and the MCP Server.
 //
-// Logging is directed exclusively to stderr via a dedicated *log.Logger so
-// that stdout is never contaminated.
+// Logging goes through the default slog logger, which the caller must point
+// at stderr (see internal/logging) so that stdout is never contaminated.
 type StdioTransport struct {
-	server  *Server
-	in      io.Reader
-	out     io.Writer
-	logger  *log.Logger
+	server          *Server
+	in              io.Reader
+	out             io.Writer
+	writeMu         sync.Mutex // serialises responses and notifications on out
+	logger          *slog.Logger
+	maxRequestBytes int // longest accepted request line (see WithMaxRequestBytes)
+}
+
+// StdioTransportOption configures optional StdioTransport behaviour.
+type StdioTransportOption func(*StdioTransport)
+
+// WithMaxRequestBytes caps the length of a request line. Longer lines are
+// discarded without being buffered in full and answered with an
+// ErrCodeInvalidRequest error. A non-positive n keeps DefaultMaxRequestBytes.
+func WithMaxRequestBytes(n int) StdioTransportOption {
+	return func(t *StdioTransport) {
+		if n > 0 {
+			t.maxRequestBytes = n
+		}
+	}
 }
 
 // NewStdioTransport constructs a StdioTransport that reads from in and writes
-// to out.  All log messages are sent to stderr so that the stdout stream stays
-// clean for JSON-RPC framing.
+// to out.  Log messages go to the default slog logger, never to out, so that
+// the stdout stream stays clean for JSON-RPC framing.
 //
 // Usage with real stdio:
 //
 //	t := mcp.NewStdioTransport(srv, os.Stdin, os.Stdout)
 //	t.Serve(ctx)
-func NewStdioTransport(srv *Server, in io.Reader, out io.Writer) *StdioTransport {
-	return &StdioTransport{
-		server: srv,
-		in:     in,
-		out:    out,
-		// Explicitly target stderr so that log output never touches stdout.
-		logger: log.New(os.Stderr, "memento-mcp: ", log.LstdFlags),
+//
+// The transport also carries the server's notifications (see
+// Server.NotifyEnrichment), which may be written between responses.
+func NewStdioTransport(srv *Server, in io.Reader, out io.Writer, opts ...StdioTransportOption) *StdioTransport {
+	t := &StdioTransport{
+		server:          srv,
+		in:              in,
+		out:             out,
+		logger:          slog.Default().With("component", "transport"),
+		maxRequestBytes: DefaultMaxRequestBytes,
+	}
+	for _, opt := range opts {
+		opt(t)
 	}
+	srv.notifications.setSender(t.writeResponse)
+	return t
 }
 
 // Serve processes JSON-RPC 2.0 requests until stdin is closed or ctx is
@@ -59,49 +89,42 @@ func NewStdioTransport(srv *Server, in io.Reader, out io.Writer) *StdioTransport
 // Each request is handled synchronously in the order it arrives.  The MCP
 // protocol does not require concurrent processing at the transport level.
 func (t *StdioTransport) Serve(ctx context.Context) error {
-	scanner := bufio.NewScanner(t.in)
-
-	// Increase the scanner buffer to handle large payloads (up to 4 MB).
-	const maxBuf = 4 * 1024 * 1024
-	buf := make([]byte, maxBuf)
-	scanner.Buffer(buf, maxBuf)
+	reader := bufio.NewReader(t.in)
 
 	for {
 		// Check context before blocking on the next line.
 		select {
 		case <-ctx.Done():
-			t.logger.Println("context cancelled – shutting down")
+			t.logger.Info("context cancelled – shutting down")
 			return ctx.Err()
 		default:
 		}
 
-		if !scanner.Scan() {
-			// EOF or error.
-			if err := scanner.Err(); err != nil {
-				t.logger.Printf("stdin scanner error: %v", err)
-				return fmt.Errorf("stdin scanner: %w", err)
+		line, size, err := t.readLine(reader)
+		if err != nil && (len(line) == 0 || !errors.Is(err, io.EOF)) {
+			if errors.Is(err, io.EOF) {
+				// Clean EOF – stdin was closed.
+				t.logger.Info("stdin closed – shutting down")
+				return nil
 			}
-			// Clean EOF – stdin was closed.
-			t.logger.Println("stdin closed – shutting down")
-			return nil
+			t.logger.Error("stdin read error", "error", err)
+			return fmt.Errorf("stdin read: %w", err)
 		}
 
-		line := scanner.Bytes()
-		if len(line) == 0 {
+		var resp []byte
+		switch {
+		case size > t.maxRequestBytes:
+			t.logger.Warn("request rejected: too large", "bytes", size, "max_bytes", t.maxRequestBytes)
+			resp = requestErrorResponse(line, ErrCodeInvalidRequest,
+				fmt.Sprintf("request of %d bytes exceeds the %d byte limit", size, t.maxRequestBytes))
+		case len(line) == 0:
 			continue
-		}
-
-		resp, err := t.server.HandleRequest(ctx, line)
-		if err != nil {
-			// HandleRequest already produced a JSON-RPC error response in most
-			// cases, but if it returned an error we synthesise one here so the
-			// caller always gets a valid response frame.
-			t.logger.Printf("handler error: %v", err)
-			resp = t.internalErrorResponse(line, err)
+		default:
+			resp = t.handle(ctx, line)
 		}
 
 		if err := t.writeResponse(resp); err != nil {
-			t.logger.Printf("write error: %v", err)
+			t.logger.Error("write error", "error", err)
 			return fmt.Errorf("write response: %w", err)
 		}
 
@@ -109,16 +132,87 @@ func (t *StdioTransport) Serve(ctx context.Context) error {
 		// the (potentially slow) handler call.
 		select {
 		case <-ctx.Done():
-			t.logger.Println("context cancelled after handler – shutting down")
+			t.logger.Info("context cancelled after handler – shutting down")
 			return ctx.Err()
 		default:
 		}
 	}
 }
 
-// writeResponse writes a single JSON-RPC response line to stdout.  A trailing
-// newline is appended so the caller can frame responses by line.
+// readLine reads the next request line without its line terminator and
+// returns it with its full length. A line longer than maxRequestBytes is
+// read to its end but not kept: only a prefix of it is returned, enough for
+// requestErrorResponse to recover the request ID when it comes first.
+func (t *StdioTransport) readLine(r *bufio.Reader) ([]byte, int, error) {
+	var line []byte
+	size := 0
+	for {
+		chunk, err := r.ReadSlice('\n')
+		size += len(chunk)
+		if len(line) <= t.maxRequestBytes {
+			line = append(line, chunk...)
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err == nil {
+			line = line[:len(line)-1]
+			size--
+		}
+		if n := len(line); n > 0 && line[n-1] == '\r' {
+			line = line[:n-1]
+			size--
+		}
+		return line, size, err
+	}
+}
+
+// handle runs one request through the server, bounded by the server's
+// request timeout (see WithRequestTimeout). A handler that is still running
+// when the deadline passes is abandoned: the client gets a timeout error now
+// and the late response is dropped. The handler itself keeps running, so if
+// it holds the store (a SQLite store has a single connection), the next
+// request that needs the store still waits for it.
+func (t *StdioTransport) handle(ctx context.Context, line []byte) []byte {
+	timeout := t.server.requestTimeout
+	if timeout <= 0 {
+		return t.serve(ctx, line)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan []byte, 1)
+	go func() { done <- t.serve(ctx, line) }()
+	select {
+	case resp := <-done:
+		return resp
+	case <-ctx.Done():
+		t.logger.Warn("request abandoned: handler exceeded the timeout", "timeout", timeout)
+		return requestErrorResponse(line, ErrCodeTimeout,
+			fmt.Sprintf("request exceeded %v: %v", timeout, ErrRequestTimeout))
+	}
+}
+
+// serve runs one request through Server.HandleRequest.
+func (t *StdioTransport) serve(ctx context.Context, line []byte) []byte {
+	resp, err := t.server.HandleRequest(ctx, line)
+	if err != nil {
+		// HandleRequest already produced a JSON-RPC error response in most
+		// cases, but if it returned an error we synthesise one here so the
+		// caller always gets a valid response frame.
+		t.logger.Error("handler error", "error", err)
+		resp = internalErrorResponse(line, err)
+	}
+	return resp
+}
+
+// writeResponse writes a single JSON-RPC response or notification line to
+// stdout.  A trailing newline is appended so the caller can frame responses by
+// line.  Notifications arrive from enrichment workers, so writes are
+// serialised to keep frames whole.
 func (t *StdioTransport) writeResponse(resp []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
 	_, err := fmt.Fprintf(t.out, "%s\n", resp)
 	return err
 }
@@ -126,19 +220,20 @@ func (t *StdioTransport) writeResponse(resp []byte) error {
 // internalErrorResponse builds a best-effort JSON-RPC error response when the
 // server returns an unexpected error.  It attempts to extract the request ID
 // from the raw request bytes so the caller can correlate the response.
-func (t *StdioTransport) internalErrorResponse(rawRequest []byte, handlerErr error) []byte {
-	// Try to recover the request ID.
-	var partial struct {
-		ID interface{} `json:"id"`
-	}
-	_ = json.Unmarshal(rawRequest, &partial)
+func internalErrorResponse(rawRequest []byte, handlerErr error) []byte {
+	return requestErrorResponse(rawRequest, ErrCodeInternalError, handlerErr.Error())
+}
 
+// requestErrorResponse builds a JSON-RPC error response with the given code
+// and message, recovering the request ID from the raw request bytes when it
+// can. A truncated request still yields its ID if "id" precedes the cut.
+func requestErrorResponse(rawRequest []byte, code int, message string) []byte {
 	resp := JSONRPCResponse{
 		JSONRPC: "2.0",
-		ID:      partial.ID,
+		ID:      requestID(rawRequest),
 		Error: &JSONRPCError{
-			Code:    ErrCodeInternalError,
-			Message: handlerErr.Error(),
+			Code:    code,
+			Message: message,
 		},
 	}
 
@@ -150,3 +245,31 @@ func (t *StdioTransport) internalErrorResponse(rawRequest []byte, handlerErr err
 	}
 	return data
 }
+
+// requestID returns the "id" member of a raw JSON-RPC request, or nil when
+// there is none. It reads the top-level members in order, so it also works
+// on a request cut short after its ID.
+func requestID(rawRequest []byte) interface{} {
+	dec := json.NewDecoder(bytes.NewReader(rawRequest))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		if key == "id" {
+			var id interface{}
+			if err := dec.Decode(&id); err != nil {
+				return nil
+			}
+			return id
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package mcp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stdioClient drives a StdioTransport over pipes, one JSON-RPC frame per line.
+type stdioClient struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	frames chan map[string]interface{}
+}
+
+func newStdioClient(t *testing.T, srv *mcp.Server, opts ...mcp.StdioTransportOption) *stdioClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	transport := mcp.NewStdioTransport(srv, inR, outW, opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { _ = transport.Serve(ctx) }()
+
+	frames := make(chan map[string]interface{}, 16)
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		scanner.Buffer(nil, mcp.DefaultMaxRequestBytes)
+		for scanner.Scan() {
+			var frame map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &frame); err == nil {
+				frames <- frame
+			}
+		}
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		_ = inW.Close()
+		_ = outW.Close()
+	})
+	return &stdioClient{t: t, in: inW, frames: frames}
+}
+
+func (c *stdioClient) send(line string) {
+	c.t.Helper()
+	_, err := io.WriteString(c.in, line+"\n")
+	require.NoError(c.t, err)
+}
+
+// next returns the next frame written by the transport.
+func (c *stdioClient) next() map[string]interface{} {
+	c.t.Helper()
+	select {
+	case frame := <-c.frames:
+		return frame
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timeout waiting for a frame from the transport")
+		return nil
+	}
+}
+
+func TestStdioTransport_EnrichmentNotification(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	cfg := engine.DefaultConfig()
+	cfg.NumWorkers = 1
+	eng, err := engine.NewMemoryEngine(store, cfg, nil)
+	require.NoError(t, err)
+
+	srv := mcp.NewServer(store, mcp.WithEngine(eng))
+	eng.SetOnEnrichmentComplete(func(memoryID string) {
+		srv.NotifyEnrichment(memoryID, types.StatusEnriched)
+	})
+
+	ctx := context.Background()
+	require.NoError(t, eng.Start(ctx))
+	t.Cleanup(func() { _ = eng.Shutdown(ctx) })
+
+	client := newStdioClient(t, srv)
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{"experimental":{"enrichmentNotifications":{}}},"clientInfo":{"name":"test"}}}`)
+	initResp := client.next()
+	caps := initResp["result"].(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.Contains(t, caps["experimental"], mcp.EnrichmentNotificationsCapability)
+
+	client.send(`{"jsonrpc":"2.0","id":2,"method":"store_memory","params":{"content":"notify me when enriched"}}`)
+
+	// The store response and the notification may arrive in either order.
+	var memoryID string
+	var notification map[string]interface{}
+	for memoryID == "" || notification == nil {
+		frame := client.next()
+		if frame["method"] == mcp.EnrichmentNotificationMethod {
+			notification = frame
+			continue
+		}
+		memoryID = frame["result"].(map[string]interface{})["id"].(string)
+	}
+
+	assert.NotContains(t, notification, "id", "notifications carry no request ID")
+	params := notification["params"].(map[string]interface{})
+	assert.Equal(t, memoryID, params["memory_id"])
+	assert.Equal(t, string(types.StatusEnriched), params["status"])
+}
+
+func TestStdioTransport_NoNotificationsWithoutCapability(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+	client := newStdioClient(t, srv)
+
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test"}}}`)
+	client.next()
+
+	srv.NotifyEnrichment("mem:general:abc", types.StatusFailed)
+
+	// The next frame must be the response to this request, not a notification.
+	client.send(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	frame := client.next()
+	assert.Equal(t, float64(2), frame["id"])
+	assert.NotContains(t, frame, "method")
+}
+
+func TestStdioTransport_RejectsOversizedRequest(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+	client := newStdioClient(t, srv, mcp.WithMaxRequestBytes(1024))
+
+	// Longer than both the limit and bufio's default buffer.
+	client.send(`{"jsonrpc":"2.0","id":3,"method":"store_memory","params":{"content":"` + strings.Repeat("x", 10000) + `"}}`)
+	frame := client.next()
+	assert.Equal(t, float64(3), frame["id"])
+	rpcErr := frame["error"].(map[string]interface{})
+	assert.Equal(t, float64(mcp.ErrCodeInvalidRequest), rpcErr["code"])
+	assert.Contains(t, rpcErr["message"], "1024 byte limit")
+
+	// The transport stays in sync and serves the next request.
+	client.send(`{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)
+	frame = client.next()
+	assert.Equal(t, float64(4), frame["id"])
+	assert.Contains(t, frame, "result")
+}
+
+// wedgedStore is a mockStore whose List ignores its context and blocks until
+// release is closed, like an LLM call that never returns.
+type wedgedStore struct {
+	*mockStore
+	release chan struct{}
+}
+
+func (w *wedgedStore) List(ctx context.Context, opts storage.ListOptions) (*storage.PaginatedResult[types.Memory], error) {
+	<-w.release
+	return w.mockStore.List(ctx, opts)
+}
+
+func TestStdioTransport_RequestTimeout(t *testing.T) {
+	store := &wedgedStore{mockStore: newMockStore(), release: make(chan struct{})}
+	t.Cleanup(func() { close(store.release) })
+	srv := mcp.NewServer(store, mcp.WithRequestTimeout(50*time.Millisecond))
+	client := newStdioClient(t, srv)
+
+	client.send(`{"jsonrpc":"2.0","id":7,"method":"list_projects","params":{}}`)
+	frame := client.next()
+	assert.Equal(t, float64(7), frame["id"])
+	rpcErr := frame["error"].(map[string]interface{})
+	assert.Equal(t, float64(mcp.ErrCodeTimeout), rpcErr["code"])
+	assert.Contains(t, rpcErr["message"], mcp.ErrRequestTimeout.Error())
+
+	// The wedged handler still runs, but no longer blocks the transport for
+	// requests that do not need the store.
+	client.send(`{"jsonrpc":"2.0","id":8,"method":"tools/list"}`)
+	frame = client.next()
+	assert.Equal(t, float64(8), frame["id"])
+	assert.Contains(t, frame, "result")
+}
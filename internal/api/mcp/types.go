@@ -5,20 +5,32 @@ package mcp
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
+	"github.com/scrypster/memento/internal/connections"
+	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/pkg/types"
 )
 
 // StoreMemoryArgs contains arguments for the store_memory tool.
 type StoreMemoryArgs struct {
-	Content      string                 `json:"content"`                 // Memory content (required)
-	Source       string                 `json:"source,omitempty"`        // Source of the memory
-	Domain       string                 `json:"domain,omitempty"`        // Memory domain/category (deprecated: use connection_id)
-	ConnectionID string                 `json:"connection_id,omitempty"` // Connection to store into (sets domain)
-	Tags         []string               `json:"tags,omitempty"`          // User-defined tags
-	Metadata     map[string]interface{} `json:"metadata,omitempty"`      // Arbitrary metadata
-	CreatedBy    string                 `json:"created_by,omitempty"`    // Name of the agent or developer storing this memory. Auto-detected if not provided.
-	SessionID    string                 `json:"session_id,omitempty"`    // Session ID override; uses server session ID if not provided.
+	Content           string                 `json:"content"`                       // Memory content (required)
+	Source            string                 `json:"source,omitempty"`              // Source of the memory
+	Domain            string                 `json:"domain,omitempty"`              // Memory domain/category (deprecated: use connection_id)
+	ConnectionID      string                 `json:"connection_id,omitempty"`       // Connection to store into (sets domain)
+	Tags              []string               `json:"tags,omitempty"`                // User-defined tags
+	Metadata          map[string]interface{} `json:"metadata,omitempty"`            // Arbitrary metadata
+	CreatedBy         string                 `json:"created_by,omitempty"`          // Name of the agent or developer storing this memory. Auto-detected if not provided.
+	SessionID         string                 `json:"session_id,omitempty"`          // Session ID override; uses server session ID if not provided.
+	ExpiresAt         string                 `json:"expires_at,omitempty"`          // RFC-3339 time after which the memory is hidden and later soft-deleted.
+	AuthorType        string                 `json:"author_type,omitempty"`         // "human", "agent" or "system". Derived from created_by detection if not provided.
+	Truncate          bool                   `json:"truncate,omitempty"`            // Accept content over the length limit, enriching only its first part.
+	IdempotencyKey    string                 `json:"idempotency_key,omitempty"`     // Client retry key; a repeat within the TTL returns the first result.
+	WaitForEnrichment bool                   `json:"wait_for_enrichment,omitempty"` // Block until enrichment finishes or fails and return the enriched memory.
+	TimeoutSeconds    int                    `json:"timeout_seconds,omitempty"`     // Longest wait for wait_for_enrichment; 0 uses the server default.
+
+	memoryType string // memory_type set by create_typed_memory; store_memory leaves it to classification
 }
 
 // UnmarshalJSON handles the case where some MCP clients (e.g. Claude Code) send
@@ -65,15 +77,27 @@ func (a *StoreMemoryArgs) UnmarshalJSON(data []byte) error {
 }
 
 // StoreMemoryResult contains the result of storing a memory.
-// In v2.0, this returns immediately with pending status.
+// In v2.0, this returns immediately with pending status unless the call set
+// wait_for_enrichment.
 type StoreMemoryResult struct {
-	ID         string             `json:"id"`                      // Memory ID
-	Status     types.MemoryStatus `json:"status"`                  // Memory status (always "pending" in v2.0)
-	Message    string             `json:"message"`                 // Status message
-	Duplicate  bool               `json:"duplicate,omitempty"`     // If true, content was a duplicate
-	ExistingID string             `json:"existing_id,omitempty"`   // ID of existing memory if duplicate
+	ID         string             `json:"id"`                    // Memory ID
+	Status     types.MemoryStatus `json:"status"`                // Memory status ("pending" unless wait_for_enrichment was set)
+	Message    string             `json:"message"`               // Status message
+	Duplicate  bool               `json:"duplicate,omitempty"`   // If true, content was a duplicate
+	ExistingID string             `json:"existing_id,omitempty"` // ID of existing memory if duplicate
+	Truncated  bool               `json:"truncated,omitempty"`   // If true, only the first part of the content is enriched
+	Replayed   bool               `json:"replayed,omitempty"`    // If true, this is the cached result of an earlier call with the same idempotency_key
+	Memory     *types.Memory      `json:"memory,omitempty"`      // The memory after enrichment, when wait_for_enrichment was set
+	Enrichment string             `json:"enrichment,omitempty"`  // EnrichmentQueued, EnrichmentDeferred or EnrichmentDisabled; empty for duplicates or without an engine
 }
 
+// Values of StoreMemoryResult.Enrichment.
+const (
+	EnrichmentQueued   = "queued"   // the enrichment job is in the engine's queue
+	EnrichmentDeferred = "deferred" // the queue was full; the memory stays pending until the engine's pending rescan queues it
+	EnrichmentDisabled = "disabled" // the connection has enrichment disabled; the memory is stored as enriched with every step skipped
+)
+
 // RecallMemoryArgs contains arguments for the recall_memory tool.
 //
 // Priority order:
@@ -102,6 +126,15 @@ type RecallMemoryArgs struct {
 	// CreatedBy filters by the agent or user that created the memory.
 	CreatedBy string `json:"created_by,omitempty"`
 
+	// AuthorType filters by authorship origin: "human", "agent" or "system".
+	// Applies in both query and list mode.
+	AuthorType string `json:"author_type,omitempty"`
+
+	// MemoryType filters by memory_type, e.g. "decision" for memories
+	// created from the decision template. Applies in both query and list
+	// mode.
+	MemoryType string `json:"memory_type,omitempty"`
+
 	// CreatedAfter is an ISO-8601 / RFC-3339 timestamp.  Only memories
 	// created strictly after this time are returned.
 	CreatedAfter string `json:"created_after,omitempty"`
@@ -110,17 +143,50 @@ type RecallMemoryArgs struct {
 	// created strictly before this time are returned.
 	CreatedBefore string `json:"created_before,omitempty"`
 
+	// AccessedAfter is an ISO-8601 / RFC-3339 timestamp. Only memories last
+	// accessed strictly after this time are returned; memories never
+	// accessed are left out. List mode only.
+	AccessedAfter string `json:"accessed_after,omitempty"`
+
+	// AccessedBefore is an ISO-8601 / RFC-3339 timestamp. Only memories last
+	// accessed strictly before this time are returned; memories never
+	// accessed are left out. List mode only.
+	AccessedBefore string `json:"accessed_before,omitempty"`
+
+	// Metadata keeps only memories whose metadata holds every key with an
+	// equal string, number or boolean value, e.g. {"project": "atlas"}.
+	// Types must match: 5 does not match "5". Applies in both query and
+	// list mode.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
 	// MinDecayScore filters to memories whose decay_score is >= this value.
 	// Accepts values in the range [0.0, 1.0].
 	MinDecayScore float64 `json:"min_decay_score,omitempty"`
 
-	// Limit controls how many memories to return (default 10, max 100).
+	// MaxDecayScore filters to memories whose decay_score is <= this value;
+	// it must not be below MinDecayScore. Zero means no upper bound.
+	MaxDecayScore float64 `json:"max_decay_score,omitempty"`
+
+	// Limit controls how many memories to return (default 10, max 100; both
+	// configurable with WithResultLimits).
 	// Ignored when ID is set.
 	Limit int `json:"limit,omitempty"`
 
 	// Page is the 1-indexed page number in list mode (default 1).
 	// Ignored when ID or Query is set.
 	Page int `json:"page,omitempty"`
+
+	// IncludeExpired includes memories whose expires_at has passed but which
+	// have not yet been swept. Ignored when ID is set.
+	IncludeExpired bool `json:"include_expired,omitempty"`
+
+	// SortBy orders list mode by one of storage.SortFields (default
+	// created_at). Ignored when ID or Query is set.
+	SortBy string `json:"sort_by,omitempty"`
+
+	// SortOrder is "asc" or "desc" (default desc). Ignored when ID or Query
+	// is set.
+	SortOrder string `json:"sort_order,omitempty"`
 }
 
 // RecallMemoryResult contains the result of recalling a memory.
@@ -145,11 +211,39 @@ type RecallMemoryResult struct {
 
 	// HasMore indicates whether additional pages exist (list-filter mode).
 	HasMore bool `json:"has_more,omitempty"`
+
+	// Limit is the page size actually applied after defaulting and clamping
+	// (query and list-filter modes).
+	Limit int `json:"limit,omitempty"`
+
+	// EnrichmentSummary describes Memory's enrichment progress, e.g.
+	// "3/5 complete, embedding pending" (ID-lookup mode). The per-step
+	// statuses are on the memory itself.
+	EnrichmentSummary string `json:"enrichment_summary,omitempty"`
+
+	// EnrichmentSummaries maps each ID in Memories to its enrichment summary
+	// (query and list-filter modes).
+	EnrichmentSummaries map[string]string `json:"enrichment_summaries,omitempty"`
+}
+
+// GetMemoryArgs contains arguments for the get_memory tool.
+type GetMemoryArgs struct {
+	ID           string `json:"id"`                      // Memory ID (required)
+	ConnectionID string `json:"connection_id,omitempty"` // Connection to read from (inferred from the ID if omitted)
+}
+
+// GetMemoryResult contains the result of the get_memory tool.
+type GetMemoryResult struct {
+	Found             bool          `json:"found"`                        // Whether the memory exists
+	Memory            *types.Memory `json:"memory,omitempty"`             // The memory, when found
+	EnrichmentSummary string        `json:"enrichment_summary,omitempty"` // Enrichment progress, e.g. "3/5 complete, embedding pending"
 }
 
 // FindRelatedArgs contains arguments for the find_related tool.
 type FindRelatedArgs struct {
 	Query        string `json:"query"`                   // Search query (required)
+	ExpandQuery  bool   `json:"expand_query,omitempty"`  // Also search LLM-suggested synonyms of the query (see WithQueryExpansion)
+	Fuzzy        bool   `json:"fuzzy,omitempty"`         // Also return memories with near-miss spellings of the query words (see WithFuzzySearch)
 	Limit        int    `json:"limit,omitempty"`         // Maximum number of results (default: 10)
 	Domain       string `json:"domain,omitempty"`        // Filter by domain
 	ConnectionID string `json:"connection_id,omitempty"` // Scope search to a specific connection (default: all)
@@ -163,12 +257,62 @@ type FindRelatedArgs struct {
 	// strictly before this time are considered during graph traversal.
 	// Empty string means no upper bound.
 	CreatedBefore string `json:"created_before,omitempty"`
+
+	// IncludeExpired includes memories whose expires_at has passed but which
+	// have not yet been swept.
+	IncludeExpired bool `json:"include_expired,omitempty"`
+
+	// AuthorType filters results by authorship origin: "human", "agent" or
+	// "system". Empty string means no filter.
+	AuthorType string `json:"author_type,omitempty"`
+
+	// MemoryType filters results by memory_type, e.g. "decision". Empty
+	// string means no filter.
+	MemoryType string `json:"memory_type,omitempty"`
+
+	// MinSimilarity drops semantic (vector) matches whose cosine similarity
+	// to the query is below this value, between 0 and 1. Keyword matches are
+	// kept. 0 means no cutoff.
+	MinSimilarity float64 `json:"min_similarity,omitempty"`
+
+	// Tags restricts results to memories carrying these tags, as directed
+	// by TagsMatch. Empty means no filter.
+	Tags []string `json:"tags,omitempty"`
+
+	// TagsMatch is "any" (default) to require at least one of Tags or
+	// "all" to require every one of them.
+	TagsMatch string `json:"tags_match,omitempty"`
+
+	// Metadata restricts results to memories whose metadata holds every key
+	// with an equal string, number or boolean value. Empty means no filter.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// Tag match modes for FindRelatedArgs.TagsMatch.
+const (
+	TagsMatchAny = "any"
+	TagsMatchAll = "all"
+)
+
 // FindRelatedResult contains the result of searching for related memories.
+//
+// TotalMatches counts every memory the search matched, before the limit and
+// before the created_after/created_before, domain, author_type and
+// memory_type filters are applied to the top results; Total counts the
+// memories returned. The tags and metadata filters are applied by the search
+// itself where the store supports them, so TotalMatches only counts matching
+// memories. Total
+// can therefore be below min(Limit, TotalMatches) when filters drop results,
+// and TotalMatches > Total means more matches exist ("showing 10 of 147").
 type FindRelatedResult struct {
-	Memories []types.Memory `json:"memories"` // List of related memories
-	Total    int            `json:"total"`    // Total number of matches
+	Memories     []types.Memory `json:"memories"`      // List of related memories
+	Total        int            `json:"total"`         // Number of memories returned
+	TotalMatches int            `json:"total_matches"` // Matches reported by the search, before limit and filters
+	Limit        int            `json:"limit"`         // Limit applied after defaulting and clamping
+
+	// ExpandedTerms lists the synonyms searched in addition to the query
+	// when expand_query was set; empty when expansion was off or failed.
+	ExpandedTerms []string `json:"expanded_terms,omitempty"`
 }
 
 // RetryEnrichmentArgs contains arguments for the retry_enrichment tool.
@@ -216,15 +360,104 @@ type UpdateMemoryStateResult struct {
 	Message       string `json:"message"`        // Status message
 }
 
+// BatchUpdateStateArgs contains arguments for the batch_update_state tool.
+type BatchUpdateStateArgs struct {
+	// Exactly one of IDs or Query must be provided.
+	IDs          []string `json:"ids,omitempty"`           // Memory IDs to transition
+	Query        string   `json:"query,omitempty"`         // Full-text query selecting the memories
+	Limit        int      `json:"limit,omitempty"`         // Max memories matched by query (default 10, max 100)
+	State        string   `json:"state"`                   // New lifecycle state (required)
+	ConnectionID string   `json:"connection_id,omitempty"` // Connection (inferred from the first ID if omitted)
+}
+
+// BatchStateResult is the outcome of one memory's transition in
+// batch_update_state.
+type BatchStateResult struct {
+	ID            string `json:"id"`                       // Memory ID
+	Success       bool   `json:"success"`                  // Whether the transition was applied
+	PreviousState string `json:"previous_state,omitempty"` // State before the call
+	Error         string `json:"error,omitempty"`          // Why the transition was not applied
+}
+
+// BatchUpdateStateResult contains the result of batch_update_state.
+type BatchUpdateStateResult struct {
+	State   string             `json:"state"`   // Target state
+	Updated int                `json:"updated"` // Transitions applied
+	Failed  int                `json:"failed"`  // Transitions not applied
+	Results []BatchStateResult `json:"results"` // One entry per ID, in request order
+}
+
+// GetStateHistoryArgs contains arguments for the get_state_history tool.
+type GetStateHistoryArgs struct {
+	ID           string `json:"id"`                      // Memory ID (required)
+	ConnectionID string `json:"connection_id,omitempty"` // Connection to read from (inferred from the ID if omitted)
+}
+
+// StateHistoryEntry is one lifecycle transition in a get_state_history
+// timeline.
+type StateHistoryEntry struct {
+	From      string    `json:"from,omitempty"`       // State before; empty when none was set
+	To        string    `json:"to"`                   // State entered
+	ChangedAt time.Time `json:"changed_at"`           // When the transition happened
+	ChangedBy string    `json:"changed_by,omitempty"` // Agent or user that made it, when known
+	// DurationSeconds is how long the memory stayed in To: until the next
+	// transition, or until now for the latest one.
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+// GetStateHistoryResult contains the result of get_state_history.
+type GetStateHistoryResult struct {
+	ID             string              `json:"id"`                         // Memory ID
+	CurrentState   string              `json:"current_state,omitempty"`    // Current lifecycle state
+	StateUpdatedAt *time.Time          `json:"state_updated_at,omitempty"` // When the current state was entered
+	Transitions    []StateHistoryEntry `json:"transitions"`                // Oldest first
+	Total          int                 `json:"total"`                      // Number of transitions
+}
+
+// CreateTypedMemoryArgs contains arguments for the create_typed_memory tool.
+type CreateTypedMemoryArgs struct {
+	Template       string                 `json:"template"`                  // Template name, e.g. "decision" (required)
+	Fields         map[string]interface{} `json:"fields"`                    // Field values: strings or lists of strings (required)
+	ConnectionID   string                 `json:"connection_id,omitempty"`   // Connection to store into
+	Source         string                 `json:"source,omitempty"`          // Source of the memory
+	Tags           []string               `json:"tags,omitempty"`            // User-defined tags
+	CreatedBy      string                 `json:"created_by,omitempty"`      // Name of the agent or developer storing this memory. Auto-detected if not provided.
+	AuthorType     string                 `json:"author_type,omitempty"`     // "human", "agent" or "system"
+	IdempotencyKey string                 `json:"idempotency_key,omitempty"` // Client retry key, as for store_memory
+}
+
+// ListMemoryTemplatesArgs contains arguments for the list_memory_templates
+// tool. It takes none.
+type ListMemoryTemplatesArgs struct{}
+
+// ListMemoryTemplatesResult contains the result of list_memory_templates.
+type ListMemoryTemplatesResult struct {
+	Templates []types.MemoryTemplate `json:"templates"` // Built-in and configured templates
+	Total     int                    `json:"total"`     // Number of templates
+}
+
 // DetectContradictionsArgs contains arguments for the detect_contradictions tool.
 type DetectContradictionsArgs struct {
 	// MemoryID is optional. If provided, only contradictions involving this memory are returned.
 	MemoryID string `json:"memory_id,omitempty"`
+
+	// Semantic additionally asks the LLM whether the memory's most similar
+	// memories contradict it. Requires MemoryID and a server started with
+	// WithSemanticContradictions.
+	Semantic bool `json:"semantic,omitempty"`
+
+	// TopK is how many similar memories the semantic check compares against
+	// (default 5, max 20). Ignored unless Semantic is set.
+	TopK int `json:"top_k,omitempty"`
+
+	// MinConfidence drops contradictions whose confidence is below it
+	// (0.0-1.0, default 0: keep all).
+	MinConfidence float64 `json:"min_confidence,omitempty"`
 }
 
 // ContradictionResult represents a single detected contradiction.
 type ContradictionResult struct {
-	Type        string   `json:"type"`        // Contradiction type (conflicting_relationship, superseded_active, temporal_impossibility)
+	Type        string   `json:"type"`        // Contradiction type (conflicting_relationship, superseded_active, temporal_impossibility, semantic)
 	MemoryIDs   []string `json:"memory_ids"`  // Memory IDs involved in this contradiction
 	Description string   `json:"description"` // Human-readable description
 	Confidence  float64  `json:"confidence"`  // Confidence score (0.0-1.0)
@@ -232,9 +465,102 @@ type ContradictionResult struct {
 
 // DetectContradictionsResult contains the result of contradiction detection.
 type DetectContradictionsResult struct {
-	Contradictions []ContradictionResult `json:"contradictions"` // List of detected contradictions
-	Total          int                   `json:"total"`          // Total number of contradictions detected
-	Message        string                `json:"message"`        // Status message
+	Contradictions []ContradictionResult `json:"contradictions"`  // Detected contradictions, highest confidence first
+	Total          int                   `json:"total"`           // Number of contradictions returned
+	BelowThreshold int                   `json:"below_threshold"` // Contradictions dropped by min_confidence
+	Message        string                `json:"message"`         // Status message
+}
+
+// ResolveContradictionArgs contains arguments for the resolve_contradiction tool.
+type ResolveContradictionArgs struct {
+	// Contradiction is the contradiction as returned by detect_contradictions.
+	Contradiction ContradictionResult `json:"contradiction"`
+
+	// WinnerID is the memory to keep; it must be one of Contradiction.MemoryIDs.
+	WinnerID string `json:"winner_id"`
+
+	// Action applied to every other memory: "supersede" (default) or "archive".
+	Action string `json:"action,omitempty"`
+
+	// RecordRelationship creates a SUPERSEDES link from the winner to each loser.
+	RecordRelationship bool `json:"record_relationship,omitempty"`
+}
+
+// ResolvedMemoryState describes one memory's state after resolve_contradiction.
+type ResolvedMemoryState struct {
+	ID            string `json:"id"`
+	Role          string `json:"role"` // winner or loser
+	PreviousState string `json:"previous_state"`
+	State         string `json:"state"`
+}
+
+// ResolveContradictionResult contains the result of resolving a contradiction.
+type ResolveContradictionResult struct {
+	WinnerID     string                `json:"winner_id"`
+	Action       string                `json:"action"`
+	Memories     []ResolvedMemoryState `json:"memories"`
+	LinksCreated int                   `json:"links_created"`
+	Message      string                `json:"message"`
+}
+
+// RecomputeDecayArgs contains arguments for the recompute_decay tool.
+type RecomputeDecayArgs struct {
+	// ConnectionID selects the connection whose store is recomputed
+	// (default: the default connection).
+	ConnectionID string `json:"connection_id,omitempty"`
+}
+
+// RecomputeDecayResult contains the result of recompute_decay.
+type RecomputeDecayResult struct {
+	ConnectionID string `json:"connection_id,omitempty"` // Connection recomputed; empty for the default store
+	Updated      int    `json:"updated"`                 // Number of memories whose decay score was updated
+	Message      string `json:"message"`
+}
+
+// GetDecayReportArgs contains arguments for the get_decay_report tool.
+type GetDecayReportArgs struct {
+	// Threshold selects memories whose decay score is at or below it, in (0, 1]
+	// (default DefaultDecayReportThreshold).
+	Threshold    float64 `json:"threshold,omitempty"`
+	ConnectionID string  `json:"connection_id,omitempty"` // Connection to report on (default: the default connection)
+	Limit        int     `json:"limit,omitempty"`         // Max results (default 10)
+	Page         int     `json:"page,omitempty"`          // Page number (default 1)
+}
+
+// DecayReportEntry is one fading memory in a get_decay_report result.
+type DecayReportEntry struct {
+	ID              string   `json:"id"`
+	Content         string   `json:"content"` // First 200 characters
+	State           string   `json:"state,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	DecayScore      float64  `json:"decay_score"`
+	AccessCount     int      `json:"access_count"`
+	LastAccessedAt  string   `json:"last_accessed_at,omitempty"` // RFC-3339; empty if never accessed
+	DaysSinceAccess int      `json:"days_since_access"`          // Since last access, or since creation if never accessed
+}
+
+// GetDecayReportResult contains the result of get_decay_report.
+type GetDecayReportResult struct {
+	Memories  []DecayReportEntry `json:"memories"`  // Lowest decay score first
+	Threshold float64            `json:"threshold"` // Threshold applied
+	Total     int                `json:"total"`     // Memories below the threshold
+	Page      int                `json:"page"`
+	HasMore   bool               `json:"has_more"`
+	Limit     int                `json:"limit"` // Limit applied after defaulting and clamping
+}
+
+// GetConnectionStatusResult contains the result of get_connection_status.
+type GetConnectionStatusResult struct {
+	DefaultConnection string                         `json:"default_connection,omitempty"`
+	Connections       []connections.ConnectionStatus `json:"connections"`
+	Message           string                         `json:"message"`
+}
+
+// GetEngineStatusResult contains the result of get_engine_status.
+type GetEngineStatusResult struct {
+	Available bool                 `json:"available"`        // false when the server runs without an enrichment engine
+	Engine    *engine.EngineStatus `json:"engine,omitempty"`
+	Message   string               `json:"message"`
 }
 
 // UpdateMemoryArgs contains arguments for the update_memory tool.
@@ -243,12 +569,27 @@ type UpdateMemoryArgs struct {
 	ID string `json:"id"`
 	// Content replaces the memory content when non-empty.
 	Content string `json:"content,omitempty"`
-	// Tags replaces the tags list when non-nil.
+	// Tags updates the tags list when non-nil, as directed by TagsMode.
 	Tags []string `json:"tags,omitempty"`
-	// Metadata replaces the metadata map when non-nil.
+	// TagsMode controls how Tags is applied: "replace" (default) overwrites
+	// the list, "append" adds tags not already present and "remove" drops
+	// the given tags.
+	TagsMode string `json:"tags_mode,omitempty"`
+	// Metadata replaces the metadata map when non-nil, unless MetadataMerge
+	// is set.
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// MetadataMerge merges Metadata into the existing map instead of
+	// replacing it. Keys whose value is null are deleted.
+	MetadataMerge bool `json:"metadata_merge,omitempty"`
 }
 
+// Tag update modes for UpdateMemoryArgs.TagsMode.
+const (
+	TagsModeReplace = "replace"
+	TagsModeAppend  = "append"
+	TagsModeRemove  = "remove"
+)
+
 // UpdateMemoryResult contains the result of updating a memory.
 type UpdateMemoryResult struct {
 	ID      string `json:"id"`      // Memory ID
@@ -265,6 +606,10 @@ type GetSessionContextArgs struct {
 	// TimeWindowH restricts results to memories created in the last N hours.
 	// Zero means no time window filter (all memories in the session).
 	TimeWindowH int `json:"time_window_hours,omitempty"`
+	// CreatedAfter and CreatedBefore are optional RFC-3339 bounds on when the
+	// memories were created.
+	CreatedAfter  string `json:"created_after,omitempty"`
+	CreatedBefore string `json:"created_before,omitempty"`
 	// ConnectionID scopes the query to a specific connection.
 	ConnectionID string `json:"connection_id,omitempty"`
 }
@@ -275,41 +620,95 @@ type SessionTopicSummary struct {
 	Count  int    `json:"count"`
 }
 
-// GetSessionContextResult contains the result of get_session_context.
+// GetSessionContextResult contains the result of get_session_context. In
+// cross-session mode SessionID is empty and Sessions groups the memories.
 type GetSessionContextResult struct {
 	SessionID      string                `json:"session_id"`
+	Label          string                `json:"label,omitempty"` // Label given to begin_session, if any
 	MemoryCount    int                   `json:"memory_count"`
 	Memories       []types.Memory        `json:"memories"`
 	Topics         []SessionTopicSummary `json:"topics"`
 	Summary        string                `json:"summary"`
+	Sessions       []SessionSummary      `json:"sessions,omitempty"`        // Per-session breakdown (cross-session mode only)
 	OnboardingHint string                `json:"onboarding_hint,omitempty"` // Set when the store is empty
 }
 
+// SessionSummary describes one session's memories in a cross-session
+// get_session_context result. An empty SessionID groups memories stored
+// without a session.
+type SessionSummary struct {
+	SessionID     string                `json:"session_id"`
+	Label         string                `json:"label,omitempty"` // Label given to begin_session, if any
+	MemoryCount   int                   `json:"memory_count"`
+	FirstActivity string                `json:"first_activity"` // RFC-3339 creation time of the oldest memory
+	LastActivity  string                `json:"last_activity"`  // RFC-3339 creation time of the newest memory
+	Topics        []SessionTopicSummary `json:"topics"`
+	Summary       string                `json:"summary"`
+}
+
+// GetCurrentSessionResult contains the result of get_current_session.
+type GetCurrentSessionResult struct {
+	SessionID          string `json:"session_id"`           // Session new memories are tagged with
+	Label              string `json:"label,omitempty"`      // Label given to begin_session, if the session was begun explicitly
+	StartedAt          string `json:"started_at"`           // RFC-3339 time the session started
+	LastActivity       string `json:"last_activity"`        // RFC-3339 time of the last tool call
+	IdleTimeoutSeconds int    `json:"idle_timeout_seconds"` // Idle gap that starts a new session; 0 = never
+	TTLSeconds         int    `json:"ttl_seconds"`          // Maximum session age before a new one starts; 0 = no limit
+	ExpiresAt          string `json:"expires_at,omitempty"` // RFC-3339 time the session reaches its TTL, when one is set
+}
+
+// BeginSessionArgs contains arguments for the begin_session tool.
+type BeginSessionArgs struct {
+	Label string `json:"label,omitempty"` // Human-readable name, e.g. "refactor auth module"
+}
+
+// SessionInfo describes a session started with begin_session.
+type SessionInfo struct {
+	SessionID string `json:"session_id"`
+	Label     string `json:"label,omitempty"`
+	StartedAt string `json:"started_at"`         // RFC-3339
+	EndedAt   string `json:"ended_at,omitempty"` // RFC-3339; empty while the session is open
+}
+
 // ForgetMemoryArgs contains arguments for the forget_memory tool.
 type ForgetMemoryArgs struct {
-	ID           string `json:"id"`                       // Memory ID to delete (required)
-	ConnectionID string `json:"connection_id,omitempty"`  // Connection the memory lives in (inferred from ID if omitted)
-	HardDelete   bool   `json:"hard_delete,omitempty"`    // if true, purge permanently
+	ID           string `json:"id"`                      // Memory ID to delete (required)
+	ConnectionID string `json:"connection_id,omitempty"` // Connection the memory lives in (inferred from ID if omitted)
+	HardDelete   bool   `json:"hard_delete,omitempty"`   // if true, purge permanently
 }
 
 // ForgetMemoryResult contains the result of forgetting a memory.
 type ForgetMemoryResult struct {
-	ID      string `json:"id"`                // Memory ID
-	Deleted bool   `json:"deleted"`           // Whether the memory was deleted
-	Purged  bool   `json:"purged,omitempty"`  // Whether the memory was permanently purged
+	ID      string `json:"id"`               // Memory ID
+	Deleted bool   `json:"deleted"`          // Whether the memory was deleted
+	Purged  bool   `json:"purged,omitempty"` // Whether the memory was permanently purged
 }
 
 // EvolveMemoryArgs contains arguments for the evolve_memory tool.
 type EvolveMemoryArgs struct {
-	ID           string `json:"id"`                       // Existing memory to supersede (required)
-	NewContent   string `json:"new_content"`              // Content for the new version (required)
-	ConnectionID string `json:"connection_id,omitempty"`  // Connection the memory lives in (inferred from ID if omitted)
+	ID           string `json:"id"`                      // Existing memory to supersede (required)
+	NewContent   string `json:"new_content"`             // Content for the new version (required)
+	ConnectionID string `json:"connection_id,omitempty"` // Connection the memory lives in (inferred from ID if omitted)
 }
 
 // EvolveMemoryResult contains the result of evolving a memory.
 type EvolveMemoryResult struct {
-	NewID        string `json:"new_id"`         // ID of the new memory
-	SupersededID string `json:"superseded_id"`  // ID of the old memory (now state=superseded)
+	NewID        string `json:"new_id"`        // ID of the new memory
+	SupersededID string `json:"superseded_id"` // ID of the old memory (now state=superseded)
+}
+
+// SupersedeMemoryArgs contains arguments for the supersede_memory tool.
+type SupersedeMemoryArgs struct {
+	OldID        string `json:"old_id"`                  // Memory being replaced (required)
+	NewID        string `json:"new_id"`                  // Memory that replaces it (required)
+	ConnectionID string `json:"connection_id,omitempty"` // Connection both memories live in (inferred from the IDs if omitted)
+}
+
+// SupersedeMemoryResult contains the result of supersede_memory.
+type SupersedeMemoryResult struct {
+	OldID         string `json:"old_id"`         // Now state=superseded
+	NewID         string `json:"new_id"`         // Now has supersedes_id=old_id
+	PreviousState string `json:"previous_state"` // State of old_id before it was superseded
 }
 
 // ConsolidateMemoriesArgs holds arguments for consolidate_memories tool.
@@ -324,22 +723,22 @@ type ConsolidateMemoriesArgs struct {
 
 // ConsolidateMemoriesResult is returned by consolidate_memories.
 type ConsolidateMemoriesResult struct {
-	NewID           string   `json:"new_id"`             // ID of the new consolidated memory
-	ConsolidatedIDs []string `json:"consolidated_ids"`   // IDs that were soft-deleted
-	Content         string   `json:"content"`            // the merged content
-	Message         string   `json:"message"`            // status message
+	NewID           string   `json:"new_id"`           // ID of the new consolidated memory
+	ConsolidatedIDs []string `json:"consolidated_ids"` // IDs that were soft-deleted
+	Content         string   `json:"content"`          // the merged content
+	Message         string   `json:"message"`          // status message
 }
 
 // RestoreMemoryArgs contains arguments for the restore_memory tool.
 type RestoreMemoryArgs struct {
-	ID           string `json:"id"`                       // Memory ID to restore (required)
-	ConnectionID string `json:"connection_id,omitempty"`  // Connection the memory lives in (inferred from ID if omitted)
+	ID           string `json:"id"`                      // Memory ID to restore (required)
+	ConnectionID string `json:"connection_id,omitempty"` // Connection the memory lives in (inferred from ID if omitted)
 }
 
 // RestoreMemoryResult contains the result of restoring a soft-deleted memory.
 type RestoreMemoryResult struct {
-	ID      string `json:"id"`      // Memory ID
-	Restored bool  `json:"restored"` // Whether the memory was restored
+	ID       string `json:"id"`       // Memory ID
+	Restored bool   `json:"restored"` // Whether the memory was restored
 }
 
 // ListDeletedMemoriesArgs contains arguments for the list_deleted_memories tool.
@@ -347,6 +746,8 @@ type ListDeletedMemoriesArgs struct {
 	ConnectionID string `json:"connection_id,omitempty"` // Connection to query (defaults to primary)
 	Limit        int    `json:"limit,omitempty"`         // Max results (default 10)
 	Page         int    `json:"page,omitempty"`          // Page number (default 1)
+	SortBy       string `json:"sort_by,omitempty"`       // One of storage.SortFields (default created_at)
+	SortOrder    string `json:"sort_order,omitempty"`    // asc or desc (default desc)
 }
 
 // ListDeletedMemoriesResult contains the result of listing soft-deleted memories.
@@ -355,28 +756,170 @@ type ListDeletedMemoriesResult struct {
 	Total    int            `json:"total"`    // Total count
 	Page     int            `json:"page"`     // Current page
 	HasMore  bool           `json:"has_more"` // Whether more pages exist
+	Limit    int            `json:"limit"`    // Limit applied after defaulting and clamping
+}
+
+// TraverseMemoryGraphArgs contains arguments for the traverse_memory_graph tool.
+type TraverseMemoryGraphArgs struct {
+	MemoryID          string   `json:"memory_id"`                    // Starting memory ID (required)
+	MaxHops           int      `json:"max_hops,omitempty"`           // Relationship hops to follow (1-4, default 2)
+	Limit             int      `json:"limit,omitempty"`              // Maximum results
+	RelationshipTypes []string `json:"relationship_types,omitempty"` // Only follow these relationship types
+	ExcludeTypes      []string `json:"exclude_types,omitempty"`      // Never follow these relationship types
+	Directed          bool     `json:"directed,omitempty"`           // Follow relationships source→target only
+
+	// Relative weights of the relevance score components. When all three
+	// are omitted storage.DefaultTraversalWeights is used.
+	ProximityWeight float64 `json:"proximity_weight,omitempty"` // Favor fewer hops
+	OverlapWeight   float64 `json:"overlap_weight,omitempty"`   // Favor more shared entities
+	DecayWeight     float64 `json:"decay_weight,omitempty"`     // Favor higher decay scores
+}
+
+// GetMemoryNeighborsArgs contains arguments for the get_memory_neighbors tool.
+type GetMemoryNeighborsArgs struct {
+	ID string `json:"id"` // Memory ID whose neighbors to list (required)
+}
+
+// MemoryNeighbor is one memory directly linked to another, as reported by
+// get_memory_neighbors.
+type MemoryNeighbor struct {
+	ID       string `json:"id"`                // Neighbor memory ID
+	LinkType string `json:"link_type"`         // Link type, e.g. CONTAINS or SUPERSEDES
+	Summary  string `json:"summary,omitempty"` // Neighbor summary, or the start of its content
+	State    string `json:"state,omitempty"`   // Neighbor lifecycle state
+	Missing  bool   `json:"missing,omitempty"` // Neighbor is deleted or no longer exists
+}
+
+// GetMemoryNeighborsResult contains the result of the get_memory_neighbors tool.
+type GetMemoryNeighborsResult struct {
+	ID           string           `json:"id"`                      // Memory ID
+	Outgoing     []MemoryNeighbor `json:"outgoing"`                // Links from this memory
+	Incoming     []MemoryNeighbor `json:"incoming"`                // Links to this memory
+	Supersedes   []string         `json:"supersedes,omitempty"`    // Memories this one replaced
+	SupersededBy []string         `json:"superseded_by,omitempty"` // Memories that replaced this one
+}
+
+// GetMemoryContextArgs contains arguments for the get_memory_context tool.
+type GetMemoryContextArgs struct {
+	ID          string `json:"id"`                     // Memory ID to build the context around (required)
+	MaxMemories int    `json:"max_memories,omitempty"` // Most memories in the bundle, the memory itself included (default 8, max 20)
+	MaxChars    int    `json:"max_chars,omitempty"`    // Length budget of the rendered text in characters (default 4000, min 200, max 50000)
+}
+
+// Roles of the memories in a get_memory_context bundle.
+const (
+	ContextRoleMemory        = "memory"         // The requested memory
+	ContextRoleLatestVersion = "latest_version" // The tip of its evolution chain
+	ContextRoleLinked        = "linked"         // A memory directly linked to it
+	ContextRoleRelated       = "related"        // A memory sharing entities with it
+)
+
+// MemoryContextEntry is one memory in a get_memory_context bundle.
+type MemoryContextEntry struct {
+	ID             string   `json:"id"`
+	Role           string   `json:"role"`                      // One of the ContextRole constants
+	LinkType       string   `json:"link_type,omitempty"`       // Link type, for linked memories
+	SharedEntities []string `json:"shared_entities,omitempty"` // Entities in common, for related memories
+	State          string   `json:"state,omitempty"`
+	CreatedAt      string   `json:"created_at"`          // RFC-3339 creation time
+	Content        string   `json:"content"`             // Content, trimmed to fit the budget
+	Truncated      bool     `json:"truncated,omitempty"` // Content was trimmed
+}
+
+// GetMemoryContextResult contains the result of the get_memory_context tool.
+type GetMemoryContextResult struct {
+	ID        string               `json:"id"`                   // Requested memory ID
+	CurrentID string               `json:"current_id,omitempty"` // Latest version of the memory, when it has been evolved
+	Memories  []MemoryContextEntry `json:"memories"`             // The bundle, most relevant first
+	Omitted   int                  `json:"omitted"`              // Candidate memories left out to respect the budget
+	Text      string               `json:"text"`                 // The bundle rendered for a prompt; at most max_chars characters
+}
+
+// GetAuditLogArgs contains arguments for the get_audit_log tool.
+type GetAuditLogArgs struct {
+	MemoryID string `json:"memory_id,omitempty"` // Only entries involving this memory
+	Agent    string `json:"agent,omitempty"`     // Only entries by this actor (created_by)
+	Tool     string `json:"tool,omitempty"`      // Only entries for this tool
+	Since    string `json:"since,omitempty"`     // RFC-3339; only entries at or after this time
+	Until    string `json:"until,omitempty"`     // RFC-3339; only entries before this time
+	Limit    int    `json:"limit,omitempty"`     // Maximum entries to return
+}
+
+// GetAuditLogResult contains the result of the get_audit_log tool.
+type GetAuditLogResult struct {
+	Entries []storage.AuditEntry `json:"entries"` // Matching entries, newest first
+	Total   int                  `json:"total"`   // Number of entries returned
+	Limit   int                  `json:"limit"`   // Limit applied after defaulting and clamping
+	Enabled bool                 `json:"enabled"` // Whether new operations are being recorded
 }
 
 // GetEvolutionChainArgs contains arguments for the get_evolution_chain tool.
 type GetEvolutionChainArgs struct {
-	ID           string `json:"id"`                       // Memory ID to trace (required)
-	ConnectionID string `json:"connection_id,omitempty"`  // Connection the memory lives in (inferred from ID if omitted)
+	ID             string `json:"id"`                        // Memory ID to trace (required)
+	ConnectionID   string `json:"connection_id,omitempty"`   // Connection the memory lives in (inferred from ID if omitted)
+	IncludeDeleted bool   `json:"include_deleted,omitempty"` // Also list soft-deleted versions, marked deleted (default false)
 }
 
 // EvolutionEntry represents a single version in an evolution chain.
 type EvolutionEntry struct {
-	Position  int    `json:"position"`            // 1-indexed position in chain (1 = oldest)
-	ID        string `json:"id"`                  // Memory ID
-	Content   string `json:"content"`             // First 200 chars of content
-	State     string `json:"state,omitempty"`     // Lifecycle state
-	CreatedAt string `json:"created_at"`          // RFC-3339 creation time
+	Position  int    `json:"position"`             // 1-indexed position in chain (1 = oldest)
+	ID        string `json:"id"`                   // Memory ID
+	Content   string `json:"content"`              // First 200 chars of content
+	State     string `json:"state,omitempty"`      // Lifecycle state
+	CreatedAt string `json:"created_at"`           // RFC-3339 creation time
+	Deleted   bool   `json:"deleted,omitempty"`    // Version is soft-deleted (only listed with include_deleted)
+	DeletedAt string `json:"deleted_at,omitempty"` // RFC-3339 soft-delete time
 }
 
 // GetEvolutionChainResult contains the result of getting an evolution chain.
 type GetEvolutionChainResult struct {
 	Chain         []EvolutionEntry `json:"chain"`          // Ordered list of versions (oldest first)
-	TotalVersions int              `json:"total_versions"` // Total number of versions
-	CurrentID     string           `json:"current_id"`     // ID of the most recent (current) version
+	TotalVersions int              `json:"total_versions"` // Number of versions listed
+	CurrentID     string           `json:"current_id"`     // ID of the most recent (current) version that is not deleted
+	// DeletedVersions counts the soft-deleted versions in the chain,
+	// whether or not they are listed.
+	DeletedVersions int `json:"deleted_versions,omitempty"`
+}
+
+// GetMemorySnapshotArgs contains arguments for the get_memory_snapshot tool.
+type GetMemorySnapshotArgs struct {
+	ID string `json:"id"` // Memory ID to capture (required)
+}
+
+// MemorySnapshot is a point-in-time copy of a memory and the graph around it:
+// its entities, the relationships among them, and its incoming and outgoing
+// memory links. It is returned by get_memory_snapshot and accepted as-is by
+// restore_memory_snapshot.
+type MemorySnapshot struct {
+	Version    int          `json:"version"`     // Snapshot format version (MemorySnapshotVersion)
+	CapturedAt string       `json:"captured_at"` // RFC-3339 time the snapshot was taken
+	Memory     types.Memory `json:"memory"`      // The memory as stored
+	storage.MemoryGraph
+}
+
+// RestoreMemorySnapshotArgs contains arguments for the restore_memory_snapshot tool.
+type RestoreMemorySnapshotArgs struct {
+	Snapshot MemorySnapshot `json:"snapshot"` // Snapshot returned by get_memory_snapshot (required)
+}
+
+// RestoreMemorySnapshotResult contains the result of restoring a snapshot.
+type RestoreMemorySnapshotResult struct {
+	ID             string `json:"id"`              // Restored memory ID
+	Restored       bool   `json:"restored"`        // Whether the memory was restored
+	Entities       int    `json:"entities"`        // Entity associations restored
+	Relationships  int    `json:"relationships"`   // Relationships restored
+	LinksRecreated int    `json:"links_recreated"` // Memory links that were missing and recreated
+}
+
+// SummarizeMemoryArgs contains arguments for the summarize_memory tool.
+type SummarizeMemoryArgs struct {
+	ID string `json:"id"` // Memory ID to summarize (required)
+}
+
+// SummarizeMemoryResult contains the result of summarizing a memory.
+type SummarizeMemoryResult struct {
+	ID      string `json:"id"`      // Memory ID
+	Summary string `json:"summary"` // Stored summary
 }
 
 // CreateProjectArgs contains arguments for the create_project tool.
@@ -399,6 +942,7 @@ type AddProjectItemArgs struct {
 	ItemType     string `json:"item_type"`               // epic, phase, task, step, or milestone (required)
 	Name         string `json:"name"`                    // Item name (required)
 	Description  string `json:"description,omitempty"`   // Item description
+	Position     int    `json:"position,omitempty"`      // 1-based position among the parent's children (default: last)
 	ConnectionID string `json:"connection_id,omitempty"` // Connection (inferred from parent_id if omitted)
 }
 
@@ -409,12 +953,38 @@ type AddProjectItemResult struct {
 	ItemType string `json:"item_type"` // The item type that was created
 }
 
+// ReorderProjectItemsArgs contains arguments for the reorder_project_items tool.
+type ReorderProjectItemsArgs struct {
+	ParentID string   `json:"parent_id"` // ID of the parent memory (required)
+	ItemIDs  []string `json:"item_ids"`  // Children in their new order; unlisted children follow (required)
+}
+
+// ReorderProjectItemsResult contains the result of reordering project items.
+type ReorderProjectItemsResult struct {
+	ParentID string   `json:"parent_id"` // ID of the parent memory
+	ItemIDs  []string `json:"item_ids"`  // All children in their new order
+}
+
+// MoveProjectItemArgs contains arguments for the move_project_item tool.
+type MoveProjectItemArgs struct {
+	ItemID      string `json:"item_id"`       // ID of the item to move (required)
+	NewParentID string `json:"new_parent_id"` // ID of the new parent memory (required)
+}
+
+// MoveProjectItemResult contains the result of moving a project item.
+type MoveProjectItemResult struct {
+	ID          string           `json:"id"`               // ID of the moved item
+	OldParentID string           `json:"old_parent_id"`    // ID of the previous parent
+	NewParentID string           `json:"new_parent_id"`    // ID of the new parent
+	Parent      *ProjectTreeNode `json:"parent,omitempty"` // New parent with its direct children, after the move
+}
+
 // ProjectTreeNode represents a node in a project tree.
 type ProjectTreeNode struct {
-	ID       string            `json:"id"`                // Memory ID
-	Name     string            `json:"name"`              // Item name (first line of content)
-	Type     string            `json:"type"`              // Memory type (project/epic/phase/task/step/milestone)
-	State    string            `json:"state,omitempty"`   // Lifecycle state
+	ID       string            `json:"id"`                 // Memory ID
+	Name     string            `json:"name"`               // Item name (first line of content)
+	Type     string            `json:"type"`               // Memory type (project/epic/phase/task/step/milestone)
+	State    string            `json:"state,omitempty"`    // Lifecycle state
 	Children []ProjectTreeNode `json:"children,omitempty"` // Nested children
 }
 
@@ -436,6 +1006,8 @@ type ListProjectsArgs struct {
 	State        string `json:"state,omitempty"`         // Filter by lifecycle state
 	Limit        int    `json:"limit,omitempty"`         // Max results (default 10)
 	Page         int    `json:"page,omitempty"`          // Page number (default 1)
+	SortBy       string `json:"sort_by,omitempty"`       // One of storage.SortFields (default created_at)
+	SortOrder    string `json:"sort_order,omitempty"`    // asc or desc (default desc)
 }
 
 // ListProjectsResult contains the result of listing projects.
@@ -444,6 +1016,290 @@ type ListProjectsResult struct {
 	Total    int            `json:"total"`    // Total count
 	Page     int            `json:"page"`     // Current page
 	HasMore  bool           `json:"has_more"` // Whether more pages exist
+	Limit    int            `json:"limit"`    // Limit applied after defaulting and clamping
+}
+
+// ListEntitiesArgs contains arguments for the list_entities tool.
+type ListEntitiesArgs struct {
+	ConnectionID string `json:"connection_id,omitempty"` // Connection to query (defaults to primary)
+	Type         string `json:"type,omitempty"`          // Filter by entity type
+	Limit        int    `json:"limit,omitempty"`         // Max results (default 20)
+	Page         int    `json:"page,omitempty"`          // Page number (default 1)
+	Duplicates   bool   `json:"duplicates,omitempty"`    // List groups of likely duplicate entities instead
+}
+
+// EntitySummary describes one entity in a list_entities result.
+type EntitySummary struct {
+	ID          string `json:"id"`           // Entity ID
+	Name        string `json:"name"`         // Entity name
+	Type        string `json:"type"`         // Entity type
+	MemoryCount int    `json:"memory_count"` // Number of live memories linked to the entity
+	FirstSeen   string `json:"first_seen"`   // RFC3339 creation time of the oldest linked memory
+	LastSeen    string `json:"last_seen"`    // RFC3339 creation time of the newest linked memory
+}
+
+// ListEntitiesResult contains the result of listing entities.
+type ListEntitiesResult struct {
+	Entities        []EntitySummary        `json:"entities"`                   // Entities on this page
+	DuplicateGroups []DuplicateEntityGroup `json:"duplicate_groups,omitempty"` // Groups on this page, when duplicates is set
+	Total           int                    `json:"total"`                      // Total count of entities, or of groups when duplicates is set
+	Page            int                    `json:"page"`                       // Current page
+	HasMore         bool                   `json:"has_more"`                   // Whether more pages exist
+}
+
+// DuplicateEntityGroup is a set of entities whose names suggest they are
+// the same person, project or thing, for review before merge_entities.
+type DuplicateEntityGroup struct {
+	CanonicalID string          `json:"canonical_id"` // Suggested entity to merge into: the most mentioned
+	Entities    []EntitySummary `json:"entities"`     // The entities, most mentioned first
+}
+
+// MergeEntitiesArgs contains arguments for the merge_entities tool.
+type MergeEntitiesArgs struct {
+	ConnectionID string   `json:"connection_id,omitempty"` // Connection holding the entities (defaults to primary)
+	CanonicalID  string   `json:"canonical_id"`            // Entity to keep (required)
+	DuplicateIDs []string `json:"duplicate_ids"`           // Entities to merge into it and delete (required)
+}
+
+// MergeEntitiesResult contains the result of merging entities.
+type MergeEntitiesResult struct {
+	CanonicalID            string   `json:"canonical_id"`            // Entity the duplicates were merged into
+	MergedIDs              []string `json:"merged_ids"`              // Duplicate entities that were deleted
+	MemoriesRepointed      int      `json:"memories_repointed"`      // Memory links moved to the canonical entity
+	RelationshipsRepointed int      `json:"relationships_repointed"` // Relationships moved to the canonical entity
+	RelationshipsDropped   int      `json:"relationships_dropped"`   // Relationships the canonical entity already had, or that would link it to itself
+}
+
+// PurgeConnectionArgs contains arguments for the purge_connection tool.
+type PurgeConnectionArgs struct {
+	ConnectionID     string `json:"connection_id"`               // Connection to purge (required)
+	Confirm          string `json:"confirm"`                     // Must equal connection_id (required)
+	RemoveConnection bool   `json:"remove_connection,omitempty"` // Also remove the connection from connections.json
+	DeleteFiles      bool   `json:"delete_files,omitempty"`      // Also delete its SQLite database files (requires remove_connection)
+}
+
+// PurgeConnectionResult contains the result of purging a connection.
+type PurgeConnectionResult struct {
+	ConnectionID         string   `json:"connection_id"`            // Connection that was purged
+	MemoriesDeleted      int      `json:"memories_deleted"`         // Memories deleted, soft-deleted ones included
+	EntitiesDeleted      int      `json:"entities_deleted"`         // Entities deleted
+	RelationshipsDeleted int      `json:"relationships_deleted"`    // Entity relationships deleted
+	LinksDeleted         int      `json:"links_deleted"`            // Memory links deleted
+	RoutesDeleted        int      `json:"routes_deleted,omitempty"` // Opaque ID routes deleted
+	ConnectionRemoved    bool     `json:"connection_removed"`       // Whether the connection was removed from connections.json
+	FilesDeleted         []string `json:"files_deleted"`            // Database files deleted
+}
+
+// GetEmbeddingArgs contains arguments for the get_embedding tool. Exactly
+// one of ID and Text is required.
+type GetEmbeddingArgs struct {
+	ID           string `json:"id,omitempty"`            // Memory whose stored embedding to return
+	Text         string `json:"text,omitempty"`          // Text to embed with the current model
+	ConnectionID string `json:"connection_id,omitempty"` // Connection holding the memory (inferred from id if omitted)
+	Precision    int    `json:"precision,omitempty"`     // Decimal places per value (default DefaultEmbeddingPrecision)
+}
+
+// GetEmbeddingResult contains an embedding vector returned by get_embedding.
+type GetEmbeddingResult struct {
+	ID        string    `json:"id,omitempty"` // Memory the embedding belongs to, if one was requested
+	Source    string    `json:"source"`       // "stored" for a memory's embedding, "computed" for text
+	Model     string    `json:"model"`        // Embedding model, empty if unknown
+	Dimension int       `json:"dimension"`    // Number of values in the vector
+	Norm      float64   `json:"norm"`         // Euclidean length of the unrounded vector
+	Precision int       `json:"precision"`    // Decimal places Vector is rounded to
+	Vector    []float64 `json:"vector"`       // The embedding, rounded to Precision
+}
+
+// MemorySimilarityArgs contains arguments for the memory_similarity tool.
+// MemoryID and exactly one of OtherID and Text are required.
+type MemorySimilarityArgs struct {
+	MemoryID     string `json:"memory_id"`               // First memory
+	OtherID      string `json:"other_id,omitempty"`      // Second memory
+	Text         string `json:"text,omitempty"`          // Text to compare the memory with
+	ConnectionID string `json:"connection_id,omitempty"` // Connection holding the memories (inferred from the IDs if omitted)
+}
+
+// MemorySimilarityResult contains the similarity of two embeddings.
+type MemorySimilarityResult struct {
+	MemoryID        string  `json:"memory_id"`          // First memory
+	OtherID         string  `json:"other_id,omitempty"` // Second memory, if compared with one
+	Similarity      float64 `json:"similarity"`         // Cosine similarity, from -1 to 1
+	Dimension       int     `json:"dimension"`          // Dimensions of the embeddings
+	MemoryEmbedding string  `json:"memory_embedding"`   // "stored", or "computed" if the memory had none
+	OtherEmbedding  string  `json:"other_embedding"`    // Same for the second memory; always "computed" for text
+	Warning         string  `json:"warning,omitempty"`  // Set when the embeddings come from different models
+}
+
+// ClusterMemoriesArgs contains arguments for the cluster_memories tool.
+type ClusterMemoriesArgs struct {
+	ConnectionID  string `json:"connection_id,omitempty"`  // Connection to cluster (defaults to primary)
+	Clusters      int    `json:"clusters,omitempty"`       // Number of clusters (default: about sqrt(memories/2), max 20)
+	MaxMemories   int    `json:"max_memories,omitempty"`   // Newest memories to cluster (default 200, max 1000)
+	MemoryType    string `json:"memory_type,omitempty"`    // Only memories of this type
+	CreatedAfter  string `json:"created_after,omitempty"`  // RFC-3339 lower bound for memory created_at
+	CreatedBefore string `json:"created_before,omitempty"` // RFC-3339 upper bound for memory created_at
+}
+
+// ClusterMemoriesResult contains the topic clusters of a set of memories.
+type ClusterMemoriesResult struct {
+	Method      string          `json:"method"`      // ClusterMethodEmbeddings, or ClusterMethodTags when too few memories have embeddings
+	Clusters    []MemoryCluster `json:"clusters"`    // Clusters, largest first
+	Memories    int             `json:"memories"`    // Memories considered
+	Total       int             `json:"total"`       // Memories matching the filters; more than Memories if max_memories cut them off
+	Unclustered []string        `json:"unclustered"` // Memories left out for lack of an embedding (embeddings method only)
+}
+
+// MemoryCluster is one topic found by cluster_memories.
+type MemoryCluster struct {
+	Label           string          `json:"label"`           // Short topic name
+	LabelSource     string          `json:"label_source"`    // "llm", "tags" (its most common tags) or "content" (its best representative)
+	Size            int             `json:"size"`            // Memories in the cluster
+	Tags            []string        `json:"tags"`            // Most common tags of its memories, up to 5
+	Representatives []ClusterMember `json:"representatives"` // Memories closest to its centre (the newest when grouped by tag)
+	MemoryIDs       []string        `json:"memory_ids"`      // All of its memories
+}
+
+// ClusterMember is a representative memory of a cluster.
+type ClusterMember struct {
+	ID         string   `json:"id"`                   // Memory ID
+	Label      string   `json:"label"`                // First line of the content, shortened
+	Similarity float64  `json:"similarity,omitempty"` // Cosine similarity to the cluster centre (embeddings method only)
+	Tags       []string `json:"tags,omitempty"`       // Memory tags
+}
+
+// GenerateDigestArgs contains arguments for the generate_digest tool.
+type GenerateDigestArgs struct {
+	ConnectionID  string `json:"connection_id,omitempty"`  // Connection to digest (defaults to primary)
+	Period        string `json:"period,omitempty"`         // DigestPeriodDay (default) or DigestPeriodWeek, ending at CreatedBefore
+	CreatedAfter  string `json:"created_after,omitempty"`  // RFC-3339 start of the window, overriding Period
+	CreatedBefore string `json:"created_before,omitempty"` // RFC-3339 end of the window (default: now)
+	DryRun        bool   `json:"dry_run,omitempty"`        // Return the digest without storing it
+}
+
+// Digest windows for GenerateDigestArgs.Period.
+const (
+	DigestPeriodDay  = "day"
+	DigestPeriodWeek = "week"
+)
+
+// GenerateDigestResult contains a digest and, unless it was a dry run or
+// nothing was captured, the ID of the memory it was stored as.
+type GenerateDigestResult struct {
+	ID         string               `json:"id,omitempty"`        // Digest memory ID, when stored
+	Stored     bool                 `json:"stored"`              // Whether the digest was stored
+	Content    string               `json:"content"`             // The digest, in Markdown
+	From       time.Time            `json:"from"`                // Start of the window
+	To         time.Time            `json:"to"`                  // End of the window
+	Memories   int                  `json:"memories"`            // Memories covered
+	Truncated  bool                 `json:"truncated,omitempty"` // More than engine.MaxDigestMemories were created; the newest are covered
+	Groups     []engine.DigestGroup `json:"groups"`              // Domains or topics, largest first
+	Summarized bool                 `json:"summarized"`          // Whether the LLM wrote the digest; otherwise it lists the memories
+}
+
+// GetRelationshipsArgs contains arguments for the get_relationships tool.
+type GetRelationshipsArgs struct {
+	MemoryID     string `json:"memory_id,omitempty"`     // Memory whose entities' relationships to list
+	EntityID     string `json:"entity_id,omitempty"`     // Entity whose relationships to list
+	Type         string `json:"type,omitempty"`          // Only relationships of this type
+	ConnectionID string `json:"connection_id,omitempty"` // Connection to query (inferred from memory_id if omitted)
+	Limit        int    `json:"limit,omitempty"`         // Max results
+}
+
+// RelationshipEndpoint is the entity at one end of a relationship.
+type RelationshipEndpoint struct {
+	ID   string `json:"id"`   // Entity ID
+	Name string `json:"name"` // Entity name
+	Type string `json:"type"` // Entity type
+}
+
+// RelationshipEdge is one typed relationship between two entities.
+type RelationshipEdge struct {
+	ID         string               `json:"id"`                  // Relationship ID
+	Type       string               `json:"type"`                // Relationship type, e.g. works_on
+	Source     RelationshipEndpoint `json:"source"`              // Source entity
+	Target     RelationshipEndpoint `json:"target"`              // Target entity
+	Direction  string               `json:"direction,omitempty"` // With entity_id: outgoing when the entity is the source, incoming when it is the target
+	Confidence float64              `json:"confidence"`          // Extraction confidence (relationship strength)
+	Context    string               `json:"context,omitempty"`   // Description of the relationship, if recorded
+	CreatedAt  string               `json:"created_at"`          // RFC3339 time the relationship was first recorded
+	UpdatedAt  string               `json:"updated_at"`          // RFC3339 time the relationship was last recorded
+}
+
+// GetRelationshipsResult contains the relationships of a memory or entity.
+type GetRelationshipsResult struct {
+	Relationships []RelationshipEdge `json:"relationships"` // Highest confidence first
+	Total         int                `json:"total"`         // Number of relationships returned
+	Limit         int                `json:"limit"`         // Limit applied after defaulting and clamping
+}
+
+// GetGraphExportArgs contains arguments for the get_graph_export tool.
+type GetGraphExportArgs struct {
+	ConnectionID  string `json:"connection_id,omitempty"`  // Connection to export (defaults to primary)
+	Format        string `json:"format,omitempty"`         // "json" (node-link, the default) or "graphml"
+	Domain        string `json:"domain,omitempty"`         // Only memories of this domain
+	CreatedAfter  string `json:"created_after,omitempty"`  // RFC-3339 lower bound for memory created_at
+	CreatedBefore string `json:"created_before,omitempty"` // RFC-3339 upper bound for memory created_at
+	MaxNodes      int    `json:"max_nodes,omitempty"`      // Most memory and entity nodes (default 500, max 5000)
+}
+
+// GraphNode is a memory or entity node of a graph export.
+type GraphNode struct {
+	ID          string `json:"id"`                     // Memory or entity ID
+	Kind        string `json:"kind"`                   // "memory" or "entity"
+	Label       string `json:"label"`                  // Start of the memory's content, or the entity's name
+	Type        string `json:"type,omitempty"`         // Memory type or entity type
+	Domain      string `json:"domain,omitempty"`       // Memory domain
+	State       string `json:"state,omitempty"`        // Memory lifecycle state
+	CreatedAt   string `json:"created_at,omitempty"`   // RFC3339 memory creation time
+	MemoryCount int    `json:"memory_count,omitempty"` // Exported memories that mention the entity
+}
+
+// GraphEdge is an edge of a graph export.
+type GraphEdge struct {
+	Source string  `json:"source"`           // Source node ID
+	Target string  `json:"target"`           // Target node ID
+	Kind   string  `json:"kind"`             // "mentions" (memory to entity), "relationship" (entity to entity) or "link" (memory to memory)
+	Type   string  `json:"type,omitempty"`   // Relationship or link type
+	Weight float64 `json:"weight,omitempty"` // Mention confidence or relationship strength
+}
+
+// GetGraphExportResult contains an exported memory graph.
+type GetGraphExportResult struct {
+	Format    string      `json:"format"`            // "json" or "graphml"
+	Nodes     []GraphNode `json:"nodes,omitempty"`   // Nodes, in json format
+	Links     []GraphEdge `json:"links,omitempty"`   // Edges, in json format
+	GraphML   string      `json:"graphml,omitempty"` // The GraphML document, in graphml format
+	NodeCount int         `json:"node_count"`        // Number of nodes exported
+	EdgeCount int         `json:"edge_count"`        // Number of edges exported
+	Truncated bool        `json:"truncated"`         // Whether nodes were left out to stay within max_nodes
+}
+
+// RecallAboutEntityArgs contains arguments for the recall_about_entity tool.
+type RecallAboutEntityArgs struct {
+	Entity       string `json:"entity"`                  // Entity name, matched ignoring case (required)
+	Type         string `json:"type,omitempty"`          // Only entities of this type, e.g. "person"
+	ConnectionID string `json:"connection_id,omitempty"` // Connection to query (defaults to primary)
+	Since        string `json:"since,omitempty"`         // RFC-3339 lower bound on when the memories were created
+	Until        string `json:"until,omitempty"`         // RFC-3339 upper bound on when the memories were created
+	Limit        int    `json:"limit,omitempty"`         // Max memories returned
+}
+
+// EntityDomainGroup holds the memories of one domain in a
+// recall_about_entity result.
+type EntityDomainGroup struct {
+	Domain   string         `json:"domain"`
+	Count    int            `json:"count"`
+	Memories []types.Memory `json:"memories"` // Newest first
+}
+
+// RecallAboutEntityResult contains the result of recall_about_entity.
+type RecallAboutEntityResult struct {
+	Entity   string              `json:"entity"`   // Requested entity name
+	Matches  []EntitySummary     `json:"matches"`  // Entities with that name; empty when none was found
+	Groups   []EntityDomainGroup `json:"groups"`   // Linked memories by domain, largest group first
+	Returned int                 `json:"returned"` // Memories in Groups
+	Total    int                 `json:"total"`    // Linked memories in the time window, before the limit
+	Summary  string              `json:"summary"`  // e.g. "4 memories about Acme: sales (3), legal (1)"
 }
 
 // JSONRPCRequest represents a JSON-RPC 2.0 request.
@@ -454,6 +1310,21 @@ type JSONRPCRequest struct {
 	ID      interface{} `json:"id"`      // Request ID (string, number, or null)
 }
 
+// JSONRPCNotification represents a JSON-RPC 2.0 notification: a message
+// with no ID that expects no response.
+type JSONRPCNotification struct {
+	JSONRPC string      `json:"jsonrpc"`          // Must be "2.0"
+	Method  string      `json:"method"`           // Notification method
+	Params  interface{} `json:"params,omitempty"` // Notification parameters
+}
+
+// EnrichmentNotification is the params payload of an enrichment
+// notification.
+type EnrichmentNotification struct {
+	MemoryID string             `json:"memory_id"` // Memory whose enrichment finished
+	Status   types.MemoryStatus `json:"status"`    // Final status: "enriched" or "failed"
+}
+
 // JSONRPCResponse represents a JSON-RPC 2.0 response.
 type JSONRPCResponse struct {
 	JSONRPC string        `json:"jsonrpc"`          // Must be "2.0"
@@ -477,6 +1348,13 @@ const (
 	ErrCodeInvalidParams  = -32602 // Invalid method parameters
 	ErrCodeInternalError  = -32603 // Internal JSON-RPC error
 	ErrCodeServerError    = -32000 // Server error
+
+	// Implementation-defined codes (JSON-RPC reserves -32000..-32099 for servers).
+	ErrCodeNotFound = -32001 // Requested memory/project does not exist
+	ErrCodeReadOnly = -32002 // Mutating tool called on a read-only server
+	ErrCodeTimeout  = -32003 // Request or LLM call exceeded its timeout
+
+	ErrCodeRateLimited = -32004 // Tool called again before its cooldown elapsed
 )
 
 // ---------------------------------------------------------------------------
@@ -505,7 +1383,8 @@ type MCPServerInfo struct {
 
 // MCPServerCapabilities describes what this server supports.
 type MCPServerCapabilities struct {
-	Tools *MCPToolsCapability `json:"tools,omitempty"`
+	Tools        *MCPToolsCapability    `json:"tools,omitempty"`
+	Experimental map[string]interface{} `json:"experimental,omitempty"`
 }
 
 // MCPToolsCapability signals that the server exposes tools.
@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// DefaultEnrichmentWaitTimeout is how long store_memory blocks with
+// wait_for_enrichment when the call does not set timeout_seconds.
+const DefaultEnrichmentWaitTimeout = 30 * time.Second
+
+// MaxEnrichmentWaitSeconds is the largest timeout_seconds store_memory
+// accepts.
+const MaxEnrichmentWaitSeconds = 600
+
+// awaitEnrichment blocks until done reports the outcome of the memory's
+// enrichment, timeout passes or ctx ends, then fills result with the memory
+// as stored at that point. A nil done means no enrichment job was queued, so
+// the pending memory is returned straight away.
+func (s *Server) awaitEnrichment(ctx context.Context, store storage.MemoryStore, result *StoreMemoryResult, done <-chan types.MemoryStatus, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultEnrichmentWaitTimeout
+	}
+
+	var outcome types.MemoryStatus
+	if done == nil {
+		result.Message += " Enrichment could not be queued now, so the memory is returned without waiting."
+	} else {
+		waitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		select {
+		case outcome = <-done:
+		case <-waitCtx.Done():
+			result.Message += fmt.Sprintf(" Enrichment did not finish within %s; it continues in the background.", timeout)
+		}
+	}
+
+	memory, err := store.Get(ctx, result.ID)
+	if err == nil {
+		result.Memory = memory
+		result.Status = memory.Status
+	}
+	// The signalled outcome wins over the stored status, which a second job
+	// for the same memory (e.g. from startup recovery) may already be
+	// rewriting.
+	if outcome != "" {
+		result.Status = outcome
+	}
+	switch result.Status {
+	case types.StatusEnriched:
+		result.Message = "Memory stored and enriched."
+	case types.StatusFailed:
+		result.Message = "Memory stored, but enrichment failed."
+	}
+}
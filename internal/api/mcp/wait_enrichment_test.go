@@ -0,0 +1,85 @@
+package mcp_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scrypster/memento/internal/api/mcp"
+	"github.com/scrypster/memento/internal/engine"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func TestStoreMemory_WaitForEnrichmentReturnsEnrichedMemory(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	cfg := engine.DefaultConfig()
+	cfg.NumWorkers = 1
+	eng, err := engine.NewMemoryEngine(store, cfg, nil)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, eng.Start(ctx))
+	t.Cleanup(func() { _ = eng.Shutdown(ctx) })
+
+	srv := mcp.NewServer(store, mcp.WithEngine(eng))
+	var result mcp.StoreMemoryResult
+	callRPC(t, srv, "store_memory", map[string]interface{}{
+		"content":             "Ledger service moved to Postgres",
+		"wait_for_enrichment": true,
+		"timeout_seconds":     10,
+	}, &result)
+
+	assert.Equal(t, types.StatusEnriched, result.Status)
+	require.NotNil(t, result.Memory)
+	assert.Equal(t, result.ID, result.Memory.ID)
+	assert.Contains(t, result.Message, "enriched")
+}
+
+func TestStoreMemory_WaitForEnrichmentTimesOut(t *testing.T) {
+	eng := &recordingEngine{queued: map[string]string{}}
+	srv := mcp.NewServer(newMockStore(), mcp.WithEngine(eng))
+
+	result, err := srv.StoreMemory(context.Background(), mcp.StoreMemoryArgs{
+		Content:           "never enriched",
+		WaitForEnrichment: true,
+		TimeoutSeconds:    1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, types.StatusPending, result.Status)
+	assert.Contains(t, result.Message, "did not finish within 1s")
+	assert.Contains(t, eng.queued, result.ID)
+}
+
+func TestStoreMemory_WaitForEnrichmentRejectsBadTimeout(t *testing.T) {
+	srv := mcp.NewServer(newMockStore())
+	for _, timeout := range []string{"-1", "601"} {
+		req := `{"jsonrpc":"2.0","method":"store_memory","params":{"content":"x","wait_for_enrichment":true,"timeout_seconds":` + timeout + `},"id":1}`
+		assert.Equal(t, mcp.ErrCodeInvalidParams, rpcErrorCode(t, srv, req), "timeout_seconds=%s", timeout)
+	}
+}
+
+// fullQueueEngine rejects every job, as an engine with a full queue does.
+type fullQueueEngine struct{ recordingEngine }
+
+func (e *fullQueueEngine) QueueEnrichmentForMemory(string, string) bool { return false }
+
+func TestStoreMemory_ReportsQueuedOrDeferredEnrichment(t *testing.T) {
+	queued := mcp.NewServer(newMockStore(), mcp.WithEngine(&recordingEngine{queued: map[string]string{}}))
+	result, err := queued.StoreMemory(context.Background(), mcp.StoreMemoryArgs{Content: "queued memory"})
+	require.NoError(t, err)
+	assert.Equal(t, mcp.EnrichmentQueued, result.Enrichment)
+
+	full := mcp.NewServer(newMockStore(), mcp.WithEngine(&fullQueueEngine{}))
+	result, err = full.StoreMemory(context.Background(), mcp.StoreMemoryArgs{Content: "deferred memory"})
+	require.NoError(t, err)
+	assert.Equal(t, mcp.EnrichmentDeferred, result.Enrichment)
+	assert.Equal(t, types.StatusPending, result.Status)
+	assert.Contains(t, result.Message, "deferred")
+}
@@ -7,8 +7,30 @@ import (
 	"sync"
 )
 
+// AuthorType classifies who authored a memory.
+type AuthorType string
+
+const (
+	// AuthorHuman marks memories written by a person (MEMENTO_USER, git user, web UI).
+	AuthorHuman AuthorType = "human"
+	// AuthorAgent marks memories stored by an AI agent (MEMENTO_AGENT_NAME).
+	AuthorAgent AuthorType = "agent"
+	// AuthorSystem marks memories created by memento itself or an unidentified source.
+	AuthorSystem AuthorType = "system"
+)
+
+// IsValidAuthorType reports whether s is one of the known author types.
+func IsValidAuthorType(s string) bool {
+	switch AuthorType(s) {
+	case AuthorHuman, AuthorAgent, AuthorSystem:
+		return true
+	}
+	return false
+}
+
 var (
 	cachedName string
+	cachedType AuthorType
 	once       sync.Once
 )
 
@@ -16,24 +38,38 @@ var (
 // Checks in order: MEMENTO_AGENT_NAME env, MEMENTO_USER env, git config user.name, "unknown".
 // The git config result is cached after first call.
 func DetectAgent() string {
+	name, _ := DetectAuthor()
+	return name
+}
+
+// DetectAuthor returns the same name as DetectAgent together with the author
+// type implied by where it was found: MEMENTO_AGENT_NAME is an agent,
+// MEMENTO_USER and git user.name are humans, and "unknown" is system.
+func DetectAuthor() (string, AuthorType) {
 	once.Do(func() {
-		cachedName = detectAgentUncached()
+		cachedName, cachedType = detectAuthorUncached()
 	})
-	return cachedName
+	return cachedName, cachedType
 }
 
 // detectAgentUncached performs detection without caching. Used for testing.
 func detectAgentUncached() string {
+	name, _ := detectAuthorUncached()
+	return name
+}
+
+// detectAuthorUncached performs detection without caching. Used for testing.
+func detectAuthorUncached() (string, AuthorType) {
 	if name := os.Getenv("MEMENTO_AGENT_NAME"); name != "" {
-		return name
+		return name, AuthorAgent
 	}
 	if name := os.Getenv("MEMENTO_USER"); name != "" {
-		return name
+		return name, AuthorHuman
 	}
 	if name := gitUserName(); name != "" {
-		return name
+		return name, AuthorHuman
 	}
-	return "unknown"
+	return "unknown", AuthorSystem
 }
 
 // gitUserName runs `git config --get user.name` and returns the trimmed result.
@@ -31,3 +31,33 @@ func TestDetectAgentFallback(t *testing.T) {
 		t.Error("expected non-empty result")
 	}
 }
+
+func TestDetectAuthorTypeAgent(t *testing.T) {
+	t.Setenv("MEMENTO_AGENT_NAME", "my-agent")
+	name, kind := detectAuthorUncached()
+	if name != "my-agent" || kind != AuthorAgent {
+		t.Errorf("expected (my-agent, agent), got (%s, %s)", name, kind)
+	}
+}
+
+func TestDetectAuthorTypeHuman(t *testing.T) {
+	t.Setenv("MEMENTO_AGENT_NAME", "")
+	t.Setenv("MEMENTO_USER", "mjbonanno")
+	name, kind := detectAuthorUncached()
+	if name != "mjbonanno" || kind != AuthorHuman {
+		t.Errorf("expected (mjbonanno, human), got (%s, %s)", name, kind)
+	}
+}
+
+func TestIsValidAuthorType(t *testing.T) {
+	for _, s := range []string{"human", "agent", "system"} {
+		if !IsValidAuthorType(s) {
+			t.Errorf("IsValidAuthorType(%q) = false, want true", s)
+		}
+	}
+	for _, s := range []string{"", "robot", "Human"} {
+		if IsValidAuthorType(s) {
+			t.Errorf("IsValidAuthorType(%q) = true, want false", s)
+		}
+	}
+}
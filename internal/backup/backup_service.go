@@ -200,47 +200,61 @@ func (s *BackupService) ListBackups() ([]BackupInfo, error) {
 }
 
 // RestoreBackup restores the database from a backup file.
-// The service must be stopped before calling this function.
-func (s *BackupService) RestoreBackup(ctx context.Context, backupPath string) error {
+//
+// The backup is verified and inspected first. With opts.DryRun nothing else
+// happens: the returned report describes what would be restored and confirms
+// the target is writable. Otherwise the current database is copied to a
+// safety file next to it and the backup is swapped in via a temp file and an
+// atomic rename, so a failure never leaves a half-written database.
+// The database must not be in use while it is restored.
+func (s *BackupService) RestoreBackup(ctx context.Context, backupPath string, opts RestoreOptions) (*RestoreReport, error) {
 	s.mu.Lock()
 	running := s.running
 	s.mu.Unlock()
 
 	if running {
-		return fmt.Errorf("cannot restore while backup service is running")
+		return nil, fmt.Errorf("cannot restore while backup service is running")
 	}
 
 	// Check if backup exists
 	if _, err := os.Stat(backupPath); err != nil {
-		return fmt.Errorf("backup not found: %w", err)
+		return nil, fmt.Errorf("backup not found: %w", err)
 	}
 
-	// Create a temporary backup of the current database
-	tempBackup := s.dbPath + ".pre-restore"
+	if err := verifyBackup(backupPath); err != nil {
+		return nil, fmt.Errorf("backup verification failed: %w", err)
+	}
+	report, err := inspectBackup(backupPath)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = opts.DryRun
+
+	if err := checkWritable(s.dbPath); err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	// Keep a copy of the current database in case the backup was the wrong one
 	if _, err := os.Stat(s.dbPath); err == nil {
-		if err := backupSQLite(s.dbPath, tempBackup); err != nil {
-			return fmt.Errorf("failed to create pre-restore backup: %w", err)
+		safetyCopy := fmt.Sprintf("%s.pre-restore-%s", s.dbPath, time.Now().Format("20060102-150405"))
+		if err := backupSQLite(s.dbPath, safetyCopy); err != nil {
+			return nil, fmt.Errorf("failed to create pre-restore safety copy: %w", err)
 		}
-		defer func() {
-			// Clean up temp backup on success
-			_ = os.Remove(tempBackup)
-		}()
+		report.SafetyCopy = safetyCopy
 	}
 
-	// Restore from backup
 	if err := restoreSQLite(backupPath, s.dbPath); err != nil {
-		// Try to restore from temp backup on failure
-		if _, statErr := os.Stat(tempBackup); statErr == nil {
-			if restoreErr := restoreSQLite(tempBackup, s.dbPath); restoreErr != nil {
-				return fmt.Errorf("restore failed and rollback failed: %v (restore error: %w)", restoreErr, err)
-			}
-			return fmt.Errorf("restore failed, rolled back to previous state: %w", err)
-		}
-		return err
+		return nil, err
 	}
 
 	log.Printf("Database restored from backup: %s", backupPath)
-	return nil
+	if report.SafetyCopy != "" {
+		log.Printf("Previous database saved to: %s", report.SafetyCopy)
+	}
+	return report, nil
 }
 
 // HealthCheck returns the current health status of the backup service.
@@ -2,39 +2,106 @@ package backup
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"path/filepath"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
 
+// backupBusyTimeout is how long a backup waits for a writer holding the
+// database lock before giving up.
+const backupBusyTimeout = 5 * time.Second
+
+// sqliteHeader is the magic string every SQLite database file starts with.
+const sqliteHeader = "SQLite format 3\x00"
+
 // backupSQLite creates a consistent backup of a SQLite database.
-// It uses SQLite's VACUUM INTO command which handles WAL mode correctly
-// and creates a consistent point-in-time backup.
+// It uses SQLite's VACUUM INTO command, which reads the database inside a
+// single read transaction: the snapshot is consistent even while other
+// connections (e.g. memento-mcp) keep writing, and it handles WAL mode
+// correctly. Writers are never blocked in WAL mode; in rollback-journal mode
+// the backup waits up to backupBusyTimeout for the lock.
+//
+// A source that is not a SQLite file is copied byte for byte instead. Server
+// databases such as PostgreSQL are not file-based and need pg_dump.
 func backupSQLite(sourcePath, destPath string) error {
+	isSQLite, err := isSQLiteFile(sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to read source database: %w", err)
+	}
+	if !isSQLite {
+		log.Printf("Warning: %s is not a SQLite database; falling back to a file copy", sourcePath)
+		return copyFile(sourcePath, destPath)
+	}
+
 	// Open source database in read-only mode
 	sourceDB, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", sourcePath))
 	if err != nil {
 		return fmt.Errorf("failed to open source database: %w", err)
 	}
 	defer func() { _ = sourceDB.Close() }()
+	sourceDB.SetMaxOpenConns(1)
 
 	// Verify source database is accessible
 	if err := sourceDB.Ping(); err != nil {
 		return fmt.Errorf("failed to ping source database: %w", err)
 	}
+	if _, err := sourceDB.Exec(fmt.Sprintf("PRAGMA busy_timeout = %d", backupBusyTimeout.Milliseconds())); err != nil {
+		return fmt.Errorf("failed to set busy timeout: %w", err)
+	}
 
-	// Use VACUUM INTO to create backup
-	// This creates a consistent backup even with WAL mode
-	_, err = sourceDB.Exec(fmt.Sprintf("VACUUM INTO '%s'", destPath))
-	if err != nil {
+	// Use VACUUM INTO to create backup. The target is bound as a parameter
+	// so paths containing quotes are handled safely.
+	if _, err := sourceDB.Exec("VACUUM INTO ?", destPath); err != nil {
 		return fmt.Errorf("failed to backup database: %w", err)
 	}
 
 	return nil
 }
 
+// isSQLiteFile reports whether path starts with the SQLite file header.
+func isSQLiteFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	header := make([]byte, len(sqliteHeader))
+	if _, err := io.ReadFull(f, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return string(header) == sqliteHeader, nil
+}
+
+// copyFile copies sourcePath to destPath and syncs it to disk.
+func copyFile(sourcePath, destPath string) error {
+	src, err := os.Open(sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to open source: %w", err)
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer func() { _ = dst.Close() }()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	return dst.Sync()
+}
+
 // verifyBackup checks the integrity of a SQLite backup.
 // It opens the backup database and runs SQLite's integrity_check pragma.
 func verifyBackup(backupPath string) error {
@@ -58,8 +125,81 @@ func verifyBackup(backupPath string) error {
 	return nil
 }
 
+// inspectBackup reports how many memories a backup holds and the range of
+// their creation dates. A database without a memories table reports zero.
+func inspectBackup(backupPath string) (*RestoreReport, error) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", backupPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	report := &RestoreReport{BackupPath: backupPath}
+
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'memories'`).Scan(&tables); err != nil {
+		return nil, fmt.Errorf("failed to inspect backup: %w", err)
+	}
+	if tables == 0 {
+		return report, nil
+	}
+
+	// Databases from before soft delete have no deleted_at column.
+	var hasDeletedAt int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('memories') WHERE name = 'deleted_at'`).Scan(&hasDeletedAt); err != nil {
+		return nil, fmt.Errorf("failed to inspect backup: %w", err)
+	}
+	where := ""
+	if hasDeletedAt > 0 {
+		where = " WHERE deleted_at IS NULL"
+	}
+
+	if err := db.QueryRow(`SELECT COUNT(*) FROM memories` + where).Scan(&report.MemoryCount); err != nil {
+		return nil, fmt.Errorf("failed to count memories in backup: %w", err)
+	}
+	if report.MemoryCount == 0 {
+		return report, nil
+	}
+
+	// Selecting the column itself (not MIN/MAX) keeps its TIMESTAMP type so
+	// the driver parses it.
+	for _, q := range []struct {
+		order string
+		dest  *time.Time
+	}{{"ASC", &report.OldestMemory}, {"DESC", &report.NewestMemory}} {
+		query := `SELECT created_at FROM memories` + where + ` ORDER BY created_at ` + q.order + ` LIMIT 1`
+		if err := db.QueryRow(query).Scan(q.dest); err != nil {
+			return nil, fmt.Errorf("failed to read memory dates from backup: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// checkWritable confirms a restore could write targetPath: its directory
+// accepts new files and, if it exists, the file itself can be opened for
+// writing.
+func checkWritable(targetPath string) error {
+	probe, err := os.CreateTemp(filepath.Dir(targetPath), ".restore-check-*")
+	if err != nil {
+		return fmt.Errorf("restore target directory is not writable: %w", err)
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+
+	f, err := os.OpenFile(targetPath, os.O_WRONLY, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("restore target is not writable: %w", err)
+	}
+	return f.Close()
+}
+
 // restoreSQLite restores a database from a backup.
-// It copies the backup file to the target location.
+// It copies the backup to a temp file beside targetPath, verifies the copy,
+// and renames it over the target so the swap is atomic. Stale WAL and shared
+// memory files belonging to the replaced database are removed.
 // The target database should not be in use when calling this function.
 func restoreSQLite(backupPath, targetPath string) error {
 	// Verify backup before restoring
@@ -74,27 +214,39 @@ func restoreSQLite(backupPath, targetPath string) error {
 	}
 	defer func() { _ = src.Close() }()
 
-	// Create destination file
-	dst, err := os.Create(targetPath)
+	// Stage the copy next to the target so the rename stays on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(targetPath), filepath.Base(targetPath)+".restore-*")
 	if err != nil {
-		return fmt.Errorf("failed to create target file: %w", err)
+		return fmt.Errorf("failed to create temp file: %w", err)
 	}
-	defer func() { _ = dst.Close() }()
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
 
-	// Copy backup to target
-	if _, err := io.Copy(dst, src); err != nil {
+	if _, err := io.Copy(tmp, src); err != nil {
+		_ = tmp.Close()
 		return fmt.Errorf("failed to copy backup: %w", err)
 	}
 
 	// Ensure data is written to disk
-	if err := dst.Sync(); err != nil {
-		return fmt.Errorf("failed to sync target file: %w", err)
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
 	}
 
-	// Verify restored database
-	if err := verifyBackup(targetPath); err != nil {
+	// Verify the staged copy before it replaces anything
+	if err := verifyBackup(tmpPath); err != nil {
 		return fmt.Errorf("restored database verification failed: %w", err)
 	}
 
+	if err := os.Rename(tmpPath, targetPath); err != nil {
+		return fmt.Errorf("failed to replace database: %w", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		_ = os.Remove(targetPath + suffix)
+	}
+
 	return nil
 }
@@ -77,6 +77,33 @@ type BackupResult struct {
 	Error error
 }
 
+// RestoreOptions controls BackupService.RestoreBackup.
+type RestoreOptions struct {
+	// DryRun verifies and inspects the backup and checks the target is
+	// writable, without replacing the live database
+	DryRun bool
+}
+
+// RestoreReport describes a restore, or what a dry run would restore.
+type RestoreReport struct {
+	// BackupPath is the backup that was (or would be) restored
+	BackupPath string
+
+	// MemoryCount is the number of non-deleted memories in the backup
+	MemoryCount int
+
+	// OldestMemory and NewestMemory bound the created_at of those memories;
+	// both are zero when the backup holds no memories
+	OldestMemory time.Time
+	NewestMemory time.Time
+
+	// DryRun is true when the live database was left untouched
+	DryRun bool
+
+	// SafetyCopy is where the previous database was saved before the swap
+	SafetyCopy string
+}
+
 // HealthStatus represents the health of the backup service.
 type HealthStatus struct {
 	// Status is the overall health status: "healthy", "warning", or "error"
@@ -11,8 +11,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"time"
+
+	"github.com/scrypster/memento/pkg/types"
 )
 
 // Config holds all configuration settings for the Memento application.
@@ -30,24 +34,396 @@ type Config struct {
 type ServerConfig struct {
 	Port int    // Server port (default: 6363)
 	Host string // Server host (default: 0.0.0.0)
+
+	// LogLevel is the minimum level logged to stderr: debug, info, warn or
+	// error (see internal/logging).
+	// Env var: MEMENTO_LOG_LEVEL
+	LogLevel string // Minimum log level (default: info)
+
+	// MCPTransport selects how memento-mcp serves MCP: "stdio" for a single
+	// client on stdin/stdout, or "http" for several clients over HTTP with
+	// an SSE notification stream, at path /mcp on MCPHTTPAddr.
+	// Env vars: MEMENTO_MCP_TRANSPORT, MEMENTO_MCP_HTTP_ADDR
+	MCPTransport string // stdio or http (default: stdio)
+	MCPHTTPAddr  string // Listen address of the http transport (default: 127.0.0.1:6364)
+
+	// Limit of the stdio transport: a request line longer than
+	// MCPMaxRequestBytes is discarded and answered with a JSON-RPC error.
+	// Env var: MEMENTO_MCP_MAX_REQUEST_BYTES
+	MCPMaxRequestBytes int // Longest accepted request line (default: 4194304)
 }
 
 // StorageConfig contains database and storage configuration.
 type StorageConfig struct {
 	StorageEngine string // Storage engine type: sqlite, postgres, etc. (default: sqlite)
 	DataPath      string // Path to data directory (default: ./data)
+
+	// CompressContent gzip-compresses memory content of at least
+	// CompressionThreshold bytes in the SQLite store. Full-text search still
+	// indexes the uncompressed text. PostgreSQL already compresses large
+	// values itself (TOAST), so this only affects SQLite.
+	// Env vars: MEMENTO_COMPRESS_CONTENT, MEMENTO_COMPRESSION_THRESHOLD
+	CompressContent      bool // Enable content compression (default: false)
+	CompressionThreshold int  // Minimum content size in bytes to compress (default: 4096)
+
+	// SQLite connection PRAGMAs. Raising SQLiteBusyTimeoutMs reduces
+	// "database is locked" errors when memento-web and memento-mcp share a
+	// database file, at the cost of writers waiting longer under contention.
+	// Env vars: MEMENTO_SQLITE_BUSY_TIMEOUT_MS, MEMENTO_SQLITE_JOURNAL_MODE,
+	// MEMENTO_SQLITE_WAL_AUTOCHECKPOINT
+	SQLiteBusyTimeoutMs     int    // PRAGMA busy_timeout in milliseconds (default: 5000)
+	SQLiteJournalMode       string // PRAGMA journal_mode (default: WAL)
+	SQLiteWALAutocheckpoint int    // PRAGMA wal_autocheckpoint in pages (default: 1000)
+
+	// MemoryIDScheme selects how memento-mcp generates memory IDs:
+	// "deterministic" (mem:<connection>:<hash>) or "opaque" (mem:<uuid>,
+	// routed through a memory_routes.db table in DataPath). "content-hash"
+	// and "uuid" are accepted as their aliases. A connection's id_prefix in
+	// connections.json replaces "mem" in either scheme.
+	// Env var: MEMENTO_MEMORY_ID_SCHEME
+	MemoryIDScheme string // Memory ID scheme (default: deterministic)
+
+	// DetectIDCollisions makes store_memory check that a memory already
+	// stored under a new memory's ID has the same content. If not, the two
+	// contents merely share an ID prefix, and the new memory is stored as
+	// <id>-2 (or -3, ...) instead of being dropped as a duplicate.
+	// Env var: MEMENTO_DETECT_ID_COLLISIONS
+	DetectIDCollisions bool // Disambiguate memory ID collisions (default: true)
+
+	// MaxContentLength caps store_memory content, in characters. Longer
+	// content is rejected unless the caller passes truncate=true, in which
+	// case it is stored in full but only the first MaxContentLength
+	// characters are enriched and embedded. 0 disables the limit.
+	// Env var: MEMENTO_MAX_CONTENT_LENGTH
+	MaxContentLength int // Max store_memory content length in characters (default: 32768)
+
+	// NormalizeUnicode NFC-normalizes content written by store_memory,
+	// update_memory and evolve_memory, so precomposed and decomposed
+	// spellings of the same text search alike. Trimming and stripping of
+	// control characters happen regardless.
+	// Env var: MEMENTO_NORMALIZE_UNICODE
+	NormalizeUnicode bool // NFC-normalize stored content (default: true)
+
+	// DedupNormalization controls how much store_memory normalizes content
+	// before hashing it into the memory ID, so near-identical submissions
+	// collapse to the same memory: "exact" (no normalization), "whitespace"
+	// (trim and collapse runs of whitespace) or "normalized" (also lowercase
+	// and strip trailing punctuation). The stored content is not changed by
+	// this setting; stores record the hash of the normalized content as
+	// content_hash. Changing it affects dedup of memories stored afterwards
+	// only; existing IDs and hashes are not recomputed.
+	// Env var: MEMENTO_DEDUP_NORMALIZATION
+	DedupNormalization string // Dedup normalization level (default: exact)
+
+	// DefaultLimit and MaxLimit bound how many results the MCP list and
+	// search tools return per call. An omitted limit uses DefaultLimit and a
+	// larger one is clamped to MaxLimit, which cannot exceed 100.
+	// Env vars: MEMENTO_DEFAULT_LIMIT, MEMENTO_MAX_LIMIT
+	DefaultLimit int // Results per call when no limit is given (default: 10)
+	MaxLimit     int // Largest limit a call may request (default: 100)
+
+	// TraversalMaxBreadth caps how many memories a single entity contributes
+	// to each hop of traverse_memory_graph; a hub entity linked to more only
+	// expands its highest-decay memories.
+	// Env var: MEMENTO_TRAVERSAL_MAX_BREADTH
+	TraversalMaxBreadth int // Memories expanded per entity per hop (default: 100)
+
+	// FuzzyThreshold and FuzzyMaxResults tune find_related's fuzzy option:
+	// the trigram similarity (0-1) a stored word needs to a query word to
+	// count as a typo match, and how many such matches are added.
+	// Env vars: MEMENTO_FUZZY_THRESHOLD, MEMENTO_FUZZY_MAX_RESULTS
+	FuzzyThreshold  float64 // Minimum similarity of a fuzzy match (default: 0.5)
+	FuzzyMaxResults int     // Fuzzy matches added per search (default: 10)
+
+	// AutoArchive periodically archives stale memories: decay score below
+	// AutoArchiveMaxDecayScore, not accessed for AutoArchiveStaleDays and
+	// accessed at most AutoArchiveMaxAccessCount times (-1 for any count).
+	// Memories tagged "pinned" are never archived. Archived memories drop
+	// out of search but stay retrievable by ID. With AutoArchiveDryRun the
+	// candidates are only logged.
+	// Env vars: MEMENTO_AUTO_ARCHIVE, MEMENTO_AUTO_ARCHIVE_INTERVAL,
+	// MEMENTO_AUTO_ARCHIVE_MAX_DECAY_SCORE, MEMENTO_AUTO_ARCHIVE_STALE_DAYS,
+	// MEMENTO_AUTO_ARCHIVE_MAX_ACCESS_COUNT, MEMENTO_AUTO_ARCHIVE_DRY_RUN
+	AutoArchive               bool    // Enable auto-archival (default: false)
+	AutoArchiveInterval       string  // How often the policy runs (default: 24h)
+	AutoArchiveMaxDecayScore  float64 // Decay score below which memories may be archived (default: 0.1)
+	AutoArchiveStaleDays      int     // Days without access before archival (default: 90)
+	AutoArchiveMaxAccessCount int     // Most accesses an archivable memory may have (default: 3)
+	AutoArchiveDryRun         bool    // Log candidates without archiving (default: false)
+
+	// DigestInterval makes memento-web store a digest of the memories
+	// created during each interval, e.g. "24h" for a daily digest or "168h"
+	// for a weekly one. Empty disables scheduled digests; generate_digest
+	// works either way.
+	// Env var: MEMENTO_DIGEST_INTERVAL
+	DigestInterval string // How often memento-web stores a digest (default: "", disabled)
+
+	// EnrichmentWorkers is the number of enrichment workers. 0 picks a
+	// default for the backend: one for SQLite, which serializes all database
+	// access through a single connection, and more for PostgreSQL. The older
+	// MEMENTO_NUM_WORKERS is read when MEMENTO_ENRICHMENT_WORKERS is unset.
+	// Env var: MEMENTO_ENRICHMENT_WORKERS
+	EnrichmentWorkers int // Enrichment worker count (default: 0, backend default)
+
+	// EnrichmentQueueSize is the capacity of the in-memory enrichment queue.
+	// When it is full, store_memory waits up to EnrichmentQueueWait for space
+	// and otherwise defers the job: the memory stays pending and is queued by
+	// the pending rescan, which runs at startup and every
+	// PendingRescanInterval ("0" limits it to startup).
+	// Env vars: MEMENTO_ENRICHMENT_QUEUE_SIZE, MEMENTO_ENRICHMENT_QUEUE_WAIT,
+	// MEMENTO_PENDING_RESCAN_INTERVAL
+	EnrichmentQueueSize   int    // Enrichment queue capacity (default: 1000)
+	EnrichmentQueueWait   string // Max wait for queue space (default: 0s)
+	PendingRescanInterval string // How often pending memories are re-queued (default: 5m)
+
+	// PgvectorIndex selects the approximate nearest-neighbour index the
+	// PostgreSQL store builds on the embedding column: "none" (exact scan),
+	// "hnsw", "ivfflat" or "auto" (HNSW when pgvector supports it, else
+	// ivfflat). The index covers embeddings of LLM.EmbeddingDimension, so
+	// it is only built when that is set. PgvectorIVFFlatLists sets the
+	// ivfflat list count; roughly rows/1000 is a good starting point.
+	// Env vars: MEMENTO_PGVECTOR_INDEX, MEMENTO_PGVECTOR_IVFFLAT_LISTS
+	PgvectorIndex        string // ANN index method (default: none)
+	PgvectorIVFFlatLists int    // ivfflat lists (default: 100)
+
+	// PgMaxOpenConns, PgMaxIdleConns and PgConnMaxLifetimeSeconds size the
+	// connection pool of each PostgreSQL connection. 0 follows database/sql:
+	// unlimited open connections, no idle connections, connections never
+	// recycled. Negative values are rejected.
+	// Env vars: MEMENTO_PG_MAX_OPEN_CONNS, MEMENTO_PG_MAX_IDLE_CONNS,
+	// MEMENTO_PG_CONN_MAX_LIFETIME_SECONDS
+	PgMaxOpenConns           int // Max open connections (default: 25)
+	PgMaxIdleConns           int // Max idle connections (default: 5)
+	PgConnMaxLifetimeSeconds int // Max connection lifetime in seconds (default: 300)
+
+	// StateMachineFile is a JSON file defining custom lifecycle states and
+	// the transitions between them (see types.StateMachine), used instead
+	// of the built-in planning/active/.../archived machine. LoadConfig
+	// reads and validates it into StateMachine. A connection in
+	// connections.json may define its own with "state_machine".
+	// Env var: MEMENTO_STATE_MACHINE_FILE
+	StateMachineFile string // Custom state machine file (default: "", built-in)
+
+	// StateMachine is the machine loaded from StateMachineFile, nil for the
+	// built-in one.
+	StateMachine *types.StateMachine
+
+	// MemoryTemplatesFile is a JSON array of memory templates (see
+	// types.MemoryTemplate) that create_typed_memory offers alongside the
+	// built-in decision, meeting and person templates; one with a built-in
+	// name replaces it. LoadConfig reads and validates it into
+	// MemoryTemplates.
+	// Env var: MEMENTO_MEMORY_TEMPLATES_FILE
+	MemoryTemplatesFile string // Custom memory templates file (default: "", built-ins only)
+
+	// MemoryTemplates are the templates loaded from MemoryTemplatesFile.
+	MemoryTemplates []types.MemoryTemplate
+}
+
+// Validate reports an error if the enrichment worker count or any
+// PostgreSQL pool setting is negative.
+func (s StorageConfig) Validate() error {
+	for _, r := range []struct {
+		env   string
+		value int
+	}{
+		{"MEMENTO_ENRICHMENT_WORKERS", s.EnrichmentWorkers},
+		{"MEMENTO_PG_MAX_OPEN_CONNS", s.PgMaxOpenConns},
+		{"MEMENTO_PG_MAX_IDLE_CONNS", s.PgMaxIdleConns},
+		{"MEMENTO_PG_CONN_MAX_LIFETIME_SECONDS", s.PgConnMaxLifetimeSeconds},
+	} {
+		if r.value < 0 {
+			return fmt.Errorf("config: %s must be non-negative, got %d", r.env, r.value)
+		}
+	}
+	return nil
+}
+
+// loadStateMachine reads and validates StateMachineFile into StateMachine.
+func (s *StorageConfig) loadStateMachine() error {
+	if s.StateMachineFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.StateMachineFile)
+	if err != nil {
+		return fmt.Errorf("config: MEMENTO_STATE_MACHINE_FILE: %w", err)
+	}
+	m, err := types.ParseStateMachine(data)
+	if err != nil {
+		return fmt.Errorf("config: MEMENTO_STATE_MACHINE_FILE %s: %w", s.StateMachineFile, err)
+	}
+	s.StateMachine = m
+	return nil
+}
+
+// loadMemoryTemplates reads and validates MemoryTemplatesFile into
+// MemoryTemplates.
+func (s *StorageConfig) loadMemoryTemplates() error {
+	if s.MemoryTemplatesFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.MemoryTemplatesFile)
+	if err != nil {
+		return fmt.Errorf("config: MEMENTO_MEMORY_TEMPLATES_FILE: %w", err)
+	}
+	templates, err := types.ParseMemoryTemplates(data)
+	if err != nil {
+		return fmt.Errorf("config: MEMENTO_MEMORY_TEMPLATES_FILE %s: %w", s.MemoryTemplatesFile, err)
+	}
+	s.MemoryTemplates = templates
+	return nil
+}
+
+// Memory ID schemes accepted by StorageConfig.MemoryIDScheme.
+const (
+	MemoryIDSchemeDeterministic = "deterministic"
+	MemoryIDSchemeOpaque        = "opaque"
+)
+
+// memoryIDSchemeAliases maps alternative MEMENTO_MEMORY_ID_SCHEME names to
+// the scheme they select.
+var memoryIDSchemeAliases = map[string]string{
+	"content-hash": MemoryIDSchemeDeterministic,
+	"uuid":         MemoryIDSchemeOpaque,
+}
+
+// memoryIDScheme resolves an alias of a memory ID scheme to its canonical
+// name; other values are returned unchanged.
+func memoryIDScheme(name string) string {
+	if scheme, ok := memoryIDSchemeAliases[name]; ok {
+		return scheme
+	}
+	return name
 }
 
+// Dedup normalization levels accepted by StorageConfig.DedupNormalization.
+const (
+	DedupExact      = "exact"
+	DedupWhitespace = "whitespace"
+	DedupNormalized = "normalized"
+)
+
 // LLMConfig contains LLM provider configuration.
 type LLMConfig struct {
 	LLMProvider          string // LLM provider: ollama, openai, anthropic (default: ollama)
-	OllamaURL            string // Ollama API URL (default: http://localhost:11434)
+	OllamaURL            string // Ollama API base URL, e.g. http://ollama:11434 in Docker (default: http://localhost:11434)
 	OllamaModel          string // Ollama model name for extraction (default: qwen2.5:7b)
-	OllamaEmbeddingModel string // Ollama model name for embeddings (default: nomic-embed-text)
+	OllamaEmbeddingModel string // Embedding model name (default: "", nomic-embed-text for Ollama or text-embedding-3-small for OpenAI)
+	EmbeddingDimension   int    // Expected embedding dimension; 0 accepts whatever the model returns (default: 0)
 	OpenAIAPIKey         string // OpenAI API key
 	OpenAIModel          string // OpenAI model name (default: gpt-4)
 	AnthropicAPIKey      string // Anthropic API key
 	AnthropicModel       string // Anthropic model name (default: claude-3-5-sonnet-20241022)
+
+	// EmbeddingProvider selects where embeddings come from, separately from
+	// LLMProvider: "ollama" or "openai". Empty follows LLMProvider, except
+	// that Anthropic, which has no embedding API, falls back to Ollama, so
+	// extraction and summarization can use Claude with a local embedder.
+	// Env var: MEMENTO_EMBEDDING_PROVIDER
+	EmbeddingProvider string // Embedding provider: ollama, openai (default: "", see EmbeddingProviderName)
+
+	// OllamaKeepAlive is how long Ollama keeps the extraction and embedding
+	// models loaded after each call (a Go duration; negative keeps them
+	// loaded indefinitely). Ollama unloads idle models after 5m by default,
+	// so the first enrichment after a quiet spell waits for a reload.
+	// Env var: MEMENTO_OLLAMA_KEEP_ALIVE
+	OllamaKeepAlive string // Sent as keep_alive on each Ollama call (default: "", Ollama's own default)
+
+	// LLMTimeout bounds each embedding and summarization call (a Go
+	// duration; "0" disables the bound). A call that runs longer fails with
+	// a timeout, which consolidate_memories answers by concatenating the
+	// memories instead of summarizing them.
+	// Env var: MEMENTO_LLM_TIMEOUT
+	LLMTimeout string // Max duration of an embedding or summarization call (default: 30s)
+
+	// QueryExpansion lets find_related callers pass expand_query to also
+	// search up to QueryExpansionMaxTerms LLM-suggested synonyms of the query.
+	// Env vars: MEMENTO_QUERY_EXPANSION, MEMENTO_QUERY_EXPANSION_MAX_TERMS
+	QueryExpansion         bool // Enable expand_query (default: false)
+	QueryExpansionMaxTerms int  // Most synonyms searched per query (default: 5)
+
+	// EntityPromptFile and RelationshipPromptFile are text files whose
+	// contents replace the built-in entity and relationship extraction
+	// prompts, e.g. to tune extraction for legal or medical text. They use
+	// placeholders such as {{content}} (see types.ValidateEntityPrompt and
+	// types.ValidateRelationshipPrompt). LoadConfig reads and validates
+	// them into EntityPrompt and RelationshipPrompt.
+	// Env vars: MEMENTO_ENTITY_PROMPT_FILE, MEMENTO_RELATIONSHIP_PROMPT_FILE
+	EntityPromptFile       string // Custom entity prompt file (default: "", built-in)
+	RelationshipPromptFile string // Custom relationship prompt file (default: "", built-in)
+
+	// EntityPrompt and RelationshipPrompt are the prompts loaded from
+	// EntityPromptFile and RelationshipPromptFile, empty for the built-in
+	// ones.
+	EntityPrompt       string
+	RelationshipPrompt string
+}
+
+// EmbeddingProviderName returns the embedding provider in effect:
+// EmbeddingProvider if set, else LLMProvider, with "anthropic" and the
+// empty provider mapped to "ollama".
+func (l LLMConfig) EmbeddingProviderName() string {
+	provider := l.EmbeddingProvider
+	if provider == "" {
+		provider = l.LLMProvider
+	}
+	if provider == "" || provider == "anthropic" {
+		return "ollama"
+	}
+	return provider
+}
+
+// Validate reports an error if the providers, the Ollama endpoint or the
+// keep-alive are malformed, or if Anthropic is selected without an API
+// key. The endpoint is only checked when Ollama generates text or
+// embeddings.
+func (l LLMConfig) Validate() error {
+	switch l.EmbeddingProvider {
+	case "", "ollama", "openai":
+	default:
+		return fmt.Errorf("config: MEMENTO_EMBEDDING_PROVIDER %q must be \"ollama\" or \"openai\"", l.EmbeddingProvider)
+	}
+	if l.LLMProvider == "anthropic" && l.AnthropicAPIKey == "" {
+		return errors.New("config: MEMENTO_ANTHROPIC_API_KEY is required when MEMENTO_LLM_PROVIDER is anthropic")
+	}
+	if l.LLMProvider == "ollama" || l.LLMProvider == "" || l.EmbeddingProviderName() == "ollama" {
+		u, err := url.Parse(l.OllamaURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("config: MEMENTO_OLLAMA_URL %q must be an http or https URL such as http://localhost:11434", l.OllamaURL)
+		}
+	}
+	if l.OllamaKeepAlive != "" {
+		if _, err := time.ParseDuration(l.OllamaKeepAlive); err != nil {
+			return fmt.Errorf("config: MEMENTO_OLLAMA_KEEP_ALIVE %q must be a duration such as 30m or -1m", l.OllamaKeepAlive)
+		}
+	}
+	return nil
+}
+
+// loadExtractionPrompts reads and validates EntityPromptFile and
+// RelationshipPromptFile into EntityPrompt and RelationshipPrompt.
+func (l *LLMConfig) loadExtractionPrompts() error {
+	for _, p := range []struct {
+		env, file string
+		validate  func(string) error
+		prompt    *string
+	}{
+		{"MEMENTO_ENTITY_PROMPT_FILE", l.EntityPromptFile, types.ValidateEntityPrompt, &l.EntityPrompt},
+		{"MEMENTO_RELATIONSHIP_PROMPT_FILE", l.RelationshipPromptFile, types.ValidateRelationshipPrompt, &l.RelationshipPrompt},
+	} {
+		if p.file == "" {
+			continue
+		}
+		data, err := os.ReadFile(p.file)
+		if err != nil {
+			return fmt.Errorf("config: %s: %w", p.env, err)
+		}
+		if err := p.validate(string(data)); err != nil {
+			return fmt.Errorf("config: %s %s: %w", p.env, p.file, err)
+		}
+		*p.prompt = string(data)
+	}
+	return nil
 }
 
 // SecurityConfig contains security and authentication settings.
@@ -68,11 +444,53 @@ type BackupConfig struct {
 	BackupRetentionMonthly int    // Number of monthly backups to keep (default: 12)
 }
 
+// Validate reports an error if any backup retention count is negative.
+func (b BackupConfig) Validate() error {
+	for _, r := range []struct {
+		env   string
+		value int
+	}{
+		{"MEMENTO_BACKUP_RETENTION_HOURLY", b.BackupRetentionHourly},
+		{"MEMENTO_BACKUP_RETENTION_DAILY", b.BackupRetentionDaily},
+		{"MEMENTO_BACKUP_RETENTION_WEEKLY", b.BackupRetentionWeekly},
+		{"MEMENTO_BACKUP_RETENTION_MONTHLY", b.BackupRetentionMonthly},
+	} {
+		if r.value < 0 {
+			return fmt.Errorf("config: %s must be non-negative, got %d", r.env, r.value)
+		}
+	}
+	return nil
+}
+
 // FeaturesConfig contains feature flags.
 type FeaturesConfig struct {
 	EnableWebUI bool // Enable web UI (default: true)
 	EnableMCP   bool // Enable MCP server (default: true)
 	EnableREST  bool // Enable REST API (default: true)
+
+	// EnableContradictionEvents runs contradiction detection after each memory
+	// is enriched and emits a contradiction_detected event when any are found.
+	// Off by default because it scans the store once per enriched memory.
+	// Env var: MEMENTO_ENABLE_CONTRADICTION_EVENTS
+	EnableContradictionEvents bool
+
+	// EnableSemanticContradictions allows detect_contradictions to run in
+	// semantic mode, where an LLM compares a memory with its most similar
+	// memories. Off by default because every semantic check costs an LLM call.
+	// Env var: MEMENTO_ENABLE_SEMANTIC_CONTRADICTIONS
+	EnableSemanticContradictions bool
+
+	// EnableEmbeddingDebug exposes the get_embedding tool, which returns raw
+	// embedding vectors for diagnosing search ranking. Off by default because
+	// the vectors are large and of no use to an ordinary client.
+	// Env var: MEMENTO_ENABLE_EMBEDDING_DEBUG
+	EnableEmbeddingDebug bool
+
+	// EnableAuditLog records every mutating MCP operation (who, which tool,
+	// which memories, which connection) in the audit log, readable with
+	// get_audit_log. Off by default because it adds a write per operation.
+	// Env var: MEMENTO_ENABLE_AUDIT_LOG
+	EnableAuditLog bool
 }
 
 // UserConfig contains user-specific settings that persist across restarts.
@@ -90,6 +508,24 @@ type UserConfig struct {
 // Use LoadConfigFromDB to also read persisted user settings from the database.
 func LoadConfig() (*Config, error) {
 	cfg := buildBaseConfig()
+	if err := cfg.Backup.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Storage.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Storage.loadStateMachine(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Storage.loadMemoryTemplates(); err != nil {
+		return nil, err
+	}
+	if err := cfg.LLM.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.LLM.loadExtractionPrompts(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -104,6 +540,24 @@ func LoadConfigFromDB(db *sql.DB) (*Config, error) {
 	}
 
 	cfg := buildBaseConfig()
+	if err := cfg.Backup.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Storage.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Storage.loadStateMachine(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Storage.loadMemoryTemplates(); err != nil {
+		return nil, err
+	}
+	if err := cfg.LLM.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.LLM.loadExtractionPrompts(); err != nil {
+		return nil, err
+	}
 
 	// Load user_name from settings table (DB takes precedence over env var)
 	userName, err := getSetting(db, "user_name")
@@ -167,20 +621,84 @@ func buildBaseConfig() *Config {
 		Server: ServerConfig{
 			Port: getEnvInt("MEMENTO_PORT", 6363),
 			Host: getEnv("MEMENTO_HOST", "127.0.0.1"),
+
+			LogLevel: getEnv("MEMENTO_LOG_LEVEL", "info"),
+
+			MCPTransport: getEnv("MEMENTO_MCP_TRANSPORT", "stdio"),
+			MCPHTTPAddr:  getEnv("MEMENTO_MCP_HTTP_ADDR", "127.0.0.1:6364"),
+
+			MCPMaxRequestBytes: getEnvInt("MEMENTO_MCP_MAX_REQUEST_BYTES", 4*1024*1024),
 		},
 		Storage: StorageConfig{
 			StorageEngine: getEnv("MEMENTO_STORAGE_ENGINE", "sqlite"),
 			DataPath:      getEnv("MEMENTO_DATA_PATH", "./data"),
+
+			CompressContent:      getEnvBool("MEMENTO_COMPRESS_CONTENT", false),
+			CompressionThreshold: getEnvInt("MEMENTO_COMPRESSION_THRESHOLD", 4096),
+
+			SQLiteBusyTimeoutMs:     getEnvInt("MEMENTO_SQLITE_BUSY_TIMEOUT_MS", 5000),
+			SQLiteJournalMode:       getEnv("MEMENTO_SQLITE_JOURNAL_MODE", "WAL"),
+			SQLiteWALAutocheckpoint: getEnvInt("MEMENTO_SQLITE_WAL_AUTOCHECKPOINT", 1000),
+
+			MemoryIDScheme:     memoryIDScheme(getEnv("MEMENTO_MEMORY_ID_SCHEME", MemoryIDSchemeDeterministic)),
+			DetectIDCollisions: getEnvBool("MEMENTO_DETECT_ID_COLLISIONS", true),
+
+			MaxContentLength: getEnvInt("MEMENTO_MAX_CONTENT_LENGTH", 32768),
+			NormalizeUnicode: getEnvBool("MEMENTO_NORMALIZE_UNICODE", true),
+
+			DedupNormalization: getEnv("MEMENTO_DEDUP_NORMALIZATION", DedupExact),
+
+			DefaultLimit: getEnvInt("MEMENTO_DEFAULT_LIMIT", 10),
+			MaxLimit:     getEnvInt("MEMENTO_MAX_LIMIT", 100),
+
+			TraversalMaxBreadth: getEnvInt("MEMENTO_TRAVERSAL_MAX_BREADTH", 100),
+
+			FuzzyThreshold:  getEnvFloat("MEMENTO_FUZZY_THRESHOLD", 0.5),
+			FuzzyMaxResults: getEnvInt("MEMENTO_FUZZY_MAX_RESULTS", 10),
+
+			AutoArchive:               getEnvBool("MEMENTO_AUTO_ARCHIVE", false),
+			AutoArchiveInterval:       getEnv("MEMENTO_AUTO_ARCHIVE_INTERVAL", "24h"),
+			AutoArchiveMaxDecayScore:  getEnvFloat("MEMENTO_AUTO_ARCHIVE_MAX_DECAY_SCORE", 0.1),
+			AutoArchiveStaleDays:      getEnvInt("MEMENTO_AUTO_ARCHIVE_STALE_DAYS", 90),
+			AutoArchiveMaxAccessCount: getEnvInt("MEMENTO_AUTO_ARCHIVE_MAX_ACCESS_COUNT", 3),
+			AutoArchiveDryRun:         getEnvBool("MEMENTO_AUTO_ARCHIVE_DRY_RUN", false),
+
+			DigestInterval: getEnv("MEMENTO_DIGEST_INTERVAL", ""),
+
+			EnrichmentWorkers:     getEnvInt("MEMENTO_ENRICHMENT_WORKERS", getEnvInt("MEMENTO_NUM_WORKERS", 0)),
+			EnrichmentQueueSize:   getEnvInt("MEMENTO_ENRICHMENT_QUEUE_SIZE", 1000),
+			EnrichmentQueueWait:   getEnv("MEMENTO_ENRICHMENT_QUEUE_WAIT", "0s"),
+			PendingRescanInterval: getEnv("MEMENTO_PENDING_RESCAN_INTERVAL", "5m"),
+
+			PgvectorIndex:        getEnv("MEMENTO_PGVECTOR_INDEX", "none"),
+			PgvectorIVFFlatLists: getEnvInt("MEMENTO_PGVECTOR_IVFFLAT_LISTS", 100),
+
+			PgMaxOpenConns:           getEnvInt("MEMENTO_PG_MAX_OPEN_CONNS", 25),
+			PgMaxIdleConns:           getEnvInt("MEMENTO_PG_MAX_IDLE_CONNS", 5),
+			PgConnMaxLifetimeSeconds: getEnvInt("MEMENTO_PG_CONN_MAX_LIFETIME_SECONDS", 300),
+
+			StateMachineFile:    getEnv("MEMENTO_STATE_MACHINE_FILE", ""),
+			MemoryTemplatesFile: getEnv("MEMENTO_MEMORY_TEMPLATES_FILE", ""),
 		},
 		LLM: LLMConfig{
 			LLMProvider:          getEnv("MEMENTO_LLM_PROVIDER", "ollama"),
 			OllamaURL:            getEnv("MEMENTO_OLLAMA_URL", "http://localhost:11434"),
 			OllamaModel:          getEnv("MEMENTO_OLLAMA_MODEL", "qwen2.5:7b"),
-			OllamaEmbeddingModel: getEnv("MEMENTO_EMBEDDING_MODEL", "nomic-embed-text"),
+			OllamaEmbeddingModel: getEnv("MEMENTO_EMBEDDING_MODEL", ""),
+			EmbeddingDimension:   getEnvInt("MEMENTO_EMBEDDING_DIMENSION", 0),
 			OpenAIAPIKey:         getEnv("MEMENTO_OPENAI_API_KEY", ""),
 			OpenAIModel:          getEnv("MEMENTO_OPENAI_MODEL", "gpt-4"),
 			AnthropicAPIKey:      getEnv("MEMENTO_ANTHROPIC_API_KEY", ""),
 			AnthropicModel:       getEnv("MEMENTO_ANTHROPIC_MODEL", "claude-3-5-sonnet-20241022"),
+			OllamaKeepAlive:      getEnv("MEMENTO_OLLAMA_KEEP_ALIVE", ""),
+			EmbeddingProvider:    getEnv("MEMENTO_EMBEDDING_PROVIDER", ""),
+			LLMTimeout:           getEnv("MEMENTO_LLM_TIMEOUT", "30s"),
+
+			QueryExpansion:         getEnvBool("MEMENTO_QUERY_EXPANSION", false),
+			QueryExpansionMaxTerms: getEnvInt("MEMENTO_QUERY_EXPANSION_MAX_TERMS", 5),
+
+			EntityPromptFile:       getEnv("MEMENTO_ENTITY_PROMPT_FILE", ""),
+			RelationshipPromptFile: getEnv("MEMENTO_RELATIONSHIP_PROMPT_FILE", ""),
 		},
 		Security: SecurityConfig{
 			SecurityMode: getEnv("MEMENTO_SECURITY_MODE", "development"),
@@ -200,6 +718,11 @@ func buildBaseConfig() *Config {
 			EnableWebUI: getEnvBool("MEMENTO_ENABLE_WEB_UI", true),
 			EnableMCP:   getEnvBool("MEMENTO_ENABLE_MCP", true),
 			EnableREST:  getEnvBool("MEMENTO_ENABLE_REST", true),
+
+			EnableContradictionEvents:    getEnvBool("MEMENTO_ENABLE_CONTRADICTION_EVENTS", false),
+			EnableSemanticContradictions: getEnvBool("MEMENTO_ENABLE_SEMANTIC_CONTRADICTIONS", false),
+			EnableEmbeddingDebug:         getEnvBool("MEMENTO_ENABLE_EMBEDDING_DEBUG", false),
+			EnableAuditLog:               getEnvBool("MEMENTO_ENABLE_AUDIT_LOG", false),
 		},
 		User: UserConfig{
 			UserName: getEnv("MEMENTO_USER_NAME", ""),
@@ -227,6 +750,18 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvFloat retrieves a float environment variable or returns a default value.
+// If the environment variable exists but cannot be parsed as a float,
+// it returns the default value.
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 // getEnvBool retrieves a boolean environment variable or returns a default value.
 // It recognizes "true", "1", "yes" as true and "false", "0", "no" as false (case-insensitive).
 // If the environment variable exists but cannot be parsed as a boolean,
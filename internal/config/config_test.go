@@ -3,6 +3,7 @@ package config_test
 import (
 	"database/sql"
 	"os"
+	"path/filepath"
 	"testing"
 
 	_ "modernc.org/sqlite"
@@ -27,6 +28,453 @@ func TestLoadConfig_CanOverrideHost(t *testing.T) {
 	assert.Equal(t, "0.0.0.0", cfg.Server.Host)
 }
 
+// TestStorageConfig_SQLitePragmaDefaults verifies the SQLite PRAGMA defaults
+// match the behaviour the store had before they were configurable.
+func TestStorageConfig_SQLitePragmaDefaults(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_SQLITE_BUSY_TIMEOUT_MS")
+	_ = os.Unsetenv("MEMENTO_SQLITE_JOURNAL_MODE")
+	_ = os.Unsetenv("MEMENTO_SQLITE_WAL_AUTOCHECKPOINT")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, 5000, cfg.Storage.SQLiteBusyTimeoutMs)
+	assert.Equal(t, "WAL", cfg.Storage.SQLiteJournalMode)
+	assert.Equal(t, 1000, cfg.Storage.SQLiteWALAutocheckpoint)
+}
+
+func TestStorageConfig_SQLitePragmaOverrides(t *testing.T) {
+	t.Setenv("MEMENTO_SQLITE_BUSY_TIMEOUT_MS", "15000")
+	t.Setenv("MEMENTO_SQLITE_JOURNAL_MODE", "DELETE")
+	t.Setenv("MEMENTO_SQLITE_WAL_AUTOCHECKPOINT", "500")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, 15000, cfg.Storage.SQLiteBusyTimeoutMs)
+	assert.Equal(t, "DELETE", cfg.Storage.SQLiteJournalMode)
+	assert.Equal(t, 500, cfg.Storage.SQLiteWALAutocheckpoint)
+}
+
+func TestStorageConfig_NormalizeUnicode(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_NORMALIZE_UNICODE")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.Storage.NormalizeUnicode)
+
+	t.Setenv("MEMENTO_NORMALIZE_UNICODE", "false")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.False(t, cfg.Storage.NormalizeUnicode)
+}
+
+func TestFeaturesConfig_EnableAuditLog(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_ENABLE_AUDIT_LOG")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.False(t, cfg.Features.EnableAuditLog)
+
+	t.Setenv("MEMENTO_ENABLE_AUDIT_LOG", "true")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.Features.EnableAuditLog)
+}
+
+func TestStorageConfig_PgvectorIndex(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_PGVECTOR_INDEX")
+	_ = os.Unsetenv("MEMENTO_PGVECTOR_IVFFLAT_LISTS")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "none", cfg.Storage.PgvectorIndex)
+	assert.Equal(t, 100, cfg.Storage.PgvectorIVFFlatLists)
+
+	t.Setenv("MEMENTO_PGVECTOR_INDEX", "hnsw")
+	t.Setenv("MEMENTO_PGVECTOR_IVFFLAT_LISTS", "400")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "hnsw", cfg.Storage.PgvectorIndex)
+	assert.Equal(t, 400, cfg.Storage.PgvectorIVFFlatLists)
+}
+
+func TestStorageConfig_EnrichmentQueue(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_ENRICHMENT_QUEUE_SIZE")
+	_ = os.Unsetenv("MEMENTO_ENRICHMENT_QUEUE_WAIT")
+	_ = os.Unsetenv("MEMENTO_PENDING_RESCAN_INTERVAL")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 1000, cfg.Storage.EnrichmentQueueSize)
+	assert.Equal(t, "0s", cfg.Storage.EnrichmentQueueWait)
+	assert.Equal(t, "5m", cfg.Storage.PendingRescanInterval)
+
+	t.Setenv("MEMENTO_ENRICHMENT_QUEUE_SIZE", "50")
+	t.Setenv("MEMENTO_ENRICHMENT_QUEUE_WAIT", "250ms")
+	t.Setenv("MEMENTO_PENDING_RESCAN_INTERVAL", "0")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 50, cfg.Storage.EnrichmentQueueSize)
+	assert.Equal(t, "250ms", cfg.Storage.EnrichmentQueueWait)
+	assert.Equal(t, "0", cfg.Storage.PendingRescanInterval)
+}
+
+func TestServerConfig_LogLevel(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_LOG_LEVEL")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "info", cfg.Server.LogLevel)
+
+	t.Setenv("MEMENTO_LOG_LEVEL", "debug")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "debug", cfg.Server.LogLevel)
+}
+
+func TestServerConfig_MCPTransport(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_MCP_TRANSPORT")
+	_ = os.Unsetenv("MEMENTO_MCP_HTTP_ADDR")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "stdio", cfg.Server.MCPTransport)
+	assert.Equal(t, "127.0.0.1:6364", cfg.Server.MCPHTTPAddr)
+
+	t.Setenv("MEMENTO_MCP_TRANSPORT", "http")
+	t.Setenv("MEMENTO_MCP_HTTP_ADDR", "0.0.0.0:7000")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "http", cfg.Server.MCPTransport)
+	assert.Equal(t, "0.0.0.0:7000", cfg.Server.MCPHTTPAddr)
+}
+
+func TestServerConfig_MCPStdioLimits(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_MCP_MAX_REQUEST_BYTES")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 4*1024*1024, cfg.Server.MCPMaxRequestBytes)
+
+	t.Setenv("MEMENTO_MCP_MAX_REQUEST_BYTES", "65536")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 65536, cfg.Server.MCPMaxRequestBytes)
+}
+
+func TestStorageConfig_TraversalMaxBreadth(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_TRAVERSAL_MAX_BREADTH")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 100, cfg.Storage.TraversalMaxBreadth)
+
+	t.Setenv("MEMENTO_TRAVERSAL_MAX_BREADTH", "25")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 25, cfg.Storage.TraversalMaxBreadth)
+}
+
+func TestStorageConfig_Fuzzy(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_FUZZY_THRESHOLD")
+	_ = os.Unsetenv("MEMENTO_FUZZY_MAX_RESULTS")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, cfg.Storage.FuzzyThreshold)
+	assert.Equal(t, 10, cfg.Storage.FuzzyMaxResults)
+
+	t.Setenv("MEMENTO_FUZZY_THRESHOLD", "0.7")
+	t.Setenv("MEMENTO_FUZZY_MAX_RESULTS", "3")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 0.7, cfg.Storage.FuzzyThreshold)
+	assert.Equal(t, 3, cfg.Storage.FuzzyMaxResults)
+}
+
+func TestLLMConfig_QueryExpansion(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_QUERY_EXPANSION")
+	_ = os.Unsetenv("MEMENTO_QUERY_EXPANSION_MAX_TERMS")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.False(t, cfg.LLM.QueryExpansion)
+	assert.Equal(t, 5, cfg.LLM.QueryExpansionMaxTerms)
+
+	t.Setenv("MEMENTO_QUERY_EXPANSION", "true")
+	t.Setenv("MEMENTO_QUERY_EXPANSION_MAX_TERMS", "3")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.LLM.QueryExpansion)
+	assert.Equal(t, 3, cfg.LLM.QueryExpansionMaxTerms)
+}
+
+func TestLLMConfig_OllamaEndpoint(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_LLM_PROVIDER")
+	_ = os.Unsetenv("MEMENTO_OLLAMA_URL")
+	_ = os.Unsetenv("MEMENTO_OLLAMA_KEEP_ALIVE")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:11434", cfg.LLM.OllamaURL)
+	assert.Empty(t, cfg.LLM.OllamaKeepAlive, "Ollama's own keep-alive by default")
+
+	t.Setenv("MEMENTO_OLLAMA_URL", "http://ollama:11434")
+	t.Setenv("MEMENTO_OLLAMA_KEEP_ALIVE", "30m")
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "http://ollama:11434", cfg.LLM.OllamaURL)
+	assert.Equal(t, "30m", cfg.LLM.OllamaKeepAlive)
+
+	t.Setenv("MEMENTO_OLLAMA_KEEP_ALIVE", "forever")
+	_, err = config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MEMENTO_OLLAMA_KEEP_ALIVE")
+
+	t.Setenv("MEMENTO_OLLAMA_KEEP_ALIVE", "-1m")
+	for _, bad := range []string{"localhost:11434", "ftp://ollama:11434", "http://"} {
+		t.Setenv("MEMENTO_OLLAMA_URL", bad)
+		_, err = config.LoadConfig()
+		require.Error(t, err, bad)
+		assert.Contains(t, err.Error(), "MEMENTO_OLLAMA_URL")
+		_, err = config.LoadConfigFromDB(openTestDB(t))
+		assert.Error(t, err, bad)
+	}
+
+	// The endpoint is not used, so not checked, with another provider.
+	t.Setenv("MEMENTO_LLM_PROVIDER", "openai")
+	_, err = config.LoadConfig()
+	assert.NoError(t, err)
+}
+
+func TestLLMConfig_EmbeddingProvider(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_EMBEDDING_PROVIDER")
+	_ = os.Unsetenv("MEMENTO_EMBEDDING_MODEL")
+	_ = os.Unsetenv("MEMENTO_OLLAMA_URL")
+	t.Setenv("MEMENTO_LLM_PROVIDER", "openai")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "openai", cfg.LLM.EmbeddingProviderName(), "follows the LLM provider by default")
+	assert.Empty(t, cfg.LLM.OllamaEmbeddingModel, "the embedding provider picks its own default model")
+
+	t.Setenv("MEMENTO_LLM_PROVIDER", "anthropic")
+	_, err = config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MEMENTO_ANTHROPIC_API_KEY")
+
+	t.Setenv("MEMENTO_ANTHROPIC_API_KEY", "sk-ant-test")
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "ollama", cfg.LLM.EmbeddingProviderName(), "Anthropic has no embeddings, so Ollama embeds")
+
+	// Ollama only embeds here, but its endpoint is still checked.
+	t.Setenv("MEMENTO_OLLAMA_URL", "not a url")
+	_, err = config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MEMENTO_OLLAMA_URL")
+
+	t.Setenv("MEMENTO_EMBEDDING_PROVIDER", "openai")
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "openai", cfg.LLM.EmbeddingProviderName())
+
+	t.Setenv("MEMENTO_EMBEDDING_PROVIDER", "anthropic")
+	_, err = config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MEMENTO_EMBEDDING_PROVIDER")
+}
+
+// TestBackupConfig_RetentionDefaults verifies the retention tiers default to
+// the policy memento-backup used before it was configurable.
+func TestBackupConfig_RetentionDefaults(t *testing.T) {
+	for _, key := range []string{"HOURLY", "DAILY", "WEEKLY", "MONTHLY"} {
+		_ = os.Unsetenv("MEMENTO_BACKUP_RETENTION_" + key)
+	}
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, 24, cfg.Backup.BackupRetentionHourly)
+	assert.Equal(t, 7, cfg.Backup.BackupRetentionDaily)
+	assert.Equal(t, 4, cfg.Backup.BackupRetentionWeekly)
+	assert.Equal(t, 12, cfg.Backup.BackupRetentionMonthly)
+}
+
+func TestBackupConfig_RetentionOverrides(t *testing.T) {
+	t.Setenv("MEMENTO_BACKUP_RETENTION_HOURLY", "48")
+	t.Setenv("MEMENTO_BACKUP_RETENTION_DAILY", "14")
+	t.Setenv("MEMENTO_BACKUP_RETENTION_WEEKLY", "0")
+	t.Setenv("MEMENTO_BACKUP_RETENTION_MONTHLY", "not-a-number")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, 48, cfg.Backup.BackupRetentionHourly)
+	assert.Equal(t, 14, cfg.Backup.BackupRetentionDaily)
+	assert.Equal(t, 0, cfg.Backup.BackupRetentionWeekly)
+	assert.Equal(t, 12, cfg.Backup.BackupRetentionMonthly, "unparseable values fall back to the default")
+}
+
+func TestBackupConfig_NegativeRetentionRejected(t *testing.T) {
+	t.Setenv("MEMENTO_BACKUP_RETENTION_DAILY", "-1")
+
+	_, err := config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MEMENTO_BACKUP_RETENTION_DAILY")
+
+	_, err = config.LoadConfigFromDB(openTestDB(t))
+	assert.Error(t, err)
+}
+
+func TestStorageConfig_EnrichmentWorkers(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_ENRICHMENT_WORKERS")
+	_ = os.Unsetenv("MEMENTO_NUM_WORKERS")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 0, cfg.Storage.EnrichmentWorkers, "0 selects the backend default")
+
+	t.Setenv("MEMENTO_NUM_WORKERS", "3")
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 3, cfg.Storage.EnrichmentWorkers, "the older variable is still read")
+
+	t.Setenv("MEMENTO_ENRICHMENT_WORKERS", "8")
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 8, cfg.Storage.EnrichmentWorkers)
+
+	t.Setenv("MEMENTO_ENRICHMENT_WORKERS", "-2")
+	_, err = config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MEMENTO_ENRICHMENT_WORKERS")
+}
+
+func TestStorageConfig_PostgresPool(t *testing.T) {
+	for _, key := range []string{"MEMENTO_PG_MAX_OPEN_CONNS", "MEMENTO_PG_MAX_IDLE_CONNS", "MEMENTO_PG_CONN_MAX_LIFETIME_SECONDS"} {
+		_ = os.Unsetenv(key)
+	}
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 25, cfg.Storage.PgMaxOpenConns)
+	assert.Equal(t, 5, cfg.Storage.PgMaxIdleConns)
+	assert.Equal(t, 300, cfg.Storage.PgConnMaxLifetimeSeconds)
+
+	t.Setenv("MEMENTO_PG_MAX_OPEN_CONNS", "80")
+	t.Setenv("MEMENTO_PG_MAX_IDLE_CONNS", "20")
+	t.Setenv("MEMENTO_PG_CONN_MAX_LIFETIME_SECONDS", "0")
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 80, cfg.Storage.PgMaxOpenConns)
+	assert.Equal(t, 20, cfg.Storage.PgMaxIdleConns)
+	assert.Equal(t, 0, cfg.Storage.PgConnMaxLifetimeSeconds)
+
+	t.Setenv("MEMENTO_PG_MAX_IDLE_CONNS", "-1")
+
+	_, err = config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MEMENTO_PG_MAX_IDLE_CONNS")
+}
+
+func TestStorageConfig_StateMachineFile(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_STATE_MACHINE_FILE")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Nil(t, cfg.Storage.StateMachine, "the built-in machine by default")
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "states.json")
+	require.NoError(t, os.WriteFile(valid, []byte(`{
+		"states": ["todo", "review", "done"],
+		"initial": ["todo"],
+		"transitions": {"todo": ["review"], "review": ["todo", "done"]}
+	}`), 0o644))
+	t.Setenv("MEMENTO_STATE_MACHINE_FILE", valid)
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Storage.StateMachine)
+	assert.True(t, cfg.Storage.StateMachine.CanTransition("todo", "review"))
+
+	dangling := filepath.Join(dir, "dangling.json")
+	require.NoError(t, os.WriteFile(dangling, []byte(`{
+		"states": ["todo", "done"],
+		"initial": ["todo"],
+		"transitions": {"todo": ["qa"]}
+	}`), 0o644))
+	t.Setenv("MEMENTO_STATE_MACHINE_FILE", dangling)
+
+	_, err = config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MEMENTO_STATE_MACHINE_FILE")
+
+	t.Setenv("MEMENTO_STATE_MACHINE_FILE", filepath.Join(dir, "missing.json"))
+	_, err = config.LoadConfig()
+	require.Error(t, err)
+}
+
+func TestStorageConfig_MemoryIDSchemeAliases(t *testing.T) {
+	for value, want := range map[string]string{
+		"":             config.MemoryIDSchemeDeterministic,
+		"content-hash": config.MemoryIDSchemeDeterministic,
+		"uuid":         config.MemoryIDSchemeOpaque,
+		"opaque":       config.MemoryIDSchemeOpaque,
+		"sequential":   "sequential", // rejected by memento-mcp
+	} {
+		if value == "" {
+			_ = os.Unsetenv("MEMENTO_MEMORY_ID_SCHEME")
+		} else {
+			t.Setenv("MEMENTO_MEMORY_ID_SCHEME", value)
+		}
+		cfg, err := config.LoadConfig()
+		require.NoError(t, err)
+		assert.Equal(t, want, cfg.Storage.MemoryIDScheme, value)
+	}
+}
+
+func TestStorageConfig_MemoryTemplatesFile(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_MEMORY_TEMPLATES_FILE")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Storage.MemoryTemplates, "only the built-ins by default")
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "templates.json")
+	require.NoError(t, os.WriteFile(valid, []byte(`[
+		{"name": "incident", "memory_type": "event", "fields": [{"name": "summary", "required": true}]}
+	]`), 0o644))
+	t.Setenv("MEMENTO_MEMORY_TEMPLATES_FILE", valid)
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	require.Len(t, cfg.Storage.MemoryTemplates, 1)
+	assert.Equal(t, "incident", cfg.Storage.MemoryTemplates[0].Name)
+
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`[{"name": "incident", "fields": []}]`), 0o644))
+	t.Setenv("MEMENTO_MEMORY_TEMPLATES_FILE", invalid)
+
+	_, err = config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MEMENTO_MEMORY_TEMPLATES_FILE")
+}
+
 // TestUserConfig_DefaultValues verifies UserConfig has sensible defaults
 // when no environment variables or database entries are set.
 func TestUserConfig_DefaultValues(t *testing.T) {
@@ -212,3 +660,32 @@ func openTestDB(t *testing.T) *sql.DB {
 
 	return db
 }
+
+func TestLLMConfig_ExtractionPromptFiles(t *testing.T) {
+	_ = os.Unsetenv("MEMENTO_ENTITY_PROMPT_FILE")
+	_ = os.Unsetenv("MEMENTO_RELATIONSHIP_PROMPT_FILE")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.LLM.EntityPrompt, "the built-in prompt by default")
+	assert.Empty(t, cfg.LLM.RelationshipPrompt, "the built-in prompt by default")
+
+	dir := t.TempDir()
+	entity := filepath.Join(dir, "entity.txt")
+	require.NoError(t, os.WriteFile(entity, []byte("Extract parties from:\n{{content}}"), 0o644))
+	t.Setenv("MEMENTO_ENTITY_PROMPT_FILE", entity)
+
+	cfg, err = config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "Extract parties from:\n{{content}}", cfg.LLM.EntityPrompt)
+	assert.Empty(t, cfg.LLM.RelationshipPrompt)
+
+	relationship := filepath.Join(dir, "relationship.txt")
+	require.NoError(t, os.WriteFile(relationship, []byte("Relate these parties:\n{{content}}"), 0o644))
+	t.Setenv("MEMENTO_RELATIONSHIP_PROMPT_FILE", relationship)
+
+	_, err = config.LoadConfig()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MEMENTO_RELATIONSHIP_PROMPT_FILE")
+	assert.Contains(t, err.Error(), "{{entities}}")
+}
@@ -8,12 +8,15 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/internal/storage/postgres"
 	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
 )
 
 // sanitizeDSN replaces the password in a DSN string with [REDACTED] for safe logging.
@@ -48,24 +51,73 @@ type DatabaseConfig struct {
 
 // LLMConfig holds LLM configuration per connection
 type LLMConfig struct {
-	Provider       string `json:"provider"`                        // ollama, openai, anthropic
-	Model          string `json:"model"`                           // Model name
-	APIKey         string `json:"api_key,omitempty"`               // For cloud providers
-	BaseURL        string `json:"base_url,omitempty"`              // Custom base URL (Ollama/custom endpoints)
-	EmbeddingModel string `json:"embedding_model,omitempty"`       // Model name for embeddings
+	Provider       string `json:"provider"`                  // ollama, openai, anthropic
+	Model          string `json:"model"`                     // Model name
+	APIKey         string `json:"api_key,omitempty"`         // For cloud providers
+	BaseURL        string `json:"base_url,omitempty"`        // Custom base URL (Ollama/custom endpoints)
+	EmbeddingModel string `json:"embedding_model,omitempty"` // Model name for embeddings
+	KeepAlive      string `json:"keep_alive,omitempty"`      // How long Ollama keeps models loaded, e.g. "30m"
 }
 
 // Connection represents a workspace/project connection configuration
 type Connection struct {
-	Name             string          `json:"name"`
-	DisplayName      string          `json:"display_name"`
-	Description      string          `json:"description"`
-	Enabled          bool            `json:"enabled"`
-	CreatedAt        string          `json:"created_at"`
-	Database         DatabaseConfig  `json:"database"`
-	LLM              LLMConfig       `json:"llm"`
-	CategoryTemplate string          `json:"category_template,omitempty"`
-	Categories       []string        `json:"categories,omitempty"`
+	Name             string         `json:"name"`
+	DisplayName      string         `json:"display_name"`
+	Description      string         `json:"description"`
+	Enabled          bool           `json:"enabled"`
+	CreatedAt        string         `json:"created_at"`
+	Database         DatabaseConfig `json:"database"`
+	LLM              LLMConfig      `json:"llm"`
+	CategoryTemplate string         `json:"category_template,omitempty"`
+	Categories       []string       `json:"categories,omitempty"`
+
+	// EnrichmentEnabled turns entity extraction, embeddings and the other
+	// enrichment steps on or off for memories stored in this connection.
+	// Unset means enabled, so existing configs keep enriching; set it to
+	// false for a lightweight connection such as a raw log dump.
+	EnrichmentEnabled *bool `json:"enrichment_enabled,omitempty"`
+
+	// ReadOnly makes the MCP server reject every tool that would write to
+	// this connection, such as an archived workspace that agents may search
+	// but never modify. Reads are unaffected.
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// StateMachine replaces the lifecycle states and transitions
+	// update_memory_state accepts in this connection, e.g. to add "review"
+	// or "qa" states. Unset uses the manager's default (see
+	// SetStateMachine), which is the built-in machine unless configured.
+	StateMachine *types.StateMachine `json:"state_machine,omitempty"`
+
+	// IDPrefix replaces "mem" at the start of the memory IDs generated for
+	// this connection, e.g. "acme" gives acme:<connection>:<hash> or, with
+	// opaque IDs, acme:<uuid>, so IDs embedded in another system do not
+	// collide with its own. Letters, digits, '-' and '_' only, and unique
+	// across connections. Unset means DefaultIDPrefix.
+	IDPrefix string `json:"id_prefix,omitempty"`
+}
+
+// DefaultIDPrefix starts the memory IDs of connections without an id_prefix.
+const DefaultIDPrefix = "mem"
+
+// idPrefixPattern matches a valid Connection.IDPrefix.
+var idPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Validate reports an error if the connection's state machine or ID
+// prefix is invalid.
+func (c Connection) Validate() error {
+	if err := c.StateMachine.Validate(); err != nil {
+		return err
+	}
+	if c.IDPrefix != "" && !idPrefixPattern.MatchString(c.IDPrefix) {
+		return fmt.Errorf("invalid id_prefix %q: use letters, digits, '-' and '_' only", c.IDPrefix)
+	}
+	return nil
+}
+
+// Enriches reports whether memories stored in the connection are enriched
+// (see EnrichmentEnabled).
+func (c Connection) Enriches() bool {
+	return c.EnrichmentEnabled == nil || *c.EnrichmentEnabled
 }
 
 // ConnectionsConfig holds the connections configuration
@@ -85,8 +137,14 @@ type Manager struct {
 	stores      map[string]storage.MemoryStore
 	storesLock  sync.RWMutex
 	configPath  string
-	baseDir     string // Directory used to resolve relative paths in the config
-	ownedStores map[string]bool // Track which stores are owned vs borrowed
+	baseDir     string           // Directory used to resolve relative paths in the config
+	ownedStores map[string]bool  // Track which stores are owned vs borrowed
+	openErrors  map[string]error // Last error opening each connection's store, cleared on success
+
+	sqliteOptions      []sqlite.Option     // Options applied to every SQLite store opened
+	postgresOptions    []postgres.Option   // Options applied to every PostgreSQL store opened
+	stateMachine       *types.StateMachine // State machine of connections that do not define one
+	dedupNormalization string              // Content normalization before hashing into content_hash
 }
 
 // NewManagerWithStore creates a Manager that wraps a single pre-existing store.
@@ -102,6 +160,7 @@ func NewManagerWithStore(store storage.MemoryStore, connectionName string) *Mana
 		ownedStores: map[string]bool{
 			connectionName: false, // Borrowed from caller, don't close
 		},
+		openErrors: make(map[string]error),
 		config: &ConnectionsConfig{
 			DefaultConnection: connectionName,
 			Connections: []Connection{
@@ -128,6 +187,7 @@ func NewManager(configPath string) (*Manager, error) {
 	manager := &Manager{
 		stores:      make(map[string]storage.MemoryStore),
 		ownedStores: make(map[string]bool),
+		openErrors:  make(map[string]error),
 		configPath:  absPath,
 		// Relative paths inside connections.json are resolved from the directory
 		// that *contains* the config file (e.g. the project root when connections.json
@@ -155,6 +215,14 @@ func (m *Manager) LoadConfig() error {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return fmt.Errorf("failed to parse config: %w", err)
 	}
+	for i, conn := range config.Connections {
+		if err := conn.Validate(); err != nil {
+			return fmt.Errorf("connection '%s': %w", conn.Name, err)
+		}
+		if other := idPrefixOwner(config.Connections[:i], conn.IDPrefix, ""); other != "" {
+			return fmt.Errorf("connection '%s': id_prefix %q is already used by connection '%s'", conn.Name, conn.IDPrefix, other)
+		}
+	}
 
 	m.config = &config
 	return nil
@@ -212,10 +280,72 @@ func (m *Manager) GetStore(connectionName string) (storage.MemoryStore, error) {
 		return nil, fmt.Errorf("connection '%s' is disabled", connectionName)
 	}
 
-	// Create new store based on database type
+	store, err := m.openStore(conn)
+	if err != nil {
+		m.storesLock.Lock()
+		m.openErrors[connectionName] = err
+		m.storesLock.Unlock()
+		return nil, err
+	}
+
+	// Cache it and mark as owned by this manager
+	m.storesLock.Lock()
+	m.stores[connectionName] = store
+	m.ownedStores[connectionName] = true
+	delete(m.openErrors, connectionName)
+	m.storesLock.Unlock()
+
+	return store, nil
+}
+
+// SetSQLiteOptions sets the options (e.g. content compression and the
+// busy timeout and journal mode PRAGMAs) applied to SQLite stores the
+// manager opens or tests. Call it before the first GetStore; stores that are
+// already open are not affected.
+func (m *Manager) SetSQLiteOptions(opts ...sqlite.Option) {
+	m.storesLock.Lock()
+	defer m.storesLock.Unlock()
+	m.sqliteOptions = opts
+}
+
+// SetPostgresOptions sets the options (e.g. the pgvector ANN index) applied
+// to PostgreSQL stores the manager opens. Call it before the first GetStore;
+// stores that are already open are not affected.
+func (m *Manager) SetPostgresOptions(opts ...postgres.Option) {
+	m.storesLock.Lock()
+	defer m.storesLock.Unlock()
+	m.postgresOptions = opts
+}
+
+// SetStateMachine sets the lifecycle state machine of stores opened for
+// connections that do not define their own. nil is the built-in machine.
+// Stores already open are unaffected.
+func (m *Manager) SetStateMachine(sm *types.StateMachine) {
+	m.storesLock.Lock()
+	defer m.storesLock.Unlock()
+	m.stateMachine = sm
+}
+
+// SetDedupNormalization sets how stores the manager opens normalize content
+// before hashing it into content_hash (see sqlite.WithDedupNormalization).
+// Stores already open are unaffected.
+func (m *Manager) SetDedupNormalization(level string) {
+	m.storesLock.Lock()
+	defer m.storesLock.Unlock()
+	m.dedupNormalization = level
+}
+
+// openStore opens a new store for conn based on its database type.
+func (m *Manager) openStore(conn *Connection) (storage.MemoryStore, error) {
+	connectionName := conn.Name
 	var store storage.MemoryStore
 	var err error
 
+	stateMachine := conn.StateMachine
+	if stateMachine == nil {
+		stateMachine = m.stateMachine
+	}
+
 	switch conn.Database.Type {
 	case "sqlite":
 		dbPath := conn.Database.Path
@@ -224,7 +354,10 @@ func (m *Manager) GetStore(connectionName string) (storage.MemoryStore, error) {
 		if !filepath.IsAbs(dbPath) && m.baseDir != "" {
 			dbPath = filepath.Join(m.baseDir, dbPath)
 		}
-		store, err = sqlite.NewMemoryStore(dbPath)
+		opts := append(slices.Clone(m.sqliteOptions),
+			sqlite.WithStateMachine(stateMachine),
+			sqlite.WithDedupNormalization(m.dedupNormalization))
+		store, err = sqlite.NewMemoryStore(dbPath, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create SQLite store for '%s': %w", connectionName, err)
 		}
@@ -247,7 +380,10 @@ func (m *Manager) GetStore(connectionName string) (storage.MemoryStore, error) {
 			conn.Database.Database,
 			sslmode,
 		)
-		store, err = postgres.NewMemoryStore(dsn)
+		opts := append(slices.Clone(m.postgresOptions),
+			postgres.WithStateMachine(stateMachine),
+			postgres.WithDedupNormalization(m.dedupNormalization))
+		store, err = postgres.NewMemoryStore(dsn, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create PostgreSQL store for '%s' (DSN: %s): %w", connectionName, sanitizeDSN(dsn), err)
 		}
@@ -255,20 +391,150 @@ func (m *Manager) GetStore(connectionName string) (storage.MemoryStore, error) {
 		return nil, fmt.Errorf("unsupported database type '%s' for connection '%s'", conn.Database.Type, connectionName)
 	}
 
-	// Cache it and mark as owned by this manager
-	m.storesLock.Lock()
-	m.stores[connectionName] = store
-	m.ownedStores[connectionName] = true
-	m.storesLock.Unlock()
-
 	return store, nil
 }
 
+// OpenError returns the error from the last failed attempt to open the
+// connection's store, or nil if it opened (or has not been tried).
+func (m *Manager) OpenError(connectionName string) error {
+	m.storesLock.RLock()
+	defer m.storesLock.RUnlock()
+	return m.openErrors[connectionName]
+}
+
+// ConnectionStatus reports whether a connection's store is usable.
+type ConnectionStatus struct {
+	Name        string `json:"name"`
+	Enabled     bool   `json:"enabled"`
+	Default     bool   `json:"default"`
+	Backend     string `json:"backend,omitempty"`    // sqlite, postgresql
+	Opened      bool   `json:"opened"`               // store opened successfully
+	OpenError   string `json:"open_error,omitempty"` // why the store failed to open
+	MemoryCount int    `json:"memory_count"`         // memories in the store (when the ping succeeded)
+	PingOK      bool   `json:"ping_ok"`              // a one-row query succeeded
+	PingError   string `json:"ping_error,omitempty"` // why the ping failed
+	PingMillis  int64  `json:"ping_ms"`              // ping round-trip time
+}
+
+// Status opens every enabled connection (reusing cached stores) and pings it
+// with a one-row query, so a broken connection shows up here instead of
+// requests silently falling back to the default store. Disabled connections
+// are listed but not opened.
+func (m *Manager) Status(ctx context.Context) []ConnectionStatus {
+	statuses := make([]ConnectionStatus, 0, len(m.config.Connections))
+	for _, conn := range m.config.Connections {
+		status := ConnectionStatus{
+			Name:    conn.Name,
+			Enabled: conn.Enabled,
+			Default: conn.Name == m.config.DefaultConnection,
+			Backend: conn.Database.Type,
+		}
+		if conn.Enabled {
+			store, err := m.GetStore(conn.Name)
+			if err != nil {
+				status.OpenError = err.Error()
+			} else {
+				status.Opened = true
+				status.MemoryCount, status.PingMillis, err = PingStore(ctx, store)
+				if err != nil {
+					status.PingError = err.Error()
+				} else {
+					status.PingOK = true
+				}
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// PingStore runs a one-row List against store and returns the total memory
+// count and the round-trip time in milliseconds.
+func PingStore(ctx context.Context, store storage.MemoryStore) (int, int64, error) {
+	start := time.Now()
+	result, err := store.List(ctx, storage.ListOptions{Page: 1, Limit: 1})
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		return 0, elapsed, fmt.Errorf("failed to query database: %w", err)
+	}
+	return result.Total, elapsed, nil
+}
+
 // ListConnections returns all configured connections
 func (m *Manager) ListConnections() []Connection {
 	return m.config.Connections
 }
 
+// EnrichmentEnabled reports whether memories stored in the named connection
+// should be enriched; an empty name means the default connection. Unknown
+// connections report true so single-store setups are unaffected.
+func (m *Manager) EnrichmentEnabled(connectionName string) bool {
+	if connectionName == "" {
+		connectionName = m.config.DefaultConnection
+	}
+	for _, conn := range m.config.Connections {
+		if conn.Name == connectionName {
+			return conn.Enriches()
+		}
+	}
+	return true
+}
+
+// ReadOnly reports whether the named connection is configured read-only; an
+// empty name means the default connection. Unknown connections report false.
+func (m *Manager) ReadOnly(connectionName string) bool {
+	if connectionName == "" {
+		connectionName = m.config.DefaultConnection
+	}
+	for _, conn := range m.config.Connections {
+		if conn.Name == connectionName {
+			return conn.ReadOnly
+		}
+	}
+	return false
+}
+
+// IDPrefix returns the prefix of memory IDs generated for the named
+// connection: its id_prefix, or DefaultIDPrefix when it has none or is
+// unknown. An empty name means the default connection.
+func (m *Manager) IDPrefix(connectionName string) string {
+	if connectionName == "" {
+		connectionName = m.config.DefaultConnection
+	}
+	for _, conn := range m.config.Connections {
+		if conn.Name == connectionName && conn.IDPrefix != "" {
+			return conn.IDPrefix
+		}
+	}
+	return DefaultIDPrefix
+}
+
+// ConnectionForIDPrefix returns the name of the connection whose id_prefix
+// is prefix. DefaultIDPrefix is shared by every connection without one, so
+// it never names a connection.
+func (m *Manager) ConnectionForIDPrefix(prefix string) (string, bool) {
+	if prefix == DefaultIDPrefix {
+		return "", false
+	}
+	name := idPrefixOwner(m.config.Connections, prefix, "")
+	return name, name != ""
+}
+
+// idPrefixOwner returns the name of the connection in conns, other than
+// except, whose id_prefix is prefix, or "" when there is none or prefix is
+// empty or DefaultIDPrefix.
+func idPrefixOwner(conns []Connection, prefix, except string) string {
+	if prefix == "" || prefix == DefaultIDPrefix {
+		return ""
+	}
+	for _, conn := range conns {
+		if conn.IDPrefix == prefix && conn.Name != except {
+			return conn.Name
+		}
+	}
+	return ""
+}
+
 // GetDefaultConnection returns the default connection name
 func (m *Manager) GetDefaultConnection() string {
 	return m.config.DefaultConnection
@@ -280,6 +546,12 @@ func (m *Manager) AddConnection(ctx context.Context, conn Connection) error {
 	if conn.Name == "" {
 		return fmt.Errorf("connection name is required")
 	}
+	if err := conn.Validate(); err != nil {
+		return err
+	}
+	if other := idPrefixOwner(m.config.Connections, conn.IDPrefix, ""); other != "" {
+		return fmt.Errorf("id_prefix %q is already used by connection '%s'", conn.IDPrefix, other)
+	}
 
 	// Check if exists
 	for _, existing := range m.config.Connections {
@@ -306,6 +578,12 @@ func (m *Manager) UpdateConnection(ctx context.Context, name string, updatedConn
 	if name == "" {
 		return fmt.Errorf("connection name is required")
 	}
+	if err := updatedConn.Validate(); err != nil {
+		return err
+	}
+	if other := idPrefixOwner(m.config.Connections, updatedConn.IDPrefix, name); other != "" {
+		return fmt.Errorf("id_prefix %q is already used by connection '%s'", updatedConn.IDPrefix, other)
+	}
 
 	// Find and update connection
 	found := false
@@ -334,6 +612,7 @@ func (m *Manager) UpdateConnection(ctx context.Context, name string, updatedConn
 		delete(m.stores, name)
 		delete(m.ownedStores, name)
 	}
+	delete(m.openErrors, name)
 	m.storesLock.Unlock()
 
 	// Save config
@@ -362,6 +641,7 @@ func (m *Manager) DeleteConnection(ctx context.Context, name string) error {
 				delete(m.stores, name)
 				delete(m.ownedStores, name)
 			}
+			delete(m.openErrors, name)
 			m.storesLock.Unlock()
 			continue
 		}
@@ -376,6 +656,26 @@ func (m *Manager) DeleteConnection(ctx context.Context, name string) error {
 	return m.SaveConfig()
 }
 
+// DatabaseFiles returns the files of the named connection's SQLite
+// database, with its WAL and shared-memory files, resolved the way its
+// store was opened. PostgreSQL connections have none.
+func (m *Manager) DatabaseFiles(name string) ([]string, error) {
+	for _, conn := range m.config.Connections {
+		if conn.Name != name {
+			continue
+		}
+		if conn.Database.Type != "sqlite" || conn.Database.Path == "" || conn.Database.Path == ":memory:" {
+			return nil, nil
+		}
+		dbPath := conn.Database.Path
+		if !filepath.IsAbs(dbPath) && m.baseDir != "" {
+			dbPath = filepath.Join(m.baseDir, dbPath)
+		}
+		return []string{dbPath, dbPath + "-wal", dbPath + "-shm"}, nil
+	}
+	return nil, fmt.Errorf("connection '%s' not found", name)
+}
+
 // SetDefaultConnection sets the default connection
 func (m *Manager) SetDefaultConnection(ctx context.Context, name string) error {
 	// Verify connection exists
@@ -400,7 +700,10 @@ func (m *Manager) TestConnection(ctx context.Context, conn Connection) error {
 	switch conn.Database.Type {
 	case "sqlite":
 		// Test SQLite connection
-		store, err := sqlite.NewMemoryStore(conn.Database.Path)
+		m.storesLock.RLock()
+		opts := slices.Clone(m.sqliteOptions)
+		m.storesLock.RUnlock()
+		store, err := sqlite.NewMemoryStore(conn.Database.Path, opts...)
 		if err != nil {
 			return fmt.Errorf("failed to connect to SQLite: %w", err)
 		}
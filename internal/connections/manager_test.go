@@ -2,13 +2,18 @@ package connections
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
 )
 
 // newTestStore creates an in-memory SQLite store for testing.
@@ -862,3 +867,281 @@ func containsString(s, substr string) bool {
 	}
 	return false
 }
+
+// TestStatus_ReportsGoodAndBrokenConnections verifies that Status opens and
+// pings each enabled connection, records why a broken one failed to open, and
+// lists disabled connections without opening them.
+func TestStatus_ReportsGoodAndBrokenConnections(t *testing.T) {
+	dir := t.TempDir()
+	notADir := filepath.Join(dir, "file")
+	if err := os.WriteFile(notADir, []byte("x"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	config := &ConnectionsConfig{
+		DefaultConnection: "good",
+		Connections: []Connection{
+			{Name: "good", Enabled: true, Database: DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "good.db")}},
+			{Name: "broken", Enabled: true, Database: DatabaseConfig{Type: "sqlite", Path: filepath.Join(notADir, "broken.db")}},
+			{Name: "off", Enabled: false, Database: DatabaseConfig{Type: "sqlite", Path: filepath.Join(dir, "off.db")}},
+		},
+	}
+	manager, err := NewManager(createTestConfig(t, config))
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+	defer func() { _ = manager.Close() }()
+
+	statuses := manager.Status(context.Background())
+	if len(statuses) != 3 {
+		t.Fatalf("expected 3 statuses, got %d", len(statuses))
+	}
+
+	good, broken, off := statuses[0], statuses[1], statuses[2]
+	if !good.Default || !good.Opened || !good.PingOK || good.Backend != "sqlite" || good.OpenError != "" {
+		t.Errorf("unexpected status for good connection: %+v", good)
+	}
+	if broken.Opened || broken.PingOK || broken.OpenError == "" {
+		t.Errorf("expected broken connection to report an open error: %+v", broken)
+	}
+	if manager.OpenError("broken") == nil {
+		t.Error("expected OpenError to record the broken connection's failure")
+	}
+	if manager.OpenError("good") != nil {
+		t.Errorf("expected no open error for good connection, got %v", manager.OpenError("good"))
+	}
+	if off.Enabled || off.Opened || off.OpenError != "" {
+		t.Errorf("expected disabled connection to be listed but not opened: %+v", off)
+	}
+}
+
+// TestEnrichmentEnabled verifies that enrichment defaults to on when
+// enrichment_enabled is absent and can be turned off per connection.
+func TestEnrichmentEnabled(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "connections.json")
+	data := `{
+  "default_connection": "main",
+  "connections": [
+    {"name": "main", "enabled": true, "database": {"type": "sqlite", "path": ":memory:"}},
+    {"name": "logs", "enabled": true, "enrichment_enabled": false, "database": {"type": "sqlite", "path": ":memory:"}}
+  ]
+}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	manager, err := NewManager(configPath)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	if !manager.EnrichmentEnabled("main") {
+		t.Error("expected enrichment enabled for a connection without enrichment_enabled")
+	}
+	if !manager.EnrichmentEnabled("") {
+		t.Error("expected the default connection to be enriched")
+	}
+	if manager.EnrichmentEnabled("logs") {
+		t.Error("expected enrichment disabled for logs")
+	}
+	if !manager.EnrichmentEnabled("unknown") {
+		t.Error("expected unknown connections to report enrichment enabled")
+	}
+}
+
+// TestStateMachine verifies that a connection's state_machine is validated
+// on load and used by its store, and that other connections get the
+// manager's default.
+func TestStateMachine(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "connections.json")
+	data := `{
+  "default_connection": "main",
+  "connections": [
+    {"name": "main", "enabled": true, "database": {"type": "sqlite", "path": ":memory:"}},
+    {"name": "tickets", "enabled": true, "database": {"type": "sqlite", "path": ":memory:"},
+     "state_machine": {"states": ["open", "review", "closed"], "initial": ["open"],
+                       "transitions": {"open": ["review"], "review": ["open", "closed"]}}}
+  ]
+}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	manager, err := NewManager(configPath)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	defer func() { _ = manager.Close() }()
+
+	defaultMachine := &types.StateMachine{States: []string{"new"}, Initial: []string{"new"}}
+	manager.SetStateMachine(defaultMachine)
+
+	machineOf := func(name string) *types.StateMachine {
+		t.Helper()
+		store, err := manager.GetStore(name)
+		if err != nil {
+			t.Fatalf("GetStore(%s) failed: %v", name, err)
+		}
+		return store.(storage.StateMachineProvider).StateMachine()
+	}
+	if got := machineOf("tickets"); got == nil || !got.CanTransition("review", "closed") {
+		t.Errorf("tickets state machine = %+v, want the connection's own", got)
+	}
+	if got := machineOf("main"); got != defaultMachine {
+		t.Errorf("main state machine = %+v, want the manager default", got)
+	}
+
+	// A dangling state is rejected when the config is loaded.
+	data = `{"connections": [{"name": "bad", "database": {"type": "sqlite", "path": ":memory:"},
+  "state_machine": {"states": ["open"], "initial": ["open"], "transitions": {"open": ["qa"]}}}]}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if _, err := NewManager(configPath); err == nil || !strings.Contains(err.Error(), "connection 'bad'") {
+		t.Errorf("NewManager() error = %v, want a state machine error for connection 'bad'", err)
+	}
+}
+
+// TestIDPrefix verifies id_prefix lookups in both directions and that
+// invalid or shared prefixes are rejected.
+func TestIDPrefix(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "connections.json")
+	data := `{
+  "default_connection": "main",
+  "connections": [
+    {"name": "main", "enabled": true, "database": {"type": "sqlite", "path": ":memory:"}},
+    {"name": "crm", "enabled": true, "id_prefix": "acme-crm", "database": {"type": "sqlite", "path": ":memory:"}}
+  ]
+}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	manager, err := NewManager(configPath)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+
+	for name, want := range map[string]string{"main": DefaultIDPrefix, "": DefaultIDPrefix, "crm": "acme-crm", "unknown": DefaultIDPrefix} {
+		if got := manager.IDPrefix(name); got != want {
+			t.Errorf("IDPrefix(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if conn, ok := manager.ConnectionForIDPrefix("acme-crm"); !ok || conn != "crm" {
+		t.Errorf("ConnectionForIDPrefix(acme-crm) = %q, %v; want crm", conn, ok)
+	}
+	for _, prefix := range []string{DefaultIDPrefix, "other", ""} {
+		if conn, ok := manager.ConnectionForIDPrefix(prefix); ok {
+			t.Errorf("ConnectionForIDPrefix(%q) = %q, want no connection", prefix, conn)
+		}
+	}
+
+	manager.config.Settings.MaxConnections = 10
+	if err := manager.AddConnection(context.Background(), Connection{Name: "sales", IDPrefix: "acme-crm"}); err == nil {
+		t.Error("AddConnection() accepted an id_prefix already in use")
+	}
+	if err := manager.AddConnection(context.Background(), Connection{Name: "sales", IDPrefix: "acme:sales"}); err == nil {
+		t.Error("AddConnection() accepted an id_prefix containing ':'")
+	}
+	if err := manager.UpdateConnection(context.Background(), "crm", Connection{IDPrefix: "acme-crm"}); err != nil {
+		t.Errorf("UpdateConnection() rejected the connection's own id_prefix: %v", err)
+	}
+
+	data = `{"connections": [
+  {"name": "a", "id_prefix": "shared", "database": {"type": "sqlite", "path": ":memory:"}},
+  {"name": "b", "id_prefix": "shared", "database": {"type": "sqlite", "path": ":memory:"}}]}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	if _, err := NewManager(configPath); err == nil || !strings.Contains(err.Error(), "already used by connection 'a'") {
+		t.Errorf("NewManager() error = %v, want a shared id_prefix error", err)
+	}
+}
+
+// openManagerDB returns a manager with one SQLite connection, "main",
+// stored in a file, and the path of that file.
+func openManagerDB(t *testing.T) (*Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "connections.json")
+	data := `{"default_connection": "main", "connections": [
+  {"name": "main", "enabled": true, "database": {"type": "sqlite", "path": "main.db"}}]}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	manager, err := NewManager(configPath)
+	if err != nil {
+		t.Fatalf("NewManager failed: %v", err)
+	}
+	t.Cleanup(func() { _ = manager.Close() })
+	return manager, filepath.Join(dir, "main.db")
+}
+
+// TestSetSQLiteOptions_CompressesContent verifies that options set with
+// SetSQLiteOptions reach the SQLite stores the manager opens.
+func TestSetSQLiteOptions_CompressesContent(t *testing.T) {
+	manager, dbPath := openManagerDB(t)
+	manager.SetSQLiteOptions(sqlite.WithCompressionThreshold(64))
+
+	store, err := manager.GetStore("main")
+	if err != nil {
+		t.Fatalf("GetStore failed: %v", err)
+	}
+	content := strings.Repeat("the deploy is still running. ", 20)
+	ctx := context.Background()
+	if err := store.Store(ctx, &types.Memory{ID: "mem:main:large", Content: content, Source: "test"}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	var compressed bool
+	if err := db.QueryRow(`SELECT content_compressed FROM memories WHERE id = ?`, "mem:main:large").Scan(&compressed); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if !compressed {
+		t.Error("content was not compressed; SetSQLiteOptions did not reach the store")
+	}
+	got, err := store.Get(ctx, "mem:main:large")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Content != content {
+		t.Error("Get returned different content than was stored")
+	}
+}
+
+// TestSetSQLiteOptions_Pragmas verifies that the busy timeout, journal mode
+// and WAL autocheckpoint from config reach the SQLite stores the manager
+// opens.
+func TestSetSQLiteOptions_Pragmas(t *testing.T) {
+	manager, dbPath := openManagerDB(t)
+	manager.SetSQLiteOptions(sqlite.OptionsFromConfig(config.StorageConfig{
+		SQLiteBusyTimeoutMs:     1234,
+		SQLiteJournalMode:       "delete",
+		SQLiteWALAutocheckpoint: 500,
+	})...)
+	if _, err := manager.GetStore("main"); err != nil {
+		t.Fatalf("GetStore failed: %v", err)
+	}
+
+	// The default WAL mode is persisted in the database file, so a fresh
+	// connection reporting delete shows the configured mode was applied.
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("PRAGMA journal_mode failed: %v", err)
+	}
+	if mode != "delete" {
+		t.Errorf("journal_mode = %q, want delete", mode)
+	}
+
+	if err := manager.TestConnection(context.Background(), Connection{
+		Name: "probe", Database: DatabaseConfig{Type: "sqlite", Path: filepath.Join(t.TempDir(), "probe.db")},
+	}); err != nil {
+		t.Fatalf("TestConnection failed: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package connections
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/internal/storage/postgres"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+)
+
+// ResolveConfigPath finds connections.json: MEMENTO_CONNECTIONS_CONFIG
+// first, then config/connections.json next to the executable, then
+// relative to the working directory. It returns "" when none exists.
+func ResolveConfigPath() string {
+	if path := os.Getenv("MEMENTO_CONNECTIONS_CONFIG"); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		slog.Warn("MEMENTO_CONNECTIONS_CONFIG does not exist, continuing search", "path", path)
+	}
+	if execPath, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(execPath), "config", "connections.json")
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	if _, err := os.Stat("config/connections.json"); err == nil {
+		return "config/connections.json"
+	}
+	return ""
+}
+
+// OpenStore opens the store of the named connection for command-line tools
+// such as memento-import and memento-export; an empty name means the
+// default connection. When connections.json is found (see
+// ResolveConfigPath) the connection comes from it, with the storage
+// settings of cfg applied; otherwise the single-store database under
+// MEMENTO_DATA_PATH is used, which is an error when the connection was
+// named explicitly. That database is created if create is set and must
+// exist otherwise.
+//
+// It also returns the prefix of the connection's memory IDs and a function
+// that closes the store.
+func OpenStore(cfg *config.Config, name string, explicit, create bool) (storage.MemoryStore, string, func(), error) {
+	if path := ResolveConfigPath(); path != "" {
+		manager, err := NewManager(path)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to load connections config from %s: %w", path, err)
+		}
+		manager.SetSQLiteOptions(sqlite.OptionsFromConfig(cfg.Storage)...)
+		manager.SetPostgresOptions(postgres.OptionsFromConfig(cfg.Storage, cfg.LLM.EmbeddingDimension)...)
+		manager.SetStateMachine(cfg.Storage.StateMachine)
+		manager.SetDedupNormalization(cfg.Storage.DedupNormalization)
+		store, err := manager.GetStore(name)
+		if err != nil {
+			_ = manager.Close()
+			return nil, "", nil, fmt.Errorf("failed to open connection %q: %w", name, err)
+		}
+		return store, manager.IDPrefix(name), func() { _ = manager.Close() }, nil
+	}
+
+	if explicit {
+		return nil, "", nil, fmt.Errorf("connection %q requested but no connections.json was found", name)
+	}
+	dbPath := filepath.Join(cfg.Storage.DataPath, "memento.db")
+	if create {
+		if err := os.MkdirAll(cfg.Storage.DataPath, 0o700); err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create data directory %q: %w", cfg.Storage.DataPath, err)
+		}
+	} else if _, err := os.Stat(dbPath); err != nil {
+		return nil, "", nil, fmt.Errorf("no database at %s (set MEMENTO_DATA_PATH): %w", dbPath, err)
+	}
+	store, err := sqlite.NewMemoryStore(dbPath, sqlite.OptionsFromConfig(cfg.Storage)...)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to open database at %s: %w", dbPath, err)
+	}
+	return store, DefaultIDPrefix, func() { _ = store.Close() }, nil
+}
@@ -0,0 +1,86 @@
+package connections
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func openStoreConfig(dataPath string) *config.Config {
+	return &config.Config{Storage: config.StorageConfig{
+		DataPath:                dataPath,
+		SQLiteBusyTimeoutMs:     5000,
+		SQLiteJournalMode:       "WAL",
+		SQLiteWALAutocheckpoint: 1000,
+	}}
+}
+
+// TestOpenStore_Connection verifies that OpenStore opens the named
+// connection from connections.json and returns its ID prefix.
+func TestOpenStore_Connection(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "connections.json")
+	data := `{"default_connection": "main", "connections": [
+  {"name": "main", "enabled": true, "database": {"type": "sqlite", "path": "main.db"}},
+  {"name": "acme", "enabled": true, "id_prefix": "acme", "database": {"type": "sqlite", "path": "acme.db"}}]}`
+	if err := os.WriteFile(configPath, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Setenv("MEMENTO_CONNECTIONS_CONFIG", configPath)
+
+	store, prefix, closeStore, err := OpenStore(openStoreConfig(filepath.Join(dir, "data")), "acme", true, false)
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	defer closeStore()
+	if prefix != "acme" {
+		t.Errorf("prefix = %q, want acme", prefix)
+	}
+	if err := store.Store(context.Background(), &types.Memory{ID: "acme:acme:one", Content: "kestrel", Source: "test"}); err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "acme.db")); err != nil {
+		t.Errorf("expected the connection's database: %v", err)
+	}
+
+	if _, _, _, err := OpenStore(openStoreConfig(dir), "missing", true, false); err == nil {
+		t.Error("expected an error for an unknown connection")
+	}
+}
+
+// TestOpenStore_SingleStore verifies the fallback to memento.db under the
+// data path when no connections.json is found.
+func TestOpenStore_SingleStore(t *testing.T) {
+	t.Setenv("MEMENTO_CONNECTIONS_CONFIG", "")
+	dataPath := filepath.Join(t.TempDir(), "data")
+	cfg := openStoreConfig(dataPath)
+
+	if _, _, _, err := OpenStore(cfg, "work", true, true); err == nil {
+		t.Error("expected an error for a named connection without connections.json")
+	}
+	if _, _, _, err := OpenStore(cfg, "", false, false); err == nil {
+		t.Error("expected an error for a missing database when create is not set")
+	}
+
+	_, prefix, closeStore, err := OpenStore(cfg, "", false, true)
+	if err != nil {
+		t.Fatalf("OpenStore failed: %v", err)
+	}
+	closeStore()
+	if prefix != DefaultIDPrefix {
+		t.Errorf("prefix = %q, want %q", prefix, DefaultIDPrefix)
+	}
+	if _, err := os.Stat(filepath.Join(dataPath, "memento.db")); err != nil {
+		t.Errorf("expected memento.db to be created: %v", err)
+	}
+
+	_, _, closeStore, err = OpenStore(cfg, "", false, false)
+	if err != nil {
+		t.Fatalf("OpenStore of the existing database failed: %v", err)
+	}
+	closeStore()
+}
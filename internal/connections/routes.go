@@ -0,0 +1,299 @@
+package connections
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// routesSchema creates the memory_routes table mapping each memory ID to the
+// connection that stores it, plus a small key/value table recording one-off
+// migrations such as the backfill.
+const routesSchema = `
+CREATE TABLE IF NOT EXISTS memory_routes (
+    memory_id  TEXT PRIMARY KEY,
+    connection TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_memory_routes_connection ON memory_routes(connection);
+
+CREATE TABLE IF NOT EXISTS memory_routes_meta (
+    key   TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+`
+
+// backfillMetaKey marks that existing memories have been copied into
+// memory_routes, so the full scan runs only once.
+const backfillMetaKey = "backfilled_at"
+
+// RouteTable persists which connection owns each memory ID. It lets the MCP
+// server route opaque memory IDs, which no longer encode the connection name,
+// and keeps routing correct when a connection is renamed (see Reconcile).
+type RouteTable struct {
+	db *sql.DB
+}
+
+// OpenRouteTable opens (creating if needed) the SQLite route table at path.
+func OpenRouteTable(path string) (*RouteTable, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open route table: %w", err)
+	}
+	// A single connection serialises writers and keeps ":memory:" databases
+	// from being split across pool connections.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(routesSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create route table: %w", err)
+	}
+	return &RouteTable{db: db}, nil
+}
+
+// Set records that memoryID is stored in connection, replacing any previous
+// route for that ID.
+func (r *RouteTable) Set(ctx context.Context, memoryID, connection string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO memory_routes (memory_id, connection, created_at) VALUES (?, ?, ?)
+		ON CONFLICT(memory_id) DO UPDATE SET connection = excluded.connection`,
+		memoryID, connection, time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record route for %s: %w", memoryID, err)
+	}
+	return nil
+}
+
+// Lookup returns the connection that stores memoryID, or storage.ErrNotFound
+// when no route has been recorded.
+func (r *RouteTable) Lookup(ctx context.Context, memoryID string) (string, error) {
+	var connection string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT connection FROM memory_routes WHERE memory_id = ?`, memoryID,
+	).Scan(&connection)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", storage.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up route for %s: %w", memoryID, err)
+	}
+	return connection, nil
+}
+
+// RenameConnection re-points every route from oldName to newName and returns
+// how many routes changed.
+func (r *RouteTable) RenameConnection(ctx context.Context, oldName, newName string) (int, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE memory_routes SET connection = ? WHERE connection = ?`, newName, oldName,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename routes from %s to %s: %w", oldName, newName, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// reconcileSample is how many memories routed to a connection that is no
+// longer configured Reconcile looks up to find where they went.
+const reconcileSample = 20
+
+// Reconcile follows connections renamed in connections.json. Routes still
+// name the old connection, so for every routed name the manager no longer
+// knows, it looks up a few of that name's memories in the enabled
+// connections; the first connection that stores one is taken to be the
+// renamed connection and all of the name's routes move to it (see
+// RenameConnection). Names whose memories are found nowhere, as for a
+// removed connection, keep their routes. It returns how many routes changed.
+func (r *RouteTable) Reconcile(ctx context.Context, m *Manager) (int, error) {
+	known := make(map[string]bool)
+	for _, conn := range m.ListConnections() {
+		known[conn.Name] = true
+	}
+	stale, err := r.staleConnections(ctx, known)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, oldName := range stale {
+		newName, err := r.findConnection(ctx, m, oldName)
+		if err != nil {
+			return changed, err
+		}
+		if newName == "" {
+			continue
+		}
+		n, err := r.RenameConnection(ctx, oldName, newName)
+		changed += n
+		if err != nil {
+			return changed, err
+		}
+	}
+	return changed, nil
+}
+
+// staleConnections returns the routed connection names not in known.
+func (r *RouteTable) staleConnections(ctx context.Context, known map[string]bool) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT connection FROM memory_routes`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routed connections: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var stale []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list routed connections: %w", err)
+		}
+		if !known[name] {
+			stale = append(stale, name)
+		}
+	}
+	return stale, rows.Err()
+}
+
+// findConnection returns the enabled connection that stores one of the
+// first memories routed to oldName, or "" if none does.
+func (r *RouteTable) findConnection(ctx context.Context, m *Manager, oldName string) (string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT memory_id FROM memory_routes WHERE connection = ? ORDER BY created_at LIMIT ?`, oldName, reconcileSample,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to list routes to %s: %w", oldName, err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return "", fmt.Errorf("failed to list routes to %s: %w", oldName, err)
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to list routes to %s: %w", oldName, err)
+	}
+
+	for _, conn := range m.ListConnections() {
+		if !conn.Enabled {
+			continue
+		}
+		store, err := m.GetStore(conn.Name)
+		if err != nil {
+			continue
+		}
+		for _, id := range ids {
+			if _, err := store.Get(ctx, id); err == nil {
+				return conn.Name, nil
+			} else if !errors.Is(err, storage.ErrNotFound) {
+				return "", fmt.Errorf("failed to look up %s in %s: %w", id, conn.Name, err)
+			}
+		}
+	}
+	return "", nil
+}
+
+// DeleteConnection removes every route to connection, as when its memories
+// are purged, and returns how many routes were removed.
+func (r *RouteTable) DeleteConnection(ctx context.Context, connection string) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM memory_routes WHERE connection = ?`, connection)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete routes to %s: %w", connection, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// Backfill records a route for every memory already stored in the manager's
+// enabled connections, including soft-deleted and expired ones, so memories
+// created under the deterministic mem:<connection>:<hash> scheme keep routing
+// after opaque IDs are turned on. Existing routes are left untouched. The scan
+// runs once; later calls return 0 without reading the stores.
+func (r *RouteTable) Backfill(ctx context.Context, m *Manager) (int, error) {
+	var done string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT value FROM memory_routes_meta WHERE key = ?`, backfillMetaKey,
+	).Scan(&done)
+	if err == nil {
+		return 0, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("failed to read route backfill state: %w", err)
+	}
+
+	added := 0
+	for _, conn := range m.ListConnections() {
+		if !conn.Enabled {
+			continue
+		}
+		store, err := m.GetStore(conn.Name)
+		if err != nil {
+			return added, fmt.Errorf("backfill routes for %s: %w", conn.Name, err)
+		}
+		n, err := r.backfillStore(ctx, store, conn.Name)
+		added += n
+		if err != nil {
+			return added, fmt.Errorf("backfill routes for %s: %w", conn.Name, err)
+		}
+	}
+
+	if _, err := r.db.ExecContext(ctx,
+		`INSERT INTO memory_routes_meta (key, value) VALUES (?, ?)`,
+		backfillMetaKey, time.Now().UTC().Format(time.RFC3339),
+	); err != nil {
+		return added, fmt.Errorf("failed to record route backfill: %w", err)
+	}
+	return added, nil
+}
+
+// backfillStore pages through every memory in store and inserts a route to
+// connection for each ID that has none yet.
+func (r *RouteTable) backfillStore(ctx context.Context, store storage.MemoryStore, connection string) (int, error) {
+	added := 0
+	now := time.Now().UTC()
+	for page := 1; ; page++ {
+		result, err := store.List(ctx, storage.ListOptions{
+			Page:           page,
+			Limit:          100,
+			SortBy:         "created_at",
+			SortOrder:      "asc",
+			IncludeDeleted: true,
+			IncludeExpired: true,
+		})
+		if err != nil {
+			return added, err
+		}
+		for _, mem := range result.Items {
+			res, err := r.db.ExecContext(ctx,
+				`INSERT OR IGNORE INTO memory_routes (memory_id, connection, created_at) VALUES (?, ?, ?)`,
+				mem.ID, connection, now,
+			)
+			if err != nil {
+				return added, err
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				added++
+			}
+		}
+		if !result.HasMore {
+			return added, nil
+		}
+	}
+}
+
+// Close closes the underlying database.
+func (r *RouteTable) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,138 @@
+package connections
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// newTestRouteTable opens a route table in a temporary directory.
+func newTestRouteTable(t *testing.T) *RouteTable {
+	t.Helper()
+	routes, err := OpenRouteTable(filepath.Join(t.TempDir(), "memory_routes.db"))
+	if err != nil {
+		t.Fatalf("OpenRouteTable() failed: %v", err)
+	}
+	t.Cleanup(func() { _ = routes.Close() })
+	return routes
+}
+
+func TestRouteTable_SetAndLookup(t *testing.T) {
+	routes := newTestRouteTable(t)
+	ctx := context.Background()
+
+	if _, err := routes.Lookup(ctx, "mem:missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Lookup(missing) error = %v, want ErrNotFound", err)
+	}
+
+	if err := routes.Set(ctx, "mem:abc", "work"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if err := routes.Set(ctx, "mem:abc", "personal"); err != nil {
+		t.Fatalf("Set() overwrite failed: %v", err)
+	}
+	got, err := routes.Lookup(ctx, "mem:abc")
+	if err != nil {
+		t.Fatalf("Lookup() failed: %v", err)
+	}
+	if got != "personal" {
+		t.Errorf("Lookup() = %q, want personal", got)
+	}
+}
+
+func TestRouteTable_RenameConnection(t *testing.T) {
+	routes := newTestRouteTable(t)
+	ctx := context.Background()
+	for _, id := range []string{"mem:a", "mem:b"} {
+		if err := routes.Set(ctx, id, "old"); err != nil {
+			t.Fatalf("Set() failed: %v", err)
+		}
+	}
+	if err := routes.Set(ctx, "mem:c", "other"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	n, err := routes.RenameConnection(ctx, "old", "new")
+	if err != nil {
+		t.Fatalf("RenameConnection() failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("RenameConnection() = %d, want 2", n)
+	}
+	if got, _ := routes.Lookup(ctx, "mem:a"); got != "new" {
+		t.Errorf("Lookup(mem:a) = %q, want new", got)
+	}
+	if got, _ := routes.Lookup(ctx, "mem:c"); got != "other" {
+		t.Errorf("Lookup(mem:c) = %q, want other", got)
+	}
+}
+
+func TestRouteTable_DeleteConnection(t *testing.T) {
+	routes := newTestRouteTable(t)
+	ctx := context.Background()
+	for id, conn := range map[string]string{"mem:a": "purged", "mem:b": "purged", "mem:c": "other"} {
+		if err := routes.Set(ctx, id, conn); err != nil {
+			t.Fatalf("Set() failed: %v", err)
+		}
+	}
+
+	n, err := routes.DeleteConnection(ctx, "purged")
+	if err != nil {
+		t.Fatalf("DeleteConnection() failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("DeleteConnection() = %d, want 2", n)
+	}
+	if _, err := routes.Lookup(ctx, "mem:a"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Lookup(mem:a) error = %v, want ErrNotFound", err)
+	}
+	if got, _ := routes.Lookup(ctx, "mem:c"); got != "other" {
+		t.Errorf("Lookup(mem:c) = %q, want other", got)
+	}
+}
+
+// TestRouteTable_Backfill verifies that existing memories, including
+// soft-deleted ones, get routes once and that later calls skip the scan.
+func TestRouteTable_Backfill(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	for _, id := range []string{"mem:work:1111", "mem:work:2222", "mem:work:3333"} {
+		if err := store.Store(ctx, &types.Memory{ID: id, Content: "content " + id, Source: "test"}); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+	if err := store.Delete(ctx, "mem:work:3333"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+
+	manager := NewManagerWithStore(store, "work")
+	routes := newTestRouteTable(t)
+
+	n, err := routes.Backfill(ctx, manager)
+	if err != nil {
+		t.Fatalf("Backfill() failed: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Backfill() = %d, want 3", n)
+	}
+	for _, id := range []string{"mem:work:1111", "mem:work:3333"} {
+		if got, err := routes.Lookup(ctx, id); err != nil || got != "work" {
+			t.Errorf("Lookup(%s) = %q, %v; want work", id, got, err)
+		}
+	}
+
+	if err := store.Store(ctx, &types.Memory{ID: "mem:work:4444", Content: "later", Source: "test"}); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	n, err = routes.Backfill(ctx, manager)
+	if err != nil {
+		t.Fatalf("second Backfill() failed: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("second Backfill() = %d, want 0", n)
+	}
+}
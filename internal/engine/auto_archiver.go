@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// startAutoArchiver launches a goroutine that applies the AutoArchive policy
+// every AutoArchive.Interval until ctx is cancelled. Like the expiry sweeper it
+// runs once immediately and is tracked by workerWaitGroup.
+func (e *MemoryEngine) startAutoArchiver(ctx context.Context) {
+	interval := e.config.AutoArchive.Interval
+	if interval <= 0 {
+		return
+	}
+	if _, ok := e.memoryStore.(storage.StaleMemoryArchiver); !ok {
+		slog.Warn("auto-archival disabled (store does not support it)")
+		return
+	}
+
+	e.workerWaitGroup.Add(1)
+	go func() {
+		defer e.workerWaitGroup.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			e.runAutoArchive(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runAutoArchive applies the policy once and logs the outcome.
+func (e *MemoryEngine) runAutoArchive(ctx context.Context) {
+	policy := e.config.AutoArchive
+	ids, err := e.ArchiveStaleMemories(ctx, policy, policy.DryRun)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("auto-archive failed", "error", err)
+		}
+		return
+	}
+	switch {
+	case policy.DryRun:
+		slog.Info("auto-archive dry run: stale memories would be archived", "count", len(ids), "ids", ids)
+	case len(ids) > 0:
+		slog.Info("auto-archive archived stale memories", "count", len(ids))
+	}
+}
+
+// ArchiveStaleMemories archives every memory that policy considers stale and
+// returns their IDs. With dryRun it only returns the candidates. Archived
+// memories drop out of search but remain available by ID and via list
+// filters on the archived state.
+func (e *MemoryEngine) ArchiveStaleMemories(ctx context.Context, policy AutoArchivePolicy, dryRun bool) ([]string, error) {
+	archiver, ok := e.memoryStore.(storage.StaleMemoryArchiver)
+	if !ok {
+		return nil, fmt.Errorf("store does not support auto-archival")
+	}
+
+	ids, err := archiver.FindStaleMemories(ctx, storage.StaleCriteria{
+		MaxDecayScore:  policy.MaxDecayScore,
+		AccessedBefore: time.Now().Add(-policy.StaleAfter),
+		MaxAccessCount: policy.MaxAccessCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dryRun || len(ids) == 0 {
+		return ids, nil
+	}
+
+	if _, err := archiver.ArchiveMemories(ctx, ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AutoArchivePolicyFromConfig builds the auto-archival policy from the
+// MEMENTO_AUTO_ARCHIVE* settings. It returns a zero (disabled) policy when
+// auto-archival is off, and an error when the interval cannot be parsed.
+func AutoArchivePolicyFromConfig(cfg config.StorageConfig) (AutoArchivePolicy, error) {
+	if !cfg.AutoArchive {
+		return AutoArchivePolicy{}, nil
+	}
+	interval, err := time.ParseDuration(cfg.AutoArchiveInterval)
+	if err != nil || interval <= 0 {
+		return AutoArchivePolicy{}, fmt.Errorf("invalid MEMENTO_AUTO_ARCHIVE_INTERVAL %q: must be a positive duration", cfg.AutoArchiveInterval)
+	}
+	if cfg.AutoArchiveStaleDays < 0 {
+		return AutoArchivePolicy{}, fmt.Errorf("invalid MEMENTO_AUTO_ARCHIVE_STALE_DAYS %d: must be non-negative", cfg.AutoArchiveStaleDays)
+	}
+	return AutoArchivePolicy{
+		Interval:       interval,
+		MaxDecayScore:  cfg.AutoArchiveMaxDecayScore,
+		StaleAfter:     time.Duration(cfg.AutoArchiveStaleDays) * 24 * time.Hour,
+		MaxAccessCount: cfg.AutoArchiveMaxAccessCount,
+		DryRun:         cfg.AutoArchiveDryRun,
+	}, nil
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// TestEngine_ArchiveStaleMemories checks that only memories matching every
+// criterion are archived, that a dry run changes nothing, and that archived
+// memories leave search but stay retrievable.
+func TestEngine_ArchiveStaleMemories(t *testing.T) {
+	store := createTestStore(t)
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+
+	old := time.Now().Add(-200 * 24 * time.Hour)
+	recent := time.Now().Add(-time.Hour)
+	for _, m := range []*types.Memory{
+		{ID: "mem:test:stale", Content: "stale rollout notes", DecayScore: 0.05, LastAccessedAt: &old},
+		{ID: "mem:test:pinned", Content: "pinned rollout notes", DecayScore: 0.05, LastAccessedAt: &old, Tags: []string{types.PinnedTag}},
+		{ID: "mem:test:relevant", Content: "relevant rollout notes", DecayScore: 0.8, LastAccessedAt: &old},
+		{ID: "mem:test:recent", Content: "recent rollout notes", DecayScore: 0.05, LastAccessedAt: &recent},
+		{ID: "mem:test:popular", Content: "popular rollout notes", DecayScore: 0.05, LastAccessedAt: &old, AccessCount: 50},
+	} {
+		m.Source = "test"
+		m.Status = types.StatusEnriched
+		m.CreatedAt = old
+		if err := store.Store(ctx, m); err != nil {
+			t.Fatalf("Store(%s) failed: %v", m.ID, err)
+		}
+	}
+
+	eng, err := NewMemoryEngine(store, DefaultConfig(), nil)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	policy := AutoArchivePolicy{MaxDecayScore: 0.1, StaleAfter: 90 * 24 * time.Hour, MaxAccessCount: 3}
+
+	ids, err := eng.ArchiveStaleMemories(ctx, policy, true)
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "mem:test:stale" {
+		t.Fatalf("dry run candidates = %v, want [mem:test:stale]", ids)
+	}
+	if m, _ := store.Get(ctx, "mem:test:stale"); m.State == types.StateArchived {
+		t.Fatal("dry run archived a memory")
+	}
+
+	if ids, err = eng.ArchiveStaleMemories(ctx, policy, false); err != nil || len(ids) != 1 {
+		t.Fatalf("ArchiveStaleMemories() = %v, %v; want one archived memory", ids, err)
+	}
+	m, err := store.Get(ctx, "mem:test:stale")
+	if err != nil {
+		t.Fatalf("archived memory is no longer retrievable: %v", err)
+	}
+	if m.State != types.StateArchived {
+		t.Errorf("state = %q, want %q", m.State, types.StateArchived)
+	}
+
+	search := store.(storage.SearchProvider)
+	result, err := search.FullTextSearch(ctx, storage.SearchOptions{Query: "rollout", Limit: 10})
+	if err != nil {
+		t.Fatalf("FullTextSearch() failed: %v", err)
+	}
+	for _, item := range result.Items {
+		if item.ID == "mem:test:stale" {
+			t.Error("archived memory returned by default search")
+		}
+	}
+	result, err = search.FullTextSearch(ctx, storage.SearchOptions{Query: "stale", Limit: 10, IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("FullTextSearch(IncludeArchived) failed: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Errorf("IncludeArchived search returned %d memories, want 1", len(result.Items))
+	}
+}
+
+func TestAutoArchivePolicyFromConfig(t *testing.T) {
+	policy, err := AutoArchivePolicyFromConfig(config.StorageConfig{AutoArchiveInterval: "bogus"})
+	if err != nil || policy.Interval != 0 {
+		t.Fatalf("disabled policy = %+v, %v; want zero policy", policy, err)
+	}
+
+	cfg := config.StorageConfig{
+		AutoArchive:               true,
+		AutoArchiveInterval:       "12h",
+		AutoArchiveMaxDecayScore:  0.2,
+		AutoArchiveStaleDays:      30,
+		AutoArchiveMaxAccessCount: -1,
+		AutoArchiveDryRun:         true,
+	}
+	policy, err = AutoArchivePolicyFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("AutoArchivePolicyFromConfig() failed: %v", err)
+	}
+	want := AutoArchivePolicy{Interval: 12 * time.Hour, MaxDecayScore: 0.2, StaleAfter: 30 * 24 * time.Hour, MaxAccessCount: -1, DryRun: true}
+	if policy != want {
+		t.Errorf("policy = %+v, want %+v", policy, want)
+	}
+
+	cfg.AutoArchiveInterval = "soon"
+	if _, err := AutoArchivePolicyFromConfig(cfg); err == nil {
+		t.Error("AutoArchivePolicyFromConfig() accepted an invalid interval")
+	}
+}
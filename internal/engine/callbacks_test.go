@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -104,6 +105,33 @@ func TestOnEnrichmentComplete_FiresAfterEnrichment(t *testing.T) {
 	}
 }
 
+func TestOnEnrichmentFailed_FiresWhenRetriesExhausted(t *testing.T) {
+	eng := newTestEngine(t)
+	eng.config.MaxRetries = 0
+
+	failed := make(chan string, 1)
+	eng.SetOnEnrichmentFailed(func(memoryID string) {
+		failed <- memoryID
+	})
+	eng.SetOnEnrichmentComplete(func(memoryID string) {
+		t.Errorf("onEnrichmentComplete fired for failed memory %s", memoryID)
+	})
+
+	ctx := context.Background()
+	require.NoError(t, eng.Start(ctx))
+	defer func() { _ = eng.Shutdown(ctx) }()
+
+	// The memory does not exist, so the worker cannot mark it processing.
+	require.True(t, eng.QueueEnrichmentForMemory("mem:general:missing", "content"))
+
+	select {
+	case id := <-failed:
+		assert.Equal(t, "mem:general:missing", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout: onEnrichmentFailed callback never fired")
+	}
+}
+
 func TestAllCallbacks_FireInOrder(t *testing.T) {
 	eng := newTestEngine(t)
 
@@ -163,6 +191,106 @@ func TestNoCallbacks_DoesNotPanic(t *testing.T) {
 	assert.Equal(t, "enriched", string(got.Status))
 }
 
+// storeWithRelationship writes a memory carrying a married_to relationship in
+// its metadata, which the contradiction detector indexes.
+func storeWithRelationship(t *testing.T, eng *MemoryEngine, id, toID string) {
+	t.Helper()
+	now := time.Now()
+	require.NoError(t, eng.memoryStore.Store(context.Background(), &types.Memory{
+		ID:        id,
+		Content:   "relationship memory " + id,
+		Status:    types.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Metadata: map[string]interface{}{
+			"relationships": []interface{}{
+				map[string]interface{}{"from_id": "person:alice", "to_id": toID, "type": types.RelMarriedTo},
+			},
+		},
+	}))
+}
+
+func TestOnContradictionDetected_FiresWhenEnabled(t *testing.T) {
+	eng := newTestEngine(t)
+	eng.config.DetectContradictions = true
+
+	// Startup recovery may enrich the same memory more than once, so sends
+	// must not block the worker.
+	received := make(chan ContradictionEvent, 1)
+	eng.SetOnContradictionDetected(func(event ContradictionEvent) {
+		if event.MemoryID != "mem:general:second" {
+			return
+		}
+		select {
+		case received <- event:
+		default:
+		}
+	})
+
+	ctx := context.Background()
+	storeWithRelationship(t, eng, "mem:general:first", "person:bob")
+	storeWithRelationship(t, eng, "mem:general:second", "person:carol")
+
+	require.NoError(t, eng.Start(ctx))
+	defer func() { _ = eng.Shutdown(ctx) }()
+
+	require.True(t, eng.QueueEnrichmentForMemory("mem:general:second", "relationship memory mem:general:second"))
+
+	select {
+	case evt := <-received:
+		assert.Equal(t, "mem:general:second", evt.MemoryID)
+		require.NotEmpty(t, evt.Contradictions)
+		assert.Equal(t, ContradictionTypeConflictingRelationship, evt.Contradictions[0].Type)
+		assert.ElementsMatch(t, []string{"mem:general:first", "mem:general:second"}, evt.Contradictions[0].MemoryIDs)
+		assert.NotEmpty(t, evt.Summary)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout: onContradictionDetected callback never fired")
+	}
+}
+
+func TestOnContradictionDetected_DisabledByDefault(t *testing.T) {
+	eng := newTestEngine(t)
+
+	fired := make(chan ContradictionEvent, 1)
+	eng.SetOnContradictionDetected(func(event ContradictionEvent) {
+		select {
+		case fired <- event:
+		default:
+		}
+	})
+	completed := make(chan string, 1)
+	eng.SetOnEnrichmentComplete(func(memoryID string) {
+		if memoryID != "mem:general:second" {
+			return
+		}
+		select {
+		case completed <- memoryID:
+		default:
+		}
+	})
+
+	ctx := context.Background()
+	storeWithRelationship(t, eng, "mem:general:first", "person:bob")
+	storeWithRelationship(t, eng, "mem:general:second", "person:carol")
+
+	require.NoError(t, eng.Start(ctx))
+	defer func() { _ = eng.Shutdown(ctx) }()
+
+	require.True(t, eng.QueueEnrichmentForMemory("mem:general:second", "relationship memory mem:general:second"))
+
+	select {
+	case <-completed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout: enrichment never completed")
+	}
+
+	select {
+	case evt := <-fired:
+		t.Fatalf("contradiction callback fired while disabled: %+v", evt)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
 func init() {
 	// Suppress noisy log output during tests
 	_ = os.Setenv("MEMENTO_DATA_PATH", os.TempDir())
@@ -24,6 +24,11 @@ const (
 	// ContradictionTypeTemporalImpossibility indicates temporal ordering violations
 	// Example: Event A is claimed to happen before Event B, but Event A's timestamp is later
 	ContradictionTypeTemporalImpossibility ContradictionType = "temporal_impossibility"
+
+	// ContradictionTypeSemantic indicates two memories whose content cannot both
+	// be true, as judged by an LLM (see DetectSemanticContradictions)
+	// Example: "The API uses port 8080" vs "The API listens on port 9090"
+	ContradictionTypeSemantic ContradictionType = "semantic"
 )
 
 // Contradiction represents a detected structural contradiction in the memory graph
@@ -41,6 +46,33 @@ type Contradiction struct {
 	Confidence float64 `json:"confidence"`
 }
 
+// ContradictionEvent is emitted by the enrichment worker when a newly enriched
+// memory is involved in one or more contradictions.
+type ContradictionEvent struct {
+	// MemoryID is the newly enriched memory that triggered detection.
+	MemoryID string `json:"memory_id"`
+
+	// Summary is a one-line human-readable description suitable for display.
+	Summary string `json:"summary"`
+
+	// Contradictions lists every contradiction involving MemoryID.
+	Contradictions []Contradiction `json:"contradictions"`
+}
+
+// NewContradictionEvent builds a ContradictionEvent for memoryID, deriving the
+// summary from the detected contradictions.
+func NewContradictionEvent(memoryID string, contradictions []Contradiction) ContradictionEvent {
+	summary := fmt.Sprintf("Memory %s contradicts %d existing finding(s)", memoryID, len(contradictions))
+	if len(contradictions) == 1 {
+		summary = fmt.Sprintf("Memory %s: %s", memoryID, contradictions[0].Description)
+	}
+	return ContradictionEvent{
+		MemoryID:       memoryID,
+		Summary:        summary,
+		Contradictions: contradictions,
+	}
+}
+
 // ContradictionDetector uses deterministic graph algorithms to detect structural contradictions
 type ContradictionDetector struct {
 	store storage.MemoryStore
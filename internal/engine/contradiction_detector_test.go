@@ -125,11 +125,23 @@ func (m *mockContradictionStore) UpdateDecayScores(_ context.Context) (int, erro
 	return 0, nil
 }
 
+func (m *mockContradictionStore) SetEmbedding(_ context.Context, _ string, _ []float64, _ string) error {
+	return nil
+}
+
+func (m *mockContradictionStore) GetEmbedding(_ context.Context, _ string) ([]float64, string, error) {
+	return nil, "", storage.ErrNotFound
+}
+
+func (m *mockContradictionStore) ExpireMemories(_ context.Context, _ time.Time) (int, error) {
+	return 0, nil
+}
+
 func (m *mockContradictionStore) GetRelatedMemories(_ context.Context, memoryID string) ([]string, error) {
 	return []string{}, nil
 }
 
-func (m *mockContradictionStore) Traverse(_ context.Context, _ string, _ int, _ int) ([]storage.TraversalResult, error) {
+func (m *mockContradictionStore) Traverse(_ context.Context, _ string, _ storage.TraversalOptions) ([]storage.TraversalResult, error) {
 	return nil, nil
 }
 
@@ -137,6 +149,10 @@ func (m *mockContradictionStore) GetMemoryEntities(_ context.Context, _ string)
 	return nil, nil
 }
 
+func (m *mockContradictionStore) ListEntities(_ context.Context, _ storage.EntityListOptions) (*storage.PaginatedResult[types.Entity], error) {
+	return &storage.PaginatedResult[types.Entity]{}, nil
+}
+
 func (m *mockContradictionStore) Restore(_ context.Context, _ string) error { return nil }
 
 func (m *mockContradictionStore) GetEvolutionChain(_ context.Context, _ string) ([]*types.Memory, error) {
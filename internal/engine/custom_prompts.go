@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"errors"
+	"log/slog"
+	"sync/atomic"
+)
+
+// CustomPromptMaxFailures is how many responses in a row to a custom
+// extraction prompt may fail to parse before enrichment gives up on it and
+// returns to the built-in prompt until restart.
+const CustomPromptMaxFailures = 3
+
+// customPrompt is a custom entity or relationship extraction prompt (see
+// Config.EntityPrompt) together with its record of unparseable responses.
+// It is shared by the enrichment workers.
+type customPrompt struct {
+	kind     string // "entity" or "relationship", for logging
+	template string
+
+	failures atomic.Int32 // unparseable responses in a row
+	disabled atomic.Bool
+}
+
+// newCustomPrompt returns the custom prompt of kind, or nil for an empty
+// template, meaning the built-in prompt.
+func newCustomPrompt(kind, template string) *customPrompt {
+	if template == "" {
+		return nil
+	}
+	return &customPrompt{kind: kind, template: template}
+}
+
+// active reports whether the custom prompt is set and still in use.
+func (c *customPrompt) active() bool {
+	return c != nil && !c.disabled.Load()
+}
+
+// unparseable records the outcome of a call with the custom prompt and
+// reports whether err is a *parseError, in which case the caller retries
+// with the built-in prompt. After CustomPromptMaxFailures parse failures in
+// a row the custom prompt is disabled. Other errors, such as the LLM being
+// unreachable, say nothing about the prompt and are not counted.
+func (c *customPrompt) unparseable(memoryID string, err error) bool {
+	var pe *parseError
+	if !errors.As(err, &pe) {
+		if err == nil {
+			c.failures.Store(0)
+		}
+		return false
+	}
+	failures := c.failures.Add(1)
+	slog.Warn("pipeline: could not parse response to custom prompt, retrying with the built-in prompt",
+		"prompt", c.kind, "memory_id", memoryID, "failures", failures, "error", pe.err)
+	if failures >= CustomPromptMaxFailures && c.disabled.CompareAndSwap(false, true) {
+		slog.Error("pipeline: custom prompt disabled after repeated parse failures, using the built-in prompt",
+			"prompt", c.kind, "failures", failures)
+	}
+	return true
+}
+
+// parseError is an LLM response that could not be parsed, as opposed to a
+// failure to call the LLM.
+type parseError struct {
+	err error
+}
+
+func (e *parseError) Error() string { return e.err.Error() }
+
+func (e *parseError) Unwrap() error { return e.err }
+
+// useCustomPrompts makes the pipeline extract entities and relationships
+// with the given prompts; an empty one keeps the built-in prompt.
+func (p *ExtractionPipeline) useCustomPrompts(entity, relationship string) {
+	p.entityPrompt = newCustomPrompt("entity", entity)
+	p.relationshipPrompt = newCustomPrompt("relationship", relationship)
+}
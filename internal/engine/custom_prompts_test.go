@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/scrypster/memento/internal/llm"
+)
+
+const (
+	legalEntityPrompt       = "LEGAL ENTITIES\n{{content}}"
+	legalRelationshipPrompt = "LEGAL RELATIONSHIPS\n{{entities}}\n{{content}}"
+)
+
+func TestEnrichmentPipeline_CustomPrompts(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	memoryID := "mem:test:custom"
+	content := "Acme Corp leased the warehouse to Initech."
+	insertTestMemory(t, db, memoryID, content)
+
+	mock := newMockLLMClient()
+	mock.responses = []string{
+		`{"entities": [{"name": "Acme Corp", "type": "organization", "confidence": 0.9},
+		               {"name": "Initech", "type": "organization", "confidence": 0.9}]}`,
+		`{"relationships": [{"from": "Acme Corp", "to": "Initech", "type": "partners_with", "confidence": 0.8}]}`,
+	}
+
+	pipeline := NewExtractionPipeline(mock, db)
+	pipeline.useCustomPrompts(legalEntityPrompt, legalRelationshipPrompt)
+	result, err := pipeline.Extract(ctx, memoryID, content)
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	if len(result.Entities) != 2 || len(result.Relationships) != 1 {
+		t.Errorf("Expected 2 entities and 1 relationship, got %d and %d", len(result.Entities), len(result.Relationships))
+	}
+	if mock.prompts[0] != "LEGAL ENTITIES\n"+content {
+		t.Errorf("Expected the custom entity prompt, got:\n%s", mock.prompts[0])
+	}
+	want := "LEGAL RELATIONSHIPS\n- Acme Corp (organization)\n- Initech (organization)\n\n" + content
+	if mock.prompts[1] != want {
+		t.Errorf("Expected the custom relationship prompt, got:\n%s", mock.prompts[1])
+	}
+}
+
+// TestEnrichmentPipeline_CustomPromptFallback checks that an unparseable
+// response to a custom prompt is retried with the built-in prompt, and that
+// the custom prompt is abandoned after CustomPromptMaxFailures in a row.
+func TestEnrichmentPipeline_CustomPromptFallback(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	pipeline := NewExtractionPipeline(nil, db)
+	pipeline.useCustomPrompts(legalEntityPrompt, "")
+
+	for i := 0; i < CustomPromptMaxFailures; i++ {
+		if !pipeline.entityPrompt.active() {
+			t.Fatalf("custom prompt disabled after %d failures", i)
+		}
+		mock := newMockLLMClient()
+		mock.responses = []string{
+			"Sure! The parties are Acme Corp and Initech.",
+			`{"entities": [{"name": "Acme Corp", "type": "organization", "confidence": 0.9}]}`,
+		}
+		pipeline.llmClient = mock
+
+		entities, _, err := pipeline.extractAndStoreEntities(ctx, "mem:test:fallback", "Acme Corp signed.", nil)
+		if err != nil {
+			t.Fatalf("extractAndStoreEntities() failed: %v", err)
+		}
+		if len(entities) != 1 {
+			t.Errorf("Expected the built-in prompt's entity, got %d entities", len(entities))
+		}
+		if len(mock.prompts) != 2 || !strings.HasPrefix(mock.prompts[0], "LEGAL ENTITIES") ||
+			mock.prompts[1] != llm.EntityExtractionPrompt("Acme Corp signed.") {
+			t.Errorf("Expected the custom prompt, then the built-in one; got %q", mock.prompts)
+		}
+	}
+	if pipeline.entityPrompt.active() {
+		t.Fatal("Expected the custom prompt to be disabled")
+	}
+
+	mock := newMockLLMClient()
+	mock.responses = []string{`{"entities": []}`}
+	pipeline.llmClient = mock
+	if _, _, err := pipeline.extractAndStoreEntities(ctx, "mem:test:fallback", "Acme Corp signed.", nil); err != nil {
+		t.Fatalf("extractAndStoreEntities() failed: %v", err)
+	}
+	if mock.prompts[0] != llm.EntityExtractionPrompt("Acme Corp signed.") {
+		t.Errorf("Expected only the built-in prompt once disabled, got %q", mock.prompts)
+	}
+}
+
+// TestCustomPrompt_SuccessResetsFailures checks that only parse failures in
+// a row count towards disabling a custom prompt.
+func TestCustomPrompt_SuccessResetsFailures(t *testing.T) {
+	c := newCustomPrompt("entity", legalEntityPrompt)
+	for i := 0; i < 3*CustomPromptMaxFailures; i++ {
+		c.unparseable("mem:test:a", &parseError{err: context.Canceled})
+		c.unparseable("mem:test:a", context.DeadlineExceeded)
+		c.unparseable("mem:test:a", nil)
+	}
+	if !c.active() {
+		t.Error("Expected failures separated by successes not to disable the prompt")
+	}
+	if newCustomPrompt("entity", "") != nil || (*customPrompt)(nil).active() {
+		t.Error("Expected an empty template to mean the built-in prompt")
+	}
+}
@@ -0,0 +1,271 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/llm"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// MaxDigestMemories bounds how many memories a digest covers. When more were
+// created in its window the newest are kept and Digest.Truncated is set.
+const MaxDigestMemories = 200
+
+// digestExcerptLength is how many characters of each memory a digest
+// quotes, both in the LLM prompt and in the plain listing.
+const digestExcerptLength = 280
+
+// DigestGroup is one section of a digest: the memories of a domain or,
+// for memories without one, of their first tag.
+type DigestGroup struct {
+	Name      string   `json:"name"`
+	MemoryIDs []string `json:"memory_ids"`
+}
+
+// Digest is a readable summary of the memories created in a time window.
+type Digest struct {
+	Content   string        // Markdown text
+	From, To  time.Time     // The window, both ends exclusive
+	Memories  int           // Memories covered
+	Truncated bool          // More than MaxDigestMemories were created
+	Groups    []DigestGroup // Largest first
+
+	// Summarized reports whether the LLM wrote Content. Without an LLM, or
+	// when the call fails, Content lists the memories instead.
+	Summarized bool
+}
+
+// GenerateDigest gathers the memories of store created between from and
+// to, groups them by domain or topic and asks summarize (e.g.
+// MemoryEngine.Summarize) to write a digest of them. Earlier digests are
+// not included. A nil summarize, or a failed call, produces a plain listing
+// of the memories instead.
+func GenerateDigest(ctx context.Context, store storage.MemoryStore, from, to time.Time, summarize SummarizeFunc) (*Digest, error) {
+	digest := &Digest{From: from, To: to, Groups: []DigestGroup{}}
+	opts := storage.ListOptions{
+		Limit:         storage.MaxLimit,
+		SortBy:        "created_at",
+		SortOrder:     "desc",
+		CreatedAfter:  from,
+		CreatedBefore: to,
+	}
+	var memories []types.Memory
+	for page := 1; !digest.Truncated; page++ {
+		opts.Page = page
+		opts.Normalize()
+		result, err := store.List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list memories: %w", err)
+		}
+		for _, m := range result.Items {
+			if m.MemoryType == types.MemoryTypeDigest {
+				continue
+			}
+			if len(memories) == MaxDigestMemories {
+				digest.Truncated = true
+				break
+			}
+			memories = append(memories, m)
+		}
+		if !result.HasMore || len(result.Items) == 0 {
+			break
+		}
+	}
+	digest.Memories = len(memories)
+
+	period := digestPeriod(from, to)
+	if len(memories) == 0 {
+		digest.Content = fmt.Sprintf("No memories were captured from %s.", period)
+		return digest, nil
+	}
+
+	// Oldest first within each group, so the digest reads in order.
+	groups := make(map[string][]types.Memory)
+	for i := len(memories) - 1; i >= 0; i-- {
+		name := digestGroupName(memories[i])
+		groups[name] = append(groups[name], memories[i])
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := groups[names[i]], groups[names[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return names[i] < names[j]
+	})
+
+	sections := make([]llm.DigestSection, 0, len(names))
+	for _, name := range names {
+		group := DigestGroup{Name: name}
+		section := llm.DigestSection{Name: name}
+		for _, m := range groups[name] {
+			group.MemoryIDs = append(group.MemoryIDs, m.ID)
+			section.Memories = append(section.Memories, digestExcerpt(m))
+		}
+		digest.Groups = append(digest.Groups, group)
+		sections = append(sections, section)
+	}
+
+	title := fmt.Sprintf("# Digest: %s\n\n", period)
+	if summarize != nil {
+		text, err := summarize(ctx, llm.DigestPrompt(period, sections))
+		if err == nil && strings.TrimSpace(text) != "" {
+			digest.Content = title + strings.TrimSpace(text)
+			digest.Summarized = true
+			return digest, nil
+		}
+		slog.Warn("digest: summarization failed, listing the memories instead", "error", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(title)
+	for _, s := range sections {
+		fmt.Fprintf(&b, "## %s\n\n", s.Name)
+		for _, m := range s.Memories {
+			fmt.Fprintf(&b, "- %s\n", m)
+		}
+		b.WriteString("\n")
+	}
+	digest.Content = strings.TrimSpace(b.String())
+	return digest, nil
+}
+
+// Memory returns the digest as a pending memory of type
+// types.MemoryTypeDigest with the given ID, ready to store and enrich. Its
+// metadata records the window and the number of memories covered.
+func (d *Digest) Memory(id string) *types.Memory {
+	now := time.Now()
+	return &types.Memory{
+		ID:         id,
+		Content:    d.Content,
+		Source:     "digest",
+		MemoryType: types.MemoryTypeDigest,
+		Metadata: map[string]interface{}{
+			"digest_from":     d.From.UTC().Format(time.RFC3339),
+			"digest_to":       d.To.UTC().Format(time.RFC3339),
+			"digest_memories": d.Memories,
+		},
+		Status:               types.StatusPending,
+		EntityStatus:         types.EnrichmentPending,
+		RelationshipStatus:   types.EnrichmentPending,
+		ClassificationStatus: types.EnrichmentPending,
+		SummarizationStatus:  types.EnrichmentPending,
+		EmbeddingStatus:      types.EnrichmentPending,
+		AuthorType:           "system",
+		Timestamp:            now,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+}
+
+// digestGroupName returns the group a memory is listed under: its domain,
+// else its first tag, else "general".
+func digestGroupName(m types.Memory) string {
+	switch {
+	case m.Domain != "":
+		return m.Domain
+	case len(m.Tags) > 0:
+		return m.Tags[0]
+	default:
+		return "general"
+	}
+}
+
+// digestExcerpt returns the memory's summary, or its content when it has
+// not been summarized, on one line and cut to digestExcerptLength.
+func digestExcerpt(m types.Memory) string {
+	text := m.Summary
+	if text == "" {
+		text = m.Content
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if cut := types.TruncateRunes(text, digestExcerptLength); cut != text {
+		return cut + "…"
+	}
+	return text
+}
+
+// digestPeriod formats a digest window for its title, in UTC.
+func digestPeriod(from, to time.Time) string {
+	const layout = "2006-01-02 15:04"
+	return fmt.Sprintf("%s to %s UTC", from.UTC().Format(layout), to.UTC().Format(layout))
+}
+
+// ApplyDigestConfig sets DigestInterval from cfg (MEMENTO_DIGEST_INTERVAL).
+func (c *Config) ApplyDigestConfig(cfg config.StorageConfig) error {
+	if cfg.DigestInterval == "" {
+		c.DigestInterval = 0
+		return nil
+	}
+	interval, err := time.ParseDuration(cfg.DigestInterval)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("invalid MEMENTO_DIGEST_INTERVAL %q: must be a positive duration", cfg.DigestInterval)
+	}
+	c.DigestInterval = interval
+	return nil
+}
+
+// startDigester launches a goroutine that stores a digest of the memories
+// created during each DigestInterval until ctx is cancelled. Unlike the
+// auto-archiver it waits for the first interval to pass, so restarts do not
+// produce extra digests. It is tracked by workerWaitGroup.
+func (e *MemoryEngine) startDigester(ctx context.Context) {
+	interval := e.config.DigestInterval
+	if interval <= 0 {
+		return
+	}
+
+	e.workerWaitGroup.Add(1)
+	go func() {
+		defer e.workerWaitGroup.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				e.runDigest(ctx, now.Add(-interval), now)
+			}
+		}
+	}()
+}
+
+// runDigest stores and queues for enrichment a digest of the memories
+// created between from and to, if there were any, and logs the outcome.
+func (e *MemoryEngine) runDigest(ctx context.Context, from, to time.Time) {
+	digest, err := GenerateDigest(ctx, e.memoryStore, from, to, e.Summarize)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("digest failed", "error", err)
+		}
+		return
+	}
+	if digest.Memories == 0 {
+		slog.Info("digest skipped: no memories were created", "from", from, "to", to)
+		return
+	}
+
+	memory := digest.Memory(GenerateMemoryID("", ""))
+	if err := e.memoryStore.Store(ctx, memory); err != nil {
+		slog.Error("failed to store digest", "error", err)
+		return
+	}
+	if e.onMemoryCreated != nil {
+		e.onMemoryCreated(memory.ID)
+	}
+	e.QueueEnrichmentForMemory(memory.ID, memory.Content)
+	slog.Info("stored digest", "memory_id", memory.ID, "memories", digest.Memories, "summarized", digest.Summarized)
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// TestGenerateDigest checks that a digest covers the memories created in
+// its window, largest group first, leaves out earlier digests, and lists
+// the memories when summarization fails.
+func TestGenerateDigest(t *testing.T) {
+	store := createTestStore(t)
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+
+	now := time.Now()
+	for i, m := range []*types.Memory{
+		{ID: "mem:test:deploy", Content: "Deployed the billing service", Domain: "ops"},
+		{ID: "mem:test:pager", Content: "Paged twice for disk alerts", Domain: "ops"},
+		{ID: "mem:test:idea", Content: "Idea: cache the search index", Tags: []string{"ideas"}},
+		{ID: "mem:test:digest", Content: "# Digest: yesterday", MemoryType: types.MemoryTypeDigest},
+		{ID: "mem:test:old", Content: "Last week's retro", Domain: "ops", CreatedAt: now.Add(-48 * time.Hour)},
+	} {
+		m.Source = "test"
+		m.Status = types.StatusEnriched
+		if m.CreatedAt.IsZero() {
+			m.CreatedAt = now.Add(-time.Duration(3-i) * time.Hour)
+		}
+		if err := store.Store(ctx, m); err != nil {
+			t.Fatalf("Store(%s) failed: %v", m.ID, err)
+		}
+	}
+
+	var prompt string
+	digest, err := GenerateDigest(ctx, store, now.Add(-24*time.Hour), now, func(_ context.Context, p string) (string, error) {
+		prompt = p
+		return "## ops\n- Billing shipped; disk alerts paged twice.", nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateDigest() failed: %v", err)
+	}
+	if digest.Memories != 3 || !digest.Summarized {
+		t.Errorf("GenerateDigest() covered %d memories, summarized %v; want 3, true", digest.Memories, digest.Summarized)
+	}
+	if len(digest.Groups) != 2 || digest.Groups[0].Name != "ops" || digest.Groups[1].Name != "ideas" {
+		t.Fatalf("GenerateDigest() groups = %+v, want ops then ideas", digest.Groups)
+	}
+	if got := strings.Join(digest.Groups[0].MemoryIDs, ","); got != "mem:test:deploy,mem:test:pager" {
+		t.Errorf("ops group = %s, want oldest first", got)
+	}
+	if !strings.Contains(prompt, "Paged twice for disk alerts") || strings.Contains(prompt, "retro") {
+		t.Errorf("prompt does not cover exactly the window:\n%s", prompt)
+	}
+	if !strings.HasPrefix(digest.Content, "# Digest: ") || !strings.Contains(digest.Content, "Billing shipped") {
+		t.Errorf("Content = %q", digest.Content)
+	}
+
+	digest, err = GenerateDigest(ctx, store, now.Add(-24*time.Hour), now, func(context.Context, string) (string, error) {
+		return "", errors.New("llm down")
+	})
+	if err != nil {
+		t.Fatalf("GenerateDigest() failed: %v", err)
+	}
+	if digest.Summarized || !strings.Contains(digest.Content, "## ideas\n\n- Idea: cache the search index") {
+		t.Errorf("fallback Content = %q, want a listing", digest.Content)
+	}
+
+	memory := digest.Memory("mem:test:new-digest")
+	if memory.MemoryType != types.MemoryTypeDigest || memory.Metadata["digest_memories"] != 3 {
+		t.Errorf("Memory() = %+v", memory)
+	}
+}
+
+func TestApplyDigestConfig(t *testing.T) {
+	var cfg Config
+	if err := cfg.ApplyDigestConfig(config.StorageConfig{DigestInterval: "24h"}); err != nil || cfg.DigestInterval != 24*time.Hour {
+		t.Errorf("ApplyDigestConfig(24h) = %v, interval %v", err, cfg.DigestInterval)
+	}
+	if err := cfg.ApplyDigestConfig(config.StorageConfig{}); err != nil || cfg.DigestInterval != 0 {
+		t.Errorf("ApplyDigestConfig(\"\") = %v, interval %v", err, cfg.DigestInterval)
+	}
+	for _, bad := range []string{"daily", "-1h", "0s"} {
+		if err := cfg.ApplyDigestConfig(config.StorageConfig{DigestInterval: bad}); err == nil {
+			t.Errorf("ApplyDigestConfig(%q) succeeded, want an error", bad)
+		}
+	}
+}
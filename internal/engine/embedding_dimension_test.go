@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// fixedEmbeddingClient returns a zero vector of the given dimension.
+type fixedEmbeddingClient struct{ dim int }
+
+func (c fixedEmbeddingClient) Embed(context.Context, string) ([]float32, error) {
+	return make([]float32, c.dim), nil
+}
+
+func (fixedEmbeddingClient) GetModel() string { return "fixed" }
+
+// recordingEmbeddingProvider records StoreEmbedding calls.
+type recordingEmbeddingProvider struct{ stored int }
+
+func (p *recordingEmbeddingProvider) StoreEmbedding(context.Context, string, []float64, int, string) error {
+	p.stored++
+	return nil
+}
+
+func (p *recordingEmbeddingProvider) GetEmbedding(context.Context, string) ([]float64, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (p *recordingEmbeddingProvider) DeleteEmbedding(context.Context, string) error { return nil }
+
+func (p *recordingEmbeddingProvider) GetDimension(context.Context, string) (int, error) {
+	return 0, storage.ErrNotFound
+}
+
+func TestEmbed_RejectsUnexpectedDimension(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.EmbeddingDimension = 768
+	e := &MemoryEngine{
+		config:            cfg,
+		enrichmentService: &EnrichmentService{embeddingClient: fixedEmbeddingClient{dim: 1536}},
+	}
+
+	_, err := e.Embed(context.Background(), "text")
+	if !errors.Is(err, storage.ErrDimensionMismatch) {
+		t.Fatalf("Embed() error = %v, want ErrDimensionMismatch", err)
+	}
+
+	e.config.EmbeddingDimension = 1536
+	vec, err := e.Embed(context.Background(), "text")
+	if err != nil {
+		t.Fatalf("Embed() with matching dimension failed: %v", err)
+	}
+	if len(vec) != 1536 {
+		t.Errorf("len(vec) = %d, want 1536", len(vec))
+	}
+}
+
+func TestGenerateEmbeddings_RejectsUnexpectedDimension(t *testing.T) {
+	provider := &recordingEmbeddingProvider{}
+	s := &EnrichmentService{
+		embeddingClient:   fixedEmbeddingClient{dim: 1536},
+		embeddingProvider: provider,
+		expectedDimension: 768,
+	}
+
+	err := s.generateEmbeddings(context.Background(), "mem:test:1", "content")
+	if !errors.Is(err, storage.ErrDimensionMismatch) {
+		t.Fatalf("generateEmbeddings() error = %v, want ErrDimensionMismatch", err)
+	}
+	if provider.stored != 0 {
+		t.Errorf("StoreEmbedding called %d times, want 0", provider.stored)
+	}
+}
@@ -4,9 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/scrypster/memento/internal/llm"
 	"github.com/scrypster/memento/internal/services"
@@ -23,6 +24,16 @@ type ExtractionPipeline struct {
 	llmClient       llm.TextGenerator
 	db              *sql.DB
 	settingsService *services.SettingsService
+
+	// summarizeMinLength skips Call 4 for content shorter than this many
+	// characters (see Config.SummarizeMinLength). Zero summarizes everything.
+	summarizeMinLength int
+
+	// entityPrompt and relationshipPrompt replace the built-in extraction
+	// prompts of Calls 1 and 2 (see Config.EntityPrompt). Nil uses the
+	// built-in ones.
+	entityPrompt       *customPrompt
+	relationshipPrompt *customPrompt
 }
 
 // NewExtractionPipeline creates a new extraction pipeline with the given LLM client and database.
@@ -86,6 +97,7 @@ type ExtractPipelineResult struct {
 // Call 4: Summarization (independent)
 //   - Extracts summary and key points
 //   - Stores summary in memory record
+//   - Skipped for content shorter than summarizeMinLength
 //   - Non-fatal if fails
 //
 // Error Handling:
@@ -109,16 +121,16 @@ func (p *ExtractionPipeline) Extract(ctx context.Context, memoryID, content stri
 	// Fetch per-connection settings (custom entity/relationship/memory types)
 	settings := p.fetchSettings(memoryID)
 	if settings != nil {
-		log.Printf("Pipeline: Loaded settings for memory %s (active category: %q, custom entity types: %d, custom rel types: %d)",
-			memoryID, settings.ActiveClassificationCategory,
-			len(settings.CustomEntityTypes), len(settings.CustomRelationshipTypes))
+		slog.Debug("pipeline: loaded settings",
+			"memory_id", memoryID, "active_category", settings.ActiveClassificationCategory,
+			"custom_entity_types", len(settings.CustomEntityTypes), "custom_relationship_types", len(settings.CustomRelationshipTypes))
 	}
 
 	// Call 1: Entity Extraction
-	log.Printf("Pipeline: Starting entity extraction for memory %s", memoryID)
+	slog.Debug("pipeline: starting entity extraction", "memory_id", memoryID)
 	entities, entityIDMap, entityErr := p.extractAndStoreEntities(ctx, memoryID, content, settings)
 	if entityErr != nil {
-		log.Printf("Pipeline: Entity extraction failed for %s: %v", memoryID, entityErr)
+		slog.Warn("pipeline: entity extraction failed", "memory_id", memoryID, "error", entityErr)
 		result.EntityStatus = types.EnrichmentFailed
 		result.EntityError = entityErr.Error()
 		// Return error to skip remaining extractions
@@ -128,53 +140,57 @@ func (p *ExtractionPipeline) Extract(ctx context.Context, memoryID, content stri
 	result.EntityStatus = types.EnrichmentCompleted
 	result.Entities = entities
 	result.EntityIDs = entityIDMap
-	log.Printf("Pipeline: Successfully extracted %d entities for memory %s", len(entities), memoryID)
+	slog.Debug("pipeline: extracted entities", "memory_id", memoryID, "count", len(entities))
 
 	// Call 2: Relationship Extraction (only if entities were extracted)
 	if len(entities) > 0 {
-		log.Printf("Pipeline: Starting relationship extraction for memory %s", memoryID)
+		slog.Debug("pipeline: starting relationship extraction", "memory_id", memoryID)
 		relationships, relErr := p.extractAndStoreRelationships(ctx, memoryID, content, entities, entityIDMap, settings)
 		if relErr != nil {
-			log.Printf("Pipeline: Relationship extraction failed for %s: %v", memoryID, relErr)
+			slog.Warn("pipeline: relationship extraction failed, entities were preserved", "memory_id", memoryID, "error", relErr)
 			result.RelationshipStatus = types.EnrichmentFailed
 			result.RelationshipError = relErr.Error()
-			log.Printf("Pipeline: WARNING - Relationship extraction failed, but entities were preserved")
 		} else {
 			result.RelationshipStatus = types.EnrichmentCompleted
 			result.Relationships = relationships
-			log.Printf("Pipeline: Successfully extracted %d relationships for memory %s", len(relationships), memoryID)
+			slog.Debug("pipeline: extracted relationships", "memory_id", memoryID, "count", len(relationships))
 		}
 	} else {
-		log.Printf("Pipeline: Skipping relationship extraction (no entities extracted)")
+		slog.Debug("pipeline: skipping relationship extraction (no entities extracted)", "memory_id", memoryID)
 		result.RelationshipStatus = types.EnrichmentSkipped
 	}
 
 	// Call 3: Classification Extraction (independent of entities/relationships)
-	log.Printf("Pipeline: Starting classification extraction for memory %s", memoryID)
+	slog.Debug("pipeline: starting classification extraction", "memory_id", memoryID)
 	classification, classErr := p.extractAndStoreClassification(ctx, memoryID, content, settings)
 	if classErr != nil {
-		log.Printf("Pipeline: Classification extraction failed for %s: %v", memoryID, classErr)
+		slog.Warn("pipeline: classification extraction failed, other extractions preserved", "memory_id", memoryID, "error", classErr)
 		result.ClassificationStatus = types.EnrichmentFailed
 		result.ClassificationError = classErr.Error()
-		log.Printf("Pipeline: WARNING - Classification extraction failed, but other extractions preserved")
 	} else {
 		result.ClassificationStatus = types.EnrichmentCompleted
 		result.Classification = classification
-		log.Printf("Pipeline: Successfully extracted classification for memory %s: %s/%s", memoryID, classification.Category, classification.Classification)
+		slog.Debug("pipeline: extracted classification", "memory_id", memoryID, "category", classification.Category, "classification", classification.Classification)
 	}
 
-	// Call 4: Summarization (independent of other extractions)
-	log.Printf("Pipeline: Starting summarization for memory %s", memoryID)
+	// Call 4: Summarization (independent of other extractions). Short
+	// memories serve as their own preview, so only long ones are summarized.
+	if p.summarizeMinLength > 0 && utf8.RuneCountInString(content) < p.summarizeMinLength {
+		slog.Debug("pipeline: skipping summarization of short memory", "memory_id", memoryID, "min_length", p.summarizeMinLength)
+		result.SummarizationStatus = types.EnrichmentSkipped
+		p.markSummarizationSkipped(ctx, memoryID)
+		return result, nil
+	}
+	slog.Debug("pipeline: starting summarization", "memory_id", memoryID)
 	summary, summErr := p.extractAndStoreSummary(ctx, memoryID, content)
 	if summErr != nil {
-		log.Printf("Pipeline: Summarization failed for %s: %v", memoryID, summErr)
+		slog.Warn("pipeline: summarization failed, other extractions preserved", "memory_id", memoryID, "error", summErr)
 		result.SummarizationStatus = types.EnrichmentFailed
 		result.SummarizationError = summErr.Error()
-		log.Printf("Pipeline: WARNING - Summarization failed, but other extractions preserved")
 	} else {
 		result.SummarizationStatus = types.EnrichmentCompleted
 		result.Summary = summary
-		log.Printf("Pipeline: Successfully created summary for memory %s", memoryID)
+		slog.Debug("pipeline: created summary", "memory_id", memoryID)
 	}
 
 	return result, nil
@@ -195,7 +211,7 @@ func (p *ExtractionPipeline) fetchSettings(memoryID string) *types.SettingsRespo
 	connectionID := parts[1]
 	settings, err := p.settingsService.GetSettings(connectionID)
 	if err != nil {
-		log.Printf("Pipeline: Warning - failed to get settings for connection %s: %v, using defaults", connectionID, err)
+		slog.Warn("pipeline: failed to get connection settings, using defaults", "connection", connectionID, "error", err)
 		return nil
 	}
 	return settings
@@ -214,30 +230,29 @@ func (p *ExtractionPipeline) fetchSettings(memoryID string) *types.SettingsRespo
 //   - Slice of extracted EntityResponse objects
 //   - Error if LLM call or storage fails (partial failures logged but not fatal)
 func (p *ExtractionPipeline) extractAndStoreEntities(ctx context.Context, memoryID, content string, settings *types.SettingsResponse) ([]llm.EntityResponse, map[string]string, error) {
-	// Call LLM for entity extraction using settings-aware prompt
-	prompt := llm.EntityExtractionPromptWithSettings(content, settings)
-	response, err := p.llmClient.Complete(ctx, prompt)
-	if err != nil {
-		return nil, nil, fmt.Errorf("LLM entity extraction failed: %w", err)
-	}
-
-	// Parse response, validating against merged type list
+	// Call LLM for entity extraction, with the custom prompt if one is in
+	// use and otherwise the settings-aware built-in prompt
 	var entities []llm.EntityResponse
 	var entitySkipped []llm.SkippedTypeInfo
-	if settings != nil && len(settings.AllEntityTypes) > 0 {
-		entities, entitySkipped, err = llm.ParseEntityResponseWithTypesDetailed(response, settings.AllEntityTypes)
-	} else {
-		entities, entitySkipped, err = llm.ParseEntityResponseDetailed(response)
+	var err error
+	custom := p.entityPrompt.active()
+	if custom {
+		prompt := llm.RenderEntityPrompt(p.entityPrompt.template, content, settings)
+		entities, entitySkipped, err = p.completeEntities(ctx, prompt, settings)
+	}
+	if !custom || p.entityPrompt.unparseable(memoryID, err) {
+		prompt := llm.EntityExtractionPromptWithSettings(content, settings)
+		entities, entitySkipped, err = p.completeEntities(ctx, prompt, settings)
 	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse entity response: %w", err)
+		return nil, nil, err
 	}
 	p.recordUnknownTypes(ctx, entitySkipped)
 
 	entityIDMap := make(map[string]string, len(entities))
 
 	if len(entities) == 0 {
-		log.Printf("Pipeline: LLM returned no entities for memory %s", memoryID)
+		slog.Debug("pipeline: LLM returned no entities", "memory_id", memoryID)
 		return entities, entityIDMap, nil // Not an error; just no entities
 	}
 
@@ -249,7 +264,7 @@ func (p *ExtractionPipeline) extractAndStoreEntities(ctx context.Context, memory
 	for _, entity := range entities {
 		// Validate entity against merged types list
 		if err := p.validateEntityWithTypes(entity, allowedEntityTypes); err != nil {
-			log.Printf("Pipeline: Skipping invalid entity %s: %v", entity.Name, err)
+			slog.Debug("pipeline: skipping invalid entity", "entity", entity.Name, "error", err)
 			continue
 		}
 
@@ -257,7 +272,7 @@ func (p *ExtractionPipeline) extractAndStoreEntities(ctx context.Context, memory
 		// when entity already existed from a previous memory's enrichment).
 		entityID, err := p.storeEntity(ctx, entity)
 		if err != nil {
-			log.Printf("Pipeline: WARNING - Failed to store entity %s: %v", entity.Name, err)
+			slog.Warn("pipeline: failed to store entity", "entity", entity.Name, "error", err)
 			// Continue storing other entities
 			continue
 		}
@@ -266,7 +281,7 @@ func (p *ExtractionPipeline) extractAndStoreEntities(ctx context.Context, memory
 
 		// Link entity to memory
 		if err := p.linkEntityToMemory(ctx, memoryID, entityID, entity.Confidence); err != nil {
-			log.Printf("Pipeline: WARNING - Failed to link entity %s to memory: %v", entity.Name, err)
+			slog.Warn("pipeline: failed to link entity to memory", "entity", entity.Name, "memory_id", memoryID, "error", err)
 		}
 	}
 
@@ -302,28 +317,27 @@ func (p *ExtractionPipeline) extractAndStoreRelationships(
 		}
 	}
 
-	// Call LLM for relationship extraction using settings-aware prompt
-	prompt := llm.RelationshipExtractionPromptWithSettings(content, typedEntities, settings)
-	response, err := p.llmClient.Complete(ctx, prompt)
-	if err != nil {
-		return nil, fmt.Errorf("LLM relationship extraction failed: %w", err)
-	}
-
-	// Parse response, validating against merged type list
+	// Call LLM for relationship extraction, with the custom prompt if one is
+	// in use and otherwise the settings-aware built-in prompt
 	var relationships []llm.RelationshipResponse
 	var relSkipped []llm.SkippedTypeInfo
-	if settings != nil && len(settings.AllRelationshipTypes) > 0 {
-		relationships, relSkipped, err = llm.ParseRelationshipResponseWithTypesDetailed(response, settings.AllRelationshipTypes)
-	} else {
-		relationships, relSkipped, err = llm.ParseRelationshipResponseDetailed(response)
+	var err error
+	custom := p.relationshipPrompt.active()
+	if custom {
+		prompt := llm.RenderRelationshipPrompt(p.relationshipPrompt.template, content, typedEntities, settings)
+		relationships, relSkipped, err = p.completeRelationships(ctx, prompt, settings)
+	}
+	if !custom || p.relationshipPrompt.unparseable(memoryID, err) {
+		prompt := llm.RelationshipExtractionPromptWithSettings(content, typedEntities, settings)
+		relationships, relSkipped, err = p.completeRelationships(ctx, prompt, settings)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse relationship response: %w", err)
+		return nil, err
 	}
 	p.recordUnknownTypes(ctx, relSkipped)
 
 	if len(relationships) == 0 {
-		log.Printf("Pipeline: LLM returned no relationships for memory %s", memoryID)
+		slog.Debug("pipeline: LLM returned no relationships", "memory_id", memoryID)
 		return relationships, nil // Not an error; just no relationships
 	}
 
@@ -335,7 +349,7 @@ func (p *ExtractionPipeline) extractAndStoreRelationships(
 	for _, rel := range relationships {
 		// Validate relationship against merged types list
 		if err := p.validateRelationshipWithTypes(rel, allowedRelTypes); err != nil {
-			log.Printf("Pipeline: Skipping invalid relationship %s -> %s: %v", rel.From, rel.To, err)
+			slog.Debug("pipeline: skipping invalid relationship", "from", rel.From, "to", rel.To, "error", err)
 			continue
 		}
 
@@ -344,13 +358,13 @@ func (p *ExtractionPipeline) extractAndStoreRelationships(
 		targetID := entityIDMap[rel.To]
 
 		if sourceID == "" || targetID == "" {
-			log.Printf("Pipeline: Skipping relationship %s -> %s (entity not found in extraction)", rel.From, rel.To)
+			slog.Debug("pipeline: skipping relationship (entity not found in extraction)", "from", rel.From, "to", rel.To)
 			continue
 		}
 
 		// Store relationship
 		if err := p.storeRelationship(ctx, sourceID, targetID, rel.Type, rel.Confidence); err != nil {
-			log.Printf("Pipeline: WARNING - Failed to store relationship %s -> %s: %v", rel.From, rel.To, err)
+			slog.Warn("pipeline: failed to store relationship", "from", rel.From, "to", rel.To, "error", err)
 			// Continue storing other relationships
 		}
 	}
@@ -358,6 +372,50 @@ func (p *ExtractionPipeline) extractAndStoreRelationships(
 	return relationships, nil
 }
 
+// completeEntities sends an entity extraction prompt to the LLM and parses
+// the response, validating against the merged type list of settings. Parse
+// failures are returned as *parseError.
+func (p *ExtractionPipeline) completeEntities(ctx context.Context, prompt string, settings *types.SettingsResponse) ([]llm.EntityResponse, []llm.SkippedTypeInfo, error) {
+	response, err := p.llmClient.Complete(ctx, prompt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("LLM entity extraction failed: %w", err)
+	}
+
+	var entities []llm.EntityResponse
+	var skipped []llm.SkippedTypeInfo
+	if settings != nil && len(settings.AllEntityTypes) > 0 {
+		entities, skipped, err = llm.ParseEntityResponseWithTypesDetailed(response, settings.AllEntityTypes)
+	} else {
+		entities, skipped, err = llm.ParseEntityResponseDetailed(response)
+	}
+	if err != nil {
+		return nil, nil, &parseError{fmt.Errorf("failed to parse entity response: %w", err)}
+	}
+	return entities, skipped, nil
+}
+
+// completeRelationships sends a relationship extraction prompt to the LLM
+// and parses the response, validating against the merged type list of
+// settings. Parse failures are returned as *parseError.
+func (p *ExtractionPipeline) completeRelationships(ctx context.Context, prompt string, settings *types.SettingsResponse) ([]llm.RelationshipResponse, []llm.SkippedTypeInfo, error) {
+	response, err := p.llmClient.Complete(ctx, prompt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("LLM relationship extraction failed: %w", err)
+	}
+
+	var relationships []llm.RelationshipResponse
+	var skipped []llm.SkippedTypeInfo
+	if settings != nil && len(settings.AllRelationshipTypes) > 0 {
+		relationships, skipped, err = llm.ParseRelationshipResponseWithTypesDetailed(response, settings.AllRelationshipTypes)
+	} else {
+		relationships, skipped, err = llm.ParseRelationshipResponseDetailed(response)
+	}
+	if err != nil {
+		return nil, nil, &parseError{fmt.Errorf("failed to parse relationship response: %w", err)}
+	}
+	return relationships, skipped, nil
+}
+
 // storeEntity stores an entity in the database (upsert).
 // Returns the entity ID on success.
 func (p *ExtractionPipeline) storeEntity(ctx context.Context, entity llm.EntityResponse) (string, error) {
@@ -524,10 +582,13 @@ func (p *ExtractionPipeline) extractAndStoreClassification(ctx context.Context,
 		return nil, fmt.Errorf("failed to parse classification response: %w", err)
 	}
 
-	// Store classification in memory record
+	// Store classification in memory record. A memory created from a
+	// template keeps the template's memory_type, and a digest stays one.
 	query := `
 		UPDATE memories
-		SET memory_type = ?, category = ?, classification = ?, priority = ?,
+		SET memory_type = CASE WHEN json_extract(metadata, '$.template') IS NULL
+		        AND COALESCE(memory_type, '') != '` + types.MemoryTypeDigest + `' THEN ? ELSE memory_type END,
+		    category = ?, classification = ?, priority = ?,
 		    context_labels = ?, tags = ?, classification_status = ?, updated_at = ?
 		WHERE id = ?
 	`
@@ -549,7 +610,7 @@ func (p *ExtractionPipeline) extractAndStoreClassification(ctx context.Context,
 	)
 
 	if err != nil {
-		log.Printf("Pipeline: WARNING - Failed to store classification for memory %s: %v", memoryID, err)
+		slog.Warn("pipeline: failed to store classification", "memory_id", memoryID, "error", err)
 		// Don't return error; classification failure shouldn't block other extractions
 	}
 
@@ -603,13 +664,28 @@ func (p *ExtractionPipeline) extractAndStoreSummary(ctx context.Context, memoryI
 	)
 
 	if err != nil {
-		log.Printf("Pipeline: WARNING - Failed to store summary for memory %s: %v", memoryID, err)
+		slog.Warn("pipeline: failed to store summary", "memory_id", memoryID, "error", err)
 		// Don't return error; summarization failure shouldn't block other extractions
 	}
 
 	return summary, nil
 }
 
+// markSummarizationSkipped records that Call 4 was skipped for memoryID.
+// Storage errors are logged, like those of the other calls.
+func (p *ExtractionPipeline) markSummarizationSkipped(ctx context.Context, memoryID string) {
+	if p.db == nil {
+		return
+	}
+	_, err := p.db.ExecContext(ctx,
+		`UPDATE memories SET summarization_status = ?, updated_at = ? WHERE id = ?`,
+		types.EnrichmentSkipped, time.Now(), memoryID,
+	)
+	if err != nil {
+		slog.Warn("pipeline: failed to mark summarization skipped", "memory_id", memoryID, "error", err)
+	}
+}
+
 // formatStringArray converts a string slice to JSON array format for storage.
 // Used for storing context_labels, tags, and key_points in database.
 func formatStringArray(arr []string) string {
@@ -655,7 +731,7 @@ func (p *ExtractionPipeline) recordUnknownTypes(ctx context.Context, skipped []l
 			last_seen = strftime('%Y-%m-%dT%H:%M:%SZ','now')`
 	for _, s := range skipped {
 		if _, err := p.db.ExecContext(ctx, upsertSQL, s.Domain, s.TypeName); err != nil {
-			log.Printf("enrichment_pipeline: failed to record unknown type %q/%q: %v", s.Domain, s.TypeName, err)
+			slog.Warn("pipeline: failed to record unknown type", "domain", s.Domain, "type", s.TypeName, "error", err)
 		}
 	}
 }
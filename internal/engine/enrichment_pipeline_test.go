@@ -58,6 +58,7 @@ func TestFullPipelineWithClassificationAndSummary(t *testing.T) {
 		id TEXT PRIMARY KEY,
 		content TEXT,
 		memory_type TEXT,
+		metadata TEXT,
 		category TEXT,
 		classification TEXT,
 		priority TEXT,
@@ -16,6 +16,7 @@ import (
 type mockLLMClient struct {
 	responses []string // responses to return in order
 	errors    []error  // errors to return in order (nil for success)
+	prompts   []string // prompts received, in order
 	callCount int
 	model     string
 }
@@ -31,6 +32,7 @@ func newMockLLMClient() *mockLLMClient {
 
 func (m *mockLLMClient) Complete(ctx context.Context, prompt string) (string, error) {
 	defer func() { m.callCount++ }()
+	m.prompts = append(m.prompts, prompt)
 
 	if m.callCount < len(m.errors) && m.errors[m.callCount] != nil {
 		return "", m.errors[m.callCount]
@@ -60,6 +62,7 @@ func setupTestDB(t *testing.T) *sql.DB {
 		id TEXT PRIMARY KEY,
 		content TEXT,
 		memory_type TEXT,
+		metadata TEXT,
 		category TEXT,
 		classification TEXT,
 		priority TEXT,
@@ -813,6 +816,51 @@ func TestEnrichmentPipeline_Call3Fails(t *testing.T) {
 	}
 }
 
+// TestEnrichmentPipeline_TemplateKeepsMemoryType tests that classification
+// does not replace the memory_type of a memory created from a template, or
+// of a digest
+func TestEnrichmentPipeline_TemplateKeepsMemoryType(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	insertTestMemory(t, db, "mem:test:typed", "Decision\nChoice: Use Go")
+	insertTestMemory(t, db, "mem:test:plain", "We chose Go")
+	insertTestMemory(t, db, "mem:test:digest", "# Digest\n- We chose Go")
+	if _, err := db.Exec(`UPDATE memories SET memory_type = 'decision', metadata = '{"template":"decision"}' WHERE id = 'mem:test:typed'`); err != nil {
+		t.Fatalf("failed to mark typed memory: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE memories SET memory_type = ? WHERE id = 'mem:test:digest'`, types.MemoryTypeDigest); err != nil {
+		t.Fatalf("failed to mark digest: %v", err)
+	}
+
+	for _, id := range []string{"mem:test:typed", "mem:test:plain", "mem:test:digest"} {
+		mock := newMockLLMClient()
+		mock.responses = []string{
+			`{"entities": []}`,
+			`{"memory_type": "concept", "category": "Technology", "classification": "Career",
+			  "priority": "Low", "context_labels": [], "tags": [], "confidence": 0.7}`,
+			`{"summary": "Go was chosen.", "key_points": ["Go"]}`,
+		}
+		if _, err := NewExtractionPipeline(mock, db).Extract(ctx, id, "We chose Go"); err != nil {
+			t.Fatalf("Extract(%s) failed: %v", id, err)
+		}
+	}
+
+	for id, want := range map[string]string{"mem:test:typed": "decision", "mem:test:plain": "concept", "mem:test:digest": types.MemoryTypeDigest} {
+		var memoryType, status string
+		if err := db.QueryRow(`SELECT memory_type, classification_status FROM memories WHERE id = ?`, id).Scan(&memoryType, &status); err != nil {
+			t.Fatalf("failed to read %s: %v", id, err)
+		}
+		if memoryType != want {
+			t.Errorf("%s: memory_type = %q, want %q", id, memoryType, want)
+		}
+		if status != string(types.EnrichmentCompleted) {
+			t.Errorf("%s: classification_status = %q, want completed", id, status)
+		}
+	}
+}
+
 // TestEnrichmentPipeline_Call4Fails tests when summarization extraction fails
 func TestEnrichmentPipeline_Call4Fails(t *testing.T) {
 	ctx := context.Background()
@@ -1001,3 +1049,45 @@ func TestEnrichmentPipeline_StoresIntoDatabase(t *testing.T) {
 		t.Errorf("Expected total frequency 3, got %d", totalFreq)
 	}
 }
+
+// TestEnrichmentPipeline_SkipsSummaryForShortContent verifies that Call 4 is
+// skipped, without an LLM call, for content below summarizeMinLength.
+func TestEnrichmentPipeline_SkipsSummaryForShortContent(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	memoryID := "mem:test:011"
+	content := "MJ reviewed the release notes."
+	insertTestMemory(t, db, memoryID, content)
+
+	mock := newMockLLMClient()
+	mock.responses = []string{
+		`{"entities": [{"name": "MJ", "type": "person", "confidence": 0.95}]}`,
+		`{"relationships": []}`,
+		`{"memory_type": "event", "category": "Work", "classification": "Review",
+		  "priority": "Low", "context_labels": [], "tags": [], "confidence": 0.80}`,
+	}
+
+	pipeline := NewExtractionPipeline(mock, db)
+	pipeline.summarizeMinLength = 200
+	result, err := pipeline.Extract(ctx, memoryID, content)
+	if err != nil {
+		t.Fatalf("Extract() failed: %v", err)
+	}
+
+	if result.SummarizationStatus != types.EnrichmentSkipped {
+		t.Errorf("Expected summarization status skipped, got %s", result.SummarizationStatus)
+	}
+	if mock.callCount != 3 {
+		t.Errorf("Expected 3 LLM calls (no summarization), got %d", mock.callCount)
+	}
+
+	var status string
+	if err := db.QueryRow(`SELECT summarization_status FROM memories WHERE id = ?`, memoryID).Scan(&status); err != nil {
+		t.Fatalf("failed to read summarization_status: %v", err)
+	}
+	if status != string(types.EnrichmentSkipped) {
+		t.Errorf("Expected stored summarization_status skipped, got %s", status)
+	}
+}
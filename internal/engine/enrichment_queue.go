@@ -2,28 +2,125 @@ package engine
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"sync"
 	"time"
+
+	"github.com/scrypster/memento/internal/config"
 )
 
+// ApplyQueueConfig sets QueueSize, QueueWait and PendingRescanInterval from
+// cfg (MEMENTO_ENRICHMENT_QUEUE_SIZE, MEMENTO_ENRICHMENT_QUEUE_WAIT and
+// MEMENTO_PENDING_RESCAN_INTERVAL).
+func (c *Config) ApplyQueueConfig(cfg config.StorageConfig) error {
+	if cfg.EnrichmentQueueSize < 1 {
+		return fmt.Errorf("invalid MEMENTO_ENRICHMENT_QUEUE_SIZE %d: must be at least 1", cfg.EnrichmentQueueSize)
+	}
+	wait, err := time.ParseDuration(cfg.EnrichmentQueueWait)
+	if err != nil || wait < 0 {
+		return fmt.Errorf("invalid MEMENTO_ENRICHMENT_QUEUE_WAIT %q: must be a non-negative duration", cfg.EnrichmentQueueWait)
+	}
+	rescan, err := time.ParseDuration(cfg.PendingRescanInterval)
+	if err != nil || rescan < 0 {
+		return fmt.Errorf("invalid MEMENTO_PENDING_RESCAN_INTERVAL %q: must be a non-negative duration", cfg.PendingRescanInterval)
+	}
+	c.QueueSize = cfg.EnrichmentQueueSize
+	c.QueueWait = wait
+	c.PendingRescanInterval = rescan
+	return nil
+}
+
 // queueEnrichmentJob attempts to queue an enrichment job.
 // Returns true if the job was queued successfully, false if the queue is full or closed.
 func (e *MemoryEngine) queueEnrichmentJob(job *EnrichmentJob) bool {
+	return e.queueEnrichmentJobWithin(job, 0)
+}
+
+// queueEnrichmentJobWithin is queueEnrichmentJob, but when the queue is full
+// it waits up to wait for a worker to free a slot before giving up.
+func (e *MemoryEngine) queueEnrichmentJobWithin(job *EnrichmentJob, wait time.Duration) bool {
 	// Check if worker context is cancelled (shutdown in progress)
 	if e.workerCtx != nil && e.workerCtx.Err() != nil {
 		return false
 	}
 
+	// Count the job before sending so a fast worker cannot finish it first.
+	e.queued.add(job.MemoryID)
+
 	// Try to queue (non-blocking)
 	select {
 	case e.enrichmentQueue <- job:
 		return true
 	default:
-		// Queue is full or closed
-		log.Printf("WARNING: Enrichment queue full (size=%d), dropping job for memory %s",
-			e.config.QueueSize, job.MemoryID)
+	}
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		var stopping <-chan struct{}
+		if e.workerCtx != nil {
+			stopping = e.workerCtx.Done()
+		}
+		select {
+		case e.enrichmentQueue <- job:
+			return true
+		case <-timer.C:
+		case <-stopping:
+			e.queued.done(job.MemoryID)
+			return false
+		}
+	}
+
+	e.queued.done(job.MemoryID)
+	e.deferred.Add(1)
+	slog.Warn("enrichment queue full, dropping job",
+		"queue_size", e.config.QueueSize, "memory_id", job.MemoryID)
+	return false
+}
+
+// queueIfRunning queues job unless the engine is stopped or shutting down,
+// waiting up to wait for space. It holds the read lock throughout so
+// Shutdown cannot close the queue under a blocked send.
+func (e *MemoryEngine) queueIfRunning(job *EnrichmentJob, wait time.Duration) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if !e.started || e.shuttingDown {
 		return false
 	}
+	return e.queueEnrichmentJobWithin(job, wait)
+}
+
+// queuedJobs counts, per memory, the jobs that are queued or being
+// processed, so a pending rescan does not queue the same memory twice.
+type queuedJobs struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (q *queuedJobs) add(memoryID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.counts == nil {
+		q.counts = make(map[string]int)
+	}
+	q.counts[memoryID]++
+}
+
+func (q *queuedJobs) done(memoryID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.counts[memoryID] <= 1 {
+		delete(q.counts, memoryID)
+		return
+	}
+	q.counts[memoryID]--
+}
+
+func (q *queuedJobs) has(memoryID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.counts[memoryID] > 0
 }
 
 // createEnrichmentJob creates a new enrichment job from memory data.
@@ -41,30 +138,32 @@ func (e *MemoryEngine) createEnrichmentJob(memoryID, content string, attempt int
 func (e *MemoryEngine) requeueEnrichmentJob(ctx context.Context, job *EnrichmentJob) bool {
 	// Check if worker context is cancelled (shutdown in progress)
 	if e.workerCtx != nil && e.workerCtx.Err() != nil {
-		log.Printf("WARNING: Failed to requeue job for memory %s, shutdown in progress", job.MemoryID)
+		slog.Warn("failed to requeue job, shutdown in progress", "memory_id", job.MemoryID)
 		return false
 	}
 
 	// Check if max retries exceeded
 	if job.Attempt >= e.config.MaxRetries {
-		log.Printf("Max retries (%d) exceeded for memory %s, giving up",
-			e.config.MaxRetries, job.MemoryID)
+		slog.Warn("max retries exceeded, giving up",
+			"max_retries", e.config.MaxRetries, "memory_id", job.MemoryID)
 		return false
 	}
 
 	// Increment attempt counter
 	job.Attempt++
+	e.queued.add(job.MemoryID)
 
 	// Try to requeue (non-blocking to avoid panic on closed channel)
 	select {
 	case e.enrichmentQueue <- job:
-		log.Printf("Requeued enrichment job for memory %s (attempt %d/%d)",
-			job.MemoryID, job.Attempt, e.config.MaxRetries)
+		slog.Info("requeued enrichment job",
+			"memory_id", job.MemoryID, "attempt", job.Attempt, "max_retries", e.config.MaxRetries)
 		return true
 	case <-time.After(10 * time.Millisecond):
 		// Timeout - queue might be full or closed
-		log.Printf("WARNING: Failed to requeue job for memory %s, queue timeout",
-			job.MemoryID)
+		e.queued.done(job.MemoryID)
+		slog.Warn("failed to requeue job, queue timeout",
+			"memory_id", job.MemoryID)
 		return false
 	}
 }
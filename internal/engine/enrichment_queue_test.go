@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStalledEngine returns an engine that accepts jobs but has no workers,
+// so its queue only drains when the test reads from it.
+func newStalledEngine(t *testing.T, queueSize int) *MemoryEngine {
+	t.Helper()
+	eng := newTestEngine(t)
+	eng.config.QueueSize = queueSize
+	eng.enrichmentQueue = make(chan *EnrichmentJob, queueSize)
+	eng.started = true
+	return eng
+}
+
+// drainOne takes one job off the queue as a worker would.
+func drainOne(t *testing.T, eng *MemoryEngine) *EnrichmentJob {
+	t.Helper()
+	select {
+	case job := <-eng.enrichmentQueue:
+		eng.queued.done(job.MemoryID)
+		return job
+	case <-time.After(time.Second):
+		t.Fatal("queue is empty")
+		return nil
+	}
+}
+
+func TestQueueEnrichmentForMemory_WaitsForSpace(t *testing.T) {
+	eng := newStalledEngine(t, 1)
+	require.True(t, eng.QueueEnrichmentForMemory("mem:general:a", "a"))
+
+	eng.config.QueueWait = 50 * time.Millisecond
+	start := time.Now()
+	assert.False(t, eng.QueueEnrichmentForMemory("mem:general:b", "b"))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.False(t, eng.queued.has("mem:general:b"))
+
+	eng.config.QueueWait = 5 * time.Second
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		job := <-eng.enrichmentQueue
+		eng.queued.done(job.MemoryID)
+	}()
+	assert.True(t, eng.QueueEnrichmentForMemory("mem:general:b", "b"))
+	assert.Equal(t, "mem:general:b", drainOne(t, eng).MemoryID)
+}
+
+func TestRescanPending_QueuesDeferredMemoriesOnce(t *testing.T) {
+	eng := newStalledEngine(t, 2)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, eng.memoryStore.Store(ctx, &types.Memory{
+			ID:        fmt.Sprintf("mem:general:%d", i),
+			Content:   fmt.Sprintf("pending %d", i),
+			Source:    "test",
+			Status:    types.StatusPending,
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Second),
+		}))
+	}
+
+	eng.rescanPending(ctx)
+	assert.Equal(t, 2, eng.GetQueueSize())
+
+	// Already-queued memories are skipped, and the full queue stops the pass.
+	eng.rescanPending(ctx)
+	assert.Equal(t, 2, eng.GetQueueSize())
+
+	first := drainOne(t, eng)
+	require.NoError(t, eng.memoryStore.UpdateStatus(ctx, first.MemoryID, types.StatusEnriched))
+	eng.rescanPending(ctx)
+
+	queued := map[string]bool{drainOne(t, eng).MemoryID: true, drainOne(t, eng).MemoryID: true}
+	assert.Equal(t, map[string]bool{"mem:general:1": true, "mem:general:2": true}, queued)
+	assert.Equal(t, "mem:general:0", first.MemoryID)
+}
+
+func TestRecoverPendingEnrichments_LeavesOverflowPending(t *testing.T) {
+	eng := newStalledEngine(t, 1)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		require.NoError(t, eng.memoryStore.Store(ctx, &types.Memory{
+			ID: fmt.Sprintf("mem:general:r%d", i), Content: "pending", Source: "test", Status: types.StatusPending,
+		}))
+	}
+
+	require.NoError(t, eng.RecoverPendingEnrichments(ctx))
+	assert.Equal(t, 1, eng.GetQueueSize())
+
+	for i := 0; i < 2; i++ {
+		m, err := eng.memoryStore.Get(ctx, fmt.Sprintf("mem:general:r%d", i))
+		require.NoError(t, err)
+		assert.Equal(t, types.StatusPending, m.Status, "overflow must stay pending, not fail")
+	}
+}
+
+func TestRecoverPendingEnrichments_SkipsFilteredDomains(t *testing.T) {
+	eng := newStalledEngine(t, 4)
+	eng.SetEnrichmentFilter(func(domain string) bool { return domain != "logs" })
+	ctx := context.Background()
+	for _, domain := range []string{"", "logs", "work"} {
+		require.NoError(t, eng.memoryStore.Store(ctx, &types.Memory{
+			ID: "mem:" + domain + ":f", Content: "pending", Source: "test", Domain: domain, Status: types.StatusPending,
+		}))
+	}
+
+	require.NoError(t, eng.RecoverPendingEnrichments(ctx))
+	assert.Equal(t, 2, eng.GetQueueSize())
+	queued := map[string]bool{drainOne(t, eng).MemoryID: true, drainOne(t, eng).MemoryID: true}
+	assert.Equal(t, map[string]bool{"mem::f": true, "mem:work:f": true}, queued)
+}
+
+func TestApplyQueueConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, cfg.ApplyQueueConfig(config.StorageConfig{
+		EnrichmentQueueSize: 50, EnrichmentQueueWait: "250ms", PendingRescanInterval: "0",
+	}))
+	assert.Equal(t, 50, cfg.QueueSize)
+	assert.Equal(t, 250*time.Millisecond, cfg.QueueWait)
+	assert.Zero(t, cfg.PendingRescanInterval)
+
+	for _, bad := range []config.StorageConfig{
+		{EnrichmentQueueSize: 0, EnrichmentQueueWait: "0s", PendingRescanInterval: "5m"},
+		{EnrichmentQueueSize: 10, EnrichmentQueueWait: "soon", PendingRescanInterval: "5m"},
+		{EnrichmentQueueSize: 10, EnrichmentQueueWait: "0s", PendingRescanInterval: "-1m"},
+	} {
+		assert.Error(t, cfg.ApplyQueueConfig(bad), "%+v", bad)
+	}
+}
@@ -2,7 +2,8 @@ package engine
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"time"
 
 	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/pkg/types"
@@ -11,52 +12,121 @@ import (
 // RecoverPendingEnrichments recovers pending enrichments from previous runs.
 // It queries the storage for memories with StatusPending and queues them for enrichment.
 // This is called automatically during Start() to ensure no enrichments are lost.
+// Memories that do not fit in the queue stay pending for the next rescan
+// (see Config.PendingRescanInterval).
 func (e *MemoryEngine) RecoverPendingEnrichments(ctx context.Context) error {
-	log.Println("Starting enrichment recovery for pending memories...")
+	slog.Debug("starting enrichment recovery for pending memories")
 
-	totalQueued := 0
+	totalQueued, full, err := e.queuePendingMemories(ctx, e.queueEnrichmentJob)
+	if err != nil {
+		slog.Error("failed to list pending memories for recovery", "error", err)
+		return err
+	}
 
+	if full {
+		slog.Warn("recovery stopped: queue full; the rest stay pending for the next rescan", "queued", totalQueued)
+		return nil
+	}
+	if totalQueued == 0 {
+		slog.Debug("no pending memories to recover")
+		return nil
+	}
+	slog.Info("recovery complete", "queued", totalQueued)
+	return nil
+}
+
+// queuePendingMemories passes every pending memory that has no job queued or
+// in flight, and whose domain the enrichment filter accepts, to enqueue. It
+// stops at the first job enqueue rejects, reporting
+// full, since later ones would be rejected too.
+func (e *MemoryEngine) queuePendingMemories(ctx context.Context, enqueue func(*EnrichmentJob) bool) (queued int, full bool, err error) {
 	for page := 1; ; page++ {
 		opts := storage.ListOptions{
 			Filter: map[string]interface{}{
 				"status": types.StatusPending,
 			},
-			Limit: e.config.RecoveryBatchSize,
-			Page:  page,
+			Limit:     e.config.RecoveryBatchSize,
+			Page:      page,
+			SortBy:    "created_at",
+			SortOrder: "asc",
 		}
 
 		result, err := e.memoryStore.List(ctx, opts)
 		if err != nil {
-			log.Printf("ERROR: Failed to list pending memories for recovery: %v", err)
-			return err
+			return queued, false, err
 		}
 
-		if len(result.Items) == 0 {
-			log.Println("No pending memories to recover")
-			return nil
-		}
-
-		// Queue all pending memories for enrichment
 		for _, memory := range result.Items {
-			job := e.createEnrichmentJob(memory.ID, memory.Content, 0)
-
-			if e.queueEnrichmentJob(job) {
-				totalQueued++
-			} else {
-				// Queue is full, mark as failed
-				if err := e.memoryStore.UpdateStatus(ctx, memory.ID, types.StatusFailed); err != nil {
-					log.Printf("ERROR: Failed to mark memory %s as failed: %v", memory.ID, err)
-				}
+			if e.queued.has(memory.ID) {
+				continue
+			}
+			if e.enrichesDomain != nil && !e.enrichesDomain(memory.Domain) {
+				continue
+			}
+			job := e.createEnrichmentJob(memory.ID, memory.EnrichmentContent(), 0)
+			if !enqueue(job) {
+				return queued, true, nil
 			}
+			queued++
 		}
 
-		if !result.HasMore {
-			break
+		if !result.HasMore || len(result.Items) == 0 {
+			return queued, false, nil
 		}
 
-		log.Printf("More pending memories found (%d total), processing next batch...", result.Total)
+		slog.Debug("more pending memories found, processing next batch", "total", result.Total)
 	}
+}
 
-	log.Printf("Recovery complete: queued %d pending enrichments", totalQueued)
-	return nil
+// startPendingRescanner launches a background goroutine that re-queues
+// pending memories every PendingRescanInterval, so a memory whose job was
+// dropped because the queue was full is enriched without waiting for a
+// restart. It stops when ctx is cancelled.
+func (e *MemoryEngine) startPendingRescanner(ctx context.Context) {
+	interval := e.config.PendingRescanInterval
+	if interval <= 0 {
+		return
+	}
+
+	e.workerWaitGroup.Add(1)
+	go func() {
+		defer e.workerWaitGroup.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			e.rescanPending(ctx)
+		}
+	}()
+}
+
+// rescanPending queues pending memories that have no job queued or in flight.
+func (e *MemoryEngine) rescanPending(ctx context.Context) {
+	queued, full, err := e.queuePendingMemories(ctx, func(job *EnrichmentJob) bool {
+		// Shutdown holds the write lock while it waits for this goroutine to
+		// exit, so blocking on the read lock here would stall it.
+		if !e.mu.TryRLock() {
+			return false
+		}
+		defer e.mu.RUnlock()
+		if !e.started || e.shuttingDown {
+			return false
+		}
+		return e.queueEnrichmentJob(job)
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("pending rescan failed", "error", err)
+		}
+		return
+	}
+	if queued > 0 || full {
+		slog.Info("pending rescan queued enrichments", "queued", queued, "queue_full", full)
+	}
 }
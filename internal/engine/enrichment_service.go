@@ -4,7 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/scrypster/memento/internal/llm"
 )
@@ -18,6 +18,10 @@ type EnrichmentService struct {
 	db                 *sql.DB
 	embeddingProvider  EmbeddingProvider
 	ExtractionPipeline *ExtractionPipeline
+
+	// expectedDimension, when non-zero, is the only embedding dimension
+	// generateEmbeddings will store (see Config.EmbeddingDimension).
+	expectedDimension int
 }
 
 // EmbeddingProvider defines the interface for storing embeddings.
@@ -84,31 +88,31 @@ func (s *EnrichmentService) Embed(ctx context.Context, text string) ([]float64,
 // Uses ExtractionPipeline for orchestrated entity and relationship extraction.
 // Handles partial failures gracefully (entities preserved even if relationships fail).
 func (s *EnrichmentService) EnrichMemory(ctx context.Context, memoryID, content string) error {
-	log.Printf("Enriching memory %s using extraction pipeline", memoryID)
+	slog.Debug("enriching memory using extraction pipeline", "memory_id", memoryID)
 
 	// Run the two-call extraction pipeline
 	pipelineResult, err := s.ExtractionPipeline.Extract(ctx, memoryID, content)
 	if err != nil {
-		log.Printf("ERROR: Extraction pipeline failed for memory %s: %v", memoryID, err)
+		slog.Error("extraction pipeline failed", "memory_id", memoryID, "error", err)
 		// If entity extraction failed, return error (relationships weren't even attempted)
 		return fmt.Errorf("extraction pipeline failed: %w", err)
 	}
 
 	// Log pipeline results
-	log.Printf("Pipeline result for memory %s: EntityStatus=%s, RelationshipStatus=%s",
-		memoryID, pipelineResult.EntityStatus, pipelineResult.RelationshipStatus)
+	slog.Debug("pipeline result",
+		"memory_id", memoryID, "entity", pipelineResult.EntityStatus, "relationship", pipelineResult.RelationshipStatus)
 
 	// Step 2: Generate embeddings if embedding provider is available
 	if s.embeddingProvider != nil {
 		if err := s.generateEmbeddings(ctx, memoryID, content); err != nil {
-			log.Printf("Warning: Failed to generate embeddings for memory %s: %v", memoryID, err)
+			slog.Warn("failed to generate embeddings", "memory_id", memoryID, "error", err)
 			// Don't fail the entire enrichment if embedding generation fails
 			// The memory will still be enriched with entities and relationships
 		}
 	}
 
-	log.Printf("Enrichment complete for memory %s: %d entities, %d relationships",
-		memoryID, len(pipelineResult.Entities), len(pipelineResult.Relationships))
+	slog.Info("enrichment complete",
+		"memory_id", memoryID, "entities", len(pipelineResult.Entities), "relationships", len(pipelineResult.Relationships))
 	return nil
 }
 
@@ -148,13 +152,16 @@ func (s *EnrichmentService) generateEmbeddings(ctx context.Context, memoryID, co
 
 	dimension := len(embedding)
 	model := s.embeddingClient.GetModel()
+	if err := checkEmbeddingDimension(dimension, s.expectedDimension, model); err != nil {
+		return err
+	}
 
 	// Store embedding in the database
 	if err := s.embeddingProvider.StoreEmbedding(ctx, memoryID, embedding, dimension, model); err != nil {
 		return fmt.Errorf("failed to store embedding: %w", err)
 	}
 
-	log.Printf("Successfully generated and stored embedding for memory %s (dimension: %d, model: %s)",
-		memoryID, dimension, model)
+	slog.Debug("stored embedding",
+		"memory_id", memoryID, "dimension", dimension, "model", model)
 	return nil
 }
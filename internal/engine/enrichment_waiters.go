@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// enrichmentWaiters delivers the outcome of a memory's enrichment to callers
+// blocked on it, keyed by memory ID.
+type enrichmentWaiters struct {
+	mu      sync.Mutex
+	waiters map[string][]chan types.MemoryStatus
+}
+
+// WaitForEnrichment registers interest in the outcome of the next full
+// enrichment of memoryID. The returned channel receives StatusEnriched or
+// StatusFailed once a worker finishes with the memory (retries included);
+// cancel unregisters the waiter and must be called when the caller stops
+// waiting. Register before queueing the job so a fast worker cannot finish
+// first.
+func (e *MemoryEngine) WaitForEnrichment(memoryID string) (done <-chan types.MemoryStatus, cancel func()) {
+	ch := make(chan types.MemoryStatus, 1)
+
+	w := &e.enrichmentWaiters
+	w.mu.Lock()
+	if w.waiters == nil {
+		w.waiters = make(map[string][]chan types.MemoryStatus)
+	}
+	w.waiters[memoryID] = append(w.waiters[memoryID], ch)
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		chans := w.waiters[memoryID]
+		for i, c := range chans {
+			if c == ch {
+				chans = append(chans[:i], chans[i+1:]...)
+				break
+			}
+		}
+		if len(chans) == 0 {
+			delete(w.waiters, memoryID)
+		} else {
+			w.waiters[memoryID] = chans
+		}
+	}
+}
+
+// signalEnrichmentDone sends status to every waiter registered for memoryID
+// and unregisters them.
+func (e *MemoryEngine) signalEnrichmentDone(memoryID string, status types.MemoryStatus) {
+	w := &e.enrichmentWaiters
+	w.mu.Lock()
+	chans := w.waiters[memoryID]
+	delete(w.waiters, memoryID)
+	w.mu.Unlock()
+
+	for _, ch := range chans {
+		ch <- status // buffered; each channel receives exactly one value
+	}
+}
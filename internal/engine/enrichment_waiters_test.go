@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForEnrichment_ReceivesOutcome(t *testing.T) {
+	eng := newTestEngine(t)
+	eng.config.MaxRetries = 0
+
+	ctx := context.Background()
+	require.NoError(t, eng.Start(ctx))
+	defer func() { _ = eng.Shutdown(ctx) }()
+
+	require.NoError(t, eng.memoryStore.Store(ctx, &types.Memory{
+		ID: "mem:general:waited", Content: "wait for me", Source: "test", Status: types.StatusPending,
+	}))
+
+	enriched, cancelEnriched := eng.WaitForEnrichment("mem:general:waited")
+	defer cancelEnriched()
+	failed, cancelFailed := eng.WaitForEnrichment("mem:general:missing")
+	defer cancelFailed()
+
+	require.True(t, eng.QueueEnrichmentForMemory("mem:general:waited", "wait for me"))
+	// The memory does not exist, so the worker cannot mark it processing.
+	require.True(t, eng.QueueEnrichmentForMemory("mem:general:missing", "content"))
+
+	for _, tc := range []struct {
+		done <-chan types.MemoryStatus
+		want types.MemoryStatus
+	}{
+		{enriched, types.StatusEnriched},
+		{failed, types.StatusFailed},
+	} {
+		select {
+		case status := <-tc.done:
+			assert.Equal(t, tc.want, status)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %s", tc.want)
+		}
+	}
+}
+
+func TestWaitForEnrichment_CancelUnregisters(t *testing.T) {
+	eng := newTestEngine(t)
+
+	_, cancel := eng.WaitForEnrichment("mem:general:a")
+	done, keep := eng.WaitForEnrichment("mem:general:a")
+	defer keep()
+	cancel()
+
+	eng.signalEnrichmentDone("mem:general:a", types.StatusEnriched)
+	assert.Equal(t, types.StatusEnriched, <-done)
+	assert.Empty(t, eng.enrichmentWaiters.waiters)
+}
@@ -3,7 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/scrypster/memento/internal/storage"
@@ -15,13 +15,16 @@ import (
 func (e *MemoryEngine) enrichmentWorker(ctx context.Context, workerID int) {
 	defer e.workerWaitGroup.Done()
 
-	log.Printf("Enrichment worker %d started", workerID)
+	slog.Debug("enrichment worker started", "worker", workerID)
 
 	for job := range e.enrichmentQueue {
+		e.inFlight.Add(1)
 		e.processEnrichmentJob(ctx, workerID, job)
+		e.inFlight.Add(-1)
+		e.queued.done(job.MemoryID)
 	}
 
-	log.Printf("Enrichment worker %d stopped", workerID)
+	slog.Debug("enrichment worker stopped", "worker", workerID)
 }
 
 // processEnrichmentJob processes a single enrichment job using the extraction pipeline.
@@ -29,7 +32,7 @@ func (e *MemoryEngine) enrichmentWorker(ctx context.Context, workerID int) {
 // Handles partial failures gracefully (entities preserved even if relationships fail).
 // If job.EmbeddingOnly is true, skips the full LLM extraction and only generates embeddings.
 func (e *MemoryEngine) processEnrichmentJob(ctx context.Context, workerID int, job *EnrichmentJob) {
-	log.Printf("Worker %d processing memory %s (attempt %d, embeddingOnly=%v)", workerID, job.MemoryID, job.Attempt, job.EmbeddingOnly)
+	slog.Debug("processing enrichment job", "worker", workerID, "memory_id", job.MemoryID, "attempt", job.Attempt, "embedding_only", job.EmbeddingOnly)
 
 	// Use background context for database operations to avoid cancellation during shutdown
 	dbCtx := context.Background()
@@ -37,20 +40,20 @@ func (e *MemoryEngine) processEnrichmentJob(ctx context.Context, workerID int, j
 	// Apply exponential backoff for retries to reduce database lock contention
 	if job.Attempt > 0 {
 		backoffDuration := time.Duration(job.Attempt*job.Attempt) * 100 * time.Millisecond // 100ms, 400ms, 900ms...
-		log.Printf("Worker %d: Waiting %v before retry (attempt %d)", workerID, backoffDuration, job.Attempt)
+		slog.Debug("waiting before retry", "worker", workerID, "backoff", backoffDuration, "attempt", job.Attempt)
 		time.Sleep(backoffDuration)
 	}
 
 	// EmbeddingOnly path: just generate embeddings and return.
 	if job.EmbeddingOnly {
 		if e.enrichmentService != nil {
-			if embErr := e.enrichmentService.GenerateEmbeddings(ctx, job.MemoryID, job.Content); embErr != nil {
-				log.Printf("Worker %d: WARNING - embedding-only generation failed for %s: %v", workerID, job.MemoryID, embErr)
+			if embErr := e.generateEmbeddings(ctx, job); embErr != nil {
+				slog.Warn("embedding-only generation failed", "worker", workerID, "memory_id", job.MemoryID, "error", embErr)
 			} else {
-				log.Printf("Worker %d: embedding-only job completed for %s", workerID, job.MemoryID)
+				slog.Debug("embedding-only job completed", "worker", workerID, "memory_id", job.MemoryID)
 			}
 		} else {
-			log.Printf("Worker %d: embedding-only job skipped (no enrichment service) for %s", workerID, job.MemoryID)
+			slog.Debug("embedding-only job skipped (no enrichment service)", "worker", workerID, "memory_id", job.MemoryID)
 		}
 		if e.onEnrichmentComplete != nil {
 			e.onEnrichmentComplete(job.MemoryID)
@@ -60,15 +63,9 @@ func (e *MemoryEngine) processEnrichmentJob(ctx context.Context, workerID int, j
 
 	// Update status to processing
 	if err := e.memoryStore.UpdateStatus(dbCtx, job.MemoryID, types.StatusProcessing); err != nil {
-		log.Printf("ERROR: Worker %d failed to update status to processing for %s: %v",
-			workerID, job.MemoryID, err)
-		// Try to requeue the job
-		if !e.requeueEnrichmentJob(ctx, job) {
-			// Failed to requeue, mark as failed
-			if err := e.memoryStore.UpdateStatus(dbCtx, job.MemoryID, types.StatusFailed); err != nil {
-				log.Printf("ERROR: Worker %d failed to mark %s as failed: %v", workerID, job.MemoryID, err)
-			}
-		}
+		slog.Error("failed to update status to processing",
+			"worker", workerID, "memory_id", job.MemoryID, "error", err)
+		e.retryOrFail(ctx, dbCtx, workerID, job)
 		return
 	}
 
@@ -86,16 +83,10 @@ func (e *MemoryEngine) processEnrichmentJob(ctx context.Context, workerID int, j
 	// Phase 3 integration: Call LLM extraction pipeline for entity and relationship extraction
 	var embeddingStatus types.EnrichmentStatus
 	if e.enrichmentService != nil {
-		pipelineResult, err := e.enrichmentService.ExtractionPipeline.Extract(ctx, job.MemoryID, job.Content)
+		pipelineResult, err := e.extract(ctx, job)
 		if err != nil {
-			log.Printf("ERROR: Worker %d entity extraction failed for %s: %v", workerID, job.MemoryID, err)
-			// Mark as failed and try to requeue
-			if !e.requeueEnrichmentJob(ctx, job) {
-				// Failed to requeue, mark as failed
-				if err := e.memoryStore.UpdateStatus(dbCtx, job.MemoryID, types.StatusFailed); err != nil {
-					log.Printf("ERROR: Worker %d failed to mark %s as failed: %v", workerID, job.MemoryID, err)
-				}
-			}
+			slog.Error("entity extraction failed", "worker", workerID, "memory_id", job.MemoryID, "error", err)
+			e.retryOrFail(ctx, dbCtx, workerID, job)
 			return
 		}
 
@@ -108,20 +99,20 @@ func (e *MemoryEngine) processEnrichmentJob(ctx context.Context, workerID int, j
 			enrichmentError = fmt.Sprintf("entity: success, relationship: %s", pipelineResult.RelationshipError)
 		}
 
-		log.Printf("Worker %d pipeline results for %s: Entity=%s, Relationship=%s",
-			workerID, job.MemoryID, entityStatus, relationshipStatus)
+		slog.Debug("pipeline results",
+			"worker", workerID, "memory_id", job.MemoryID, "entity", entityStatus, "relationship", relationshipStatus)
 
 		// Generate vector embedding
-		if embErr := e.enrichmentService.GenerateEmbeddings(ctx, job.MemoryID, job.Content); embErr != nil {
-			log.Printf("Worker %d: WARNING - embedding generation failed for %s: %v", workerID, job.MemoryID, embErr)
+		if embErr := e.generateEmbeddings(ctx, job); embErr != nil {
+			slog.Warn("embedding generation failed", "worker", workerID, "memory_id", job.MemoryID, "error", embErr)
 			embeddingStatus = types.EnrichmentFailed
 		} else {
 			embeddingStatus = types.EnrichmentCompleted
-			log.Printf("Worker %d: embedding generated for %s", workerID, job.MemoryID)
+			slog.Debug("embedding generated", "worker", workerID, "memory_id", job.MemoryID)
 		}
 	} else {
 		// Fallback: simulate enrichment work if service not initialized
-		log.Printf("Warning: Enrichment service not available, skipping LLM enrichment for %s", job.MemoryID)
+		slog.Warn("enrichment service not available, skipping LLM enrichment", "memory_id", job.MemoryID)
 		time.Sleep(100 * time.Millisecond)
 		entityStatus = types.EnrichmentSkipped
 		relationshipStatus = types.EnrichmentSkipped
@@ -130,15 +121,9 @@ func (e *MemoryEngine) processEnrichmentJob(ctx context.Context, workerID int, j
 
 	// Update status to enriched
 	if err := e.memoryStore.UpdateStatus(dbCtx, job.MemoryID, types.StatusEnriched); err != nil {
-		log.Printf("ERROR: Worker %d failed to update status to enriched for %s: %v",
-			workerID, job.MemoryID, err)
-		// Try to requeue the job
-		if !e.requeueEnrichmentJob(ctx, job) {
-			// Failed to requeue, mark as failed
-			if err := e.memoryStore.UpdateStatus(dbCtx, job.MemoryID, types.StatusFailed); err != nil {
-				log.Printf("ERROR: Worker %d failed to mark %s as failed: %v", workerID, job.MemoryID, err)
-			}
-		}
+		slog.Error("failed to update status to enriched",
+			"worker", workerID, "memory_id", job.MemoryID, "error", err)
+		e.retryOrFail(ctx, dbCtx, workerID, job)
 		return
 	}
 
@@ -153,17 +138,74 @@ func (e *MemoryEngine) processEnrichmentJob(ctx context.Context, workerID int, j
 	}
 
 	if err := e.memoryStore.UpdateEnrichment(ctx, job.MemoryID, enrichment); err != nil {
-		log.Printf("WARNING: Worker %d failed to update enrichment metadata for %s: %v",
-			workerID, job.MemoryID, err)
+		slog.Warn("failed to update enrichment metadata",
+			"worker", workerID, "memory_id", job.MemoryID, "error", err)
 	}
 
-	log.Printf("Worker %d completed enrichment for memory %s (Entity=%s, Relationship=%s)",
-		workerID, job.MemoryID, entityStatus, relationshipStatus)
+	slog.Info("enrichment complete",
+		"worker", workerID, "memory_id", job.MemoryID, "entity", entityStatus, "relationship", relationshipStatus)
 
 	// Trigger callback for UI updates (e.g., WebSocket broadcast)
 	if e.onEnrichmentComplete != nil {
 		e.onEnrichmentComplete(job.MemoryID)
 	}
+	e.signalEnrichmentDone(job.MemoryID, types.StatusEnriched)
+
+	e.checkContradictions(dbCtx, workerID, job.MemoryID)
+}
+
+// retryOrFail requeues a job whose enrichment failed. When it cannot be
+// requeued the memory is marked failed and the failure callback fires.
+func (e *MemoryEngine) retryOrFail(ctx, dbCtx context.Context, workerID int, job *EnrichmentJob) {
+	if e.requeueEnrichmentJob(ctx, job) {
+		return
+	}
+	if err := e.memoryStore.UpdateStatus(dbCtx, job.MemoryID, types.StatusFailed); err != nil {
+		slog.Error("failed to mark memory as failed", "worker", workerID, "memory_id", job.MemoryID, "error", err)
+	}
+	if e.onEnrichmentFailed != nil {
+		e.onEnrichmentFailed(job.MemoryID)
+	}
+	e.signalEnrichmentDone(job.MemoryID, types.StatusFailed)
+}
+
+// extract runs the extraction pipeline for job, bounded by
+// Config.EnrichmentStepTimeout.
+func (e *MemoryEngine) extract(ctx context.Context, job *EnrichmentJob) (*ExtractPipelineResult, error) {
+	stepCtx, cancel := withTimeout(ctx, e.config.EnrichmentStepTimeout)
+	defer cancel()
+	result, err := e.enrichmentService.ExtractionPipeline.Extract(stepCtx, job.MemoryID, job.Content)
+	return result, timeoutError(stepCtx, "extraction", e.config.EnrichmentStepTimeout, err)
+}
+
+// generateEmbeddings generates and stores the embedding for job, bounded by
+// Config.EnrichmentStepTimeout.
+func (e *MemoryEngine) generateEmbeddings(ctx context.Context, job *EnrichmentJob) error {
+	stepCtx, cancel := withTimeout(ctx, e.config.EnrichmentStepTimeout)
+	defer cancel()
+	err := e.enrichmentService.GenerateEmbeddings(stepCtx, job.MemoryID, job.Content)
+	return timeoutError(stepCtx, "embedding generation", e.config.EnrichmentStepTimeout, err)
+}
+
+// checkContradictions runs the contradiction detector for a freshly enriched
+// memory and fires the contradiction callback when any are found. It is a
+// no-op unless Config.DetectContradictions is enabled and a callback is set.
+func (e *MemoryEngine) checkContradictions(ctx context.Context, workerID int, memoryID string) {
+	if !e.config.DetectContradictions || e.onContradiction == nil {
+		return
+	}
+
+	found, err := e.contradictions.DetectContradictions(ctx, memoryID)
+	if err != nil {
+		slog.Warn("contradiction detection failed", "worker", workerID, "memory_id", memoryID, "error", err)
+		return
+	}
+	if len(found) == 0 {
+		return
+	}
+
+	slog.Info("contradictions detected", "worker", workerID, "memory_id", memoryID, "count", len(found))
+	e.onContradiction(NewContradictionEvent(memoryID, found))
 }
 
 // startWorkerPool starts the worker goroutines.
@@ -173,7 +215,7 @@ func (e *MemoryEngine) startWorkerPool(ctx context.Context) {
 		go e.enrichmentWorker(ctx, i)
 	}
 
-	log.Printf("Started %d enrichment workers", e.config.NumWorkers)
+	slog.Info("started enrichment workers", "workers", e.config.NumWorkers)
 }
 
 // stopWorkerPool stops the worker goroutines gracefully.
@@ -190,15 +232,15 @@ func (e *MemoryEngine) stopWorkerPool(ctx context.Context) error {
 
 	select {
 	case <-done:
-		log.Println("All enrichment workers finished gracefully")
+		slog.Info("all enrichment workers finished gracefully")
 		return nil
 	case <-time.After(e.config.ShutdownTimeout):
 		remaining := e.getQueueLength()
-		log.Printf("WARNING: Shutdown timeout reached, %d enrichment jobs may be dropped", remaining)
+		slog.Warn("shutdown timeout reached, enrichment jobs may be dropped", "remaining", remaining)
 		return nil
 	case <-ctx.Done():
 		remaining := e.getQueueLength()
-		log.Printf("WARNING: Context cancelled, %d enrichment jobs may be dropped", remaining)
+		slog.Warn("context cancelled, enrichment jobs may be dropped", "remaining", remaining)
 		return ctx.Err()
 	}
 }
@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// startExpirySweeper launches a goroutine that soft-deletes expired memories
+// every ExpirySweepInterval until ctx is cancelled. It runs one sweep
+// immediately so memories that expired while the process was down are
+// cleaned up at startup. The goroutine is tracked by workerWaitGroup so
+// Shutdown waits for an in-flight sweep to finish.
+func (e *MemoryEngine) startExpirySweeper(ctx context.Context) {
+	interval := e.config.ExpirySweepInterval
+	if interval <= 0 {
+		return
+	}
+
+	e.workerWaitGroup.Add(1)
+	go func() {
+		defer e.workerWaitGroup.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			e.sweepExpired(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// sweepExpired soft-deletes all memories whose expires_at has passed.
+func (e *MemoryEngine) sweepExpired(ctx context.Context) {
+	n, err := e.memoryStore.ExpireMemories(ctx, time.Now())
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("expiry sweep failed", "error", err)
+		}
+		return
+	}
+	if n > 0 {
+		slog.Info("expiry sweep soft-deleted expired memories", "count", n)
+	}
+}
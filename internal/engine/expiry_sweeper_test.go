@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// TestEngine_ExpirySweeperSoftDeletesExpiredMemories verifies that the
+// background sweeper soft-deletes memories whose expires_at has passed and
+// leaves unexpired memories alone.
+func TestEngine_ExpirySweeperSoftDeletesExpiredMemories(t *testing.T) {
+	store := createTestStore(t)
+	defer func() { _ = store.Close() }()
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(150 * time.Millisecond)
+	keepUntil := time.Now().Add(time.Hour)
+	for _, m := range []*types.Memory{
+		{ID: "mem:test:short", Content: "short-lived", Source: "test", Status: types.StatusEnriched, ExpiresAt: &expiresAt},
+		{ID: "mem:test:long", Content: "long-lived", Source: "test", Status: types.StatusEnriched, ExpiresAt: &keepUntil},
+	} {
+		if err := store.Store(ctx, m); err != nil {
+			t.Fatalf("Store(%s) failed: %v", m.ID, err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.ExpirySweepInterval = 20 * time.Millisecond
+	eng, err := NewMemoryEngine(store, cfg, nil)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if err := eng.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer func() { _ = eng.Shutdown(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := store.Get(ctx, "mem:test:short")
+		if errors.Is(err, storage.ErrNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expired memory was not swept within 5s (Get error: %v)", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if _, err := store.Get(ctx, "mem:test:long"); err != nil {
+		t.Errorf("unexpired memory was swept: %v", err)
+	}
+}
+
+func TestConfig_ValidateRejectsNegativeExpirySweepInterval(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ExpirySweepInterval = -time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted a negative ExpirySweepInterval")
+	}
+
+	cfg.ExpirySweepInterval = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() rejected a disabled sweeper: %v", err)
+	}
+}
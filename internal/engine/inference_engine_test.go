@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/pkg/types"
@@ -117,7 +118,7 @@ func (m *mockMemoryStore) GetRelatedMemories(ctx context.Context, memoryID strin
 	return []string{}, nil
 }
 
-func (m *mockMemoryStore) Traverse(ctx context.Context, startMemoryID string, maxHops int, limit int) ([]storage.TraversalResult, error) {
+func (m *mockMemoryStore) Traverse(ctx context.Context, startMemoryID string, opts storage.TraversalOptions) ([]storage.TraversalResult, error) {
 	panic("not implemented")
 }
 
@@ -125,10 +126,26 @@ func (m *mockMemoryStore) GetMemoryEntities(ctx context.Context, memoryID string
 	panic("not implemented")
 }
 
+func (m *mockMemoryStore) ListEntities(ctx context.Context, opts storage.EntityListOptions) (*storage.PaginatedResult[types.Entity], error) {
+	panic("not implemented")
+}
+
 func (m *mockMemoryStore) UpdateDecayScores(ctx context.Context) (int, error) {
 	panic("not implemented")
 }
 
+func (m *mockMemoryStore) SetEmbedding(ctx context.Context, id string, vec []float64, model string) error {
+	panic("not implemented")
+}
+
+func (m *mockMemoryStore) GetEmbedding(ctx context.Context, id string) ([]float64, string, error) {
+	panic("not implemented")
+}
+
+func (m *mockMemoryStore) ExpireMemories(ctx context.Context, now time.Time) (int, error) {
+	panic("not implemented")
+}
+
 func (m *mockMemoryStore) Close() error {
 	return nil
 }
@@ -3,8 +3,9 @@ package engine
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/scrypster/memento/internal/config"
@@ -36,6 +37,7 @@ type MemoryEngine struct {
 	inferenceEngine    *InferenceEngine
 	decayManager       *DecayManager
 	confidenceScorer   *ConfidenceScorer
+	contradictions     *ContradictionDetector
 
 	// Enrichment service
 	enrichmentService *EnrichmentService
@@ -49,6 +51,23 @@ type MemoryEngine struct {
 	onMemoryCreated      func(memoryID string)
 	onEnrichmentStarted  func(memoryID string)
 	onEnrichmentComplete func(memoryID string)
+	onEnrichmentFailed   func(memoryID string)
+	onContradiction      func(event ContradictionEvent)
+
+	// Reports whether memories in a domain are enriched (see SetEnrichmentFilter)
+	enrichesDomain func(domain string) bool
+
+	// Callers blocked on a memory's enrichment (see WaitForEnrichment)
+	enrichmentWaiters enrichmentWaiters
+
+	// Jobs a worker is currently processing (see Status)
+	inFlight atomic.Int64
+
+	// Jobs turned away because the queue stayed full (see Status)
+	deferred atomic.Int64
+
+	// Memories with a job queued or in flight (see queuePendingMemories)
+	queued queuedJobs
 }
 
 // NewMemoryEngine creates a new memory engine with the given configuration.
@@ -77,6 +96,7 @@ func NewMemoryEngine(store storage.MemoryStore, engineConfig Config, globalConfi
 	engine.inferenceEngine = NewInferenceEngine(store)
 	engine.decayManager = NewDecayManager()
 	engine.confidenceScorer = NewConfidenceScorer(store)
+	engine.contradictions = NewContradictionDetector(store)
 
 	// Initialize enrichment service with LLM client via factory
 	if globalConfig != nil {
@@ -85,11 +105,15 @@ func NewMemoryEngine(store storage.MemoryStore, engineConfig Config, globalConfi
 		if err != nil {
 			return nil, fmt.Errorf("failed to create LLM client: %w", err)
 		}
+		embeddingCfg := embeddingConfigFromGlobal(globalConfig)
+		if connCfg.Provider == "ollama" || embeddingCfg.Provider == "ollama" {
+			slog.Info("using Ollama endpoint", "url", globalConfig.LLM.OllamaURL, "keep_alive", globalConfig.LLM.OllamaKeepAlive)
+		}
 
 		embeddingModel := globalConfig.LLM.OllamaEmbeddingModel
-		embeddingClient, embErr := llm.NewEmbeddingGenerator(connCfg, embeddingModel)
+		embeddingClient, embErr := llm.NewEmbeddingGenerator(embeddingCfg, embeddingModel)
 		if embErr != nil {
-			log.Printf("warning: failed to create embedding client: %v", embErr)
+			slog.Warn("failed to create embedding client", "error", embErr)
 			embeddingClient = nil
 		}
 
@@ -97,28 +121,58 @@ func NewMemoryEngine(store storage.MemoryStore, engineConfig Config, globalConfi
 		if sqliteStore, ok := store.(*sqlite.MemoryStore); ok {
 			embeddingProvider := sqlite.NewEmbeddingProvider(sqliteStore.GetDB())
 			engine.enrichmentService = NewEnrichmentServiceWithEmbeddings(llmClient, embeddingClient, sqliteStore.GetDB(), embeddingProvider)
-			log.Printf("Enrichment service initialized with provider=%s model=%s", connCfg.Provider, connCfg.Model)
+			engine.enrichmentService.expectedDimension = engineConfig.EmbeddingDimension
+			engine.enrichmentService.ExtractionPipeline.summarizeMinLength = engineConfig.SummarizeMinLength
+			engine.enrichmentService.ExtractionPipeline.useCustomPrompts(engineConfig.EntityPrompt, engineConfig.RelationshipPrompt)
+			slog.Info("enrichment service initialized", "provider", connCfg.Provider, "model", connCfg.Model, "embedding_provider", embeddingCfg.Provider)
 		} else {
-			log.Println("Warning: Enrichment service not initialized (non-SQLite store)")
+			slog.Warn("enrichment service not initialized (non-SQLite store)")
 		}
 	} else {
-		log.Println("Warning: Enrichment service not initialized (no config provided)")
+		slog.Warn("enrichment service not initialized (no config provided)")
 	}
 
 	return engine, nil
 }
 
-// QueueEnrichmentForMemory queues a memory for immediate enrichment.
-// Returns true if the job was queued, false if the queue is full or engine not started.
-func (e *MemoryEngine) QueueEnrichmentForMemory(memoryID, content string) bool {
-	e.mu.RLock()
-	canQueue := e.started && !e.shuttingDown
-	e.mu.RUnlock()
-	if !canQueue {
-		return false
+// DefaultNumWorkers returns the enrichment worker count suited to store.
+// SQLite allows a single writer at a time, so concurrent workers only contend
+// for the database lock; it gets one worker. Stores with row-level locking
+// (e.g. PostgreSQL) get DefaultConfig().NumWorkers, since each job only
+// updates its own memory row.
+func DefaultNumWorkers(store storage.MemoryStore) int {
+	if _, ok := store.(*sqlite.MemoryStore); ok {
+		return 1
 	}
+	return DefaultConfig().NumWorkers
+}
+
+// ApplyWorkerConfig sets NumWorkers from cfg.EnrichmentWorkers
+// (MEMENTO_ENRICHMENT_WORKERS), or to DefaultNumWorkers(store) when that is
+// 0.
+//
+// More than one worker on SQLite is safe: the store keeps a single database
+// connection, so workers overlap their LLM calls but take turns on the
+// database, waiting in the connection pool rather than on the file lock.
+// busy_timeout (MEMENTO_SQLITE_BUSY_TIMEOUT_MS) therefore only comes into
+// play when another process, such as memento-web next to memento-mcp,
+// writes to the same file.
+func (c *Config) ApplyWorkerConfig(cfg config.StorageConfig, store storage.MemoryStore) {
+	if cfg.EnrichmentWorkers > 0 {
+		c.NumWorkers = cfg.EnrichmentWorkers
+		return
+	}
+	c.NumWorkers = DefaultNumWorkers(store)
+}
+
+// QueueEnrichmentForMemory queues a memory for immediate enrichment, waiting
+// up to Config.QueueWait for space when the queue is full.
+// Returns true if the job was queued, false if the queue stayed full or the
+// engine is not started; the memory then stays pending until the next
+// pending rescan queues it.
+func (e *MemoryEngine) QueueEnrichmentForMemory(memoryID, content string) bool {
 	job := e.createEnrichmentJob(memoryID, content, 0)
-	return e.queueEnrichmentJob(job)
+	return e.queueIfRunning(job, e.config.QueueWait)
 }
 
 // QueueEmbeddingForMemory queues a memory for embedding-only processing.
@@ -140,22 +194,57 @@ func (e *MemoryEngine) QueueEmbeddingForMemory(memoryID, content string) bool {
 }
 
 // Embed generates a vector embedding for the given text using the embedding model.
-// Returns an error if no embedding client is configured.
+// Returns an error if no embedding client is configured, an error wrapping
+// ErrTimeout if the call exceeds Config.LLMTimeout, or an error wrapping
+// storage.ErrDimensionMismatch if the vector does not have
+// Config.EmbeddingDimension dimensions.
 func (e *MemoryEngine) Embed(ctx context.Context, text string) ([]float64, error) {
 	if e.enrichmentService == nil {
 		return nil, fmt.Errorf("enrichment service not available")
 	}
-	return e.enrichmentService.Embed(ctx, text)
+	callCtx, cancel := withTimeout(ctx, e.config.LLMTimeout)
+	defer cancel()
+	vec, err := e.enrichmentService.Embed(callCtx, text)
+	if err != nil {
+		return nil, timeoutError(callCtx, "embedding", e.config.LLMTimeout, err)
+	}
+	if err := checkEmbeddingDimension(len(vec), e.config.EmbeddingDimension, e.enrichmentService.embeddingClient.GetModel()); err != nil {
+		return nil, err
+	}
+	return vec, nil
+}
+
+// EmbeddingModel returns the name of the model Embed uses, or "" if no
+// embedding client is configured.
+func (e *MemoryEngine) EmbeddingModel() string {
+	if e.enrichmentService == nil || e.enrichmentService.embeddingClient == nil {
+		return ""
+	}
+	return e.enrichmentService.embeddingClient.GetModel()
+}
+
+// checkEmbeddingDimension returns an error wrapping storage.ErrDimensionMismatch
+// when want is non-zero and got differs from it.
+func checkEmbeddingDimension(got, want int, model string) error {
+	if want == 0 || got == want {
+		return nil
+	}
+	return fmt.Errorf("%w: model %q returned %d dimensions, expected %d (check MEMENTO_EMBEDDING_DIMENSION)",
+		storage.ErrDimensionMismatch, model, got, want)
 }
 
 // Summarize sends a prompt to the LLM and returns the completion text.
 // Used by the MCP consolidate_memories tool for LLM-assisted merging.
-// Returns an error if no LLM client is configured.
+// Returns an error if no LLM client is configured, or an error wrapping
+// ErrTimeout if the call exceeds Config.LLMTimeout.
 func (e *MemoryEngine) Summarize(ctx context.Context, prompt string) (string, error) {
 	if e.enrichmentService == nil {
 		return "", fmt.Errorf("enrichment service not available")
 	}
-	return e.enrichmentService.llmClient.Complete(ctx, prompt)
+	callCtx, cancel := withTimeout(ctx, e.config.LLMTimeout)
+	defer cancel()
+	result, err := e.enrichmentService.llmClient.Complete(callCtx, prompt)
+	return result, timeoutError(callCtx, "summarization", e.config.LLMTimeout, err)
 }
 
 // NewMemoryEngineWithEmbeddings creates a new memory engine with embedding support.
@@ -191,15 +280,18 @@ func NewMemoryEngineWithEmbeddings(store storage.MemoryStore, engineConfig Confi
 	engine.inferenceEngine = NewInferenceEngine(store)
 	engine.decayManager = NewDecayManager()
 	engine.confidenceScorer = NewConfidenceScorer(store)
+	engine.contradictions = NewContradictionDetector(store)
 
 	// Initialize enrichment service with embedding support
 	if sqliteStore, ok := store.(*sqlite.MemoryStore); ok {
 		engine.enrichmentService = NewEnrichmentServiceWithEmbeddings(llmClient, embeddingClient, sqliteStore.GetDB(), embeddingProvider)
-		log.Println("Enrichment service initialized with LLM and embedding support")
+		slog.Info("enrichment service initialized with LLM and embedding support")
 	} else {
-		log.Println("Warning: Enrichment service initialized without embedding support (non-SQLite store)")
+		slog.Warn("enrichment service initialized without embedding support (non-SQLite store)")
 		engine.enrichmentService = NewEnrichmentService(llmClient, nil)
 	}
+	engine.enrichmentService.ExtractionPipeline.summarizeMinLength = engineConfig.SummarizeMinLength
+	engine.enrichmentService.ExtractionPipeline.useCustomPrompts(engineConfig.EntityPrompt, engineConfig.RelationshipPrompt)
 
 	return engine, nil
 }
@@ -226,6 +318,35 @@ func (e *MemoryEngine) SetOnEnrichmentComplete(callback func(memoryID string)) {
 	e.onEnrichmentComplete = callback
 }
 
+// SetOnEnrichmentFailed sets a callback fired when enrichment gives up on a
+// memory and marks it failed (retries exhausted or the job could not be
+// requeued).
+func (e *MemoryEngine) SetOnEnrichmentFailed(callback func(memoryID string)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onEnrichmentFailed = callback
+}
+
+// SetOnContradictionDetected sets a callback fired when a newly enriched memory
+// contradicts existing ones. It is only invoked when Config.DetectContradictions
+// is enabled.
+func (e *MemoryEngine) SetOnContradictionDetected(callback func(event ContradictionEvent)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onContradiction = callback
+}
+
+// SetEnrichmentFilter sets a predicate reporting whether memories in a
+// domain (connection) are enriched. Startup recovery and the pending rescan
+// leave pending memories alone when it returns false, so a connection with
+// enrichment disabled never has jobs queued for it. Call it before Start;
+// a nil filter enriches every domain.
+func (e *MemoryEngine) SetEnrichmentFilter(filter func(domain string) bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.enrichesDomain = filter
+}
+
 // Start starts the memory engine and its worker pool.
 // It also initiates recovery of pending enrichments from previous runs.
 // This must be called before using Store().
@@ -237,7 +358,7 @@ func (e *MemoryEngine) Start(ctx context.Context) error {
 		return fmt.Errorf("engine already started")
 	}
 
-	log.Println("Starting memory engine...")
+	slog.Debug("starting memory engine")
 
 	// Create worker context
 	e.workerCtx, e.workerCancel = context.WithCancel(ctx)
@@ -245,16 +366,28 @@ func (e *MemoryEngine) Start(ctx context.Context) error {
 	// Start worker pool
 	e.startWorkerPool(e.workerCtx)
 
+	// Start expiry sweeper
+	e.startExpirySweeper(e.workerCtx)
+
+	// Start auto-archiver (no-op unless AutoArchive.Interval is set)
+	e.startAutoArchiver(e.workerCtx)
+
+	// Start digester (no-op unless DigestInterval is set)
+	e.startDigester(e.workerCtx)
+
+	// Re-queue pending memories periodically (no-op unless PendingRescanInterval is set)
+	e.startPendingRescanner(e.workerCtx)
+
 	// Recover pending enrichments in background
 	// (non-blocking so Start() returns quickly)
 	go func() {
 		if err := e.RecoverPendingEnrichments(ctx); err != nil {
-			log.Printf("ERROR: Enrichment recovery failed: %v", err)
+			slog.Error("enrichment recovery failed", "error", err)
 		}
 	}()
 
 	e.started = true
-	log.Println("Memory engine started successfully")
+	slog.Info("memory engine started")
 
 	return nil
 }
@@ -305,7 +438,7 @@ func (e *MemoryEngine) Store(ctx context.Context, content string) (*types.Memory
 	if !e.queueEnrichmentJob(job) {
 		// Queue is full - mark as failed for manual retry
 		if err := e.memoryStore.UpdateStatus(ctx, memory.ID, types.StatusFailed); err != nil {
-			log.Printf("ERROR: Failed to mark memory %s as failed: %v", memory.ID, err)
+			slog.Error("failed to mark memory as failed", "memory_id", memory.ID, "error", err)
 		}
 		return memory, fmt.Errorf("enrichment queue full, memory stored but not queued")
 	}
@@ -334,7 +467,7 @@ func (e *MemoryEngine) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("engine not started")
 	}
 
-	log.Println("Shutting down memory engine...")
+	slog.Info("shutting down memory engine")
 
 	// Mark as shutting down (prevents requeueing)
 	e.shuttingDown = true
@@ -346,12 +479,12 @@ func (e *MemoryEngine) Shutdown(ctx context.Context) error {
 
 	// Stop worker pool gracefully
 	if err := e.stopWorkerPool(ctx); err != nil {
-		log.Printf("WARNING: Worker pool shutdown had errors: %v", err)
+		slog.Warn("worker pool shutdown had errors", "error", err)
 	}
 
 	e.started = false
 	e.shuttingDown = false
-	log.Println("Memory engine shut down successfully")
+	slog.Info("memory engine shut down")
 
 	return nil
 }
@@ -475,9 +608,27 @@ func llmConfigFromGlobal(cfg *config.Config) connections.LLMConfig {
 		}
 	default:
 		return connections.LLMConfig{
-			Provider: "ollama",
-			BaseURL:  cfg.LLM.OllamaURL,
-			Model:    cfg.LLM.OllamaModel,
+			Provider:  "ollama",
+			BaseURL:   cfg.LLM.OllamaURL,
+			Model:     cfg.LLM.OllamaModel,
+			KeepAlive: cfg.LLM.OllamaKeepAlive,
 		}
 	}
 }
+
+// embeddingConfigFromGlobal maps the global application config to the
+// connections.LLMConfig of the embedding provider, which may differ from
+// the text provider (see config.LLMConfig.EmbeddingProviderName).
+func embeddingConfigFromGlobal(cfg *config.Config) connections.LLMConfig {
+	if cfg.LLM.EmbeddingProviderName() == "openai" {
+		return connections.LLMConfig{
+			Provider: "openai",
+			APIKey:   cfg.LLM.OpenAIAPIKey,
+		}
+	}
+	return connections.LLMConfig{
+		Provider:  "ollama",
+		BaseURL:   cfg.LLM.OllamaURL,
+		KeepAlive: cfg.LLM.OllamaKeepAlive,
+	}
+}
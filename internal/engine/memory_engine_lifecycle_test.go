@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/llm"
 	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/internal/storage/sqlite"
 	"github.com/scrypster/memento/pkg/types"
@@ -563,3 +565,46 @@ func TestEngine_NoStoreProvided(t *testing.T) {
 		t.Errorf("Expected error 'memory store is required', got: %v", err)
 	}
 }
+
+// TestNewMemoryEngine_AnthropicWithLocalEmbedder verifies that with the
+// Anthropic provider, extraction and summarization use Claude while
+// embeddings come from Ollama, or from the provider chosen separately.
+func TestNewMemoryEngine_AnthropicWithLocalEmbedder(t *testing.T) {
+	store := createTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	cfg := &config.Config{LLM: config.LLMConfig{
+		LLMProvider:          "anthropic",
+		AnthropicAPIKey:      "sk-ant-test",
+		AnthropicModel:       "claude-haiku-4-5",
+		OllamaURL:            "http://localhost:11434",
+		OllamaEmbeddingModel: "nomic-embed-text",
+	}}
+	engine, err := NewMemoryEngine(store, DefaultConfig(), cfg)
+	if err != nil {
+		t.Fatalf("NewMemoryEngine() failed: %v", err)
+	}
+	if _, ok := engine.enrichmentService.llmClient.(*llm.AnthropicClient); !ok {
+		t.Errorf("expected an Anthropic text client, got %T", engine.enrichmentService.llmClient)
+	}
+	if _, ok := engine.enrichmentService.embeddingClient.(*llm.OllamaClient); !ok {
+		t.Errorf("expected an Ollama embedding client, got %T", engine.enrichmentService.embeddingClient)
+	}
+	if got := engine.EmbeddingModel(); got != "nomic-embed-text" {
+		t.Errorf("EmbeddingModel() = %q, want nomic-embed-text", got)
+	}
+
+	// Without a configured model, OpenAI gets its own default, not Ollama's.
+	cfg.LLM.EmbeddingProvider = "openai"
+	cfg.LLM.OllamaEmbeddingModel = ""
+	engine, err = NewMemoryEngine(store, DefaultConfig(), cfg)
+	if err != nil {
+		t.Fatalf("NewMemoryEngine() failed: %v", err)
+	}
+	if _, ok := engine.enrichmentService.embeddingClient.(*llm.OpenAIEmbeddingClient); !ok {
+		t.Errorf("expected an OpenAI embedding client, got %T", engine.enrichmentService.embeddingClient)
+	}
+	if got := engine.EmbeddingModel(); got != "text-embedding-3-small" {
+		t.Errorf("EmbeddingModel() = %q, want text-embedding-3-small", got)
+	}
+}
@@ -121,7 +121,7 @@ func (m *mockListStore) GetRelatedMemories(ctx context.Context, memoryID string)
 	panic("not implemented")
 }
 
-func (m *mockListStore) Traverse(ctx context.Context, startMemoryID string, maxHops int, limit int) ([]storage.TraversalResult, error) {
+func (m *mockListStore) Traverse(ctx context.Context, startMemoryID string, opts storage.TraversalOptions) ([]storage.TraversalResult, error) {
 	panic("not implemented")
 }
 
@@ -129,10 +129,26 @@ func (m *mockListStore) GetMemoryEntities(ctx context.Context, memoryID string)
 	panic("not implemented")
 }
 
+func (m *mockListStore) ListEntities(ctx context.Context, opts storage.EntityListOptions) (*storage.PaginatedResult[types.Entity], error) {
+	panic("not implemented")
+}
+
 func (m *mockListStore) UpdateDecayScores(ctx context.Context) (int, error) {
 	panic("not implemented")
 }
 
+func (m *mockListStore) SetEmbedding(ctx context.Context, id string, vec []float64, model string) error {
+	panic("not implemented")
+}
+
+func (m *mockListStore) GetEmbedding(ctx context.Context, id string) ([]float64, string, error) {
+	panic("not implemented")
+}
+
+func (m *mockListStore) ExpireMemories(ctx context.Context, now time.Time) (int, error) {
+	panic("not implemented")
+}
+
 func (m *mockListStore) Close() error {
 	panic("not implemented")
 }
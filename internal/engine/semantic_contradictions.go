@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/llm"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// SummarizeFunc sends a prompt to the LLM and returns its raw text response,
+// e.g. MemoryEngine.Summarize.
+type SummarizeFunc func(ctx context.Context, prompt string) (string, error)
+
+// DetectSemanticContradictions asks the LLM whether any of candidates directly
+// contradicts memory, in a single call. Candidates are typically the memory's
+// nearest neighbours from hybrid search; the memory itself and deleted
+// candidates are skipped. Each reported contradiction pairs memory with one
+// candidate and carries the LLM's confidence and explanation. IDs the LLM
+// invents are ignored.
+func DetectSemanticContradictions(ctx context.Context, memory *types.Memory, candidates []types.Memory, summarize SummarizeFunc) ([]Contradiction, error) {
+	prompts := make([]llm.ContradictionCandidate, 0, len(candidates))
+	known := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		if c.ID == memory.ID || c.DeletedAt != nil || known[c.ID] {
+			continue
+		}
+		known[c.ID] = true
+		prompts = append(prompts, llm.ContradictionCandidate{ID: c.ID, Content: c.EnrichmentContent()})
+	}
+	if len(prompts) == 0 {
+		return nil, nil
+	}
+
+	response, err := summarize(ctx, llm.ContradictionCheckPrompt(memory.EnrichmentContent(), prompts))
+	if err != nil {
+		return nil, fmt.Errorf("semantic contradiction check failed: %w", err)
+	}
+	reported, err := llm.ParseContradictionCheckResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	var contradictions []Contradiction
+	for _, r := range reported {
+		if !known[r.ID] {
+			continue
+		}
+		known[r.ID] = false // report each pair once
+		description := r.Explanation
+		if description == "" {
+			description = fmt.Sprintf("Memory %s contradicts memory %s", memory.ID, r.ID)
+		}
+		contradictions = append(contradictions, Contradiction{
+			Type:        ContradictionTypeSemantic,
+			MemoryIDs:   []string{memory.ID, r.ID},
+			Description: description,
+			Confidence:  r.Confidence,
+		})
+	}
+	return contradictions, nil
+}
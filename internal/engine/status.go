@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// Reachability of an LLM backend as reported in EngineStatus.
+const (
+	BackendReachable     = "reachable"
+	BackendUnreachable   = "unreachable"
+	BackendUnchecked     = "unchecked"      // configured, but the client has no health check
+	BackendNotConfigured = "not_configured" // no client, e.g. enrichment service unavailable
+)
+
+// BackendStatus describes one LLM backend used by the engine.
+type BackendStatus struct {
+	Model string `json:"model,omitempty"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
+}
+
+// EngineStatus is a snapshot of the enrichment pipeline's health, shared by
+// the web UI and the MCP get_engine_status tool.
+type EngineStatus struct {
+	Started       bool `json:"started"`
+	Workers       int  `json:"workers"`
+	QueueDepth    int  `json:"queue_depth"`    // jobs waiting in the queue
+	QueueCapacity int  `json:"queue_capacity"` // queue buffer size; new jobs are dropped when full
+	InFlight      int  `json:"in_flight"`      // jobs a worker is processing right now
+
+	// QueueUtilization is QueueDepth / QueueCapacity, from 0 (empty) to 1
+	// (full, so new jobs are deferred).
+	QueueUtilization float64 `json:"queue_utilization"`
+
+	// Deferred counts jobs turned away since the engine was created because
+	// the queue stayed full. Their memories stay pending until the pending
+	// rescan or the next startup recovery queues them.
+	Deferred int64 `json:"deferred"`
+
+	Embedder   BackendStatus `json:"embedder"`
+	Summarizer BackendStatus `json:"summarizer"`
+
+	// StatusCounts counts the engine store's memories by enrichment status.
+	StatusCounts map[types.MemoryStatus]int `json:"status_counts"`
+}
+
+// healthChecker is implemented by LLM clients that can cheaply verify their
+// backend is reachable (e.g. llm.OllamaClient).
+type healthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Status reports queue depth, in-flight jobs, worker count, LLM backend
+// reachability and memory counts by enrichment status. Backends are probed
+// with their health check, bounded by Config.LLMTimeout.
+func (e *MemoryEngine) Status(ctx context.Context) (*EngineStatus, error) {
+	e.mu.RLock()
+	status := &EngineStatus{
+		Started:       e.started && !e.shuttingDown,
+		Workers:       e.config.NumWorkers,
+		QueueDepth:    len(e.enrichmentQueue),
+		QueueCapacity: cap(e.enrichmentQueue),
+	}
+	e.mu.RUnlock()
+	status.InFlight = int(e.inFlight.Load())
+	status.Deferred = e.deferred.Load()
+	if status.QueueCapacity > 0 {
+		status.QueueUtilization = float64(status.QueueDepth) / float64(status.QueueCapacity)
+	}
+
+	status.Embedder = BackendStatus{State: BackendNotConfigured}
+	status.Summarizer = BackendStatus{State: BackendNotConfigured}
+	if e.enrichmentService != nil {
+		if client := e.enrichmentService.embeddingClient; client != nil {
+			status.Embedder = e.backendStatus(ctx, client.GetModel(), client)
+		}
+		if client := e.enrichmentService.llmClient; client != nil {
+			status.Summarizer = e.backendStatus(ctx, client.GetModel(), client)
+		}
+	}
+
+	status.StatusCounts = make(map[types.MemoryStatus]int)
+	for _, s := range []types.MemoryStatus{types.StatusPending, types.StatusProcessing, types.StatusEnriched, types.StatusFailed} {
+		result, err := e.memoryStore.List(ctx, storage.ListOptions{Limit: 1, Filter: map[string]interface{}{"status": s}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s memories: %w", s, err)
+		}
+		status.StatusCounts[s] = result.Total
+	}
+	return status, nil
+}
+
+// backendStatus probes client when it supports a health check.
+func (e *MemoryEngine) backendStatus(ctx context.Context, model string, client interface{}) BackendStatus {
+	checker, ok := client.(healthChecker)
+	if !ok {
+		return BackendStatus{Model: model, State: BackendUnchecked}
+	}
+	callCtx, cancel := withTimeout(ctx, e.config.LLMTimeout)
+	defer cancel()
+	if err := checker.HealthCheck(callCtx); err != nil {
+		return BackendStatus{Model: model, State: BackendUnreachable, Error: err.Error()}
+	}
+	return BackendStatus{Model: model, State: BackendReachable}
+}
@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus_ReportsQueueAndStatusCounts(t *testing.T) {
+	eng := newTestEngine(t)
+	ctx := context.Background()
+
+	for id, status := range map[string]types.MemoryStatus{
+		"mem:general:p1": types.StatusPending,
+		"mem:general:p2": types.StatusPending,
+		"mem:general:e1": types.StatusEnriched,
+		"mem:general:f1": types.StatusFailed,
+	} {
+		require.NoError(t, eng.memoryStore.Store(ctx, &types.Memory{ID: id, Content: id, Source: "test", Status: status}))
+	}
+
+	status, err := eng.Status(ctx)
+	require.NoError(t, err)
+	assert.False(t, status.Started)
+	assert.Equal(t, 1, status.Workers)
+	assert.Equal(t, eng.config.QueueSize, status.QueueCapacity)
+	assert.Equal(t, 0, status.QueueDepth)
+	assert.Equal(t, 0, status.InFlight)
+	assert.Equal(t, BackendNotConfigured, status.Embedder.State)
+	assert.Equal(t, BackendNotConfigured, status.Summarizer.State)
+	assert.Equal(t, map[types.MemoryStatus]int{
+		types.StatusPending:    2,
+		types.StatusProcessing: 0,
+		types.StatusEnriched:   1,
+		types.StatusFailed:     1,
+	}, status.StatusCounts)
+}
+
+func TestStatus_StartedAfterStart(t *testing.T) {
+	eng := newTestEngine(t)
+	ctx := context.Background()
+	require.NoError(t, eng.Start(ctx))
+	defer func() { _ = eng.Shutdown(ctx) }()
+
+	status, err := eng.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, status.Started)
+	assert.Equal(t, 0, status.InFlight)
+}
+
+func TestStatus_ReportsUtilizationAndDeferredJobs(t *testing.T) {
+	eng := newStalledEngine(t, 4)
+	ctx := context.Background()
+
+	for _, id := range []string{"mem:general:a", "mem:general:b", "mem:general:c"} {
+		require.True(t, eng.QueueEnrichmentForMemory(id, id))
+	}
+	status, err := eng.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, status.QueueDepth)
+	assert.InDelta(t, 0.75, status.QueueUtilization, 1e-9)
+	assert.Zero(t, status.Deferred)
+
+	require.True(t, eng.QueueEnrichmentForMemory("mem:general:d", "d"))
+	assert.False(t, eng.QueueEnrichmentForMemory("mem:general:e", "e"))
+	assert.False(t, eng.QueueEnrichmentForMemory("mem:general:f", "f"))
+	status, err = eng.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, status.QueueDepth)
+	assert.InDelta(t, 1.0, status.QueueUtilization, 1e-9)
+	assert.Equal(t, int64(2), status.Deferred)
+}
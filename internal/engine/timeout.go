@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/config"
+)
+
+// ErrTimeout is returned when an LLM call or enrichment step runs longer than
+// its configured timeout (see Config.LLMTimeout and
+// Config.EnrichmentStepTimeout). Callers can test for it with errors.Is.
+var ErrTimeout = errors.New("operation timed out")
+
+// ApplyLLMConfig sets LLMTimeout from cfg (MEMENTO_LLM_TIMEOUT).
+func (c *Config) ApplyLLMConfig(cfg config.LLMConfig) error {
+	timeout, err := time.ParseDuration(cfg.LLMTimeout)
+	if err != nil || timeout < 0 {
+		return fmt.Errorf("invalid MEMENTO_LLM_TIMEOUT %q: must be a non-negative duration", cfg.LLMTimeout)
+	}
+	c.LLMTimeout = timeout
+	return nil
+}
+
+// withTimeout derives a context bounded by d. A non-positive d disables the
+// bound and returns ctx unchanged (with a no-op cancel).
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// timeoutError replaces err with an ErrTimeout-wrapping error when the
+// bounded context stepCtx hit its deadline. Errors caused by cancellation of
+// the parent context, or by the operation itself, are returned unchanged.
+func timeoutError(stepCtx context.Context, op string, d time.Duration, err error) error {
+	if err == nil || d <= 0 || !errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%s exceeded %v: %w", op, d, ErrTimeout)
+}
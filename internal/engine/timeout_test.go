@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/config"
+)
+
+// stallingLLMClient blocks every call until ctx is done, simulating a stalled
+// LLM backend (e.g. Ollama hung on model load).
+type stallingLLMClient struct{}
+
+func (stallingLLMClient) Complete(ctx context.Context, _ string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (stallingLLMClient) Embed(ctx context.Context, _ string) ([]float32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (stallingLLMClient) GetModel() string { return "stalling" }
+
+// newStallingEngine returns an engine whose LLM and embedding clients never
+// answer, with the given timeouts applied.
+func newStallingEngine(llmTimeout, stepTimeout time.Duration) *MemoryEngine {
+	cfg := DefaultConfig()
+	cfg.LLMTimeout = llmTimeout
+	cfg.EnrichmentStepTimeout = stepTimeout
+	return &MemoryEngine{
+		config: cfg,
+		enrichmentService: &EnrichmentService{
+			llmClient:       stallingLLMClient{},
+			embeddingClient: stallingLLMClient{},
+		},
+	}
+}
+
+func TestSummarize_TimesOut(t *testing.T) {
+	e := newStallingEngine(20*time.Millisecond, 0)
+
+	_, err := e.Summarize(context.Background(), "prompt")
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Summarize() error = %v, want ErrTimeout", err)
+	}
+}
+
+func TestEmbed_TimesOut(t *testing.T) {
+	e := newStallingEngine(20*time.Millisecond, 0)
+
+	_, err := e.Embed(context.Background(), "text")
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Embed() error = %v, want ErrTimeout", err)
+	}
+}
+
+// TestSummarize_ParentCancellationIsNotTimeout verifies that cancellation by
+// the caller is reported as-is rather than as ErrTimeout.
+func TestSummarize_ParentCancellationIsNotTimeout(t *testing.T) {
+	e := newStallingEngine(time.Minute, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err := e.Summarize(ctx, "prompt")
+	if errors.Is(err, ErrTimeout) {
+		t.Fatalf("Summarize() error = %v, want plain cancellation", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Summarize() error = %v, want context.Canceled", err)
+	}
+}
+
+// TestExtract_StepTimeout verifies that a stuck extraction step is bounded by
+// EnrichmentStepTimeout so it cannot wedge the worker.
+func TestExtract_StepTimeout(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	e := newStallingEngine(0, 20*time.Millisecond)
+	e.enrichmentService.ExtractionPipeline = NewExtractionPipeline(stallingLLMClient{}, db)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := e.extract(context.Background(), &EnrichmentJob{MemoryID: "mem:test:stuck", Content: "content"})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrTimeout) {
+			t.Fatalf("extract() error = %v, want ErrTimeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("extract() did not return; step timeout not applied")
+	}
+}
+
+func TestConfigValidate_NegativeTimeouts(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LLMTimeout = -time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted negative LLMTimeout")
+	}
+
+	cfg = DefaultConfig()
+	cfg.EnrichmentStepTimeout = -time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted negative EnrichmentStepTimeout")
+	}
+}
+
+func TestApplyLLMConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.ApplyLLMConfig(config.LLMConfig{LLMTimeout: "90s"}); err != nil {
+		t.Fatalf("ApplyLLMConfig() error = %v", err)
+	}
+	if cfg.LLMTimeout != 90*time.Second {
+		t.Errorf("LLMTimeout = %v, want 90s", cfg.LLMTimeout)
+	}
+	if err := cfg.ApplyLLMConfig(config.LLMConfig{LLMTimeout: "0"}); err != nil || cfg.LLMTimeout != 0 {
+		t.Errorf("ApplyLLMConfig(0) = %v, LLMTimeout %v; want the bound disabled", err, cfg.LLMTimeout)
+	}
+	for _, bad := range []string{"", "soon", "-1s"} {
+		if err := cfg.ApplyLLMConfig(config.LLMConfig{LLMTimeout: bad}); err == nil {
+			t.Errorf("ApplyLLMConfig(%q) accepted an invalid timeout", bad)
+		}
+	}
+}
@@ -39,6 +39,16 @@ type Config struct {
 	// QueueSize is the size of the enrichment job queue buffer (default: 1000).
 	QueueSize int
 
+	// QueueWait is how long QueueEnrichmentForMemory waits for space in a
+	// full queue before giving up (default: 0, no wait). A job that is not
+	// queued leaves its memory pending for the pending rescan.
+	QueueWait time.Duration
+
+	// PendingRescanInterval is how often the engine re-queues pending
+	// memories that are not already queued, e.g. because the queue was full
+	// when they were stored (default: 5m). Zero limits the scan to startup.
+	PendingRescanInterval time.Duration
+
 	// ShutdownTimeout is the maximum time to wait for workers to drain on shutdown (default: 30s).
 	ShutdownTimeout time.Duration
 
@@ -47,8 +57,85 @@ type Config struct {
 
 	// RecoveryBatchSize is the number of pending memories to recover per batch (default: 1000).
 	RecoveryBatchSize int
+
+	// DetectContradictions runs the ContradictionDetector after each memory is
+	// enriched and fires the contradiction callback when any are found
+	// (default: false). Each check scans the store, so it is opt-in.
+	DetectContradictions bool
+
+	// LLMTimeout bounds each Embed and Summarize call (default: 30s). A call
+	// that exceeds it fails with ErrTimeout instead of blocking the caller
+	// (e.g. an MCP request) while the LLM backend is stalled. Zero disables it.
+	LLMTimeout time.Duration
+
+	// EnrichmentStepTimeout bounds each step of an enrichment job — the
+	// extraction pipeline and embedding generation — so one stuck job cannot
+	// wedge a worker (default: 2m). Zero disables it.
+	EnrichmentStepTimeout time.Duration
+
+	// ExpirySweepInterval is how often the engine soft-deletes memories whose
+	// expires_at has passed (default: 1m). Zero disables the sweeper; expired
+	// memories are still hidden from List and search.
+	ExpirySweepInterval time.Duration
+
+	// EmbeddingDimension is the dimension every embedding must have (default:
+	// 0, no check). When set, query embeddings and embeddings generated during
+	// enrichment with a different dimension are rejected with
+	// storage.ErrDimensionMismatch, so a misconfigured model never mixes
+	// incompatible vectors into the store.
+	EmbeddingDimension int
+
+	// SummarizeMinLength is the content length, in characters, from which
+	// enrichment generates a summary (default: DefaultSummarizeMinLength).
+	// Shorter memories are already short enough to preview, so their
+	// summarization is marked skipped. Zero summarizes every memory.
+	SummarizeMinLength int
+
+	// EntityPrompt and RelationshipPrompt replace the built-in entity and
+	// relationship extraction prompts (default: "", built-in). They must be
+	// valid per types.ValidateEntityPrompt and
+	// types.ValidateRelationshipPrompt. A prompt whose responses fail to
+	// parse CustomPromptMaxFailures times in a row is abandoned for the
+	// built-in one.
+	EntityPrompt       string
+	RelationshipPrompt string
+
+	// AutoArchive moves stale memories to the archived state in the
+	// background (default: disabled). See AutoArchivePolicy.
+	AutoArchive AutoArchivePolicy
+
+	// DigestInterval makes the engine store a digest of the memories
+	// created during each interval (default: 0, disabled). See
+	// GenerateDigest.
+	DigestInterval time.Duration
+}
+
+// AutoArchivePolicy selects which memories the engine archives automatically.
+// A memory is archived when its decay score is below MaxDecayScore, it has
+// not been accessed for StaleAfter, and it has been accessed at most
+// MaxAccessCount times. Memories tagged types.PinnedTag are never archived.
+type AutoArchivePolicy struct {
+	// Interval is how often the policy runs. Zero disables auto-archival.
+	Interval time.Duration
+
+	// MaxDecayScore is the decay score below which a memory may be archived.
+	MaxDecayScore float64
+
+	// StaleAfter is how long a memory must go unaccessed before it may be
+	// archived.
+	StaleAfter time.Duration
+
+	// MaxAccessCount is the most accesses an archivable memory may have.
+	// Negative disables the check.
+	MaxAccessCount int
+
+	// DryRun logs the memories that would be archived without changing them.
+	DryRun bool
 }
 
+// DefaultSummarizeMinLength is the default Config.SummarizeMinLength.
+const DefaultSummarizeMinLength = 500
+
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
@@ -57,6 +144,14 @@ func DefaultConfig() Config {
 		ShutdownTimeout:   30 * time.Second,
 		MaxRetries:        3,
 		RecoveryBatchSize: 1000,
+
+		PendingRescanInterval: 5 * time.Minute,
+
+		LLMTimeout:            30 * time.Second,
+		EnrichmentStepTimeout: 2 * time.Minute,
+		ExpirySweepInterval:   time.Minute,
+
+		SummarizeMinLength: DefaultSummarizeMinLength,
 	}
 }
 
@@ -70,6 +165,14 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("QueueSize must be >= 1, got %d", c.QueueSize)
 	}
 
+	if c.QueueWait < 0 {
+		return fmt.Errorf("QueueWait must be >= 0, got %v", c.QueueWait)
+	}
+
+	if c.PendingRescanInterval < 0 {
+		return fmt.Errorf("PendingRescanInterval must be >= 0, got %v", c.PendingRescanInterval)
+	}
+
 	if c.ShutdownTimeout < 0 {
 		return fmt.Errorf("ShutdownTimeout must be >= 0, got %v", c.ShutdownTimeout)
 	}
@@ -82,6 +185,34 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("RecoveryBatchSize must be >= 1, got %d", c.RecoveryBatchSize)
 	}
 
+	if c.LLMTimeout < 0 {
+		return fmt.Errorf("LLMTimeout must be >= 0, got %v", c.LLMTimeout)
+	}
+
+	if c.EnrichmentStepTimeout < 0 {
+		return fmt.Errorf("EnrichmentStepTimeout must be >= 0, got %v", c.EnrichmentStepTimeout)
+	}
+
+	if c.ExpirySweepInterval < 0 {
+		return fmt.Errorf("ExpirySweepInterval must be >= 0, got %v", c.ExpirySweepInterval)
+	}
+
+	if c.AutoArchive.Interval < 0 {
+		return fmt.Errorf("AutoArchive.Interval must be >= 0, got %v", c.AutoArchive.Interval)
+	}
+
+	if c.AutoArchive.StaleAfter < 0 {
+		return fmt.Errorf("AutoArchive.StaleAfter must be >= 0, got %v", c.AutoArchive.StaleAfter)
+	}
+
+	if c.AutoArchive.MaxDecayScore < 0 || c.AutoArchive.MaxDecayScore > 1 {
+		return fmt.Errorf("AutoArchive.MaxDecayScore must be between 0 and 1, got %v", c.AutoArchive.MaxDecayScore)
+	}
+
+	if c.EmbeddingDimension < 0 {
+		return fmt.Errorf("EmbeddingDimension must be >= 0, got %d", c.EmbeddingDimension)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/config"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/internal/storage/postgres"
+	"github.com/scrypster/memento/internal/storage/sqlite"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func TestDefaultNumWorkers_SQLiteUsesSingleWorker(t *testing.T) {
+	store := createTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	if got := DefaultNumWorkers(store); got != 1 {
+		t.Errorf("DefaultNumWorkers(sqlite) = %d, want 1", got)
+	}
+}
+
+func TestDefaultNumWorkers_OtherStoresUseDefault(t *testing.T) {
+	store := &postgres.MemoryStore{}
+
+	if got, want := DefaultNumWorkers(store), DefaultConfig().NumWorkers; got != want {
+		t.Errorf("DefaultNumWorkers(postgres) = %d, want %d", got, want)
+	}
+}
+
+func TestApplyWorkerConfig_BackendDefault(t *testing.T) {
+	sqliteStore := createTestStore(t)
+	defer func() { _ = sqliteStore.Close() }()
+
+	for _, tc := range []struct {
+		name  string
+		store storage.MemoryStore
+		want  int
+	}{
+		{"sqlite", sqliteStore, 1},
+		{"postgres", &postgres.MemoryStore{}, DefaultConfig().NumWorkers},
+	} {
+		cfg := DefaultConfig()
+		cfg.ApplyWorkerConfig(config.StorageConfig{}, tc.store)
+		if cfg.NumWorkers != tc.want {
+			t.Errorf("%s: NumWorkers = %d, want %d", tc.name, cfg.NumWorkers, tc.want)
+		}
+	}
+}
+
+func TestApplyWorkerConfig_ExplicitOverride(t *testing.T) {
+	sqliteStore := createTestStore(t)
+	defer func() { _ = sqliteStore.Close() }()
+
+	for _, store := range []storage.MemoryStore{sqliteStore, &postgres.MemoryStore{}} {
+		cfg := DefaultConfig()
+		cfg.ApplyWorkerConfig(config.StorageConfig{EnrichmentWorkers: 6}, store)
+		if cfg.NumWorkers != 6 {
+			t.Errorf("%T: NumWorkers = %d, want the configured 6", store, cfg.NumWorkers)
+		}
+	}
+}
+
+// TestEngine_SQLiteMultipleWorkers runs several enrichment workers against a
+// SQLite file and verifies every memory is enriched: the store's single
+// connection serializes their writes, so none fails with "database is
+// locked".
+func TestEngine_SQLiteMultipleWorkers(t *testing.T) {
+	store, err := sqlite.NewMemoryStore(filepath.Join(t.TempDir(), "memento.db"))
+	if err != nil {
+		t.Fatalf("NewMemoryStore() failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	cfg := DefaultConfig()
+	cfg.ApplyWorkerConfig(config.StorageConfig{EnrichmentWorkers: 4}, store)
+	eng, err := NewMemoryEngine(store, cfg, nil)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := eng.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer func() { _ = eng.Shutdown(ctx) }()
+
+	const numMemories = 40
+	ids := make([]string, 0, numMemories)
+	for i := 0; i < numMemories; i++ {
+		mem, err := eng.Store(ctx, fmt.Sprintf("sqlite multi-worker memory %d", i))
+		if err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+		ids = append(ids, mem.ID)
+	}
+
+	deadline := time.Now().Add(30 * time.Second)
+	for _, id := range ids {
+		for {
+			mem, err := store.Get(ctx, id)
+			if err != nil {
+				t.Fatalf("Get(%s) failed: %v", id, err)
+			}
+			if mem.Status == types.StatusEnriched {
+				break
+			}
+			if mem.Status == types.StatusFailed {
+				t.Fatalf("memory %s failed enrichment", id)
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("memory %s still %s after 30s", id, mem.Status)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+}
+
+// TestEngine_PostgresMultipleWorkers runs several enrichment workers against
+// a PostgreSQL store and verifies every memory reaches StatusEnriched without
+// the workers deadlocking on each other's status updates.
+func TestEngine_PostgresMultipleWorkers(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set; skipping PostgreSQL integration tests")
+	}
+
+	store, err := postgres.NewMemoryStore(dsn)
+	if err != nil {
+		t.Fatalf("NewMemoryStore() failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	cfg := DefaultConfig()
+	cfg.NumWorkers = 8
+	eng, err := NewMemoryEngine(store, cfg, nil)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := eng.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer func() { _ = eng.Shutdown(ctx) }()
+
+	const numMemories = 50
+	ids := make([]string, 0, numMemories)
+	for i := 0; i < numMemories; i++ {
+		mem, err := eng.Store(ctx, fmt.Sprintf("multi-worker memory %d", i))
+		if err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+		ids = append(ids, mem.ID)
+	}
+
+	deadline := time.Now().Add(30 * time.Second)
+	for _, id := range ids {
+		for {
+			mem, err := store.Get(ctx, id)
+			if err != nil {
+				t.Fatalf("Get(%s) failed: %v", id, err)
+			}
+			if mem.Status == types.StatusEnriched {
+				break
+			}
+			if mem.Status == types.StatusFailed {
+				t.Fatalf("memory %s failed enrichment", id)
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("memory %s still %s after 30s; workers may be deadlocked", id, mem.Status)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+
+	for _, id := range ids {
+		_ = store.Delete(ctx, id)
+	}
+}
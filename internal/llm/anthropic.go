@@ -7,17 +7,31 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // AnthropicConfig holds configuration for the Anthropic client.
 type AnthropicConfig struct {
-	APIKey  string
-	Model   string        // default: claude-haiku-4-5-20251001
-	Timeout time.Duration // default: 60s
+	APIKey     string
+	Model      string        // default: claude-haiku-4-5-20251001
+	BaseURL    string        // default: https://api.anthropic.com
+	Timeout    time.Duration // default: 60s, per attempt
+	MaxRetries int           // Retries of rate-limited or overloaded requests (default: 3; negative disables)
 }
 
+// Delays between retries of a rate-limited or overloaded request when the
+// response has no retry-after header: anthropicRetryBaseDelay, doubling
+// each attempt. No wait, with or without the header, exceeds
+// anthropicMaxRetryDelay.
+var (
+	anthropicRetryBaseDelay = time.Second
+	anthropicMaxRetryDelay  = 30 * time.Second
+)
+
 // AnthropicClient implements TextGenerator using the Anthropic Messages API.
 type AnthropicClient struct {
 	cfg            AnthropicConfig
@@ -30,9 +44,16 @@ func NewAnthropicClient(cfg AnthropicConfig) *AnthropicClient {
 	if cfg.Model == "" {
 		cfg.Model = "claude-haiku-4-5-20251001"
 	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.anthropic.com"
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
 	if cfg.Timeout == 0 {
 		cfg.Timeout = 60 * time.Second
 	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
 	return &AnthropicClient{
 		cfg: cfg,
 		client: &http.Client{
@@ -57,6 +78,7 @@ type anthropicMessage struct {
 // anthropicMessagesResponse is the response body from POST /v1/messages.
 type anthropicMessagesResponse struct {
 	Content []struct {
+		Type string `json:"type"`
 		Text string `json:"text"`
 	} `json:"content"`
 }
@@ -75,10 +97,11 @@ func (c *AnthropicClient) Complete(ctx context.Context, prompt string) (string,
 	return result.(string), nil
 }
 
+// complete sends the request, retrying up to MaxRetries times when
+// Anthropic answers 429 (rate limited), 529 (overloaded) or another 5xx.
+// It waits as long as the retry-after header asks, or backs off
+// exponentially without one.
 func (c *AnthropicClient) complete(ctx context.Context, prompt string) (string, error) {
-	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
-	defer cancel()
-
 	reqBody := anthropicMessagesRequest{
 		Model:     c.cfg.Model,
 		MaxTokens: 4096,
@@ -92,9 +115,35 @@ func (c *AnthropicClient) complete(ctx context.Context, prompt string) (string,
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(jsonData))
+	for attempt := 0; ; attempt++ {
+		text, retryAfter, err := c.send(ctx, jsonData)
+		if err == nil || retryAfter < 0 || attempt >= c.cfg.MaxRetries {
+			return text, err
+		}
+		delay := retryAfter
+		if delay == 0 {
+			delay = anthropicRetryBaseDelay << attempt
+		}
+		delay = min(delay, anthropicMaxRetryDelay)
+		slog.Warn("anthropic request failed, retrying", "attempt", attempt+1, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("%w (retry abandoned: %v)", err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// send makes one request. On failure, retryAfter is negative when the
+// request should not be retried, and otherwise the delay the response
+// asked for, or 0 if it named none.
+func (c *AnthropicClient) send(ctx context.Context, jsonData []byte) (text string, retryAfter time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.cfg.BaseURL+"/v1/messages", bytes.NewReader(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return "", -1, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("content-type", "application/json")
 	req.Header.Set("x-api-key", c.cfg.APIKey)
@@ -102,25 +151,38 @@ func (c *AnthropicClient) complete(ctx context.Context, prompt string) (string,
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return "", -1, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("anthropic returned status %d: %s", resp.StatusCode, string(body))
+		err := fmt.Errorf("anthropic returned status %d: %s", resp.StatusCode, string(body))
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return "", -1, err
+		}
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("retry-after")); convErr == nil && seconds > 0 {
+			return "", time.Duration(seconds) * time.Second, err
+		}
+		return "", 0, err
 	}
 
 	var respData anthropicMessagesResponse
 	if err := json.NewDecoder(resp.Body).Decode(&respData); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return "", -1, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if len(respData.Content) == 0 {
-		return "", fmt.Errorf("anthropic returned empty content")
+	// Join the text blocks; other block types carry no completion text.
+	var b strings.Builder
+	for _, block := range respData.Content {
+		if block.Type == "" || block.Type == "text" {
+			b.WriteString(block.Text)
+		}
 	}
-
-	return respData.Content[0].Text, nil
+	if b.Len() == 0 {
+		return "", -1, fmt.Errorf("anthropic returned empty content")
+	}
+	return b.String(), 0, nil
 }
 
 // GetModel returns the configured model name.
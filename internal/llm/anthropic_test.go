@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// anthropicServer answers /v1/messages with each status in turn, then 200.
+func anthropicServer(t *testing.T, statuses ...int) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("unexpected request %s with key %q", r.URL.Path, r.Header.Get("x-api-key"))
+		}
+		calls++
+		if calls <= len(statuses) {
+			w.Header().Set("retry-after", "0")
+			w.WriteHeader(statuses[calls-1])
+			_, _ = w.Write([]byte(`{"type":"error"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"content":[{"type":"thinking","text":""},{"type":"text","text":"Hello"},{"type":"text","text":" there"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestAnthropicClient_RetriesRateLimits(t *testing.T) {
+	defer func(d time.Duration) { anthropicRetryBaseDelay = d }(anthropicRetryBaseDelay)
+	anthropicRetryBaseDelay = time.Millisecond
+
+	srv, calls := anthropicServer(t, http.StatusTooManyRequests, 529)
+	client := NewAnthropicClient(AnthropicConfig{APIKey: "test-key", BaseURL: srv.URL})
+	text, err := client.Complete(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("Complete() failed: %v", err)
+	}
+	if text != "Hello there" {
+		t.Errorf("Complete() = %q, want the joined text blocks", text)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 2 retries, got %d calls", *calls)
+	}
+
+	srv, calls = anthropicServer(t, http.StatusTooManyRequests, http.StatusTooManyRequests)
+	client = NewAnthropicClient(AnthropicConfig{APIKey: "test-key", BaseURL: srv.URL, MaxRetries: 1})
+	if _, err := client.Complete(context.Background(), "hi"); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("expected a 429 error once retries run out, got %v", err)
+	}
+	if *calls != 2 {
+		t.Errorf("expected 1 retry, got %d calls", *calls)
+	}
+}
+
+func TestAnthropicClient_DoesNotRetryClientErrors(t *testing.T) {
+	srv, calls := anthropicServer(t, http.StatusBadRequest)
+	client := NewAnthropicClient(AnthropicConfig{APIKey: "test-key", BaseURL: srv.URL})
+	if _, err := client.Complete(context.Background(), "hi"); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("expected a 400 error, got %v", err)
+	}
+	if *calls != 1 {
+		t.Errorf("expected no retry, got %d calls", *calls)
+	}
+}
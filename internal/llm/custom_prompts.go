@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// RenderEntityPrompt fills a custom entity extraction prompt (see
+// types.ValidateEntityPrompt) for content. types.PromptEntityTypes lists the
+// entity types of settings, or the system types if settings is nil.
+//
+// The LLM must still answer in the format ParseEntityResponse accepts:
+//
+//	{"entities":[{"name":"...","type":"...","description":"...","confidence":0.9}]}
+func RenderEntityPrompt(template, content string, settings *types.SettingsResponse) string {
+	typeIDs := types.ValidEntityTypes
+	var custom []types.CustomEntityType
+	if settings != nil && len(settings.AllEntityTypes) > 0 {
+		typeIDs, custom = settings.AllEntityTypes, settings.CustomEntityTypes
+	}
+	return strings.NewReplacer(
+		types.PromptEntityTypes, entityTypeList(typeIDs, custom),
+		types.PromptContent, content,
+	).Replace(template)
+}
+
+// RenderRelationshipPrompt fills a custom relationship extraction prompt
+// (see types.ValidateRelationshipPrompt) for content and the entities
+// extracted from it. types.PromptRelationshipTypes lists the relationship
+// types of settings, or the system types if settings is nil.
+//
+// The LLM must still answer in the format ParseRelationshipResponse accepts:
+//
+//	{"relationships":[{"from":"...","to":"...","type":"...","confidence":0.9}]}
+func RenderRelationshipPrompt(template, content string, entities []types.Entity, settings *types.SettingsResponse) string {
+	typeIDs := types.ValidRelationshipTypes
+	if settings != nil && len(settings.AllRelationshipTypes) > 0 {
+		typeIDs = settings.AllRelationshipTypes
+	}
+	var typeList strings.Builder
+	for _, typeID := range typeIDs {
+		fmt.Fprintf(&typeList, "- %s\n", typeID)
+	}
+	return strings.NewReplacer(
+		types.PromptRelationshipTypes, typeList.String(),
+		types.PromptEntities, entityList(entities),
+		types.PromptContent, content,
+	).Replace(template)
+}
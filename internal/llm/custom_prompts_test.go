@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func TestRenderEntityPrompt(t *testing.T) {
+	template := "Types:\n{{entity_types}}Text:\n{{content}}"
+
+	prompt := RenderEntityPrompt(template, "The lessee shall pay {{entities}}.", nil)
+	if !strings.Contains(prompt, "- person: Individual human\n") {
+		t.Errorf("expected the system entity types, got:\n%s", prompt)
+	}
+	if !strings.HasSuffix(prompt, "Text:\nThe lessee shall pay {{entities}}.") {
+		t.Errorf("expected the content verbatim, got:\n%s", prompt)
+	}
+
+	settings := &types.SettingsResponse{
+		AllEntityTypes:    []string{"person", "statute"},
+		CustomEntityTypes: []types.CustomEntityType{{ID: "statute", Name: "Statute", Description: "A law or regulation"}},
+	}
+	prompt = RenderEntityPrompt(template, "content", settings)
+	want := "Types:\n- person: Individual human\n- statute: A law or regulation (custom)\nText:\ncontent"
+	if prompt != want {
+		t.Errorf("RenderEntityPrompt() = %q, want %q", prompt, want)
+	}
+}
+
+func TestRenderRelationshipPrompt(t *testing.T) {
+	template := "{{relationship_types}}--\n{{entities}}--\n{{content}}"
+	entities := []types.Entity{{Name: "Acme", Type: "organization"}, {Name: "Lease", Type: "document"}}
+	settings := &types.SettingsResponse{AllRelationshipTypes: []string{"party_to", "references"}}
+
+	prompt := RenderRelationshipPrompt(template, "Acme signed the lease.", entities, settings)
+	want := "- party_to\n- references\n--\n- Acme (organization)\n- Lease (document)\n--\nAcme signed the lease."
+	if prompt != want {
+		t.Errorf("RenderRelationshipPrompt() = %q, want %q", prompt, want)
+	}
+
+	prompt = RenderRelationshipPrompt(template, "content", entities, nil)
+	if !strings.Contains(prompt, "- works_with\n") {
+		t.Errorf("expected the system relationship types, got:\n%s", prompt)
+	}
+}
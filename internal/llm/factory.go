@@ -12,7 +12,7 @@ func NewTextGenerator(cfg connections.LLMConfig) (TextGenerator, error) {
 	case "openai":
 		return NewOpenAIClient(OpenAIConfig{APIKey: cfg.APIKey, Model: cfg.Model, BaseURL: cfg.BaseURL}), nil
 	case "anthropic":
-		return NewAnthropicClient(AnthropicConfig{APIKey: cfg.APIKey, Model: cfg.Model}), nil
+		return NewAnthropicClient(AnthropicConfig{APIKey: cfg.APIKey, Model: cfg.Model, BaseURL: cfg.BaseURL}), nil
 	case "ollama", "":
 		baseURL := cfg.BaseURL
 		if baseURL == "" {
@@ -22,7 +22,7 @@ func NewTextGenerator(cfg connections.LLMConfig) (TextGenerator, error) {
 		if model == "" {
 			model = "qwen2.5:7b"
 		}
-		return NewOllamaClient(OllamaConfig{BaseURL: baseURL, Model: model}), nil
+		return NewOllamaClient(OllamaConfig{BaseURL: baseURL, Model: model, KeepAlive: cfg.KeepAlive}), nil
 	default:
 		return nil, fmt.Errorf("unsupported LLM provider: %q", cfg.Provider)
 	}
@@ -47,7 +47,7 @@ func NewEmbeddingGenerator(cfg connections.LLMConfig, embeddingModel string) (Em
 		if model == "" {
 			model = "nomic-embed-text"
 		}
-		return NewOllamaClient(OllamaConfig{BaseURL: baseURL, Model: model}), nil
+		return NewOllamaClient(OllamaConfig{BaseURL: baseURL, Model: model, KeepAlive: cfg.KeepAlive}), nil
 	default:
 		// Anthropic and others don't support embeddings
 		return nil, nil
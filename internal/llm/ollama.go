@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -18,6 +19,7 @@ type OllamaClient struct {
 	client         *http.Client
 	circuitBreaker *CircuitBreaker
 	model          string
+	keepAlive      string
 	timeout        time.Duration
 }
 
@@ -31,13 +33,19 @@ type OllamaConfig struct {
 
 	// Timeout is the request timeout duration (default: 5s)
 	Timeout time.Duration
+
+	// KeepAlive is sent as keep_alive with each generate and embed request:
+	// how long Ollama keeps the model loaded afterwards, as a duration such
+	// as "30m" (negative keeps it loaded). Empty leaves Ollama's default.
+	KeepAlive string
 }
 
 // generateRequest represents the request body for /api/generate endpoint
 type generateRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	Model     string `json:"model"`
+	Prompt    string `json:"prompt"`
+	Stream    bool   `json:"stream"`
+	KeepAlive string `json:"keep_alive,omitempty"`
 }
 
 // generateResponse represents the response from /api/generate endpoint
@@ -48,8 +56,9 @@ type generateResponse struct {
 
 // embedRequest represents the request body for /api/embed endpoint
 type embedRequest struct {
-	Model string `json:"model"`
-	Input string `json:"input"`
+	Model     string `json:"model"`
+	Input     string `json:"input"`
+	KeepAlive string `json:"keep_alive,omitempty"`
 }
 
 // embedResponse represents the response from /api/embed endpoint
@@ -83,12 +92,13 @@ func NewOllamaClient(config OllamaConfig) *OllamaClient {
 	}
 
 	return &OllamaClient{
-		baseURL: config.BaseURL,
+		baseURL: strings.TrimRight(config.BaseURL, "/"),
 		client: &http.Client{
 			Timeout: config.Timeout,
 		},
 		circuitBreaker: NewCircuitBreaker(),
 		model:          config.Model,
+		keepAlive:      config.KeepAlive,
 		timeout:        config.Timeout,
 	}
 }
@@ -127,9 +137,10 @@ func (c *OllamaClient) complete(ctx context.Context, prompt string) (string, err
 
 	// Build request body
 	reqBody := generateRequest{
-		Model:  c.model,
-		Prompt: prompt,
-		Stream: false, // We don't support streaming
+		Model:     c.model,
+		Prompt:    prompt,
+		Stream:    false, // We don't support streaming
+		KeepAlive: c.keepAlive,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -200,8 +211,9 @@ func (c *OllamaClient) embed(ctx context.Context, text string) ([]float32, error
 
 	// Build request body
 	reqBody := embedRequest{
-		Model: c.model,
-		Input: text,
+		Model:     c.model,
+		Input:     text,
+		KeepAlive: c.keepAlive,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOllamaClient_KeepAlive(t *testing.T) {
+	var paths, keepAlives []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		paths = append(paths, r.URL.Path)
+		keepAlive, _ := body["keep_alive"].(string)
+		keepAlives = append(keepAlives, keepAlive)
+		if r.URL.Path == "/api/embed" {
+			_, _ = w.Write([]byte(`{"embeddings":[[0.1,0.2]]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok","done":true}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := NewOllamaClient(OllamaConfig{BaseURL: srv.URL + "/", KeepAlive: "30m"})
+	if _, err := client.Complete(ctx, "hello"); err != nil {
+		t.Fatalf("Complete() failed: %v", err)
+	}
+	if _, err := client.Embed(ctx, "hello"); err != nil {
+		t.Fatalf("Embed() failed: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != "/api/generate" || paths[1] != "/api/embed" {
+		t.Errorf("expected /api/generate and /api/embed without a doubled slash, got %v", paths)
+	}
+	for i, keepAlive := range keepAlives {
+		if keepAlive != "30m" {
+			t.Errorf("request %d: keep_alive = %q, want 30m", i, keepAlive)
+		}
+	}
+
+	keepAlives = nil
+	client = NewOllamaClient(OllamaConfig{BaseURL: srv.URL})
+	if _, err := client.Complete(ctx, "hello"); err != nil {
+		t.Fatalf("Complete() failed: %v", err)
+	}
+	if keepAlives[0] != "" {
+		t.Errorf("keep_alive = %q, want it omitted by default", keepAlives[0])
+	}
+}
@@ -99,16 +99,6 @@ RESPOND WITH ONLY THIS JSON STRUCTURE (nothing else):
 // Returns:
 //   - A prompt string that will elicit JSON-only responses from the LLM
 func RelationshipExtractionPrompt(content string, entities []types.Entity) string {
-	// Build entity list for the prompt
-	var entityList strings.Builder
-	for i, entity := range entities {
-		fmt.Fprintf(&entityList, "- %s (%s)\n", entity.Name, entity.Type)
-		if i >= 50 { // Limit to first 50 entities to avoid token limits
-			fmt.Fprintf(&entityList, "... and %d more entities\n", len(entities)-50)
-			break
-		}
-	}
-
 	return fmt.Sprintf(`Find relationships between these entities. Return ONLY valid JSON, no markdown, no code blocks, no explanation.
 
 BIDIRECTIONAL (use ONE direction, system stores both):
@@ -146,7 +136,7 @@ Content to analyze:
 %s
 
 Return ONLY JSON object, nothing else, no markdown:
-{"relationships":[{"from":"X","to":"Y","type":"...","confidence":0.85},...]}`, entityList.String(), content)
+{"relationships":[{"from":"X","to":"Y","type":"...","confidence":0.85},...]}`, entityList(entities), content)
 }
 
 // SummarizationPrompt generates a strict JSON-only prompt for content summarization.
@@ -172,6 +162,81 @@ Return ONLY JSON object, nothing else, no markdown:
 {"summary":"...","key_points":["...","..."]}`, content)
 }
 
+// ContradictionCandidate is a memory that ContradictionCheckPrompt asks the
+// LLM to compare against the statement under review.
+type ContradictionCandidate struct {
+	ID      string
+	Content string
+}
+
+// ContradictionCheckPrompt generates a strict JSON-only prompt asking whether
+// any of the candidates directly contradicts content, i.e. whether both cannot
+// be true at the same time. Related or merely different statements are not
+// contradictions.
+//
+// Parameters:
+//   - content: The memory content under review
+//   - candidates: Similar memories to compare it against
+//
+// Returns:
+//   - A prompt string that will elicit JSON-only responses from the LLM
+func ContradictionCheckPrompt(content string, candidates []ContradictionCandidate) string {
+	var list strings.Builder
+	for _, c := range candidates {
+		list.WriteString(fmt.Sprintf("[%s]\n%s\n\n", c.ID, c.Content))
+	}
+	return fmt.Sprintf(`Find contradictions. Return ONLY valid JSON, no markdown, no code blocks, no explanation.
+
+A candidate contradicts the statement only if both cannot be true at the same time
+(e.g. different values for the same fact). Related, more detailed or merely
+different statements are NOT contradictions. Use the candidate IDs exactly.
+
+Statement:
+%s
+
+Candidates:
+%s
+Return ONLY JSON object listing contradicting candidates (empty list if none), nothing else, no markdown:
+{"contradictions":[{"id":"...","confidence":0.9,"explanation":"..."}]}`, content, list.String())
+}
+
+// DigestSection is a group of memories, e.g. one domain, that DigestPrompt
+// asks the LLM to cover.
+type DigestSection struct {
+	Name     string
+	Memories []string
+}
+
+// DigestPrompt generates a prompt asking for a readable digest of the
+// memories captured during period (e.g. "2026-10-15 to 2026-10-16"), one
+// section per group. Unlike the extraction prompts it asks for Markdown
+// text, which is stored as the digest's content as is.
+//
+// Parameters:
+//   - period: The time window the memories were captured in
+//   - sections: The memories, grouped by domain or topic
+//
+// Returns:
+//   - A prompt string that will elicit a Markdown digest from the LLM
+func DigestPrompt(period string, sections []DigestSection) string {
+	var list strings.Builder
+	for _, s := range sections {
+		list.WriteString(fmt.Sprintf("## %s\n", s.Name))
+		for _, m := range s.Memories {
+			list.WriteString(fmt.Sprintf("- %s\n", m))
+		}
+		list.WriteString("\n")
+	}
+	return fmt.Sprintf(`Write a digest of the notes captured from %s.
+
+Keep the groups below as Markdown sections with the same headings. Under each,
+summarize what was captured in a few bullet points, merging related notes and
+highlighting decisions, open questions and follow-ups. Do not add information
+that is not in the notes. Respond with only the digest, no preamble.
+
+%s`, period, list.String())
+}
+
 // KeywordExtractionPrompt generates a strict JSON-only prompt for keyword extraction.
 // The prompt instructs the LLM to extract important keywords and phrases from the content.
 //
@@ -285,27 +350,6 @@ func EntityExtractionPromptWithSettings(content string, settings *types.Settings
 		return EntityExtractionPrompt(content)
 	}
 
-	// Build type list: system types with descriptions, custom types with name+description
-	var typeList strings.Builder
-	customTypeMap := make(map[string]types.CustomEntityType)
-	for _, ct := range settings.CustomEntityTypes {
-		customTypeMap[ct.ID] = ct
-	}
-
-	for _, typeID := range settings.AllEntityTypes {
-		if ct, isCustom := customTypeMap[typeID]; isCustom {
-			desc := ct.Description
-			if desc == "" {
-				desc = ct.Name
-			}
-			fmt.Fprintf(&typeList, "- %s: %s (custom)\n", typeID, desc)
-		} else if desc, ok := systemEntityTypeDescriptions[typeID]; ok {
-			fmt.Fprintf(&typeList, "- %s: %s\n", typeID, desc)
-		} else {
-			fmt.Fprintf(&typeList, "- %s\n", typeID)
-		}
-	}
-
 	// Build valid types string for validation line
 	validTypes := strings.Join(settings.AllEntityTypes, "|")
 
@@ -343,7 +387,45 @@ TEXT TO EXTRACT FROM:
 %s
 
 RESPOND WITH ONLY THIS JSON STRUCTURE (nothing else):
-{"entities":[{"name":"X","type":"person","description":"...","confidence":0.85}]}`, typeList.String(), validTypes, content)
+{"entities":[{"name":"X","type":"person","description":"...","confidence":0.85}]}`, entityTypeList(settings.AllEntityTypes, settings.CustomEntityTypes), validTypes, content)
+}
+
+// entityTypeList lists typeIDs one per line for a prompt: system types with
+// their descriptions, custom types with their description or name.
+func entityTypeList(typeIDs []string, custom []types.CustomEntityType) string {
+	var typeList strings.Builder
+	customTypeMap := make(map[string]types.CustomEntityType)
+	for _, ct := range custom {
+		customTypeMap[ct.ID] = ct
+	}
+
+	for _, typeID := range typeIDs {
+		if ct, isCustom := customTypeMap[typeID]; isCustom {
+			desc := ct.Description
+			if desc == "" {
+				desc = ct.Name
+			}
+			fmt.Fprintf(&typeList, "- %s: %s (custom)\n", typeID, desc)
+		} else if desc, ok := systemEntityTypeDescriptions[typeID]; ok {
+			fmt.Fprintf(&typeList, "- %s: %s\n", typeID, desc)
+		} else {
+			fmt.Fprintf(&typeList, "- %s\n", typeID)
+		}
+	}
+	return typeList.String()
+}
+
+// entityList lists entities one per line for a relationship prompt.
+func entityList(entities []types.Entity) string {
+	var list strings.Builder
+	for i, entity := range entities {
+		fmt.Fprintf(&list, "- %s (%s)\n", entity.Name, entity.Type)
+		if i >= 50 { // Limit to first 50 entities to avoid token limits
+			fmt.Fprintf(&list, "... and %d more entities\n", len(entities)-50)
+			break
+		}
+	}
+	return list.String()
 }
 
 // RelationshipExtractionPromptWithSettings generates a relationship extraction prompt using all
@@ -354,16 +436,6 @@ func RelationshipExtractionPromptWithSettings(content string, entities []types.E
 		return RelationshipExtractionPrompt(content, entities)
 	}
 
-	// Build entity list
-	var entityList strings.Builder
-	for i, entity := range entities {
-		fmt.Fprintf(&entityList, "- %s (%s)\n", entity.Name, entity.Type)
-		if i >= 50 {
-			fmt.Fprintf(&entityList, "... and %d more entities\n", len(entities)-50)
-			break
-		}
-	}
-
 	// Separate custom types into bidirectional and unidirectional
 	var customBidi, customUni strings.Builder
 	for _, ct := range settings.CustomRelationshipTypes {
@@ -411,7 +483,7 @@ Content to analyze:
 %s
 
 Return ONLY JSON object, nothing else, no markdown:
-{"relationships":[{"from":"X","to":"Y","type":"...","confidence":0.85},...]}`, customBidi.String(), customUni.String(), entityList.String(), content)
+{"relationships":[{"from":"X","to":"Y","type":"...","confidence":0.85},...]}`, customBidi.String(), customUni.String(), entityList(entities), content)
 }
 
 // ClassificationExtractionPromptWithSettings generates a classification prompt driven by
@@ -61,6 +61,14 @@ type SummarizationResponse struct {
 	KeyPoints []string `json:"key_points"`
 }
 
+// ContradictionCheckResponse represents a single contradiction reported in
+// response to ContradictionCheckPrompt
+type ContradictionCheckResponse struct {
+	ID          string  `json:"id"`
+	Confidence  float64 `json:"confidence"`
+	Explanation string  `json:"explanation"`
+}
+
 // KeywordExtractionResponse represents the keyword extraction response
 type KeywordExtractionResponse struct {
 	Keywords []string `json:"keywords"`
@@ -173,6 +181,37 @@ func ParseSummarizationResponse(jsonStr string) (*SummarizationResponse, error)
 	return &response, nil
 }
 
+// ParseContradictionCheckResponse parses the JSON response to
+// ContradictionCheckPrompt. Entries without an ID are dropped and confidence
+// is clamped to [0, 1].
+//
+// Parameters:
+//   - jsonStr: JSON string returned by the LLM
+//
+// Returns:
+//   - Slice of reported contradictions (empty when there are none)
+//   - Error if parsing fails
+func ParseContradictionCheckResponse(jsonStr string) ([]ContradictionCheckResponse, error) {
+	cleanJSON := extractJSON(jsonStr)
+
+	var response struct {
+		Contradictions []ContradictionCheckResponse `json:"contradictions"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSON), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse contradiction JSON: %w", err)
+	}
+
+	valid := make([]ContradictionCheckResponse, 0, len(response.Contradictions))
+	for _, c := range response.Contradictions {
+		if strings.TrimSpace(c.ID) == "" {
+			continue
+		}
+		c.Confidence = min(max(c.Confidence, 0), 1)
+		valid = append(valid, c)
+	}
+	return valid, nil
+}
+
 // ParseKeywordResponse parses keyword extraction JSON response.
 // It returns an error if the JSON is malformed.
 //
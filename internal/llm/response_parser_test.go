@@ -549,6 +549,29 @@ func TestParseSummarizationResponse(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// Tests for ParseContradictionCheckResponse
+// ============================================================================
+
+func TestParseContradictionCheckResponse(t *testing.T) {
+	got, err := ParseContradictionCheckResponse("```json\n" +
+		`{"contradictions": [{"id": "mem:a", "confidence": 1.7, "explanation": "x"}, {"id": "", "confidence": 0.5}, {"id": "mem:b", "confidence": -1}]}` +
+		"\n```")
+	if err != nil {
+		t.Fatalf("ParseContradictionCheckResponse() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ParseContradictionCheckResponse() = %+v, want 2 entries", got)
+	}
+	if got[0].ID != "mem:a" || got[0].Confidence != 1 || got[1].Confidence != 0 {
+		t.Errorf("ParseContradictionCheckResponse() = %+v, want clamped confidences", got)
+	}
+
+	if _, err := ParseContradictionCheckResponse(`{"contradictions": [`); err == nil {
+		t.Error("ParseContradictionCheckResponse() on malformed JSON: want error")
+	}
+}
+
 // ============================================================================
 // Tests for ParseKeywordResponse
 // ============================================================================
@@ -0,0 +1,59 @@
+// Package logging configures Memento's leveled, structured logger.
+//
+// It is a thin layer over log/slog: Setup installs a text handler as the
+// slog default, so packages log with slog.Debug, slog.Info, slog.Warn and
+// slog.Error. Output from the standard log package is routed through the
+// same handler at info level, so it obeys the configured level too.
+//
+// Log lines use the same keys for the same things so they can be grepped
+// across packages: memory_id for a memory, connection for a connection
+// name, op for the tool or operation being performed, and error for the
+// error.
+//
+// The MCP server speaks JSON-RPC on stdout, so callers must pass stderr (or
+// another non-stdout writer) as the destination.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// DefaultLevel is the level used when MEMENTO_LOG_LEVEL is unset.
+const DefaultLevel = "info"
+
+// ParseLevel parses a MEMENTO_LOG_LEVEL value: debug, info, warn (or
+// warning) or error, case-insensitively. The empty string means info.
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("invalid log level %q: must be debug, info, warn or error", value)
+	}
+}
+
+// New returns a logger that writes key=value lines at or above level to w.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// Setup parses level and installs a logger writing to w as the slog
+// default. Any prefix set on the standard logger stays in front of its
+// messages.
+func Setup(w io.Writer, level string) error {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(New(w, l))
+	return nil
+}
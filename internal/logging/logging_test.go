@@ -0,0 +1,77 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warn":    slog.LevelWarn,
+		"Warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+	}
+	for value, want := range cases {
+		got, err := ParseLevel(value)
+		if err != nil {
+			t.Errorf("ParseLevel(%q) returned error: %v", value, err)
+		} else if got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", value, got, want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+func TestSetup_SuppressesMessagesBelowLevel(t *testing.T) {
+	prev := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(prev)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	})
+
+	var buf bytes.Buffer
+	if err := Setup(&buf, "warn"); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	slog.Debug("debug message")
+	slog.Info("info message")
+	log.Print("stdlib message")
+	slog.Warn("warn message", "memory_id", "mem:general:abc")
+	slog.Error("error message")
+
+	out := buf.String()
+	for _, suppressed := range []string{"debug message", "info message", "stdlib message"} {
+		if strings.Contains(out, suppressed) {
+			t.Errorf("expected %q to be suppressed at warn level, got:\n%s", suppressed, out)
+		}
+	}
+	for _, kept := range []string{"level=WARN", `msg="warn message"`, "memory_id=mem:general:abc", "level=ERROR"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("expected output to contain %q, got:\n%s", kept, out)
+		}
+	}
+
+	buf.Reset()
+	if err := Setup(&buf, "debug"); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	slog.Debug("debug message")
+	log.Print("stdlib message")
+	if out := buf.String(); !strings.Contains(out, "debug message") || !strings.Contains(out, "stdlib message") {
+		t.Errorf("expected debug and stdlib messages at debug level, got:\n%s", out)
+	}
+
+	if err := Setup(&buf, "loud"); err == nil {
+		t.Error("expected Setup to reject an unknown level")
+	}
+}
@@ -1,6 +1,7 @@
 package notify
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -134,6 +135,43 @@ func TestEventTypeRoundTrip(t *testing.T) {
 	}
 }
 
+func TestEventWatcherReceivesPayload(t *testing.T) {
+	dir := t.TempDir()
+
+	received := make(chan Event, 1)
+	watcher := NewEventWatcherWithData(dir, func(evt Event) {
+		received <- evt
+	})
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer watcher.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+
+	writer := NewEventWriter(dir)
+	payload := map[string]interface{}{"summary": "conflicting married_to"}
+	if err := writer.NotifyWithData("contradiction_detected", "mem:general:payload", payload); err != nil {
+		t.Fatalf("NotifyWithData failed: %v", err)
+	}
+
+	select {
+	case evt := <-received:
+		if evt.Type != "contradiction_detected" {
+			t.Errorf("expected event type contradiction_detected, got %s", evt.Type)
+		}
+		var got map[string]interface{}
+		if err := json.Unmarshal(evt.Data, &got); err != nil {
+			t.Fatalf("unmarshal payload: %v", err)
+		}
+		if got["summary"] != "conflicting married_to" {
+			t.Errorf("expected summary to round-trip, got %v", got["summary"])
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+}
+
 func TestSanitizeID(t *testing.T) {
 	got := sanitizeID("mem:general:abc/def")
 	if got != "mem_general_abc_def" {
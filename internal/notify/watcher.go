@@ -13,13 +13,23 @@ import (
 // EventWatcher watches the events directory and dispatches callbacks.
 type EventWatcher struct {
 	dir      string
-	callback func(eventType, memoryID string)
+	callback func(evt Event)
 	watcher  *fsnotify.Watcher
 	done     chan struct{}
 }
 
 // NewEventWatcher creates a watcher for {dataPath}/events/.
 func NewEventWatcher(dataPath string, callback func(eventType, memoryID string)) *EventWatcher {
+	return NewEventWatcherWithData(dataPath, func(evt Event) {
+		if callback != nil {
+			callback(evt.Type, evt.MemoryID)
+		}
+	})
+}
+
+// NewEventWatcherWithData creates a watcher for {dataPath}/events/ whose
+// callback receives the full Event, including any Data payload.
+func NewEventWatcherWithData(dataPath string, callback func(evt Event)) *EventWatcher {
 	return &EventWatcher{
 		dir:      filepath.Join(dataPath, "events"),
 		callback: callback,
@@ -105,6 +115,6 @@ func (ew *EventWatcher) processFile(path string) {
 	}
 
 	if event.MemoryID != "" && ew.callback != nil {
-		ew.callback(event.Type, event.MemoryID)
+		ew.callback(event)
 	}
 }
@@ -12,9 +12,10 @@ import (
 
 // Event is the payload written to an event file.
 type Event struct {
-	Type     string `json:"type"`
-	MemoryID string `json:"memory_id"`
-	Time     int64  `json:"time"`
+	Type     string          `json:"type"`
+	MemoryID string          `json:"memory_id"`
+	Time     int64           `json:"time"`
+	Data     json.RawMessage `json:"data,omitempty"` // Optional event-specific payload
 }
 
 // EventWriter writes notification event files to a shared directory.
@@ -30,6 +31,13 @@ func NewEventWriter(dataPath string) *EventWriter {
 // Notify writes an event file with the given type.
 // Safe to call concurrently. Errors are returned but not fatal.
 func (w *EventWriter) Notify(eventType, memoryID string) error {
+	return w.NotifyWithData(eventType, memoryID, nil)
+}
+
+// NotifyWithData writes an event file carrying an additional JSON payload
+// (e.g. the contradictions behind a contradiction_detected event).
+// A nil payload produces the same file as Notify.
+func (w *EventWriter) NotifyWithData(eventType, memoryID string, payload interface{}) error {
 	if err := os.MkdirAll(w.dir, 0o700); err != nil {
 		return fmt.Errorf("notify: mkdir %s: %w", w.dir, err)
 	}
@@ -38,6 +46,13 @@ func (w *EventWriter) Notify(eventType, memoryID string) error {
 		MemoryID: memoryID,
 		Time:     time.Now().UnixNano(),
 	}
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("notify: marshal %s payload: %w", eventType, err)
+		}
+		evt.Data = raw
+	}
 	data, _ := json.Marshal(evt)
 	filename := fmt.Sprintf("%d-%s.event", evt.Time, sanitizeID(memoryID))
 	path := filepath.Join(w.dir, filename)
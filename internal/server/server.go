@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
@@ -16,6 +17,8 @@ import (
 	"github.com/scrypster/memento/internal/connections"
 	"github.com/scrypster/memento/internal/services"
 	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/internal/storage/postgres"
+	"github.com/scrypster/memento/internal/storage/sqlite"
 	"github.com/scrypster/memento/web/handlers"
 )
 
@@ -78,8 +81,13 @@ func Start(ctx context.Context, cfg *config.Config, store storage.MemoryStore, q
 		var err error
 		connManager, err = connections.NewManager(connectionsConfigPath)
 		if err != nil {
-			log.Printf("Warning: failed to load connections config: %v, falling back to default", err)
+			slog.Warn("failed to load connections config, falling back to default", "error", err)
 			connManager = connections.NewManagerWithStore(store, "default")
+		} else if cfg != nil {
+			connManager.SetSQLiteOptions(sqlite.OptionsFromConfig(cfg.Storage)...)
+			connManager.SetPostgresOptions(postgres.OptionsFromConfig(cfg.Storage, cfg.LLM.EmbeddingDimension)...)
+			connManager.SetStateMachine(cfg.Storage.StateMachine)
+			connManager.SetDedupNormalization(cfg.Storage.DedupNormalization)
 		}
 	} else {
 		// Build a single-store connections manager so that the stats and search
@@ -444,7 +452,7 @@ func Start(ctx context.Context, cfg *config.Config, store storage.MemoryStore, q
 
 	go func() {
 		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
-			log.Printf("Server error: %v", err)
+			slog.Error("server error", "error", err)
 		}
 	}()
 
@@ -454,7 +462,7 @@ func Start(ctx context.Context, cfg *config.Config, store storage.MemoryStore, q
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := server.Shutdown(shutdownCtx); err != nil {
-			log.Printf("Server shutdown error: %v", err)
+			slog.Error("server shutdown error", "error", err)
 		}
 		wsHub.Stop()
 	}()
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/scrypster/memento/internal/config"
+)
+
+// NormalizeForDedup returns the text hashed for deduplication of content at
+// the given dedup normalization level: config.DedupExact hashes the content
+// as-is, config.DedupWhitespace trims it and collapses whitespace runs, and
+// config.DedupNormalized additionally lowercases it and strips trailing
+// punctuation. Unknown levels, including the empty string, behave like
+// config.DedupExact.
+func NormalizeForDedup(level, content string) string {
+	switch level {
+	case config.DedupWhitespace:
+		return strings.Join(strings.Fields(content), " ")
+	case config.DedupNormalized:
+		content = strings.Join(strings.Fields(strings.ToLower(content)), " ")
+		return strings.TrimRightFunc(content, func(r rune) bool {
+			return unicode.IsPunct(r) || unicode.IsSpace(r)
+		})
+	default:
+		return content
+	}
+}
+
+// ContentHash returns the hex SHA-256 of content normalized at level (see
+// NormalizeForDedup), the value stores record as a memory's content_hash.
+func ContentHash(level, content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(NormalizeForDedup(level, content))))
+}
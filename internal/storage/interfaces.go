@@ -45,11 +45,14 @@ type MemoryStore interface {
 	// GetEvolutionChain returns the full version history for a memory,
 	// ordered oldest → newest (original at index 0, latest at last).
 	// It walks backward via supersedes_id links and forward via reverse lookups.
+	// Soft-deleted versions are included, with DeletedAt set, so the chain
+	// stays connected; callers decide whether to show them.
 	// Capped at 50 versions to prevent infinite loops.
 	GetEvolutionChain(ctx context.Context, memoryID string) ([]*types.Memory, error)
 
 	// GetMemoriesByRelationType returns memories connected to memoryID via
-	// relationships of the given type (e.g. "CONTAINS").
+	// relationships of the given type (e.g. "CONTAINS"), in link order:
+	// links are appended as they are created and can be reordered.
 	// Used by get_project_tree to walk project hierarchies.
 	GetMemoriesByRelationType(ctx context.Context, memoryID string, relType string) ([]*types.Memory, error)
 
@@ -71,21 +74,47 @@ type MemoryStore interface {
 
 	// GetRelatedMemories returns the IDs of memories that share entities with
 	// the given memory. This supports 1-hop graph traversal for the
-	// GraphTraversal engine. Implementations may return an empty slice when
-	// relationship data is not yet populated.
+	// GraphTraversal engine. Soft-deleted memories are excluded on both
+	// ends. Implementations may return an empty slice when relationship
+	// data is not yet populated.
 	GetRelatedMemories(ctx context.Context, memoryID string) ([]string, error)
 
 	// Traverse finds memories connected through the entity relationship graph.
 	// Starting from startMemoryID, it follows entity→relationship→entity→memory
-	// links up to maxHops times and returns up to limit results, sorted by
-	// hop distance ascending then by memory importance descending.
+	// links up to opts.MaxHops times and returns up to opts.Limit results,
+	// ranked by relevance score (see RankTraversalResults).
+	// Only relationships allowed by opts (type filters, direction) are followed.
+	// Soft-deleted memories are never returned, and a soft-deleted start
+	// memory has no connections until it is restored.
 	// Returns an empty slice (not an error) when no connected memories exist.
-	Traverse(ctx context.Context, startMemoryID string, maxHops int, limit int) ([]TraversalResult, error)
+	Traverse(ctx context.Context, startMemoryID string, opts TraversalOptions) ([]TraversalResult, error)
 
 	// GetMemoryEntities returns the entities associated with a specific memory.
 	// Returns an empty slice (not an error) when the memory has no entities.
 	GetMemoryEntities(ctx context.Context, memoryID string) ([]*types.Entity, error)
 
+	// ListEntities returns distinct entities linked to at least one
+	// non-deleted, unexpired memory, with MemoryCount, FirstSeen and LastSeen
+	// aggregated from those memories' created_at. Results are ordered by MemoryCount
+	// descending, then LastSeen descending.
+	ListEntities(ctx context.Context, opts EntityListOptions) (*PaginatedResult[types.Entity], error)
+
+	// SetEmbedding stores the embedding vector for a memory, replacing any
+	// existing one, and records the model that produced it.
+	// Returns ErrNotFound if the memory doesn't exist.
+	SetEmbedding(ctx context.Context, id string, vec []float64, model string) error
+
+	// GetEmbedding returns the embedding vector for a memory and the model
+	// that produced it. Callers should compare the model (and vector length)
+	// with the current embedding provider and re-embed on a mismatch rather
+	// than comparing incompatible vectors.
+	// Returns ErrNotFound if the memory has no embedding.
+	GetEmbedding(ctx context.Context, id string) ([]float64, string, error)
+
+	// ExpireMemories soft-deletes every memory whose expires_at is at or
+	// before now and returns the number of memories expired.
+	ExpireMemories(ctx context.Context, now time.Time) (int, error)
+
 	// UpdateDecayScores applies time-based decay to all active memories.
 	// This should be called periodically (e.g., daily). Returns count of updated rows.
 	UpdateDecayScores(ctx context.Context) (int, error)
@@ -126,6 +155,128 @@ type GraphProvider interface {
 	GetNeighbors(ctx context.Context, memoryID string, opts ListOptions) (*PaginatedResult[types.Memory], error)
 }
 
+// StaleMemoryArchiver finds and archives memories that have gone stale, for
+// the engine's auto-archival policy.
+type StaleMemoryArchiver interface {
+	// FindStaleMemories returns the IDs of memories matching criteria, least
+	// relevant first. Deleted, already-archived and pinned memories are never
+	// returned.
+	FindStaleMemories(ctx context.Context, criteria StaleCriteria) ([]string, error)
+
+	// ArchiveMemories sets the state of each memory to archived and returns
+	// how many were changed. This is a system transition: unlike UpdateState
+	// it does not apply the lifecycle state machine, so active memories can
+	// be archived directly. Archived memories stay retrievable by ID and via
+	// a state filter; they are only dropped from search.
+	ArchiveMemories(ctx context.Context, ids []string) (int, error)
+}
+
+// SnapshotStore captures and re-applies the graph around a single memory so
+// it can be saved alongside the memory for audit and restored later.
+type SnapshotStore interface {
+	// GetMemoryGraph returns the memory's entity associations, the
+	// relationships among those entities, and every memory link that starts
+	// or ends at the memory.
+	GetMemoryGraph(ctx context.Context, memoryID string) (*MemoryGraph, error)
+
+	// RestoreMemoryGraph replaces the memory's entity associations with those
+	// in graph (creating missing entities), upserts the relationships, and
+	// recreates missing links. Links whose other memory no longer exists are
+	// skipped. It returns the number of links it recreated.
+	RestoreMemoryGraph(ctx context.Context, memoryID string, graph *MemoryGraph) (int, error)
+}
+
+// RelationshipReader is implemented by stores that can list the typed
+// relationships enrichment recorded between entities.
+type RelationshipReader interface {
+	// ListRelationships returns the relationships matching filter, highest
+	// strength (extraction confidence) first. Returns ErrNotFound if the
+	// memory or entity doesn't exist and ErrInvalidInput unless exactly one
+	// of them is given.
+	ListRelationships(ctx context.Context, filter RelationshipFilter) ([]EntityRelationship, error)
+}
+
+// GraphExporter is implemented by stores that can export their memory and
+// entity graph in one piece, for visualization tools.
+type GraphExporter interface {
+	// ExportGraph returns the memories matching opts, newest first, with
+	// the entities they mention and the relationships and links among
+	// them, keeping at most opts.MaxNodes memories and entities together.
+	ExportGraph(ctx context.Context, opts GraphExportOptions) (*GraphExport, error)
+}
+
+// SessionStore records named work sessions so their labels and time spans
+// outlive the server process.
+type SessionStore interface {
+	// SaveSession creates or updates a session (upsert on ID).
+	SaveSession(ctx context.Context, session *Session) error
+
+	// GetSession returns the session with the given ID.
+	// Returns ErrNotFound if no such session was recorded.
+	GetSession(ctx context.Context, id string) (*Session, error)
+}
+
+// AuditLog is an append-only record of mutating operations, for teams that
+// need to know who changed a shared memory store. Entries are never updated
+// or deleted.
+type AuditLog interface {
+	// AppendAuditEntry records entry, setting its ID and, when zero, its
+	// Timestamp.
+	AppendAuditEntry(ctx context.Context, entry *AuditEntry) error
+
+	// ListAuditEntries returns the entries matching filter, newest first.
+	ListAuditEntries(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+}
+
+// StateBatchUpdater is implemented by stores that can apply many lifecycle
+// state transitions in one transaction.
+type StateBatchUpdater interface {
+	// UpdateStates transitions each memory in ids to state, validating each
+	// transition as UpdateState does, and returns one StateUpdate per ID in
+	// order. A failed transition is reported in its StateUpdate and does not
+	// prevent the others. A non-nil error means the transaction itself
+	// failed and no memory was changed.
+	UpdateStates(ctx context.Context, ids []string, state string) ([]StateUpdate, error)
+}
+
+// StateHistory is implemented by stores that record every lifecycle state
+// transition made through UpdateState and UpdateStates.
+type StateHistory interface {
+	// GetStateHistory returns the transitions of memoryID, oldest first.
+	// It returns an empty slice for a memory whose state never changed.
+	GetStateHistory(ctx context.Context, memoryID string) ([]StateTransition, error)
+}
+
+// StateMachineProvider is implemented by stores that report the lifecycle
+// state machine UpdateState validates against, so callers can check a
+// transition before attempting it. A nil result is the built-in machine.
+type StateMachineProvider interface {
+	StateMachine() *types.StateMachine
+}
+
+// EntityMerger is implemented by stores that can merge duplicate entities,
+// such as "Alice" and "alice" extracted from different memories, into one.
+type EntityMerger interface {
+	// MergeEntities moves the memory associations and relationships of each
+	// entity in duplicateIDs to canonicalID and deletes the duplicates, in
+	// one transaction. Associations and relationships the canonical entity
+	// already has, and relationships that would link it to itself, are
+	// dropped. Returns ErrNotFound if any of the entities doesn't exist and
+	// ErrInvalidInput if duplicateIDs is empty, repeats an ID or contains
+	// canonicalID.
+	MergeEntities(ctx context.Context, canonicalID string, duplicateIDs []string) (*EntityMergeResult, error)
+}
+
+// StorePurger is implemented by stores that can erase their whole memory
+// graph, as purge_connection does to a connection's store.
+type StorePurger interface {
+	// PurgeAll hard-deletes every memory, soft-deleted ones included, with
+	// its embeddings, entity associations, links and state history, and
+	// every entity and relationship, in one transaction. Settings, sessions
+	// and the audit log are kept.
+	PurgeAll(ctx context.Context) (*PurgeResult, error)
+}
+
 // RelationshipStore manages relationships between memories and entities.
 // This interface will be implemented in a later phase.
 type RelationshipStore interface {
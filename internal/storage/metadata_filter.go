@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NormalizeMetadataFilter checks a ListOptions or SearchOptions Metadata
+// filter and returns a copy whose values are all strings, float64s or
+// bools, the types the filter compares; other numeric types become
+// float64. Keys may not be empty or contain a double quote or backslash,
+// and values may not be null, objects or arrays. Errors wrap
+// ErrInvalidInput.
+func NormalizeMetadataFilter(filter map[string]interface{}) (map[string]interface{}, error) {
+	if len(filter) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]interface{}, len(filter))
+	for key, value := range filter {
+		if key == "" || strings.ContainsAny(key, `"\`) {
+			return nil, fmt.Errorf("%w: metadata key %q must be non-empty and contain no quotes or backslashes", ErrInvalidInput, key)
+		}
+		v, ok := metadataScalar(value)
+		if !ok {
+			return nil, fmt.Errorf("%w: metadata value of %q must be a string, number or boolean, got %T", ErrInvalidInput, key, value)
+		}
+		out[key] = v
+	}
+	return out, nil
+}
+
+// MetadataMatches reports whether metadata holds every key of filter, a
+// normalized Metadata filter, with an equal value of the same type: the
+// number 5 does not match the string "5".
+func MetadataMatches(metadata, filter map[string]interface{}) bool {
+	for key, want := range filter {
+		got, ok := metadataScalar(metadata[key])
+		if !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// metadataScalar returns v as a string, float64 or bool, or false if it is
+// none of them (nil, an object, an array).
+func metadataScalar(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case string, bool, float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// archivedFilter returns a WHERE fragment (prefixed with " AND ") excluding
+// archived memories, or "" when includeArchived is set. alias is an optional
+// table prefix ("m.").
+func archivedFilter(alias string, includeArchived bool) string {
+	if includeArchived {
+		return ""
+	}
+	return fmt.Sprintf(" AND (%[1]sstate IS NULL OR %[1]sstate != '%[2]s')", alias, types.StateArchived)
+}
+
+// FindStaleMemories returns the IDs of memories matching criteria, lowest
+// decay score first.
+func (s *MemoryStore) FindStaleMemories(ctx context.Context, criteria storage.StaleCriteria) ([]string, error) {
+	query := `
+		SELECT id
+		FROM memories
+		WHERE deleted_at IS NULL` + archivedFilter("", false) + `
+			AND decay_score < $1
+			AND COALESCE(last_accessed_at, created_at) < $2
+			AND ($3 < 0 OR access_count <= $3)
+			AND NOT COALESCE(tags ? $4, false)
+		ORDER BY decay_score ASC, id ASC`
+	args := []interface{}{criteria.MaxDecayScore, criteria.AccessedBefore.UTC(), criteria.MaxAccessCount, types.PinnedTag}
+	if criteria.Limit > 0 {
+		query += ` LIMIT $5`
+		args = append(args, criteria.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: failed to find stale memories: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("postgres: failed to scan stale memory: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ArchiveMemories moves each memory to the archived state without applying
+// the lifecycle state machine, recording each transition in the state
+// history, and returns how many memories changed. Missing, deleted and
+// already archived memories are skipped.
+func (s *MemoryStore) ArchiveMemories(ctx context.Context, ids []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: failed to archive memories: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now().UTC()
+	archived := 0
+	for _, id := range ids {
+		var current sql.NullString
+		err := tx.QueryRowContext(ctx,
+			`SELECT state FROM memories WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id,
+		).Scan(&current)
+		if errors.Is(err, sql.ErrNoRows) || current.String == types.StateArchived {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("postgres: failed to archive memory %s: %w", id, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE memories SET state = $1, state_updated_at = $2, updated_at = $2 WHERE id = $3`,
+			types.StateArchived, now, id,
+		); err != nil {
+			return 0, fmt.Errorf("postgres: failed to archive memory %s: %w", id, err)
+		}
+		if err := recordStateTransition(ctx, tx, id, current.String, types.StateArchived, now); err != nil {
+			return 0, fmt.Errorf("postgres: failed to record archive of memory %s: %w", id, err)
+		}
+		archived++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("postgres: failed to archive memories: %w", err)
+	}
+	return archived, nil
+}
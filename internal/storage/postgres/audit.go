@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// AppendAuditEntry records a mutating operation in the audit log. It
+// implements storage.AuditLog.
+func (s *MemoryStore) AppendAuditEntry(ctx context.Context, entry *storage.AuditEntry) error {
+	if entry == nil || entry.Tool == "" {
+		return fmt.Errorf("%w: audit entry tool is required", storage.ErrInvalidInput)
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	memoryIDs := entry.MemoryIDs
+	if memoryIDs == nil {
+		memoryIDs = []string{}
+	}
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO audit_log (created_at, actor, author_type, tool, memory_ids, connection)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		entry.Timestamp.UTC(), entry.Actor, entry.AuthorType, entry.Tool, pq.Array(memoryIDs), entry.Connection,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("postgres: AppendAuditEntry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns the audit entries matching filter, newest first.
+// It implements storage.AuditLog.
+func (s *MemoryStore) ListAuditEntries(ctx context.Context, filter storage.AuditFilter) ([]storage.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filter.MemoryID != "" {
+		conditions = append(conditions, "memory_ids @> ARRAY["+arg(filter.MemoryID)+"]::TEXT[]")
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = "+arg(filter.Actor))
+	}
+	if filter.Tool != "" {
+		conditions = append(conditions, "tool = "+arg(filter.Tool))
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(filter.Since.UTC()))
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(filter.Until.UTC()))
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = storage.DefaultLimit
+	}
+
+	query := `SELECT id, created_at, actor, author_type, tool, memory_ids, connection FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT " + arg(limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: ListAuditEntries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []storage.AuditEntry{}
+	for rows.Next() {
+		var e storage.AuditEntry
+		if err := rows.Scan(&e.ID, &e.Timestamp, &e.Actor, &e.AuthorType, &e.Tool, pq.Array(&e.MemoryIDs), &e.Connection); err != nil {
+			return nil, fmt.Errorf("postgres: ListAuditEntries: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: ListAuditEntries: %w", err)
+	}
+	return entries, nil
+}
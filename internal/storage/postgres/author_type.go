@@ -0,0 +1,8 @@
+package postgres
+
+// MigrationAuthorType adds the author_type column to databases created before
+// authorship classification. Safe to run multiple times.
+const MigrationAuthorType = `
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS author_type TEXT;
+CREATE INDEX IF NOT EXISTS idx_memories_author_type ON memories(author_type) WHERE author_type IS NOT NULL;
+`
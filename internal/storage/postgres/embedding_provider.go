@@ -5,7 +5,7 @@ import (
 	"database/sql"
 	"encoding/binary"
 	"fmt"
-	"log"
+	"log/slog"
 	"unsafe"
 
 	pgvector "github.com/pgvector/pgvector-go"
@@ -26,7 +26,8 @@ func NewEmbeddingProvider(db *sql.DB, pgvectorAvailable bool) *EmbeddingProvider
 	return &EmbeddingProvider{db: db, pgvectorAvailable: pgvectorAvailable}
 }
 
-// StoreEmbedding stores a vector embedding for a memory.
+// StoreEmbedding stores a vector embedding for a memory, replacing any
+// earlier embedding from the same model. Embeddings from other models are kept.
 // The embedding is always stored in the binary BYTEA column for backward
 // compatibility. When pgvector is available it is also stored in embedding_vec
 // for efficient cosine-distance queries.
@@ -70,10 +71,9 @@ func (p *EmbeddingProvider) StoreEmbedding(ctx context.Context, memoryID string,
 		query := `
 			INSERT INTO embeddings (memory_id, embedding, dimension, model, embedding_vec, created_at, updated_at)
 			VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-			ON CONFLICT(memory_id) DO UPDATE SET
+			ON CONFLICT(memory_id, model) DO UPDATE SET
 				embedding = excluded.embedding,
 				dimension = excluded.dimension,
-				model = excluded.model,
 				embedding_vec = excluded.embedding_vec,
 				updated_at = CURRENT_TIMESTAMP
 		`
@@ -81,7 +81,7 @@ func (p *EmbeddingProvider) StoreEmbedding(ctx context.Context, memoryID string,
 		_, err = p.db.ExecContext(ctx, query, memoryID, embeddingBytes, dimension, model, vec)
 		if err != nil {
 			// Pgvector store failed — fall back to BYTEA-only path and log.
-			log.Printf("postgres: failed to store embedding_vec (falling back to BYTEA only): %v", err)
+			slog.Warn("postgres: failed to store embedding_vec, falling back to BYTEA only", "error", err)
 			goto byteaOnly
 		}
 		return nil
@@ -91,10 +91,9 @@ byteaOnly:
 	query := `
 		INSERT INTO embeddings (memory_id, embedding, dimension, model, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		ON CONFLICT(memory_id) DO UPDATE SET
+		ON CONFLICT(memory_id, model) DO UPDATE SET
 			embedding = excluded.embedding,
 			dimension = excluded.dimension,
-			model = excluded.model,
 			updated_at = CURRENT_TIMESTAMP
 	`
 
@@ -106,7 +105,7 @@ byteaOnly:
 	return nil
 }
 
-// GetEmbedding retrieves the embedding for a memory.
+// GetEmbedding retrieves the most recently stored embedding for a memory.
 // Returns the embedding vector or storage.ErrNotFound if not found.
 func (p *EmbeddingProvider) GetEmbedding(ctx context.Context, memoryID string) ([]float64, error) {
 	if memoryID == "" {
@@ -117,6 +116,8 @@ func (p *EmbeddingProvider) GetEmbedding(ctx context.Context, memoryID string) (
 		SELECT embedding, dimension
 		FROM embeddings
 		WHERE memory_id = $1
+		ORDER BY updated_at DESC, model DESC
+		LIMIT 1
 	`
 
 	var embeddingBytes []byte
@@ -139,6 +140,103 @@ func (p *EmbeddingProvider) GetEmbedding(ctx context.Context, memoryID string) (
 	return embedding, nil
 }
 
+// embeddingChoiceFilter returns a WHERE fragment (prefixed with " AND ") that
+// keeps one row of embeddings alias e per memory: the one from model when
+// set, otherwise the most recently stored one of its dimension. argN is the
+// placeholder number used for model.
+func embeddingChoiceFilter(model string, argN int) (string, []interface{}) {
+	if model != "" {
+		return fmt.Sprintf(" AND e.model = $%d", argN), []interface{}{model}
+	}
+	return ` AND NOT EXISTS (
+			SELECT 1 FROM embeddings e2
+			WHERE e2.memory_id = e.memory_id AND e2.dimension = e.dimension
+				AND (e2.updated_at > e.updated_at OR (e2.updated_at = e.updated_at AND e2.model > e.model)))`, nil
+}
+
+// checkEmbeddingDimension returns an error wrapping storage.ErrDimensionMismatch
+// when active memories have embeddings of a dimension other than dim. It
+// reports the most common stored dimension and model so the caller can tell
+// which model the store was embedded with.
+func (s *MemoryStore) checkEmbeddingDimension(ctx context.Context, dim int) error {
+	var storedDim int
+	var model string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT e.dimension, e.model
+		FROM embeddings e
+		JOIN memories m ON m.id = e.memory_id
+		WHERE m.deleted_at IS NULL AND e.dimension <> $1
+		GROUP BY e.dimension, e.model
+		ORDER BY COUNT(*) DESC
+		LIMIT 1`, dim,
+	).Scan(&storedDim, &model)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("postgres: failed to check embedding dimensions: %w", err)
+	}
+	return fmt.Errorf("%w: query embedding has %d dimensions, stored embeddings have %d (model %q)",
+		storage.ErrDimensionMismatch, dim, storedDim, model)
+}
+
+// SetEmbedding stores the embedding for memory id in the BYTEA column and,
+// when pgvector is available, in embedding_vec. The model is recorded so a
+// later provider or dimension change can be detected via GetEmbedding;
+// embeddings from other models are kept alongside it.
+// Returns storage.ErrNotFound if the memory doesn't exist.
+func (s *MemoryStore) SetEmbedding(ctx context.Context, id string, vec []float64, model string) error {
+	if id == "" {
+		return fmt.Errorf("%w: memory ID is required", storage.ErrInvalidInput)
+	}
+
+	found, err := s.exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return storage.ErrNotFound
+	}
+
+	return NewEmbeddingProvider(s.db, s.pgvectorAvailable).StoreEmbedding(ctx, id, vec, len(vec), model)
+}
+
+// GetEmbedding returns the most recently stored embedding for memory id and
+// the model that produced it. Returns storage.ErrNotFound if the memory has
+// no embedding.
+func (s *MemoryStore) GetEmbedding(ctx context.Context, id string) ([]float64, string, error) {
+	if id == "" {
+		return nil, "", fmt.Errorf("%w: memory ID is required", storage.ErrInvalidInput)
+	}
+
+	query := `
+		SELECT embedding, dimension, model
+		FROM embeddings
+		WHERE memory_id = $1
+		ORDER BY updated_at DESC, model DESC
+		LIMIT 1
+	`
+
+	var embeddingBytes []byte
+	var dimension int
+	var model string
+
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&embeddingBytes, &dimension, &model)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", storage.ErrNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get embedding: %w", err)
+	}
+
+	embedding, err := deserializeEmbedding(embeddingBytes, dimension)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to deserialize embedding: %w", err)
+	}
+
+	return embedding, model, nil
+}
+
 // DeleteEmbedding removes an embedding from the database.
 // Returns storage.ErrNotFound if the embedding doesn't exist.
 func (p *EmbeddingProvider) DeleteEmbedding(ctx context.Context, memoryID string) error {
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// MergeEntities merges the duplicate entities into canonicalID in one
+// transaction. See storage.EntityMerger.
+func (s *MemoryStore) MergeEntities(ctx context.Context, canonicalID string, duplicateIDs []string) (*storage.EntityMergeResult, error) {
+	if len(duplicateIDs) == 0 {
+		return nil, fmt.Errorf("%w: no duplicate entities given", storage.ErrInvalidInput)
+	}
+	seen := map[string]bool{canonicalID: true}
+	for _, id := range duplicateIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: entity %s given twice", storage.ErrInvalidInput, id)
+		}
+		seen[id] = true
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: MergeEntities: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, id := range append([]string{canonicalID}, duplicateIDs...) {
+		var exists int
+		err := tx.QueryRowContext(ctx, `SELECT 1 FROM entities WHERE id = $1 FOR UPDATE`, id).Scan(&exists)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: entity %s", storage.ErrNotFound, id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("postgres: MergeEntities get %s: %w", id, err)
+		}
+	}
+
+	// exec runs one statement of the merge and returns the rows it changed.
+	exec := func(step, id, query string, args ...interface{}) (int, error) {
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("postgres: MergeEntities %s %s: %w", step, id, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("postgres: MergeEntities %s %s: %w", step, id, err)
+		}
+		return int(n), nil
+	}
+
+	now := time.Now()
+	result := &storage.EntityMergeResult{CanonicalID: canonicalID, MergedIDs: duplicateIDs}
+	for _, id := range duplicateIDs {
+		// Move the associations of memories not already linked to the
+		// canonical entity; the rest are deleted with the duplicate.
+		n, err := exec("memories", id, `
+			UPDATE memory_entities SET entity_id = $1
+			WHERE entity_id = $2
+			  AND memory_id NOT IN (SELECT memory_id FROM memory_entities WHERE entity_id = $3)`,
+			canonicalID, id, canonicalID)
+		if err != nil {
+			return nil, err
+		}
+		result.MemoriesRepointed += n
+
+		// Move relationships unless the canonical entity already has the
+		// same one or they would link it to itself; those are dropped.
+		n, err = exec("outgoing", id, `
+			UPDATE relationships SET source_id = $1, updated_at = $2
+			WHERE source_id = $3 AND target_id != $4
+			  AND NOT EXISTS (
+			      SELECT 1 FROM relationships r
+			      WHERE r.source_id = $5 AND r.target_id = relationships.target_id AND r.type = relationships.type)`,
+			canonicalID, now, id, canonicalID, canonicalID)
+		if err != nil {
+			return nil, err
+		}
+		result.RelationshipsRepointed += n
+
+		n, err = exec("incoming", id, `
+			UPDATE relationships SET target_id = $1, updated_at = $2
+			WHERE target_id = $3 AND source_id != $4
+			  AND NOT EXISTS (
+			      SELECT 1 FROM relationships r
+			      WHERE r.source_id = relationships.source_id AND r.target_id = $5 AND r.type = relationships.type)`,
+			canonicalID, now, id, canonicalID, canonicalID)
+		if err != nil {
+			return nil, err
+		}
+		result.RelationshipsRepointed += n
+
+		n, err = exec("drop relationships", id,
+			`DELETE FROM relationships WHERE source_id = $1 OR target_id = $2`, id, id)
+		if err != nil {
+			return nil, err
+		}
+		result.RelationshipsDropped += n
+
+		if _, err := exec("drop memories", id, `DELETE FROM memory_entities WHERE entity_id = $1`, id); err != nil {
+			return nil, err
+		}
+		if _, err := exec("delete", id, `DELETE FROM entities WHERE id = $1`, id); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := exec("touch", canonicalID, `UPDATE entities SET updated_at = $1 WHERE id = $2`, now, canonicalID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("postgres: MergeEntities commit: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MigrationExpiresAt adds the expires_at column to databases created before
+// per-memory expiry. Safe to run multiple times.
+const MigrationExpiresAt = `
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_memories_expires_at ON memories(expires_at) WHERE expires_at IS NOT NULL;
+`
+
+// expiryFilter returns a WHERE fragment (prefixed with " AND ") excluding
+// expired memories, using placeholder $argPos for the current time, plus its
+// bound arguments. Both are empty when includeExpired is set.
+//
+// expires_at is a TIMESTAMP without time zone, so both sides of the
+// comparison are bound as UTC.
+func expiryFilter(alias string, argPos int, includeExpired bool) (string, []interface{}) {
+	if includeExpired {
+		return "", nil
+	}
+	cond := fmt.Sprintf(" AND (%[1]sexpires_at IS NULL OR %[1]sexpires_at > $%[2]d)", alias, argPos)
+	return cond, []interface{}{time.Now().UTC()}
+}
+
+// ExpireMemories soft-deletes every memory whose expires_at is at or before
+// now and returns how many were expired. Already-deleted memories are left
+// untouched so their original deleted_at is preserved.
+func (s *MemoryStore) ExpireMemories(ctx context.Context, now time.Time) (int, error) {
+	now = now.UTC()
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE memories
+		SET deleted_at = $1, updated_at = $1
+		WHERE deleted_at IS NULL AND expires_at IS NOT NULL AND expires_at <= $1`,
+		now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("postgres: failed to expire memories: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("postgres: failed to count expired memories: %w", err)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// addFuzzyMatches appends to result memories containing a word whose pg_trgm
+// word_similarity to a query word is at least opts.FuzzyThreshold, most
+// similar first, up to opts.FuzzyMaxResults and opts.Limit in total. Only
+// the first page of a natural-language query is fuzzy-matched, and nothing
+// is added when pg_trgm is not installed.
+func (s *MemoryStore) addFuzzyMatches(ctx context.Context, result *storage.PaginatedResult[types.Memory], opts storage.SearchOptions) error {
+	room := min(opts.FuzzyMaxResults, opts.Limit-len(result.Items))
+	if room <= 0 || opts.Offset > 0 || !s.trgmAvailable || storage.ParseSearchQuery(opts.Query) != nil {
+		return nil
+	}
+	words := storage.FuzzyQueryWords(opts.Query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	// Score each memory by its best-matching query word.
+	args := []interface{}{opts.FuzzyThreshold, len(result.Items) + room}
+	similarities := make([]string, len(words))
+	for i, w := range words {
+		args = append(args, w)
+		similarities[i] = fmt.Sprintf("word_similarity($%d, content)", len(args))
+	}
+	score := "GREATEST(" + strings.Join(similarities, ", ") + ")"
+	if len(similarities) == 1 {
+		score = similarities[0]
+	}
+	expiryCond, expiryArgs := expiryFilter("", len(args)+1, opts.IncludeExpired)
+	expiryCond += archivedFilter("", opts.IncludeArchived)
+	args = append(args, expiryArgs...)
+
+	// The exact matches usually score highest, so ask for enough rows to
+	// fill the room after skipping them.
+	querySQL := `
+		SELECT ` + memorySelectColumns + `
+		FROM memories
+		WHERE ` + score + ` >= $1 AND deleted_at IS NULL` + expiryCond + `
+		ORDER BY ` + score + ` DESC
+		LIMIT $2
+	`
+	rows, err := s.db.QueryContext(ctx, querySQL, args...)
+	if err != nil {
+		return fmt.Errorf("postgres: fuzzy search %q: %w", opts.Query, err)
+	}
+	defer func() { _ = rows.Close() }()
+	fuzzy, err := scanMemoryRows(rows)
+	if err != nil {
+		return fmt.Errorf("postgres: fuzzy search scan: %w", err)
+	}
+
+	seen := make(map[string]bool, len(result.Items))
+	for _, m := range result.Items {
+		seen[m.ID] = true
+	}
+	for _, m := range fuzzy {
+		if seen[m.ID] || room == 0 {
+			continue
+		}
+		result.Items = append(result.Items, m)
+		result.Total++
+		room--
+	}
+	return nil
+}
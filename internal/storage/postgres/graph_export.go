@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// ExportGraph returns the graph of the memories matching opts. It
+// implements storage.GraphExporter.
+func (s *MemoryStore) ExportGraph(ctx context.Context, opts storage.GraphExportOptions) (*storage.GraphExport, error) {
+	if opts.MaxNodes < 1 {
+		return nil, fmt.Errorf("%w: MaxNodes must be positive", storage.ErrInvalidInput)
+	}
+	graph := &storage.GraphExport{
+		Memories:      []types.Memory{},
+		Entities:      []types.Entity{},
+		Mentions:      []storage.EntityMention{},
+		Relationships: []types.Relationship{},
+		Links:         []storage.MemoryLink{},
+	}
+
+	expiry, args := expiryFilter("", 1, false)
+	conditions := []string{"deleted_at IS NULL" + expiry}
+	if opts.Domain != "" {
+		args = append(args, opts.Domain)
+		conditions = append(conditions, fmt.Sprintf("domain = $%d", len(args)))
+	}
+	if !opts.CreatedAfter.IsZero() {
+		args = append(args, opts.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at > $%d", len(args)))
+	}
+	if !opts.CreatedBefore.IsZero() {
+		args = append(args, opts.CreatedBefore)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	// One extra row tells whether the cap cut the memories short.
+	args = append(args, opts.MaxNodes+1)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, content, domain, memory_type, state, created_at
+		FROM memories
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY created_at DESC, id
+		LIMIT $`+fmt.Sprint(len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: ExportGraph memories: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var m types.Memory
+		var domain, memoryType, state sql.NullString
+		if err := rows.Scan(&m.ID, &m.Content, &domain, &memoryType, &state, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("postgres: ExportGraph memories scan: %w", err)
+		}
+		m.Domain, m.MemoryType, m.State = domain.String, memoryType.String, state.String
+		graph.Memories = append(graph.Memories, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: ExportGraph memories rows: %w", err)
+	}
+	if len(graph.Memories) > opts.MaxNodes {
+		graph.Memories = graph.Memories[:opts.MaxNodes]
+		graph.Truncated = true
+	}
+	if len(graph.Memories) == 0 {
+		return graph, nil
+	}
+
+	memoryIDs := make([]string, len(graph.Memories))
+	for i, m := range graph.Memories {
+		memoryIDs[i] = m.ID
+	}
+
+	// Entities fill the nodes the memories left, most mentioned first.
+	remaining := opts.MaxNodes - len(graph.Memories)
+	entRows, err := s.db.QueryContext(ctx, `
+		SELECT e.id, e.name, e.type, e.description, e.created_at, e.updated_at, COUNT(*) AS memory_count
+		FROM memory_entities me
+		JOIN entities e ON e.id = me.entity_id
+		WHERE me.memory_id = ANY($1)
+		GROUP BY e.id
+		ORDER BY memory_count DESC, e.name, e.id
+		LIMIT $2`, pq.Array(memoryIDs), remaining+1)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: ExportGraph entities: %w", err)
+	}
+	defer func() { _ = entRows.Close() }()
+	for entRows.Next() {
+		var e types.Entity
+		var desc sql.NullString
+		if err := entRows.Scan(&e.ID, &e.Name, &e.Type, &desc, &e.CreatedAt, &e.UpdatedAt, &e.MemoryCount); err != nil {
+			return nil, fmt.Errorf("postgres: ExportGraph entities scan: %w", err)
+		}
+		e.Description = desc.String
+		graph.Entities = append(graph.Entities, e)
+	}
+	if err := entRows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: ExportGraph entities rows: %w", err)
+	}
+	if len(graph.Entities) > remaining {
+		graph.Entities = graph.Entities[:remaining]
+		graph.Truncated = true
+	}
+
+	if len(graph.Entities) > 0 {
+		entityIDs := make([]string, len(graph.Entities))
+		for i, e := range graph.Entities {
+			entityIDs[i] = e.ID
+		}
+
+		mentionRows, err := s.db.QueryContext(ctx, `
+			SELECT memory_id, entity_id, frequency, confidence
+			FROM memory_entities
+			WHERE memory_id = ANY($1) AND entity_id = ANY($2)
+			ORDER BY memory_id, entity_id`, pq.Array(memoryIDs), pq.Array(entityIDs))
+		if err != nil {
+			return nil, fmt.Errorf("postgres: ExportGraph mentions: %w", err)
+		}
+		defer func() { _ = mentionRows.Close() }()
+		for mentionRows.Next() {
+			var m storage.EntityMention
+			if err := mentionRows.Scan(&m.MemoryID, &m.EntityID, &m.Frequency, &m.Confidence); err != nil {
+				return nil, fmt.Errorf("postgres: ExportGraph mentions scan: %w", err)
+			}
+			graph.Mentions = append(graph.Mentions, m)
+		}
+		if err := mentionRows.Err(); err != nil {
+			return nil, fmt.Errorf("postgres: ExportGraph mentions rows: %w", err)
+		}
+
+		relRows, err := s.db.QueryContext(ctx, `
+			SELECT id, source_id, target_id, type, weight, created_at, updated_at
+			FROM relationships
+			WHERE source_id = ANY($1) AND target_id = ANY($1)
+			ORDER BY id`, pq.Array(entityIDs))
+		if err != nil {
+			return nil, fmt.Errorf("postgres: ExportGraph relationships: %w", err)
+		}
+		defer func() { _ = relRows.Close() }()
+		for relRows.Next() {
+			var rel types.Relationship
+			if err := relRows.Scan(&rel.ID, &rel.FromID, &rel.ToID, &rel.Type, &rel.Strength, &rel.CreatedAt, &rel.UpdatedAt); err != nil {
+				return nil, fmt.Errorf("postgres: ExportGraph relationships scan: %w", err)
+			}
+			graph.Relationships = append(graph.Relationships, rel)
+		}
+		if err := relRows.Err(); err != nil {
+			return nil, fmt.Errorf("postgres: ExportGraph relationships rows: %w", err)
+		}
+	}
+
+	linkRows, err := s.db.QueryContext(ctx, `
+		SELECT id, source_id, target_id, type, created_at
+		FROM memory_links
+		WHERE source_id = ANY($1) AND target_id = ANY($1)
+		ORDER BY created_at, id`, pq.Array(memoryIDs))
+	if err != nil {
+		return nil, fmt.Errorf("postgres: ExportGraph links: %w", err)
+	}
+	defer func() { _ = linkRows.Close() }()
+	for linkRows.Next() {
+		var link storage.MemoryLink
+		if err := linkRows.Scan(&link.ID, &link.SourceID, &link.TargetID, &link.Type, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("postgres: ExportGraph links scan: %w", err)
+		}
+		graph.Links = append(graph.Links, link)
+	}
+	if err := linkRows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: ExportGraph links rows: %w", err)
+	}
+
+	return graph, nil
+}
@@ -19,3 +19,9 @@ func (s *MemoryStore) TruncateForTest(ctx context.Context) error {
 	}
 	return nil
 }
+
+// SetPgvectorAvailableForTest overrides pgvector detection so tests can
+// exercise the full-text-only fallback against a pgvector-enabled server.
+func (s *MemoryStore) SetPgvectorAvailableForTest(available bool) {
+	s.pgvectorAvailable = available
+}
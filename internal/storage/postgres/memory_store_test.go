@@ -19,7 +19,7 @@ import (
 
 // postgresTestDSN returns the DSN for the test database.
 // If POSTGRES_TEST_DSN is not set, tests are skipped.
-func postgresTestDSN(t *testing.T) string {
+func postgresTestDSN(t testing.TB) string {
 	t.Helper()
 
 	dsn := os.Getenv("POSTGRES_TEST_DSN")
@@ -63,6 +63,35 @@ func newTestMemory(id string) *types.Memory {
 	}
 }
 
+// ---- Connection pool tests ----
+
+func TestNewMemoryStore_PoolSettings(t *testing.T) {
+	dsn := postgresTestDSN(t)
+
+	store, err := postgres.NewMemoryStore(dsn, postgres.WithPool(postgres.PoolConfig{
+		MaxOpenConns:    7,
+		MaxIdleConns:    3,
+		ConnMaxLifetime: time.Minute,
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	assert.Equal(t, 7, store.GetDB().Stats().MaxOpenConnections)
+}
+
+func TestNewMemoryStore_RejectsNegativePoolSettings(t *testing.T) {
+	// Validation happens before connecting, so no database is needed.
+	for _, pool := range []postgres.PoolConfig{
+		{MaxOpenConns: -1},
+		{MaxIdleConns: -1},
+		{ConnMaxLifetime: -time.Second},
+	} {
+		_, err := postgres.NewMemoryStore("postgres://unused", postgres.WithPool(pool))
+		require.Error(t, err, "%+v", pool)
+		assert.Contains(t, err.Error(), "non-negative")
+	}
+}
+
 // ---- Store tests ----
 
 func TestStore_NilMemory(t *testing.T) {
@@ -453,6 +482,49 @@ func TestList_StatusFilter(t *testing.T) {
 	assert.Len(t, result.Items, 2)
 }
 
+func TestList_MetadataFilter(t *testing.T) {
+	store := newTestStore(t)
+	truncateMemories(t, store)
+	ctx := context.Background()
+
+	for id, metadata := range map[string]map[string]interface{}{
+		"mem:test:meta-atlas":    {"project": "atlas", "priority": 1, "urgent": true},
+		"mem:test:meta-atlas-lo": {"project": "atlas", "priority": 3, "urgent": false},
+		"mem:test:meta-string":   {"project": "zeus", "priority": "1"},
+		"mem:test:meta-none":     nil,
+	} {
+		mem := newTestMemory(id)
+		mem.Metadata = metadata
+		require.NoError(t, store.Store(ctx, mem))
+	}
+
+	cases := []struct {
+		name     string
+		metadata map[string]interface{}
+		want     []string
+	}{
+		{"string", map[string]interface{}{"project": "atlas"}, []string{"mem:test:meta-atlas", "mem:test:meta-atlas-lo"}},
+		{"number", map[string]interface{}{"priority": 1}, []string{"mem:test:meta-atlas"}},
+		{"string is not number", map[string]interface{}{"priority": "1"}, []string{"mem:test:meta-string"}},
+		{"bool", map[string]interface{}{"urgent": false}, []string{"mem:test:meta-atlas-lo"}},
+		{"all keys", map[string]interface{}{"project": "atlas", "urgent": true}, []string{"mem:test:meta-atlas"}},
+		{"no match", map[string]interface{}{"owner": "bob"}, nil},
+	}
+	for _, tc := range cases {
+		result, err := store.List(ctx, storage.ListOptions{Limit: 100, Metadata: tc.metadata})
+		require.NoError(t, err, tc.name)
+		var got []string
+		for _, m := range result.Items {
+			got = append(got, m.ID)
+		}
+		assert.ElementsMatch(t, tc.want, got, tc.name)
+		assert.Equal(t, len(tc.want), result.Total, tc.name)
+	}
+
+	_, err := store.List(ctx, storage.ListOptions{Metadata: map[string]interface{}{"project": nil}})
+	assert.ErrorIs(t, err, storage.ErrInvalidInput)
+}
+
 // ---- Update tests ----
 
 func TestUpdate_NotFound(t *testing.T) {
@@ -569,3 +641,201 @@ func TestNullableTimestamps_AllNil(t *testing.T) {
 	assert.Nil(t, got.LastAccessedAt)
 	assert.Nil(t, got.DecayUpdatedAt)
 }
+
+// ---- Embedding tests ----
+
+func TestSetEmbedding_RoundTripsVectorAndModel(t *testing.T) {
+	store := newTestStore(t)
+	truncateMemories(t, store)
+	ctx := context.Background()
+	require.NoError(t, store.Store(ctx, newTestMemory("mem:test:emb")))
+
+	require.NoError(t, store.SetEmbedding(ctx, "mem:test:emb", []float64{0.1, 0.2, 0.3}, "nomic-embed-text"))
+
+	vec, model, err := store.GetEmbedding(ctx, "mem:test:emb")
+	require.NoError(t, err)
+	assert.Equal(t, "nomic-embed-text", model)
+	assert.Equal(t, []float64{0.1, 0.2, 0.3}, vec)
+
+	// Re-embedding with another model returns the new vector and model; the
+	// earlier model's embedding is kept alongside it.
+	require.NoError(t, store.SetEmbedding(ctx, "mem:test:emb", []float64{0.5, 0.5}, "text-embedding-3-small"))
+	vec, model, err = store.GetEmbedding(ctx, "mem:test:emb")
+	require.NoError(t, err)
+	assert.Equal(t, "text-embedding-3-small", model)
+	assert.Len(t, vec, 2)
+
+	var count int
+	require.NoError(t, store.GetDB().QueryRowContext(ctx, `SELECT COUNT(*) FROM embeddings WHERE memory_id = $1`, "mem:test:emb").Scan(&count))
+	assert.Equal(t, 2, count)
+}
+
+// TestMigrationEmbeddingsKey_KeepsEmbeddings re-keys an embeddings table
+// keyed by memory_id alone and checks existing embeddings survive and a
+// second model can then be stored.
+func TestMigrationEmbeddingsKey_KeepsEmbeddings(t *testing.T) {
+	store := newTestStore(t)
+	truncateMemories(t, store)
+	ctx := context.Background()
+	db := store.GetDB()
+	require.NoError(t, store.Store(ctx, newTestMemory("mem:test:legacy")))
+	require.NoError(t, store.SetEmbedding(ctx, "mem:test:legacy", []float64{0.1, 0.2, 0.3}, "nomic-embed-text"))
+
+	var pkName string
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT conname FROM pg_constraint WHERE conrelid = 'embeddings'::regclass AND contype = 'p'`).Scan(&pkName))
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE embeddings DROP CONSTRAINT %q`, pkName))
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `ALTER TABLE embeddings ADD PRIMARY KEY (memory_id)`)
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, postgres.MigrationEmbeddingsKey)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, postgres.MigrationEmbeddingsKey)
+	require.NoError(t, err, "migration should be idempotent")
+
+	vec, model, err := store.GetEmbedding(ctx, "mem:test:legacy")
+	require.NoError(t, err)
+	assert.Equal(t, "nomic-embed-text", model)
+	assert.Equal(t, []float64{0.1, 0.2, 0.3}, vec)
+
+	require.NoError(t, store.SetEmbedding(ctx, "mem:test:legacy", []float64{0.5, 0.5}, "text-embedding-3-small"))
+	var pkColumns int
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT array_length(conkey, 1) FROM pg_constraint WHERE conrelid = 'embeddings'::regclass AND contype = 'p'`).Scan(&pkColumns))
+	assert.Equal(t, 2, pkColumns)
+}
+
+func TestSetEmbedding_NotFound(t *testing.T) {
+	store := newTestStore(t)
+	truncateMemories(t, store)
+	ctx := context.Background()
+
+	err := store.SetEmbedding(ctx, "mem:test:missing", []float64{0.1}, "model")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, store.Store(ctx, newTestMemory("mem:test:plain")))
+	_, _, err = store.GetEmbedding(ctx, "mem:test:plain")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestExpiry_ListAndSearchExcludeExpired(t *testing.T) {
+	store := newTestStore(t)
+	truncateMemories(t, store)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	expired := newTestMemory("mem:test:expired")
+	expired.Content = "heron feeding schedule"
+	expired.ExpiresAt = &past
+	pending := newTestMemory("mem:test:pending")
+	pending.Content = "heron nesting survey"
+	pending.ExpiresAt = &future
+	require.NoError(t, store.Store(ctx, expired))
+	require.NoError(t, store.Store(ctx, pending))
+
+	got, err := store.Get(ctx, "mem:test:pending")
+	require.NoError(t, err)
+	require.NotNil(t, got.ExpiresAt)
+	assert.WithinDuration(t, future, *got.ExpiresAt, time.Millisecond)
+
+	list, err := store.List(ctx, storage.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "mem:test:pending", list.Items[0].ID)
+
+	list, err = store.List(ctx, storage.ListOptions{IncludeExpired: true})
+	require.NoError(t, err)
+	assert.Len(t, list.Items, 2)
+
+	search, err := store.FullTextSearch(ctx, storage.SearchOptions{Query: "heron"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, search.Total)
+
+	search, err = store.FullTextSearch(ctx, storage.SearchOptions{Query: "heron", IncludeExpired: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, search.Total)
+}
+
+func TestExpireMemories_SoftDeletesExpired(t *testing.T) {
+	store := newTestStore(t)
+	truncateMemories(t, store)
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Minute)
+	expired := newTestMemory("mem:test:expired")
+	expired.ExpiresAt = &past
+	require.NoError(t, store.Store(ctx, expired))
+	require.NoError(t, store.Store(ctx, newTestMemory("mem:test:forever")))
+
+	n, err := store.ExpireMemories(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = store.Get(ctx, "mem:test:expired")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = store.Get(ctx, "mem:test:forever")
+	assert.NoError(t, err)
+
+	n, err = store.ExpireMemories(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestAuthorType_StoreAndListFilter(t *testing.T) {
+	store := newTestStore(t)
+	truncateMemories(t, store)
+	ctx := context.Background()
+
+	human := newTestMemory("mem:test:human")
+	human.AuthorType = "human"
+	agent := newTestMemory("mem:test:agent")
+	agent.AuthorType = "agent"
+	require.NoError(t, store.Store(ctx, human))
+	require.NoError(t, store.Store(ctx, agent))
+
+	got, err := store.Get(ctx, "mem:test:human")
+	require.NoError(t, err)
+	assert.Equal(t, "human", got.AuthorType)
+
+	list, err := store.List(ctx, storage.ListOptions{AuthorType: "agent"})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "mem:test:agent", list.Items[0].ID)
+}
+
+func TestListEntities_TypeFilterAndCounts(t *testing.T) {
+	store := newTestStore(t)
+	truncateMemories(t, store)
+	ctx := context.Background()
+	db := store.GetDB()
+
+	_, err := db.ExecContext(ctx, "DELETE FROM entities WHERE id LIKE 'ent:test:%'")
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = db.ExecContext(context.Background(), "DELETE FROM entities WHERE id LIKE 'ent:test:%'") })
+
+	for _, id := range []string{"mem:test:e1", "mem:test:e2"} {
+		require.NoError(t, store.Store(ctx, newTestMemory(id)))
+	}
+	for id, typ := range map[string]string{"ent:test:alice": "person", "ent:test:bob": "person", "ent:test:go": "language"} {
+		_, err := db.ExecContext(ctx, "INSERT INTO entities (id, name, type) VALUES ($1, $2, $3)", id, strings.TrimPrefix(id, "ent:test:"), typ)
+		require.NoError(t, err)
+	}
+	for _, link := range [][2]string{
+		{"mem:test:e1", "ent:test:alice"}, {"mem:test:e2", "ent:test:alice"},
+		{"mem:test:e1", "ent:test:bob"}, {"mem:test:e2", "ent:test:go"},
+	} {
+		_, err := db.ExecContext(ctx, "INSERT INTO memory_entities (memory_id, entity_id) VALUES ($1, $2)", link[0], link[1])
+		require.NoError(t, err)
+	}
+
+	result, err := store.ListEntities(ctx, storage.EntityListOptions{Type: "person"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, "ent:test:alice", result.Items[0].ID)
+	assert.Equal(t, 2, result.Items[0].MemoryCount)
+	assert.False(t, result.Items[0].LastSeen.IsZero())
+	assert.Equal(t, 1, result.Items[1].MemoryCount)
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Connection pool defaults used when WithPool is not given.
+const (
+	DefaultMaxOpenConns    = 25
+	DefaultMaxIdleConns    = 5
+	DefaultConnMaxLifetime = 5 * time.Minute
+)
+
+// PoolConfig sizes the database/sql connection pool of a MemoryStore.
+// Zero follows database/sql: MaxOpenConns 0 means unlimited, MaxIdleConns 0
+// keeps no idle connections and ConnMaxLifetime 0 never recycles them.
+// Negative values are rejected by NewMemoryStore.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// DefaultPoolConfig returns the pool settings used when WithPool is not
+// given.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    DefaultMaxOpenConns,
+		MaxIdleConns:    DefaultMaxIdleConns,
+		ConnMaxLifetime: DefaultConnMaxLifetime,
+	}
+}
+
+// WithPool sets the connection pool limits. Raise MaxOpenConns when many
+// enrichment workers share one database, and lower it when the server's
+// max_connections is shared with other clients.
+func WithPool(cfg PoolConfig) Option {
+	return func(s *MemoryStore) {
+		s.pool = cfg
+	}
+}
+
+// validate reports an error if any pool setting is negative.
+func (p PoolConfig) validate() error {
+	switch {
+	case p.MaxOpenConns < 0:
+		return fmt.Errorf("postgres: max open connections must be non-negative, got %d", p.MaxOpenConns)
+	case p.MaxIdleConns < 0:
+		return fmt.Errorf("postgres: max idle connections must be non-negative, got %d", p.MaxIdleConns)
+	case p.ConnMaxLifetime < 0:
+		return fmt.Errorf("postgres: connection max lifetime must be non-negative, got %s", p.ConnMaxLifetime)
+	}
+	return nil
+}
+
+// apply configures db's pool. database/sql lowers the idle limit to the
+// open limit when the latter is smaller.
+func (p PoolConfig) apply(db *sql.DB) {
+	db.SetMaxOpenConns(p.MaxOpenConns)
+	db.SetMaxIdleConns(p.MaxIdleConns)
+	db.SetConnMaxLifetime(p.ConnMaxLifetime)
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// ListRelationships returns the relationships of a memory's entities or of
+// one entity. It implements storage.RelationshipReader.
+func (s *MemoryStore) ListRelationships(ctx context.Context, filter storage.RelationshipFilter) ([]storage.EntityRelationship, error) {
+	if (filter.MemoryID == "") == (filter.EntityID == "") {
+		return nil, fmt.Errorf("%w: exactly one of memory ID and entity ID is required", storage.ErrInvalidInput)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = storage.DefaultLimit
+	}
+
+	var exists int
+	var where string
+	var args []interface{}
+	var err error
+	if filter.MemoryID != "" {
+		err = s.db.QueryRowContext(ctx,
+			`SELECT 1 FROM memories WHERE id = $1 AND deleted_at IS NULL`, filter.MemoryID).Scan(&exists)
+		where = `r.source_id IN (SELECT entity_id FROM memory_entities WHERE memory_id = $1)
+		  AND r.target_id IN (SELECT entity_id FROM memory_entities WHERE memory_id = $1)`
+		args = []interface{}{filter.MemoryID}
+	} else {
+		err = s.db.QueryRowContext(ctx,
+			`SELECT 1 FROM entities WHERE id = $1`, filter.EntityID).Scan(&exists)
+		where = `(r.source_id = $1 OR r.target_id = $1)`
+		args = []interface{}{filter.EntityID}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("postgres: ListRelationships: %w", err)
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		where += fmt.Sprintf(" AND r.type = $%d", len(args))
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT r.id, r.source_id, r.target_id, r.type, r.weight, r.context, r.created_at, r.updated_at,
+		       src.name, src.type, tgt.name, tgt.type
+		FROM relationships r
+		JOIN entities src ON src.id = r.source_id
+		JOIN entities tgt ON tgt.id = r.target_id
+		WHERE `+where+`
+		ORDER BY r.weight DESC, r.type, r.id
+		LIMIT $`+fmt.Sprint(len(args)+1), append(args, filter.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: ListRelationships: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	rels := []storage.EntityRelationship{}
+	for rows.Next() {
+		var rel storage.EntityRelationship
+		var relContext sql.NullString
+		if err := rows.Scan(&rel.ID, &rel.FromID, &rel.ToID, &rel.Type, &rel.Strength, &relContext,
+			&rel.CreatedAt, &rel.UpdatedAt, &rel.SourceName, &rel.SourceType, &rel.TargetName, &rel.TargetType); err != nil {
+			return nil, fmt.Errorf("postgres: ListRelationships scan: %w", err)
+		}
+		rel.Context = relContext.String
+		rels = append(rels, rel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: ListRelationships rows: %w", err)
+	}
+	return rels, nil
+}
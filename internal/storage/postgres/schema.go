@@ -65,7 +65,17 @@ CREATE TABLE IF NOT EXISTS memories (
     supersedes_id TEXT,
 
     -- Memory type classification
-    memory_type TEXT
+    memory_type TEXT,
+
+    -- Optional expiry; once passed the memory is hidden from List/search and
+    -- soft-deleted by the engine's expiry sweeper. NULL = never expires.
+    expires_at TIMESTAMP,
+
+    -- Authorship origin of created_by
+    author_type TEXT, -- 'human', 'agent' or 'system'
+
+    -- Short summary of long content, written by enrichment or summarize_memory
+    summary TEXT
 );
 
 -- Entities table: Extracted entities from memories
@@ -126,9 +136,9 @@ CREATE TABLE IF NOT EXISTS memory_entities (
     FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
 );
 
--- Embeddings table: Vector embeddings with dimension tracking
+-- Embeddings table: Vector embeddings with dimension tracking, one per memory and model
 CREATE TABLE IF NOT EXISTS embeddings (
-    memory_id TEXT PRIMARY KEY,
+    memory_id TEXT NOT NULL,
     embedding BYTEA NOT NULL, -- Stored as binary packed float64 array
     dimension INTEGER NOT NULL,
     model TEXT NOT NULL,
@@ -136,6 +146,7 @@ CREATE TABLE IF NOT EXISTS embeddings (
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 
+    PRIMARY KEY (memory_id, model),
     FOREIGN KEY (memory_id) REFERENCES memories(id) ON DELETE CASCADE
 );
 
@@ -192,6 +203,7 @@ CREATE TABLE IF NOT EXISTS memory_links (
     source_id TEXT NOT NULL,
     target_id TEXT NOT NULL,
     type TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,  -- order among the source's links of this type
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     UNIQUE(source_id, target_id, type)
 );
@@ -221,6 +233,44 @@ CREATE TABLE IF NOT EXISTS unknown_type_stats (
 );
 
 CREATE INDEX IF NOT EXISTS idx_unknown_type_stats_domain ON unknown_type_stats(domain);
+
+-- Sessions: named work sessions started with begin_session. Memories refer
+-- to them through memories.session_id; anonymous sessions have no row.
+CREATE TABLE IF NOT EXISTS sessions (
+    id TEXT PRIMARY KEY,
+    label TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP
+);
+
+-- Audit log: an append-only record of mutating MCP operations, written when
+-- the server runs with the audit log enabled.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    author_type TEXT NOT NULL DEFAULT '',
+    tool TEXT NOT NULL,
+    memory_ids TEXT[] NOT NULL DEFAULT '{}',
+    connection TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
+CREATE INDEX IF NOT EXISTS idx_audit_log_memory_ids ON audit_log USING GIN (memory_ids);
+
+-- State history: one row per lifecycle state transition, written by
+-- UpdateState. Purging a memory removes its history.
+CREATE TABLE IF NOT EXISTS state_history (
+    id BIGSERIAL PRIMARY KEY,
+    memory_id TEXT NOT NULL REFERENCES memories(id) ON DELETE CASCADE,
+    from_state TEXT NOT NULL DEFAULT '',
+    to_state TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL,
+    changed_by TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_state_history_memory ON state_history(memory_id, id);
 `
 
 // MigrationFTS contains SQL to add full-text search support to the memories table.
@@ -264,6 +314,26 @@ CREATE TRIGGER memories_tsv_trigger
     EXECUTE FUNCTION memories_tsv_update();
 `
 
+// MigrationEmbeddingsKey re-keys the embeddings table of databases created
+// before a memory could hold one embedding per model, from memory_id alone to
+// (memory_id, model). Existing rows are kept. Safe to run multiple times.
+const MigrationEmbeddingsKey = `
+DO $$
+DECLARE
+    pk_name TEXT;
+BEGIN
+    SELECT conname INTO pk_name
+    FROM pg_constraint
+    WHERE conrelid = 'embeddings'::regclass AND contype = 'p'
+        AND array_length(conkey, 1) = 1;
+    IF pk_name IS NOT NULL THEN
+        EXECUTE format('ALTER TABLE embeddings DROP CONSTRAINT %I', pk_name);
+        ALTER TABLE embeddings ADD PRIMARY KEY (memory_id, model);
+    END IF;
+END
+$$;
+`
+
 // MigrationPgvector contains SQL to add pgvector support to the embeddings table.
 // This migration is only applied when the vector extension is available.
 // Safe to run multiple times (uses IF NOT EXISTS / conditional checks).
@@ -284,13 +354,20 @@ $$;
 -- Lists = 100 is a good default for up to ~1M vectors; tune upward for larger datasets.
 -- The index is created CONCURRENTLY so it won't block reads on existing data.
 -- IMPORTANT: ivfflat requires at least one row to exist; we guard with a DO block.
+-- ivfflat also requires a fixed dimension, which embedding_vec lacks when it
+-- holds vectors from more than one model. Index creation failure is therefore
+-- only reported as a notice: vector search still works as a sequential scan.
 DO $$
 BEGIN
   IF NOT EXISTS (
     SELECT 1 FROM pg_indexes WHERE indexname = 'idx_embeddings_vec_cosine'
   ) THEN
     IF EXISTS (SELECT 1 FROM embeddings LIMIT 1) THEN
-      EXECUTE 'CREATE INDEX idx_embeddings_vec_cosine ON embeddings USING ivfflat (embedding_vec vector_cosine_ops) WITH (lists = 100)';
+      BEGIN
+        EXECUTE 'CREATE INDEX idx_embeddings_vec_cosine ON embeddings USING ivfflat (embedding_vec vector_cosine_ops) WITH (lists = 100)';
+      EXCEPTION WHEN others THEN
+        RAISE NOTICE 'skipping idx_embeddings_vec_cosine: %', SQLERRM;
+      END;
     END IF;
   END IF;
 END$$;
@@ -4,10 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/lib/pq"
 	pgvector "github.com/pgvector/pgvector-go"
 
 	"github.com/scrypster/memento/internal/storage"
@@ -28,56 +32,153 @@ const memorySelectColumns = `
 	state, state_updated_at,
 	created_by, session_id, source_context,
 	access_count, last_accessed_at, decay_score, decay_updated_at,
-	deleted_at, content_hash, supersedes_id, memory_type
+	deleted_at, content_hash, supersedes_id, memory_type, expires_at, author_type, summary
 `
 
+// headlineOptions bounds the ts_headline excerpt returned as each full-text
+// result's Snippet.
+var headlineOptions = fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=%d, MinWords=%d, MaxFragments=%d, FragmentDelimiter=" %s "`,
+	storage.SnippetStartSel, storage.SnippetStopSel, storage.SnippetMaxTokens, storage.SnippetMaxTokens/3,
+	storage.SnippetMaxFragments, storage.SnippetEllipsis)
+
 // FullTextSearch performs PostgreSQL tsvector full-text search across memory content.
+// Each result carries a Snippet excerpt with the matched terms marked.
 //
 // When opts.Query is empty the method falls back to a full table scan ordered
 // by created_at DESC so the caller still receives a useful result set.
 //
 // When the content_tsv column is not yet populated (e.g. on a fresh row that
 // hasn't been through the UPDATE trigger) we fall back gracefully to ILIKE.
+//
+// A query using the documented search syntax (see storage.ParseSearchQuery)
+// is rebuilt from its parsed tokens and run through to_tsquery, without the
+// fuzzy fallback.
+//
+// With opts.Fuzzy, typo matches found with pg_trgm are appended after the
+// exact ones (see addFuzzyMatches).
 func (s *MemoryStore) FullTextSearch(ctx context.Context, opts storage.SearchOptions) (*storage.PaginatedResult[types.Memory], error) {
 	opts.Normalize()
+	result, err := s.matchFullText(ctx, opts)
+	if err != nil || !opts.Fuzzy {
+		return result, err
+	}
+	if err := s.addFuzzyMatches(ctx, result, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// matchFullText runs the exact tsvector search behind FullTextSearch.
+func (s *MemoryStore) matchFullText(ctx context.Context, opts storage.SearchOptions) (*storage.PaginatedResult[types.Memory], error) {
+	if tokens := storage.ParseSearchQuery(opts.Query); tokens != nil {
+		if len(tokens) == 0 {
+			return &storage.PaginatedResult[types.Memory]{Page: 1, PageSize: opts.Limit}, nil
+		}
+		opts.Query = tsqueryExpression(tokens)
+		opts.FuzzyFallback = false
+		return s.fullTextSearch(ctx, opts, "to_tsquery")
+	}
+	return s.fullTextSearch(ctx, opts, "plainto_tsquery")
+}
+
+// tsqueryExpression renders tokens from storage.ParseSearchQuery in
+// to_tsquery syntax. Words hold only letters and digits, so they need no
+// quoting; a phrase becomes words joined by <-> and a prefix gets :*. The
+// precedence of !, & and | matches the search syntax.
+func tsqueryExpression(tokens []storage.QueryToken) string {
+	parts := make([]string, 0, len(tokens))
+	for _, tok := range tokens {
+		switch tok.Kind {
+		case storage.QueryAnd:
+			parts = append(parts, "&")
+		case storage.QueryOr:
+			parts = append(parts, "|")
+		case storage.QueryNot:
+			parts = append(parts, "& !")
+		default:
+			term := strings.Join(tok.Words, " <-> ")
+			if tok.Prefix {
+				term += ":*"
+			}
+			if len(tok.Words) > 1 {
+				term = "(" + term + ")"
+			}
+			parts = append(parts, term)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// fullTextSearch runs FullTextSearch with the given tsquery constructor.
+// plainto_tsquery ANDs every term; the fuzzy fallback uses
+// websearch_to_tsquery, which understands the OR operator.
+func (s *MemoryStore) fullTextSearch(ctx context.Context, opts storage.SearchOptions, tsqueryFunc string) (*storage.PaginatedResult[types.Memory], error) {
+	opts.Normalize()
 
 	// When the query is empty fall back to a plain list ordered by creation time.
 	if strings.TrimSpace(opts.Query) == "" {
 		return s.List(ctx, storage.ListOptions{
-			Page:      1,
-			Limit:     opts.Limit,
-			SortBy:    "created_at",
-			SortOrder: "desc",
+			Page:           1,
+			Limit:          opts.Limit,
+			SortBy:         "created_at",
+			SortOrder:      "desc",
+			IncludeExpired: opts.IncludeExpired,
+			Metadata:       opts.Metadata,
 		})
 	}
 
-	const querySQL = `
-		SELECT ` + memorySelectColumns + `
+	expiryCond, expiryArgs := expiryFilter("", 4, opts.IncludeExpired)
+	expiryCond += archivedFilter("", opts.IncludeArchived)
+	tagsCond, tagsArgs := tagsFilter("", 4+len(expiryArgs), opts.Tags, opts.TagsMatchAll)
+	metadataCond, metadataArgs, err := metadataFilter("", 4+len(expiryArgs)+len(tagsArgs), opts.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	querySQL := `
+		SELECT ` + memorySelectColumns + `,
+			ts_headline('english', content, ` + tsqueryFunc + `('english', $1), '` + headlineOptions + `')
 		FROM memories
-		WHERE content_tsv @@ plainto_tsquery('english', $1) AND deleted_at IS NULL
-		ORDER BY ts_rank(content_tsv, plainto_tsquery('english', $1)) DESC
+		WHERE content_tsv @@ ` + tsqueryFunc + `('english', $1) AND deleted_at IS NULL` + expiryCond + tagsCond + metadataCond + `
+		ORDER BY ts_rank(content_tsv, ` + tsqueryFunc + `('english', $1)) DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := s.db.QueryContext(ctx, querySQL, opts.Query, opts.Limit, opts.Offset)
+	args := append([]interface{}{opts.Query, opts.Limit, opts.Offset}, expiryArgs...)
+	args = append(args, tagsArgs...)
+	args = append(args, metadataArgs...)
+	rows, err := s.db.QueryContext(ctx, querySQL, args...)
 	if err != nil {
 		return nil, fmt.Errorf("postgres: FullTextSearch query %q: %w", opts.Query, err)
 	}
 	defer func() { _ = rows.Close() }()
 
-	memories, err := scanMemoryRows(rows)
-	if err != nil {
+	var memories []types.Memory
+	for rows.Next() {
+		var snippet sql.NullString
+		mem, err := scanMemoryRow(rows, &snippet)
+		if err != nil {
+			return nil, fmt.Errorf("postgres: FullTextSearch scan: %w", err)
+		}
+		mem.Snippet = snippet.String
+		memories = append(memories, mem)
+	}
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("postgres: FullTextSearch scan: %w", err)
 	}
 
 	// Count total matching rows for pagination.
-	const countSQL = `
+	countExpiryCond, _ := expiryFilter("", 2, opts.IncludeExpired)
+	countTagsCond, _ := tagsFilter("", 2+len(expiryArgs), opts.Tags, opts.TagsMatchAll)
+	countMetadataCond, _, _ := metadataFilter("", 2+len(expiryArgs)+len(tagsArgs), opts.Metadata)
+	countSQL := `
 		SELECT COUNT(*)
 		FROM memories
-		WHERE content_tsv @@ plainto_tsquery('english', $1) AND deleted_at IS NULL
+		WHERE content_tsv @@ ` + tsqueryFunc + `('english', $1) AND deleted_at IS NULL` + countExpiryCond + countTagsCond + countMetadataCond + `
 	`
+	countArgs := append([]interface{}{opts.Query}, expiryArgs...)
+	countArgs = append(countArgs, tagsArgs...)
 	var total int
-	if err := s.db.QueryRowContext(ctx, countSQL, opts.Query).Scan(&total); err != nil {
+	if err := s.db.QueryRowContext(ctx, countSQL, append(countArgs, metadataArgs...)...).Scan(&total); err != nil {
 		return nil, fmt.Errorf("postgres: FullTextSearch count: %w", err)
 	}
 
@@ -101,19 +202,40 @@ func (s *MemoryStore) FullTextSearch(ctx context.Context, opts storage.SearchOpt
 			relaxedOpts := opts
 			relaxedOpts.Query = strings.Join(terms, " OR ")
 			relaxedOpts.FuzzyFallback = false // prevent recursion
-			return s.FullTextSearch(ctx, relaxedOpts)
+			return s.fullTextSearch(ctx, relaxedOpts, "websearch_to_tsquery")
 		}
 	}
 
 	return result, nil
 }
 
+// similarityFilter returns a WHERE fragment (prefixed with " AND ") that keeps
+// rows whose cosine similarity to the query is at least minSimilarity, given
+// the cosine distance expression used for ordering. argN is the placeholder
+// number used for the distance bound. It returns no condition when
+// minSimilarity <= 0.
+func similarityFilter(distance string, argN int, minSimilarity float64) (string, []interface{}) {
+	if minSimilarity <= 0 {
+		return "", nil
+	}
+	return fmt.Sprintf(" AND %s <= $%d", distance, argN), []interface{}{1 - minSimilarity}
+}
+
 // VectorSearch performs semantic similarity search using pgvector cosine distance.
-// The search is accelerated by an ivfflat index (idx_embeddings_vec_cosine) when the embeddings table is non-empty.
+// The search is accelerated by the optional ANN index (see WithVectorIndex).
+//
+// Only embeddings with the same dimension as query are compared, so vectors
+// from a previous embedding model never cause a pgvector error. Each memory
+// contributes one embedding: the one from opts.EmbeddingModel when set,
+// otherwise the most recently stored one. When none
+// match but embeddings of another dimension exist, it returns an error
+// wrapping storage.ErrDimensionMismatch. Candidates below
+// opts.MinSimilarity are dropped.
 //
-// When pgvector is not available or the embedding_vec column is not populated,
-// it falls back to returning recent memories (same as FullTextSearch with empty
-// query).
+// When pgvector is not available it falls back to returning recent memories
+// (same as FullTextSearch with empty query). Query errors are returned so that
+// HybridSearch can fall back to full-text results instead of blending in
+// unranked ones.
 func (s *MemoryStore) VectorSearch(ctx context.Context, query []float64, opts storage.SearchOptions) (*storage.PaginatedResult[types.Memory], error) {
 	opts.Normalize()
 
@@ -124,10 +246,12 @@ func (s *MemoryStore) VectorSearch(ctx context.Context, query []float64, opts st
 	if !s.pgvectorAvailable {
 		// Fall back to recent memories when pgvector is not available.
 		return s.List(ctx, storage.ListOptions{
-			Page:      1,
-			Limit:     opts.Limit,
-			SortBy:    "created_at",
-			SortOrder: "desc",
+			Page:           1,
+			Limit:          opts.Limit,
+			SortBy:         "created_at",
+			SortOrder:      "desc",
+			IncludeExpired: opts.IncludeExpired,
+			Metadata:       opts.Metadata,
 		})
 	}
 
@@ -138,24 +262,36 @@ func (s *MemoryStore) VectorSearch(ctx context.Context, query []float64, opts st
 	}
 	vec := pgvector.NewVector(f32)
 
-	const querySQL = `
+	// The dimension is inlined rather than bound so that it matches the
+	// predicate of a partial ANN index (see WithVectorIndex).
+	expiryCond, expiryArgs := expiryFilter("m.", 4, opts.IncludeExpired)
+	expiryCond += archivedFilter("m.", opts.IncludeArchived)
+	embeddingCond, embeddingArgs := embeddingChoiceFilter(opts.EmbeddingModel, 4+len(expiryArgs))
+	distance := s.vectorDistanceExpr(len(query))
+	similarityCond, similarityArgs := similarityFilter(distance, 4+len(expiryArgs)+len(embeddingArgs), opts.MinSimilarity)
+	tagsCond, tagsArgs := tagsFilter("m.", 4+len(expiryArgs)+len(embeddingArgs)+len(similarityArgs), opts.Tags, opts.TagsMatchAll)
+	metadataCond, metadataArgs, err := metadataFilter("m.", 4+len(expiryArgs)+len(embeddingArgs)+len(similarityArgs)+len(tagsArgs), opts.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	querySQL := `
 		SELECT ` + memorySelectColumns + `
 		FROM memories m
 		JOIN embeddings e ON e.memory_id = m.id
 		WHERE e.embedding_vec IS NOT NULL AND m.deleted_at IS NULL
-		ORDER BY e.embedding_vec <=> $1::vector
+			AND vector_dims(e.embedding_vec) = ` + strconv.Itoa(len(query)) + expiryCond + embeddingCond + similarityCond + tagsCond + metadataCond + `
+		ORDER BY ` + distance + `
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := s.db.QueryContext(ctx, querySQL, vec, opts.Limit, opts.Offset)
+	args := append([]interface{}{vec, opts.Limit, opts.Offset}, expiryArgs...)
+	args = append(args, embeddingArgs...)
+	args = append(args, similarityArgs...)
+	args = append(args, tagsArgs...)
+	args = append(args, metadataArgs...)
+	rows, err := s.db.QueryContext(ctx, querySQL, args...)
 	if err != nil {
-		// If the query fails (e.g. no rows with embedding_vec yet), fall back.
-		return s.List(ctx, storage.ListOptions{
-			Page:      1,
-			Limit:     opts.Limit,
-			SortBy:    "created_at",
-			SortOrder: "desc",
-		})
+		return nil, fmt.Errorf("postgres: VectorSearch query: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
@@ -165,17 +301,40 @@ func (s *MemoryStore) VectorSearch(ctx context.Context, query []float64, opts st
 	}
 
 	// Count total rows with embedding vectors for pagination.
-	const countSQL = `
+	countExpiryCond, _ := expiryFilter("m.", 2, opts.IncludeExpired)
+	countExpiryCond += archivedFilter("m.", opts.IncludeArchived)
+	countEmbeddingCond, _ := embeddingChoiceFilter(opts.EmbeddingModel, 2+len(expiryArgs))
+	countSimilarityCond := ""
+	countArgs := append([]interface{}{len(query)}, expiryArgs...)
+	countArgs = append(countArgs, embeddingArgs...)
+	if len(similarityArgs) > 0 {
+		vecArg := len(countArgs) + 1
+		countSimilarityCond, _ = similarityFilter(fmt.Sprintf("e.embedding_vec <=> $%d::vector", vecArg), vecArg+1, opts.MinSimilarity)
+		countArgs = append(countArgs, vec)
+		countArgs = append(countArgs, similarityArgs...)
+	}
+	countTagsCond, _ := tagsFilter("m.", len(countArgs)+1, opts.Tags, opts.TagsMatchAll)
+	countArgs = append(countArgs, tagsArgs...)
+	countMetadataCond, _, _ := metadataFilter("m.", len(countArgs)+1, opts.Metadata)
+	countArgs = append(countArgs, metadataArgs...)
+	countSQL := `
 		SELECT COUNT(*)
 		FROM memories m
 		JOIN embeddings e ON e.memory_id = m.id
 		WHERE e.embedding_vec IS NOT NULL AND m.deleted_at IS NULL
+			AND vector_dims(e.embedding_vec) = $1` + countExpiryCond + countEmbeddingCond + countSimilarityCond + countTagsCond + countMetadataCond + `
 	`
 	var total int
-	if err := s.db.QueryRowContext(ctx, countSQL).Scan(&total); err != nil {
+	if err := s.db.QueryRowContext(ctx, countSQL, countArgs...).Scan(&total); err != nil {
 		total = len(memories) + opts.Offset
 	}
 
+	if total == 0 {
+		if err := s.checkEmbeddingDimension(ctx, len(query)); err != nil {
+			return nil, err
+		}
+	}
+
 	return &storage.PaginatedResult[types.Memory]{
 		Items:    memories,
 		Total:    total,
@@ -184,10 +343,42 @@ func (s *MemoryStore) VectorSearch(ctx context.Context, query []float64, opts st
 	}, nil
 }
 
+// tagsFilter returns a WHERE fragment (prefixed with " AND ") keeping
+// memories whose JSONB tags array holds any of tags, or all of them when
+// matchAll is set, or "" when tags is empty. argN is the placeholder number
+// used for tags.
+func tagsFilter(alias string, argN int, tags []string, matchAll bool) (string, []interface{}) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+	op := "?|"
+	if matchAll {
+		op = "?&"
+	}
+	return fmt.Sprintf(" AND %stags %s $%d", alias, op, argN), []interface{}{pq.Array(tags)}
+}
+
+// metadataFilter returns a WHERE fragment (prefixed with " AND ") keeping
+// memories whose JSONB metadata contains every key of filter with an equal
+// value of the same JSON type, or "" when filter is empty. argN is the
+// placeholder number used for the filter. Errors wrap
+// storage.ErrInvalidInput.
+func metadataFilter(alias string, argN int, filter map[string]interface{}) (string, []interface{}, error) {
+	filter, err := storage.NormalizeMetadataFilter(filter)
+	if err != nil || len(filter) == 0 {
+		return "", nil, err
+	}
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal metadata filter: %w", err)
+	}
+	return fmt.Sprintf(" AND %smetadata @> $%d::jsonb", alias, argN), []interface{}{string(data)}, nil
+}
+
 // HybridSearch combines full-text search and vector similarity search using
-// Reciprocal Rank Fusion (RRF) to merge and re-rank results.
-// When no vector is provided or pgvector is unavailable, it falls back to
-// FullTextSearch.
+// Reciprocal Rank Fusion (RRF) to merge and re-rank results, matching the
+// SQLite hybrid path. When no vector is provided, pgvector is unavailable or
+// the vector query fails, it falls back to FullTextSearch.
 func (s *MemoryStore) HybridSearch(ctx context.Context, text string, vector []float64, opts storage.SearchOptions) (*storage.PaginatedResult[types.Memory], error) {
 	if len(vector) == 0 || !s.pgvectorAvailable {
 		opts.Query = text
@@ -200,16 +391,19 @@ func (s *MemoryStore) HybridSearch(ctx context.Context, text string, vector []fl
 		candidateLimit = 30
 	}
 
-	ftsOpts := storage.SearchOptions{Query: text, Limit: candidateLimit}
+	ftsOpts := storage.SearchOptions{Query: text, Limit: candidateLimit, IncludeExpired: opts.IncludeExpired, Tags: opts.Tags, TagsMatchAll: opts.TagsMatchAll, Metadata: opts.Metadata}
 	ftsResult, err := s.FullTextSearch(ctx, ftsOpts)
 	if err != nil {
 		return nil, fmt.Errorf("postgres: hybrid search FTS failed: %w", err)
 	}
 
-	vecOpts := storage.SearchOptions{Limit: candidateLimit}
+	vecOpts := storage.SearchOptions{Limit: candidateLimit, IncludeExpired: opts.IncludeExpired, EmbeddingModel: opts.EmbeddingModel, MinSimilarity: opts.MinSimilarity, Tags: opts.Tags, TagsMatchAll: opts.TagsMatchAll, Metadata: opts.Metadata}
 	vecResult, err := s.VectorSearch(ctx, vector, vecOpts)
 	if err != nil {
 		// Vector search failure is non-fatal — fall back to FTS only.
+		if errors.Is(err, storage.ErrDimensionMismatch) {
+			slog.Warn("postgres: falling back to full-text search; re-embed existing memories with the re-embed-all maintenance backfill", "error", err)
+		}
 		opts.Query = text
 		return s.FullTextSearch(ctx, opts)
 	}
@@ -217,8 +411,10 @@ func (s *MemoryStore) HybridSearch(ctx context.Context, text string, vector []fl
 	// Reciprocal Rank Fusion (k=60 is a well-tuned default).
 	const rrfK = 60.0
 	scores := make(map[string]float64)
+	snippets := make(map[string]string)
 	for rank, mem := range ftsResult.Items {
 		scores[mem.ID] += 1.0 / (rrfK + float64(rank+1))
+		snippets[mem.ID] = mem.Snippet
 	}
 	for rank, mem := range vecResult.Items {
 		scores[mem.ID] += 1.0 / (rrfK + float64(rank+1))
@@ -253,6 +449,7 @@ func (s *MemoryStore) HybridSearch(ctx context.Context, text string, vector []fl
 		if err != nil {
 			continue
 		}
+		mem.Snippet = snippets[r.id]
 		memories = append(memories, *mem)
 	}
 
@@ -285,15 +482,18 @@ func scanMemoryRows(rows *sql.Rows) ([]types.Memory, error) {
 }
 
 // scanMemoryRow scans a single row (from *sql.Rows) into a types.Memory.
+// extra receives any columns selected after memorySelectColumns.
 // The SELECT column order must match memorySelectColumns.
-func scanMemoryRow(rows *sql.Rows) (types.Memory, error) {
+func scanMemoryRow(rows *sql.Rows, extra ...interface{}) (types.Memory, error) {
 	var memory types.Memory
 	var metadataJSON, tagsJSON, sourceContextJSON sql.NullString
 	var enrichedAt, timestamp, stateUpdatedAt, lastAccessedAt, decayUpdatedAt, deletedAt sql.NullTime
 	var domain, enrichmentError, state, createdBy, sessionID sql.NullString
 	var contentHash, supersedesID, memType sql.NullString
+	var expiresAt sql.NullTime
+	var authorType, summary sql.NullString
 
-	err := rows.Scan(
+	dest := []interface{}{
 		&memory.ID,
 		&memory.Content,
 		&memory.Source,
@@ -323,7 +523,11 @@ func scanMemoryRow(rows *sql.Rows) (types.Memory, error) {
 		&contentHash,
 		&supersedesID,
 		&memType,
-	)
+		&expiresAt,
+		&authorType,
+		&summary,
+	}
+	err := rows.Scan(append(dest, extra...)...)
 	if err != nil {
 		return memory, fmt.Errorf("postgres: scan memory row: %w", err)
 	}
@@ -391,6 +595,16 @@ func scanMemoryRow(rows *sql.Rows) (types.Memory, error) {
 	if memType.Valid {
 		memory.MemoryType = memType.String
 	}
+	if expiresAt.Valid {
+		t := expiresAt.Time
+		memory.ExpiresAt = &t
+	}
+	if authorType.Valid {
+		memory.AuthorType = authorType.String
+	}
+	if summary.Valid {
+		memory.Summary = summary.String
+	}
 
 	return memory, nil
 }
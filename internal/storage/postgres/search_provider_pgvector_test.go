@@ -0,0 +1,180 @@
+//go:build pgvector
+
+package postgres_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/internal/storage/postgres"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// These tests need a PostgreSQL server with the pgvector extension:
+//
+//	POSTGRES_TEST_DSN=postgres://... go test -tags pgvector ./internal/storage/postgres/
+
+// newVectorTestStore returns an empty store, skipping the test when pgvector
+// is not installed on the test server.
+func newVectorTestStore(t *testing.T) *postgres.MemoryStore {
+	t.Helper()
+	store := newTestStore(t)
+	if !store.PgvectorAvailable() {
+		t.Skip("pgvector extension not available on the test server")
+	}
+	truncateMemories(t, store)
+	return store
+}
+
+// storeWithEmbedding stores a memory and its embedding.
+func storeWithEmbedding(t *testing.T, store *postgres.MemoryStore, id, content string, vec []float64) {
+	t.Helper()
+	ctx := context.Background()
+	require.NoError(t, store.Store(ctx, &types.Memory{ID: id, Content: content, Source: "test"}))
+
+	provider := postgres.NewEmbeddingProvider(store.GetDB(), true)
+	require.NoError(t, provider.StoreEmbedding(ctx, id, vec, len(vec), "test-model"))
+}
+
+func resultIDs(result *storage.PaginatedResult[types.Memory]) []string {
+	ids := make([]string, 0, len(result.Items))
+	for _, m := range result.Items {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestVectorSearch_OrdersByCosineDistance(t *testing.T) {
+	store := newVectorTestStore(t)
+	storeWithEmbedding(t, store, "mem:test:near", "deploy pipeline notes", []float64{1, 0, 0})
+	storeWithEmbedding(t, store, "mem:test:mid", "release checklist", []float64{0.7, 0.7, 0})
+	storeWithEmbedding(t, store, "mem:test:far", "lunch order", []float64{0, 0, 1})
+
+	result, err := store.VectorSearch(context.Background(), []float64{1, 0.1, 0}, storage.SearchOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:test:near", "mem:test:mid", "mem:test:far"}, resultIDs(result))
+	assert.Equal(t, 3, result.Total)
+}
+
+// TestVectorSearch_IgnoresOtherDimensions verifies that embeddings produced by
+// a model with a different dimension are skipped rather than causing an error.
+func TestVectorSearch_IgnoresOtherDimensions(t *testing.T) {
+	store := newVectorTestStore(t)
+	storeWithEmbedding(t, store, "mem:test:3d", "three dimensions", []float64{1, 0, 0})
+	storeWithEmbedding(t, store, "mem:test:2d", "two dimensions", []float64{1, 0})
+
+	result, err := store.VectorSearch(context.Background(), []float64{1, 0, 0}, storage.SearchOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:test:3d"}, resultIDs(result))
+}
+
+// TestVectorSearch_OneEmbeddingPerMemory verifies that a memory embedded by
+// two models of the same dimension is returned once, ranked by the requested
+// model's embedding or, by default, its newest one.
+func TestVectorSearch_OneEmbeddingPerMemory(t *testing.T) {
+	store := newVectorTestStore(t)
+	ctx := context.Background()
+	storeWithEmbedding(t, store, "mem:test:a", "first memory", []float64{1, 0, 0})
+	storeWithEmbedding(t, store, "mem:test:b", "second memory", []float64{0, 1, 0})
+	require.NoError(t, store.SetEmbedding(ctx, "mem:test:a", []float64{0, 1, 0}, "other-model"))
+	require.NoError(t, store.SetEmbedding(ctx, "mem:test:b", []float64{1, 0, 0}, "other-model"))
+
+	result, err := store.VectorSearch(ctx, []float64{1, 0, 0}, storage.SearchOptions{Limit: 10, EmbeddingModel: "test-model"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:test:a", "mem:test:b"}, resultIDs(result))
+
+	result, err = store.VectorSearch(ctx, []float64{1, 0, 0}, storage.SearchOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:test:b", "mem:test:a"}, resultIDs(result))
+	assert.Equal(t, 2, result.Total)
+}
+
+// TestHybridSearch_BlendsTextAndVector verifies that a memory matching both
+// the text query and the vector outranks memories matching only one.
+func TestHybridSearch_BlendsTextAndVector(t *testing.T) {
+	store := newVectorTestStore(t)
+	storeWithEmbedding(t, store, "mem:test:both", "kubernetes rollout strategy", []float64{1, 0, 0})
+	storeWithEmbedding(t, store, "mem:test:text", "kubernetes cluster costs", []float64{0, 0, 1})
+	storeWithEmbedding(t, store, "mem:test:vector", "canary deployment approach", []float64{0.9, 0.1, 0})
+
+	result, err := store.HybridSearch(context.Background(), "kubernetes", []float64{1, 0, 0}, storage.SearchOptions{Limit: 10})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Items)
+	assert.Equal(t, "mem:test:both", result.Items[0].ID)
+	assert.ElementsMatch(t, []string{"mem:test:both", "mem:test:text", "mem:test:vector"}, resultIDs(result))
+}
+
+// TestHybridSearch_MinSimilarityDropsWeakMatches verifies that an unrelated
+// query returns nothing once weak vector matches are cut off.
+func TestHybridSearch_MinSimilarityDropsWeakMatches(t *testing.T) {
+	store := newVectorTestStore(t)
+	storeWithEmbedding(t, store, "mem:test:kestrel", "kestrel migration notes", []float64{1, 0, 0})
+	storeWithEmbedding(t, store, "mem:test:budget", "budget spreadsheet", []float64{0.8, 0.6, 0})
+	ctx := context.Background()
+
+	unrelated := []float64{0.1, 0, 1}
+	loose, err := store.HybridSearch(ctx, "sourdough", unrelated, storage.SearchOptions{Limit: 5})
+	require.NoError(t, err)
+	assert.Len(t, loose.Items, 2)
+
+	strict, err := store.HybridSearch(ctx, "sourdough", unrelated, storage.SearchOptions{Limit: 5, MinSimilarity: 0.5})
+	require.NoError(t, err)
+	assert.Empty(t, strict.Items)
+	assert.Zero(t, strict.Total)
+
+	vec, err := store.VectorSearch(ctx, []float64{1, 0.1, 0}, storage.SearchOptions{Limit: 5, MinSimilarity: 0.8})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:test:kestrel", "mem:test:budget"}, resultIDs(vec))
+	assert.Equal(t, 2, vec.Total)
+}
+
+// TestHybridSearch_FallsBackToFTSWithoutPgvector verifies that HybridSearch
+// returns full-text results only when pgvector is unavailable.
+func TestHybridSearch_FallsBackToFTSWithoutPgvector(t *testing.T) {
+	store := newVectorTestStore(t)
+	storeWithEmbedding(t, store, "mem:test:text", "kubernetes cluster costs", []float64{0, 0, 1})
+	storeWithEmbedding(t, store, "mem:test:vector", "canary deployment approach", []float64{1, 0, 0})
+
+	store.SetPgvectorAvailableForTest(false)
+
+	result, err := store.HybridSearch(context.Background(), "kubernetes", []float64{1, 0, 0}, storage.SearchOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:test:text"}, resultIDs(result))
+}
+
+// TestFullTextSearch_FuzzyFallbackMatchesAnyTerm verifies that the relaxed
+// retry matches memories containing any of the query terms.
+func TestFullTextSearch_FuzzyFallbackMatchesAnyTerm(t *testing.T) {
+	store := newVectorTestStore(t)
+	storeWithEmbedding(t, store, "mem:test:a", "kubernetes cluster costs", []float64{1, 0, 0})
+
+	result, err := store.FullTextSearch(context.Background(), storage.SearchOptions{
+		Query:         "kubernetes zeppelin",
+		Limit:         10,
+		FuzzyFallback: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:test:a"}, resultIDs(result))
+}
+
+// TestFullTextSearch_SnippetHighlightsMatch verifies that ts_headline returns
+// a bounded excerpt with the query term marked.
+func TestFullTextSearch_SnippetHighlightsMatch(t *testing.T) {
+	store := newVectorTestStore(t)
+	filler := strings.Repeat("the quarterly planning notes cover staffing budgets and timelines ", 30)
+	content := filler + "we decided to adopt kestrel for the ingest pipeline " + filler
+	storeWithEmbedding(t, store, "mem:test:long", content, []float64{1, 0, 0})
+
+	result, err := store.FullTextSearch(context.Background(), storage.SearchOptions{Query: "kestrel", Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, result.Items, 1)
+
+	snippet := result.Items[0].Snippet
+	assert.Contains(t, snippet, "**kestrel**")
+	assert.Less(t, len(snippet), len(content)/4)
+}
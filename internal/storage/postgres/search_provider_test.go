@@ -0,0 +1,21 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+func TestTsqueryExpression(t *testing.T) {
+	cases := map[string]string{
+		`"blue green" OR rollback`:  "(blue <-> green) | rollback",
+		`deploy* pipeline`:          "deploy:* & pipeline",
+		`pipeline NOT "roll back"*`: "pipeline & ! (roll <-> back:*)",
+		`a:b AND (c OR d`:           "(a <-> b) & c | d",
+	}
+	for query, want := range cases {
+		if got := tsqueryExpression(storage.ParseSearchQuery(query)); got != want {
+			t.Errorf("tsqueryExpression(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// SaveSession creates or updates a named session. It implements
+// storage.SessionStore.
+func (s *MemoryStore) SaveSession(ctx context.Context, session *storage.Session) error {
+	if session == nil || session.ID == "" {
+		return fmt.Errorf("%w: session ID is required", storage.ErrInvalidInput)
+	}
+	var endedAt sql.NullTime
+	if session.EndedAt != nil {
+		endedAt = sql.NullTime{Time: session.EndedAt.UTC(), Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (id, label, started_at, ended_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT(id) DO UPDATE SET
+			label = EXCLUDED.label,
+			started_at = EXCLUDED.started_at,
+			ended_at = EXCLUDED.ended_at`,
+		session.ID, session.Label, session.StartedAt.UTC(), endedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("postgres: SaveSession: %w", err)
+	}
+	return nil
+}
+
+// GetSession returns the named session with the given ID, or
+// storage.ErrNotFound. It implements storage.SessionStore.
+func (s *MemoryStore) GetSession(ctx context.Context, id string) (*storage.Session, error) {
+	session := &storage.Session{ID: id}
+	var endedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx,
+		`SELECT label, started_at, ended_at FROM sessions WHERE id = $1`, id,
+	).Scan(&session.Label, &session.StartedAt, &endedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("postgres: GetSession: %w", err)
+	}
+	if endedAt.Valid {
+		session.EndedAt = &endedAt.Time
+	}
+	return session, nil
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// recordStateTransition appends one row to the state history inside tx,
+// attributing it to the actor carried by ctx (see storage.WithActor).
+func recordStateTransition(ctx context.Context, tx *sql.Tx, id, from, to string, at time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO state_history (memory_id, from_state, to_state, changed_at, changed_by)
+		VALUES ($1, $2, $3, $4, $5)`,
+		id, from, to, at.UTC(), storage.ActorFromContext(ctx),
+	)
+	return err
+}
+
+// GetStateHistory returns the recorded state transitions of a memory,
+// oldest first. It implements storage.StateHistory.
+func (s *MemoryStore) GetStateHistory(ctx context.Context, memoryID string) ([]storage.StateTransition, error) {
+	if memoryID == "" {
+		return nil, fmt.Errorf("%w: memory ID is required", storage.ErrInvalidInput)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, memory_id, from_state, to_state, changed_at, changed_by
+		FROM state_history
+		WHERE memory_id = $1
+		ORDER BY id`, memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: GetStateHistory: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	transitions := []storage.StateTransition{}
+	for rows.Next() {
+		var t storage.StateTransition
+		if err := rows.Scan(&t.ID, &t.MemoryID, &t.From, &t.To, &t.ChangedAt, &t.ChangedBy); err != nil {
+			return nil, fmt.Errorf("postgres: GetStateHistory: %w", err)
+		}
+		transitions = append(transitions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres: GetStateHistory: %w", err)
+	}
+	return transitions, nil
+}
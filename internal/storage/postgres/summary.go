@@ -0,0 +1,7 @@
+package postgres
+
+// MigrationSummary adds the summary column to databases created before
+// memory summaries were stored. Safe to run multiple times.
+const MigrationSummary = `
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS summary TEXT;
+`
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Approximate nearest-neighbour index methods accepted by WithVectorIndex.
+const (
+	VectorIndexNone    = "none"
+	VectorIndexAuto    = "auto"
+	VectorIndexHNSW    = "hnsw"
+	VectorIndexIVFFlat = "ivfflat"
+)
+
+// DefaultIVFFlatLists is the ivfflat list count used when WithIVFFlatLists
+// is not given. It suits tables of up to roughly 100k embeddings.
+const DefaultIVFFlatLists = 100
+
+// WithVectorIndex builds an approximate nearest-neighbour index of the given
+// method ("none", "hnsw", "ivfflat" or "auto") over embeddings of dimension
+// dim, and makes VectorSearch order by the indexed expression so large
+// tables avoid a full scan. "auto" picks HNSW when the installed pgvector
+// supports it (0.5.0 and later) and ivfflat otherwise. embedding_vec has no
+// fixed dimension, so the index is a partial expression index over a
+// vector(dim) cast; queries of any other dimension keep using exact search,
+// as does everything when dim <= 0 or the index cannot be created. ivfflat
+// trains its lists on the rows present when it is built, so prefer HNSW for
+// tables that start out empty.
+func WithVectorIndex(method string, dim int) Option {
+	return func(s *MemoryStore) {
+		s.vectorIndex = strings.ToLower(strings.TrimSpace(method))
+		s.vectorIndexDim = dim
+	}
+}
+
+// WithIVFFlatLists sets the number of lists of an ivfflat index. More lists
+// make queries faster but less accurate; about rows/1000 is a good start.
+// Values <= 0 keep DefaultIVFFlatLists. It has no effect on HNSW indexes.
+func WithIVFFlatLists(lists int) Option {
+	return func(s *MemoryStore) {
+		if lists > 0 {
+			s.ivfflatLists = lists
+		}
+	}
+}
+
+// VectorIndexDimension reports the embedding dimension covered by the
+// approximate nearest-neighbour index, or 0 when vector search is exact.
+func (s *MemoryStore) VectorIndexDimension() int {
+	return s.annIndexDim
+}
+
+// ensureVectorIndex creates the configured ANN index if it does not exist
+// yet and, once it does, switches VectorSearch to the indexed expression.
+func (s *MemoryStore) ensureVectorIndex(ctx context.Context) error {
+	method := s.vectorIndex
+	if method == "" || method == VectorIndexNone || s.vectorIndexDim <= 0 {
+		return nil
+	}
+
+	if method == VectorIndexAuto {
+		version, err := s.pgvectorVersion(ctx)
+		if err != nil {
+			return err
+		}
+		method = VectorIndexIVFFlat
+		if hnswSupported(version) {
+			method = VectorIndexHNSW
+		}
+	}
+
+	var with string
+	switch method {
+	case VectorIndexHNSW:
+	case VectorIndexIVFFlat:
+		with = fmt.Sprintf(" WITH (lists = %d)", s.ivfflatLists)
+	default:
+		return fmt.Errorf("unknown vector index method %q (want none, auto, hnsw or ivfflat)", s.vectorIndex)
+	}
+
+	dim := s.vectorIndexDim
+	stmt := fmt.Sprintf(
+		`CREATE INDEX IF NOT EXISTS idx_embeddings_vec_%s_%d ON embeddings USING %s ((embedding_vec::vector(%d)) vector_cosine_ops)%s WHERE vector_dims(embedding_vec) = %d`,
+		method, dim, method, dim, with, dim,
+	)
+	if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+		return err
+	}
+
+	s.vectorIndex = method
+	s.annIndexDim = dim
+	return nil
+}
+
+// pgvectorVersion returns the installed version of the vector extension.
+func (s *MemoryStore) pgvectorVersion(ctx context.Context) (string, error) {
+	var version string
+	err := s.db.QueryRowContext(ctx, `SELECT extversion FROM pg_extension WHERE extname = 'vector'`).Scan(&version)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("vector extension is not installed")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read pgvector version: %w", err)
+	}
+	return version, nil
+}
+
+// hnswSupported reports whether a pgvector version string such as "0.7.4"
+// is at least 0.5.0, the first release with HNSW indexes.
+func hnswSupported(version string) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return major > 0 || minor >= 5
+}
+
+// vectorDistanceExpr returns the ORDER BY expression for a query vector of
+// dim dimensions bound to $1. When an ANN index covers dim the expression
+// matches the index so the planner can use it; otherwise it is the exact
+// cosine distance over every candidate row.
+func (s *MemoryStore) vectorDistanceExpr(dim int) string {
+	if s.annIndexDim > 0 && s.annIndexDim == dim {
+		return fmt.Sprintf("e.embedding_vec::vector(%d) <=> $1::vector(%d)", dim, dim)
+	}
+	return "e.embedding_vec <=> $1::vector"
+}
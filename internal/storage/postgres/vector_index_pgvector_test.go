@@ -0,0 +1,149 @@
+//go:build pgvector
+
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/internal/storage/postgres"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+const (
+	annDim      = 32
+	annRows     = 2000
+	annQueries  = 20
+	annNeighbor = 10
+)
+
+// newANNTestStore returns an empty store with an ANN index over annDim
+// embeddings, seeded with annRows random unit vectors. It returns the
+// vectors keyed by memory ID.
+func newANNTestStore(tb testing.TB, method string) (*postgres.MemoryStore, map[string][]float64) {
+	tb.Helper()
+	store, err := postgres.NewMemoryStore(postgresTestDSN(tb), postgres.WithVectorIndex(method, annDim))
+	require.NoError(tb, err)
+	tb.Cleanup(func() { _ = store.Close() })
+	if !store.PgvectorAvailable() {
+		tb.Skip("pgvector extension not available on the test server")
+	}
+	require.NoError(tb, store.TruncateForTest(context.Background()))
+	require.Equal(tb, annDim, store.VectorIndexDimension(), "ANN index should have been created")
+
+	ctx := context.Background()
+	provider := postgres.NewEmbeddingProvider(store.GetDB(), true)
+	rng := rand.New(rand.NewSource(42))
+	vectors := make(map[string][]float64, annRows)
+	for i := 0; i < annRows; i++ {
+		id := fmt.Sprintf("mem:test:ann-%04d", i)
+		vec := randomUnitVector(rng, annDim)
+		require.NoError(tb, store.Store(ctx, &types.Memory{ID: id, Content: id, Source: "test"}))
+		require.NoError(tb, provider.StoreEmbedding(ctx, id, vec, annDim, "test-model"))
+		vectors[id] = vec
+	}
+	_, err = store.GetDB().ExecContext(ctx, "ANALYZE embeddings")
+	require.NoError(tb, err)
+	return store, vectors
+}
+
+func randomUnitVector(rng *rand.Rand, dim int) []float64 {
+	vec := make([]float64, dim)
+	var norm float64
+	for i := range vec {
+		vec[i] = rng.NormFloat64()
+		norm += vec[i] * vec[i]
+	}
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] /= norm
+	}
+	return vec
+}
+
+// exactNeighbors returns the IDs of the k vectors closest to query by
+// cosine distance, computed in Go.
+func exactNeighbors(vectors map[string][]float64, query []float64, k int) []string {
+	type scored struct {
+		id  string
+		sim float64
+	}
+	all := make([]scored, 0, len(vectors))
+	for id, vec := range vectors {
+		var dot float64
+		for i := range vec {
+			dot += vec[i] * query[i]
+		}
+		all = append(all, scored{id, dot})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].sim > all[j].sim })
+	ids := make([]string, k)
+	for i := range ids {
+		ids[i] = all[i].id
+	}
+	return ids
+}
+
+// TestVectorSearch_ANNIndexFindsNearestNeighbors checks the recall of the
+// indexed search path against brute force on a synthetic dataset.
+func TestVectorSearch_ANNIndexFindsNearestNeighbors(t *testing.T) {
+	store, vectors := newANNTestStore(t, postgres.VectorIndexAuto)
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(7))
+
+	hits := 0
+	for q := 0; q < annQueries; q++ {
+		query := randomUnitVector(rng, annDim)
+		result, err := store.VectorSearch(ctx, query, storage.SearchOptions{Limit: annNeighbor})
+		require.NoError(t, err)
+		require.Len(t, result.Items, annNeighbor)
+
+		want := make(map[string]bool, annNeighbor)
+		for _, id := range exactNeighbors(vectors, query, annNeighbor) {
+			want[id] = true
+		}
+		for _, id := range resultIDs(result) {
+			if want[id] {
+				hits++
+			}
+		}
+	}
+
+	recall := float64(hits) / float64(annQueries*annNeighbor)
+	t.Logf("recall@%d: %.2f", annNeighbor, recall)
+	assert.GreaterOrEqual(t, recall, 0.8, "ANN search should mostly agree with exact search")
+}
+
+// TestVectorSearch_ANNIndexKeepsOtherDimensionsExact verifies that queries
+// of a dimension the index does not cover still use exact search.
+func TestVectorSearch_ANNIndexKeepsOtherDimensionsExact(t *testing.T) {
+	store, _ := newANNTestStore(t, postgres.VectorIndexAuto)
+	storeWithEmbedding(t, store, "mem:test:near", "deploy pipeline notes", []float64{1, 0, 0})
+	storeWithEmbedding(t, store, "mem:test:far", "lunch order", []float64{0, 0, 1})
+
+	result, err := store.VectorSearch(context.Background(), []float64{1, 0.1, 0}, storage.SearchOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mem:test:near", "mem:test:far"}, resultIDs(result))
+}
+
+func BenchmarkVectorSearch_ANN(b *testing.B) {
+	store, _ := newANNTestStore(b, postgres.VectorIndexAuto)
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(7))
+	query := randomUnitVector(rng, annDim)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.VectorSearch(ctx, query, storage.SearchOptions{Limit: annNeighbor}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
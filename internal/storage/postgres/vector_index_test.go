@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHNSWSupported(t *testing.T) {
+	cases := map[string]bool{
+		"0.4.4": false,
+		"0.5.0": true,
+		"0.7.4": true,
+		"1.0":   true,
+		"0.10":  true,
+		"":      false,
+		"dev":   false,
+	}
+	for version, want := range cases {
+		if got := hnswSupported(version); got != want {
+			t.Errorf("hnswSupported(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
+
+// TestVectorDistanceExpr verifies that only queries of the indexed
+// dimension use the index expression; everything else stays exact.
+func TestVectorDistanceExpr(t *testing.T) {
+	s := &MemoryStore{}
+	if got := s.vectorDistanceExpr(768); got != "e.embedding_vec <=> $1::vector" {
+		t.Errorf("without index: got %q", got)
+	}
+
+	s.annIndexDim = 768
+	if got := s.vectorDistanceExpr(768); got != "e.embedding_vec::vector(768) <=> $1::vector(768)" {
+		t.Errorf("indexed dimension: got %q", got)
+	}
+	if got := s.vectorDistanceExpr(384); got != "e.embedding_vec <=> $1::vector" {
+		t.Errorf("other dimension: got %q", got)
+	}
+}
+
+func TestEnsureVectorIndex_SkipsWithoutDimension(t *testing.T) {
+	for _, s := range []*MemoryStore{
+		{vectorIndex: VectorIndexNone, vectorIndexDim: 768},
+		{vectorIndex: VectorIndexHNSW},
+	} {
+		// db is nil: reaching the database would panic.
+		if err := s.ensureVectorIndex(context.Background()); err != nil {
+			t.Fatalf("ensureVectorIndex: %v", err)
+		}
+		if s.annIndexDim != 0 {
+			t.Errorf("annIndexDim = %d, want 0", s.annIndexDim)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Full-text queries (SearchOptions.Query) are natural language by default:
+// each word is matched on its own and punctuation is ignored. A query that
+// uses any of the following syntax is instead matched exactly as written:
+//
+//	"exact phrase"   the words must appear together, in this order
+//	term*            words starting with term ("deploy*" matches "deployment")
+//	a AND b          both must match; adjacent terms are ANDed as well
+//	a OR b           either may match
+//	a NOT b          a must match and b must not
+//
+// Operators must be uppercase; lowercase "and", "or" and "not" are ordinary
+// words. NOT binds tightest, then AND, then OR. Parentheses are not
+// grouping operators, and every other punctuation character (including an
+// unbalanced quote) only separates words, so no user input is a syntax
+// error. Operators with nothing to join are dropped, as is a NOT with
+// nothing before it to subtract from.
+
+// QueryTokenKind identifies the kind of a QueryToken.
+type QueryTokenKind int
+
+const (
+	// QueryTerm is a word or phrase to match.
+	QueryTerm QueryTokenKind = iota
+	// QueryAnd requires the terms on both sides to match.
+	QueryAnd
+	// QueryOr requires either term to match.
+	QueryOr
+	// QueryNot requires the term before it to match and the term after it
+	// not to.
+	QueryNot
+)
+
+// QueryToken is one element of a parsed search query.
+type QueryToken struct {
+	Kind QueryTokenKind
+
+	// Words holds the lowercase words of a QueryTerm, in order; more than
+	// one word is a phrase. Words contain only letters and digits.
+	Words []string
+
+	// Prefix makes the last word of a QueryTerm match any word starting
+	// with it.
+	Prefix bool
+}
+
+// ParseSearchQuery parses query using the syntax described above. It
+// returns nil when query uses none of that syntax, in which case backends
+// treat it as natural language. Otherwise the tokens alternate between
+// QueryTerm and an operator, starting and ending with a QueryTerm; the
+// slice is empty (but not nil) when no term is left to match, e.g. for
+// "NOT draft".
+func ParseSearchQuery(query string) []QueryToken {
+	var tokens []QueryToken
+	explicit := false
+
+	// An odd quote out is a stray character: it is left inside the bare
+	// word it touches, where it only separates words.
+	pairs := strings.Count(query, `"`) / 2
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == '"' && pairs > 0:
+			pairs--
+			end := i + 1 + strings.IndexByte(query[i+1:], '"')
+			tok := QueryToken{Kind: QueryTerm, Words: searchWords(query[i+1 : end])}
+			i = end + 1
+			if i < len(query) && query[i] == '*' {
+				tok.Prefix = true
+				i++
+			}
+			if len(tok.Words) > 0 {
+				tokens = append(tokens, tok)
+				explicit = true
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		default:
+			end := i
+			for end < len(query) && !strings.ContainsRune(" \t\n\r", rune(query[end])) && !(query[end] == '"' && pairs > 0) {
+				end++
+			}
+			word := query[i:end]
+			i = end
+			switch word {
+			case "AND":
+				tokens = append(tokens, QueryToken{Kind: QueryAnd})
+				explicit = true
+				continue
+			case "OR":
+				tokens = append(tokens, QueryToken{Kind: QueryOr})
+				explicit = true
+				continue
+			case "NOT":
+				tokens = append(tokens, QueryToken{Kind: QueryNot})
+				explicit = true
+				continue
+			}
+			tok := QueryToken{Kind: QueryTerm, Words: searchWords(word)}
+			if strings.HasSuffix(word, "*") && len(tok.Words) > 0 {
+				tok.Prefix = true
+				explicit = true
+			}
+			if len(tok.Words) > 0 {
+				tokens = append(tokens, tok)
+			}
+		}
+	}
+	if !explicit {
+		return nil
+	}
+
+	// Rebuild the sequence so operators sit between exactly two terms.
+	normalised := []QueryToken{}
+	join := QueryAnd
+	for _, tok := range tokens {
+		if tok.Kind != QueryTerm {
+			join = tok.Kind
+			continue
+		}
+		switch {
+		case len(normalised) > 0:
+			normalised = append(normalised, QueryToken{Kind: join}, tok)
+		case join != QueryNot:
+			normalised = append(normalised, tok)
+		}
+		join = QueryAnd
+	}
+	return normalised
+}
+
+// FuzzyQueryWords returns the distinct lowercase words of a natural-language
+// query worth fuzzy-matching (see SearchOptions.Fuzzy): at most
+// maxFuzzyWords of them, skipping stop words and words too short to have
+// meaningful trigrams.
+func FuzzyQueryWords(query string) []string {
+	seen := map[string]bool{}
+	var words []string
+	for _, w := range searchWords(query) {
+		if len([]rune(w)) < 3 || IsStopWord(w) || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+		if len(words) == maxFuzzyWords {
+			break
+		}
+	}
+	return words
+}
+
+// maxFuzzyWords caps how many query words a fuzzy search compares.
+const maxFuzzyWords = 8
+
+// IsStopWord reports whether the lowercase word w is too common to help
+// a search, such as "the" or "which".
+func IsStopWord(w string) bool {
+	return stopWords[w]
+}
+
+// stopWords are words that carry no discriminative value in a query.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true,
+	"is": true, "are": true, "was": true, "were": true, "be": true, "been": true, "being": true,
+	"have": true, "has": true, "had": true,
+	"do": true, "does": true, "did": true,
+	"will": true, "would": true, "could": true, "should": true,
+	"may": true, "might": true, "shall": true, "can": true,
+	"to": true, "of": true, "in": true, "on": true, "at": true,
+	"by": true, "for": true, "with": true, "from": true, "as": true,
+	"about": true, "into": true, "through": true, "during": true,
+	"before": true, "after": true, "above": true, "below": true,
+	"between": true, "out": true, "off": true, "over": true, "under": true,
+	"what": true, "how": true, "when": true, "where": true, "why": true,
+	"who": true, "which": true,
+	"this": true, "that": true, "these": true, "those": true,
+	"i": true, "you": true, "he": true, "she": true, "it": true, "we": true, "they": true,
+	"and": true, "or": true, "but": true, "if": true, "not": true,
+	"s": true, "t": true, // post-apostrophe fragments e.g. "MJ's" → "MJ" + "s"
+}
+
+// searchWords splits s into lowercase runs of letters and digits.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
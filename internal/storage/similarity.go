@@ -0,0 +1,21 @@
+package storage
+
+import "math"
+
+// CosineSimilarity computes cosine similarity between two equal-length vectors.
+// Returns 0 if either vector has zero magnitude or lengths differ.
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// archivedFilter returns a WHERE fragment (prefixed with " AND ") excluding
+// archived memories, or "" when includeArchived is set. alias is an optional
+// table prefix ("m.").
+func archivedFilter(alias string, includeArchived bool) string {
+	if includeArchived {
+		return ""
+	}
+	return fmt.Sprintf(" AND (%[1]sstate IS NULL OR %[1]sstate != '%[2]s')", alias, types.StateArchived)
+}
+
+// pinnedTagPattern matches the pinned tag inside the JSON-encoded tags column.
+var pinnedTagPattern = `%"` + types.PinnedTag + `"%`
+
+// FindStaleMemories returns the IDs of memories matching criteria, lowest
+// decay score first. The access-time check is applied after scanning because
+// SQLite stores timestamps as text in the writer's time zone.
+func (s *MemoryStore) FindStaleMemories(ctx context.Context, criteria storage.StaleCriteria) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, created_at, last_accessed_at
+		FROM memories
+		WHERE deleted_at IS NULL`+archivedFilter("", false)+`
+			AND decay_score < ?
+			AND (? < 0 OR access_count <= ?)
+			AND (tags IS NULL OR tags NOT LIKE ?)
+		ORDER BY decay_score ASC, id ASC`,
+		criteria.MaxDecayScore, criteria.MaxAccessCount, criteria.MaxAccessCount, pinnedTagPattern,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stale memories: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var (
+			id           string
+			createdAt    time.Time
+			lastAccessed sql.NullTime
+		)
+		if err := rows.Scan(&id, &createdAt, &lastAccessed); err != nil {
+			return nil, fmt.Errorf("failed to scan stale memory: %w", err)
+		}
+		ref := createdAt
+		if lastAccessed.Valid {
+			ref = lastAccessed.Time
+		}
+		if !ref.Before(criteria.AccessedBefore) {
+			continue
+		}
+		ids = append(ids, id)
+		if criteria.Limit > 0 && len(ids) >= criteria.Limit {
+			break
+		}
+	}
+	return ids, rows.Err()
+}
+
+// ArchiveMemories moves each memory to the archived state without applying
+// the lifecycle state machine, recording each transition in the state
+// history, and returns how many memories changed. Missing, deleted and
+// already archived memories are skipped.
+func (s *MemoryStore) ArchiveMemories(ctx context.Context, ids []string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive memories: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	archived := 0
+	for _, id := range ids {
+		var current sql.NullString
+		err := tx.QueryRowContext(ctx,
+			`SELECT state FROM memories WHERE id = ? AND deleted_at IS NULL`, id,
+		).Scan(&current)
+		if errors.Is(err, sql.ErrNoRows) || current.String == types.StateArchived {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to archive memory %s: %w", id, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE memories SET state = ?, state_updated_at = ?, updated_at = ? WHERE id = ?`,
+			types.StateArchived, now, now, id,
+		); err != nil {
+			return 0, fmt.Errorf("failed to archive memory %s: %w", id, err)
+		}
+		if err := recordStateTransition(ctx, tx, id, current.String, types.StateArchived, now); err != nil {
+			return 0, fmt.Errorf("failed to record archive of memory %s: %w", id, err)
+		}
+		archived++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to archive memories: %w", err)
+	}
+	return archived, nil
+}
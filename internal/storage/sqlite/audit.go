@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// AppendAuditEntry records a mutating operation in the audit log. It
+// implements storage.AuditLog.
+func (s *MemoryStore) AppendAuditEntry(ctx context.Context, entry *storage.AuditEntry) error {
+	if entry == nil || entry.Tool == "" {
+		return fmt.Errorf("%w: audit entry tool is required", storage.ErrInvalidInput)
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	memoryIDs := entry.MemoryIDs
+	if memoryIDs == nil {
+		memoryIDs = []string{}
+	}
+	ids, err := json.Marshal(memoryIDs)
+	if err != nil {
+		return fmt.Errorf("sqlite: AppendAuditEntry: %w", err)
+	}
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO audit_log (created_at, actor, author_type, tool, memory_ids, connection)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		entry.Timestamp.UTC(), entry.Actor, entry.AuthorType, entry.Tool, string(ids), entry.Connection,
+	)
+	if err != nil {
+		return fmt.Errorf("sqlite: AppendAuditEntry: %w", err)
+	}
+	if entry.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("sqlite: AppendAuditEntry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns the audit entries matching filter, newest first.
+// It implements storage.AuditLog.
+func (s *MemoryStore) ListAuditEntries(ctx context.Context, filter storage.AuditFilter) ([]storage.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.MemoryID != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM json_each(audit_log.memory_ids) WHERE value = ?)")
+		args = append(args, filter.MemoryID)
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.Tool != "" {
+		conditions = append(conditions, "tool = ?")
+		args = append(args, filter.Tool)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = storage.DefaultLimit
+	}
+
+	query := `SELECT id, created_at, actor, author_type, tool, memory_ids, connection FROM audit_log`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: ListAuditEntries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []storage.AuditEntry{}
+	for rows.Next() {
+		var e storage.AuditEntry
+		var ids string
+		if err := rows.Scan(&e.ID, &e.Timestamp, &e.Actor, &e.AuthorType, &e.Tool, &ids, &e.Connection); err != nil {
+			return nil, fmt.Errorf("sqlite: ListAuditEntries: %w", err)
+		}
+		if err := json.Unmarshal([]byte(ids), &e.MemoryIDs); err != nil {
+			return nil, fmt.Errorf("sqlite: ListAuditEntries: memory_ids of entry %d: %w", e.ID, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: ListAuditEntries: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// TestAuditLog_AppendAndFilter appends entries and checks ordering and each
+// filter of ListAuditEntries.
+func TestAuditLog_AppendAndFilter(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	base := time.Now().UTC().Truncate(time.Second)
+	entries := []*storage.AuditEntry{
+		{Timestamp: base, Actor: "alice", AuthorType: "human", Tool: "store_memory", MemoryIDs: []string{"mem:work:a"}, Connection: "work"},
+		{Timestamp: base.Add(time.Minute), Actor: "bot", AuthorType: "agent", Tool: "consolidate_memories", MemoryIDs: []string{"mem:work:a", "mem:work:b", "mem:work:c"}},
+		{Timestamp: base.Add(2 * time.Minute), Actor: "alice", AuthorType: "human", Tool: "begin_session"},
+	}
+	for _, e := range entries {
+		if err := store.AppendAuditEntry(ctx, e); err != nil {
+			t.Fatalf("AppendAuditEntry() failed: %v", err)
+		}
+	}
+	if !(entries[0].ID < entries[1].ID && entries[1].ID < entries[2].ID) {
+		t.Fatalf("entry IDs not increasing: %d, %d, %d", entries[0].ID, entries[1].ID, entries[2].ID)
+	}
+
+	tools := func(filter storage.AuditFilter) []string {
+		t.Helper()
+		got, err := store.ListAuditEntries(ctx, filter)
+		if err != nil {
+			t.Fatalf("ListAuditEntries(%+v) failed: %v", filter, err)
+		}
+		names := []string{}
+		for _, e := range got {
+			names = append(names, e.Tool)
+		}
+		return names
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter storage.AuditFilter
+		want   []string
+	}{
+		{"all newest first", storage.AuditFilter{}, []string{"begin_session", "consolidate_memories", "store_memory"}},
+		{"memory", storage.AuditFilter{MemoryID: "mem:work:a"}, []string{"consolidate_memories", "store_memory"}},
+		{"memory in a longer list", storage.AuditFilter{MemoryID: "mem:work:c"}, []string{"consolidate_memories"}},
+		{"actor", storage.AuditFilter{Actor: "alice"}, []string{"begin_session", "store_memory"}},
+		{"tool", storage.AuditFilter{Tool: "store_memory"}, []string{"store_memory"}},
+		{"since inclusive", storage.AuditFilter{Since: base.Add(time.Minute)}, []string{"begin_session", "consolidate_memories"}},
+		{"until exclusive", storage.AuditFilter{Until: base.Add(time.Minute)}, []string{"store_memory"}},
+		{"limit", storage.AuditFilter{Limit: 1}, []string{"begin_session"}},
+	} {
+		if got := tools(tc.filter); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: tools = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	got, err := store.ListAuditEntries(ctx, storage.AuditFilter{Tool: "store_memory"})
+	if err != nil {
+		t.Fatalf("ListAuditEntries() failed: %v", err)
+	}
+	want := *entries[0]
+	if len(got) != 1 || !got[0].Timestamp.Equal(want.Timestamp) {
+		t.Fatalf("ListAuditEntries() = %+v, want %+v", got, want)
+	}
+	got[0].Timestamp = want.Timestamp
+	if !reflect.DeepEqual(got[0], want) {
+		t.Fatalf("ListAuditEntries() = %+v, want %+v", got[0], want)
+	}
+}
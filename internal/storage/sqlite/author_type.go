@@ -0,0 +1,27 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// upgradeAuthorType adds the author_type column to databases created before
+// authorship classification, then creates its index. Existing rows keep a
+// NULL author_type, which matches no author_type filter.
+func upgradeAuthorType(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM pragma_table_info('memories') WHERE name = 'author_type'`,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to inspect memories table: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE memories ADD COLUMN author_type TEXT`); err != nil {
+			return err
+		}
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_memories_author_type ON memories(author_type) WHERE author_type IS NOT NULL`)
+	return err
+}
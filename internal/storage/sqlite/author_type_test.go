@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func TestAuthorType_StoreGetAndListFilter(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:human", Content: "written by hand", Source: "test", AuthorType: "human"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:agent", Content: "stored by an agent", Source: "test", AuthorType: "agent"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:legacy", Content: "no author type", Source: "test"})
+
+	got, err := store.Get(ctx, "mem:test:human")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if got.AuthorType != "human" {
+		t.Errorf("AuthorType = %q, want human", got.AuthorType)
+	}
+
+	result, err := store.List(ctx, storage.ListOptions{AuthorType: "agent"})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if result.Total != 1 || result.Items[0].ID != "mem:test:agent" {
+		t.Errorf("List(AuthorType=agent) = %v, want only mem:test:agent", memoryIDs(result.Items))
+	}
+
+	result, err = store.List(ctx, storage.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if result.Total != 3 {
+		t.Errorf("unfiltered Total = %d, want 3", result.Total)
+	}
+}
+
+// TestAuthorType_UpgradesExistingDatabase verifies that opening a database
+// created before authorship classification adds the author_type column.
+func TestAuthorType_UpgradesExistingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	legacy, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := legacy.Exec(Schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	if _, err := legacy.Exec(`ALTER TABLE memories DROP COLUMN author_type`); err != nil {
+		t.Fatalf("failed to build legacy schema: %v", err)
+	}
+	_ = legacy.Close()
+
+	store, err := NewMemoryStore(dbPath)
+	if err != nil {
+		t.Fatalf("NewMemoryStore() on legacy database failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:upgraded", Content: "after upgrade", Source: "test", AuthorType: "system"})
+	got, err := store.Get(context.Background(), "mem:test:upgraded")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if got.AuthorType != "system" {
+		t.Errorf("AuthorType = %q, want system", got.AuthorType)
+	}
+}
@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+)
+
+// DefaultCompressionThreshold is the content size (in bytes) above which
+// memories are gzip-compressed when compression is enabled without an
+// explicit threshold. Short notes compress poorly and are read far more
+// often than long transcripts, so only the long tail is worth the CPU.
+const DefaultCompressionThreshold = 4096
+
+// encodeContent returns the value to write to the content column and whether
+// it was compressed. Content is stored verbatim when compression is disabled,
+// when it is below the threshold, or when gzip would not make it smaller.
+func (s *MemoryStore) encodeContent(content string) (interface{}, bool, error) {
+	if s.compressionThreshold <= 0 || len(content) < s.compressionThreshold {
+		return content, false, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		return nil, false, fmt.Errorf("failed to compress content: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to compress content: %w", err)
+	}
+
+	if buf.Len() >= len(content) {
+		return content, false, nil
+	}
+	return buf.Bytes(), true, nil
+}
+
+// DecodeContent returns the plain-text content of a memory row given the raw
+// content column and its content_compressed flag. It is exported for callers
+// that read the memories table directly via GetDB.
+func DecodeContent(raw string, compressed bool) (string, error) {
+	if !compressed {
+		return raw, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader([]byte(raw)))
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress content: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress content: %w", err)
+	}
+	return string(plain), nil
+}
+
+// syncFTSContent writes the uncompressed content of a compressed memory to
+// memories_fts. The FTS triggers skip compressed rows (they would otherwise
+// index gzip bytes), so Store keeps the index in sync for them here. The FTS
+// rowid is pinned to the memories rowid because FullTextSearch joins on it.
+func syncFTSContent(ctx context.Context, tx *sql.Tx, id, content string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM memories_fts WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to clear FTS entry: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO memories_fts(rowid, id, content) SELECT rowid, id, ? FROM memories WHERE id = ?`,
+		content, id,
+	); err != nil {
+		return fmt.Errorf("failed to index content: %w", err)
+	}
+	return nil
+}
+
+// contentCompressionUpgrade adds the content_compressed column to databases
+// created before compression support and recreates the FTS triggers so they
+// skip compressed rows. The new Schema already contains both.
+const contentCompressionUpgrade = `
+ALTER TABLE memories ADD COLUMN content_compressed INTEGER NOT NULL DEFAULT 0;
+
+DROP TRIGGER IF EXISTS memories_fts_insert;
+CREATE TRIGGER memories_fts_insert
+AFTER INSERT ON memories
+FOR EACH ROW WHEN NEW.content_compressed = 0
+BEGIN
+    INSERT INTO memories_fts(id, content) VALUES (NEW.id, NEW.content);
+END;
+
+DROP TRIGGER IF EXISTS memories_fts_update;
+CREATE TRIGGER memories_fts_update
+AFTER UPDATE ON memories
+FOR EACH ROW WHEN NEW.content_compressed = 0
+BEGIN
+    UPDATE memories_fts SET content = NEW.content WHERE id = NEW.id;
+END;
+`
+
+// upgradeContentCompression applies contentCompressionUpgrade when the
+// memories table predates the content_compressed column.
+func upgradeContentCompression(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM pragma_table_info('memories') WHERE name = 'content_compressed'`,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to inspect memories table: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(contentCompressionUpgrade); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,216 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// newCompressingStore creates an in-memory store that compresses content of
+// at least threshold bytes.
+func newCompressingStore(t *testing.T, threshold int) *MemoryStore {
+	t.Helper()
+	store, err := NewMemoryStore(":memory:", WithCompressionThreshold(threshold))
+	if err != nil {
+		t.Fatalf("failed to create test store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+// largeTranscript returns repetitive, highly compressible content with a
+// distinctive search term near the end.
+func largeTranscript() string {
+	var b strings.Builder
+	for i := 0; i < 400; i++ {
+		b.WriteString("user: how is the deploy going? assistant: the deploy is still running.\n")
+	}
+	b.WriteString("final note: the rollback used the zebrafish runbook.\n")
+	return b.String()
+}
+
+// storedContent returns the raw content column and compression flag for id.
+func storedContent(t *testing.T, store *MemoryStore, id string) ([]byte, bool) {
+	t.Helper()
+	var raw []byte
+	var compressed bool
+	err := store.db.QueryRow(
+		`SELECT content, content_compressed FROM memories WHERE id = ?`, id,
+	).Scan(&raw, &compressed)
+	if err != nil {
+		t.Fatalf("failed to read raw content: %v", err)
+	}
+	return raw, compressed
+}
+
+// TestCompression_RoundTripLargeMemory verifies that large content is stored
+// compressed, is smaller on disk, and is returned verbatim by Get and List.
+func TestCompression_RoundTripLargeMemory(t *testing.T) {
+	store := newCompressingStore(t, DefaultCompressionThreshold)
+	ctx := context.Background()
+	content := largeTranscript()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:large", Content: content, Source: "test"})
+
+	raw, compressed := storedContent(t, store, "mem:test:large")
+	if !compressed {
+		t.Fatal("expected content_compressed to be set for large content")
+	}
+	if len(raw) >= len(content) {
+		t.Errorf("stored %d bytes, want fewer than the %d-byte original", len(raw), len(content))
+	}
+
+	got, err := store.Get(ctx, "mem:test:large")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if got.Content != content {
+		t.Error("Get() returned content that differs from the original")
+	}
+
+	list, err := store.List(ctx, storage.ListOptions{Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Content != content {
+		t.Error("List() did not return the decompressed content")
+	}
+}
+
+// TestCompression_FullTextSearchFindsCompressedMemory verifies that the FTS
+// index holds the uncompressed text of compressed memories.
+func TestCompression_FullTextSearchFindsCompressedMemory(t *testing.T) {
+	store := newCompressingStore(t, DefaultCompressionThreshold)
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:small", Content: "a short note about lunch", Source: "test"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:large", Content: largeTranscript(), Source: "test"})
+
+	result, err := store.FullTextSearch(ctx, storage.SearchOptions{Query: "zebrafish", Limit: 10})
+	if err != nil {
+		t.Fatalf("FullTextSearch() failed: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(result.Items))
+	}
+	if result.Items[0].ID != "mem:test:large" {
+		t.Errorf("expected mem:test:large, got %s", result.Items[0].ID)
+	}
+	if !strings.Contains(result.Items[0].Content, "zebrafish") {
+		t.Error("search result content was not decompressed")
+	}
+}
+
+// TestCompression_SmallContentStoredPlain verifies that content below the
+// threshold is left uncompressed.
+func TestCompression_SmallContentStoredPlain(t *testing.T) {
+	store := newCompressingStore(t, DefaultCompressionThreshold)
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:small", Content: "a short note", Source: "test"})
+
+	raw, compressed := storedContent(t, store, "mem:test:small")
+	if compressed {
+		t.Error("expected small content to be stored uncompressed")
+	}
+	if string(raw) != "a short note" {
+		t.Errorf("raw content = %q, want %q", raw, "a short note")
+	}
+}
+
+// TestCompression_DisabledByDefault verifies that stores opened without
+// WithCompressionThreshold never compress.
+func TestCompression_DisabledByDefault(t *testing.T) {
+	store := newTestStore(t)
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:large", Content: largeTranscript(), Source: "test"})
+
+	if _, compressed := storedContent(t, store, "mem:test:large"); compressed {
+		t.Error("expected content to be stored uncompressed when compression is disabled")
+	}
+}
+
+// TestCompression_UpdateKeepsSearchInSync verifies that the FTS index follows
+// content changes between compressed and uncompressed representations.
+func TestCompression_UpdateKeepsSearchInSync(t *testing.T) {
+	store := newCompressingStore(t, DefaultCompressionThreshold)
+	ctx := context.Background()
+
+	mem := &types.Memory{ID: "mem:test:changing", Content: "original kestrel note", Source: "test"}
+	mustStore(t, store, mem)
+
+	// Grow past the threshold: now compressed.
+	mem.Content = largeTranscript()
+	if err := store.Update(ctx, mem); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	assertSearchHits(t, store, "kestrel", 0)
+	assertSearchHits(t, store, "zebrafish", 1)
+
+	// Shrink below the threshold: back to plain text.
+	mem.Content = "replacement osprey note"
+	if err := store.Update(ctx, mem); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	assertSearchHits(t, store, "zebrafish", 0)
+	assertSearchHits(t, store, "osprey", 1)
+}
+
+// assertSearchHits fails the test unless query returns exactly want results.
+func assertSearchHits(t *testing.T, store *MemoryStore, query string, want int) {
+	t.Helper()
+	result, err := store.FullTextSearch(context.Background(), storage.SearchOptions{Query: query, Limit: 10})
+	if err != nil {
+		t.Fatalf("FullTextSearch(%q) failed: %v", query, err)
+	}
+	if len(result.Items) != want {
+		t.Errorf("FullTextSearch(%q) returned %d results, want %d", query, len(result.Items), want)
+	}
+}
+
+// TestCompression_UpgradesExistingDatabase verifies that opening a database
+// created before compression support adds the content_compressed column and
+// makes the FTS triggers skip compressed rows.
+func TestCompression_UpgradesExistingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	// Simulate a legacy database: drop the column and restore the old triggers.
+	legacy, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := legacy.Exec(Schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	for _, stmt := range []string{
+		`DROP TRIGGER memories_fts_insert`,
+		`DROP TRIGGER memories_fts_update`,
+		`ALTER TABLE memories DROP COLUMN content_compressed`,
+		`CREATE TRIGGER memories_fts_insert AFTER INSERT ON memories FOR EACH ROW
+		 BEGIN INSERT INTO memories_fts(id, content) VALUES (NEW.id, NEW.content); END`,
+		`CREATE TRIGGER memories_fts_update AFTER UPDATE ON memories FOR EACH ROW
+		 BEGIN UPDATE memories_fts SET content = NEW.content WHERE id = NEW.id; END`,
+	} {
+		if _, err := legacy.Exec(stmt); err != nil {
+			t.Fatalf("failed to build legacy schema (%s): %v", stmt, err)
+		}
+	}
+	_ = legacy.Close()
+
+	store, err := NewMemoryStore(dbPath, WithCompressionThreshold(DefaultCompressionThreshold))
+	if err != nil {
+		t.Fatalf("NewMemoryStore() on legacy database failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:large", Content: largeTranscript(), Source: "test"})
+
+	if _, compressed := storedContent(t, store, "mem:test:large"); !compressed {
+		t.Error("expected content to be compressed after upgrade")
+	}
+	assertSearchHits(t, store, "zebrafish", 1)
+}
@@ -20,7 +20,8 @@ func NewEmbeddingProvider(db *sql.DB) *EmbeddingProvider {
 	return &EmbeddingProvider{db: db}
 }
 
-// StoreEmbedding stores a vector embedding for a memory.
+// StoreEmbedding stores a vector embedding for a memory, replacing any
+// earlier embedding from the same model. Embeddings from other models are kept.
 // The embedding is serialized as a binary BLOB for efficient storage and retrieval.
 func (p *EmbeddingProvider) StoreEmbedding(ctx context.Context, memoryID string, embedding []float64, dimension int, model string) error {
 	if memoryID == "" {
@@ -54,10 +55,9 @@ func (p *EmbeddingProvider) StoreEmbedding(ctx context.Context, memoryID string,
 	query := `
 		INSERT INTO embeddings (memory_id, embedding, dimension, model, created_at, updated_at)
 		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		ON CONFLICT(memory_id) DO UPDATE SET
+		ON CONFLICT(memory_id, model) DO UPDATE SET
 			embedding = excluded.embedding,
 			dimension = excluded.dimension,
-			model = excluded.model,
 			updated_at = CURRENT_TIMESTAMP
 	`
 
@@ -69,7 +69,7 @@ func (p *EmbeddingProvider) StoreEmbedding(ctx context.Context, memoryID string,
 	return nil
 }
 
-// GetEmbedding retrieves the embedding for a memory.
+// GetEmbedding retrieves the most recently stored embedding for a memory.
 // Returns the embedding vector or storage.ErrNotFound if not found.
 func (p *EmbeddingProvider) GetEmbedding(ctx context.Context, memoryID string) ([]float64, error) {
 	if memoryID == "" {
@@ -80,6 +80,8 @@ func (p *EmbeddingProvider) GetEmbedding(ctx context.Context, memoryID string) (
 		SELECT embedding, dimension
 		FROM embeddings
 		WHERE memory_id = ?
+		ORDER BY updated_at DESC, model DESC
+		LIMIT 1
 	`
 
 	var embeddingBytes []byte
@@ -102,7 +104,154 @@ func (p *EmbeddingProvider) GetEmbedding(ctx context.Context, memoryID string) (
 	return embedding, nil
 }
 
-// DeleteEmbedding removes an embedding from the database.
+// SetEmbedding stores the embedding for memory id as a binary BLOB. The model
+// is recorded so a later provider or dimension change can be detected via
+// GetEmbedding; embeddings from other models are kept alongside it.
+// Returns storage.ErrNotFound if the memory doesn't exist.
+func (s *MemoryStore) SetEmbedding(ctx context.Context, id string, vec []float64, model string) error {
+	if id == "" {
+		return fmt.Errorf("%w: memory ID is required", storage.ErrInvalidInput)
+	}
+
+	found, err := s.exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return storage.ErrNotFound
+	}
+
+	return NewEmbeddingProvider(s.db).StoreEmbedding(ctx, id, vec, len(vec), model)
+}
+
+// GetEmbedding returns the most recently stored embedding for memory id and
+// the model that produced it. Returns storage.ErrNotFound if the memory has
+// no embedding.
+func (s *MemoryStore) GetEmbedding(ctx context.Context, id string) ([]float64, string, error) {
+	if id == "" {
+		return nil, "", fmt.Errorf("%w: memory ID is required", storage.ErrInvalidInput)
+	}
+
+	query := `
+		SELECT embedding, dimension, model
+		FROM embeddings
+		WHERE memory_id = ?
+		ORDER BY updated_at DESC, model DESC
+		LIMIT 1
+	`
+
+	var embeddingBytes []byte
+	var dimension int
+	var model string
+
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&embeddingBytes, &dimension, &model)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", storage.ErrNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get embedding: %w", err)
+	}
+
+	embedding, err := deserializeEmbedding(embeddingBytes, dimension)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to deserialize embedding: %w", err)
+	}
+
+	return embedding, model, nil
+}
+
+// checkEmbeddingDimension returns an error wrapping storage.ErrDimensionMismatch
+// when active memories have embeddings of a dimension other than dim. It
+// reports the most common stored dimension and model so the caller can tell
+// which model the store was embedded with.
+func (s *MemoryStore) checkEmbeddingDimension(ctx context.Context, dim int) error {
+	var storedDim int
+	var model string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT e.dimension, e.model
+		FROM embeddings e
+		JOIN memories m ON m.id = e.memory_id
+		WHERE m.deleted_at IS NULL AND e.dimension != ?
+		GROUP BY e.dimension, e.model
+		ORDER BY COUNT(*) DESC
+		LIMIT 1`, dim,
+	).Scan(&storedDim, &model)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check embedding dimensions: %w", err)
+	}
+	return fmt.Errorf("%w: query embedding has %d dimensions, stored embeddings have %d (model %q)",
+		storage.ErrDimensionMismatch, dim, storedDim, model)
+}
+
+// embeddingChoiceFilter returns a WHERE fragment (prefixed with " AND ") that
+// keeps one row of embeddings alias e per memory: the one from model when
+// set, otherwise the most recently stored one of its dimension.
+func embeddingChoiceFilter(model string) (string, []interface{}) {
+	if model != "" {
+		return " AND e.model = ?", []interface{}{model}
+	}
+	return ` AND NOT EXISTS (
+			SELECT 1 FROM embeddings e2
+			WHERE e2.memory_id = e.memory_id AND e2.dimension = e.dimension
+				AND (e2.updated_at > e.updated_at OR (e2.updated_at = e.updated_at AND e2.model > e.model)))`, nil
+}
+
+// upgradeEmbeddingsKey re-keys the embeddings table of databases created
+// before a memory could hold one embedding per model. SQLite cannot change a
+// primary key in place, so the table is rebuilt and every existing embedding
+// copied across.
+func upgradeEmbeddingsKey(db *sql.DB) error {
+	var pkColumns int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM pragma_table_info('embeddings') WHERE pk > 0`,
+	).Scan(&pkColumns)
+	if err != nil {
+		return fmt.Errorf("failed to inspect embeddings table: %w", err)
+	}
+	if pkColumns != 1 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range []string{
+		`CREATE TABLE embeddings_new (
+			memory_id TEXT NOT NULL,
+			embedding BLOB NOT NULL,
+			dimension INTEGER NOT NULL,
+			model TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (memory_id, model),
+			FOREIGN KEY (memory_id) REFERENCES memories(id) ON DELETE CASCADE
+		)`,
+		`INSERT INTO embeddings_new (memory_id, embedding, dimension, model, created_at, updated_at)
+			SELECT memory_id, embedding, dimension, model, created_at, updated_at FROM embeddings`,
+		`DROP TABLE embeddings`,
+		`ALTER TABLE embeddings_new RENAME TO embeddings`,
+		`CREATE INDEX IF NOT EXISTS idx_embeddings_model ON embeddings(model)`,
+		`CREATE TRIGGER IF NOT EXISTS embeddings_updated_at
+		AFTER UPDATE ON embeddings
+		FOR EACH ROW
+		BEGIN
+			UPDATE embeddings SET updated_at = CURRENT_TIMESTAMP WHERE memory_id = NEW.memory_id AND model = NEW.model;
+		END`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteEmbedding removes a memory's embeddings (from every model).
 // Returns storage.ErrNotFound if the embedding doesn't exist.
 func (p *EmbeddingProvider) DeleteEmbedding(ctx context.Context, memoryID string) error {
 	if memoryID == "" {
@@ -2,6 +2,9 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -437,3 +440,168 @@ func BenchmarkGetEmbedding(b *testing.B) {
 		_, _ = provider.GetEmbedding(ctx, memoryID)
 	}
 }
+
+func TestMemoryStore_SetAndGetEmbedding(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	mustStore(t, store, &types.Memory{ID: "mem:test:emb", Content: "embedding target", Source: "test"})
+
+	if err := store.SetEmbedding(ctx, "mem:test:emb", []float64{0.1, 0.2, 0.3}, "nomic-embed-text"); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+
+	vec, model, err := store.GetEmbedding(ctx, "mem:test:emb")
+	if err != nil {
+		t.Fatalf("GetEmbedding failed: %v", err)
+	}
+	if model != "nomic-embed-text" {
+		t.Errorf("model = %q, want %q", model, "nomic-embed-text")
+	}
+	if len(vec) != 3 || vec[0] != 0.1 || vec[2] != 0.3 {
+		t.Errorf("vector = %v, want [0.1 0.2 0.3]", vec)
+	}
+}
+
+// TestMemoryStore_SetEmbeddingKeepsOtherModels verifies that re-embedding
+// with a different model stores a second embedding: GetEmbedding returns the
+// newest one while the earlier model's vector stays searchable.
+func TestMemoryStore_SetEmbeddingKeepsOtherModels(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	mustStore(t, store, &types.Memory{ID: "mem:test:emb", Content: "embedding target", Source: "test"})
+
+	if err := store.SetEmbedding(ctx, "mem:test:emb", []float64{0.1, 0.2, 0.3}, "nomic-embed-text"); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+	if _, err := store.db.Exec(`UPDATE embeddings SET updated_at = datetime('now', '-1 hour')`); err != nil {
+		t.Fatalf("failed to age embedding: %v", err)
+	}
+	if err := store.SetEmbedding(ctx, "mem:test:emb", []float64{0.5, 0.5}, "text-embedding-3-small"); err != nil {
+		t.Fatalf("SetEmbedding (re-embed) failed: %v", err)
+	}
+
+	vec, model, err := store.GetEmbedding(ctx, "mem:test:emb")
+	if err != nil {
+		t.Fatalf("GetEmbedding failed: %v", err)
+	}
+	if model != "text-embedding-3-small" || len(vec) != 2 {
+		t.Errorf("got model %q with %d dimensions, want text-embedding-3-small with 2", model, len(vec))
+	}
+
+	result, err := store.VectorSearch(ctx, []float64{0.1, 0.2, 0.3}, storage.SearchOptions{Limit: 5, EmbeddingModel: "nomic-embed-text"})
+	if err != nil {
+		t.Fatalf("VectorSearch failed: %v", err)
+	}
+	if result.Total != 1 || result.Items[0].ID != "mem:test:emb" {
+		t.Errorf("VectorSearch with the earlier model = %+v, want mem:test:emb", result.Items)
+	}
+}
+
+// TestEmbeddings_UpgradesLegacyTable verifies that opening a database whose
+// embeddings table is keyed by memory_id alone re-keys it by (memory_id,
+// model) without losing embeddings, and that hybrid search still ranks them.
+func TestEmbeddings_UpgradesLegacyTable(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	legacy, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := legacy.Exec(Schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	if _, err := legacy.Exec(`
+		DROP TABLE embeddings;
+		CREATE TABLE embeddings (
+			memory_id TEXT PRIMARY KEY,
+			embedding BLOB NOT NULL,
+			dimension INTEGER NOT NULL,
+			model TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (memory_id) REFERENCES memories(id) ON DELETE CASCADE
+		)`); err != nil {
+		t.Fatalf("failed to build legacy embeddings table: %v", err)
+	}
+	for _, m := range []struct {
+		id, content string
+		vec         []float64
+	}{
+		{"mem:test:kestrel", "kestrel nesting notes", []float64{1, 0, 0}},
+		{"mem:test:budget", "kestrel budget spreadsheet", []float64{0, 1, 0}},
+	} {
+		if _, err := legacy.Exec(`INSERT INTO memories (id, content, source) VALUES (?, ?, 'test')`, m.id, m.content); err != nil {
+			t.Fatalf("failed to insert memory: %v", err)
+		}
+		blob, err := serializeEmbedding(m.vec)
+		if err != nil {
+			t.Fatalf("failed to serialize embedding: %v", err)
+		}
+		if _, err := legacy.Exec(`INSERT INTO embeddings (memory_id, embedding, dimension, model) VALUES (?, ?, 3, 'old-model')`, m.id, blob); err != nil {
+			t.Fatalf("failed to insert embedding: %v", err)
+		}
+	}
+	_ = legacy.Close()
+
+	store, err := NewMemoryStore(dbPath)
+	if err != nil {
+		t.Fatalf("NewMemoryStore() on legacy database failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	var pkColumns int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('embeddings') WHERE pk > 0`).Scan(&pkColumns); err != nil {
+		t.Fatalf("failed to inspect embeddings table: %v", err)
+	}
+	if pkColumns != 2 {
+		t.Errorf("embeddings primary key has %d columns, want 2", pkColumns)
+	}
+
+	vec, model, err := store.GetEmbedding(ctx, "mem:test:kestrel")
+	if err != nil {
+		t.Fatalf("GetEmbedding after upgrade failed: %v", err)
+	}
+	if model != "old-model" || len(vec) != 3 || vec[0] != 1 {
+		t.Errorf("GetEmbedding after upgrade = %v (%q), want [1 0 0] (old-model)", vec, model)
+	}
+
+	if err := store.SetEmbedding(ctx, "mem:test:kestrel", []float64{0, 0, 1}, "new-model"); err != nil {
+		t.Fatalf("SetEmbedding with a second model failed: %v", err)
+	}
+
+	result, err := store.HybridSearch(ctx, "spreadsheet", []float64{0, 1, 0}, storage.SearchOptions{Limit: 5, EmbeddingModel: "old-model"})
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+	if len(result.Items) != 2 || result.Items[0].ID != "mem:test:budget" || result.Items[1].ID != "mem:test:kestrel" {
+		t.Errorf("HybridSearch = %+v, want mem:test:budget then mem:test:kestrel", result.Items)
+	}
+}
+
+func TestMemoryStore_EmbeddingNotFound(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.SetEmbedding(ctx, "mem:test:missing", []float64{0.1}, "model"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("SetEmbedding on missing memory: got %v, want ErrNotFound", err)
+	}
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:plain", Content: "no embedding yet", Source: "test"})
+	if _, _, err := store.GetEmbedding(ctx, "mem:test:plain"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("GetEmbedding without embedding: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryStore_SetEmbeddingInvalidInput(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	mustStore(t, store, &types.Memory{ID: "mem:test:emb", Content: "embedding target", Source: "test"})
+
+	if err := store.SetEmbedding(ctx, "mem:test:emb", nil, "model"); !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("empty vector: got %v, want ErrInvalidInput", err)
+	}
+	if err := store.SetEmbedding(ctx, "mem:test:emb", []float64{0.1}, ""); !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("empty model: got %v, want ErrInvalidInput", err)
+	}
+}
@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// aggregateTimeLayouts are the layouts tried when parsing MIN/MAX(created_at).
+// SQLite drops the column's declared type on aggregates, so the driver hands
+// back the stored text instead of a time.Time.
+var aggregateTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseAggregateTime parses a timestamp returned by an aggregate over a
+// TIMESTAMP column. It returns the zero time for NULL or unparseable values.
+func parseAggregateTime(v sql.NullString) time.Time {
+	if !v.Valid {
+		return time.Time{}
+	}
+	s := v.String
+	// time.Time.String() appends the monotonic clock reading ("m=+0.0123").
+	if i := strings.Index(s, " m="); i >= 0 {
+		s = s[:i]
+	}
+	for _, layout := range aggregateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// ListEntities returns distinct entities linked to live memories, with the
+// number of linked memories and when the entity was first and last seen.
+func (s *MemoryStore) ListEntities(ctx context.Context, opts storage.EntityListOptions) (*storage.PaginatedResult[types.Entity], error) {
+	opts.Normalize()
+	now := nowUTC()
+
+	where := "m.deleted_at IS NULL AND " + notExpiredCondition("m.")
+	args := []interface{}{now}
+	if opts.Type != "" {
+		where += " AND e.type = ?"
+		args = append(args, opts.Type)
+	}
+	if opts.Name != "" {
+		where += " AND LOWER(e.name) = LOWER(?)"
+		args = append(args, opts.Name)
+	}
+
+	var total int
+	countQuery := `
+		SELECT COUNT(DISTINCT e.id)
+		FROM entities e
+		JOIN memory_entities me ON e.id = me.entity_id
+		JOIN memories m ON m.id = me.memory_id
+		WHERE ` + where
+	if err := s.GetDB().QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("sqlite: ListEntities count: %w", err)
+	}
+
+	query := `
+		SELECT e.id, e.name, e.type, e.description, e.created_at, e.updated_at,
+		       COUNT(DISTINCT m.id) AS memory_count,
+		       MIN(m.created_at), MAX(m.created_at)
+		FROM entities e
+		JOIN memory_entities me ON e.id = me.entity_id
+		JOIN memories m ON m.id = me.memory_id
+		WHERE ` + where + `
+		GROUP BY e.id
+		ORDER BY memory_count DESC, MAX(m.created_at) DESC, e.name ASC
+		LIMIT ? OFFSET ?`
+	rows, err := s.GetDB().QueryContext(ctx, query, append(args, opts.Limit, opts.Offset())...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: ListEntities: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	entities := make([]types.Entity, 0, opts.Limit)
+	for rows.Next() {
+		var e types.Entity
+		var desc, firstSeen, lastSeen sql.NullString
+		if err := rows.Scan(&e.ID, &e.Name, &e.Type, &desc, &e.CreatedAt, &e.UpdatedAt,
+			&e.MemoryCount, &firstSeen, &lastSeen); err != nil {
+			return nil, fmt.Errorf("sqlite: ListEntities scan: %w", err)
+		}
+		if desc.Valid {
+			e.Description = desc.String
+		}
+		e.FirstSeen = parseAggregateTime(firstSeen)
+		e.LastSeen = parseAggregateTime(lastSeen)
+		entities = append(entities, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: ListEntities rows: %w", err)
+	}
+
+	return &storage.PaginatedResult[types.Entity]{
+		Items:    entities,
+		Total:    total,
+		Page:     opts.Page,
+		PageSize: opts.Limit,
+		HasMore:  opts.Offset()+len(entities) < total,
+	}, nil
+}
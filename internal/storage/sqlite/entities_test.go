@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// seedEntityFixtures stores three memories a day apart and links them to
+// entities of several types:
+//
+//	alice (person)  → old, mid, new
+//	bob   (person)  → mid
+//	go    (language)→ old, new, deleted
+//	acme  (organization) → deleted only
+func seedEntityFixtures(t *testing.T, s *MemoryStore) (oldest, newest time.Time) {
+	t.Helper()
+	newest = time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+	oldest = newest.Add(-48 * time.Hour)
+
+	for i, id := range []string{"mem:test:old", "mem:test:mid", "mem:test:new", "mem:test:deleted"} {
+		mustStore(t, s, &types.Memory{
+			ID:        id,
+			Content:   "entity fixture " + id,
+			Source:    "test",
+			CreatedAt: oldest.Add(time.Duration(i) * 24 * time.Hour),
+		})
+	}
+	if err := s.Delete(context.Background(), "mem:test:deleted"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+
+	insertEntity(t, s, "ent:alice", "alice", types.EntityTypePerson)
+	insertEntity(t, s, "ent:bob", "bob", types.EntityTypePerson)
+	insertEntity(t, s, "ent:go", "go", types.EntityTypeLanguage)
+	insertEntity(t, s, "ent:acme", "acme", types.EntityTypeOrganization)
+
+	linkMemoryEntity(t, s, "mem:test:old", "ent:alice")
+	linkMemoryEntity(t, s, "mem:test:mid", "ent:alice")
+	linkMemoryEntity(t, s, "mem:test:new", "ent:alice")
+	linkMemoryEntity(t, s, "mem:test:mid", "ent:bob")
+	linkMemoryEntity(t, s, "mem:test:old", "ent:go")
+	linkMemoryEntity(t, s, "mem:test:new", "ent:go")
+	linkMemoryEntity(t, s, "mem:test:deleted", "ent:go")
+	linkMemoryEntity(t, s, "mem:test:deleted", "ent:acme")
+	return oldest, newest
+}
+
+func TestListEntities_CountsAndOrder(t *testing.T) {
+	store := newTestStore(t)
+	oldest, newest := seedEntityFixtures(t, store)
+
+	result, err := store.ListEntities(context.Background(), storage.EntityListOptions{})
+	if err != nil {
+		t.Fatalf("ListEntities() failed: %v", err)
+	}
+	if result.Total != 3 {
+		t.Errorf("Total = %d, want 3 (acme only links a deleted memory)", result.Total)
+	}
+
+	want := []struct {
+		id    string
+		count int
+	}{{"ent:alice", 3}, {"ent:go", 2}, {"ent:bob", 1}}
+	if len(result.Items) != len(want) {
+		t.Fatalf("got %d entities, want %d", len(result.Items), len(want))
+	}
+	for i, w := range want {
+		got := result.Items[i]
+		if got.ID != w.id || got.MemoryCount != w.count {
+			t.Errorf("Items[%d] = %s (%d), want %s (%d)", i, got.ID, got.MemoryCount, w.id, w.count)
+		}
+	}
+
+	alice := result.Items[0]
+	if !alice.FirstSeen.Equal(oldest) {
+		t.Errorf("alice FirstSeen = %v, want %v", alice.FirstSeen, oldest)
+	}
+	if !alice.LastSeen.Equal(newest) {
+		t.Errorf("alice LastSeen = %v, want %v", alice.LastSeen, newest)
+	}
+	// The deleted memory is newer than "new" but must not count as a sighting.
+	if goEnt := result.Items[1]; !goEnt.LastSeen.Equal(newest) {
+		t.Errorf("go LastSeen = %v, want %v", goEnt.LastSeen, newest)
+	}
+}
+
+func TestListEntities_TypeFilterAndPaging(t *testing.T) {
+	store := newTestStore(t)
+	seedEntityFixtures(t, store)
+	ctx := context.Background()
+
+	result, err := store.ListEntities(ctx, storage.EntityListOptions{Type: types.EntityTypePerson, Limit: 1})
+	if err != nil {
+		t.Fatalf("ListEntities(person) failed: %v", err)
+	}
+	if result.Total != 2 || !result.HasMore {
+		t.Errorf("Total = %d, HasMore = %v; want 2, true", result.Total, result.HasMore)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "ent:alice" {
+		t.Fatalf("page 1 = %+v, want alice", result.Items)
+	}
+
+	result, err = store.ListEntities(ctx, storage.EntityListOptions{Type: types.EntityTypePerson, Limit: 1, Page: 2})
+	if err != nil {
+		t.Fatalf("ListEntities(person, page 2) failed: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "ent:bob" || result.HasMore {
+		t.Errorf("page 2 = %+v (HasMore %v), want bob and no more pages", result.Items, result.HasMore)
+	}
+
+	result, err = store.ListEntities(ctx, storage.EntityListOptions{Type: types.EntityTypeOrganization})
+	if err != nil {
+		t.Fatalf("ListEntities(organization) failed: %v", err)
+	}
+	if result.Total != 0 || len(result.Items) != 0 {
+		t.Errorf("organization result = %+v, want empty", result.Items)
+	}
+}
+
+func TestListEntities_NameFilter(t *testing.T) {
+	store := newTestStore(t)
+	seedEntityFixtures(t, store)
+
+	result, err := store.ListEntities(context.Background(), storage.EntityListOptions{Name: "ALICE"})
+	if err != nil {
+		t.Fatalf("ListEntities(ALICE) failed: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "ent:alice" {
+		t.Errorf("ListEntities(ALICE) = %+v, want alice only", result.Items)
+	}
+}
+
+func TestList_EntityIDsFilter(t *testing.T) {
+	store := newTestStore(t)
+	oldest, _ := seedEntityFixtures(t, store)
+	ctx := context.Background()
+
+	ids := func(opts storage.ListOptions) []string {
+		t.Helper()
+		result, err := store.List(ctx, opts)
+		if err != nil {
+			t.Fatalf("List(%+v) failed: %v", opts, err)
+		}
+		got := []string{}
+		for _, m := range result.Items {
+			got = append(got, m.ID)
+		}
+		return got
+	}
+
+	if got := ids(storage.ListOptions{EntityIDs: []string{"ent:bob"}}); len(got) != 1 || got[0] != "mem:test:mid" {
+		t.Errorf("bob memories = %v, want [mem:test:mid]", got)
+	}
+	// The deleted memory linked to go and acme stays hidden.
+	if got := ids(storage.ListOptions{EntityIDs: []string{"ent:bob", "ent:go", "ent:acme"}, SortBy: "created_at", SortOrder: "asc"}); len(got) != 3 ||
+		got[0] != "mem:test:old" || got[1] != "mem:test:mid" || got[2] != "mem:test:new" {
+		t.Errorf("bob/go/acme memories = %v, want old, mid, new", got)
+	}
+	if got := ids(storage.ListOptions{EntityIDs: []string{"ent:alice"}, CreatedAfter: oldest}); len(got) != 2 {
+		t.Errorf("alice memories after the oldest = %v, want 2", got)
+	}
+}
+
+func TestParseAggregateTime(t *testing.T) {
+	want := time.Date(2026, 3, 3, 12, 0, 0, 500, time.UTC)
+	for _, s := range []string{
+		want.String(),
+		want.String() + " m=+0.001234567",
+		want.Format("2006-01-02 15:04:05.999999999-07:00"),
+		want.Format(time.RFC3339Nano),
+	} {
+		if got := parseAggregateTime(sql.NullString{String: s, Valid: true}); !got.Equal(want) {
+			t.Errorf("parseAggregateTime(%q) = %v, want %v", s, got, want)
+		}
+	}
+	if got := parseAggregateTime(sql.NullString{}); !got.IsZero() {
+		t.Errorf("parseAggregateTime(NULL) = %v, want zero", got)
+	}
+}
@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+func TestMergeEntities(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	storeTestMemory(t, store, "mem:test:1", "Alice reviewed the design")
+	storeTestMemory(t, store, "mem:test:2", "alice shipped the release")
+	storeTestMemory(t, store, "mem:test:3", "Alice Smith and alice met")
+	insertEntity(t, store, "ent:alice", "Alice", "person")
+	insertEntity(t, store, "ent:alice-lower", "alice", "person")
+	insertEntity(t, store, "ent:alice-smith", "Alice Smith", "person")
+	insertEntity(t, store, "ent:memento", "Memento", "project")
+	linkMemoryEntity(t, store, "mem:test:1", "ent:alice")
+	linkMemoryEntity(t, store, "mem:test:2", "ent:alice-lower")
+	linkMemoryEntity(t, store, "mem:test:3", "ent:alice-lower")
+	linkMemoryEntity(t, store, "mem:test:3", "ent:alice-smith")
+	insertRelationship(t, store, "rel:1", "ent:alice", "ent:memento", "works_on")
+	insertRelationship(t, store, "rel:2", "ent:alice-lower", "ent:memento", "works_on")
+	insertRelationship(t, store, "rel:3", "ent:memento", "ent:alice-smith", "owned_by")
+	insertRelationship(t, store, "rel:4", "ent:alice-smith", "ent:alice", "knows")
+
+	result, err := store.MergeEntities(ctx, "ent:alice", []string{"ent:alice-lower", "ent:alice-smith"})
+	if err != nil {
+		t.Fatalf("MergeEntities() failed: %v", err)
+	}
+	// mem:test:2 and mem:test:3 move over; the second link of mem:test:3 is
+	// dropped. rel:3 moves over; rel:2 duplicates rel:1 and rel:4 would be a
+	// self-loop.
+	if result.MemoriesRepointed != 2 || result.RelationshipsRepointed != 1 || result.RelationshipsDropped != 2 {
+		t.Errorf("MergeEntities() = %+v, want 2 memories repointed, 1 relationship repointed, 2 dropped", result)
+	}
+
+	var links int
+	if err := store.GetDB().QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM memory_entities WHERE entity_id = 'ent:alice'`).Scan(&links); err != nil {
+		t.Fatal(err)
+	}
+	if links != 3 {
+		t.Errorf("canonical entity linked to %d memories, want 3", links)
+	}
+	var remaining int
+	if err := store.GetDB().QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM entities WHERE id IN ('ent:alice-lower', 'ent:alice-smith')`).Scan(&remaining); err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 0 {
+		t.Errorf("%d duplicate entities remain, want 0", remaining)
+	}
+	var target string
+	if err := store.GetDB().QueryRowContext(ctx,
+		`SELECT target_id FROM relationships WHERE id = 'rel:3'`).Scan(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target != "ent:alice" {
+		t.Errorf("rel:3 targets %q, want ent:alice", target)
+	}
+}
+
+func TestMergeEntities_InvalidInput(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	insertEntity(t, store, "ent:alice", "Alice", "person")
+	insertEntity(t, store, "ent:alice-lower", "alice", "person")
+
+	for _, tc := range []struct {
+		canonical  string
+		duplicates []string
+		want       error
+	}{
+		{"ent:alice", nil, storage.ErrInvalidInput},
+		{"ent:alice", []string{"ent:alice"}, storage.ErrInvalidInput},
+		{"ent:alice", []string{"ent:alice-lower", "ent:alice-lower"}, storage.ErrInvalidInput},
+		{"ent:missing", []string{"ent:alice-lower"}, storage.ErrNotFound},
+		{"ent:alice", []string{"ent:alice-lower", "ent:missing"}, storage.ErrNotFound},
+	} {
+		if _, err := store.MergeEntities(ctx, tc.canonical, tc.duplicates); !errors.Is(err, tc.want) {
+			t.Errorf("MergeEntities(%q, %v) error = %v, want %v", tc.canonical, tc.duplicates, err, tc.want)
+		}
+	}
+
+	// A failed merge changes nothing.
+	var n int
+	if err := store.GetDB().QueryRowContext(ctx, `SELECT COUNT(*) FROM entities`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("%d entities after failed merges, want 2", n)
+	}
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// nullableUTCTime converts an optional timestamp to a UTC sql.NullTime so
+// expires_at comparisons against nowUTC are consistent regardless of the
+// caller's time zone.
+func nullableUTCTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// nowUTC returns the current time in UTC for expires_at comparisons.
+func nowUTC() time.Time {
+	return time.Now().UTC()
+}
+
+// notExpiredCondition returns a WHERE fragment matching memories that have no
+// expiry or whose expiry is still in the future. The caller binds the current
+// time to its single placeholder. alias is an optional table prefix ("m.").
+func notExpiredCondition(alias string) string {
+	return fmt.Sprintf("(%[1]sexpires_at IS NULL OR %[1]sexpires_at > ?)", alias)
+}
+
+// expiryFilter returns a WHERE fragment (prefixed with " AND ") excluding
+// expired memories, plus its bound arguments. Both are empty when
+// includeExpired is set.
+func expiryFilter(alias string, includeExpired bool) (string, []interface{}) {
+	if includeExpired {
+		return "", nil
+	}
+	return " AND " + notExpiredCondition(alias), []interface{}{nowUTC()}
+}
+
+// upgradeExpiresAt adds the expires_at column to databases created before
+// per-memory expiry, then creates its index. The index is created here rather
+// than in Schema because legacy tables lack the column when Schema runs.
+func upgradeExpiresAt(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM pragma_table_info('memories') WHERE name = 'expires_at'`,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to inspect memories table: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE memories ADD COLUMN expires_at TIMESTAMP`); err != nil {
+			return err
+		}
+	}
+
+	_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_memories_expires_at ON memories(expires_at) WHERE expires_at IS NOT NULL`)
+	return err
+}
+
+// ExpireMemories soft-deletes every memory whose expires_at is at or before
+// now and returns how many were expired. Already-deleted memories are left
+// untouched so their original deleted_at is preserved.
+func (s *MemoryStore) ExpireMemories(ctx context.Context, now time.Time) (int, error) {
+	now = now.UTC()
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE memories
+		SET deleted_at = ?, updated_at = ?
+		WHERE deleted_at IS NULL AND expires_at IS NOT NULL AND expires_at <= ?`,
+		now, now, now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire memories: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count expired memories: %w", err)
+	}
+	return int(n), nil
+}
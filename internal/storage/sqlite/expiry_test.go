@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// storeExpiryFixtures stores one memory without an expiry, one that expired an
+// hour ago and one that expires in an hour. All three mention "heron".
+func storeExpiryFixtures(t *testing.T, store *MemoryStore) {
+	t.Helper()
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:forever", Content: "heron sighting at the lake", Source: "test"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:expired", Content: "heron feeding schedule", Source: "test", ExpiresAt: &past})
+	mustStore(t, store, &types.Memory{ID: "mem:test:pending", Content: "heron nesting survey", Source: "test", ExpiresAt: &future})
+}
+
+func memoryIDs(items []types.Memory) map[string]bool {
+	ids := make(map[string]bool, len(items))
+	for _, m := range items {
+		ids[m.ID] = true
+	}
+	return ids
+}
+
+func TestExpiry_StoreAndGetRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	mustStore(t, store, &types.Memory{ID: "mem:test:ttl", Content: "short-lived note", Source: "test", ExpiresAt: &expiresAt})
+
+	got, err := store.Get(context.Background(), "mem:test:ttl")
+	if err != nil {
+		t.Fatalf("Get() failed: %v", err)
+	}
+	if got.ExpiresAt == nil || !got.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("ExpiresAt = %v, want %v", got.ExpiresAt, expiresAt)
+	}
+}
+
+func TestExpiry_ListExcludesExpiredByDefault(t *testing.T) {
+	store := newTestStore(t)
+	storeExpiryFixtures(t, store)
+	ctx := context.Background()
+
+	result, err := store.List(ctx, storage.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() failed: %v", err)
+	}
+	ids := memoryIDs(result.Items)
+	if ids["mem:test:expired"] {
+		t.Error("List() returned an expired memory")
+	}
+	if !ids["mem:test:forever"] || !ids["mem:test:pending"] {
+		t.Errorf("List() = %v, want the unexpired memories", ids)
+	}
+	if result.Total != 2 {
+		t.Errorf("Total = %d, want 2", result.Total)
+	}
+
+	result, err = store.List(ctx, storage.ListOptions{IncludeExpired: true})
+	if err != nil {
+		t.Fatalf("List(IncludeExpired) failed: %v", err)
+	}
+	if !memoryIDs(result.Items)["mem:test:expired"] {
+		t.Error("List(IncludeExpired) did not return the expired memory")
+	}
+}
+
+func TestExpiry_FullTextSearchExcludesExpired(t *testing.T) {
+	store := newTestStore(t)
+	storeExpiryFixtures(t, store)
+	ctx := context.Background()
+
+	result, err := store.FullTextSearch(ctx, storage.SearchOptions{Query: "heron"})
+	if err != nil {
+		t.Fatalf("FullTextSearch() failed: %v", err)
+	}
+	if memoryIDs(result.Items)["mem:test:expired"] {
+		t.Error("FullTextSearch() returned an expired memory")
+	}
+	if result.Total != 2 {
+		t.Errorf("Total = %d, want 2", result.Total)
+	}
+
+	result, err = store.FullTextSearch(ctx, storage.SearchOptions{Query: "heron", IncludeExpired: true})
+	if err != nil {
+		t.Fatalf("FullTextSearch(IncludeExpired) failed: %v", err)
+	}
+	if result.Total != 3 {
+		t.Errorf("Total with IncludeExpired = %d, want 3", result.Total)
+	}
+}
+
+func TestExpiry_ExpireMemoriesSoftDeletes(t *testing.T) {
+	store := newTestStore(t)
+	storeExpiryFixtures(t, store)
+	ctx := context.Background()
+
+	n, err := store.ExpireMemories(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("ExpireMemories() failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("ExpireMemories() = %d, want 1", n)
+	}
+
+	if _, err := store.Get(ctx, "mem:test:expired"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Get(expired) error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(ctx, "mem:test:pending"); err != nil {
+		t.Errorf("Get(pending) failed: %v", err)
+	}
+
+	// The expired memory is recoverable like any other soft delete.
+	if err := store.Restore(ctx, "mem:test:expired"); err != nil {
+		t.Errorf("Restore(expired) failed: %v", err)
+	}
+
+	// A second sweep leaves already-deleted memories alone.
+	if err := store.Delete(ctx, "mem:test:expired"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	n, err = store.ExpireMemories(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("ExpireMemories() failed: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("second ExpireMemories() = %d, want 0", n)
+	}
+}
+
+// TestExpiry_UpgradesExistingDatabase verifies that opening a database
+// created before per-memory expiry adds the expires_at column.
+func TestExpiry_UpgradesExistingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	legacy, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := legacy.Exec(Schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	if _, err := legacy.Exec(`ALTER TABLE memories DROP COLUMN expires_at`); err != nil {
+		t.Fatalf("failed to build legacy schema: %v", err)
+	}
+	_ = legacy.Close()
+
+	store, err := NewMemoryStore(dbPath)
+	if err != nil {
+		t.Fatalf("NewMemoryStore() on legacy database failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	storeExpiryFixtures(t, store)
+	n, err := store.ExpireMemories(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("ExpireMemories() failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("ExpireMemories() = %d, want 1", n)
+	}
+}
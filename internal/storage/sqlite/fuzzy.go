@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// maxFuzzyTerms caps how many similar vocabulary terms a fuzzy search ORs
+// together, keeping the most similar.
+const maxFuzzyTerms = 20
+
+// addFuzzyMatches appends to result memories containing an indexed word
+// whose trigram similarity to a query word is at least opts.FuzzyThreshold,
+// up to opts.FuzzyMaxResults and opts.Limit in total. Candidate words come
+// from the memories_fts_vocab table, so a typo such as "postgress" finds
+// memories about "postgres" without scanning memory content. Only the first
+// page of a natural-language query is fuzzy-matched.
+func (s *MemoryStore) addFuzzyMatches(ctx context.Context, result *storage.PaginatedResult[types.Memory], opts storage.SearchOptions) error {
+	room := min(opts.FuzzyMaxResults, opts.Limit-len(result.Items))
+	if room <= 0 || opts.Offset > 0 || storage.ParseSearchQuery(opts.Query) != nil {
+		return nil
+	}
+
+	terms, err := s.similarTerms(ctx, storage.FuzzyQueryWords(opts.Query), opts.FuzzyThreshold)
+	if err != nil || len(terms) == 0 {
+		return err
+	}
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = ftsString(term)
+	}
+
+	// The similar terms usually include the query's own words, so ask for
+	// enough rows to fill the room after skipping the exact matches.
+	fuzzyOpts := opts
+	fuzzyOpts.Query = strings.Join(quoted, " OR ")
+	fuzzyOpts.Limit = len(result.Items) + room
+	fuzzyOpts.FuzzyFallback = false
+	fuzzyOpts.Fuzzy = false
+	fuzzy, err := s.matchFullText(ctx, fuzzyOpts)
+	if err != nil {
+		return fmt.Errorf("sqlite: fuzzy search: %w", err)
+	}
+
+	seen := make(map[string]bool, len(result.Items))
+	for _, m := range result.Items {
+		seen[m.ID] = true
+	}
+	for _, m := range fuzzy.Items {
+		if seen[m.ID] || room == 0 {
+			continue
+		}
+		result.Items = append(result.Items, m)
+		result.Total++
+		room--
+	}
+	return nil
+}
+
+// similarTerms returns the memories_fts_vocab terms whose trigram
+// similarity to any of words is at least threshold, most similar first.
+func (s *MemoryStore) similarTerms(ctx context.Context, words []string, threshold float64) ([]string, error) {
+	if len(words) == 0 {
+		return nil, nil
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT term FROM memories_fts_vocab`)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: fuzzy search vocabulary: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	type candidate struct {
+		term       string
+		similarity float64
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var term string
+		if err := rows.Scan(&term); err != nil {
+			return nil, fmt.Errorf("sqlite: fuzzy search vocabulary: %w", err)
+		}
+		best := 0.0
+		for _, w := range words {
+			best = max(best, trigramSimilarity(w, term))
+		}
+		if best >= threshold {
+			candidates = append(candidates, candidate{term, best})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: fuzzy search vocabulary: %w", err)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].similarity > candidates[j].similarity })
+	terms := make([]string, 0, min(len(candidates), maxFuzzyTerms))
+	for _, c := range candidates[:min(len(candidates), maxFuzzyTerms)] {
+		terms = append(terms, c.term)
+	}
+	return terms, nil
+}
+
+// trigramSimilarity returns the similarity of two words the way PostgreSQL's
+// pg_trgm computes it: the share of their trigrams, padded with two leading
+// spaces and one trailing space, that they have in common.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	union := len(ta) + len(tb) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// trigrams returns the set of padded trigrams of word.
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+	set := make(map[string]bool, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// TestFullTextSearch_FuzzyMatchesTypos verifies that Fuzzy finds memories
+// whose spelling differs from the query, after the exact matches.
+func TestFullTextSearch_FuzzyMatchesTypos(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:fuzzy-typo", Content: "Reindexed the elasticsearch cluster", Source: "test"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:fuzzy-exact", Content: "Elasticserch upgrade scheduled for Friday", Source: "test"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:fuzzy-typo-2", Content: "Elasticsearch snapshots restored", Source: "test"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:fuzzy-other", Content: "Lunch order for the team", Source: "test"})
+
+	result, err := store.FullTextSearch(ctx, storage.SearchOptions{Query: "elasticserch", Limit: 10})
+	if err != nil {
+		t.Fatalf("FullTextSearch(): %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "mem:test:fuzzy-exact" {
+		t.Fatalf("exact search: got %d results, want only mem:test:fuzzy-exact", len(result.Items))
+	}
+
+	result, err = store.FullTextSearch(ctx, storage.SearchOptions{Query: "elasticserch", Limit: 10, Fuzzy: true})
+	if err != nil {
+		t.Fatalf("FullTextSearch(Fuzzy): %v", err)
+	}
+	if len(result.Items) != 3 || result.Total != 3 {
+		t.Fatalf("fuzzy search: got %d results (total %d), want 3", len(result.Items), result.Total)
+	}
+	if result.Items[0].ID != "mem:test:fuzzy-exact" {
+		t.Errorf("fuzzy search: got %s first, want the exact match before the typos", result.Items[0].ID)
+	}
+
+	// The threshold and cap are honoured.
+	result, err = store.FullTextSearch(ctx, storage.SearchOptions{Query: "elasticserch", Limit: 10, Fuzzy: true, FuzzyThreshold: 0.95})
+	if err != nil {
+		t.Fatalf("FullTextSearch(FuzzyThreshold): %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Errorf("strict threshold: got %d results, want 1", len(result.Items))
+	}
+	result, err = store.FullTextSearch(ctx, storage.SearchOptions{Query: "elasticserch", Limit: 10, Fuzzy: true, FuzzyMaxResults: 1})
+	if err != nil {
+		t.Fatalf("FullTextSearch(FuzzyMaxResults): %v", err)
+	}
+	if len(result.Items) != 2 {
+		t.Errorf("max results 1: got %d results, want the exact match plus 1 fuzzy", len(result.Items))
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	if got := trigramSimilarity("postgres", "postgres"); got != 1 {
+		t.Errorf("identical words: got %v, want 1", got)
+	}
+	if got := trigramSimilarity("elasticserch", "elasticsearch"); got < 0.6 {
+		t.Errorf("one-letter typo: got %v, want >= 0.6", got)
+	}
+	if got := trigramSimilarity("postgres", "lunch"); got != 0 {
+		t.Errorf("unrelated words: got %v, want 0", got)
+	}
+}
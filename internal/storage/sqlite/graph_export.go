@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// ExportGraph returns the graph of the memories matching opts. It
+// implements storage.GraphExporter.
+func (s *MemoryStore) ExportGraph(ctx context.Context, opts storage.GraphExportOptions) (*storage.GraphExport, error) {
+	if opts.MaxNodes < 1 {
+		return nil, fmt.Errorf("%w: MaxNodes must be positive", storage.ErrInvalidInput)
+	}
+	graph := &storage.GraphExport{
+		Memories:      []types.Memory{},
+		Entities:      []types.Entity{},
+		Mentions:      []storage.EntityMention{},
+		Relationships: []types.Relationship{},
+		Links:         []storage.MemoryLink{},
+	}
+
+	conditions := []string{"deleted_at IS NULL", notExpiredCondition("")}
+	args := []interface{}{nowUTC()}
+	if opts.Domain != "" {
+		conditions = append(conditions, "domain = ?")
+		args = append(args, opts.Domain)
+	}
+	if !opts.CreatedAfter.IsZero() {
+		conditions = append(conditions, "created_at > ?")
+		args = append(args, opts.CreatedAfter)
+	}
+	if !opts.CreatedBefore.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, opts.CreatedBefore)
+	}
+	// One extra row tells whether the cap cut the memories short.
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, content, content_compressed, domain, memory_type, state, created_at
+		FROM memories
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY created_at DESC, id
+		LIMIT ?`, append(args, opts.MaxNodes+1)...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: ExportGraph memories: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var m types.Memory
+		var domain, memoryType, state sql.NullString
+		var compressed bool
+		if err := rows.Scan(&m.ID, &m.Content, &compressed, &domain, &memoryType, &state, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlite: ExportGraph memories scan: %w", err)
+		}
+		if m.Content, err = DecodeContent(m.Content, compressed); err != nil {
+			return nil, err
+		}
+		m.Domain, m.MemoryType, m.State = domain.String, memoryType.String, state.String
+		graph.Memories = append(graph.Memories, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: ExportGraph memories rows: %w", err)
+	}
+	if len(graph.Memories) > opts.MaxNodes {
+		graph.Memories = graph.Memories[:opts.MaxNodes]
+		graph.Truncated = true
+	}
+	if len(graph.Memories) == 0 {
+		return graph, nil
+	}
+
+	memoryIDs := make([]interface{}, len(graph.Memories))
+	for i, m := range graph.Memories {
+		memoryIDs[i] = m.ID
+	}
+	memoryIn := buildInClause(len(memoryIDs))
+
+	// Entities fill the nodes the memories left, most mentioned first.
+	remaining := opts.MaxNodes - len(graph.Memories)
+	entRows, err := s.db.QueryContext(ctx, `
+		SELECT e.id, e.name, e.type, e.description, e.created_at, e.updated_at, COUNT(*) AS memory_count
+		FROM memory_entities me
+		JOIN entities e ON e.id = me.entity_id
+		WHERE me.memory_id IN (`+memoryIn+`)
+		GROUP BY e.id, e.name, e.type, e.description, e.created_at, e.updated_at
+		ORDER BY memory_count DESC, e.name, e.id
+		LIMIT ?`, append(memoryIDs, remaining+1)...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: ExportGraph entities: %w", err)
+	}
+	defer func() { _ = entRows.Close() }()
+	for entRows.Next() {
+		var e types.Entity
+		var desc sql.NullString
+		if err := entRows.Scan(&e.ID, &e.Name, &e.Type, &desc, &e.CreatedAt, &e.UpdatedAt, &e.MemoryCount); err != nil {
+			return nil, fmt.Errorf("sqlite: ExportGraph entities scan: %w", err)
+		}
+		e.Description = desc.String
+		graph.Entities = append(graph.Entities, e)
+	}
+	if err := entRows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: ExportGraph entities rows: %w", err)
+	}
+	if len(graph.Entities) > remaining {
+		graph.Entities = graph.Entities[:remaining]
+		graph.Truncated = true
+	}
+
+	if len(graph.Entities) > 0 {
+		entityIDs := make([]interface{}, len(graph.Entities))
+		for i, e := range graph.Entities {
+			entityIDs[i] = e.ID
+		}
+		entityIn := buildInClause(len(entityIDs))
+
+		mentionRows, err := s.db.QueryContext(ctx, `
+			SELECT memory_id, entity_id, frequency, confidence
+			FROM memory_entities
+			WHERE memory_id IN (`+memoryIn+`) AND entity_id IN (`+entityIn+`)
+			ORDER BY memory_id, entity_id`, append(append([]interface{}{}, memoryIDs...), entityIDs...)...)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: ExportGraph mentions: %w", err)
+		}
+		defer func() { _ = mentionRows.Close() }()
+		for mentionRows.Next() {
+			var m storage.EntityMention
+			if err := mentionRows.Scan(&m.MemoryID, &m.EntityID, &m.Frequency, &m.Confidence); err != nil {
+				return nil, fmt.Errorf("sqlite: ExportGraph mentions scan: %w", err)
+			}
+			graph.Mentions = append(graph.Mentions, m)
+		}
+		if err := mentionRows.Err(); err != nil {
+			return nil, fmt.Errorf("sqlite: ExportGraph mentions rows: %w", err)
+		}
+
+		relRows, err := s.db.QueryContext(ctx, `
+			SELECT id, source_id, target_id, type, weight, created_at, updated_at
+			FROM relationships
+			WHERE source_id IN (`+entityIn+`) AND target_id IN (`+entityIn+`)
+			ORDER BY id`, append(append([]interface{}{}, entityIDs...), entityIDs...)...)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: ExportGraph relationships: %w", err)
+		}
+		defer func() { _ = relRows.Close() }()
+		for relRows.Next() {
+			var rel types.Relationship
+			if err := relRows.Scan(&rel.ID, &rel.FromID, &rel.ToID, &rel.Type, &rel.Strength, &rel.CreatedAt, &rel.UpdatedAt); err != nil {
+				return nil, fmt.Errorf("sqlite: ExportGraph relationships scan: %w", err)
+			}
+			graph.Relationships = append(graph.Relationships, rel)
+		}
+		if err := relRows.Err(); err != nil {
+			return nil, fmt.Errorf("sqlite: ExportGraph relationships rows: %w", err)
+		}
+	}
+
+	linkRows, err := s.db.QueryContext(ctx, `
+		SELECT id, source_id, target_id, type, created_at
+		FROM memory_links
+		WHERE source_id IN (`+memoryIn+`) AND target_id IN (`+memoryIn+`)
+		ORDER BY created_at, id`, append(append([]interface{}{}, memoryIDs...), memoryIDs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: ExportGraph links: %w", err)
+	}
+	defer func() { _ = linkRows.Close() }()
+	for linkRows.Next() {
+		var link storage.MemoryLink
+		if err := linkRows.Scan(&link.ID, &link.SourceID, &link.TargetID, &link.Type, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlite: ExportGraph links scan: %w", err)
+		}
+		graph.Links = append(graph.Links, link)
+	}
+	if err := linkRows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: ExportGraph links rows: %w", err)
+	}
+
+	return graph, nil
+}
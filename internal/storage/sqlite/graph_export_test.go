@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// seedExportGraph stores three memories mentioning three entities, with two
+// relationships and one memory link.
+func seedExportGraph(t *testing.T, s *MemoryStore) {
+	t.Helper()
+	ctx := context.Background()
+	for i, id := range []string{"mem:test:old", "mem:test:mid", "mem:test:new"} {
+		mem := &types.Memory{
+			ID:        id,
+			Content:   "memory " + id,
+			Source:    "test",
+			Domain:    "work",
+			Status:    types.StatusEnriched,
+			CreatedAt: time.Date(2026, 1, 1+i, 0, 0, 0, 0, time.UTC),
+		}
+		if i == 0 {
+			mem.Domain = "personal"
+		}
+		if err := s.Store(ctx, mem); err != nil {
+			t.Fatalf("Store(%q): %v", id, err)
+		}
+	}
+	insertEntity(t, s, "ent:alice", "Alice", "person")
+	insertEntity(t, s, "ent:bob", "Bob", "person")
+	insertEntity(t, s, "ent:memento", "Memento", "project")
+	linkMemoryEntity(t, s, "mem:test:old", "ent:alice")
+	linkMemoryEntity(t, s, "mem:test:mid", "ent:alice")
+	linkMemoryEntity(t, s, "mem:test:mid", "ent:bob")
+	linkMemoryEntity(t, s, "mem:test:new", "ent:alice")
+	linkMemoryEntity(t, s, "mem:test:new", "ent:memento")
+	insertRelationship(t, s, "rel:1", "ent:alice", "ent:bob", "knows")
+	insertRelationship(t, s, "rel:2", "ent:alice", "ent:memento", "works_on")
+	if err := s.CreateMemoryLink(ctx, "link:1", "mem:test:new", "mem:test:mid", "RELATES_TO"); err != nil {
+		t.Fatalf("CreateMemoryLink: %v", err)
+	}
+}
+
+func TestExportGraph(t *testing.T) {
+	store := newTestStore(t)
+	seedExportGraph(t, store)
+	ctx := context.Background()
+
+	graph, err := store.ExportGraph(ctx, storage.GraphExportOptions{MaxNodes: 100})
+	if err != nil {
+		t.Fatalf("ExportGraph() failed: %v", err)
+	}
+	if len(graph.Memories) != 3 || len(graph.Entities) != 3 || len(graph.Mentions) != 5 ||
+		len(graph.Relationships) != 2 || len(graph.Links) != 1 || graph.Truncated {
+		t.Errorf("ExportGraph() = %d memories, %d entities, %d mentions, %d relationships, %d links, truncated %v; want 3, 3, 5, 2, 1, false",
+			len(graph.Memories), len(graph.Entities), len(graph.Mentions), len(graph.Relationships), len(graph.Links), graph.Truncated)
+	}
+	if graph.Memories[0].ID != "mem:test:new" {
+		t.Errorf("first memory = %q, want the newest", graph.Memories[0].ID)
+	}
+	if graph.Entities[0].ID != "ent:alice" || graph.Entities[0].MemoryCount != 3 {
+		t.Errorf("first entity = %q mentioned %d times, want ent:alice mentioned 3 times", graph.Entities[0].ID, graph.Entities[0].MemoryCount)
+	}
+}
+
+func TestExportGraph_Filters(t *testing.T) {
+	store := newTestStore(t)
+	seedExportGraph(t, store)
+	ctx := context.Background()
+
+	graph, err := store.ExportGraph(ctx, storage.GraphExportOptions{Domain: "work", MaxNodes: 100})
+	if err != nil {
+		t.Fatalf("ExportGraph() failed: %v", err)
+	}
+	if len(graph.Memories) != 2 || len(graph.Entities) != 3 || len(graph.Mentions) != 4 || len(graph.Links) != 1 {
+		t.Errorf("domain filter: %d memories, %d entities, %d mentions, %d links; want 2, 3, 4, 1",
+			len(graph.Memories), len(graph.Entities), len(graph.Mentions), len(graph.Links))
+	}
+
+	graph, err = store.ExportGraph(ctx, storage.GraphExportOptions{
+		CreatedBefore: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC),
+		MaxNodes:      100,
+	})
+	if err != nil {
+		t.Fatalf("ExportGraph() failed: %v", err)
+	}
+	// The link's source is the newest memory, which is filtered out.
+	if len(graph.Memories) != 2 || len(graph.Entities) != 2 || len(graph.Relationships) != 1 || len(graph.Links) != 0 {
+		t.Errorf("date filter: %d memories, %d entities, %d relationships, %d links; want 2, 2, 1, 0",
+			len(graph.Memories), len(graph.Entities), len(graph.Relationships), len(graph.Links))
+	}
+
+	// The three memories and the most mentioned entity fill four nodes.
+	graph, err = store.ExportGraph(ctx, storage.GraphExportOptions{MaxNodes: 4})
+	if err != nil {
+		t.Fatalf("ExportGraph() failed: %v", err)
+	}
+	if len(graph.Memories) != 3 || len(graph.Entities) != 1 || len(graph.Mentions) != 3 || len(graph.Relationships) != 0 || !graph.Truncated {
+		t.Errorf("cap: %d memories, %d entities, %d mentions, %d relationships, truncated %v; want 3, 1, 3, 0, true",
+			len(graph.Memories), len(graph.Entities), len(graph.Mentions), len(graph.Relationships), graph.Truncated)
+	}
+}
+
+// TestExportGraph_CompressedContent verifies that compressed memories are
+// exported with their original content.
+func TestExportGraph_CompressedContent(t *testing.T) {
+	store := newCompressingStore(t, 1024)
+	content := largeTranscript()
+	if err := store.Store(context.Background(), &types.Memory{ID: "mem:test:large", Content: content, Source: "test"}); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if _, compressed := storedContent(t, store, "mem:test:large"); !compressed {
+		t.Fatal("expected the memory to be stored compressed")
+	}
+
+	graph, err := store.ExportGraph(context.Background(), storage.GraphExportOptions{MaxNodes: 10})
+	if err != nil {
+		t.Fatalf("ExportGraph: %v", err)
+	}
+	if len(graph.Memories) != 1 || graph.Memories[0].Content != content {
+		t.Errorf("ExportGraph content was not decompressed: %d memories", len(graph.Memories))
+	}
+}
@@ -4,40 +4,44 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"sort"
+	"slices"
 
 	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/pkg/types"
 )
 
 // Traverse performs a multi-hop BFS through the entity relationship graph
-// starting from startMemoryID and returns up to limit connected memories
-// reachable within maxHops.
+// starting from startMemoryID and returns up to opts.Limit connected memories
+// reachable within opts.MaxHops.
 //
 // Algorithm:
 //  1. Look up entities for startMemoryID via memory_entities.
 //     These seed entities form the hop-0 frontier.
 //  2. BFS loop (hop = 1..maxHops):
-//     a. Find memories connected to the current frontier entities.
-//        These memories are at distance `hop` from the start.
+//     a. Find memories connected to the current frontier entities, at most
+//     opts.MaxBreadth per entity, highest decay score first.
+//     These memories are at distance `hop` from the start.
 //     b. Expand the frontier: query relationships from frontier entities
-//        to obtain their neighbours (new, unvisited entities).
-//        The neighbours become the frontier for the next iteration.
+//     to obtain their neighbours (new, unvisited entities), following
+//     only the relationship types and direction allowed by opts.
+//     The neighbours become the frontier for the next iteration, each
+//     remembering the relationships followed to reach it, which the
+//     memories found through it report as Relationships.
 //  3. Fetch Memory objects for all discovered memory IDs.
-//  4. Return sorted by hopDistance ASC, then DecayScore DESC.
+//  4. Score each memory from its hop distance, shared entity count and
+//     decay score (weighted by opts.Weights) and return by score DESC.
 //
 // Cycle detection: visitedEntities prevents re-visiting the same entity,
-// and seenMemories prevents the same memory from appearing more than once.
-func (s *MemoryStore) Traverse(ctx context.Context, startMemoryID string, maxHops int, limit int) ([]storage.TraversalResult, error) {
+// and foundMemories keeps each memory at the first hop it was reached.
+//
+// Soft-deleted memories are hidden: they are never returned, and a deleted
+// start memory yields no results. Restoring a memory brings it back.
+func (s *MemoryStore) Traverse(ctx context.Context, startMemoryID string, opts storage.TraversalOptions) ([]storage.TraversalResult, error) {
 	if startMemoryID == "" {
 		return nil, fmt.Errorf("sqlite: Traverse: startMemoryID is required")
 	}
-	if maxHops < 1 {
-		maxHops = 2
-	}
-	if limit < 1 {
-		limit = 10
-	}
+	opts.Normalize()
+	maxHops, limit := opts.MaxHops, opts.Limit
 
 	db := s.GetDB()
 
@@ -60,11 +64,13 @@ func (s *MemoryStore) Traverse(ctx context.Context, startMemoryID string, maxHop
 	type discovered struct {
 		hop   int
 		names []string
+		edges []storage.TraversalEdge
 	}
 	foundMemories := make(map[string]discovered)
 
-	// Track the start memory so we never include it in results.
-	seenMemories := map[string]bool{startMemoryID: true}
+	// entityPaths holds the relationships followed to reach each entity
+	// discovered by expansion; seed entities have none.
+	entityPaths := make(map[string][]storage.TraversalEdge)
 
 	// entityNameCache maps entity IDs to their display names.
 	// Pre-populate with names of the seed entities.
@@ -85,7 +91,7 @@ func (s *MemoryStore) Traverse(ctx context.Context, startMemoryID string, maxHop
 		// 2a. Discover memories connected to the current frontier entities.
 		//     These memories are reachable in exactly `hop` steps.
 		for _, eid := range frontier {
-			memIDs, err := s.getMemoryIDsForEntity(ctx, db, eid)
+			memIDs, err := s.getMemoryIDsForEntity(ctx, db, eid, startMemoryID, opts.MaxBreadth)
 			if err != nil {
 				return nil, fmt.Errorf("sqlite: Traverse hop %d entity %s: %w", hop, eid, err)
 			}
@@ -95,22 +101,23 @@ func (s *MemoryStore) Traverse(ctx context.Context, startMemoryID string, maxHop
 				name = eid // fallback to ID
 			}
 			for _, mid := range memIDs {
-				if seenMemories[mid] {
+				// Skip the start memory and memories already reached at an
+				// earlier hop; a memory reached through several frontier
+				// entities at this hop collects all their names.
+				existing, ok := foundMemories[mid]
+				if mid == startMemoryID || (ok && existing.hop != hop) {
 					continue
 				}
-				seenMemories[mid] = true
-				existing := foundMemories[mid]
-				if existing.hop == 0 {
-					existing.hop = hop
-				}
+				existing.hop = hop
 				existing.names = append(existing.names, name)
+				existing.edges = append(existing.edges, entityPaths[eid]...)
 				foundMemories[mid] = existing
 			}
 		}
 
 		// 2b. Expand frontier: find entities reachable via relationships from
-		//     the current frontier (both directions). These become the next frontier.
-		neighbourEntities, entityNames, err := s.getNeighbourEntities(ctx, db, frontier, visitedEntities)
+		//     the current frontier. These become the next frontier.
+		neighbourEntities, entityNames, steps, err := s.getNeighbourEntities(ctx, db, frontier, visitedEntities, &opts)
 		if err != nil {
 			return nil, fmt.Errorf("sqlite: Traverse hop %d expand: %w", hop, err)
 		}
@@ -120,6 +127,11 @@ func (s *MemoryStore) Traverse(ctx context.Context, startMemoryID string, maxHop
 			entityNameCache[id] = name
 		}
 
+		// Extend the path of the entity each neighbour was reached from.
+		for id, step := range steps {
+			entityPaths[id] = append(slices.Clone(entityPaths[step.from]), step.edge)
+		}
+
 		// Mark newly found entities as visited.
 		for _, eid := range neighbourEntities {
 			visitedEntities[eid] = true
@@ -153,16 +165,11 @@ func (s *MemoryStore) Traverse(ctx context.Context, startMemoryID string, maxHop
 			Memory:         &memCopy,
 			HopDistance:    d.hop,
 			SharedEntities: uniqueStrings(d.names),
+			Relationships:  uniqueEdges(d.edges),
 		})
 	}
 
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].HopDistance != results[j].HopDistance {
-			return results[i].HopDistance < results[j].HopDistance
-		}
-		// Higher decay score is "more important".
-		return results[i].Memory.DecayScore > results[j].Memory.DecayScore
-	})
+	storage.RankTraversalResults(results, opts.Weights)
 
 	if len(results) > limit {
 		results = results[:limit]
@@ -241,10 +248,14 @@ func (s *MemoryStore) getEntityNamesByIDs(ctx context.Context, db *sql.DB, ids [
 	return result, rows.Err()
 }
 
-// getEntityIDsForMemory returns all entity IDs linked to the given memory.
+// getEntityIDsForMemory returns all entity IDs linked to the given memory,
+// none if it is soft-deleted.
 func (s *MemoryStore) getEntityIDsForMemory(ctx context.Context, db *sql.DB, memoryID string) ([]string, error) {
-	rows, err := db.QueryContext(ctx,
-		`SELECT entity_id FROM memory_entities WHERE memory_id = ?`, memoryID)
+	rows, err := db.QueryContext(ctx, `
+		SELECT me.entity_id
+		FROM memory_entities me
+		JOIN memories m ON m.id = me.memory_id
+		WHERE me.memory_id = ? AND m.deleted_at IS NULL`, memoryID)
 	if err != nil {
 		return nil, err
 	}
@@ -262,12 +273,14 @@ func (s *MemoryStore) getEntityIDsForMemory(ctx context.Context, db *sql.DB, mem
 }
 
 // getNeighbourEntities returns entity IDs reachable from the given frontier
-// entities via the relationships table (both directions), excluding already-
-// visited entity IDs.
+// entities via the relationships table, excluding already-visited entity IDs.
+// Relationships are followed in both directions unless opts.Directed is set,
+// and only when their type passes opts.Follows. The returned steps record,
+// per neighbour, the frontier entity and relationship it was reached by.
 // It also returns a name map so callers can track which entity was the bridge.
-func (s *MemoryStore) getNeighbourEntities(ctx context.Context, db *sql.DB, frontier []string, visited map[string]bool) ([]string, map[string]string, error) {
+func (s *MemoryStore) getNeighbourEntities(ctx context.Context, db *sql.DB, frontier []string, visited map[string]bool, opts *storage.TraversalOptions) ([]string, map[string]string, map[string]traversalStep, error) {
 	if len(frontier) == 0 {
-		return nil, nil, nil
+		return nil, nil, nil, nil
 	}
 
 	// Build placeholder list for IN clause.
@@ -279,7 +292,7 @@ func (s *MemoryStore) getNeighbourEntities(ctx context.Context, db *sql.DB, fron
 
 	// Query relationships in both directions (source→target and target→source).
 	query := fmt.Sprintf(`
-		SELECT r.source_id, r.target_id,
+		SELECT r.source_id, r.target_id, r.type,
 		       COALESCE(e_src.name, r.source_id) AS source_name,
 		       COALESCE(e_tgt.name, r.target_id) AS target_name
 		FROM relationships r
@@ -293,7 +306,7 @@ func (s *MemoryStore) getNeighbourEntities(ctx context.Context, db *sql.DB, fron
 
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
@@ -304,35 +317,51 @@ func (s *MemoryStore) getNeighbourEntities(ctx context.Context, db *sql.DB, fron
 	}
 
 	newEntities := make(map[string]string) // entityID → name (bridge entity name)
+	steps := make(map[string]traversalStep)
 	for rows.Next() {
-		var srcID, tgtID, srcName, tgtName string
-		if err := rows.Scan(&srcID, &tgtID, &srcName, &tgtName); err != nil {
-			return nil, nil, err
+		var srcID, tgtID, relType, srcName, tgtName string
+		if err := rows.Scan(&srcID, &tgtID, &relType, &srcName, &tgtName); err != nil {
+			return nil, nil, nil, err
+		}
+		if !opts.Follows(relType) {
+			continue
 		}
 
-		// If source is in frontier, add target as neighbour and vice versa.
+		// If source is in frontier, add target as neighbour and vice versa
+		// (the reverse edge only for undirected traversal).
+		edge := storage.TraversalEdge{Type: relType, Source: srcName, Target: tgtName}
 		if frontierSet[srcID] && !visited[tgtID] {
 			newEntities[tgtID] = srcName
+			edge.Direction = storage.TraversalOutgoing
+			steps[tgtID] = traversalStep{from: srcID, edge: edge}
 		}
-		if frontierSet[tgtID] && !visited[srcID] {
+		if !opts.Directed && frontierSet[tgtID] && !visited[srcID] {
 			newEntities[srcID] = tgtName
+			edge.Direction = storage.TraversalIncoming
+			steps[srcID] = traversalStep{from: tgtID, edge: edge}
 		}
 	}
 	if err := rows.Err(); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	ids := make([]string, 0, len(newEntities))
 	for id := range newEntities {
 		ids = append(ids, id)
 	}
-	return ids, newEntities, nil
+	return ids, newEntities, steps, nil
 }
 
-// getMemoryIDsForEntity returns all memory IDs linked to the given entity.
-func (s *MemoryStore) getMemoryIDsForEntity(ctx context.Context, db *sql.DB, entityID string) ([]string, error) {
-	rows, err := db.QueryContext(ctx,
-		`SELECT memory_id FROM memory_entities WHERE entity_id = ?`, entityID)
+// getMemoryIDsForEntity returns the IDs of up to limit live memories linked
+// to the given entity, other than excludeID, highest decay score first.
+func (s *MemoryStore) getMemoryIDsForEntity(ctx context.Context, db *sql.DB, entityID, excludeID string, limit int) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT me.memory_id
+		FROM memory_entities me
+		JOIN memories m ON m.id = me.memory_id
+		WHERE me.entity_id = ? AND me.memory_id != ? AND m.deleted_at IS NULL
+		ORDER BY m.decay_score DESC, me.memory_id
+		LIMIT ?`, entityID, excludeID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -373,7 +402,8 @@ func (s *MemoryStore) getMemoriesByIDs(ctx context.Context, ids []string) ([]typ
 			classification_status, summarization_status,
 			state, state_updated_at,
 			created_by, session_id, source_context,
-			access_count, last_accessed_at, decay_score, decay_updated_at, deleted_at, content_hash, supersedes_id
+			access_count, last_accessed_at, decay_score, decay_updated_at, deleted_at, content_hash, supersedes_id,
+			content_compressed, expires_at, author_type
 		FROM memories
 		WHERE id IN (%s) AND deleted_at IS NULL
 	`, inClause)
@@ -399,6 +429,9 @@ func (s *MemoryStore) getMemoriesByIDs(ctx context.Context, ids []string) ([]typ
 			decayUpdatedAt, deletedAt             sql.NullTime
 			classificationStatus                  sql.NullString
 			summarizationStatus                   sql.NullString
+			compressed                            bool
+			expiresAt                             sql.NullTime
+			authorType                            sql.NullString
 		)
 
 		if err := rows.Scan(
@@ -412,10 +445,16 @@ func (s *MemoryStore) getMemoriesByIDs(ctx context.Context, ids []string) ([]typ
 			&state, &stateUpdatedAt,
 			&createdBy, &sessionID, &sourceContextJSON,
 			&mem.AccessCount, &lastAccessedAt, &mem.DecayScore, &decayUpdatedAt, &deletedAt, &contentHash, &supersedesID,
+			&compressed, &expiresAt, &authorType,
 		); err != nil {
 			return nil, err
 		}
 
+		var err error
+		if mem.Content, err = DecodeContent(mem.Content, compressed); err != nil {
+			return nil, err
+		}
+
 		if domain.Valid {
 			mem.Domain = domain.String
 		}
@@ -469,6 +508,13 @@ func (s *MemoryStore) getMemoriesByIDs(ctx context.Context, ids []string) ([]typ
 		if supersedesID.Valid {
 			mem.SupersedesID = supersedesID.String
 		}
+		if expiresAt.Valid {
+			t := expiresAt.Time
+			mem.ExpiresAt = &t
+		}
+		if authorType.Valid {
+			mem.AuthorType = authorType.String
+		}
 
 		memories = append(memories, mem)
 	}
@@ -503,3 +549,22 @@ func uniqueStrings(ss []string) []string {
 	return out
 }
 
+// traversalStep is how Traverse reached an entity: the frontier entity it
+// came from and the relationship it followed.
+type traversalStep struct {
+	from string
+	edge storage.TraversalEdge
+}
+
+// uniqueEdges deduplicates traversal edges while preserving order.
+func uniqueEdges(edges []storage.TraversalEdge) []storage.TraversalEdge {
+	seen := make(map[storage.TraversalEdge]bool, len(edges))
+	var out []storage.TraversalEdge
+	for _, e := range edges {
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	return out
+}
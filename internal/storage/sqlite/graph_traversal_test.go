@@ -2,9 +2,12 @@ package sqlite
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/pkg/types"
 )
 
@@ -74,7 +77,7 @@ func TestTraverse_NoEntities(t *testing.T) {
 
 	storeTestMemory(t, s, "mem:test:a", "Memory A with no entities")
 
-	results, err := s.Traverse(ctx, "mem:test:a", 2, 10)
+	results, err := s.Traverse(ctx, "mem:test:a", storage.TraversalOptions{MaxHops: 2, Limit: 10})
 	if err != nil {
 		t.Fatalf("Traverse() unexpected error: %v", err)
 	}
@@ -104,7 +107,7 @@ func TestTraverse_OneHop(t *testing.T) {
 	linkMemoryEntity(t, s, "mem:test:b", "ent:test-e1")
 	linkMemoryEntity(t, s, "mem:test:c", "ent:test-e2") // not connected to A
 
-	results, err := s.Traverse(ctx, "mem:test:a", 1, 10)
+	results, err := s.Traverse(ctx, "mem:test:a", storage.TraversalOptions{MaxHops: 1, Limit: 10})
 	if err != nil {
 		t.Fatalf("Traverse() error: %v", err)
 	}
@@ -145,7 +148,7 @@ func TestTraverse_TwoHops(t *testing.T) {
 	insertRelationship(t, s, "rel:test-r1", "ent:test-e1", "ent:test-e2", "knows")
 
 	// maxHops=1 should NOT find memC (requires 2 hops).
-	results, err := s.Traverse(ctx, "mem:test:a", 1, 10)
+	results, err := s.Traverse(ctx, "mem:test:a", storage.TraversalOptions{MaxHops: 1, Limit: 10})
 	if err != nil {
 		t.Fatalf("Traverse(maxHops=1) error: %v", err)
 	}
@@ -156,7 +159,7 @@ func TestTraverse_TwoHops(t *testing.T) {
 	}
 
 	// maxHops=2 should find memC at hop distance 2.
-	results2, err := s.Traverse(ctx, "mem:test:a", 2, 10)
+	results2, err := s.Traverse(ctx, "mem:test:a", storage.TraversalOptions{MaxHops: 2, Limit: 10})
 	if err != nil {
 		t.Fatalf("Traverse(maxHops=2) error: %v", err)
 	}
@@ -199,7 +202,7 @@ func TestTraverse_CycleDetection(t *testing.T) {
 	insertRelationship(t, s, "rel:test-cycle-r2", "ent:test-cycle-e2", "ent:test-cycle-e1", "knows_back")
 
 	// Should complete without panic, timeout, or error, and return memB exactly once.
-	results, err := s.Traverse(ctx, "mem:test:cycle-a", 4, 50)
+	results, err := s.Traverse(ctx, "mem:test:cycle-a", storage.TraversalOptions{MaxHops: 4, Limit: 50})
 	if err != nil {
 		t.Fatalf("Traverse() cycle error: %v", err)
 	}
@@ -222,6 +225,235 @@ func TestTraverse_CycleDetection(t *testing.T) {
 	}
 }
 
+// TestTraverse_RelationshipFilters sets up:
+//
+//	memA ─── E1 ─ CAUSES ──→ E2 ─── memB
+//	         E1 ─ CONTAINS ─→ E3 ─── memC
+//	         E1 ←─ BLOCKS ─── E4 ─── memD
+//
+// and checks the include, exclude and directed options against it.
+func TestTraverse_RelationshipFilters(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		storeTestMemory(t, s, "mem:test:"+id, "Memory "+id)
+	}
+	for _, id := range []string{"e1", "e2", "e3", "e4"} {
+		insertEntity(t, s, "ent:test-"+id, "Filter"+id, "concept")
+	}
+	linkMemoryEntity(t, s, "mem:test:a", "ent:test-e1")
+	linkMemoryEntity(t, s, "mem:test:b", "ent:test-e2")
+	linkMemoryEntity(t, s, "mem:test:c", "ent:test-e3")
+	linkMemoryEntity(t, s, "mem:test:d", "ent:test-e4")
+	insertRelationship(t, s, "rel:test-causes", "ent:test-e1", "ent:test-e2", "CAUSES")
+	insertRelationship(t, s, "rel:test-contains", "ent:test-e1", "ent:test-e3", "CONTAINS")
+	insertRelationship(t, s, "rel:test-blocks", "ent:test-e4", "ent:test-e1", "BLOCKS")
+
+	tests := []struct {
+		name string
+		opts storage.TraversalOptions
+		want []string
+	}{
+		{"all", storage.TraversalOptions{}, []string{"mem:test:b", "mem:test:c", "mem:test:d"}},
+		{"include", storage.TraversalOptions{RelationshipTypes: []string{"causes", "BLOCKS"}}, []string{"mem:test:b", "mem:test:d"}},
+		{"exclude", storage.TraversalOptions{ExcludeTypes: []string{"CONTAINS"}}, []string{"mem:test:b", "mem:test:d"}},
+		{"include and exclude", storage.TraversalOptions{RelationshipTypes: []string{"CAUSES", "CONTAINS"}, ExcludeTypes: []string{"contains"}}, []string{"mem:test:b"}},
+		{"directed", storage.TraversalOptions{Directed: true}, []string{"mem:test:b", "mem:test:c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := s.Traverse(ctx, "mem:test:a", tt.opts)
+			if err != nil {
+				t.Fatalf("Traverse() error: %v", err)
+			}
+			got := make(map[string]bool, len(results))
+			for _, r := range results {
+				got[r.Memory.ID] = true
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d results %v, want %v", len(got), got, tt.want)
+			}
+			for _, id := range tt.want {
+				if !got[id] {
+					t.Errorf("expected %s in results, got %v", id, got)
+				}
+			}
+		})
+	}
+}
+
+// TestTraverse_RelationshipEdges sets up:
+//
+//	memA ── Alice
+//	memB ── Alice                       (hop 1, no relationship)
+//	memC ── Acme   via Alice WORKS_AT Acme      (outgoing)
+//	memD ── Memo   via Memo MENTIONS Alice      (incoming)
+//	memE ── Berlin via Acme LOCATED_IN Berlin   (hop 3, path of two)
+func TestTraverse_RelationshipEdges(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		storeTestMemory(t, s, "mem:test:"+id, "Memory "+id)
+	}
+	insertEntity(t, s, "ent:alice", "Alice", "person")
+	insertEntity(t, s, "ent:acme", "Acme", "organization")
+	insertEntity(t, s, "ent:memo", "Memo", "document")
+	insertEntity(t, s, "ent:berlin", "Berlin", "location")
+	linkMemoryEntity(t, s, "mem:test:a", "ent:alice")
+	linkMemoryEntity(t, s, "mem:test:b", "ent:alice")
+	linkMemoryEntity(t, s, "mem:test:c", "ent:acme")
+	linkMemoryEntity(t, s, "mem:test:d", "ent:memo")
+	linkMemoryEntity(t, s, "mem:test:e", "ent:berlin")
+	insertRelationship(t, s, "rel:works-at", "ent:alice", "ent:acme", "WORKS_AT")
+	insertRelationship(t, s, "rel:mentions", "ent:memo", "ent:alice", "MENTIONS")
+	insertRelationship(t, s, "rel:located-in", "ent:acme", "ent:berlin", "LOCATED_IN")
+
+	results, err := s.Traverse(ctx, "mem:test:a", storage.TraversalOptions{MaxHops: 3})
+	if err != nil {
+		t.Fatalf("Traverse() error: %v", err)
+	}
+	got := make(map[string][]storage.TraversalEdge, len(results))
+	for _, r := range results {
+		got[r.Memory.ID] = r.Relationships
+	}
+
+	worksAt := storage.TraversalEdge{Type: "WORKS_AT", Direction: storage.TraversalOutgoing, Source: "Alice", Target: "Acme"}
+	want := map[string][]storage.TraversalEdge{
+		"mem:test:b": nil,
+		"mem:test:c": {worksAt},
+		"mem:test:d": {{Type: "MENTIONS", Direction: storage.TraversalIncoming, Source: "Memo", Target: "Alice"}},
+		"mem:test:e": {worksAt, {Type: "LOCATED_IN", Direction: storage.TraversalOutgoing, Source: "Acme", Target: "Berlin"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got results for %d memories, want %d: %v", len(got), len(want), got)
+	}
+	for id, edges := range want {
+		if !reflect.DeepEqual(got[id], edges) {
+			t.Errorf("%s relationships = %+v, want %+v", id, got[id], edges)
+		}
+	}
+}
+
+// TestTraverse_RelevanceScore sets up:
+//
+//	memA ── E1,E2,E3
+//	memB ── E1                              (hop 1, 1 shared)
+//	memD ── E1,E2,E3                        (hop 1, 3 shared)
+//	memC ── E4,E5,E6 via E1→E4, E2→E5, E3→E6 (hop 2, 3 shared)
+//
+// With the default weights proximity dominates (D, B, C); weighting only
+// overlap puts the hop-2 memory ahead of the single-entity hop-1 one.
+func TestTraverse_RelevanceScore(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		storeTestMemory(t, s, "mem:test:"+id, "Memory "+id)
+	}
+	for _, id := range []string{"e1", "e2", "e3", "e4", "e5", "e6"} {
+		insertEntity(t, s, "ent:test-"+id, "Score"+id, "concept")
+	}
+	for _, id := range []string{"e1", "e2", "e3"} {
+		linkMemoryEntity(t, s, "mem:test:a", "ent:test-"+id)
+		linkMemoryEntity(t, s, "mem:test:d", "ent:test-"+id)
+	}
+	linkMemoryEntity(t, s, "mem:test:b", "ent:test-e1")
+	for _, id := range []string{"e4", "e5", "e6"} {
+		linkMemoryEntity(t, s, "mem:test:c", "ent:test-"+id)
+	}
+	insertRelationship(t, s, "rel:test-14", "ent:test-e1", "ent:test-e4", "related_to")
+	insertRelationship(t, s, "rel:test-25", "ent:test-e2", "ent:test-e5", "related_to")
+	insertRelationship(t, s, "rel:test-36", "ent:test-e3", "ent:test-e6", "related_to")
+
+	order := func(results []storage.TraversalResult) []string {
+		ids := make([]string, len(results))
+		for i, r := range results {
+			ids[i] = r.Memory.ID
+		}
+		return ids
+	}
+
+	results, err := s.Traverse(ctx, "mem:test:a", storage.TraversalOptions{})
+	if err != nil {
+		t.Fatalf("Traverse() error: %v", err)
+	}
+	want := []string{"mem:test:d", "mem:test:b", "mem:test:c"}
+	if got := order(results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("default order = %v, want %v", got, want)
+	}
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Memory.ID] = r.SharedEntityCount
+		if r.Score <= 0 || r.Score > 1 {
+			t.Errorf("%s: score %v outside (0, 1]", r.Memory.ID, r.Score)
+		}
+	}
+	if counts["mem:test:d"] != 3 || counts["mem:test:b"] != 1 || counts["mem:test:c"] != 3 {
+		t.Errorf("shared entity counts = %v, want d:3 b:1 c:3", counts)
+	}
+
+	results, err = s.Traverse(ctx, "mem:test:a", storage.TraversalOptions{
+		Weights: storage.TraversalWeights{Overlap: 1},
+	})
+	if err != nil {
+		t.Fatalf("Traverse(overlap) error: %v", err)
+	}
+	want = []string{"mem:test:d", "mem:test:c", "mem:test:b"}
+	if got := order(results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("overlap-weighted order = %v, want %v", got, want)
+	}
+}
+
+// TestTraverse_HubEntityBreadth links one hub entity to many memories and
+// checks that a hop only expands the MaxBreadth memories with the highest
+// decay score.
+func TestTraverse_HubEntityBreadth(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	storeTestMemory(t, s, "mem:test:start", "Memory about the company")
+	insertEntity(t, s, "ent:test-hub", "the company", "organization")
+	linkMemoryEntity(t, s, "mem:test:start", "ent:test-hub")
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("mem:test:hub-%02d", i)
+		mem := &types.Memory{
+			ID:         id,
+			Content:    "Company memory " + id,
+			Source:     "test",
+			Status:     types.StatusEnriched,
+			DecayScore: float64(i) / 20,
+		}
+		if err := s.Store(ctx, mem); err != nil {
+			t.Fatalf("Store(%q): %v", id, err)
+		}
+		linkMemoryEntity(t, s, id, "ent:test-hub")
+	}
+
+	results, err := s.Traverse(ctx, "mem:test:start", storage.TraversalOptions{MaxHops: 1, Limit: 50, MaxBreadth: 3})
+	if err != nil {
+		t.Fatalf("Traverse() error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3 (bounded by MaxBreadth)", len(results))
+	}
+	want := map[string]bool{"mem:test:hub-19": true, "mem:test:hub-18": true, "mem:test:hub-17": true}
+	for _, r := range results {
+		if !want[r.Memory.ID] {
+			t.Errorf("unexpected result %s (decay %.2f); want the highest-decay memories", r.Memory.ID, r.Memory.DecayScore)
+		}
+	}
+
+	results, err = s.Traverse(ctx, "mem:test:start", storage.TraversalOptions{MaxHops: 1, Limit: 50})
+	if err != nil {
+		t.Fatalf("Traverse(default breadth) error: %v", err)
+	}
+	if len(results) != 20 {
+		t.Errorf("got %d results with the default breadth, want 20", len(results))
+	}
+}
+
 // TestGetMemoryEntities verifies that the entities linked to a memory are
 // returned correctly by GetMemoryEntities.
 func TestGetMemoryEntities(t *testing.T) {
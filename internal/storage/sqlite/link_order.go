@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// insertLinkSQL inserts the link (id, source_id, target_id, type) after the
+// source's existing links of that type. A link that already exists is left
+// unchanged.
+const insertLinkSQL = `
+	INSERT OR IGNORE INTO memory_links (id, source_id, target_id, type, position)
+	SELECT ?1, ?2, ?3, ?4, COALESCE(MAX(position) + 1, 0)
+	FROM memory_links WHERE source_id = ?2 AND type = ?4`
+
+// upgradeLinkPosition adds the position column to memory_links tables
+// created before links were ordered. Existing links keep position 0 and
+// fall back to creation order among themselves.
+func upgradeLinkPosition(db *sql.DB) error {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM pragma_table_info('memory_links') WHERE name = 'position'`,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to inspect memory_links table: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+	_, err = db.Exec(`ALTER TABLE memory_links ADD COLUMN position INTEGER NOT NULL DEFAULT 0`)
+	return err
+}
+
+// SetMemoryLinkOrder sets the order of the sourceID → target links of the
+// given type to the order of targetIDs, in one transaction. Links to targets
+// not listed keep their position. Returns storage.ErrNotFound if any target
+// is not linked from sourceID.
+func (s *MemoryStore) SetMemoryLinkOrder(ctx context.Context, sourceID, linkType string, targetIDs []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite: SetMemoryLinkOrder: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i, targetID := range targetIDs {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE memory_links SET position = ? WHERE source_id = ? AND target_id = ? AND type = ?`,
+			i, sourceID, targetID, linkType,
+		)
+		if err != nil {
+			return fmt.Errorf("sqlite: SetMemoryLinkOrder: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("sqlite: SetMemoryLinkOrder: %w", err)
+		} else if n == 0 {
+			return fmt.Errorf("%w: no %s link from %s to %s", storage.ErrNotFound, linkType, sourceID, targetID)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// linkedIDs returns the IDs of the CONTAINS children of sourceID.
+func linkedIDs(t *testing.T, store *MemoryStore, sourceID string) []string {
+	t.Helper()
+	children, err := store.GetMemoriesByRelationType(context.Background(), sourceID, "CONTAINS")
+	if err != nil {
+		t.Fatalf("GetMemoriesByRelationType() failed: %v", err)
+	}
+	ids := []string{}
+	for _, m := range children {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestMemoryLinkOrder(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"mem:test:parent", "mem:test:other", "mem:test:a", "mem:test:b", "mem:test:c"} {
+		mustStore(t, store, &types.Memory{ID: id, Content: "content of " + id, Source: "test"})
+	}
+	for _, target := range []string{"mem:test:c", "mem:test:a", "mem:test:b"} {
+		if err := store.CreateMemoryLink(ctx, "link:"+target, "mem:test:parent", target, "CONTAINS"); err != nil {
+			t.Fatalf("CreateMemoryLink() failed: %v", err)
+		}
+	}
+	if got, want := linkedIDs(t, store, "mem:test:parent"), []string{"mem:test:c", "mem:test:a", "mem:test:b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("links not in creation order: got %v, want %v", got, want)
+	}
+
+	want := []string{"mem:test:a", "mem:test:b", "mem:test:c"}
+	if err := store.SetMemoryLinkOrder(ctx, "mem:test:parent", "CONTAINS", want); err != nil {
+		t.Fatalf("SetMemoryLinkOrder() failed: %v", err)
+	}
+	if got := linkedIDs(t, store, "mem:test:parent"); !reflect.DeepEqual(got, want) {
+		t.Errorf("after SetMemoryLinkOrder: got %v, want %v", got, want)
+	}
+
+	// An unlinked target fails the whole reorder.
+	err := store.SetMemoryLinkOrder(ctx, "mem:test:parent", "CONTAINS", []string{"mem:test:c", "mem:test:other"})
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("SetMemoryLinkOrder() with an unlinked target = %v, want ErrNotFound", err)
+	}
+	if got := linkedIDs(t, store, "mem:test:parent"); !reflect.DeepEqual(got, want) {
+		t.Errorf("failed reorder changed the order: got %v, want %v", got, want)
+	}
+
+	// A moved link goes after the new parent's existing links.
+	if err := store.CreateMemoryLink(ctx, "link:other", "mem:test:parent", "mem:test:other", "CONTAINS"); err != nil {
+		t.Fatalf("CreateMemoryLink() failed: %v", err)
+	}
+	if err := store.MoveMemoryLink(ctx, "link:moved", "mem:test:parent", "mem:test:other", "mem:test:a", "CONTAINS"); err != nil {
+		t.Fatalf("MoveMemoryLink() failed: %v", err)
+	}
+	if err := store.CreateMemoryLink(ctx, "link:a2", "mem:test:parent", "mem:test:a", "CONTAINS"); err != nil {
+		t.Fatalf("CreateMemoryLink() failed: %v", err)
+	}
+	if got, want := linkedIDs(t, store, "mem:test:parent"), []string{"mem:test:b", "mem:test:c", "mem:test:other", "mem:test:a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after move and relink: got %v, want %v", got, want)
+	}
+}
+
+// TestMemoryLinkOrder_UpgradesExistingDatabase verifies that opening a
+// database created before links were ordered adds the position column.
+func TestMemoryLinkOrder_UpgradesExistingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	legacy, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := legacy.Exec(Schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	if _, err := legacy.Exec(`ALTER TABLE memory_links DROP COLUMN position`); err != nil {
+		t.Fatalf("failed to build legacy schema: %v", err)
+	}
+	_ = legacy.Close()
+
+	store, err := NewMemoryStore(dbPath)
+	if err != nil {
+		t.Fatalf("NewMemoryStore() on legacy database failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:parent", Content: "parent", Source: "test"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:child", Content: "child", Source: "test"})
+	if err := store.CreateMemoryLink(context.Background(), "link:child", "mem:test:parent", "mem:test:child", "CONTAINS"); err != nil {
+		t.Fatalf("CreateMemoryLink() after upgrade failed: %v", err)
+	}
+	if got := linkedIDs(t, store, "mem:test:parent"); !reflect.DeepEqual(got, []string{"mem:test:child"}) {
+		t.Errorf("links after upgrade = %v, want [mem:test:child]", got)
+	}
+}
@@ -2,11 +2,10 @@ package sqlite
 
 import (
 	"context"
-	"crypto/sha256"
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/url"
 	"os"
 	"os/exec"
@@ -15,6 +14,7 @@ import (
 
 	_ "modernc.org/sqlite" // SQLite driver
 
+	"github.com/scrypster/memento/internal/config"
 	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/pkg/types"
 )
@@ -39,14 +39,118 @@ func (s *MemoryStore) RunMigrations(migrationsDir string) error {
 // MemoryStore implements storage.MemoryStore using SQLite.
 type MemoryStore struct {
 	db *sql.DB
+
+	// compressionThreshold is the content size in bytes at or above which
+	// content is gzip-compressed on write. Zero disables compression.
+	compressionThreshold int
+
+	// Connection PRAGMAs applied when the store is opened.
+	busyTimeout       time.Duration
+	journalMode       string
+	walAutocheckpoint int
+
+	// stateMachine validates lifecycle state changes; nil is the built-in
+	// machine (see WithStateMachine).
+	stateMachine *types.StateMachine
+
+	// dedupNormalization is how content is normalized before hashing it
+	// into content_hash (see WithDedupNormalization).
+	dedupNormalization string
+}
+
+// Default connection PRAGMAs. WAL lets readers proceed while a writer holds
+// the lock; the busy timeout makes writers wait for the lock instead of
+// failing immediately with "database is locked"; 1000 pages is SQLite's own
+// auto-checkpoint default.
+const (
+	DefaultBusyTimeout       = 5 * time.Second
+	DefaultJournalMode       = "WAL"
+	DefaultWALAutocheckpoint = 1000
+)
+
+// validJournalModes lists the values accepted by PRAGMA journal_mode.
+var validJournalModes = map[string]bool{
+	"DELETE": true, "TRUNCATE": true, "PERSIST": true,
+	"MEMORY": true, "WAL": true, "OFF": true,
+}
+
+// Option configures optional MemoryStore behaviour.
+type Option func(*MemoryStore)
+
+// WithCompressionThreshold enables transparent gzip compression of memory
+// content that is at least threshold bytes long. Compressed rows are flagged
+// with content_compressed and decompressed on read; full-text search still
+// indexes the uncompressed text. A threshold <= 0 disables compression.
+func WithCompressionThreshold(threshold int) Option {
+	return func(s *MemoryStore) {
+		s.compressionThreshold = threshold
+	}
+}
+
+// WithBusyTimeout sets PRAGMA busy_timeout: how long a connection waits for
+// a lock held by another connection or process (e.g. memento-web and
+// memento-mcp sharing one database file) before failing with "database is
+// locked". Raising it trades latency under contention for fewer lock errors.
+func WithBusyTimeout(d time.Duration) Option {
+	return func(s *MemoryStore) {
+		s.busyTimeout = d
+	}
+}
+
+// WithJournalMode sets PRAGMA journal_mode (DELETE, TRUNCATE, PERSIST,
+// MEMORY, WAL or OFF). WAL is the default and is recommended whenever more
+// than one process opens the database.
+func WithJournalMode(mode string) Option {
+	return func(s *MemoryStore) {
+		s.journalMode = strings.ToUpper(mode)
+	}
+}
+
+// WithWALAutocheckpoint sets PRAGMA wal_autocheckpoint, the WAL size in pages
+// that triggers an automatic checkpoint. Larger values batch more writes per
+// checkpoint at the cost of a bigger -wal file; 0 disables auto-checkpoints.
+func WithWALAutocheckpoint(pages int) Option {
+	return func(s *MemoryStore) {
+		s.walAutocheckpoint = pages
+	}
+}
+
+// WithDedupNormalization sets how Store normalizes content before hashing
+// it into content_hash: one of the config.Dedup* levels (see
+// storage.NormalizeForDedup). The content itself is stored unchanged, and
+// memories stored earlier keep the hash they were stored with.
+func WithDedupNormalization(level string) Option {
+	return func(s *MemoryStore) {
+		s.dedupNormalization = level
+	}
+}
+
+// OptionsFromConfig translates the SQLite-related settings of cfg (PRAGMAs
+// and content compression) into store options for NewMemoryStore.
+func OptionsFromConfig(cfg config.StorageConfig) []Option {
+	opts := []Option{
+		WithBusyTimeout(time.Duration(cfg.SQLiteBusyTimeoutMs) * time.Millisecond),
+		WithJournalMode(cfg.SQLiteJournalMode),
+		WithWALAutocheckpoint(cfg.SQLiteWALAutocheckpoint),
+	}
+	if cfg.CompressContent {
+		opts = append(opts, WithCompressionThreshold(cfg.CompressionThreshold))
+	}
+	if cfg.StateMachine != nil {
+		opts = append(opts, WithStateMachine(cfg.StateMachine))
+	}
+	if cfg.DedupNormalization != "" {
+		opts = append(opts, WithDedupNormalization(cfg.DedupNormalization))
+	}
+	return opts
 }
 
 // NewMemoryStore creates a new SQLite memory store with WAL self-healing.
 // If the initial open fails due to stale WAL files (left behind by a crashed
 // process), it verifies no other process holds them and retries once after
 // removing the stale -shm/-wal files.
-func NewMemoryStore(dsn string) (*MemoryStore, error) {
-	store, err := openMemoryStore(dsn)
+func NewMemoryStore(dsn string, opts ...Option) (*MemoryStore, error) {
+	store, err := openMemoryStore(dsn, opts...)
 	if err == nil {
 		return store, nil
 	}
@@ -66,17 +170,36 @@ func NewMemoryStore(dsn string) (*MemoryStore, error) {
 
 	removeStaleWAL(dbPath)
 
-	store, retryErr := openMemoryStore(dsn)
+	store, retryErr := openMemoryStore(dsn, opts...)
 	if retryErr != nil {
 		return nil, fmt.Errorf("failed after WAL recovery: %w (original: %v)", retryErr, err)
 	}
 
-	log.Printf("sqlite: recovered from stale WAL files for %s", dbPath)
+	slog.Info("sqlite: recovered from stale WAL files", "path", dbPath)
 	return store, nil
 }
 
-// openMemoryStore opens a SQLite database, configures WAL mode, and creates the schema.
-func openMemoryStore(dsn string) (*MemoryStore, error) {
+// openMemoryStore opens a SQLite database, applies the connection PRAGMAs
+// (WAL mode and busy timeout by default), and creates the schema.
+func openMemoryStore(dsn string, opts ...Option) (*MemoryStore, error) {
+	store := &MemoryStore{
+		busyTimeout:       DefaultBusyTimeout,
+		journalMode:       DefaultJournalMode,
+		walAutocheckpoint: DefaultWALAutocheckpoint,
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+	if !validJournalModes[store.journalMode] {
+		return nil, fmt.Errorf("%w: unsupported journal mode %q", storage.ErrInvalidInput, store.journalMode)
+	}
+	if store.busyTimeout < 0 {
+		return nil, fmt.Errorf("%w: busy timeout must be >= 0, got %v", storage.ErrInvalidInput, store.busyTimeout)
+	}
+	if err := store.stateMachine.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", storage.ErrInvalidInput, err)
+	}
+
 	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -89,15 +212,24 @@ func openMemoryStore(dsn string) (*MemoryStore, error) {
 	db.SetMaxIdleConns(1)
 	db.SetConnMaxLifetime(0) // Connections live for the lifetime of the store.
 
-	// Enable WAL mode for better read concurrency (readers don't block writers).
-	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
+	// WAL mode (the default) gives better read concurrency: readers don't
+	// block writers. journalMode is validated above, so it is safe to inline.
+	if _, err := db.Exec("PRAGMA journal_mode=" + store.journalMode); err != nil {
 		_ = db.Close()
-		return nil, fmt.Errorf("failed to enable WAL mode: %w", err)
+		return nil, fmt.Errorf("failed to set journal mode %s: %w", store.journalMode, err)
+	}
+
+	if store.journalMode == "WAL" {
+		if _, err := db.Exec(fmt.Sprintf("PRAGMA wal_autocheckpoint = %d", store.walAutocheckpoint)); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to set WAL autocheckpoint: %w", err)
+		}
 	}
 
 	// Set busy timeout so that callers wait instead of getting an immediate
-	// SQLITE_BUSY error when the connection is held by another goroutine.
-	if _, err := db.Exec("PRAGMA busy_timeout = 5000"); err != nil {
+	// SQLITE_BUSY error when another process (e.g. memento-web alongside
+	// memento-mcp) holds the write lock.
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA busy_timeout = %d", store.busyTimeout.Milliseconds())); err != nil {
 		_ = db.Close()
 		return nil, fmt.Errorf("failed to set busy timeout: %w", err)
 	}
@@ -114,7 +246,33 @@ func openMemoryStore(dsn string) (*MemoryStore, error) {
 		return nil, fmt.Errorf("failed to create schema: %w", err)
 	}
 
-	return &MemoryStore{db: db}, nil
+	if err := upgradeContentCompression(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to add content compression column: %w", err)
+	}
+
+	if err := upgradeExpiresAt(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to add expires_at column: %w", err)
+	}
+
+	if err := upgradeAuthorType(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to add author_type column: %w", err)
+	}
+
+	if err := upgradeEmbeddingsKey(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to re-key embeddings table: %w", err)
+	}
+
+	if err := upgradeLinkPosition(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to add memory_links position column: %w", err)
+	}
+
+	store.db = db
+	return store, nil
 }
 
 // maxSourceContextBytes is the maximum allowed serialized size of SourceContext (Opus Issue #9).
@@ -136,7 +294,7 @@ func (s *MemoryStore) Store(ctx context.Context, memory *types.Memory) error {
 
 	// Compute and store content hash (used for dedup at the MCP layer via
 	// deterministic ID generation; stored here for analytics/querying).
-	memory.ContentHash = fmt.Sprintf("%x", sha256.Sum256([]byte(memory.Content)))
+	memory.ContentHash = storage.ContentHash(s.dedupNormalization, memory.Content)
 
 	// Marshal metadata, tags, and key_points to JSON
 	var (
@@ -208,6 +366,11 @@ func (s *MemoryStore) Store(ctx context.Context, memory *types.Memory) error {
 		memory.EmbeddingStatus = types.EnrichmentPending
 	}
 
+	storedContent, compressed, err := s.encodeContent(memory.Content)
+	if err != nil {
+		return err
+	}
+
 	// Upsert the memory
 	query := `
 		INSERT INTO memories (
@@ -221,8 +384,8 @@ func (s *MemoryStore) Store(ctx context.Context, memory *types.Memory) error {
 			state, state_updated_at,
 			created_by, session_id, source_context,
 			access_count, last_accessed_at, decay_score, decay_updated_at, deleted_at, content_hash, supersedes_id,
-			memory_type
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			memory_type, content_compressed, expires_at, author_type
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			content = excluded.content,
 			source = excluded.source,
@@ -254,12 +417,21 @@ func (s *MemoryStore) Store(ctx context.Context, memory *types.Memory) error {
 			deleted_at = excluded.deleted_at,
 			content_hash = excluded.content_hash,
 			supersedes_id = excluded.supersedes_id,
-			memory_type = excluded.memory_type
+			memory_type = excluded.memory_type,
+			content_compressed = excluded.content_compressed,
+			expires_at = excluded.expires_at,
+			author_type = excluded.author_type
 	`
 
-	_, err = s.db.ExecContext(ctx, query,
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, query,
 		memory.ID,
-		memory.Content,
+		storedContent,
 		memory.Source,
 		memory.Domain,
 		nullableTime(&memory.Timestamp),
@@ -291,12 +463,25 @@ func (s *MemoryStore) Store(ctx context.Context, memory *types.Memory) error {
 		nullableString(memory.ContentHash),
 		nullableString(memory.SupersedesID),
 		nullableString(memory.MemoryType),
+		compressed,
+		nullableUTCTime(memory.ExpiresAt),
+		nullableString(memory.AuthorType),
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to store memory: %w", err)
 	}
 
+	if compressed {
+		if err := syncFTSContent(ctx, tx, memory.ID, memory.Content); err != nil {
+			return fmt.Errorf("failed to store memory: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to store memory: %w", err)
+	}
+
 	return nil
 }
 
@@ -318,7 +503,7 @@ func (s *MemoryStore) Get(ctx context.Context, id string) (*types.Memory, error)
 			state, state_updated_at,
 			created_by, session_id, source_context,
 			access_count, last_accessed_at, decay_score, decay_updated_at, deleted_at, content_hash, supersedes_id,
-			memory_type
+			memory_type, content_compressed, expires_at, author_type
 		FROM memories
 		WHERE id = ? AND deleted_at IS NULL
 	`
@@ -334,6 +519,9 @@ func (s *MemoryStore) Get(ctx context.Context, id string) (*types.Memory, error)
 	var sourceContextJSON sql.NullString
 	var stateUpdatedAt, lastAccessedAt, decayUpdatedAt, deletedAt sql.NullTime
 	var classificationStatus, summarizationStatus sql.NullString
+	var compressed bool
+	var expiresAt sql.NullTime
+	var authorType sql.NullString
 
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&memory.ID,
@@ -369,6 +557,9 @@ func (s *MemoryStore) Get(ctx context.Context, id string) (*types.Memory, error)
 		&contentHash,
 		&supersedesID,
 		&memoryType,
+		&compressed,
+		&expiresAt,
+		&authorType,
 	)
 
 	if err == sql.ErrNoRows {
@@ -379,6 +570,10 @@ func (s *MemoryStore) Get(ctx context.Context, id string) (*types.Memory, error)
 		return nil, fmt.Errorf("failed to get memory: %w", err)
 	}
 
+	if memory.Content, err = DecodeContent(memory.Content, compressed); err != nil {
+		return nil, err
+	}
+
 	// Unmarshal JSON fields
 	if metadataJSON.Valid && metadataJSON.String != "" {
 		if err := json.Unmarshal([]byte(metadataJSON.String), &memory.Metadata); err != nil {
@@ -482,6 +677,14 @@ func (s *MemoryStore) Get(ctx context.Context, id string) (*types.Memory, error)
 		memory.MemoryType = memoryType.String
 	}
 
+	if expiresAt.Valid {
+		t := expiresAt.Time
+		memory.ExpiresAt = &t
+	}
+	if authorType.Valid {
+		memory.AuthorType = authorType.String
+	}
+
 	return &memory, nil
 }
 
@@ -503,7 +706,7 @@ func (s *MemoryStore) List(ctx context.Context, opts storage.ListOptions) (*stor
 			state, state_updated_at,
 			created_by, session_id, source_context,
 			access_count, last_accessed_at, decay_score, decay_updated_at, deleted_at, content_hash, supersedes_id,
-			memory_type
+			memory_type, content_compressed, expires_at, author_type
 		FROM memories
 	`
 
@@ -537,6 +740,11 @@ func (s *MemoryStore) List(ctx context.Context, opts storage.ListOptions) (*stor
 		args = append(args, opts.CreatedBy)
 	}
 
+	if opts.AuthorType != "" {
+		conditions = append(conditions, "author_type = ?")
+		args = append(args, opts.AuthorType)
+	}
+
 	if !opts.CreatedAfter.IsZero() {
 		conditions = append(conditions, "created_at > ?")
 		args = append(args, opts.CreatedAfter)
@@ -547,21 +755,61 @@ func (s *MemoryStore) List(ctx context.Context, opts storage.ListOptions) (*stor
 		args = append(args, opts.CreatedBefore)
 	}
 
+	// IncrementAccessCount writes last_accessed_at in UTC, so the bounds are
+	// compared in UTC too. A NULL (never accessed) never matches.
+	if !opts.AccessedAfter.IsZero() {
+		conditions = append(conditions, "last_accessed_at > ?")
+		args = append(args, opts.AccessedAfter.UTC())
+	}
+
+	if !opts.AccessedBefore.IsZero() {
+		conditions = append(conditions, "last_accessed_at < ?")
+		args = append(args, opts.AccessedBefore.UTC())
+	}
+
 	if opts.MinDecayScore > 0 {
 		conditions = append(conditions, "decay_score >= ?")
 		args = append(args, opts.MinDecayScore)
 	}
 
+	if opts.MaxDecayScore > 0 {
+		conditions = append(conditions, "decay_score <= ?")
+		args = append(args, opts.MaxDecayScore)
+	}
+
 	if opts.SessionID != "" {
 		conditions = append(conditions, "session_id = ?")
 		args = append(args, opts.SessionID)
 	}
 
+	if len(opts.EntityIDs) > 0 {
+		conditions = append(conditions, "id IN (SELECT memory_id FROM memory_entities WHERE entity_id IN (?"+
+			strings.Repeat(", ?", len(opts.EntityIDs)-1)+"))")
+		for _, id := range opts.EntityIDs {
+			args = append(args, id)
+		}
+	}
+
+	metadataCond, metadataArgs, err := metadataFilter("", opts.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if metadataCond != "" {
+		conditions = append(conditions, strings.TrimPrefix(metadataCond, " AND "))
+		args = append(args, metadataArgs...)
+	}
+
 	// Exclude soft-deleted memories unless explicitly requested
 	if !opts.IncludeDeleted {
 		conditions = append(conditions, "deleted_at IS NULL")
 	}
 
+	// Exclude expired memories unless explicitly requested
+	if !opts.IncludeExpired {
+		conditions = append(conditions, notExpiredCondition(""))
+		args = append(args, nowUTC())
+	}
+
 	// When OnlyDeleted is set, restrict to soft-deleted rows only.
 	if opts.OnlyDeleted {
 		conditions = append(conditions, "deleted_at IS NOT NULL")
@@ -609,6 +857,9 @@ func (s *MemoryStore) List(ctx context.Context, opts storage.ListOptions) (*stor
 		var sourceContextJSON sql.NullString
 		var stateUpdatedAt, lastAccessedAt, decayUpdatedAt, deletedAt sql.NullTime
 		var classificationStatus, summarizationStatus sql.NullString
+		var compressed bool
+		var expiresAt sql.NullTime
+		var authorType sql.NullString
 
 		err := rows.Scan(
 			&memory.ID,
@@ -644,12 +895,19 @@ func (s *MemoryStore) List(ctx context.Context, opts storage.ListOptions) (*stor
 			&contentHash,
 			&supersedesID,
 			&memTypeNull,
+			&compressed,
+			&expiresAt,
+			&authorType,
 		)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan memory: %w", err)
 		}
 
+		if memory.Content, err = DecodeContent(memory.Content, compressed); err != nil {
+			return nil, err
+		}
+
 		// Unmarshal JSON fields
 		if metadataJSON.Valid && metadataJSON.String != "" {
 			if err := json.Unmarshal([]byte(metadataJSON.String), &memory.Metadata); err != nil {
@@ -748,6 +1006,14 @@ func (s *MemoryStore) List(ctx context.Context, opts storage.ListOptions) (*stor
 			memory.MemoryType = memTypeNull.String
 		}
 
+		if expiresAt.Valid {
+			t := expiresAt.Time
+			memory.ExpiresAt = &t
+		}
+		if authorType.Valid {
+			memory.AuthorType = authorType.String
+		}
+
 		memories = append(memories, memory)
 	}
 
@@ -927,7 +1193,7 @@ func (s *MemoryStore) UpdateState(ctx context.Context, id string, state string)
 		return fmt.Errorf("%w: state is required", storage.ErrInvalidInput)
 	}
 
-	if !types.IsValidLifecycleState(state) {
+	if !s.stateMachine.IsValidState(state) {
 		return fmt.Errorf("%w: invalid state: %s", storage.ErrInvalidInput, state)
 	}
 
@@ -938,7 +1204,7 @@ func (s *MemoryStore) UpdateState(ctx context.Context, id string, state string)
 	}
 
 	// Validate state transition
-	if !types.IsValidStateTransition(currentMem.State, state) {
+	if !s.stateMachine.CanTransition(currentMem.State, state) {
 		return fmt.Errorf("invalid state transition: cannot transition from '%s' to '%s'", currentMem.State, state)
 	}
 
@@ -952,7 +1218,14 @@ func (s *MemoryStore) UpdateState(ctx context.Context, id string, state string)
 		WHERE id = ?
 	`
 
-	result, err := s.db.ExecContext(ctx, query, state, now, now, id)
+	// The update and its history row are written together.
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update state: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, query, state, now, now, id)
 	if err != nil {
 		return fmt.Errorf("failed to update state: %w", err)
 	}
@@ -966,6 +1239,14 @@ func (s *MemoryStore) UpdateState(ctx context.Context, id string, state string)
 		return storage.ErrNotFound
 	}
 
+	if err := recordStateTransition(ctx, tx, id, currentMem.State, state, now); err != nil {
+		return fmt.Errorf("failed to record state history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update state: %w", err)
+	}
+
 	return nil
 }
 
@@ -1050,7 +1331,7 @@ func (s *MemoryStore) Close() error {
 	}
 
 	if _, err := s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
-		log.Printf("sqlite: WAL checkpoint on close failed (non-fatal): %v", err)
+		slog.Warn("sqlite: WAL checkpoint on close failed (non-fatal)", "error", err)
 	}
 
 	return s.db.Close()
@@ -1058,12 +1339,15 @@ func (s *MemoryStore) Close() error {
 
 // GetRelatedMemories returns the IDs of memories that share at least one
 // entity with the given memory. This provides 1-hop graph traversal support
-// for the GraphTraversal engine.
+// for the GraphTraversal engine. Soft-deleted memories are neither returned
+// nor traversed from.
 func (s *MemoryStore) GetRelatedMemories(ctx context.Context, memoryID string) ([]string, error) {
 	query := `
 		SELECT DISTINCT me2.memory_id
 		FROM memory_entities me1
+		JOIN memories m1 ON m1.id = me1.memory_id AND m1.deleted_at IS NULL
 		JOIN memory_entities me2 ON me1.entity_id = me2.entity_id
+		JOIN memories m2 ON m2.id = me2.memory_id AND m2.deleted_at IS NULL
 		WHERE me1.memory_id = ?
 		  AND me2.memory_id != ?
 	`
@@ -1136,7 +1420,8 @@ func (s *MemoryStore) GetEvolutionChain(ctx context.Context, memoryID string) ([
 				classification_status, summarization_status,
 				state, state_updated_at,
 				created_by, session_id, source_context,
-				access_count, last_accessed_at, decay_score, decay_updated_at, deleted_at, content_hash, supersedes_id
+				access_count, last_accessed_at, decay_score, decay_updated_at, deleted_at, content_hash, supersedes_id,
+				content_compressed, expires_at, author_type
 			FROM memories WHERE id = ?`
 
 		var m types.Memory
@@ -1147,6 +1432,9 @@ func (s *MemoryStore) GetEvolutionChain(ctx context.Context, memoryID string) ([
 		var sourceContextJSON sql.NullString
 		var stateUpdatedAt, lastAccessedAt, decayUpdatedAt, deletedAt sql.NullTime
 		var classificationStatus, summarizationStatus sql.NullString
+		var compressed bool
+		var expiresAt sql.NullTime
+		var authorType sql.NullString
 
 		err := s.db.QueryRowContext(ctx, query, id).Scan(
 			&m.ID, &m.Content, &m.Source, &domain, &timestamp, &m.Status,
@@ -1159,6 +1447,7 @@ func (s *MemoryStore) GetEvolutionChain(ctx context.Context, memoryID string) ([
 			&state, &stateUpdatedAt,
 			&createdBy, &sessionID, &sourceContextJSON,
 			&m.AccessCount, &lastAccessedAt, &m.DecayScore, &decayUpdatedAt, &deletedAt, &contentHash, &supersedesID,
+			&compressed, &expiresAt, &authorType,
 		)
 		if err == sql.ErrNoRows {
 			return nil, storage.ErrNotFound
@@ -1166,6 +1455,9 @@ func (s *MemoryStore) GetEvolutionChain(ctx context.Context, memoryID string) ([
 		if err != nil {
 			return nil, err
 		}
+		if m.Content, err = DecodeContent(m.Content, compressed); err != nil {
+			return nil, err
+		}
 
 		if domain.Valid {
 			m.Domain = domain.String
@@ -1213,6 +1505,13 @@ func (s *MemoryStore) GetEvolutionChain(ctx context.Context, memoryID string) ([
 			t := deletedAt.Time
 			m.DeletedAt = &t
 		}
+		if expiresAt.Valid {
+			t := expiresAt.Time
+			m.ExpiresAt = &t
+		}
+		if authorType.Valid {
+			m.AuthorType = authorType.String
+		}
 		if classificationStatus.Valid {
 			m.ClassificationStatus = types.EnrichmentStatus(classificationStatus.String)
 		}
@@ -1261,8 +1560,10 @@ func (s *MemoryStore) GetEvolutionChain(ctx context.Context, memoryID string) ([
 	// Walk forward: find memories that supersede any node in the chain.
 	tip := chain[len(chain)-1]
 	for len(chain) < maxChain {
+		// Prefer a live successor over a soft-deleted one.
 		rows, err := s.db.QueryContext(ctx,
-			`SELECT id FROM memories WHERE supersedes_id = ? LIMIT 1`, tip.ID)
+			`SELECT id FROM memories WHERE supersedes_id = ?
+			 ORDER BY deleted_at IS NOT NULL, created_at LIMIT 1`, tip.ID)
 		if err != nil {
 			break
 		}
@@ -1287,20 +1588,121 @@ func (s *MemoryStore) GetEvolutionChain(ctx context.Context, memoryID string) ([
 	return chain, nil
 }
 
-// CreateMemoryLink creates a typed link between two memories in the memory_links table.
+// CreateMemoryLink creates a typed link between two memories in the memory_links table,
+// after the source's existing links of that type.
 func (s *MemoryStore) CreateMemoryLink(ctx context.Context, id, sourceID, targetID, linkType string) error {
-	_, err := s.db.ExecContext(ctx,
-		`INSERT OR IGNORE INTO memory_links (id, source_id, target_id, type) VALUES (?, ?, ?, ?)`,
-		id, sourceID, targetID, linkType,
-	)
+	_, err := s.db.ExecContext(ctx, insertLinkSQL, id, sourceID, targetID, linkType)
 	if err != nil {
 		return fmt.Errorf("sqlite: CreateMemoryLink: %w", err)
 	}
 	return nil
 }
 
+// GetMemoryLinkSources returns the IDs of memories that link to targetID
+// with the given link type (e.g. the parents of a project item for "CONTAINS").
+func (s *MemoryStore) GetMemoryLinkSources(ctx context.Context, targetID, linkType string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT source_id FROM memory_links WHERE target_id = ? AND type = ? ORDER BY created_at`,
+		targetID, linkType,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: GetMemoryLinkSources: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("sqlite: GetMemoryLinkSources scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: GetMemoryLinkSources rows: %w", err)
+	}
+	return ids, nil
+}
+
+// ListMemoryLinks returns every link that starts or ends at memoryID: the
+// rows of the memory_links table plus a SUPERSEDES link for each supersedes_id
+// pointing from or to it (from the newer memory to the one it replaced).
+// Links whose other end is soft-deleted are left out until it is restored.
+// Links are ordered by creation time.
+func (s *MemoryStore) ListMemoryLinks(ctx context.Context, memoryID string) ([]storage.MemoryLink, error) {
+	if memoryID == "" {
+		return nil, fmt.Errorf("%w: memory ID is required", storage.ErrInvalidInput)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT ml.id, ml.source_id, ml.target_id, ml.type, ml.created_at
+		FROM memory_links ml
+		WHERE (ml.source_id = ? OR ml.target_id = ?)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM memories d
+		      WHERE d.id IN (ml.source_id, ml.target_id) AND d.deleted_at IS NOT NULL)
+		UNION ALL
+		SELECT 'supersedes:' || m.id, m.id, m.supersedes_id, 'SUPERSEDES', m.created_at
+		FROM memories m
+		WHERE m.supersedes_id IS NOT NULL AND m.supersedes_id <> ''
+		  AND (m.id = ? OR m.supersedes_id = ?)
+		  AND m.deleted_at IS NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM memories d
+		      WHERE d.id = m.supersedes_id AND d.deleted_at IS NOT NULL)
+		ORDER BY 5, 1`, memoryID, memoryID, memoryID, memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: ListMemoryLinks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	links := []storage.MemoryLink{}
+	for rows.Next() {
+		var link storage.MemoryLink
+		if err := rows.Scan(&link.ID, &link.SourceID, &link.TargetID, &link.Type, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlite: ListMemoryLinks scan: %w", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: ListMemoryLinks rows: %w", err)
+	}
+	return links, nil
+}
+
+// MoveMemoryLink atomically replaces the oldSourceID → targetID link of the
+// given type with a newSourceID → targetID link identified by id, placed
+// after newSourceID's existing links of that type.
+// Returns storage.ErrNotFound if the old link does not exist.
+func (s *MemoryStore) MoveMemoryLink(ctx context.Context, id, oldSourceID, newSourceID, targetID, linkType string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite: MoveMemoryLink: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM memory_links WHERE source_id = ? AND target_id = ? AND type = ?`,
+		oldSourceID, targetID, linkType,
+	)
+	if err != nil {
+		return fmt.Errorf("sqlite: MoveMemoryLink delete: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("sqlite: MoveMemoryLink: %w", err)
+	} else if n == 0 {
+		return storage.ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, insertLinkSQL, id, newSourceID, targetID, linkType); err != nil {
+		return fmt.Errorf("sqlite: MoveMemoryLink insert: %w", err)
+	}
+
+	return tx.Commit()
+}
+
 // GetMemoriesByRelationType returns memories connected to memoryID via
-// memory_links of the given type (e.g. "CONTAINS").
+// memory_links of the given type (e.g. "CONTAINS"), in link order.
 func (s *MemoryStore) GetMemoriesByRelationType(ctx context.Context, memoryID string, relType string) ([]*types.Memory, error) {
 	if memoryID == "" {
 		return nil, fmt.Errorf("%w: memory ID is required", storage.ErrInvalidInput)
@@ -1310,10 +1712,11 @@ func (s *MemoryStore) GetMemoriesByRelationType(ctx context.Context, memoryID st
 	}
 
 	query := `
-		SELECT DISTINCT m.id
+		SELECT m.id
 		FROM memory_links ml
 		JOIN memories m ON m.id = ml.target_id
 		WHERE ml.source_id = ? AND ml.type = ? AND m.deleted_at IS NULL
+		ORDER BY ml.position, ml.created_at, ml.rowid
 	`
 	rows, err := s.db.QueryContext(ctx, query, memoryID, relType)
 	if err != nil {
@@ -1451,7 +1854,7 @@ func removeStaleWAL(dbPath string) {
 	for _, suffix := range []string{"-shm", "-wal"} {
 		path := dbPath + suffix
 		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-			log.Printf("sqlite: failed to remove stale %s: %v", path, err)
+			slog.Warn("sqlite: failed to remove stale file", "path", path, "error", err)
 		}
 	}
 }
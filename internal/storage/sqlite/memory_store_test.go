@@ -2,12 +2,15 @@ package sqlite
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/scrypster/memento/internal/config"
 	"github.com/scrypster/memento/internal/storage"
 	"github.com/scrypster/memento/pkg/types"
 )
@@ -630,6 +633,46 @@ func TestStoreMemory_ContentHashStored(t *testing.T) {
 	}
 }
 
+// TestStoreMemory_ContentHashNormalized verifies that WithDedupNormalization
+// hashes the normalized content while storing the raw content unchanged.
+func TestStoreMemory_ContentHashNormalized(t *testing.T) {
+	ctx := context.Background()
+	hashes := func(opts ...Option) (string, string) {
+		t.Helper()
+		store, err := NewMemoryStore(":memory:", opts...)
+		if err != nil {
+			t.Fatalf("NewMemoryStore() failed: %v", err)
+		}
+		t.Cleanup(func() { _ = store.Close() })
+		first := &types.Memory{ID: "mem:test:a", Content: "Hello  World.", Source: "test"}
+		second := &types.Memory{ID: "mem:test:b", Content: "hello world", Source: "test"}
+		for _, m := range []*types.Memory{first, second} {
+			if err := store.Store(ctx, m); err != nil {
+				t.Fatalf("Store(%s) failed: %v", m.ID, err)
+			}
+		}
+		got, err := store.Get(ctx, first.ID)
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		if got.Content != "Hello  World." {
+			t.Errorf("stored content = %q, want it unchanged", got.Content)
+		}
+		return got.ContentHash, second.ContentHash
+	}
+
+	if a, b := hashes(); a == b {
+		t.Errorf("exact hashing: %q and %q should differ", a, b)
+	}
+	a, b := hashes(WithDedupNormalization(config.DedupNormalized))
+	if a != b {
+		t.Errorf("normalized hashing: %q and %q should match", a, b)
+	}
+	if want := storage.ContentHash(config.DedupNormalized, "hello world"); a != want {
+		t.Errorf("ContentHash = %q, want %q", a, want)
+	}
+}
+
 // TestEvolveMemory_CreatesNewVersionAndSupersedes verifies evolution chains.
 func TestEvolveMemory_CreatesNewVersionAndSupersedes(t *testing.T) {
 	store := newTestStore(t)
@@ -1178,10 +1221,173 @@ func TestList_CreatedAfterFilter(t *testing.T) {
 	}
 }
 
+// TestList_AccessedFilter verifies filtering by AccessedAfter and
+// AccessedBefore, and that never-accessed memories match neither bound.
+func TestList_AccessedFilter(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	old, recent := now.Add(-48*time.Hour), now.Add(-time.Hour)
+	for id, accessed := range map[string]*time.Time{
+		"mem:test:accessed-never":  nil,
+		"mem:test:accessed-old":    &old,
+		"mem:test:accessed-recent": &recent,
+	} {
+		mem := &types.Memory{ID: id, Content: "accessed " + id, Source: "test", LastAccessedAt: accessed}
+		if err := store.Store(ctx, mem); err != nil {
+			t.Fatalf("Store(%s) failed: %v", id, err)
+		}
+	}
+
+	cases := []struct {
+		name          string
+		after, before time.Time
+		want          []string
+	}{
+		{"after", now.Add(-24 * time.Hour), time.Time{}, []string{"mem:test:accessed-recent"}},
+		{"before", time.Time{}, now.Add(-24 * time.Hour), []string{"mem:test:accessed-old"}},
+		{"window", now.Add(-72 * time.Hour), now, []string{"mem:test:accessed-old", "mem:test:accessed-recent"}},
+		{"empty window", now.Add(-30 * time.Hour), now.Add(-2 * time.Hour), nil},
+	}
+	for _, tc := range cases {
+		result, err := store.List(ctx, storage.ListOptions{
+			Limit:          100,
+			SortBy:         "created_at",
+			SortOrder:      "asc",
+			AccessedAfter:  tc.after,
+			AccessedBefore: tc.before,
+		})
+		if err != nil {
+			t.Fatalf("%s: List() failed: %v", tc.name, err)
+		}
+		var got []string
+		for _, m := range result.Items {
+			got = append(got, m.ID)
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: List() = %v, want %v", tc.name, got, tc.want)
+		}
+		if result.Total != len(tc.want) {
+			t.Errorf("%s: Total = %d, want %d", tc.name, result.Total, len(tc.want))
+		}
+	}
+}
+
+// TestList_MetadataFilter verifies Metadata matches string, number and
+// boolean values by key, requires the JSON types to agree and ANDs keys.
+func TestList_MetadataFilter(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for id, metadata := range map[string]map[string]interface{}{
+		"mem:test:meta-atlas":    {"project": "atlas", "priority": 1, "urgent": true},
+		"mem:test:meta-atlas-lo": {"project": "atlas", "priority": 3, "urgent": false},
+		"mem:test:meta-string":   {"project": "zeus", "priority": "1"},
+		"mem:test:meta-none":     nil,
+	} {
+		mem := &types.Memory{ID: id, Content: "metadata " + id, Source: "test", Metadata: metadata}
+		if err := store.Store(ctx, mem); err != nil {
+			t.Fatalf("Store(%s) failed: %v", id, err)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		metadata map[string]interface{}
+		want     []string
+	}{
+		{"string", map[string]interface{}{"project": "atlas"}, []string{"mem:test:meta-atlas", "mem:test:meta-atlas-lo"}},
+		{"number", map[string]interface{}{"priority": 1}, []string{"mem:test:meta-atlas"}},
+		{"float number", map[string]interface{}{"priority": 3.0}, []string{"mem:test:meta-atlas-lo"}},
+		{"string is not number", map[string]interface{}{"priority": "1"}, []string{"mem:test:meta-string"}},
+		{"bool true", map[string]interface{}{"urgent": true}, []string{"mem:test:meta-atlas"}},
+		{"bool false", map[string]interface{}{"urgent": false}, []string{"mem:test:meta-atlas-lo"}},
+		{"all keys", map[string]interface{}{"project": "atlas", "urgent": false}, []string{"mem:test:meta-atlas-lo"}},
+		{"no match", map[string]interface{}{"project": "atlas", "priority": 2}, nil},
+		{"missing key", map[string]interface{}{"owner": "bob"}, nil},
+	}
+	for _, tc := range cases {
+		result, err := store.List(ctx, storage.ListOptions{
+			Limit:    100,
+			SortBy:   "created_at",
+			Metadata: tc.metadata,
+		})
+		if err != nil {
+			t.Fatalf("%s: List() failed: %v", tc.name, err)
+		}
+		var got []string
+		for _, m := range result.Items {
+			got = append(got, m.ID)
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: List() = %v, want %v", tc.name, got, tc.want)
+		}
+		if result.Total != len(tc.want) {
+			t.Errorf("%s: Total = %d, want %d", tc.name, result.Total, len(tc.want))
+		}
+	}
+
+	_, err := store.List(ctx, storage.ListOptions{Metadata: map[string]interface{}{"project": []string{"atlas"}}})
+	if !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("List() with an array value: err = %v, want ErrInvalidInput", err)
+	}
+}
+
 // ============================================================================
 // DECAY SCORE TESTS
 // ============================================================================
 
+// TestList_DecayScoreRange verifies the MaxDecayScore upper bound (inclusive)
+// on its own and combined with MinDecayScore.
+func TestList_DecayScoreRange(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for id, decay := range map[string]float64{
+		"mem:test:decay-cold": 0.1,
+		"mem:test:decay-cool": 0.3,
+		"mem:test:decay-warm": 0.6,
+		"mem:test:decay-hot":  0.9,
+	} {
+		mem := &types.Memory{ID: id, Content: "decay range " + id, Source: "test", DecayScore: decay}
+		if err := store.Store(ctx, mem); err != nil {
+			t.Fatalf("Store(%s) failed: %v", id, err)
+		}
+	}
+
+	cases := []struct {
+		name     string
+		min, max float64
+		want     []string
+	}{
+		{"max only", 0, 0.3, []string{"mem:test:decay-cold", "mem:test:decay-cool"}},
+		{"range", 0.3, 0.6, []string{"mem:test:decay-cool", "mem:test:decay-warm"}},
+		{"min only", 0.6, 0, []string{"mem:test:decay-warm", "mem:test:decay-hot"}},
+	}
+	for _, tc := range cases {
+		result, err := store.List(ctx, storage.ListOptions{
+			Limit:         100,
+			SortBy:        "decay_score",
+			SortOrder:     "asc",
+			MinDecayScore: tc.min,
+			MaxDecayScore: tc.max,
+		})
+		if err != nil {
+			t.Fatalf("%s: List() failed: %v", tc.name, err)
+		}
+		var got []string
+		for _, m := range result.Items {
+			got = append(got, m.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: List() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
 // TestUpdateDecayScores_AppliesDecay verifies that UpdateDecayScores applies
 // time-based decay to memories. It verifies that the decay_updated_at field
 // is set and that decay_score is recalculated.
@@ -1391,3 +1597,135 @@ func TestGetEvolutionChain_SingleMemory(t *testing.T) {
 		t.Errorf("Chain[0].SupersedesID: expected empty, got %s", chain[0].SupersedesID)
 	}
 }
+
+// pragmaInt reads an integer PRAGMA from the store's connection.
+func pragmaInt(t *testing.T, store *MemoryStore, name string) int {
+	t.Helper()
+	var v int
+	if err := store.db.QueryRow("PRAGMA " + name).Scan(&v); err != nil {
+		t.Fatalf("PRAGMA %s failed: %v", name, err)
+	}
+	return v
+}
+
+// TestNewMemoryStore_DefaultPragmas verifies the default connection PRAGMAs.
+func TestNewMemoryStore_DefaultPragmas(t *testing.T) {
+	store, err := NewMemoryStore(filepath.Join(t.TempDir(), "defaults.db"))
+	if err != nil {
+		t.Fatalf("NewMemoryStore() failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	var mode string
+	if err := store.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("PRAGMA journal_mode failed: %v", err)
+	}
+	if !strings.EqualFold(mode, "wal") {
+		t.Errorf("journal_mode = %q, want wal", mode)
+	}
+	if got := pragmaInt(t, store, "busy_timeout"); got != int(DefaultBusyTimeout.Milliseconds()) {
+		t.Errorf("busy_timeout = %d, want %d", got, DefaultBusyTimeout.Milliseconds())
+	}
+	if got := pragmaInt(t, store, "wal_autocheckpoint"); got != DefaultWALAutocheckpoint {
+		t.Errorf("wal_autocheckpoint = %d, want %d", got, DefaultWALAutocheckpoint)
+	}
+}
+
+// TestNewMemoryStore_CustomPragmas verifies that the PRAGMA options are applied.
+func TestNewMemoryStore_CustomPragmas(t *testing.T) {
+	store, err := NewMemoryStore(filepath.Join(t.TempDir(), "custom.db"),
+		WithBusyTimeout(12*time.Second),
+		WithWALAutocheckpoint(250),
+	)
+	if err != nil {
+		t.Fatalf("NewMemoryStore() failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if got := pragmaInt(t, store, "busy_timeout"); got != 12000 {
+		t.Errorf("busy_timeout = %d, want 12000", got)
+	}
+	if got := pragmaInt(t, store, "wal_autocheckpoint"); got != 250 {
+		t.Errorf("wal_autocheckpoint = %d, want 250", got)
+	}
+}
+
+// TestNewMemoryStore_InvalidJournalMode verifies that unknown journal modes
+// are rejected rather than interpolated into a PRAGMA.
+func TestNewMemoryStore_InvalidJournalMode(t *testing.T) {
+	_, err := NewMemoryStore(filepath.Join(t.TempDir(), "bad.db"), WithJournalMode("wal; DROP TABLE memories"))
+	if !errors.Is(err, storage.ErrInvalidInput) {
+		t.Fatalf("NewMemoryStore() error = %v, want ErrInvalidInput", err)
+	}
+}
+
+// holdWriteLock opens a second store on dbPath (standing in for another
+// process such as memento-web) and holds the database write lock for d.
+func holdWriteLock(t *testing.T, dbPath string, d time.Duration) <-chan struct{} {
+	t.Helper()
+	other, err := NewMemoryStore(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open second store: %v", err)
+	}
+	ctx := context.Background()
+	conn, err := other.db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		t.Fatalf("failed to take write lock: %v", err)
+	}
+
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(d)
+		_, _ = conn.ExecContext(ctx, "COMMIT")
+		_ = conn.Close()
+		_ = other.Close()
+		close(released)
+	}()
+	return released
+}
+
+// TestBusyTimeout_ConcurrentWriteWaitsForLock verifies that with a busy
+// timeout a write blocked by another connection's lock waits and succeeds
+// instead of failing immediately with "database is locked".
+func TestBusyTimeout_ConcurrentWriteWaitsForLock(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "busy.db")
+	store, err := NewMemoryStore(dbPath, WithBusyTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("NewMemoryStore() failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	released := holdWriteLock(t, dbPath, 200*time.Millisecond)
+
+	err = store.Store(context.Background(), &types.Memory{ID: "mem:test:busy", Content: "written under contention", Source: "test"})
+	if err != nil {
+		t.Fatalf("Store() under lock contention failed: %v", err)
+	}
+	<-released
+
+	if _, err := store.Get(context.Background(), "mem:test:busy"); err != nil {
+		t.Errorf("Get() after contended write failed: %v", err)
+	}
+}
+
+// TestBusyTimeout_ZeroFailsFast documents the behaviour the busy timeout
+// prevents: with busy_timeout = 0 a contended write fails immediately.
+func TestBusyTimeout_ZeroFailsFast(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "nobusy.db")
+	store, err := NewMemoryStore(dbPath, WithBusyTimeout(0))
+	if err != nil {
+		t.Fatalf("NewMemoryStore() failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	released := holdWriteLock(t, dbPath, 200*time.Millisecond)
+	defer func() { <-released }()
+
+	err = store.Store(context.Background(), &types.Memory{ID: "mem:test:nobusy", Content: "written under contention", Source: "test"})
+	if err == nil {
+		t.Fatal("Store() succeeded under lock contention with busy_timeout=0, want a lock error")
+	}
+}
@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// PurgeAll hard-deletes the store's memories, entities and relationships in
+// one transaction. See storage.StorePurger.
+func (s *MemoryStore) PurgeAll(ctx context.Context) (*storage.PurgeResult, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: PurgeAll: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Dependent rows go first so the purge does not rely on cascades.
+	result := &storage.PurgeResult{}
+	for _, step := range []struct {
+		table string
+		count *int
+	}{
+		{"memory_links", &result.Links},
+		{"memory_entities", nil},
+		{"embeddings", nil},
+		{"state_history", nil},
+		{"relationships", &result.Relationships},
+		{"entities", &result.Entities},
+		{"memories", &result.Memories},
+	} {
+		res, err := tx.ExecContext(ctx, "DELETE FROM "+step.table)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: PurgeAll %s: %w", step.table, err)
+		}
+		if step.count == nil {
+			continue
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: PurgeAll %s: %w", step.table, err)
+		}
+		*step.count = int(n)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("sqlite: PurgeAll: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func TestPurgeAll(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	storeTestMemory(t, store, "mem:test:1", "Alice reviewed the design")
+	storeTestMemory(t, store, "mem:test:2", "Alice shipped the release")
+	storeTestMemory(t, store, "mem:test:3", "An old note about the release")
+	insertEntity(t, store, "ent:alice", "Alice", "person")
+	insertEntity(t, store, "ent:memento", "Memento", "project")
+	linkMemoryEntity(t, store, "mem:test:1", "ent:alice")
+	insertRelationship(t, store, "rel:1", "ent:alice", "ent:memento", "works_on")
+	if err := store.CreateMemoryLink(ctx, "link:1", "mem:test:1", "mem:test:2", "RELATED_TO"); err != nil {
+		t.Fatalf("CreateMemoryLink() failed: %v", err)
+	}
+	if err := store.UpdateState(ctx, "mem:test:2", types.StatePlanning); err != nil {
+		t.Fatalf("UpdateState() failed: %v", err)
+	}
+	if err := store.Delete(ctx, "mem:test:3"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+
+	result, err := store.PurgeAll(ctx)
+	if err != nil {
+		t.Fatalf("PurgeAll() failed: %v", err)
+	}
+	if result.Memories != 3 || result.Entities != 2 || result.Relationships != 1 || result.Links != 1 {
+		t.Errorf("PurgeAll() = %+v, want 3 memories, 2 entities, 1 relationship, 1 link", result)
+	}
+
+	for _, table := range []string{
+		"memories", "memories_fts", "memory_entities", "memory_links",
+		"embeddings", "state_history", "relationships", "entities",
+	} {
+		var n int
+		if err := store.GetDB().QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n); err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		if n != 0 {
+			t.Errorf("%d rows left in %s, want 0", n, table)
+		}
+	}
+
+	// The store stays usable.
+	storeTestMemory(t, store, "mem:test:4", "A fresh start")
+	if _, err := store.Get(ctx, "mem:test:4"); err != nil {
+		t.Errorf("Get() after purge failed: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+func TestListRelationships(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	storeTestMemory(t, store, "mem:test:1", "Alice works on Memento with Bob")
+	insertEntity(t, store, "ent:alice", "Alice", "person")
+	insertEntity(t, store, "ent:bob", "Bob", "person")
+	insertEntity(t, store, "ent:memento", "Memento", "project")
+	insertEntity(t, store, "ent:carol", "Carol", "person")
+	linkMemoryEntity(t, store, "mem:test:1", "ent:alice")
+	linkMemoryEntity(t, store, "mem:test:1", "ent:bob")
+	linkMemoryEntity(t, store, "mem:test:1", "ent:memento")
+	insertRelationship(t, store, "rel:1", "ent:alice", "ent:memento", "works_on")
+	insertRelationship(t, store, "rel:2", "ent:bob", "ent:memento", "works_on")
+	insertRelationship(t, store, "rel:3", "ent:carol", "ent:alice", "knows")
+
+	// rel:3 involves Carol, whom the memory does not mention.
+	rels, err := store.ListRelationships(ctx, storage.RelationshipFilter{MemoryID: "mem:test:1"})
+	if err != nil {
+		t.Fatalf("ListRelationships(memory) failed: %v", err)
+	}
+	if len(rels) != 2 {
+		t.Fatalf("ListRelationships(memory) returned %d relationships, want 2", len(rels))
+	}
+	if rels[0].ID != "rel:1" || rels[0].SourceName != "Alice" || rels[0].TargetType != "project" || rels[0].Strength != 1 {
+		t.Errorf("first relationship = %+v, want rel:1 from Alice to the Memento project with strength 1", rels[0])
+	}
+
+	rels, err = store.ListRelationships(ctx, storage.RelationshipFilter{EntityID: "ent:alice"})
+	if err != nil {
+		t.Fatalf("ListRelationships(entity) failed: %v", err)
+	}
+	if len(rels) != 2 {
+		t.Errorf("ListRelationships(entity) returned %d relationships, want 2", len(rels))
+	}
+
+	rels, err = store.ListRelationships(ctx, storage.RelationshipFilter{EntityID: "ent:alice", Type: "knows"})
+	if err != nil {
+		t.Fatalf("ListRelationships(entity, type) failed: %v", err)
+	}
+	if len(rels) != 1 || rels[0].ID != "rel:3" {
+		t.Errorf("ListRelationships(entity, type) = %+v, want only rel:3", rels)
+	}
+
+	for _, filter := range []storage.RelationshipFilter{
+		{MemoryID: "mem:test:missing"},
+		{EntityID: "ent:missing"},
+	} {
+		if _, err := store.ListRelationships(ctx, filter); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("ListRelationships(%+v) error = %v, want ErrNotFound", filter, err)
+		}
+	}
+	if _, err := store.ListRelationships(ctx, storage.RelationshipFilter{}); !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("ListRelationships(empty) error = %v, want ErrInvalidInput", err)
+	}
+}
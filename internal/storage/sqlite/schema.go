@@ -65,7 +65,18 @@ CREATE TABLE IF NOT EXISTS memories (
     content_hash TEXT,
 
     -- Evolution chain (migration 000011)
-    supersedes_id TEXT  -- references memories(id)
+    supersedes_id TEXT, -- references memories(id)
+
+    -- Content compression: 1 when content holds gzip bytes rather than text.
+    -- memories_fts always indexes the uncompressed text.
+    content_compressed INTEGER NOT NULL DEFAULT 0,
+
+    -- Expiry: memories past expires_at are excluded from List/search and
+    -- soft-deleted by the engine's expiry sweeper. NULL = never expires.
+    expires_at TIMESTAMP,
+
+    -- Authorship origin of created_by
+    author_type TEXT -- 'human', 'agent' or 'system'
 );
 
 -- Entities table: Extracted entities from memories
@@ -126,9 +137,9 @@ CREATE TABLE IF NOT EXISTS memory_entities (
     FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
 );
 
--- Embeddings table: Vector embeddings with dimension tracking
+-- Embeddings table: Vector embeddings with dimension tracking, one per memory and model
 CREATE TABLE IF NOT EXISTS embeddings (
-    memory_id TEXT PRIMARY KEY,
+    memory_id TEXT NOT NULL,
     embedding BLOB NOT NULL, -- Stored as binary packed float64 array
     dimension INTEGER NOT NULL,
     model TEXT NOT NULL,
@@ -136,6 +147,7 @@ CREATE TABLE IF NOT EXISTS embeddings (
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 
+    PRIMARY KEY (memory_id, model),
     FOREIGN KEY (memory_id) REFERENCES memories(id) ON DELETE CASCADE
 );
 
@@ -146,6 +158,9 @@ CREATE VIRTUAL TABLE IF NOT EXISTS memories_fts USING fts5(
     tokenize = 'porter unicode61'
 );
 
+-- Indexed terms of memories_fts, scanned for fuzzy (typo-tolerant) search
+CREATE VIRTUAL TABLE IF NOT EXISTS memories_fts_vocab USING fts5vocab(memories_fts, 'row');
+
 -- Indexes for performance
 
 -- Memory status queries
@@ -218,21 +233,21 @@ CREATE TRIGGER IF NOT EXISTS embeddings_updated_at
 AFTER UPDATE ON embeddings
 FOR EACH ROW
 BEGIN
-    UPDATE embeddings SET updated_at = CURRENT_TIMESTAMP WHERE memory_id = NEW.memory_id;
+    UPDATE embeddings SET updated_at = CURRENT_TIMESTAMP WHERE memory_id = NEW.memory_id AND model = NEW.model;
 END;
 
 -- Sync FTS index with memories table
 
 CREATE TRIGGER IF NOT EXISTS memories_fts_insert
 AFTER INSERT ON memories
-FOR EACH ROW
+FOR EACH ROW WHEN NEW.content_compressed = 0
 BEGIN
     INSERT INTO memories_fts(id, content) VALUES (NEW.id, NEW.content);
 END;
 
 CREATE TRIGGER IF NOT EXISTS memories_fts_update
 AFTER UPDATE ON memories
-FOR EACH ROW
+FOR EACH ROW WHEN NEW.content_compressed = 0
 BEGIN
     UPDATE memories_fts SET content = NEW.content WHERE id = NEW.id;
 END;
@@ -282,6 +297,7 @@ CREATE TABLE IF NOT EXISTS memory_links (
     source_id TEXT NOT NULL,
     target_id TEXT NOT NULL,
     type TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,  -- order among the source's links of this type
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     UNIQUE(source_id, target_id, type)
 );
@@ -289,4 +305,42 @@ CREATE TABLE IF NOT EXISTS memory_links (
 CREATE INDEX IF NOT EXISTS idx_memory_links_source ON memory_links(source_id);
 CREATE INDEX IF NOT EXISTS idx_memory_links_target ON memory_links(target_id);
 CREATE INDEX IF NOT EXISTS idx_memory_links_type ON memory_links(type);
+
+-- Sessions: named work sessions started with begin_session. Memories refer
+-- to them through memories.session_id; anonymous sessions have no row.
+CREATE TABLE IF NOT EXISTS sessions (
+    id TEXT PRIMARY KEY,
+    label TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP
+);
+
+-- Audit log: an append-only record of mutating MCP operations, written when
+-- the server runs with the audit log enabled.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at TIMESTAMP NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    author_type TEXT NOT NULL DEFAULT '',
+    tool TEXT NOT NULL,
+    memory_ids TEXT NOT NULL DEFAULT '[]', -- JSON array of memory IDs
+    connection TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor);
+
+-- State history: one row per lifecycle state transition, written by
+-- UpdateState. Purging a memory removes its history.
+CREATE TABLE IF NOT EXISTS state_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    memory_id TEXT NOT NULL,
+    from_state TEXT NOT NULL DEFAULT '',
+    to_state TEXT NOT NULL,
+    changed_at TIMESTAMP NOT NULL,
+    changed_by TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (memory_id) REFERENCES memories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_state_history_memory ON state_history(memory_id, id);
 `
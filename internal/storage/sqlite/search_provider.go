@@ -4,8 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math"
+	"log/slog"
 	"sort"
 	"strings"
 
@@ -16,7 +17,14 @@ import (
 // Ensure *MemoryStore implements storage.SearchProvider at compile time.
 var _ storage.SearchProvider = (*MemoryStore)(nil)
 
+// ftsSnippetColumn selects a bounded excerpt of memories_fts.content (column
+// 1) around the matched terms. It reads the FTS index, so it works on the
+// uncompressed text even when memories.content is compressed.
+var ftsSnippetColumn = fmt.Sprintf("snippet(memories_fts, 1, '%s', '%s', '%s', %d)",
+	storage.SnippetStartSel, storage.SnippetStopSel, storage.SnippetEllipsis, storage.SnippetMaxTokens)
+
 // FullTextSearch performs FTS5-backed full-text search across memory content.
+// Each result carries a Snippet excerpt with the matched terms marked.
 //
 // The FTS5 virtual table (memories_fts) is kept in sync with the memories
 // table via INSERT/UPDATE/DELETE triggers defined in schema.go.
@@ -26,27 +34,63 @@ var _ storage.SearchProvider = (*MemoryStore)(nil)
 //
 // FTS5 rank values are negative (more negative == better match), so ordering
 // by rank ASC gives the best results first.
+//
+// With opts.Fuzzy, typo matches found through the FTS5 vocabulary are
+// appended after the exact ones (see addFuzzyMatches).
 func (s *MemoryStore) FullTextSearch(ctx context.Context, opts storage.SearchOptions) (*storage.PaginatedResult[types.Memory], error) {
 	opts.Normalize()
+	result, err := s.matchFullText(ctx, opts)
+	if err != nil || !opts.Fuzzy {
+		return result, err
+	}
+	if err := s.addFuzzyMatches(ctx, result, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// matchFullText runs the exact FTS5 search behind FullTextSearch.
+func (s *MemoryStore) matchFullText(ctx context.Context, opts storage.SearchOptions) (*storage.PaginatedResult[types.Memory], error) {
+	opts.Normalize()
 
 	// When the query is empty fall back to a plain list ordered by creation time.
 	if strings.TrimSpace(opts.Query) == "" {
 		return s.List(ctx, storage.ListOptions{
-			Page:      1,
-			Limit:     opts.Limit,
-			SortBy:    "created_at",
-			SortOrder: "desc",
+			Page:           1,
+			Limit:          opts.Limit,
+			SortBy:         "created_at",
+			SortOrder:      "desc",
+			IncludeExpired: opts.IncludeExpired,
+			Metadata:       opts.Metadata,
 		})
 	}
 
-	// Sanitise the raw query string so it is safe to pass to FTS5's MATCH
-	// operator.  FTS5 syntax is powerful but fragile: an unbalanced quote or
-	// stray operator keyword will cause SQLite to return "fts5: syntax error".
-	// We convert the free-form user input into a simple prefix query that
-	// searches for each word individually (OR semantics).
+	// Never pass the raw query to FTS5's MATCH operator: its syntax is
+	// powerful but fragile, and an unbalanced quote or stray operator keyword
+	// makes SQLite return "fts5: syntax error". A query using the documented
+	// search syntax (see storage.ParseSearchQuery) is rebuilt from its parsed
+	// tokens; free-form input becomes a simple prefix query that searches
+	// for each word individually (OR semantics).
+	tokens := storage.ParseSearchQuery(opts.Query)
 	ftsQuery := sanitiseFTSQuery(opts.Query)
+	if tokens != nil {
+		if len(tokens) == 0 {
+			return &storage.PaginatedResult[types.Memory]{Page: 1, PageSize: opts.Limit}, nil
+		}
+		ftsQuery = ftsMatchQuery(tokens)
+	}
+	expiryCond, expiryArgs := expiryFilter("m.", opts.IncludeExpired)
+	expiryCond += archivedFilter("m.", opts.IncludeArchived)
+	tagsCond, tagsArgs := tagsFilter("m.", opts.Tags, opts.TagsMatchAll)
+	metadataCond, metadataArgs, err := metadataFilter("m.", opts.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	expiryCond += tagsCond + metadataCond
+	expiryArgs = append(expiryArgs, tagsArgs...)
+	expiryArgs = append(expiryArgs, metadataArgs...)
 
-	const querySQL = `
+	querySQL := `
 		SELECT
 			m.id, m.content, m.source, m.domain, m.timestamp, m.status,
 			m.entity_status, m.relationship_status, m.embedding_status,
@@ -55,15 +99,19 @@ func (s *MemoryStore) FullTextSearch(ctx context.Context, opts storage.SearchOpt
 			m.metadata, m.tags,
 			m.state, m.state_updated_at,
 			m.created_by, m.session_id, m.source_context,
-			m.access_count, m.last_accessed_at, m.decay_score, m.decay_updated_at
+			m.access_count, m.last_accessed_at, m.decay_score, m.decay_updated_at,
+			m.content_compressed, m.expires_at, m.author_type, m.summary,
+			m.memory_type,
+			` + ftsSnippetColumn + `
 		FROM memories_fts fts
 		JOIN memories m ON m.rowid = fts.rowid
-		WHERE memories_fts MATCH ? AND m.deleted_at IS NULL
+		WHERE memories_fts MATCH ? AND m.deleted_at IS NULL` + expiryCond + `
 		ORDER BY rank
 		LIMIT ? OFFSET ?
 	`
 
-	rows, err := s.db.QueryContext(ctx, querySQL, ftsQuery, opts.Limit, opts.Offset)
+	args := append([]interface{}{ftsQuery}, expiryArgs...)
+	rows, err := s.db.QueryContext(ctx, querySQL, append(args, opts.Limit, opts.Offset)...)
 	if err != nil {
 		// FTS5 can still error on malformed input that slipped past sanitisation.
 		// Wrap the error with enough context for callers to diagnose.
@@ -78,14 +126,14 @@ func (s *MemoryStore) FullTextSearch(ctx context.Context, opts storage.SearchOpt
 
 	// Count total matching rows (without LIMIT/OFFSET) so the caller can
 	// determine whether more pages exist.
-	const countSQL = `
+	countSQL := `
 		SELECT COUNT(*)
 		FROM memories_fts fts
 		JOIN memories m ON m.rowid = fts.rowid
-		WHERE memories_fts MATCH ? AND m.deleted_at IS NULL
+		WHERE memories_fts MATCH ? AND m.deleted_at IS NULL` + expiryCond + `
 	`
 	var total int
-	if err := s.db.QueryRowContext(ctx, countSQL, ftsQuery).Scan(&total); err != nil {
+	if err := s.db.QueryRowContext(ctx, countSQL, args...).Scan(&total); err != nil {
 		return nil, fmt.Errorf("sqlite: FullTextSearch count: %w", err)
 	}
 
@@ -102,14 +150,15 @@ func (s *MemoryStore) FullTextSearch(ctx context.Context, opts storage.SearchOpt
 		HasMore:  opts.Offset+len(memories) < total,
 	}
 
-	// Fuzzy fallback: if no results and FuzzyFallback is enabled, retry with OR'd terms
-	if opts.FuzzyFallback && len(result.Items) == 0 && opts.Query != "" {
+	// Fuzzy fallback: if no results and FuzzyFallback is enabled, retry with
+	// OR'd terms. Queries using the search syntax are matched as written.
+	if opts.FuzzyFallback && len(result.Items) == 0 && tokens == nil {
 		terms := strings.Fields(opts.Query)
 		if len(terms) > 1 {
 			relaxedOpts := opts
 			relaxedOpts.Query = strings.Join(terms, " OR ")
 			relaxedOpts.FuzzyFallback = false // prevent recursion
-			return s.FullTextSearch(ctx, relaxedOpts)
+			return s.matchFullText(ctx, relaxedOpts)
 		}
 	}
 
@@ -127,6 +176,13 @@ const vectorSearchMaxCandidates = 10_000
 // Embeddings are loaded into Go memory and ranked by cosine similarity.
 // The candidate pool is capped at vectorSearchMaxCandidates (most-recent first)
 // to avoid excessive memory use on large datasets.
+//
+// Only embeddings with the same dimension as query are compared, one per
+// memory: the one from opts.EmbeddingModel when set, otherwise the most
+// recently stored one. When none match but embeddings of another dimension
+// exist (the embedding model was changed without re-embedding), it returns an
+// error wrapping storage.ErrDimensionMismatch. Candidates below
+// opts.MinSimilarity are dropped.
 func (s *MemoryStore) VectorSearch(ctx context.Context, query []float64, opts storage.SearchOptions) (*storage.PaginatedResult[types.Memory], error) {
 	opts.Normalize()
 
@@ -134,13 +190,25 @@ func (s *MemoryStore) VectorSearch(ctx context.Context, query []float64, opts st
 		return &storage.PaginatedResult[types.Memory]{Items: []types.Memory{}, PageSize: opts.Limit}, nil
 	}
 
+	expiryCond, expiryArgs := expiryFilter("m.", opts.IncludeExpired)
+	expiryCond += archivedFilter("m.", opts.IncludeArchived)
+	embeddingCond, embeddingArgs := embeddingChoiceFilter(opts.EmbeddingModel)
+	tagsCond, tagsArgs := tagsFilter("m.", opts.Tags, opts.TagsMatchAll)
+	metadataCond, metadataArgs, err := metadataFilter("m.", opts.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	args := append([]interface{}{len(query)}, expiryArgs...)
+	args = append(args, embeddingArgs...)
+	args = append(args, tagsArgs...)
+	args = append(args, metadataArgs...)
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT e.memory_id, e.embedding, e.dimension
 		FROM embeddings e
 		JOIN memories m ON m.id = e.memory_id
-		WHERE m.deleted_at IS NULL
+		WHERE m.deleted_at IS NULL AND e.dimension = ?`+expiryCond+embeddingCond+tagsCond+metadataCond+`
 		ORDER BY m.created_at DESC
-		LIMIT ?`, vectorSearchMaxCandidates)
+		LIMIT ?`, append(args, vectorSearchMaxCandidates)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load embeddings: %w", err)
 	}
@@ -163,13 +231,29 @@ func (s *MemoryStore) VectorSearch(ctx context.Context, query []float64, opts st
 		if err != nil {
 			continue
 		}
-		sim := cosineSimilarity(query, embedding)
+		sim := storage.CosineSimilarity(query, embedding)
 		candidates = append(candidates, scored{memID, sim})
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating embeddings: %w", err)
 	}
 
+	if len(candidates) == 0 {
+		if err := s.checkEmbeddingDimension(ctx, len(query)); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.MinSimilarity > 0 {
+		kept := candidates[:0]
+		for _, c := range candidates {
+			if c.score >= opts.MinSimilarity {
+				kept = append(kept, c)
+			}
+		}
+		candidates = kept
+	}
+
 	sort.Slice(candidates, func(i, j int) bool {
 		return candidates[i].score > candidates[j].score
 	})
@@ -216,16 +300,19 @@ func (s *MemoryStore) HybridSearch(ctx context.Context, text string, vector []fl
 		candidateLimit = 30
 	}
 
-	ftsOpts := storage.SearchOptions{Query: text, Limit: candidateLimit}
+	ftsOpts := storage.SearchOptions{Query: text, Limit: candidateLimit, IncludeExpired: opts.IncludeExpired, Tags: opts.Tags, TagsMatchAll: opts.TagsMatchAll, Metadata: opts.Metadata}
 	ftsResult, err := s.FullTextSearch(ctx, ftsOpts)
 	if err != nil {
 		return nil, fmt.Errorf("hybrid search FTS failed: %w", err)
 	}
 
-	vecOpts := storage.SearchOptions{Limit: candidateLimit}
+	vecOpts := storage.SearchOptions{Limit: candidateLimit, IncludeExpired: opts.IncludeExpired, EmbeddingModel: opts.EmbeddingModel, MinSimilarity: opts.MinSimilarity, Tags: opts.Tags, TagsMatchAll: opts.TagsMatchAll, Metadata: opts.Metadata}
 	vecResult, err := s.VectorSearch(ctx, vector, vecOpts)
 	if err != nil {
 		// Vector search failure is non-fatal — fall back to FTS only
+		if errors.Is(err, storage.ErrDimensionMismatch) {
+			slog.Warn("sqlite: falling back to full-text search; re-embed existing memories with the re-embed-all maintenance backfill", "error", err)
+		}
 		opts.Query = text
 		return s.FullTextSearch(ctx, opts)
 	}
@@ -233,8 +320,10 @@ func (s *MemoryStore) HybridSearch(ctx context.Context, text string, vector []fl
 	// Reciprocal Rank Fusion (k=60 is a well-tuned default)
 	const rrfK = 60.0
 	scores := make(map[string]float64)
+	snippets := make(map[string]string)
 	for rank, mem := range ftsResult.Items {
 		scores[mem.ID] += 1.0 / (rrfK + float64(rank+1))
+		snippets[mem.ID] = mem.Snippet
 	}
 	for rank, mem := range vecResult.Items {
 		scores[mem.ID] += 1.0 / (rrfK + float64(rank+1))
@@ -269,6 +358,7 @@ func (s *MemoryStore) HybridSearch(ctx context.Context, text string, vector []fl
 		if err != nil {
 			continue
 		}
+		mem.Snippet = snippets[r.id]
 		memories = append(memories, *mem)
 	}
 
@@ -280,22 +370,63 @@ func (s *MemoryStore) HybridSearch(ctx context.Context, text string, vector []fl
 	}, nil
 }
 
-// cosineSimilarity computes cosine similarity between two equal-length vectors.
-// Returns 0 if either vector has zero magnitude or lengths differ.
-func cosineSimilarity(a, b []float64) float64 {
-	if len(a) != len(b) || len(a) == 0 {
-		return 0
+// tagsFilter returns a WHERE fragment (prefixed with " AND ") keeping
+// memories whose JSON tags array holds any of tags, or all of them when
+// matchAll is set, or "" when tags is empty. alias is an optional table
+// prefix ("m.").
+func tagsFilter(alias string, tags []string, matchAll bool) (string, []interface{}) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+	args := make([]interface{}, len(tags))
+	for i, tag := range tags {
+		args[i] = tag
 	}
-	var dot, normA, normB float64
-	for i := range a {
-		dot += a[i] * b[i]
-		normA += a[i] * a[i]
-		normB += b[i] * b[i]
+	if matchAll {
+		cond := strings.Repeat(" AND EXISTS (SELECT 1 FROM json_each("+alias+"tags) WHERE value = ?)", len(tags))
+		return cond, args
 	}
-	if normA == 0 || normB == 0 {
-		return 0
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(tags)), ",")
+	return fmt.Sprintf(" AND EXISTS (SELECT 1 FROM json_each(%stags) WHERE value IN (%s))", alias, placeholders), args
+}
+
+// metadataFilter returns a WHERE fragment (prefixed with " AND ") keeping
+// memories whose JSON metadata holds every key of filter with an equal
+// value of the same JSON type, or "" when filter is empty. alias is an
+// optional table prefix ("m."). Errors wrap storage.ErrInvalidInput.
+func metadataFilter(alias string, filter map[string]interface{}) (string, []interface{}, error) {
+	filter, err := storage.NormalizeMetadataFilter(filter)
+	if err != nil || len(filter) == 0 {
+		return "", nil, err
+	}
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	var args []interface{}
+	column := alias + "metadata"
+	for _, key := range keys {
+		path := `$."` + key + `"`
+		switch v := filter[key].(type) {
+		case bool:
+			jsonType := "false"
+			if v {
+				jsonType = "true"
+			}
+			fmt.Fprintf(&b, " AND json_type(%s, ?) = '%s'", column, jsonType)
+			args = append(args, path)
+		case float64:
+			fmt.Fprintf(&b, " AND json_type(%s, ?) IN ('integer', 'real') AND json_extract(%s, ?) = ?", column, column)
+			args = append(args, path, path, v)
+		case string:
+			fmt.Fprintf(&b, " AND json_type(%s, ?) = 'text' AND json_extract(%s, ?) = ?", column, column)
+			args = append(args, path, path, v)
+		}
 	}
-	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+	return b.String(), args, nil
 }
 
 // sanitiseFTSQuery converts a free-form user query into a safe FTS5 MATCH
@@ -322,46 +453,59 @@ func sanitiseFTSQuery(query string) string {
 	// Split into lowercase words.
 	words := strings.Fields(strings.ToLower(cleaned))
 
-	// Filter stop words that carry no discriminative value.
-	stopWords := map[string]bool{
-		"a": true, "an": true, "the": true,
-		"is": true, "are": true, "was": true, "were": true, "be": true, "been": true, "being": true,
-		"have": true, "has": true, "had": true,
-		"do": true, "does": true, "did": true,
-		"will": true, "would": true, "could": true, "should": true,
-		"may": true, "might": true, "shall": true, "can": true,
-		"to": true, "of": true, "in": true, "on": true, "at": true,
-		"by": true, "for": true, "with": true, "from": true, "as": true,
-		"about": true, "into": true, "through": true, "during": true,
-		"before": true, "after": true, "above": true, "below": true,
-		"between": true, "out": true, "off": true, "over": true, "under": true,
-		"what": true, "how": true, "when": true, "where": true, "why": true,
-		"who": true, "which": true,
-		"this": true, "that": true, "these": true, "those": true,
-		"i": true, "you": true, "he": true, "she": true, "it": true, "we": true, "they": true,
-		"and": true, "or": true, "but": true, "if": true, "not": true,
-		"s": true, "t": true, // post-apostrophe fragments e.g. "MJ's" → "MJ" + "s"
-	}
-
+	// Each word is quoted so punctuation FTS5 does not allow in barewords
+	// ("node.js", "a,b") cannot cause a syntax error.
 	var terms []string
 	for _, w := range words {
-		if !stopWords[w] && len(w) >= 2 {
-			terms = append(terms, w+"*")
+		if !storage.IsStopWord(w) && len(w) >= 2 {
+			terms = append(terms, ftsString(w)+"*")
 		}
 	}
 
 	if len(terms) == 0 {
-		// All words were stop words — fall back to a lowercased form of the
-		// cleaned text so FTS5 does not interpret uppercase AND/OR/NOT as operators.
-		return strings.ToLower(strings.TrimSpace(cleaned))
+		// All words were stop words — match them all instead. Quoting also
+		// keeps FTS5 from treating them as AND/OR/NOT operators.
+		for _, w := range words {
+			terms = append(terms, ftsString(w))
+		}
+		return strings.Join(terms, " ")
 	}
 
 	return strings.Join(terms, " OR ")
 }
 
+// ftsMatchQuery renders tokens from storage.ParseSearchQuery as an FTS5
+// MATCH expression. FTS5 gives NOT, AND and OR the same precedence as the
+// documented search syntax, so no parentheses are needed.
+func ftsMatchQuery(tokens []storage.QueryToken) string {
+	parts := make([]string, 0, len(tokens))
+	for _, tok := range tokens {
+		switch tok.Kind {
+		case storage.QueryAnd:
+			parts = append(parts, "AND")
+		case storage.QueryOr:
+			parts = append(parts, "OR")
+		case storage.QueryNot:
+			parts = append(parts, "NOT")
+		default:
+			term := ftsString(strings.Join(tok.Words, " "))
+			if tok.Prefix {
+				term += "*"
+			}
+			parts = append(parts, term)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ftsString quotes s as an FTS5 string, which matches its words as a phrase.
+func ftsString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
 // scanMemories reads all rows returned by a query into a []types.Memory slice.
 // The SELECT column order must match the order used in FullTextSearch above,
-// which mirrors the order used in Get and List.
+// which mirrors the order used in Get and List followed by the snippet.
 func scanMemories(rows *sql.Rows) ([]types.Memory, error) {
 	var memories []types.Memory
 
@@ -375,6 +519,10 @@ func scanMemories(rows *sql.Rows) ([]types.Memory, error) {
 		var state, createdBy, sessionID sql.NullString
 		var sourceContextJSON sql.NullString
 		var stateUpdatedAt, lastAccessedAt, decayUpdatedAt sql.NullTime
+		var compressed bool
+		var expiresAt sql.NullTime
+		var authorType, summary, memoryType sql.NullString
+		var snippet sql.NullString
 
 		err := rows.Scan(
 			&memory.ID,
@@ -402,14 +550,34 @@ func scanMemories(rows *sql.Rows) ([]types.Memory, error) {
 			&lastAccessedAt,
 			&memory.DecayScore,
 			&decayUpdatedAt,
+			&compressed,
+			&expiresAt,
+			&authorType,
+			&summary,
+			&memoryType,
+			&snippet,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan memory row: %w", err)
 		}
+		memory.Snippet = snippet.String
+
+		if memory.Content, err = DecodeContent(memory.Content, compressed); err != nil {
+			return nil, err
+		}
 
 		if enrichmentError.Valid {
 			memory.EnrichmentError = enrichmentError.String
 		}
+		if expiresAt.Valid {
+			t := expiresAt.Time
+			memory.ExpiresAt = &t
+		}
+		if authorType.Valid {
+			memory.AuthorType = authorType.String
+		}
+		memory.Summary = summary.String
+		memory.MemoryType = memoryType.String
 		if err := unmarshalMemoryFields(
 			&memory,
 			metadataJSON, tagsJSON, sourceContextJSON,
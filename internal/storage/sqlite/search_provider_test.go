@@ -2,6 +2,9 @@ package sqlite
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -288,6 +291,7 @@ func TestFullTextSearch_ResultsHaveFullMemoryFields(t *testing.T) {
 		Timestamp: now,
 		Tags:      []string{"nasa", "iss"},
 		Status:    types.StatusPending,
+		Summary:   "Notes on an ISS spacewalk.",
 	}
 	mustStore(t, store, original)
 
@@ -316,6 +320,9 @@ func TestFullTextSearch_ResultsHaveFullMemoryFields(t *testing.T) {
 	if len(m.Tags) != 2 {
 		t.Errorf("Tags: got %v, want 2 tags", m.Tags)
 	}
+	if m.Summary != original.Summary {
+		t.Errorf("Summary: got %q, want %q", m.Summary, original.Summary)
+	}
 }
 
 // TestFullTextSearch_RankedByRelevance verifies that FTS5 ranks results so
@@ -371,6 +378,13 @@ func TestFullTextSearch_SpecialCharactersInQuery(t *testing.T) {
 		`AND OR NOT`,
 		`*prefix*`,
 		`term1 AND (term2 OR`,
+		`what's "normal" about it?`,
+		`"normal content" "here`,
+		`key:value normal:`,
+		`(normal) (content`,
+		`node.js, c++ & c#`,
+		`NEAR(normal content)`,
+		`content^2 col:"normal"`,
 	}
 
 	for _, q := range problemQueries {
@@ -381,6 +395,68 @@ func TestFullTextSearch_SpecialCharactersInQuery(t *testing.T) {
 	}
 }
 
+// TestFullTextSearch_QuerySyntax verifies the documented search syntax:
+// phrases, AND/OR/NOT and prefix terms.
+func TestFullTextSearch_QuerySyntax(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:syntax-1", Content: "The deployment pipeline uses blue green releases", Source: "test"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:syntax-2", Content: "Green tea and blue cheese for the team lunch", Source: "test"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:syntax-3", Content: "Deploy notes: rollback plan for the pipeline", Source: "test"})
+
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{`"blue green"`, []string{"mem:test:syntax-1"}},
+		{`"green blue"`, nil},
+		{`blue AND cheese`, []string{"mem:test:syntax-2"}},
+		{`"blue green" OR rollback`, []string{"mem:test:syntax-1", "mem:test:syntax-3"}},
+		{`pipeline NOT rollback`, []string{"mem:test:syntax-1"}},
+		{`blue NOT "blue green"`, []string{"mem:test:syntax-2"}},
+		{`pipe*`, []string{"mem:test:syntax-1", "mem:test:syntax-3"}},
+		{`pipe* AND notes`, []string{"mem:test:syntax-3"}},
+		{`"rollback plan:" pipeline`, []string{"mem:test:syntax-3"}},
+		{`NOT pipeline`, nil},
+	}
+	for _, tc := range cases {
+		result, err := store.FullTextSearch(ctx, storage.SearchOptions{Query: tc.query, Limit: 10})
+		if err != nil {
+			t.Errorf("FullTextSearch(%q): %v", tc.query, err)
+			continue
+		}
+		var got []string
+		for _, m := range result.Items {
+			got = append(got, m.ID)
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("FullTextSearch(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+		if result.Total != len(tc.want) {
+			t.Errorf("FullTextSearch(%q): Total = %d, want %d", tc.query, result.Total, len(tc.want))
+		}
+	}
+}
+
+// TestFullTextSearch_PunctuatedNaturalQuery verifies that a free-form query
+// whose words contain punctuation FTS5 rejects in barewords still matches.
+func TestFullTextSearch_PunctuatedNaturalQuery(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:punct-1", Content: "Upgraded node.js to version 22", Source: "test"})
+
+	result, err := store.FullTextSearch(ctx, storage.SearchOptions{Query: "node.js upgrade", Limit: 10})
+	if err != nil {
+		t.Fatalf("FullTextSearch(): %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "mem:test:punct-1" {
+		t.Errorf("FullTextSearch(%q): got %d results, want mem:test:punct-1", "node.js upgrade", len(result.Items))
+	}
+}
+
 // TestVectorSearch_EmptyEmbeddingsTable verifies that VectorSearch returns an
 // empty result (not an error) when no embeddings are stored yet.
 func TestVectorSearch_EmptyEmbeddingsTable(t *testing.T) {
@@ -399,6 +475,151 @@ func TestVectorSearch_EmptyEmbeddingsTable(t *testing.T) {
 	}
 }
 
+// storeEmbedded stores a memory with an embedding produced by model.
+func storeEmbedded(t *testing.T, store *MemoryStore, id, content string, vec []float64, model string) {
+	t.Helper()
+	mustStore(t, store, &types.Memory{ID: id, Content: content, Source: "test"})
+	if err := store.SetEmbedding(context.Background(), id, vec, model); err != nil {
+		t.Fatalf("SetEmbedding(%s) failed: %v", id, err)
+	}
+}
+
+// TestVectorSearch_DimensionMismatch verifies that a query embedding whose
+// dimension differs from every stored embedding is reported as
+// ErrDimensionMismatch instead of producing meaningless rankings.
+func TestVectorSearch_DimensionMismatch(t *testing.T) {
+	store := newTestStore(t)
+	storeEmbedded(t, store, "mem:test:old-model", "kestrel migration notes", []float64{1, 0, 0}, "old-model")
+
+	_, err := store.VectorSearch(context.Background(), []float64{1, 0, 0, 0}, storage.SearchOptions{Limit: 5})
+	if !errors.Is(err, storage.ErrDimensionMismatch) {
+		t.Fatalf("VectorSearch() error = %v, want ErrDimensionMismatch", err)
+	}
+}
+
+// TestVectorSearch_SkipsOtherDimensions verifies that while some memories are
+// re-embedded under a new model, only the comparable embeddings are ranked.
+func TestVectorSearch_SkipsOtherDimensions(t *testing.T) {
+	store := newTestStore(t)
+	storeEmbedded(t, store, "mem:test:old", "kestrel migration notes", []float64{1, 0, 0}, "old-model")
+	storeEmbedded(t, store, "mem:test:new", "kestrel nesting notes", []float64{1, 0, 0, 0}, "new-model")
+
+	result, err := store.VectorSearch(context.Background(), []float64{1, 0, 0, 0}, storage.SearchOptions{Limit: 5})
+	if err != nil {
+		t.Fatalf("VectorSearch() failed: %v", err)
+	}
+	if result.Total != 1 || result.Items[0].ID != "mem:test:new" {
+		t.Errorf("VectorSearch() = %+v, want only mem:test:new", result.Items)
+	}
+}
+
+// TestVectorSearch_OneEmbeddingPerMemory verifies that a memory embedded by
+// two models of the same dimension is ranked once, using the requested model
+// or, by default, its most recently stored embedding.
+func TestVectorSearch_OneEmbeddingPerMemory(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	storeEmbedded(t, store, "mem:test:a", "kestrel nesting notes", []float64{1, 0, 0}, "model-a")
+	storeEmbedded(t, store, "mem:test:b", "kestrel migration notes", []float64{0, 1, 0}, "model-a")
+	if _, err := store.db.Exec(`UPDATE embeddings SET updated_at = datetime('now', '-1 hour')`); err != nil {
+		t.Fatalf("failed to age embeddings: %v", err)
+	}
+	for id, vec := range map[string][]float64{"mem:test:a": {0, 1, 0}, "mem:test:b": {1, 0, 0}} {
+		if err := store.SetEmbedding(ctx, id, vec, "model-b"); err != nil {
+			t.Fatalf("SetEmbedding(%s) failed: %v", id, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		model string
+		want  string
+	}{
+		{"model-a", "mem:test:a"},
+		{"model-b", "mem:test:b"},
+		{"", "mem:test:b"}, // model-b is the newest embedding of both memories
+	} {
+		result, err := store.VectorSearch(ctx, []float64{1, 0, 0}, storage.SearchOptions{Limit: 5, EmbeddingModel: tc.model})
+		if err != nil {
+			t.Fatalf("VectorSearch(model %q) failed: %v", tc.model, err)
+		}
+		if result.Total != 2 || result.Items[0].ID != tc.want {
+			t.Errorf("VectorSearch(model %q) = %d results led by %s, want 2 led by %s", tc.model, result.Total, result.Items[0].ID, tc.want)
+		}
+	}
+}
+
+// TestHybridSearch_DimensionMismatchFallsBackToFTS verifies that HybridSearch
+// returns plain full-text results when the query embedding cannot be compared
+// with the stored embeddings.
+func TestHybridSearch_DimensionMismatchFallsBackToFTS(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	storeEmbedded(t, store, "mem:test:match", "kestrel migration notes", []float64{0, 0, 1}, "old-model")
+	storeEmbedded(t, store, "mem:test:other", "budget spreadsheet", []float64{1, 0, 0}, "old-model")
+
+	hybrid, err := store.HybridSearch(ctx, "kestrel", []float64{1, 0, 0, 0}, storage.SearchOptions{Limit: 5})
+	if err != nil {
+		t.Fatalf("HybridSearch() failed: %v", err)
+	}
+	fts, err := store.FullTextSearch(ctx, storage.SearchOptions{Query: "kestrel", Limit: 5})
+	if err != nil {
+		t.Fatalf("FullTextSearch() failed: %v", err)
+	}
+
+	if len(hybrid.Items) != 1 || hybrid.Items[0].ID != "mem:test:match" {
+		t.Errorf("HybridSearch() = %+v, want only the full-text match", hybrid.Items)
+	}
+	if hybrid.Total != fts.Total {
+		t.Errorf("HybridSearch() Total = %d, want the FTS total %d", hybrid.Total, fts.Total)
+	}
+}
+
+// TestHybridSearch_MinSimilarityDropsWeakMatches verifies that an unrelated
+// query returns nothing once weak vector matches are cut off, while keyword
+// matches survive the cutoff.
+func TestHybridSearch_MinSimilarityDropsWeakMatches(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	storeEmbedded(t, store, "mem:test:kestrel", "kestrel migration notes", []float64{1, 0, 0}, "test-model")
+	storeEmbedded(t, store, "mem:test:budget", "budget spreadsheet", []float64{0.8, 0.6, 0}, "test-model")
+
+	// "sourdough" matches no content and its vector is far from both.
+	unrelated := []float64{0.1, 0, 1}
+	loose, err := store.HybridSearch(ctx, "sourdough", unrelated, storage.SearchOptions{Limit: 5})
+	if err != nil {
+		t.Fatalf("HybridSearch() failed: %v", err)
+	}
+	if len(loose.Items) != 2 {
+		t.Fatalf("HybridSearch() without cutoff returned %d items, want 2", len(loose.Items))
+	}
+
+	strict, err := store.HybridSearch(ctx, "sourdough", unrelated, storage.SearchOptions{Limit: 5, MinSimilarity: 0.5})
+	if err != nil {
+		t.Fatalf("HybridSearch() failed: %v", err)
+	}
+	if len(strict.Items) != 0 || strict.Total != 0 {
+		t.Errorf("HybridSearch() with cutoff = %+v (total %d), want no results", strict.Items, strict.Total)
+	}
+
+	// A keyword match is kept even though its vector is below the cutoff.
+	keyword, err := store.HybridSearch(ctx, "kestrel", unrelated, storage.SearchOptions{Limit: 5, MinSimilarity: 0.5})
+	if err != nil {
+		t.Fatalf("HybridSearch() failed: %v", err)
+	}
+	if len(keyword.Items) != 1 || keyword.Items[0].ID != "mem:test:kestrel" {
+		t.Errorf("HybridSearch() = %+v, want only the keyword match", keyword.Items)
+	}
+
+	// Close vector matches pass the cutoff.
+	vec, err := store.VectorSearch(ctx, []float64{1, 0.1, 0}, storage.SearchOptions{Limit: 5, MinSimilarity: 0.8})
+	if err != nil {
+		t.Fatalf("VectorSearch() failed: %v", err)
+	}
+	if vec.Total != 2 {
+		t.Errorf("VectorSearch() Total = %d, want 2", vec.Total)
+	}
+}
+
 // TestFullTextSearch_HybridSearchDelegatesToFTS verifies that HybridSearch
 // falls back to FullTextSearch when no vector is provided.
 func TestFullTextSearch_HybridSearchDelegatesToFTS(t *testing.T) {
@@ -520,3 +741,162 @@ func TestFullTextSearch_FuzzyFallback_SingleTermNoRetry(t *testing.T) {
 		t.Errorf("Single term with FuzzyFallback: expected 0 results, got %d", len(result.Items))
 	}
 }
+
+// longMemoryContent returns a memory body of roughly 400 words with needle
+// placed in the middle.
+func longMemoryContent(needle string) string {
+	filler := strings.Repeat("the quarterly planning notes cover staffing budgets and timelines ", 30)
+	return filler + "we decided to adopt " + needle + " for the ingest pipeline " + filler
+}
+
+// TestFullTextSearch_SnippetHighlightsMatch verifies that full-text results
+// carry a short excerpt around the match with the query term marked.
+func TestFullTextSearch_SnippetHighlightsMatch(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	content := longMemoryContent("kestrel")
+	mustStore(t, store, &types.Memory{ID: "mem:test:long", Content: content, Source: "test"})
+
+	result, err := store.FullTextSearch(ctx, storage.SearchOptions{Query: "kestrel", Limit: 5})
+	if err != nil {
+		t.Fatalf("FullTextSearch() failed: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Fatalf("FullTextSearch() returned %d results, want 1", len(result.Items))
+	}
+
+	snippet := result.Items[0].Snippet
+	if !strings.Contains(snippet, "**kestrel**") {
+		t.Errorf("Snippet = %q, want the marked query term", snippet)
+	}
+	if len(snippet) >= len(content)/4 {
+		t.Errorf("Snippet length = %d, want it trimmed well below the %d-byte content", len(snippet), len(content))
+	}
+	if words := len(strings.Fields(snippet)); words > storage.SnippetMaxTokens {
+		t.Errorf("Snippet has %d words, want at most %d", words, storage.SnippetMaxTokens)
+	}
+	if result.Items[0].Content != content {
+		t.Error("Content was modified; the snippet must be returned separately")
+	}
+}
+
+// TestHybridSearch_KeepsFullTextSnippet verifies that RRF-merged results
+// retain the snippet from the full-text leg.
+func TestHybridSearch_KeepsFullTextSnippet(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	storeEmbedded(t, store, "mem:test:long", longMemoryContent("kestrel"), []float64{0, 0, 1}, "model")
+	storeEmbedded(t, store, "mem:test:other", "budget spreadsheet", []float64{1, 0, 0}, "model")
+
+	result, err := store.HybridSearch(ctx, "kestrel", []float64{1, 0, 0}, storage.SearchOptions{Limit: 5})
+	if err != nil {
+		t.Fatalf("HybridSearch() failed: %v", err)
+	}
+	for _, m := range result.Items {
+		switch m.ID {
+		case "mem:test:long":
+			if !strings.Contains(m.Snippet, "**kestrel**") {
+				t.Errorf("Snippet = %q, want the marked query term", m.Snippet)
+			}
+		case "mem:test:other":
+			if m.Snippet != "" {
+				t.Errorf("vector-only result has Snippet %q, want empty", m.Snippet)
+			}
+		}
+	}
+}
+
+// TestSearch_Tags verifies that full-text and vector search only return
+// memories carrying any, or all, of SearchOptions.Tags.
+func TestSearch_Tags(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	for id, tags := range map[string][]string{
+		"mem:test:none": nil,
+		"mem:test:x":    {"project-x"},
+		"mem:test:xy":   {"project-x", "urgent"},
+	} {
+		mustStore(t, store, &types.Memory{ID: id, Content: "heron deploy notes", Source: "test", Tags: tags})
+		if err := store.SetEmbedding(ctx, id, []float64{1, 0, 0}, "model"); err != nil {
+			t.Fatalf("SetEmbedding(%s) failed: %v", id, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		tags []string
+		all  bool
+		want []string
+	}{
+		{nil, false, []string{"mem:test:none", "mem:test:x", "mem:test:xy"}},
+		{[]string{"project-x"}, false, []string{"mem:test:x", "mem:test:xy"}},
+		{[]string{"urgent", "missing"}, false, []string{"mem:test:xy"}},
+		{[]string{"project-x", "urgent"}, true, []string{"mem:test:xy"}},
+		{[]string{"urgent", "missing"}, true, nil},
+	} {
+		opts := storage.SearchOptions{Query: "heron", Limit: 10, Tags: tc.tags, TagsMatchAll: tc.all}
+		fts, err := store.FullTextSearch(ctx, opts)
+		if err != nil {
+			t.Fatalf("FullTextSearch(tags %v) failed: %v", tc.tags, err)
+		}
+		vec, err := store.VectorSearch(ctx, []float64{1, 0, 0}, opts)
+		if err != nil {
+			t.Fatalf("VectorSearch(tags %v) failed: %v", tc.tags, err)
+		}
+		for name, result := range map[string]*storage.PaginatedResult[types.Memory]{"FullTextSearch": fts, "VectorSearch": vec} {
+			var got []string
+			for _, m := range result.Items {
+				got = append(got, m.ID)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") || result.Total != len(tc.want) {
+				t.Errorf("%s(tags %v, all %v) = %v (total %d), want %v", name, tc.tags, tc.all, got, result.Total, tc.want)
+			}
+		}
+	}
+}
+
+func TestSearch_Metadata(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	for id, metadata := range map[string]map[string]interface{}{
+		"mem:test:none":  nil,
+		"mem:test:atlas": {"project": "atlas", "priority": 2, "urgent": true},
+		"mem:test:zeus":  {"project": "zeus", "priority": "2"},
+	} {
+		mustStore(t, store, &types.Memory{ID: id, Content: "heron deploy notes", Source: "test", Metadata: metadata})
+		if err := store.SetEmbedding(ctx, id, []float64{1, 0, 0}, "model"); err != nil {
+			t.Fatalf("SetEmbedding(%s) failed: %v", id, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		metadata map[string]interface{}
+		want     []string
+	}{
+		{nil, []string{"mem:test:atlas", "mem:test:none", "mem:test:zeus"}},
+		{map[string]interface{}{"project": "atlas"}, []string{"mem:test:atlas"}},
+		{map[string]interface{}{"priority": 2}, []string{"mem:test:atlas"}},
+		{map[string]interface{}{"priority": "2"}, []string{"mem:test:zeus"}},
+		{map[string]interface{}{"project": "atlas", "urgent": false}, nil},
+	} {
+		opts := storage.SearchOptions{Query: "heron", Limit: 10, Metadata: tc.metadata}
+		fts, err := store.FullTextSearch(ctx, opts)
+		if err != nil {
+			t.Fatalf("FullTextSearch(metadata %v) failed: %v", tc.metadata, err)
+		}
+		vec, err := store.VectorSearch(ctx, []float64{1, 0, 0}, opts)
+		if err != nil {
+			t.Fatalf("VectorSearch(metadata %v) failed: %v", tc.metadata, err)
+		}
+		for name, result := range map[string]*storage.PaginatedResult[types.Memory]{"FullTextSearch": fts, "VectorSearch": vec} {
+			var got []string
+			for _, m := range result.Items {
+				got = append(got, m.ID)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") || result.Total != len(tc.want) {
+				t.Errorf("%s(metadata %v) = %v (total %d), want %v", name, tc.metadata, got, result.Total, tc.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// TestSession_SaveAndGet stores a labeled session, ends it, and checks both
+// states read back.
+func TestSession_SaveAndGet(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := store.GetSession(ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetSession(missing) error = %v, want ErrNotFound", err)
+	}
+
+	started := time.Now().UTC().Truncate(time.Second)
+	session := &storage.Session{ID: "sess-1", Label: "refactor auth module", StartedAt: started}
+	if err := store.SaveSession(ctx, session); err != nil {
+		t.Fatalf("SaveSession() failed: %v", err)
+	}
+	got, err := store.GetSession(ctx, "sess-1")
+	if err != nil {
+		t.Fatalf("GetSession() failed: %v", err)
+	}
+	if got.Label != session.Label || !got.StartedAt.Equal(started) || got.EndedAt != nil {
+		t.Fatalf("GetSession() = %+v, want open session %+v", got, session)
+	}
+
+	ended := started.Add(time.Hour)
+	session.EndedAt = &ended
+	if err := store.SaveSession(ctx, session); err != nil {
+		t.Fatalf("SaveSession(ended) failed: %v", err)
+	}
+	got, err = store.GetSession(ctx, "sess-1")
+	if err != nil {
+		t.Fatalf("GetSession() failed: %v", err)
+	}
+	if got.EndedAt == nil || !got.EndedAt.Equal(ended) {
+		t.Fatalf("EndedAt = %v, want %v", got.EndedAt, ended)
+	}
+}
@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// GetMemoryGraph returns the entities associated with memoryID, the
+// relationships among those entities, and every memory link that starts or
+// ends at memoryID. It implements storage.SnapshotStore.
+func (s *MemoryStore) GetMemoryGraph(ctx context.Context, memoryID string) (*storage.MemoryGraph, error) {
+	if memoryID == "" {
+		return nil, fmt.Errorf("%w: memory ID is required", storage.ErrInvalidInput)
+	}
+
+	graph := &storage.MemoryGraph{
+		Entities:      []storage.MemoryEntity{},
+		Relationships: []types.Relationship{},
+		Links:         []storage.MemoryLink{},
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT e.id, e.name, e.type, e.description, e.created_at, e.updated_at, me.frequency, me.confidence
+		FROM memory_entities me
+		JOIN entities e ON e.id = me.entity_id
+		WHERE me.memory_id = ?
+		ORDER BY e.name, e.id`, memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: GetMemoryGraph entities: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var me storage.MemoryEntity
+		var desc sql.NullString
+		if err := rows.Scan(&me.ID, &me.Name, &me.Type, &desc, &me.CreatedAt, &me.UpdatedAt, &me.Frequency, &me.Confidence); err != nil {
+			return nil, fmt.Errorf("sqlite: GetMemoryGraph entities scan: %w", err)
+		}
+		me.Description = desc.String
+		graph.Entities = append(graph.Entities, me)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: GetMemoryGraph entities rows: %w", err)
+	}
+
+	if len(graph.Entities) > 0 {
+		ids := make([]interface{}, len(graph.Entities))
+		for i, e := range graph.Entities {
+			ids[i] = e.ID
+		}
+		in := buildInClause(len(ids))
+		relRows, err := s.db.QueryContext(ctx, `
+			SELECT id, source_id, target_id, type, weight, created_at, updated_at
+			FROM relationships
+			WHERE source_id IN (`+in+`) AND target_id IN (`+in+`)
+			ORDER BY id`, append(ids, ids...)...)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite: GetMemoryGraph relationships: %w", err)
+		}
+		defer func() { _ = relRows.Close() }()
+		for relRows.Next() {
+			var rel types.Relationship
+			if err := relRows.Scan(&rel.ID, &rel.FromID, &rel.ToID, &rel.Type, &rel.Strength, &rel.CreatedAt, &rel.UpdatedAt); err != nil {
+				return nil, fmt.Errorf("sqlite: GetMemoryGraph relationships scan: %w", err)
+			}
+			graph.Relationships = append(graph.Relationships, rel)
+		}
+		if err := relRows.Err(); err != nil {
+			return nil, fmt.Errorf("sqlite: GetMemoryGraph relationships rows: %w", err)
+		}
+	}
+
+	linkRows, err := s.db.QueryContext(ctx, `
+		SELECT id, source_id, target_id, type, created_at
+		FROM memory_links
+		WHERE source_id = ? OR target_id = ?
+		ORDER BY created_at, id`, memoryID, memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: GetMemoryGraph links: %w", err)
+	}
+	defer func() { _ = linkRows.Close() }()
+	for linkRows.Next() {
+		var link storage.MemoryLink
+		if err := linkRows.Scan(&link.ID, &link.SourceID, &link.TargetID, &link.Type, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("sqlite: GetMemoryGraph links scan: %w", err)
+		}
+		graph.Links = append(graph.Links, link)
+	}
+	if err := linkRows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite: GetMemoryGraph links rows: %w", err)
+	}
+
+	return graph, nil
+}
+
+// RestoreMemoryGraph re-applies a graph captured by GetMemoryGraph in a
+// single transaction. The memory's entity associations are replaced with the
+// snapshot's; entities and relationships are upserted (an existing entity
+// keeps its description, since other memories share it); missing links are
+// recreated unless the memory at their other end no longer exists. It returns
+// the number of links recreated and implements storage.SnapshotStore.
+func (s *MemoryStore) RestoreMemoryGraph(ctx context.Context, memoryID string, graph *storage.MemoryGraph) (int, error) {
+	if memoryID == "" || graph == nil {
+		return 0, fmt.Errorf("%w: memory ID and graph are required", storage.ErrInvalidInput)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("sqlite: RestoreMemoryGraph: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM memory_entities WHERE memory_id = ?`, memoryID); err != nil {
+		return 0, fmt.Errorf("sqlite: RestoreMemoryGraph clear entities: %w", err)
+	}
+
+	now := time.Now()
+	// An entity may exist under a different ID (same name and type); map
+	// snapshot IDs to the stored ones so relationships line up.
+	entityIDs := make(map[string]string, len(graph.Entities))
+	for _, e := range graph.Entities {
+		var id string
+		if err := tx.QueryRowContext(ctx, `
+			INSERT INTO entities (id, name, type, description, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT(name, type) DO UPDATE SET name = excluded.name
+			RETURNING id`,
+			e.ID, e.Name, e.Type, e.Description, timeOr(e.CreatedAt, now), timeOr(e.UpdatedAt, now),
+		).Scan(&id); err != nil {
+			return 0, fmt.Errorf("sqlite: RestoreMemoryGraph entity %s: %w", e.ID, err)
+		}
+		entityIDs[e.ID] = id
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO memory_entities (memory_id, entity_id, frequency, confidence, created_at)
+			VALUES (?, ?, ?, ?, ?)`,
+			memoryID, id, max(e.Frequency, 1), e.Confidence, now,
+		); err != nil {
+			return 0, fmt.Errorf("sqlite: RestoreMemoryGraph link entity %s: %w", e.ID, err)
+		}
+	}
+
+	for _, rel := range graph.Relationships {
+		from, to := entityIDs[rel.FromID], entityIDs[rel.ToID]
+		if from == "" || to == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO relationships (id, source_id, target_id, type, weight, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(source_id, target_id, type) DO UPDATE SET
+				weight = excluded.weight,
+				updated_at = excluded.updated_at`,
+			rel.ID, from, to, rel.Type, rel.Strength, timeOr(rel.CreatedAt, now), now,
+		); err != nil {
+			return 0, fmt.Errorf("sqlite: RestoreMemoryGraph relationship %s: %w", rel.ID, err)
+		}
+	}
+
+	restored := 0
+	for _, link := range graph.Links {
+		result, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO memory_links (id, source_id, target_id, type, created_at)
+			SELECT ?, ?, ?, ?, ?
+			WHERE EXISTS (SELECT 1 FROM memories WHERE id = ?)
+			  AND EXISTS (SELECT 1 FROM memories WHERE id = ?)`,
+			link.ID, link.SourceID, link.TargetID, link.Type, timeOr(link.CreatedAt, now),
+			link.SourceID, link.TargetID,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("sqlite: RestoreMemoryGraph link %s: %w", link.ID, err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			restored += int(n)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("sqlite: RestoreMemoryGraph commit: %w", err)
+	}
+	return restored, nil
+}
+
+// timeOr returns t, or fallback when t is zero.
+func timeOr(t, fallback time.Time) time.Time {
+	if t.IsZero() {
+		return fallback
+	}
+	return t
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// TestMemoryGraph_RoundTrip captures the graph around a memory, wipes its
+// entity associations and links, and checks RestoreMemoryGraph rebuilds them.
+func TestMemoryGraph_RoundTrip(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"mem:test:a", "mem:test:b", "mem:test:c"} {
+		mustStore(t, store, &types.Memory{ID: id, Content: "snapshot fixture " + id, Source: "test"})
+	}
+	insertEntity(t, store, "ent:alice", "alice", types.EntityTypePerson)
+	insertEntity(t, store, "ent:acme", "acme", types.EntityTypeOrganization)
+	insertRelationship(t, store, "rel:works-at", "ent:alice", "ent:acme", "works_at")
+	linkMemoryEntity(t, store, "mem:test:a", "ent:alice")
+	linkMemoryEntity(t, store, "mem:test:a", "ent:acme")
+	if err := store.CreateMemoryLink(ctx, "link:ab", "mem:test:a", "mem:test:b", "CONTAINS"); err != nil {
+		t.Fatalf("CreateMemoryLink() failed: %v", err)
+	}
+	if err := store.CreateMemoryLink(ctx, "link:ca", "mem:test:c", "mem:test:a", "CONTAINS"); err != nil {
+		t.Fatalf("CreateMemoryLink() failed: %v", err)
+	}
+
+	graph, err := store.GetMemoryGraph(ctx, "mem:test:a")
+	if err != nil {
+		t.Fatalf("GetMemoryGraph() failed: %v", err)
+	}
+	if len(graph.Entities) != 2 || len(graph.Relationships) != 1 || len(graph.Links) != 2 {
+		t.Fatalf("graph = %d entities, %d relationships, %d links; want 2, 1, 2",
+			len(graph.Entities), len(graph.Relationships), len(graph.Links))
+	}
+
+	db := store.GetDB()
+	for _, stmt := range []string{
+		`DELETE FROM memory_entities WHERE memory_id = 'mem:test:a'`,
+		`DELETE FROM relationships`,
+		`DELETE FROM memory_links`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	restored, err := store.RestoreMemoryGraph(ctx, "mem:test:a", graph)
+	if err != nil {
+		t.Fatalf("RestoreMemoryGraph() failed: %v", err)
+	}
+	if restored != 2 {
+		t.Errorf("RestoreMemoryGraph() recreated %d links, want 2", restored)
+	}
+
+	after, err := store.GetMemoryGraph(ctx, "mem:test:a")
+	if err != nil {
+		t.Fatalf("GetMemoryGraph() after restore failed: %v", err)
+	}
+	if len(after.Entities) != 2 || len(after.Relationships) != 1 || len(after.Links) != 2 {
+		t.Fatalf("restored graph = %d entities, %d relationships, %d links; want 2, 1, 2",
+			len(after.Entities), len(after.Relationships), len(after.Links))
+	}
+	if rel := after.Relationships[0]; rel.FromID != "ent:alice" || rel.ToID != "ent:acme" || rel.Type != "works_at" {
+		t.Errorf("restored relationship = %s -[%s]-> %s", rel.FromID, rel.Type, rel.ToID)
+	}
+
+	// Restoring again is a no-op for links that already exist.
+	restored, err = store.RestoreMemoryGraph(ctx, "mem:test:a", graph)
+	if err != nil {
+		t.Fatalf("second RestoreMemoryGraph() failed: %v", err)
+	}
+	if restored != 0 {
+		t.Errorf("second RestoreMemoryGraph() recreated %d links, want 0", restored)
+	}
+}
+
+// TestRestoreMemoryGraph_SkipsLinksToMissingMemories verifies a link whose
+// other end has been purged is dropped rather than left dangling.
+func TestRestoreMemoryGraph_SkipsLinksToMissingMemories(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:a", Content: "kept", Source: "test"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:gone", Content: "purged", Source: "test"})
+	if err := store.CreateMemoryLink(ctx, "link:a-gone", "mem:test:a", "mem:test:gone", "CONTAINS"); err != nil {
+		t.Fatalf("CreateMemoryLink() failed: %v", err)
+	}
+
+	graph, err := store.GetMemoryGraph(ctx, "mem:test:a")
+	if err != nil {
+		t.Fatalf("GetMemoryGraph() failed: %v", err)
+	}
+
+	db := store.GetDB()
+	if _, err := db.ExecContext(ctx, `DELETE FROM memory_links`); err != nil {
+		t.Fatalf("clear links: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM memories WHERE id = 'mem:test:gone'`); err != nil {
+		t.Fatalf("purge memory: %v", err)
+	}
+
+	restored, err := store.RestoreMemoryGraph(ctx, "mem:test:a", graph)
+	if err != nil {
+		t.Fatalf("RestoreMemoryGraph() failed: %v", err)
+	}
+	if restored != 0 {
+		t.Errorf("RestoreMemoryGraph() recreated %d links, want 0", restored)
+	}
+}
@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// TestSoftDelete_EvolutionChainKeepsDeletedVersions deletes the middle of a
+// three-version chain: the chain stays connected through it, with the
+// deleted version marked by DeletedAt.
+func TestSoftDelete_EvolutionChainKeepsDeletedVersions(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:v1", Content: "Version 1", Source: "test"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:v2", Content: "Version 2", Source: "test", SupersedesID: "mem:test:v1"})
+	mustStore(t, store, &types.Memory{ID: "mem:test:v3", Content: "Version 3", Source: "test", SupersedesID: "mem:test:v2"})
+	if err := store.Delete(ctx, "mem:test:v2"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+
+	for _, from := range []string{"mem:test:v1", "mem:test:v3"} {
+		chain, err := store.GetEvolutionChain(ctx, from)
+		if err != nil {
+			t.Fatalf("GetEvolutionChain(%s) failed: %v", from, err)
+		}
+		var ids []string
+		for _, m := range chain {
+			ids = append(ids, m.ID)
+		}
+		if want := []string{"mem:test:v1", "mem:test:v2", "mem:test:v3"}; !reflect.DeepEqual(ids, want) {
+			t.Fatalf("GetEvolutionChain(%s) = %v, want %v", from, ids, want)
+		}
+		if chain[0].DeletedAt != nil || chain[1].DeletedAt == nil || chain[2].DeletedAt != nil {
+			t.Errorf("GetEvolutionChain(%s): want only v2 marked deleted", from)
+		}
+	}
+}
+
+// TestSoftDelete_HidesGraphNeighbors checks that a soft-deleted memory drops
+// out of traversal, related-memory and link queries, from either side, and
+// comes back when restored.
+//
+//	start ─── E1 ─── neighbor
+//	  └─ DEPENDS_ON ─┘
+func TestSoftDelete_HidesGraphNeighbors(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	storeTestMemory(t, s, "mem:test:start", "Start")
+	storeTestMemory(t, s, "mem:test:neighbor", "Neighbor")
+	insertEntity(t, s, "ent:test-e1", "Alice", "person")
+	linkMemoryEntity(t, s, "mem:test:start", "ent:test-e1")
+	linkMemoryEntity(t, s, "mem:test:neighbor", "ent:test-e1")
+	if err := s.CreateMemoryLink(ctx, "link:depends", "mem:test:start", "mem:test:neighbor", "DEPENDS_ON"); err != nil {
+		t.Fatalf("CreateMemoryLink() failed: %v", err)
+	}
+
+	neighbors := func(from string) (traversed, related []string, links int) {
+		t.Helper()
+		results, err := s.Traverse(ctx, from, storage.TraversalOptions{MaxHops: 1, Limit: 10})
+		if err != nil {
+			t.Fatalf("Traverse(%s) failed: %v", from, err)
+		}
+		for _, r := range results {
+			traversed = append(traversed, r.Memory.ID)
+		}
+		if related, err = s.GetRelatedMemories(ctx, from); err != nil {
+			t.Fatalf("GetRelatedMemories(%s) failed: %v", from, err)
+		}
+		linkList, err := s.ListMemoryLinks(ctx, from)
+		if err != nil {
+			t.Fatalf("ListMemoryLinks(%s) failed: %v", from, err)
+		}
+		return traversed, related, len(linkList)
+	}
+	assertNeighbors := func(label, from string, want []string) {
+		t.Helper()
+		traversed, related, links := neighbors(from)
+		if !reflect.DeepEqual(traversed, want) || !reflect.DeepEqual(related, want) || links != len(want) {
+			t.Errorf("%s: from %s traversed %v, related %v, %d links; want %v", label, from, traversed, related, links, want)
+		}
+	}
+
+	assertNeighbors("before delete", "mem:test:start", []string{"mem:test:neighbor"})
+
+	if err := s.Delete(ctx, "mem:test:neighbor"); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	assertNeighbors("neighbor deleted", "mem:test:start", nil)
+	assertNeighbors("neighbor deleted", "mem:test:neighbor", nil)
+
+	if err := s.Restore(ctx, "mem:test:neighbor"); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	assertNeighbors("after restore", "mem:test:start", []string{"mem:test:neighbor"})
+	assertNeighbors("after restore", "mem:test:neighbor", []string{"mem:test:start"})
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scrypster/memento/internal/storage"
+)
+
+// UpdateStates transitions each memory in ids to state in one transaction.
+// See storage.StateBatchUpdater.
+func (s *MemoryStore) UpdateStates(ctx context.Context, ids []string, state string) ([]storage.StateUpdate, error) {
+	if !s.stateMachine.IsValidState(state) {
+		return nil, fmt.Errorf("%w: invalid state: %s", storage.ErrInvalidInput, state)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: UpdateStates: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	updates := make([]storage.StateUpdate, 0, len(ids))
+	for _, id := range ids {
+		update := storage.StateUpdate{ID: id}
+
+		var current sql.NullString
+		err := tx.QueryRowContext(ctx,
+			`SELECT state FROM memories WHERE id = ? AND deleted_at IS NULL`, id,
+		).Scan(&current)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			update.Err = storage.ErrNotFound
+		case err != nil:
+			return nil, fmt.Errorf("sqlite: UpdateStates get %s: %w", id, err)
+		case !s.stateMachine.CanTransition(current.String, state):
+			update.PreviousState = current.String
+			update.Err = fmt.Errorf("invalid state transition: cannot transition from '%s' to '%s'", current.String, state)
+		default:
+			update.PreviousState = current.String
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE memories SET state = ?, state_updated_at = ?, updated_at = ? WHERE id = ?`,
+				state, now, now, id,
+			); err != nil {
+				return nil, fmt.Errorf("sqlite: UpdateStates update %s: %w", id, err)
+			}
+			if err := recordStateTransition(ctx, tx, id, current.String, state, now); err != nil {
+				return nil, fmt.Errorf("sqlite: UpdateStates history %s: %w", id, err)
+			}
+		}
+		updates = append(updates, update)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("sqlite: UpdateStates commit: %w", err)
+	}
+	return updates, nil
+}
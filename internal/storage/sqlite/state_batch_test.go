@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func TestUpdateStates(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:active", Content: "active task", Source: "test", State: types.StateActive})
+	mustStore(t, store, &types.Memory{ID: "mem:test:archived", Content: "archived task", Source: "test", State: types.StateArchived})
+
+	updates, err := store.UpdateStates(ctx, []string{"mem:test:active", "mem:test:archived", "mem:test:missing"}, types.StateCompleted)
+	if err != nil {
+		t.Fatalf("UpdateStates() failed: %v", err)
+	}
+	if len(updates) != 3 {
+		t.Fatalf("got %d updates, want 3", len(updates))
+	}
+	if u := updates[0]; u.Err != nil || u.PreviousState != types.StateActive {
+		t.Errorf("active: got %+v, want success from active", u)
+	}
+	if u := updates[1]; u.Err == nil || u.PreviousState != types.StateArchived {
+		t.Errorf("archived: got %+v, want a rejected transition from archived", u)
+	}
+	if u := updates[2]; !errors.Is(u.Err, storage.ErrNotFound) {
+		t.Errorf("missing: got %+v, want ErrNotFound", u)
+	}
+
+	for id, want := range map[string]string{"mem:test:active": types.StateCompleted, "mem:test:archived": types.StateArchived} {
+		mem, err := store.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", id, err)
+		}
+		if mem.State != want {
+			t.Errorf("%s: state = %q, want %q", id, mem.State, want)
+		}
+	}
+
+	if _, err := store.UpdateStates(ctx, []string{"mem:test:active"}, "finished"); !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("UpdateStates() with an unknown state = %v, want ErrInvalidInput", err)
+	}
+}
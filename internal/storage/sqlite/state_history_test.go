@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// TestStateHistory_RecordsTransitions checks that UpdateState and
+// UpdateStates each write one history row, attributed to the context's
+// actor, and that rejected transitions write none.
+func TestStateHistory_RecordsTransitions(t *testing.T) {
+	store := newTestStore(t)
+	ctx := storage.WithActor(context.Background(), "planner-bot")
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:task", Content: "Ship the release", Source: "test", Status: types.StatusEnriched})
+
+	if err := store.UpdateState(ctx, "mem:test:task", types.StatePlanning); err != nil {
+		t.Fatalf("UpdateState(planning) failed: %v", err)
+	}
+	if err := store.UpdateState(ctx, "mem:test:task", types.StateActive); err != nil {
+		t.Fatalf("UpdateState(active) failed: %v", err)
+	}
+	if err := store.UpdateState(ctx, "mem:test:task", types.StatePlanning); err == nil {
+		t.Fatal("UpdateState(active -> planning) succeeded, want invalid transition")
+	}
+	if _, err := store.UpdateStates(context.Background(), []string{"mem:test:task"}, types.StateCompleted); err != nil {
+		t.Fatalf("UpdateStates(completed) failed: %v", err)
+	}
+
+	history, err := store.GetStateHistory(context.Background(), "mem:test:task")
+	if err != nil {
+		t.Fatalf("GetStateHistory() failed: %v", err)
+	}
+	want := []struct{ from, to, by string }{
+		{"", types.StatePlanning, "planner-bot"},
+		{types.StatePlanning, types.StateActive, "planner-bot"},
+		{types.StateActive, types.StateCompleted, ""},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("GetStateHistory() returned %d transitions, want %d: %+v", len(history), len(want), history)
+	}
+	for i, w := range want {
+		got := history[i]
+		if got.MemoryID != "mem:test:task" || got.From != w.from || got.To != w.to || got.ChangedBy != w.by {
+			t.Errorf("transition %d = %+v, want %s -> %s by %q", i, got, w.from, w.to, w.by)
+		}
+		if got.ChangedAt.IsZero() {
+			t.Errorf("transition %d has no timestamp", i)
+		}
+		if i > 0 && got.ChangedAt.Before(history[i-1].ChangedAt) {
+			t.Errorf("transition %d precedes transition %d", i, i-1)
+		}
+	}
+}
+
+// TestStateHistory_RecordsArchive checks that ArchiveMemories writes one
+// history row per memory it archives and skips archived and missing ones.
+func TestStateHistory_RecordsArchive(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:active", Content: "Active notes", Source: "test", State: types.StateActive})
+	mustStore(t, store, &types.Memory{ID: "mem:test:unset", Content: "Notes without a state", Source: "test"})
+
+	n, err := store.ArchiveMemories(ctx, []string{"mem:test:active", "mem:test:unset", "mem:test:missing"})
+	if err != nil {
+		t.Fatalf("ArchiveMemories() failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("ArchiveMemories() = %d, want 2", n)
+	}
+	if n, err := store.ArchiveMemories(ctx, []string{"mem:test:active"}); err != nil || n != 0 {
+		t.Errorf("ArchiveMemories(already archived) = %d, %v; want 0, nil", n, err)
+	}
+
+	for id, from := range map[string]string{"mem:test:active": types.StateActive, "mem:test:unset": ""} {
+		history, err := store.GetStateHistory(ctx, id)
+		if err != nil {
+			t.Fatalf("GetStateHistory(%s) failed: %v", id, err)
+		}
+		if len(history) != 1 || history[0].From != from || history[0].To != types.StateArchived {
+			t.Errorf("GetStateHistory(%s) = %+v, want one %q -> archived transition", id, history, from)
+		}
+	}
+}
+
+// TestStateHistory_PurgeRemovesHistory checks that the history of a purged
+// memory goes with it.
+func TestStateHistory_PurgeRemovesHistory(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:gone", Content: "Temporary task", Source: "test", Status: types.StatusEnriched})
+	if err := store.UpdateState(ctx, "mem:test:gone", types.StatePlanning); err != nil {
+		t.Fatalf("UpdateState() failed: %v", err)
+	}
+	if err := store.Purge(ctx, "mem:test:gone"); err != nil {
+		t.Fatalf("Purge() failed: %v", err)
+	}
+
+	history, err := store.GetStateHistory(ctx, "mem:test:gone")
+	if err != nil {
+		t.Fatalf("GetStateHistory() failed: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("GetStateHistory() after purge = %+v, want none", history)
+	}
+}
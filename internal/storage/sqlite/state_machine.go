@@ -0,0 +1,18 @@
+package sqlite
+
+import "github.com/scrypster/memento/pkg/types"
+
+// WithStateMachine makes UpdateState and UpdateStates validate lifecycle
+// states and transitions against m instead of the built-in machine. nil
+// keeps the built-in one.
+func WithStateMachine(m *types.StateMachine) Option {
+	return func(s *MemoryStore) {
+		s.stateMachine = m
+	}
+}
+
+// StateMachine returns the lifecycle state machine the store validates
+// against, nil for the built-in one. See storage.StateMachineProvider.
+func (s *MemoryStore) StateMachine() *types.StateMachine {
+	return s.stateMachine
+}
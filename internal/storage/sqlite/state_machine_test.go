@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/scrypster/memento/internal/storage"
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func TestWithStateMachine(t *testing.T) {
+	machine := &types.StateMachine{
+		States:      []string{"draft", "review", "published"},
+		Initial:     []string{"draft"},
+		Transitions: map[string][]string{"draft": {"review"}, "review": {"draft", "published"}},
+	}
+	store, err := NewMemoryStore(":memory:", WithStateMachine(machine))
+	if err != nil {
+		t.Fatalf("NewMemoryStore() failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	mustStore(t, store, &types.Memory{ID: "mem:test:post", Content: "blog post", Source: "test"})
+	for _, state := range []string{"draft", "review"} {
+		if err := store.UpdateState(ctx, "mem:test:post", state); err != nil {
+			t.Fatalf("UpdateState(%s) failed: %v", state, err)
+		}
+	}
+	if err := store.UpdateState(ctx, "mem:test:post", types.StateArchived); !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("UpdateState(archived) error = %v, want ErrInvalidInput for a state outside the machine", err)
+	}
+
+	updates, err := store.UpdateStates(ctx, []string{"mem:test:post"}, "published")
+	if err != nil {
+		t.Fatalf("UpdateStates() failed: %v", err)
+	}
+	if u := updates[0]; u.Err != nil || u.PreviousState != "review" {
+		t.Errorf("UpdateStates() = %+v, want success from review", u)
+	}
+	if err := store.UpdateState(ctx, "mem:test:post", "draft"); err == nil {
+		t.Error("expected published to be terminal")
+	}
+
+	invalid := &types.StateMachine{States: []string{"draft"}, Initial: []string{"draft"}, Transitions: map[string][]string{"draft": {"gone"}}}
+	if _, err := NewMemoryStore(":memory:", WithStateMachine(invalid)); !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("NewMemoryStore() error = %v, want ErrInvalidInput for a dangling transition", err)
+	}
+}
@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"context"
 	"errors"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/scrypster/memento/pkg/types"
@@ -16,6 +19,11 @@ var (
 
 	// ErrGraphBoundsExceeded indicates that graph traversal exceeded bounds.
 	ErrGraphBoundsExceeded = errors.New("graph bounds exceeded")
+
+	// ErrDimensionMismatch indicates that a query embedding cannot be
+	// compared with the stored embeddings because their dimensions differ,
+	// typically after switching embedding models without re-embedding.
+	ErrDimensionMismatch = errors.New("embedding dimension mismatch")
 )
 
 // PaginatedResult represents a paginated result set with type safety using generics.
@@ -62,6 +70,10 @@ type ListOptions struct {
 	// Empty string means no filter on created_by.
 	CreatedBy string
 
+	// AuthorType filters by authorship origin ("human", "agent" or "system").
+	// Empty string means no filter on author_type.
+	AuthorType string
+
 	// CreatedAfter filters to memories created strictly after this time.
 	// Zero value means no lower bound.
 	CreatedAfter time.Time
@@ -70,14 +82,37 @@ type ListOptions struct {
 	// Zero value means no upper bound.
 	CreatedBefore time.Time
 
+	// AccessedAfter filters to memories last accessed strictly after this
+	// time. Memories never accessed are excluded. Zero value means no lower
+	// bound.
+	AccessedAfter time.Time
+
+	// AccessedBefore filters to memories last accessed strictly before this
+	// time. Memories never accessed are excluded. Zero value means no upper
+	// bound.
+	AccessedBefore time.Time
+
 	// MinDecayScore filters to memories with a decay_score >= this value.
 	// Zero value means no minimum score filter.
 	MinDecayScore float64
 
+	// MaxDecayScore filters to memories with a decay_score <= this value.
+	// Zero value means no maximum score filter.
+	MaxDecayScore float64
+
 	// SessionID filters to memories that belong to a specific session.
 	// Empty string means no filter on session_id.
 	SessionID string
 
+	// EntityIDs filters to memories linked to at least one of these
+	// entities through memory_entities. Empty means no filter on entities.
+	EntityIDs []string
+
+	// Metadata filters to memories whose metadata holds every key with an
+	// equal string, number or boolean value (see NormalizeMetadataFilter).
+	// Empty means no filter on metadata.
+	Metadata map[string]interface{}
+
 	// IncludeDeleted includes soft-deleted memories in results.
 	// By default (false), soft-deleted memories are excluded from all queries.
 	IncludeDeleted bool
@@ -90,21 +125,37 @@ type ListOptions struct {
 	// MemoryType filters memories by their memory_type classification value
 	// (e.g. "project", "epic", "task"). Empty string means no filter.
 	MemoryType string
+
+	// IncludeExpired includes memories whose expires_at has passed but which
+	// the expiry sweeper has not yet soft-deleted. Useful for auditing.
+	IncludeExpired bool
+}
+
+// Page-size bounds applied by ListOptions and SearchOptions when normalized.
+const (
+	DefaultLimit = 10  // Limit used when none is given
+	MaxLimit     = 100 // Largest Limit a single query may request
+)
+
+// SortFields lists the columns ListOptions.SortBy may name. Normalize
+// replaces anything else with created_at, so SortBy never reaches SQL
+// unchecked.
+var SortFields = []string{"created_at", "updated_at", "id", "status", "decay_score", "access_count"}
+
+// IsSortField reports whether field is one of SortFields.
+func IsSortField(field string) bool {
+	for _, f := range SortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
 }
 
 // Normalize applies defaults and validates the ListOptions.
 func (o *ListOptions) Normalize() {
 	// Whitelist validation for SortBy to prevent SQL injection
-	allowedSortFields := map[string]bool{
-		"created_at":   true,
-		"updated_at":   true,
-		"id":           true,
-		"status":       true,
-		"decay_score":  true,
-		"access_count": true,
-	}
-
-	if !allowedSortFields[o.SortBy] {
+	if !IsSortField(o.SortBy) {
 		o.SortBy = "created_at" // Default sort field
 	}
 
@@ -117,11 +168,11 @@ func (o *ListOptions) Normalize() {
 	}
 
 	if o.Limit < 1 {
-		o.Limit = 10 // Default limit
+		o.Limit = DefaultLimit
 	}
 
-	if o.Limit > 100 {
-		o.Limit = 100 // Max limit
+	if o.Limit > MaxLimit {
+		o.Limit = MaxLimit
 	}
 
 	if o.Filter == nil {
@@ -134,6 +185,18 @@ func (o *ListOptions) Offset() int {
 	return (o.Page - 1) * o.Limit
 }
 
+// Full-text search snippets mark matched terms with SnippetStartSel and
+// SnippetStopSel and are bounded so a long memory yields a short excerpt.
+// SQLite's snippet() always returns a single fragment; PostgreSQL's
+// ts_headline joins up to SnippetMaxFragments with SnippetEllipsis.
+const (
+	SnippetStartSel     = "**"
+	SnippetStopSel      = "**"
+	SnippetEllipsis     = "…"
+	SnippetMaxTokens    = 24
+	SnippetMaxFragments = 2
+)
+
 // SearchOptions provides options for search operations.
 type SearchOptions struct {
 	// Query is the search query string.
@@ -155,16 +218,88 @@ type SearchOptions struct {
 	// When true and the initial search returns zero results, the query will be split
 	// into individual terms and searched with OR semantics instead of AND.
 	FuzzyFallback bool
+
+	// IncludeExpired includes memories whose expires_at has passed.
+	// By default (false), expired memories are excluded from results.
+	IncludeExpired bool
+
+	// IncludeArchived includes memories in the archived state.
+	// By default (false), archived memories are excluded from results.
+	IncludeArchived bool
+
+	// EmbeddingModel restricts vector search to embeddings from this model.
+	// When empty, each memory's most recently stored embedding is used.
+	EmbeddingModel string
+
+	// MinSimilarity drops vector search candidates whose cosine similarity
+	// to the query is below this value, so weak semantic matches do not
+	// reach hybrid ranking. Full-text matches are unaffected. 0 disables
+	// the cutoff.
+	MinSimilarity float64
+
+	// Fuzzy makes full-text search tolerate typos: when the first page of
+	// exact matches has fewer than Limit results, memories containing words
+	// similar to the query's ("postgress" for "postgres") are appended after
+	// them. Queries using the search syntax (see ParseSearchQuery) are not
+	// fuzzy-matched.
+	Fuzzy bool
+
+	// FuzzyThreshold is the minimum trigram similarity, between 0 and 1, a
+	// stored word needs to a query word to count as a fuzzy match
+	// (default: DefaultFuzzyThreshold).
+	FuzzyThreshold float64
+
+	// FuzzyMaxResults caps how many fuzzy matches are appended
+	// (default: DefaultFuzzyMaxResults).
+	FuzzyMaxResults int
+
+	// Tags restricts full-text and vector matches to memories carrying at
+	// least one of these tags, or every one of them when TagsMatchAll is
+	// set. Fuzzy matches and the empty-query listing are not restricted, so
+	// callers needing an exact tag constraint filter those themselves.
+	Tags []string
+
+	// TagsMatchAll requires every tag in Tags rather than any of them.
+	TagsMatchAll bool
+
+	// Metadata restricts full-text and vector matches, and the empty-query
+	// listing, to memories whose metadata holds every key with an equal
+	// value, as ListOptions.Metadata does. Fuzzy matches are not restricted.
+	Metadata map[string]interface{}
+}
+
+// Fuzzy matching defaults applied by SearchOptions when normalized.
+const (
+	DefaultFuzzyThreshold  = 0.5
+	DefaultFuzzyMaxResults = 10
+)
+
+// StaleCriteria selects memories for automatic archival. A memory is stale
+// when it matches every criterion.
+type StaleCriteria struct {
+	// MaxDecayScore matches memories whose decay_score is below this value.
+	MaxDecayScore float64
+
+	// AccessedBefore matches memories last accessed before this instant, or
+	// created before it if they were never accessed.
+	AccessedBefore time.Time
+
+	// MaxAccessCount matches memories accessed at most this many times.
+	// Negative disables the check.
+	MaxAccessCount int
+
+	// Limit caps how many IDs are returned; 0 means no limit.
+	Limit int
 }
 
 // Normalize applies defaults and validates the SearchOptions.
 func (o *SearchOptions) Normalize() {
 	if o.Limit < 1 {
-		o.Limit = 10
+		o.Limit = DefaultLimit
 	}
 
-	if o.Limit > 100 {
-		o.Limit = 100
+	if o.Limit > MaxLimit {
+		o.Limit = MaxLimit
 	}
 
 	if o.Offset < 0 {
@@ -179,6 +314,18 @@ func (o *SearchOptions) Normalize() {
 		o.MinScore = 1.0
 	}
 
+	if o.FuzzyThreshold <= 0 {
+		o.FuzzyThreshold = DefaultFuzzyThreshold
+	}
+
+	if o.FuzzyThreshold > 1.0 {
+		o.FuzzyThreshold = 1.0
+	}
+
+	if o.FuzzyMaxResults < 1 {
+		o.FuzzyMaxResults = DefaultFuzzyMaxResults
+	}
+
 	if o.Filter == nil {
 		o.Filter = make(map[string]interface{})
 	}
@@ -282,6 +429,145 @@ type GraphEdge struct {
 	Weight float64
 }
 
+// EntityListOptions provides pagination and filtering for ListEntities.
+type EntityListOptions struct {
+	// Type filters by entity type (e.g. "person", "organization").
+	// Empty string means no filter on type.
+	Type string
+
+	// Name filters to entities with this name, ignoring case.
+	// Empty string means no filter on name.
+	Name string
+
+	// Page is the page number to retrieve (1-indexed, default: 1).
+	Page int
+
+	// Limit is the number of items per page (default: 20, max: 100).
+	Limit int
+}
+
+// Normalize applies defaults and bounds to the EntityListOptions.
+func (o *EntityListOptions) Normalize() {
+	if o.Page < 1 {
+		o.Page = 1
+	}
+	if o.Limit < 1 {
+		o.Limit = 20
+	}
+	if o.Limit > 100 {
+		o.Limit = 100
+	}
+}
+
+// Offset calculates the offset for SQL queries based on page and limit.
+func (o *EntityListOptions) Offset() int {
+	return (o.Page - 1) * o.Limit
+}
+
+// TraversalOptions controls how Traverse walks the entity relationship graph.
+type TraversalOptions struct {
+	// MaxHops is the number of relationship hops to follow (default: 2).
+	MaxHops int
+
+	// Limit is the maximum number of memories to return (default: 10).
+	Limit int
+
+	// RelationshipTypes restricts traversal to relationships of these types
+	// (case-insensitive). Empty means every type is followed.
+	RelationshipTypes []string
+
+	// ExcludeTypes lists relationship types (case-insensitive) that are never
+	// followed. It is applied after RelationshipTypes.
+	ExcludeTypes []string
+
+	// Directed follows relationships only from source to target. By default
+	// relationships are followed in both directions.
+	Directed bool
+
+	// Weights balances proximity, entity overlap and decay in each result's
+	// relevance score. The zero value uses DefaultTraversalWeights.
+	Weights TraversalWeights
+
+	// MaxBreadth caps how many memories each frontier entity contributes per
+	// hop. When a hub entity is linked to more memories, only the MaxBreadth
+	// with the highest decay score are taken, which bounds the cost of a
+	// traversal (default: DefaultTraversalBreadth).
+	MaxBreadth int
+}
+
+// DefaultTraversalBreadth is the TraversalOptions.MaxBreadth used when none
+// is given.
+const DefaultTraversalBreadth = 100
+
+// TraversalWeights are the relative weights of the components of a
+// TraversalResult's relevance score. Only their ratios matter; negative
+// weights are treated as zero.
+type TraversalWeights struct {
+	// Proximity rewards fewer hops from the start memory (1/hops).
+	Proximity float64
+
+	// Overlap rewards sharing more entities with the traversal path
+	// (n/(n+1) for n shared entities).
+	Overlap float64
+
+	// Decay rewards memories with a higher decay score.
+	Decay float64
+}
+
+// DefaultTraversalWeights is used when TraversalOptions.Weights is zero.
+var DefaultTraversalWeights = TraversalWeights{Proximity: 0.5, Overlap: 0.3, Decay: 0.2}
+
+// Score returns the relevance score of r in [0, 1].
+func (w TraversalWeights) Score(r TraversalResult) float64 {
+	wp, wo, wd := max(w.Proximity, 0), max(w.Overlap, 0), max(w.Decay, 0)
+	total := wp + wo + wd
+	if total == 0 {
+		return DefaultTraversalWeights.Score(r)
+	}
+	var proximity, decay float64
+	if r.HopDistance > 0 {
+		proximity = 1 / float64(r.HopDistance)
+	}
+	n := float64(r.SharedEntityCount)
+	overlap := n / (n + 1)
+	if r.Memory != nil {
+		decay = r.Memory.DecayScore
+	}
+	return (wp*proximity + wo*overlap + wd*decay) / total
+}
+
+// Normalize applies defaults to the TraversalOptions.
+func (o *TraversalOptions) Normalize() {
+	if o.MaxHops < 1 {
+		o.MaxHops = 2
+	}
+	if o.Limit < 1 {
+		o.Limit = 10
+	}
+	if o.MaxBreadth < 1 {
+		o.MaxBreadth = DefaultTraversalBreadth
+	}
+}
+
+// Follows reports whether a relationship of type relType may be traversed
+// under the RelationshipTypes and ExcludeTypes filters.
+func (o *TraversalOptions) Follows(relType string) bool {
+	for _, t := range o.ExcludeTypes {
+		if strings.EqualFold(t, relType) {
+			return false
+		}
+	}
+	if len(o.RelationshipTypes) == 0 {
+		return true
+	}
+	for _, t := range o.RelationshipTypes {
+		if strings.EqualFold(t, relType) {
+			return true
+		}
+	}
+	return false
+}
+
 // TraversalResult represents a memory found via graph traversal through the
 // entity relationship graph (memory → entities → relationships → entities → memory).
 type TraversalResult struct {
@@ -296,4 +582,217 @@ type TraversalResult struct {
 	// SharedEntities contains the names of entities that connect this memory
 	// to the traversal path. Useful for explaining why a memory was surfaced.
 	SharedEntities []string
+
+	// SharedEntityCount is len(SharedEntities).
+	SharedEntityCount int
+
+	// Score is the relevance score in [0, 1] computed by
+	// TraversalWeights.Score; results are sorted by it.
+	Score float64
+
+	// Relationships are the entity relationships followed to reach this
+	// memory, in path order from the start memory's entities. It is empty
+	// for memories that share an entity with the start memory directly.
+	Relationships []TraversalEdge
+}
+
+// Directions of a TraversalEdge relative to the start of the traversal.
+const (
+	TraversalOutgoing = "outgoing" // followed from source to target
+	TraversalIncoming = "incoming" // followed from target back to source
+)
+
+// TraversalEdge is one relationship followed by Traverse.
+type TraversalEdge struct {
+	Type      string `json:"type"`      // Relationship type, e.g. WORKS_AT
+	Direction string `json:"direction"` // TraversalOutgoing or TraversalIncoming
+	Source    string `json:"source"`    // Name of the relationship's source entity
+	Target    string `json:"target"`    // Name of the relationship's target entity
+}
+
+// RankTraversalResults scores results with weights (DefaultTraversalWeights
+// when zero) and sorts them by score descending, breaking ties by hop
+// distance ascending then decay score descending.
+func RankTraversalResults(results []TraversalResult, weights TraversalWeights) {
+	for i := range results {
+		results[i].SharedEntityCount = len(results[i].SharedEntities)
+		results[i].Score = weights.Score(results[i])
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.HopDistance != b.HopDistance {
+			return a.HopDistance < b.HopDistance
+		}
+		return a.Memory.DecayScore > b.Memory.DecayScore
+	})
+}
+
+// MemoryLink is a typed memory-to-memory link from the memory_links table,
+// e.g. a CONTAINS link from a project to one of its phases.
+type MemoryLink struct {
+	ID        string    `json:"id"`
+	SourceID  string    `json:"source_id"`
+	TargetID  string    `json:"target_id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MemoryEntity is an entity as it is associated with one memory, including
+// how often it was mentioned and how confident the extraction was.
+type MemoryEntity struct {
+	types.Entity
+	Frequency  int     `json:"frequency"`
+	Confidence float64 `json:"confidence"`
+}
+
+// MemoryGraph is the graph state around a single memory: its entities, the
+// relationships between those entities, and its incoming and outgoing
+// memory links.
+type MemoryGraph struct {
+	Entities      []MemoryEntity       `json:"entities"`
+	Relationships []types.Relationship `json:"relationships"`
+	Links         []MemoryLink         `json:"links"`
+}
+
+// RelationshipFilter selects the relationships ListRelationships returns.
+// Exactly one of MemoryID and EntityID must be set.
+type RelationshipFilter struct {
+	MemoryID string // Relationships between entities the memory mentions
+	EntityID string // Relationships from or to this entity
+	Type     string // Only relationships of this type; empty for all
+	Limit    int    // Most relationships returned (default DefaultLimit)
+}
+
+// EntityRelationship is a relationship between two entities, with the
+// entities' names and types.
+type EntityRelationship struct {
+	types.Relationship
+	Context    string // Description of the relationship, if recorded
+	SourceName string
+	SourceType string
+	TargetName string
+	TargetType string
+}
+
+// GraphExportOptions selects the part of the graph ExportGraph returns.
+// Zero fields do not filter.
+type GraphExportOptions struct {
+	Domain        string    // Only memories of this domain
+	CreatedAfter  time.Time // Only memories created strictly after this time
+	CreatedBefore time.Time // Only memories created strictly before this time
+	MaxNodes      int       // Most memory and entity nodes returned together; required
+}
+
+// GraphExport is the graph of a store's live memories and the entities
+// extracted from them, for visualization.
+type GraphExport struct {
+	// Memories are the exported memories, newest first. Only ID, Content,
+	// Domain, MemoryType, State and CreatedAt are set.
+	Memories []types.Memory
+	// Entities are the entities linked to the exported memories, most
+	// mentioned first, with MemoryCount counting exported memories only.
+	Entities []types.Entity
+	// Mentions link exported memories to exported entities.
+	Mentions []EntityMention
+	// Relationships are those between exported entities.
+	Relationships []types.Relationship
+	// Links are the memory links between exported memories.
+	Links []MemoryLink
+	// Truncated reports whether memories or entities were left out to stay
+	// within MaxNodes. Older memories and less mentioned entities go first.
+	Truncated bool
+}
+
+// EntityMention is a memory_entities row: an entity mentioned in a memory.
+type EntityMention struct {
+	MemoryID   string
+	EntityID   string
+	Frequency  int
+	Confidence float64
+}
+
+// Session is a named work session started with begin_session. Memories
+// stored while it is current carry its ID as their session_id.
+type Session struct {
+	ID        string     `json:"session_id"`
+	Label     string     `json:"label,omitempty"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"` // nil while the session is open
+}
+
+// AuditEntry records one mutating operation that succeeded: who ran which
+// tool, when, against which memories and connection.
+type AuditEntry struct {
+	// ID orders entries: it increases with every appended entry.
+	ID         int64     `json:"id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Actor      string    `json:"actor"`                 // created_by of the caller
+	AuthorType string    `json:"author_type,omitempty"` // human, agent or system
+	Tool       string    `json:"tool"`
+	MemoryIDs  []string  `json:"memory_ids,omitempty"` // Memories read or written by the operation
+	Connection string    `json:"connection,omitempty"` // Connection written to; empty for the default
+}
+
+// StateUpdate is the outcome of one transition applied by UpdateStates.
+type StateUpdate struct {
+	ID            string // Memory ID
+	PreviousState string // State before the transition
+	Err           error  // Why the transition was not applied; nil on success
+}
+
+// EntityMergeResult is the outcome of MergeEntities.
+type EntityMergeResult struct {
+	CanonicalID            string   // Entity the duplicates were merged into
+	MergedIDs              []string // Duplicate entities that were deleted
+	MemoriesRepointed      int      // Memory associations moved to the canonical entity
+	RelationshipsRepointed int      // Relationships moved to the canonical entity
+	RelationshipsDropped   int      // Relationships deleted because the canonical entity already had them or they became self-loops
+}
+
+// PurgeResult counts what PurgeAll deleted.
+type PurgeResult struct {
+	Memories      int // Memories deleted, soft-deleted ones included
+	Entities      int // Entities deleted
+	Relationships int // Entity relationships deleted
+	Links         int // Memory links deleted
+}
+
+// AuditFilter narrows ListAuditEntries. Zero fields do not filter.
+type AuditFilter struct {
+	MemoryID string    // Entries that involve this memory
+	Actor    string    // Entries by this actor
+	Tool     string    // Entries for this tool
+	Since    time.Time // Entries at or after this time
+	Until    time.Time // Entries before this time
+	Limit    int       // Most entries returned (default DefaultLimit)
+}
+
+// StateTransition is one lifecycle state change of a memory, recorded by
+// UpdateState and UpdateStates.
+type StateTransition struct {
+	ID        int64     `json:"id"`             // Increases with every recorded transition
+	MemoryID  string    `json:"memory_id"`      // Memory whose state changed
+	From      string    `json:"from,omitempty"` // State before; empty when none was set
+	To        string    `json:"to"`             // State after
+	ChangedAt time.Time `json:"changed_at"`
+	ChangedBy string    `json:"changed_by,omitempty"` // Actor named by the context (see WithActor)
+}
+
+// actorKey is the context key of the actor set by WithActor.
+type actorKey struct{}
+
+// WithActor returns a copy of ctx naming actor (an agent or user) as the
+// one making changes, for stores to record alongside them, e.g. in the
+// state history.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set by WithActor, or "" if none.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
 }
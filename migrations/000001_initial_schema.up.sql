@@ -62,7 +62,18 @@ CREATE TABLE IF NOT EXISTS memories (
     content_hash TEXT,
 
     -- Evolution chain (tracks which memory this supersedes)
-    supersedes_id TEXT  -- references memories(id)
+    supersedes_id TEXT, -- references memories(id)
+
+    -- Content compression: 1 when content holds gzip bytes rather than text.
+    -- memories_fts always indexes the uncompressed text.
+    content_compressed INTEGER NOT NULL DEFAULT 0,
+
+    -- Expiry: memories past expires_at are excluded from List/search and
+    -- soft-deleted by the engine's expiry sweeper. NULL = never expires.
+    expires_at TIMESTAMP,
+
+    -- Authorship origin of created_by
+    author_type TEXT -- 'human', 'agent' or 'system'
 );
 
 -- Entities table: Extracted entities from memories
@@ -123,9 +134,9 @@ CREATE TABLE IF NOT EXISTS memory_entities (
     FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
 );
 
--- Embeddings table: Vector embeddings with dimension tracking
+-- Embeddings table: Vector embeddings with dimension tracking, one per memory and model
 CREATE TABLE IF NOT EXISTS embeddings (
-    memory_id TEXT PRIMARY KEY,
+    memory_id TEXT NOT NULL,
     embedding BLOB NOT NULL, -- Stored as binary packed float64 array
     dimension INTEGER NOT NULL,
     model TEXT NOT NULL,
@@ -133,6 +144,7 @@ CREATE TABLE IF NOT EXISTS embeddings (
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 
+    PRIMARY KEY (memory_id, model),
     FOREIGN KEY (memory_id) REFERENCES memories(id) ON DELETE CASCADE
 );
 
@@ -203,6 +215,8 @@ CREATE INDEX IF NOT EXISTS idx_memories_session_id ON memories(session_id) WHERE
 -- Quality signal ordering
 CREATE INDEX IF NOT EXISTS idx_memories_decay_score ON memories(decay_score DESC);
 CREATE INDEX IF NOT EXISTS idx_memories_last_accessed ON memories(last_accessed_at DESC) WHERE last_accessed_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_memories_expires_at ON memories(expires_at) WHERE expires_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_memories_author_type ON memories(author_type) WHERE author_type IS NOT NULL;
 
 -- Entity lookups
 CREATE INDEX IF NOT EXISTS idx_entities_type ON entities(type);
@@ -258,7 +272,7 @@ CREATE TRIGGER IF NOT EXISTS embeddings_updated_at
 AFTER UPDATE ON embeddings
 FOR EACH ROW
 BEGIN
-    UPDATE embeddings SET updated_at = CURRENT_TIMESTAMP WHERE memory_id = NEW.memory_id;
+    UPDATE embeddings SET updated_at = CURRENT_TIMESTAMP WHERE memory_id = NEW.memory_id AND model = NEW.model;
 END;
 
 CREATE TRIGGER IF NOT EXISTS settings_updated_at
@@ -271,14 +285,14 @@ END;
 -- Sync FTS index with memories table
 CREATE TRIGGER IF NOT EXISTS memories_fts_insert
 AFTER INSERT ON memories
-FOR EACH ROW
+FOR EACH ROW WHEN NEW.content_compressed = 0
 BEGIN
     INSERT INTO memories_fts(id, content) VALUES (NEW.id, NEW.content);
 END;
 
 CREATE TRIGGER IF NOT EXISTS memories_fts_update
 AFTER UPDATE ON memories
-FOR EACH ROW
+FOR EACH ROW WHEN NEW.content_compressed = 0
 BEGIN
     UPDATE memories_fts SET content = NEW.content WHERE id = NEW.id;
 END;
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Placeholders of custom extraction prompts. Enrichment replaces each with
+// the value it names before sending the prompt to the LLM.
+const (
+	// PromptContent is the memory content. Both prompts require it.
+	PromptContent = "{{content}}"
+	// PromptEntityTypes is the entity types to use, one "- type: description"
+	// line each.
+	PromptEntityTypes = "{{entity_types}}"
+	// PromptEntities is the entities extracted from the memory, one
+	// "- name (type)" line each. The relationship prompt requires it.
+	PromptEntities = "{{entities}}"
+	// PromptRelationshipTypes is the relationship types to use, one
+	// "- type" line each.
+	PromptRelationshipTypes = "{{relationship_types}}"
+)
+
+// ValidateEntityPrompt reports an error unless prompt is usable as a custom
+// entity extraction prompt: it must contain PromptContent and may contain
+// PromptEntityTypes.
+func ValidateEntityPrompt(prompt string) error {
+	return validatePrompt("entity", prompt, []string{PromptContent}, PromptEntityTypes)
+}
+
+// ValidateRelationshipPrompt reports an error unless prompt is usable as a
+// custom relationship extraction prompt: it must contain PromptContent and
+// PromptEntities and may contain PromptRelationshipTypes.
+func ValidateRelationshipPrompt(prompt string) error {
+	return validatePrompt("relationship", prompt, []string{PromptContent, PromptEntities}, PromptRelationshipTypes)
+}
+
+// validatePrompt checks that prompt contains every required placeholder
+// and no placeholder other than those and optional.
+func validatePrompt(kind, prompt string, required []string, optional string) error {
+	if strings.TrimSpace(prompt) == "" {
+		return fmt.Errorf("invalid %s prompt: empty", kind)
+	}
+	for _, p := range required {
+		if !strings.Contains(prompt, p) {
+			return fmt.Errorf("invalid %s prompt: missing placeholder %s", kind, p)
+		}
+	}
+	for _, p := range promptPlaceholder.FindAllString(prompt, -1) {
+		if p != optional && !slices.Contains(required, p) {
+			return fmt.Errorf("invalid %s prompt: unknown placeholder %s", kind, p)
+		}
+	}
+	return nil
+}
+
+// promptPlaceholder matches a placeholder such as {{content}}.
+var promptPlaceholder = regexp.MustCompile(`\{\{[a-z_]+\}\}`)
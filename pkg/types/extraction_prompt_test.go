@@ -0,0 +1,56 @@
+package types_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func TestValidateEntityPrompt(t *testing.T) {
+	for _, tc := range []struct {
+		prompt  string
+		wantErr string
+	}{
+		{"Extract parties and statutes from:\n{{content}}", ""},
+		{"Types:\n{{entity_types}}\nText:\n{{content}}", ""},
+		{"", "empty"},
+		{"Extract entities from the text.", "missing placeholder {{content}}"},
+		{"{{content}} {{entities}}", "unknown placeholder {{entities}}"},
+		{`{{content}} Answer {"entities":[{"name":"X"}]}`, ""},
+	} {
+		err := types.ValidateEntityPrompt(tc.prompt)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidateEntityPrompt(%q) failed: %v", tc.prompt, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("ValidateEntityPrompt(%q) = %v, want error containing %q", tc.prompt, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateRelationshipPrompt(t *testing.T) {
+	for _, tc := range []struct {
+		prompt  string
+		wantErr string
+	}{
+		{"Entities:\n{{entities}}\nText:\n{{content}}", ""},
+		{"{{relationship_types}}\n{{entities}}\n{{content}}", ""},
+		{"Text:\n{{content}}", "missing placeholder {{entities}}"},
+		{"{{entities}} {{content}} {{entity_types}}", "unknown placeholder {{entity_types}}"},
+	} {
+		err := types.ValidateRelationshipPrompt(tc.prompt)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidateRelationshipPrompt(%q) failed: %v", tc.prompt, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("ValidateRelationshipPrompt(%q) = %v, want error containing %q", tc.prompt, err, tc.wantErr)
+		}
+	}
+}
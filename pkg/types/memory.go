@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Memory represents a single memory unit in the system (v2.0 with async enrichment).
 // Memories are the atomic units of information storage, containing content,
@@ -57,6 +61,7 @@ type Memory struct {
 
 	// Provenance tracking
 	CreatedBy     string                 `json:"created_by,omitempty"`     // Agent or user that created this memory
+	AuthorType    string                 `json:"author_type,omitempty"`    // Origin of CreatedBy: human, agent, or system
 	SessionID     string                 `json:"session_id,omitempty"`     // Session in which memory was created
 	SourceContext map[string]interface{} `json:"source_context,omitempty"` // Arbitrary context about the source
 
@@ -74,4 +79,79 @@ type Memory struct {
 
 	// Evolution chain (tracks which memory this supersedes)
 	SupersedesID string `json:"supersedes_id,omitempty"` // ID of the memory this one supersedes
+
+	// Expiry (hard cutoff, unlike decay which only affects ranking)
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // When the memory expires (null = never)
+
+	// Search result context (populated by full-text search only, never persisted)
+	Snippet string `json:"snippet,omitempty"` // Excerpt around the matched terms, matches marked with **
+}
+
+// MetadataEnrichedLength is the Metadata key recording that a memory's content
+// was too long to enrich in full. Its value is the number of leading
+// characters (runes) that entity extraction and embedding should read; the
+// stored Content itself is never truncated.
+const MetadataEnrichedLength = "enriched_length"
+
+// EnrichmentContent returns the content that enrichment should process: the
+// first Metadata[MetadataEnrichedLength] characters when that key is set,
+// otherwise the full Content.
+func (m *Memory) EnrichmentContent() string {
+	var n int
+	switch v := m.Metadata[MetadataEnrichedLength].(type) {
+	case int:
+		n = v
+	case float64: // after a JSON round trip
+		n = int(v)
+	default:
+		return m.Content
+	}
+	return TruncateRunes(m.Content, n)
+}
+
+// EnrichmentSummary describes enrichment progress in one line, e.g.
+// "3/5 complete, embedding pending, entities failed". Every step that has not
+// completed is listed with its status, in pipeline order; an unset status
+// reads as pending.
+func (m *Memory) EnrichmentSummary() string {
+	steps := []struct {
+		name   string
+		status EnrichmentStatus
+	}{
+		{"entities", m.EntityStatus},
+		{"relationships", m.RelationshipStatus},
+		{"classification", m.ClassificationStatus},
+		{"summarization", m.SummarizationStatus},
+		{"embedding", m.EmbeddingStatus},
+	}
+	completed := 0
+	var outstanding []string
+	for _, step := range steps {
+		switch step.status {
+		case EnrichmentCompleted:
+			completed++
+		case "":
+			outstanding = append(outstanding, step.name+" "+string(EnrichmentPending))
+		default:
+			outstanding = append(outstanding, step.name+" "+string(step.status))
+		}
+	}
+	summary := fmt.Sprintf("%d/%d complete", completed, len(steps))
+	if len(outstanding) > 0 {
+		summary += ", " + strings.Join(outstanding, ", ")
+	}
+	return summary
+}
+
+// TruncateRunes returns the first n characters (runes) of s, or s unchanged
+// when it is not longer than n or n is not positive.
+func TruncateRunes(s string, n int) string {
+	if n <= 0 || len(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
 }
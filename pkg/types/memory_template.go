@@ -0,0 +1,228 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Metadata keys under which a memory created from a template records the
+// template's name and the fields it was rendered from.
+const (
+	MetadataTemplate       = "template"
+	MetadataTemplateFields = "fields"
+)
+
+// MemoryTemplate is a structured memory type: a named set of fields that
+// Render turns into memory content, e.g. a decision with its context,
+// choice, rationale and alternatives.
+type MemoryTemplate struct {
+	// Name identifies the template, e.g. "decision".
+	Name string `json:"name"`
+	// Description tells callers what the template is for.
+	Description string `json:"description,omitempty"`
+	// MemoryType is the memory_type of memories created from the template.
+	// Empty means Name.
+	MemoryType string `json:"memory_type,omitempty"`
+	// Fields lists the template's fields in the order they are rendered.
+	Fields []TemplateField `json:"fields"`
+}
+
+// TemplateField is one field of a MemoryTemplate. Its value is a string or
+// a list of strings.
+type TemplateField struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// BuiltinMemoryTemplates returns the templates available without
+// configuration: decision, meeting and person.
+func BuiltinMemoryTemplates() []MemoryTemplate {
+	return []MemoryTemplate{
+		{
+			Name:        "decision",
+			Description: "A choice that was made and why",
+			MemoryType:  MemoryTypeDecision,
+			Fields: []TemplateField{
+				{Name: "context", Description: "The situation that called for a decision", Required: true},
+				{Name: "choice", Description: "What was decided", Required: true},
+				{Name: "rationale", Description: "Why it was decided", Required: true},
+				{Name: "alternatives", Description: "Options that were considered and rejected"},
+			},
+		},
+		{
+			Name:        "meeting",
+			Description: "A meeting and its outcomes",
+			MemoryType:  MemoryTypeEvent,
+			Fields: []TemplateField{
+				{Name: "title", Description: "What the meeting was about", Required: true},
+				{Name: "date", Description: "When it took place"},
+				{Name: "attendees", Description: "Who attended"},
+				{Name: "notes", Description: "What was discussed and agreed", Required: true},
+				{Name: "action_items", Description: "Follow-ups and their owners"},
+			},
+		},
+		{
+			Name:        "person",
+			Description: "Someone worth remembering",
+			MemoryType:  MemoryTypePerson,
+			Fields: []TemplateField{
+				{Name: "name", Description: "The person's name", Required: true},
+				{Name: "role", Description: "Their role or title"},
+				{Name: "organization", Description: "Who they work for"},
+				{Name: "contact", Description: "How to reach them"},
+				{Name: "notes", Description: "Anything else worth knowing"},
+			},
+		},
+	}
+}
+
+// ParseMemoryTemplates decodes a JSON array of templates and validates each.
+func ParseMemoryTemplates(data []byte) ([]MemoryTemplate, error) {
+	var templates []MemoryTemplate
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("invalid memory templates: %w", err)
+	}
+	seen := make(map[string]bool, len(templates))
+	for i := range templates {
+		if err := templates[i].Validate(); err != nil {
+			return nil, err
+		}
+		if seen[templates[i].Name] {
+			return nil, fmt.Errorf("invalid memory templates: template %q declared twice", templates[i].Name)
+		}
+		seen[templates[i].Name] = true
+	}
+	return templates, nil
+}
+
+// MergeMemoryTemplates returns base followed by the templates of custom,
+// a custom template replacing the base template of the same name.
+func MergeMemoryTemplates(base, custom []MemoryTemplate) []MemoryTemplate {
+	merged := slices.Clone(base)
+	for _, t := range custom {
+		if i := slices.IndexFunc(merged, func(b MemoryTemplate) bool { return b.Name == t.Name }); i >= 0 {
+			merged[i] = t
+		} else {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
+// Validate reports an error unless the template has a name and at least
+// one field, and its field names are non-empty and distinct.
+func (t *MemoryTemplate) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("invalid memory template: empty name")
+	}
+	if len(t.Fields) == 0 {
+		return fmt.Errorf("invalid memory template %q: no fields declared", t.Name)
+	}
+	seen := make(map[string]bool, len(t.Fields))
+	for _, f := range t.Fields {
+		if strings.TrimSpace(f.Name) == "" {
+			return fmt.Errorf("invalid memory template %q: empty field name", t.Name)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("invalid memory template %q: field %q declared twice", t.Name, f.Name)
+		}
+		seen[f.Name] = true
+	}
+	return nil
+}
+
+// Type returns the memory_type of memories created from the template.
+func (t *MemoryTemplate) Type() string {
+	if t.MemoryType != "" {
+		return t.MemoryType
+	}
+	return t.Name
+}
+
+// Render checks fields against the template and renders them into memory
+// content: a heading naming the template, then one "Label: value" line per
+// field that is set, in template order, with list values as bullets. It
+// also returns the fields with values normalized to a string or a []string
+// and empty ones dropped, for storing in metadata. Unknown fields, values
+// of other types and missing required fields are errors.
+func (t *MemoryTemplate) Render(fields map[string]interface{}) (string, map[string]interface{}, error) {
+	for name := range fields {
+		if !slices.ContainsFunc(t.Fields, func(f TemplateField) bool { return f.Name == name }) {
+			return "", nil, fmt.Errorf("template %q has no field %q", t.Name, name)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fieldLabel(t.Name))
+	normalized := make(map[string]interface{}, len(fields))
+	for _, f := range t.Fields {
+		value, err := templateValue(fields[f.Name])
+		if err != nil {
+			return "", nil, fmt.Errorf("field %q: %w", f.Name, err)
+		}
+		switch v := value.(type) {
+		case nil:
+			if f.Required {
+				return "", nil, fmt.Errorf("template %q requires field %q", t.Name, f.Name)
+			}
+			continue
+		case string:
+			fmt.Fprintf(&b, "\n%s: %s", fieldLabel(f.Name), v)
+		case []string:
+			fmt.Fprintf(&b, "\n%s:", fieldLabel(f.Name))
+			for _, item := range v {
+				fmt.Fprintf(&b, "\n- %s", item)
+			}
+		}
+		normalized[f.Name] = value
+	}
+	return b.String(), normalized, nil
+}
+
+// templateValue normalizes a field value decoded from JSON to a trimmed
+// string or a []string of non-empty trimmed items, or nil when it is
+// absent or empty.
+func templateValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			return v, nil
+		}
+		return nil, nil
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return templateValue(items)
+	case []interface{}:
+		var items []string
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("list items must be strings, got %T", item)
+			}
+			if s = strings.TrimSpace(s); s != "" {
+				items = append(items, s)
+			}
+		}
+		if len(items) == 0 {
+			return nil, nil
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("must be a string or a list of strings, got %T", value)
+	}
+}
+
+// fieldLabel turns a field or template name such as "action_items" into
+// the label "Action items".
+func fieldLabel(name string) string {
+	label := strings.ReplaceAll(name, "_", " ")
+	return strings.ToUpper(label[:1]) + label[1:]
+}
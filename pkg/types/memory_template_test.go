@@ -0,0 +1,98 @@
+package types_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/scrypster/memento/pkg/types"
+)
+
+func TestMemoryTemplate_Render(t *testing.T) {
+	decision := types.BuiltinMemoryTemplates()[0]
+
+	content, fields, err := decision.Render(map[string]interface{}{
+		"context":      "Search is slow on large tenants",
+		"choice":       "  Move search to Postgres full-text  ",
+		"rationale":    "One less service to run",
+		"alternatives": []interface{}{"Elasticsearch", "", "Meilisearch"},
+	})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	want := "Decision\n" +
+		"Context: Search is slow on large tenants\n" +
+		"Choice: Move search to Postgres full-text\n" +
+		"Rationale: One less service to run\n" +
+		"Alternatives:\n- Elasticsearch\n- Meilisearch"
+	if content != want {
+		t.Errorf("Render() content = %q, want %q", content, want)
+	}
+	wantFields := map[string]interface{}{
+		"context":      "Search is slow on large tenants",
+		"choice":       "Move search to Postgres full-text",
+		"rationale":    "One less service to run",
+		"alternatives": []string{"Elasticsearch", "Meilisearch"},
+	}
+	if !reflect.DeepEqual(fields, wantFields) {
+		t.Errorf("Render() fields = %v, want %v", fields, wantFields)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		fields map[string]interface{}
+		errMsg string
+	}{
+		{"missing required", map[string]interface{}{"context": "c", "choice": "x"}, `requires field "rationale"`},
+		{"blank required", map[string]interface{}{"context": "c", "choice": "x", "rationale": "  "}, `requires field "rationale"`},
+		{"unknown field", map[string]interface{}{"context": "c", "choice": "x", "rationale": "r", "owner": "me"}, `no field "owner"`},
+		{"wrong type", map[string]interface{}{"context": "c", "choice": "x", "rationale": 3.0}, "string or a list of strings"},
+		{"wrong item type", map[string]interface{}{"context": "c", "choice": "x", "rationale": "r", "alternatives": []interface{}{1.0}}, "list items must be strings"},
+	} {
+		if _, _, err := decision.Render(tc.fields); err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+			t.Errorf("%s: Render() error = %v, want it to contain %q", tc.name, err, tc.errMsg)
+		}
+	}
+}
+
+func TestParseMemoryTemplates(t *testing.T) {
+	templates, err := types.ParseMemoryTemplates([]byte(`[
+		{"name": "incident", "memory_type": "event", "fields": [{"name": "summary", "required": true}, {"name": "impact"}]},
+		{"name": "person", "fields": [{"name": "name", "required": true}, {"name": "team"}]}
+	]`))
+	if err != nil {
+		t.Fatalf("ParseMemoryTemplates() failed: %v", err)
+	}
+	if got := templates[0].Type(); got != "event" {
+		t.Errorf("incident Type() = %q, want event", got)
+	}
+	if got := templates[1].Type(); got != "person" {
+		t.Errorf("person Type() = %q, want the template name", got)
+	}
+
+	merged := types.MergeMemoryTemplates(types.BuiltinMemoryTemplates(), templates)
+	var names []string
+	for _, m := range merged {
+		names = append(names, m.Name)
+	}
+	if want := []string{"decision", "meeting", "person", "incident"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("merged names = %v, want %v", names, want)
+	}
+	if merged[2].Fields[1].Name != "team" {
+		t.Errorf("custom person template did not replace the built-in one: %+v", merged[2])
+	}
+
+	for _, tc := range []struct {
+		name, data, errMsg string
+	}{
+		{"not an array", `{"name": "x"}`, "invalid memory templates"},
+		{"no name", `[{"fields": [{"name": "a"}]}]`, "empty name"},
+		{"no fields", `[{"name": "x"}]`, "no fields"},
+		{"repeated field", `[{"name": "x", "fields": [{"name": "a"}, {"name": "a"}]}]`, `field "a" declared twice`},
+		{"repeated template", `[{"name": "x", "fields": [{"name": "a"}]}, {"name": "x", "fields": [{"name": "b"}]}]`, `template "x" declared twice`},
+	} {
+		if _, err := types.ParseMemoryTemplates([]byte(tc.data)); err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+			t.Errorf("%s: ParseMemoryTemplates() error = %v, want it to contain %q", tc.name, err, tc.errMsg)
+		}
+	}
+}
@@ -233,3 +233,48 @@ func TestStateTransitionValidation_EmptyToEmpty(t *testing.T) {
 		t.Errorf("expected IsValidStateTransition(\"\", \"\") = false, got %v", result)
 	}
 }
+
+// TestMemoryEnrichmentContent verifies that EnrichmentContent honours the
+// enriched_length metadata both as an int and after a JSON round trip.
+func TestMemoryEnrichmentContent(t *testing.T) {
+	m := types.Memory{Content: "héllo world"}
+	if got := m.EnrichmentContent(); got != "héllo world" {
+		t.Errorf("without metadata: got %q, want full content", got)
+	}
+
+	m.Metadata = map[string]interface{}{types.MetadataEnrichedLength: 5}
+	if got := m.EnrichmentContent(); got != "héllo" {
+		t.Errorf("int length: got %q, want %q", got, "héllo")
+	}
+
+	m.Metadata[types.MetadataEnrichedLength] = float64(5)
+	if got := m.EnrichmentContent(); got != "héllo" {
+		t.Errorf("float64 length: got %q, want %q", got, "héllo")
+	}
+
+	m.Metadata[types.MetadataEnrichedLength] = 100
+	if got := m.EnrichmentContent(); got != "héllo world" {
+		t.Errorf("length beyond content: got %q, want full content", got)
+	}
+}
+
+// TestMemoryEnrichmentSummary verifies that EnrichmentSummary counts completed
+// steps and lists the rest with their status.
+func TestMemoryEnrichmentSummary(t *testing.T) {
+	m := types.Memory{
+		EntityStatus:         types.EnrichmentFailed,
+		RelationshipStatus:   types.EnrichmentCompleted,
+		ClassificationStatus: types.EnrichmentCompleted,
+		SummarizationStatus:  types.EnrichmentCompleted,
+	}
+	want := "3/5 complete, entities failed, embedding pending"
+	if got := m.EnrichmentSummary(); got != want {
+		t.Errorf("EnrichmentSummary() = %q, want %q", got, want)
+	}
+
+	m.EntityStatus = types.EnrichmentCompleted
+	m.EmbeddingStatus = types.EnrichmentCompleted
+	if got := m.EnrichmentSummary(); got != "5/5 complete" {
+		t.Errorf("EnrichmentSummary() = %q, want %q", got, "5/5 complete")
+	}
+}
@@ -1,5 +1,8 @@
 package types
 
+// PinnedTag marks a memory that automatic archival must never touch.
+const PinnedTag = "pinned"
+
 // Lifecycle state constants for memory work tracking
 const (
 	StatePlanning   = "planning"   // Being planned or designed
@@ -0,0 +1,123 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// StateMachine is a custom lifecycle state machine: the states a memory may
+// be in and the transitions allowed between them. It replaces the built-in
+// machine (IsValidLifecycleState and IsValidStateTransition) for teams whose
+// workflow needs other states, such as "review" or "qa".
+//
+// A nil *StateMachine is the built-in machine, so stores and callers can
+// hold one unconditionally.
+//
+// evolve_memory and resolve_contradiction move memories to superseded and
+// archived; a custom machine without those states makes them fail.
+type StateMachine struct {
+	// States lists every state of the machine.
+	States []string `json:"states"`
+	// Initial lists the states a memory without a state may move to.
+	Initial []string `json:"initial"`
+	// Transitions maps a state to the states it may move to. A state
+	// without an entry is terminal.
+	Transitions map[string][]string `json:"transitions"`
+}
+
+// ParseStateMachine decodes a StateMachine from JSON and validates it.
+func ParseStateMachine(data []byte) (*StateMachine, error) {
+	var m StateMachine
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid state machine: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate reports an error unless the machine is well formed: at least one
+// state and one initial state, no empty or repeated state names, every
+// initial state and transition naming a declared state, and every state
+// reachable from an initial one.
+func (m *StateMachine) Validate() error {
+	if m == nil {
+		return nil
+	}
+	if len(m.States) == 0 {
+		return fmt.Errorf("invalid state machine: no states declared")
+	}
+	if len(m.Initial) == 0 {
+		return fmt.Errorf("invalid state machine: no initial states declared")
+	}
+
+	declared := make(map[string]bool, len(m.States))
+	for _, s := range m.States {
+		if s == "" {
+			return fmt.Errorf("invalid state machine: empty state name")
+		}
+		if declared[s] {
+			return fmt.Errorf("invalid state machine: state %q declared twice", s)
+		}
+		declared[s] = true
+	}
+	for _, s := range m.Initial {
+		if !declared[s] {
+			return fmt.Errorf("invalid state machine: initial state %q is not declared", s)
+		}
+	}
+	for from, targets := range m.Transitions {
+		if !declared[from] {
+			return fmt.Errorf("invalid state machine: transitions from undeclared state %q", from)
+		}
+		for _, to := range targets {
+			if !declared[to] {
+				return fmt.Errorf("invalid state machine: transition %q -> %q targets an undeclared state", from, to)
+			}
+		}
+	}
+
+	reached := make(map[string]bool, len(m.States))
+	queue := slices.Clone(m.Initial)
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if reached[s] {
+			continue
+		}
+		reached[s] = true
+		queue = append(queue, m.Transitions[s]...)
+	}
+	for _, s := range m.States {
+		if !reached[s] {
+			return fmt.Errorf("invalid state machine: state %q is unreachable from the initial states", s)
+		}
+	}
+	return nil
+}
+
+// IsValidState reports whether state is a state of the machine. Empty is
+// valid (means state not set).
+func (m *StateMachine) IsValidState(state string) bool {
+	if m == nil {
+		return IsValidLifecycleState(state)
+	}
+	return state == "" || slices.Contains(m.States, state)
+}
+
+// CanTransition reports whether a memory in state from may move to state
+// to. Moving to the empty state is never allowed.
+func (m *StateMachine) CanTransition(from, to string) bool {
+	if m == nil {
+		return IsValidStateTransition(from, to)
+	}
+	if to == "" {
+		return false
+	}
+	if from == "" {
+		return slices.Contains(m.Initial, to)
+	}
+	return slices.Contains(m.Transitions[from], to)
+}
@@ -0,0 +1,93 @@
+package types_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/scrypster/memento/pkg/types"
+)
+
+// reviewMachine is a workflow with review and qa steps the built-in machine
+// lacks.
+const reviewMachine = `{
+	"states": ["todo", "doing", "review", "qa", "done"],
+	"initial": ["todo"],
+	"transitions": {
+		"todo": ["doing"],
+		"doing": ["review"],
+		"review": ["doing", "qa"],
+		"qa": ["doing", "done"]
+	}
+}`
+
+func TestParseStateMachine(t *testing.T) {
+	m, err := types.ParseStateMachine([]byte(reviewMachine))
+	if err != nil {
+		t.Fatalf("ParseStateMachine() failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		from, to string
+		want     bool
+	}{
+		{"", "todo", true},
+		{"", "doing", false},
+		{"doing", "review", true},
+		{"review", "qa", true},
+		{"qa", "done", true},
+		{"todo", "done", false},
+		{"done", "todo", false}, // terminal
+		{"doing", "", false},
+		{"active", "paused", false}, // built-in states are not part of it
+	} {
+		if got := m.CanTransition(tc.from, tc.to); got != tc.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+
+	if !m.IsValidState("qa") || !m.IsValidState("") {
+		t.Error("expected qa and the empty state to be valid")
+	}
+	if m.IsValidState("active") {
+		t.Error("expected the built-in active state to be invalid in a custom machine")
+	}
+}
+
+func TestStateMachine_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		json string
+		want string
+	}{
+		{"no states", `{"initial":["a"]}`, "no states"},
+		{"no initial", `{"states":["a"]}`, "no initial states"},
+		{"duplicate", `{"states":["a","a"],"initial":["a"]}`, "declared twice"},
+		{"empty name", `{"states":["a",""],"initial":["a"]}`, "empty state name"},
+		{"undeclared initial", `{"states":["a"],"initial":["b"]}`, `initial state "b"`},
+		{"undeclared source", `{"states":["a"],"initial":["a"],"transitions":{"b":["a"]}}`, `undeclared state "b"`},
+		{"dangling target", `{"states":["a"],"initial":["a"],"transitions":{"a":["b"]}}`, `"a" -> "b"`},
+		{"unreachable", `{"states":["a","b"],"initial":["a"]}`, `"b" is unreachable`},
+		{"malformed", `{"states":`, "invalid state machine"},
+	} {
+		_, err := types.ParseStateMachine([]byte(tc.json))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: ParseStateMachine() error = %v, want one containing %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+// TestStateMachine_NilIsBuiltIn verifies that a nil machine follows the
+// built-in states and transitions.
+func TestStateMachine_NilIsBuiltIn(t *testing.T) {
+	var m *types.StateMachine
+
+	if err := m.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if !m.IsValidState(types.StatePaused) || m.IsValidState("review") {
+		t.Error("expected the built-in states")
+	}
+	if !m.CanTransition(types.StateActive, types.StatePaused) || m.CanTransition(types.StateArchived, types.StateActive) {
+		t.Error("expected the built-in transitions")
+	}
+}
@@ -260,6 +260,11 @@ var ValidMemoryTypes = []string{
 	MemoryTypeStep,
 }
 
+// MemoryTypeDigest marks the periodic digests generate_digest stores. It is
+// not one of ValidMemoryTypes, so classification never assigns it, and it
+// keeps a digest's type when the digest is enriched.
+const MemoryTypeDigest = "digest"
+
 // IsValidMemoryType checks if the given memory type is valid
 func IsValidMemoryType(memoryType string) bool {
 	for _, validType := range ValidMemoryTypes {
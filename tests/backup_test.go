@@ -393,7 +393,7 @@ func TestBackupService_RestoreBackup(t *testing.T) {
 	}
 
 	// Restore from backup
-	err = service.RestoreBackup(ctx, result.Path)
+	_, err = service.RestoreBackup(ctx, result.Path, backup.RestoreOptions{})
 	if err != nil {
 		t.Fatalf("RestoreBackup failed: %v", err)
 	}
@@ -250,12 +250,14 @@ func (h *APIHandlers) CreateMemory(w http.ResponseWriter, r *http.Request) {
 		EmbeddingStatus:    types.EnrichmentPending,
 	}
 
-	// Set created_by: use explicit request value if provided, otherwise auto-detect
+	// Set created_by: use explicit request value if provided, otherwise auto-detect.
+	// Memories created through the web API are written by hand in the UI.
 	if req.CreatedBy != "" {
 		memory.CreatedBy = req.CreatedBy
 	} else {
 		memory.CreatedBy = attribution.DetectAgent()
 	}
+	memory.AuthorType = string(attribution.AuthorHuman)
 
 	// Override timestamp if provided
 	if req.Timestamp != nil && !req.Timestamp.IsZero() {
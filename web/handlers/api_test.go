@@ -82,6 +82,24 @@ func (m *MockMemoryStore) UpdateDecayScores(ctx context.Context) (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockMemoryStore) SetEmbedding(ctx context.Context, id string, vec []float64, model string) error {
+	args := m.Called(ctx, id, vec, model)
+	return args.Error(0)
+}
+
+func (m *MockMemoryStore) GetEmbedding(ctx context.Context, id string) ([]float64, string, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]float64), args.String(1), args.Error(2)
+}
+
+func (m *MockMemoryStore) ExpireMemories(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockMemoryStore) GetRelatedMemories(ctx context.Context, memoryID string) ([]string, error) {
 	args := m.Called(ctx, memoryID)
 	if args.Get(0) == nil {
@@ -90,8 +108,8 @@ func (m *MockMemoryStore) GetRelatedMemories(ctx context.Context, memoryID strin
 	return args.Get(0).([]string), args.Error(1)
 }
 
-func (m *MockMemoryStore) Traverse(ctx context.Context, startMemoryID string, maxHops int, limit int) ([]storage.TraversalResult, error) {
-	args := m.Called(ctx, startMemoryID, maxHops, limit)
+func (m *MockMemoryStore) Traverse(ctx context.Context, startMemoryID string, opts storage.TraversalOptions) ([]storage.TraversalResult, error) {
+	args := m.Called(ctx, startMemoryID, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -106,6 +124,14 @@ func (m *MockMemoryStore) GetMemoryEntities(ctx context.Context, memoryID string
 	return args.Get(0).([]*types.Entity), args.Error(1)
 }
 
+func (m *MockMemoryStore) ListEntities(ctx context.Context, opts storage.EntityListOptions) (*storage.PaginatedResult[types.Entity], error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*storage.PaginatedResult[types.Entity]), args.Error(1)
+}
+
 func (m *MockMemoryStore) Restore(_ context.Context, _ string) error { return nil }
 
 func (m *MockMemoryStore) GetEvolutionChain(_ context.Context, _ string) ([]*types.Memory, error) {
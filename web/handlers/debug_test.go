@@ -89,11 +89,23 @@ func (s *stubStore) UpdateDecayScores(_ context.Context) (int, error) {
 	return 0, nil
 }
 
+func (s *stubStore) SetEmbedding(_ context.Context, _ string, _ []float64, _ string) error {
+	return nil
+}
+
+func (s *stubStore) GetEmbedding(_ context.Context, _ string) ([]float64, string, error) {
+	return nil, "", storage.ErrNotFound
+}
+
+func (s *stubStore) ExpireMemories(_ context.Context, _ time.Time) (int, error) {
+	return 0, nil
+}
+
 func (s *stubStore) GetRelatedMemories(_ context.Context, _ string) ([]string, error) {
 	return nil, nil
 }
 
-func (s *stubStore) Traverse(_ context.Context, _ string, _ int, _ int) ([]storage.TraversalResult, error) {
+func (s *stubStore) Traverse(_ context.Context, _ string, _ storage.TraversalOptions) ([]storage.TraversalResult, error) {
 	return nil, nil
 }
 
@@ -101,6 +113,10 @@ func (s *stubStore) GetMemoryEntities(_ context.Context, _ string) ([]*types.Ent
 	return nil, nil
 }
 
+func (s *stubStore) ListEntities(_ context.Context, _ storage.EntityListOptions) (*storage.PaginatedResult[types.Entity], error) {
+	return &storage.PaginatedResult[types.Entity]{}, nil
+}
+
 func (s *stubStore) Restore(_ context.Context, _ string) error { return nil }
 
 func (s *stubStore) GetEvolutionChain(_ context.Context, _ string) ([]*types.Memory, error) {
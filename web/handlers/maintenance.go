@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"log"
 	"net/http"
 
 	"github.com/scrypster/memento/internal/connections"
+	"github.com/scrypster/memento/internal/storage"
 )
 
 // MaintenanceEngine is the engine interface needed for maintenance operations.
@@ -160,58 +162,36 @@ func (h *MaintenanceHandler) RunBackfill(w http.ResponseWriter, r *http.Request)
 
 	resp := backfillResponse{}
 
+	var queueEnrichment, queueEmbedding func(id, content string) bool
+	if h.engine != nil {
+		queueEnrichment = h.engine.QueueEnrichmentForMemory
+		queueEmbedding = h.engine.QueueEmbeddingForMemory
+	}
+
 	switch req.Type {
 	case "enrichment":
-		rows, err := db.QueryContext(r.Context(),
-			`SELECT id, content FROM memories WHERE status IN ('pending', 'failed')`)
+		queued, err := queueBackfill(r.Context(), db, store,
+			`SELECT id FROM memories WHERE status IN ('pending', 'failed')`, queueEnrichment)
 		if err != nil {
 			http.Error(w, "failed to query memories: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
-		defer func() { _ = rows.Close() }()
-
-		for rows.Next() {
-			var id, content string
-			if err := rows.Scan(&id, &content); err != nil {
-				continue
-			}
-			if h.engine != nil {
-				if h.engine.QueueEnrichmentForMemory(id, content) {
-					resp.Queued++
-				}
-			} else {
-				resp.Queued++
-			}
-		}
+		resp.Queued = queued
 		resp.Message = "queued for enrichment"
 		if h.engine == nil {
 			resp.Message = "queued for next startup (engine not running)"
 		}
 
 	case "embeddings":
-		rows, err := db.QueryContext(r.Context(),
-			`SELECT m.id, m.content FROM memories m
+		queued, err := queueBackfill(r.Context(), db, store,
+			`SELECT m.id FROM memories m
 			 WHERE m.status = 'enriched'
-			 AND NOT EXISTS (SELECT 1 FROM embeddings e WHERE e.memory_id = m.id)`)
+			 AND NOT EXISTS (SELECT 1 FROM embeddings e WHERE e.memory_id = m.id)`, queueEmbedding)
 		if err != nil {
 			http.Error(w, "failed to query memories: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
-		defer func() { _ = rows.Close() }()
-
-		for rows.Next() {
-			var id, content string
-			if err := rows.Scan(&id, &content); err != nil {
-				continue
-			}
-			if h.engine != nil {
-				if h.engine.QueueEmbeddingForMemory(id, content) {
-					resp.Queued++
-				}
-			} else {
-				resp.Queued++
-			}
-		}
+		resp.Queued = queued
 		resp.Message = "queued for embedding generation"
 		if h.engine == nil {
 			resp.Message = "queued for next startup (engine not running)"
@@ -225,27 +205,13 @@ func (h *MaintenanceHandler) RunBackfill(w http.ResponseWriter, r *http.Request)
 		}
 
 		// Queue all enriched memories for embedding
-		rows, err := db.QueryContext(r.Context(),
-			`SELECT id, content FROM memories WHERE status = 'enriched'`)
+		queued, err := queueBackfill(r.Context(), db, store,
+			`SELECT id FROM memories WHERE status = 'enriched'`, queueEmbedding)
 		if err != nil {
 			http.Error(w, "failed to query memories: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
-		defer func() { _ = rows.Close() }()
-
-		for rows.Next() {
-			var id, content string
-			if err := rows.Scan(&id, &content); err != nil {
-				continue
-			}
-			if h.engine != nil {
-				if h.engine.QueueEmbeddingForMemory(id, content) {
-					resp.Queued++
-				}
-			} else {
-				resp.Queued++
-			}
-		}
+		resp.Queued = queued
 		resp.Message = "deleted all embeddings and queued for re-embedding"
 		if h.engine == nil {
 			resp.Message = "deleted all embeddings; queued for next startup (engine not running)"
@@ -260,6 +226,42 @@ func (h *MaintenanceHandler) RunBackfill(w http.ResponseWriter, r *http.Request)
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// queueBackfill runs query, which must select memory IDs, and passes each
+// memory's content to queue. Content is loaded through the store rather than
+// scanned from the row because SQLite may hold it gzip-compressed. When queue
+// is nil (engine not running) matching memories are only counted.
+func queueBackfill(ctx context.Context, db *sql.DB, store storage.MemoryStore, query string, queue func(id, content string) bool) (int, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+
+	queued := 0
+	for _, id := range ids {
+		if queue == nil {
+			queued++
+			continue
+		}
+		mem, err := store.Get(ctx, id)
+		if err != nil {
+			continue
+		}
+		if queue(id, mem.EnrichmentContent()) {
+			queued++
+		}
+	}
+	return queued, nil
+}
+
 // RetryEnrichment handles POST /api/memories/{id}/retry.
 // Resets a failed memory to pending and queues it for re-enrichment.
 func (h *MaintenanceHandler) RetryEnrichment(w http.ResponseWriter, r *http.Request) {
@@ -313,7 +315,7 @@ func (h *MaintenanceHandler) RetryEnrichment(w http.ResponseWriter, r *http.Requ
 	// Queue for enrichment
 	queued := false
 	if h.engine != nil {
-		queued = h.engine.QueueEnrichmentForMemory(memoryID, mem.Content)
+		queued = h.engine.QueueEnrichmentForMemory(memoryID, mem.EnrichmentContent())
 	}
 
 	w.Header().Set("Content-Type", "application/json")
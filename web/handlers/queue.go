@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/scrypster/memento/internal/connections"
+	"github.com/scrypster/memento/internal/engine"
 	"github.com/scrypster/memento/internal/storage/sqlite"
 )
 
@@ -22,6 +24,13 @@ func NewQueueHandler(connectionManager *connections.Manager, queueGetter QueueSi
 	}
 }
 
+// EngineStatusGetter is implemented by engines that can report their full
+// status (e.g. *engine.MemoryEngine). When the queue getter implements it,
+// GET /api/queue includes the same snapshot as the get_engine_status tool.
+type EngineStatusGetter interface {
+	Status(ctx context.Context) (*engine.EngineStatus, error)
+}
+
 // QueueStatsResponse contains enrichment queue statistics.
 type QueueStatsResponse struct {
 	// Live channel depth (jobs buffered in the in-memory Go channel)
@@ -51,8 +60,9 @@ type QueueItemResponse struct {
 
 // QueueResponse is the full response for GET /api/queue.
 type QueueResponse struct {
-	Stats QueueStatsResponse  `json:"stats"`
-	Items []QueueItemResponse `json:"items"`
+	Stats  QueueStatsResponse   `json:"stats"`
+	Items  []QueueItemResponse  `json:"items"`
+	Engine *engine.EngineStatus `json:"engine,omitempty"`
 }
 
 // GetQueue handles GET /api/queue - returns enrichment queue stats and pending items.
@@ -73,8 +83,15 @@ func (h *QueueHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
 
 	// Live channel depth from the in-memory engine queue
 	channelDepth := 0
+	var engineStatus *engine.EngineStatus
 	if h.queueGetter != nil {
 		channelDepth = h.queueGetter.GetQueueSize()
+		if getter, ok := h.queueGetter.(EngineStatusGetter); ok {
+			if status, err := getter.Status(ctx); err == nil {
+				engineStatus = status
+				channelDepth = status.QueueDepth
+			}
+		}
 	}
 
 	stats := QueueStatsResponse{
@@ -126,7 +143,7 @@ func (h *QueueHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
 		itemsQuery := `
 			SELECT id, content, status, enrichment_attempts,
 			       COALESCE(enrichment_error, '') as enrichment_error,
-			       created_at, updated_at, enriched_at
+			       created_at, updated_at, enriched_at, content_compressed
 			FROM memories
 			WHERE status IN ('pending', 'processing')
 			ORDER BY created_at ASC
@@ -138,13 +155,17 @@ func (h *QueueHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
 			for itemRows.Next() {
 				var item QueueItemResponse
 				var enrichedAt *time.Time
+				var compressed bool
 				if err := itemRows.Scan(
 					&item.ID, &item.Content, &item.Status,
 					&item.EnrichmentAttempts, &item.EnrichmentError,
-					&item.CreatedAt, &item.UpdatedAt, &enrichedAt,
+					&item.CreatedAt, &item.UpdatedAt, &enrichedAt, &compressed,
 				); err != nil {
 					continue
 				}
+				if item.Content, err = sqlite.DecodeContent(item.Content, compressed); err != nil {
+					continue
+				}
 				item.EnrichedAt = enrichedAt
 				// Truncate content for display
 				if len(item.Content) > 200 {
@@ -160,7 +181,8 @@ func (h *QueueHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
 	}
 
 	respondJSON(w, http.StatusOK, QueueResponse{
-		Stats: stats,
-		Items: items,
+		Stats:  stats,
+		Items:  items,
+		Engine: engineStatus,
 	})
 }
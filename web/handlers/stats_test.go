@@ -80,11 +80,23 @@ func (m *mockMemoryStoreForStats) UpdateDecayScores(ctx context.Context) (int, e
 	return 0, nil
 }
 
+func (m *mockMemoryStoreForStats) SetEmbedding(ctx context.Context, id string, vec []float64, model string) error {
+	return nil
+}
+
+func (m *mockMemoryStoreForStats) GetEmbedding(ctx context.Context, id string) ([]float64, string, error) {
+	return nil, "", storage.ErrNotFound
+}
+
+func (m *mockMemoryStoreForStats) ExpireMemories(ctx context.Context, now time.Time) (int, error) {
+	return 0, nil
+}
+
 func (m *mockMemoryStoreForStats) GetRelatedMemories(ctx context.Context, memoryID string) ([]string, error) {
 	return nil, nil
 }
 
-func (m *mockMemoryStoreForStats) Traverse(_ context.Context, _ string, _ int, _ int) ([]storage.TraversalResult, error) {
+func (m *mockMemoryStoreForStats) Traverse(_ context.Context, _ string, _ storage.TraversalOptions) ([]storage.TraversalResult, error) {
 	return nil, nil
 }
 
@@ -92,6 +104,10 @@ func (m *mockMemoryStoreForStats) GetMemoryEntities(_ context.Context, _ string)
 	return nil, nil
 }
 
+func (m *mockMemoryStoreForStats) ListEntities(_ context.Context, _ storage.EntityListOptions) (*storage.PaginatedResult[types.Entity], error) {
+	return &storage.PaginatedResult[types.Entity]{}, nil
+}
+
 func (m *mockMemoryStoreForStats) Restore(_ context.Context, _ string) error { return nil }
 
 func (m *mockMemoryStoreForStats) GetEvolutionChain(_ context.Context, _ string) ([]*types.Memory, error) {